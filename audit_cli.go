@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/config"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+// runVerifyAudit runs `peopleos verify-audit [-tenant ID]`, which walks the
+// audit chains and prints the first broken link of each. It returns the
+// process exit code: 0 when every chain holds, 1 when one is broken and 2
+// for bad usage.
+func runVerifyAudit(pool *pgxpool.Pool, args []string) int {
+	flags := flag.NewFlagSet("verify-audit", flag.ContinueOnError)
+	tenant := flags.Int("tenant", -1, "tenant whose chain to verify, 0 for the rows without a tenant (default all chains)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	signingKey, err := services.ParseAuditSigningKey(config.GetEnv("AUDIT_SIGNING_KEY", ""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid AUDIT_SIGNING_KEY: %v\n", err)
+		return 2
+	}
+	if signingKey == nil {
+		fmt.Println("AUDIT_SIGNING_KEY is not set; checkpoint signatures will not be checked")
+	}
+	auditService := services.NewAuditService(repositories.NewAuditRepository(pool), repositories.NewRoleRepository(pool), signingKey)
+
+	ctx := context.Background()
+	tenantIDs := []int{*tenant}
+	if *tenant < 0 {
+		if tenantIDs, err = auditService.ListAuditChains(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	code := 0
+	for _, tenantID := range tenantIDs {
+		result, err := auditService.VerifyTenantChain(ctx, tenantID)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if result.Valid {
+			fmt.Printf("tenant %d: ok, %d rows checked, %d unchained, %d checkpoints\n",
+				tenantID, result.CheckedRows, result.UnchainedRows, result.CheckedCheckpoints)
+			continue
+		}
+
+		code = 1
+		broken := result.BrokenLink
+		if broken.CheckpointID != 0 {
+			fmt.Printf("tenant %d: BROKEN at checkpoint %d: %s\n", tenantID, broken.CheckpointID, broken.Reason)
+		} else {
+			fmt.Printf("tenant %d: BROKEN at audit log %d: %s\n", tenantID, broken.LogID, broken.Reason)
+		}
+		if broken.ExpectedHash != "" || broken.ActualHash != "" {
+			fmt.Printf("  expected %s\n  actual   %s\n", broken.ExpectedHash, broken.ActualHash)
+		}
+	}
+	return code
+}
@@ -40,3 +40,11 @@ func InitDB() (*pgxpool.Pool, error) {
 	fmt.Println("✓ Database connection pool created successfully")
 	return pool, nil
 }
+
+// GetEnv returns the value of the environment variable or the fallback when unset
+func GetEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,24 @@
+-- Export Jobs (Background data exports)
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    requested_by INTEGER,
+    entity_type VARCHAR(100) NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    columns TEXT,
+    filters JSONB,
+    status VARCHAR(50) DEFAULT 'pending',
+    file_path VARCHAR(500),
+    file_name VARCHAR(255),
+    row_count INT DEFAULT 0,
+    error TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (requested_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+-- Export jobs are claimed from the table by the export worker, so pending and
+-- running jobs are looked up by status
+CREATE INDEX IF NOT EXISTS idx_export_jobs_status ON export_jobs(status, id);
@@ -0,0 +1,24 @@
+-- Multi-factor authentication for employees
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS mfa_secret VARCHAR(64);
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN DEFAULT FALSE;
+
+-- Employee Invitations (Onboarding links)
+CREATE TABLE IF NOT EXISTS employee_invitations (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(50) DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    sent_count INT DEFAULT 0,
+    last_sent_at TIMESTAMP,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    invited_by INTEGER,
+    mfa_secret VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES employees(id) ON DELETE SET NULL
+);
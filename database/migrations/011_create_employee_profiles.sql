@@ -0,0 +1,71 @@
+-- Employee Personal Details (One row per employee)
+CREATE TABLE IF NOT EXISTS employee_personal_details (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL UNIQUE,
+    date_of_birth DATE,
+    gender VARCHAR(50),
+    nationality VARCHAR(100),
+    marital_status VARCHAR(50),
+    national_id VARCHAR(100),
+    tax_number VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+-- Employee Addresses (Kept as history, one current row per address type)
+CREATE TABLE IF NOT EXISTS employee_addresses (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    address_type VARCHAR(50) DEFAULT 'home',
+    line1 VARCHAR(255) NOT NULL,
+    line2 VARCHAR(255),
+    city VARCHAR(100) NOT NULL,
+    state VARCHAR(100),
+    postal_code VARCHAR(20),
+    country VARCHAR(2) NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    is_current BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+-- Employee Emergency Contacts
+CREATE TABLE IF NOT EXISTS employee_emergency_contacts (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    relationship VARCHAR(100) NOT NULL,
+    phone VARCHAR(30) NOT NULL,
+    email VARCHAR(255),
+    is_primary BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+-- Employee Bank Accounts (Payroll)
+CREATE TABLE IF NOT EXISTS employee_bank_accounts (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    bank_name VARCHAR(255) NOT NULL,
+    account_name VARCHAR(255) NOT NULL,
+    account_number VARCHAR(50),
+    bank_code VARCHAR(20),
+    iban VARCHAR(34),
+    currency VARCHAR(3),
+    is_primary BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
@@ -0,0 +1,22 @@
+-- Custom Field Definitions (Tenant-defined employee attributes)
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    entity_type VARCHAR(100) DEFAULT 'employee',
+    field_key VARCHAR(50) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    field_type VARCHAR(20) NOT NULL,
+    required BOOLEAN DEFAULT FALSE,
+    options JSONB,
+    rules JSONB,
+    visibility VARCHAR(50) DEFAULT 'everyone',
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE(tenant_id, entity_type, field_key)
+);
+
+-- Custom field values live alongside the employee row
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS custom_fields JSONB DEFAULT '{}'::jsonb;
+CREATE INDEX IF NOT EXISTS idx_employees_custom_fields ON employees USING GIN (custom_fields);
@@ -0,0 +1,42 @@
+-- Current employment terms on the employee row
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS employment_type VARCHAR(50) DEFAULT 'full_time';
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS salary NUMERIC(14, 2);
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS salary_currency VARCHAR(3);
+
+-- Employment History (Effective-dated changes to an employee's job)
+CREATE TABLE IF NOT EXISTS employment_history (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    change_type VARCHAR(50) NOT NULL,
+    effective_date DATE NOT NULL,
+    changed_fields TEXT[] NOT NULL,
+    department_id INTEGER,
+    designation_id INTEGER,
+    manager_id INTEGER,
+    employment_type VARCHAR(50),
+    salary NUMERIC(14, 2),
+    salary_currency VARCHAR(3),
+    reason TEXT,
+    status VARCHAR(50) DEFAULT 'scheduled',
+    created_by INTEGER,
+    applied_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE RESTRICT,
+    FOREIGN KEY (designation_id) REFERENCES designations(id) ON DELETE RESTRICT,
+    FOREIGN KEY (manager_id) REFERENCES employees(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_employment_history_employee ON employment_history(tenant_id, employee_id, effective_date);
+CREATE INDEX IF NOT EXISTS idx_employment_history_due ON employment_history(status, effective_date);
+
+-- Existing employees start their history with a hire record
+INSERT INTO employment_history (tenant_id, employee_id, change_type, effective_date, changed_fields, department_id, designation_id, manager_id, employment_type, status, applied_at)
+SELECT e.tenant_id, e.id, 'hire', COALESCE(e.hire_date, e.created_at::date), ARRAY['department_id', 'designation_id', 'manager_id', 'employment_type'], e.department_id, e.designation_id, e.manager_id, COALESCE(e.employment_type, 'full_time'), 'applied', CURRENT_TIMESTAMP
+FROM employees e
+WHERE NOT EXISTS (SELECT 1 FROM employment_history h WHERE h.employee_id = e.id);
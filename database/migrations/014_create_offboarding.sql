@@ -0,0 +1,74 @@
+-- Tokens issued before this moment are no longer accepted
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS access_revoked_at TIMESTAMP;
+
+-- Offboarding Checklist Items (Tenant-configured tasks generated for every leaver)
+CREATE TABLE IF NOT EXISTS offboarding_checklist_items (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assignee_type VARCHAR(50) NOT NULL,
+    assignee_employee_id INTEGER,
+    due_offset_days INT DEFAULT 0,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_employee_id) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_offboarding_checklist_items_tenant ON offboarding_checklist_items(tenant_id, sort_order);
+
+-- Offboardings (One per departing employee)
+CREATE TABLE IF NOT EXISTS offboardings (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    last_working_day DATE NOT NULL,
+    reason TEXT,
+    successor_id INTEGER NOT NULL,
+    status VARCHAR(50) DEFAULT 'scheduled',
+    reassigned_reports INT DEFAULT 0,
+    reassigned_approvals INT DEFAULT 0,
+    leave_settlement JSONB,
+    leave_settlement_days NUMERIC(7, 2),
+    leave_settlement_amount NUMERIC(14, 2),
+    settlement_currency VARCHAR(3),
+    initiated_by INTEGER,
+    completed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (successor_id) REFERENCES employees(id) ON DELETE RESTRICT,
+    FOREIGN KEY (initiated_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offboardings_active_employee ON offboardings(employee_id) WHERE status <> 'cancelled';
+CREATE INDEX IF NOT EXISTS idx_offboardings_due ON offboardings(status, last_working_day);
+
+-- Offboarding Tasks (Checklist generated for one offboarding)
+CREATE TABLE IF NOT EXISTS offboarding_tasks (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    offboarding_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assignee_type VARCHAR(50) NOT NULL,
+    assignee_id INTEGER,
+    due_date DATE,
+    status VARCHAR(50) DEFAULT 'pending',
+    notes TEXT,
+    completed_by INTEGER,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (offboarding_id) REFERENCES offboardings(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_id) REFERENCES employees(id) ON DELETE SET NULL,
+    FOREIGN KEY (completed_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_offboarding_tasks_offboarding ON offboarding_tasks(offboarding_id);
+CREATE INDEX IF NOT EXISTS idx_offboarding_tasks_assignee ON offboarding_tasks(tenant_id, assignee_id, status);
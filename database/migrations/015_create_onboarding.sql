@@ -0,0 +1,62 @@
+-- Onboarding Templates (Tenant-defined task lists, optionally per department or designation)
+CREATE TABLE IF NOT EXISTS onboarding_templates (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    department_id INTEGER,
+    designation_id INTEGER,
+    status VARCHAR(50) DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE CASCADE,
+    FOREIGN KEY (designation_id) REFERENCES designations(id) ON DELETE CASCADE,
+    UNIQUE(tenant_id, name)
+);
+
+-- Onboarding Template Tasks (Tasks a template spawns, due relative to the hire date)
+CREATE TABLE IF NOT EXISTS onboarding_template_tasks (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assignee_type VARCHAR(50) NOT NULL,
+    assignee_employee_id INTEGER,
+    due_offset_days INT DEFAULT 0,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (template_id) REFERENCES onboarding_templates(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_employee_id) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_onboarding_template_tasks_template ON onboarding_template_tasks(template_id, sort_order);
+
+-- Onboarding Tasks (Tasks spawned for one new hire)
+CREATE TABLE IF NOT EXISTS onboarding_tasks (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    template_id INTEGER,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assignee_type VARCHAR(50) NOT NULL,
+    assignee_id INTEGER,
+    due_date DATE NOT NULL,
+    status VARCHAR(50) DEFAULT 'pending',
+    notes TEXT,
+    completed_by INTEGER,
+    completed_at TIMESTAMP,
+    last_reminded_at TIMESTAMP,
+    reminder_count INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (template_id) REFERENCES onboarding_templates(id) ON DELETE SET NULL,
+    FOREIGN KEY (assignee_id) REFERENCES employees(id) ON DELETE SET NULL,
+    FOREIGN KEY (completed_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_onboarding_tasks_employee ON onboarding_tasks(tenant_id, employee_id);
+CREATE INDEX IF NOT EXISTS idx_onboarding_tasks_assignee ON onboarding_tasks(tenant_id, assignee_id, status);
+CREATE INDEX IF NOT EXISTS idx_onboarding_tasks_overdue ON onboarding_tasks(status, due_date);
@@ -0,0 +1,10 @@
+-- Leave type rules
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS is_paid BOOLEAN DEFAULT TRUE;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS requires_attachment BOOLEAN DEFAULT FALSE;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS min_notice_days INT DEFAULT 0;
+-- NULL means no limit
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS max_consecutive_days INT;
+-- NULL means every gender is eligible
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS eligible_gender VARCHAR(50);
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS min_tenure_months INT DEFAULT 0;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS allow_half_day BOOLEAN DEFAULT FALSE;
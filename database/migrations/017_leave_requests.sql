@@ -0,0 +1,27 @@
+-- Days of the week the company works, as 0 (Sunday) to 6 (Saturday)
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS work_days INT[] DEFAULT '{1,2,3,4,5}';
+
+-- Holidays (Days off that are not counted as leave)
+CREATE TABLE IF NOT EXISTS holidays (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    holiday_date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE(tenant_id, holiday_date)
+);
+
+-- Leave request details and decisions
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS half_day BOOLEAN DEFAULT FALSE;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS attachment_url VARCHAR(500);
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS deducted_days DECIMAL(5, 2) DEFAULT 0;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS decided_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS decision_comment TEXT;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS cancelled_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS cancellation_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_leave_requests_employee ON leave_requests(tenant_id, employee_id, status);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_status ON leave_requests(tenant_id, status, start_date);
@@ -0,0 +1,64 @@
+-- Month the company's leave year starts in; balances are kept per leave year,
+-- named after the calendar year it starts in
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS leave_year_start_month INT DEFAULT 1;
+
+-- Leave year a request draws its days from
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS leave_year INT;
+
+-- Leave Accrual Policies (How a leave type's entitlement is earned)
+CREATE TABLE IF NOT EXISTS leave_accrual_policies (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    leave_type_id INTEGER NOT NULL UNIQUE,
+    frequency VARCHAR(20) NOT NULL, -- annual, monthly, pay_period
+    pay_periods_per_year INT,
+    prorate_first_year BOOLEAN DEFAULT TRUE,
+    carry_over_max_days DECIMAL(5, 2), -- NULL carries everything over
+    carry_over_expiry_months INT, -- NULL keeps carried days for the whole year
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_type_id) REFERENCES leave_types(id) ON DELETE CASCADE
+);
+
+-- Leave Accrual Tiers (Entitlement by length of service)
+CREATE TABLE IF NOT EXISTS leave_accrual_tiers (
+    id SERIAL PRIMARY KEY,
+    policy_id INTEGER NOT NULL,
+    min_tenure_months INT NOT NULL,
+    days_per_year DECIMAL(5, 2) NOT NULL,
+    FOREIGN KEY (policy_id) REFERENCES leave_accrual_policies(id) ON DELETE CASCADE,
+    UNIQUE(policy_id, min_tenure_months)
+);
+
+-- Leave Ledger (Every change to a leave balance)
+CREATE TABLE IF NOT EXISTS leave_ledger_entries (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    leave_type_id INTEGER NOT NULL,
+    year INT NOT NULL,
+    entry_type VARCHAR(20) NOT NULL, -- accrual, deduction, restoration, adjustment, carry_over, expiry
+    days DECIMAL(6, 2) NOT NULL,
+    period_key VARCHAR(30), -- makes scheduled entries idempotent
+    leave_request_id INTEGER,
+    note TEXT,
+    created_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_type_id) REFERENCES leave_types(id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_request_id) REFERENCES leave_requests(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL,
+    UNIQUE(employee_id, leave_type_id, year, entry_type, period_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_ledger_employee ON leave_ledger_entries(tenant_id, employee_id, year);
+
+-- Existing balances become opening entries so the ledger adds up to them
+INSERT INTO leave_ledger_entries (tenant_id, employee_id, leave_type_id, year, entry_type, days, period_key, note)
+SELECT lt.tenant_id, lb.employee_id, lb.leave_type_id, lb.year, 'adjustment', lb.balance_days, 'opening', 'Opening balance'
+FROM leave_balances lb
+JOIN leave_types lt ON lt.id = lb.leave_type_id
+WHERE lb.year IS NOT NULL AND COALESCE(lb.balance_days, 0) <> 0
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,12 @@
+-- Where an employee works; holidays for a location override the tenant's
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS work_location VARCHAR(100);
+
+-- Holidays apply tenant-wide when location is empty. A holiday for a location
+-- replaces the tenant-wide one on the same date, and one that is not a day off
+-- keeps the location working on that date.
+ALTER TABLE holidays ADD COLUMN IF NOT EXISTS location VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE holidays ADD COLUMN IF NOT EXISTS is_day_off BOOLEAN DEFAULT TRUE;
+ALTER TABLE holidays ADD COLUMN IF NOT EXISTS source VARCHAR(20) DEFAULT 'custom'; -- bundled, ical, custom
+ALTER TABLE holidays ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE holidays DROP CONSTRAINT IF EXISTS holidays_tenant_id_holiday_date_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holidays_tenant_date_location ON holidays(tenant_id, holiday_date, location);
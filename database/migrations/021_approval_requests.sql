@@ -0,0 +1,38 @@
+-- How a step finds its approvers: role (everyone holding approver_role_id),
+-- manager (the requester's reporting manager) or department_head
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS approver_type VARCHAR(20) DEFAULT 'role';
+ALTER TABLE approval_steps ALTER COLUMN approver_role_id DROP NOT NULL;
+
+-- Approval Requests (A workflow running for one record)
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    workflow_id INTEGER,
+    entity_type VARCHAR(100) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    requester_id INTEGER NOT NULL,
+    status VARCHAR(20) DEFAULT 'pending', -- pending, approved, rejected, cancelled
+    current_step_order INT,
+    version INT NOT NULL DEFAULT 1,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (workflow_id) REFERENCES approval_workflows(id) ON DELETE SET NULL,
+    FOREIGN KEY (requester_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+-- A record has at most one approval running at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_approval_requests_pending_entity ON approval_requests(tenant_id, entity_type, entity_id) WHERE status = 'pending';
+
+-- Each approval is one approver's part in a step of an approval request. The
+-- step order is kept so the history survives changes to the workflow.
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS approval_request_id INTEGER REFERENCES approval_requests(id) ON DELETE CASCADE;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS step_order INT;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP;
+ALTER TABLE approvals ALTER COLUMN approval_step_id DROP NOT NULL;
+ALTER TABLE approvals DROP CONSTRAINT IF EXISTS approvals_approval_step_id_fkey;
+ALTER TABLE approvals ADD CONSTRAINT approvals_approval_step_id_fkey FOREIGN KEY (approval_step_id) REFERENCES approval_steps(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_approvals_request ON approvals(approval_request_id);
+CREATE INDEX IF NOT EXISTS idx_approvals_approver_status ON approvals(approver_id, status);
@@ -0,0 +1,11 @@
+-- Steps sharing a step_order run in parallel as one stage of the workflow
+ALTER TABLE approval_steps DROP CONSTRAINT IF EXISTS approval_steps_workflow_id_step_order_key;
+CREATE INDEX IF NOT EXISTS idx_approval_steps_workflow_order ON approval_steps(workflow_id, step_order);
+
+-- conditions: the step only applies when all of them hold for the record
+-- approval_mode: any (one approver), all, or quorum (required_approvals of them)
+-- skip_if_requester: the step is passed when the requester is one of its approvers
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS conditions JSONB DEFAULT '[]'::jsonb;
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS approval_mode VARCHAR(10) DEFAULT 'any';
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS required_approvals INT;
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS skip_if_requester BOOLEAN DEFAULT FALSE;
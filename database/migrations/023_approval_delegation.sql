@@ -0,0 +1,42 @@
+-- Approval Delegations (An approver's approvals go to a delegate for a date range)
+CREATE TABLE IF NOT EXISTS approval_delegations (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    delegator_id INTEGER NOT NULL,
+    delegate_id INTEGER NOT NULL,
+    entity_type VARCHAR(100), -- NULL covers every entity type
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason TEXT,
+    created_by INTEGER,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (delegator_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (delegate_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL,
+    CHECK (end_date >= start_date),
+    CHECK (delegator_id <> delegate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_delegations_delegator ON approval_delegations(tenant_id, delegator_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_approval_delegations_delegate ON approval_delegations(tenant_id, delegate_id) WHERE revoked_at IS NULL;
+
+-- reminder_hours: how long after an approval is assigned its approver is reminded
+-- sla_hours: how long after an approval is assigned it escalates to the next level
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS reminder_hours INT;
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS sla_hours INT;
+
+-- remind_at and escalate_at are when the scheduler acts on a pending approval,
+-- and reminded_at marks the reminder as sent, so timers survive restarts.
+-- delegated_from_id is the approver the approval was delegated from and
+-- escalated_from_id the approval it replaced when it escalated.
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS remind_at TIMESTAMP;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMP;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS escalate_at TIMESTAMP;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS delegated_from_id INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS escalated_from_id INTEGER REFERENCES approvals(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_approvals_remind_at ON approvals(remind_at) WHERE status = 'pending' AND reminded_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_approvals_escalate_at ON approvals(escalate_at) WHERE status = 'pending';
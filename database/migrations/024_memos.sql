@@ -0,0 +1,20 @@
+-- Memo type rules
+-- category: general, query, commendation or warning
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS category VARCHAR(50) DEFAULT 'general';
+-- Disciplinary memos are only visible to HR, the subject and their manager chain
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS is_disciplinary BOOLEAN DEFAULT FALSE;
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS requires_acknowledgement BOOLEAN DEFAULT FALSE;
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS status VARCHAR(50) DEFAULT 'active';
+
+-- employee_id is the memo's subject and author_id the employee who wrote it
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS author_id INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS decided_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS decision_comment TEXT;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS cancelled_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS acknowledgement_comment TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_memos_employee ON memos(tenant_id, employee_id, status);
+CREATE INDEX IF NOT EXISTS idx_memos_author ON memos(tenant_id, author_id);
@@ -0,0 +1,48 @@
+-- Disciplinary Cases (A disciplinary process about one employee, linking its memos)
+-- stage: query_issued, response_received, hearing_scheduled or outcome
+-- outcome: no_action, warning, final_warning, suspension or termination
+CREATE TABLE IF NOT EXISTS disciplinary_cases (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    stage VARCHAR(50) NOT NULL DEFAULT 'query_issued',
+    status VARCHAR(50) NOT NULL DEFAULT 'open',
+    hearing_at TIMESTAMP,
+    hearing_location VARCHAR(255),
+    outcome VARCHAR(50),
+    outcome_notes TEXT,
+    opened_by INTEGER,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (opened_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_disciplinary_cases_employee ON disciplinary_cases(tenant_id, employee_id, status);
+
+-- Disciplinary Case Events (When a case reached each stage)
+CREATE TABLE IF NOT EXISTS disciplinary_case_events (
+    id SERIAL PRIMARY KEY,
+    case_id INTEGER NOT NULL,
+    stage VARCHAR(50) NOT NULL,
+    note TEXT,
+    recorded_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (case_id) REFERENCES disciplinary_cases(id) ON DELETE CASCADE,
+    FOREIGN KEY (recorded_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_disciplinary_case_events_case ON disciplinary_case_events(case_id);
+
+-- validity_months: how long an issued warning stays active; NULL never lapses
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS validity_months INT;
+
+-- expires_at is fixed from the memo type's validity when the memo is issued
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS case_id INTEGER REFERENCES disciplinary_cases(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_memos_case ON memos(case_id);
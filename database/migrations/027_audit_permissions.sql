@@ -0,0 +1,10 @@
+-- Reading the audit log is open to HR; exporting it needs the audit_logs
+-- export permission. Company owners hold both.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_role_action ON permissions(role_id, resource, action);
+
+INSERT INTO permissions (role_id, action, resource)
+SELECT r.id, p.action, 'audit_logs'
+FROM roles r
+CROSS JOIN (VALUES ('read'), ('export')) AS p(action)
+WHERE r.name = 'Super Admin'
+ON CONFLICT (role_id, resource, action) DO NOTHING;
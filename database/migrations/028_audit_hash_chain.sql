@@ -0,0 +1,22 @@
+-- Each audit row carries a hash over its content and the hash of the previous
+-- row of the same tenant. Rows written before the chain existed have no hash.
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_id ON audit_logs(tenant_id, id);
+
+-- Signed checkpoints of each chain's head, kept so they can be exported and
+-- compared against the chain later. tenant_id is NULL for the chain of rows
+-- without a tenant.
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER,
+    last_log_id INTEGER NOT NULL,
+    last_hash VARCHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    public_key TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_tenant ON audit_checkpoints(tenant_id, last_log_id);
@@ -0,0 +1,25 @@
+-- Document types set what may be uploaded: the accepted content types (any
+-- when empty) and the largest file size
+CREATE TABLE IF NOT EXISTS document_types (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    allowed_content_types TEXT[] DEFAULT '{}',
+    max_size_bytes BIGINT NOT NULL,
+    status VARCHAR(50) DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE (tenant_id, name)
+);
+
+-- file_path is the key of the file in document storage and checksum the
+-- hex SHA-256 of its content
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS document_type_id INTEGER REFERENCES document_types(id) ON DELETE RESTRICT;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS content_type VARCHAR(255);
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS size_bytes BIGINT;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS checksum VARCHAR(64);
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS uploaded_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_documents_employee ON documents(tenant_id, employee_id);
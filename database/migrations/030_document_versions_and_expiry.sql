@@ -0,0 +1,54 @@
+-- Every upload of a document is kept as a version; the document row carries
+-- the current version's file and dates
+CREATE TABLE IF NOT EXISTS document_versions (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    document_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    file_path VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    issue_date DATE,
+    expiry_date DATE,
+    uploaded_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE,
+    FOREIGN KEY (uploaded_by) REFERENCES employees(id) ON DELETE SET NULL,
+    UNIQUE (document_id, version)
+);
+
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS current_version INTEGER DEFAULT 1;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS issue_date DATE;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS expiry_date DATE;
+-- Set when the expiry reminder for the current version was sent
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS expiry_reminded_at TIMESTAMP;
+
+-- Documents expiring within this many days of today are flagged and reminded
+ALTER TABLE document_types ADD COLUMN IF NOT EXISTS expiry_reminder_days INTEGER DEFAULT 30;
+
+INSERT INTO document_versions (tenant_id, document_id, version, file_path, file_name, content_type, size_bytes, checksum, uploaded_by, created_at)
+SELECT d.tenant_id, d.id, 1, d.file_path, COALESCE(d.file_name, 'document'), COALESCE(d.content_type, 'application/octet-stream'), COALESCE(d.size_bytes, 0), COALESCE(d.checksum, ''), d.uploaded_by, d.created_at
+FROM documents d
+WHERE d.file_path IS NOT NULL
+ON CONFLICT (document_id, version) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_documents_expiry ON documents(expiry_date) WHERE expiry_date IS NOT NULL;
+
+-- Document types every matching employee must hold. A rule without a
+-- designation or country applies to everyone on that side.
+CREATE TABLE IF NOT EXISTS document_requirements (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    document_type_id INTEGER NOT NULL,
+    designation_id INTEGER,
+    country VARCHAR(2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (document_type_id) REFERENCES document_types(id) ON DELETE CASCADE,
+    FOREIGN KEY (designation_id) REFERENCES designations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_requirements_tenant ON document_requirements(tenant_id);
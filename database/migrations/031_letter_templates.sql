@@ -0,0 +1,21 @@
+-- Letter templates are plain text with {{placeholder}} fields filled from
+-- the employee, department, designation and company. Generated letters are
+-- saved as documents of document_type_id, or of a type named after the
+-- template when it is not set.
+CREATE TABLE IF NOT EXISTS letter_templates (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(50) NOT NULL DEFAULT 'other',
+    document_type_id INTEGER,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(50) DEFAULT 'active',
+    created_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (document_type_id) REFERENCES document_types(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL,
+    UNIQUE (tenant_id, name)
+);
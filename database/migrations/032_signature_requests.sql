@@ -0,0 +1,57 @@
+-- A signature request asks employees to sign, or acknowledge, one version of
+-- a stored document. Signers sign in sign_order; signers sharing an order
+-- sign in any order. Once everyone has signed a signature request, the
+-- sealed PDF with its certificate page is saved as sealed_version of the
+-- document.
+CREATE TABLE IF NOT EXISTS signature_requests (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    document_id INTEGER NOT NULL,
+    document_version INTEGER NOT NULL,
+    document_checksum VARCHAR(64) NOT NULL,
+    kind VARCHAR(50) NOT NULL DEFAULT 'signature',
+    title VARCHAR(255) NOT NULL,
+    message TEXT,
+    policy_name VARCHAR(100),
+    policy_version VARCHAR(50),
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    sealed_version INTEGER,
+    sealed_checksum VARCHAR(64),
+    created_by INTEGER,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_signature_requests_tenant ON signature_requests(tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_signature_requests_policy ON signature_requests(tenant_id, policy_name, policy_version) WHERE policy_name IS NOT NULL;
+
+-- signature_image_key locates a drawn signature in document storage. The IP
+-- address and user agent are those of the request the signer signed in.
+CREATE TABLE IF NOT EXISTS signature_request_signers (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    request_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    sign_order INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    signature_type VARCHAR(20),
+    typed_name VARCHAR(255),
+    signature_image_key VARCHAR(500),
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(500),
+    decline_reason TEXT,
+    notified_at TIMESTAMP,
+    signed_at TIMESTAMP,
+    declined_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (request_id) REFERENCES signature_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    UNIQUE (request_id, employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_signature_signers_employee ON signature_request_signers(tenant_id, employee_id, status);
@@ -228,3 +228,729 @@ CREATE TABLE IF NOT EXISTS documents (
     FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
     FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
 );
+
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    requested_by INTEGER,
+    entity_type VARCHAR(100) NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    columns TEXT,
+    filters JSONB,
+    status VARCHAR(50) DEFAULT 'pending',
+    file_path VARCHAR(500),
+    file_name VARCHAR(255),
+    row_count INT DEFAULT 0,
+    error TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (requested_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+-- Export jobs are claimed from the table by the export worker, so pending and
+-- running jobs are looked up by status
+CREATE INDEX IF NOT EXISTS idx_export_jobs_status ON export_jobs(status, id);
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS mfa_secret VARCHAR(64);
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS employee_invitations (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(50) DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    sent_count INT DEFAULT 0,
+    last_sent_at TIMESTAMP,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    invited_by INTEGER,
+    mfa_secret VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS employee_personal_details (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL UNIQUE,
+    date_of_birth DATE,
+    gender VARCHAR(50),
+    nationality VARCHAR(100),
+    marital_status VARCHAR(50),
+    national_id VARCHAR(100),
+    tax_number VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS employee_addresses (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    address_type VARCHAR(50) DEFAULT 'home',
+    line1 VARCHAR(255) NOT NULL,
+    line2 VARCHAR(255),
+    city VARCHAR(100) NOT NULL,
+    state VARCHAR(100),
+    postal_code VARCHAR(20),
+    country VARCHAR(2) NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    is_current BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS employee_emergency_contacts (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    relationship VARCHAR(100) NOT NULL,
+    phone VARCHAR(30) NOT NULL,
+    email VARCHAR(255),
+    is_primary BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS employee_bank_accounts (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    bank_name VARCHAR(255) NOT NULL,
+    account_name VARCHAR(255) NOT NULL,
+    account_number VARCHAR(50),
+    bank_code VARCHAR(20),
+    iban VARCHAR(34),
+    currency VARCHAR(3),
+    is_primary BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    entity_type VARCHAR(100) DEFAULT 'employee',
+    field_key VARCHAR(50) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    field_type VARCHAR(20) NOT NULL,
+    required BOOLEAN DEFAULT FALSE,
+    options JSONB,
+    rules JSONB,
+    visibility VARCHAR(50) DEFAULT 'everyone',
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE(tenant_id, entity_type, field_key)
+);
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS custom_fields JSONB DEFAULT '{}'::jsonb;
+CREATE INDEX IF NOT EXISTS idx_employees_custom_fields ON employees USING GIN (custom_fields);
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS employment_type VARCHAR(50) DEFAULT 'full_time';
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS salary NUMERIC(14, 2);
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS salary_currency VARCHAR(3);
+
+CREATE TABLE IF NOT EXISTS employment_history (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    change_type VARCHAR(50) NOT NULL,
+    effective_date DATE NOT NULL,
+    changed_fields TEXT[] NOT NULL,
+    department_id INTEGER,
+    designation_id INTEGER,
+    manager_id INTEGER,
+    employment_type VARCHAR(50),
+    salary NUMERIC(14, 2),
+    salary_currency VARCHAR(3),
+    reason TEXT,
+    status VARCHAR(50) DEFAULT 'scheduled',
+    created_by INTEGER,
+    applied_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE RESTRICT,
+    FOREIGN KEY (designation_id) REFERENCES designations(id) ON DELETE RESTRICT,
+    FOREIGN KEY (manager_id) REFERENCES employees(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_employment_history_employee ON employment_history(tenant_id, employee_id, effective_date);
+CREATE INDEX IF NOT EXISTS idx_employment_history_due ON employment_history(status, effective_date);
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS access_revoked_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS offboarding_checklist_items (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assignee_type VARCHAR(50) NOT NULL,
+    assignee_employee_id INTEGER,
+    due_offset_days INT DEFAULT 0,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_employee_id) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_offboarding_checklist_items_tenant ON offboarding_checklist_items(tenant_id, sort_order);
+
+CREATE TABLE IF NOT EXISTS offboardings (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    last_working_day DATE NOT NULL,
+    reason TEXT,
+    successor_id INTEGER NOT NULL,
+    status VARCHAR(50) DEFAULT 'scheduled',
+    reassigned_reports INT DEFAULT 0,
+    reassigned_approvals INT DEFAULT 0,
+    leave_settlement JSONB,
+    leave_settlement_days NUMERIC(7, 2),
+    leave_settlement_amount NUMERIC(14, 2),
+    settlement_currency VARCHAR(3),
+    initiated_by INTEGER,
+    completed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (successor_id) REFERENCES employees(id) ON DELETE RESTRICT,
+    FOREIGN KEY (initiated_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offboardings_active_employee ON offboardings(employee_id) WHERE status <> 'cancelled';
+CREATE INDEX IF NOT EXISTS idx_offboardings_due ON offboardings(status, last_working_day);
+
+CREATE TABLE IF NOT EXISTS offboarding_tasks (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    offboarding_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assignee_type VARCHAR(50) NOT NULL,
+    assignee_id INTEGER,
+    due_date DATE,
+    status VARCHAR(50) DEFAULT 'pending',
+    notes TEXT,
+    completed_by INTEGER,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (offboarding_id) REFERENCES offboardings(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_id) REFERENCES employees(id) ON DELETE SET NULL,
+    FOREIGN KEY (completed_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_offboarding_tasks_offboarding ON offboarding_tasks(offboarding_id);
+CREATE INDEX IF NOT EXISTS idx_offboarding_tasks_assignee ON offboarding_tasks(tenant_id, assignee_id, status);
+
+CREATE TABLE IF NOT EXISTS onboarding_templates (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    department_id INTEGER,
+    designation_id INTEGER,
+    status VARCHAR(50) DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (department_id) REFERENCES departments(id) ON DELETE CASCADE,
+    FOREIGN KEY (designation_id) REFERENCES designations(id) ON DELETE CASCADE,
+    UNIQUE(tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS onboarding_template_tasks (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assignee_type VARCHAR(50) NOT NULL,
+    assignee_employee_id INTEGER,
+    due_offset_days INT DEFAULT 0,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (template_id) REFERENCES onboarding_templates(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_employee_id) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_onboarding_template_tasks_template ON onboarding_template_tasks(template_id, sort_order);
+
+CREATE TABLE IF NOT EXISTS onboarding_tasks (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    template_id INTEGER,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assignee_type VARCHAR(50) NOT NULL,
+    assignee_id INTEGER,
+    due_date DATE NOT NULL,
+    status VARCHAR(50) DEFAULT 'pending',
+    notes TEXT,
+    completed_by INTEGER,
+    completed_at TIMESTAMP,
+    last_reminded_at TIMESTAMP,
+    reminder_count INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (template_id) REFERENCES onboarding_templates(id) ON DELETE SET NULL,
+    FOREIGN KEY (assignee_id) REFERENCES employees(id) ON DELETE SET NULL,
+    FOREIGN KEY (completed_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_onboarding_tasks_employee ON onboarding_tasks(tenant_id, employee_id);
+CREATE INDEX IF NOT EXISTS idx_onboarding_tasks_assignee ON onboarding_tasks(tenant_id, assignee_id, status);
+CREATE INDEX IF NOT EXISTS idx_onboarding_tasks_overdue ON onboarding_tasks(status, due_date);
+
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS is_paid BOOLEAN DEFAULT TRUE;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS requires_attachment BOOLEAN DEFAULT FALSE;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS min_notice_days INT DEFAULT 0;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS max_consecutive_days INT;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS eligible_gender VARCHAR(50);
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS min_tenure_months INT DEFAULT 0;
+ALTER TABLE leave_types ADD COLUMN IF NOT EXISTS allow_half_day BOOLEAN DEFAULT FALSE;
+
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS work_days INT[] DEFAULT '{1,2,3,4,5}';
+
+CREATE TABLE IF NOT EXISTS holidays (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    holiday_date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE(tenant_id, holiday_date)
+);
+
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS half_day BOOLEAN DEFAULT FALSE;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS attachment_url VARCHAR(500);
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS deducted_days DECIMAL(5, 2) DEFAULT 0;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS decided_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS decision_comment TEXT;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS cancelled_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS cancellation_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_leave_requests_employee ON leave_requests(tenant_id, employee_id, status);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_status ON leave_requests(tenant_id, status, start_date);
+
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS leave_year_start_month INT DEFAULT 1;
+
+ALTER TABLE leave_requests ADD COLUMN IF NOT EXISTS leave_year INT;
+
+CREATE TABLE IF NOT EXISTS leave_accrual_policies (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    leave_type_id INTEGER NOT NULL UNIQUE,
+    frequency VARCHAR(20) NOT NULL, -- annual, monthly, pay_period
+    pay_periods_per_year INT,
+    prorate_first_year BOOLEAN DEFAULT TRUE,
+    carry_over_max_days DECIMAL(5, 2), -- NULL carries everything over
+    carry_over_expiry_months INT, -- NULL keeps carried days for the whole year
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_type_id) REFERENCES leave_types(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS leave_accrual_tiers (
+    id SERIAL PRIMARY KEY,
+    policy_id INTEGER NOT NULL,
+    min_tenure_months INT NOT NULL,
+    days_per_year DECIMAL(5, 2) NOT NULL,
+    FOREIGN KEY (policy_id) REFERENCES leave_accrual_policies(id) ON DELETE CASCADE,
+    UNIQUE(policy_id, min_tenure_months)
+);
+
+CREATE TABLE IF NOT EXISTS leave_ledger_entries (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    leave_type_id INTEGER NOT NULL,
+    year INT NOT NULL,
+    entry_type VARCHAR(20) NOT NULL, -- accrual, deduction, restoration, adjustment, carry_over, expiry
+    days DECIMAL(6, 2) NOT NULL,
+    period_key VARCHAR(30), -- makes scheduled entries idempotent
+    leave_request_id INTEGER,
+    note TEXT,
+    created_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_type_id) REFERENCES leave_types(id) ON DELETE CASCADE,
+    FOREIGN KEY (leave_request_id) REFERENCES leave_requests(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL,
+    UNIQUE(employee_id, leave_type_id, year, entry_type, period_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_ledger_employee ON leave_ledger_entries(tenant_id, employee_id, year);
+
+INSERT INTO leave_ledger_entries (tenant_id, employee_id, leave_type_id, year, entry_type, days, period_key, note)
+SELECT lt.tenant_id, lb.employee_id, lb.leave_type_id, lb.year, 'adjustment', lb.balance_days, 'opening', 'Opening balance'
+FROM leave_balances lb
+JOIN leave_types lt ON lt.id = lb.leave_type_id
+WHERE lb.year IS NOT NULL AND COALESCE(lb.balance_days, 0) <> 0
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS work_location VARCHAR(100);
+
+ALTER TABLE holidays ADD COLUMN IF NOT EXISTS location VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE holidays ADD COLUMN IF NOT EXISTS is_day_off BOOLEAN DEFAULT TRUE;
+ALTER TABLE holidays ADD COLUMN IF NOT EXISTS source VARCHAR(20) DEFAULT 'custom'; -- bundled, ical, custom
+ALTER TABLE holidays ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE holidays DROP CONSTRAINT IF EXISTS holidays_tenant_id_holiday_date_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holidays_tenant_date_location ON holidays(tenant_id, holiday_date, location);
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL UNIQUE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_requests_tenant_dates ON leave_requests(tenant_id, start_date, end_date);
+
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS approver_type VARCHAR(20) DEFAULT 'role';
+ALTER TABLE approval_steps ALTER COLUMN approver_role_id DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    workflow_id INTEGER,
+    entity_type VARCHAR(100) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    requester_id INTEGER NOT NULL,
+    status VARCHAR(20) DEFAULT 'pending', -- pending, approved, rejected, cancelled
+    current_step_order INT,
+    version INT NOT NULL DEFAULT 1,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (workflow_id) REFERENCES approval_workflows(id) ON DELETE SET NULL,
+    FOREIGN KEY (requester_id) REFERENCES employees(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_approval_requests_pending_entity ON approval_requests(tenant_id, entity_type, entity_id) WHERE status = 'pending';
+
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS approval_request_id INTEGER REFERENCES approval_requests(id) ON DELETE CASCADE;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS step_order INT;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP;
+ALTER TABLE approvals ALTER COLUMN approval_step_id DROP NOT NULL;
+ALTER TABLE approvals DROP CONSTRAINT IF EXISTS approvals_approval_step_id_fkey;
+ALTER TABLE approvals ADD CONSTRAINT approvals_approval_step_id_fkey FOREIGN KEY (approval_step_id) REFERENCES approval_steps(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_approvals_request ON approvals(approval_request_id);
+CREATE INDEX IF NOT EXISTS idx_approvals_approver_status ON approvals(approver_id, status);
+
+ALTER TABLE approval_steps DROP CONSTRAINT IF EXISTS approval_steps_workflow_id_step_order_key;
+CREATE INDEX IF NOT EXISTS idx_approval_steps_workflow_order ON approval_steps(workflow_id, step_order);
+
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS conditions JSONB DEFAULT '[]'::jsonb;
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS approval_mode VARCHAR(10) DEFAULT 'any';
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS required_approvals INT;
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS skip_if_requester BOOLEAN DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS approval_delegations (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    delegator_id INTEGER NOT NULL,
+    delegate_id INTEGER NOT NULL,
+    entity_type VARCHAR(100), -- NULL covers every entity type
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason TEXT,
+    created_by INTEGER,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (delegator_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (delegate_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL,
+    CHECK (end_date >= start_date),
+    CHECK (delegator_id <> delegate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_delegations_delegator ON approval_delegations(tenant_id, delegator_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_approval_delegations_delegate ON approval_delegations(tenant_id, delegate_id) WHERE revoked_at IS NULL;
+
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS reminder_hours INT;
+ALTER TABLE approval_steps ADD COLUMN IF NOT EXISTS sla_hours INT;
+
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS remind_at TIMESTAMP;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMP;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS escalate_at TIMESTAMP;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS delegated_from_id INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS escalated_from_id INTEGER REFERENCES approvals(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_approvals_remind_at ON approvals(remind_at) WHERE status = 'pending' AND reminded_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_approvals_escalate_at ON approvals(escalate_at) WHERE status = 'pending';
+
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS category VARCHAR(50) DEFAULT 'general';
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS is_disciplinary BOOLEAN DEFAULT FALSE;
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS requires_acknowledgement BOOLEAN DEFAULT FALSE;
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS status VARCHAR(50) DEFAULT 'active';
+
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS author_id INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS decided_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS decided_at TIMESTAMP;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS decision_comment TEXT;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS cancelled_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS acknowledgement_comment TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_memos_employee ON memos(tenant_id, employee_id, status);
+CREATE INDEX IF NOT EXISTS idx_memos_author ON memos(tenant_id, author_id);
+
+CREATE TABLE IF NOT EXISTS disciplinary_cases (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    stage VARCHAR(50) NOT NULL DEFAULT 'query_issued',
+    status VARCHAR(50) NOT NULL DEFAULT 'open',
+    hearing_at TIMESTAMP,
+    hearing_location VARCHAR(255),
+    outcome VARCHAR(50),
+    outcome_notes TEXT,
+    opened_by INTEGER,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    FOREIGN KEY (opened_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_disciplinary_cases_employee ON disciplinary_cases(tenant_id, employee_id, status);
+
+CREATE TABLE IF NOT EXISTS disciplinary_case_events (
+    id SERIAL PRIMARY KEY,
+    case_id INTEGER NOT NULL,
+    stage VARCHAR(50) NOT NULL,
+    note TEXT,
+    recorded_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (case_id) REFERENCES disciplinary_cases(id) ON DELETE CASCADE,
+    FOREIGN KEY (recorded_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_disciplinary_case_events_case ON disciplinary_case_events(case_id);
+
+ALTER TABLE memo_types ADD COLUMN IF NOT EXISTS validity_months INT;
+
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS case_id INTEGER REFERENCES disciplinary_cases(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_memos_case ON memos(case_id);
+
+ALTER TABLE audit_logs ALTER COLUMN tenant_id DROP NOT NULL;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS request_id VARCHAR(100);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_created ON audit_logs(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs(tenant_id, entity_type, entity_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_role_action ON permissions(role_id, resource, action);
+
+INSERT INTO permissions (role_id, action, resource)
+SELECT r.id, p.action, 'audit_logs'
+FROM roles r
+CROSS JOIN (VALUES ('read'), ('export')) AS p(action)
+WHERE r.name = 'Super Admin'
+ON CONFLICT (role_id, resource, action) DO NOTHING;
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_id ON audit_logs(tenant_id, id);
+
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER,
+    last_log_id INTEGER NOT NULL,
+    last_hash VARCHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    public_key TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_tenant ON audit_checkpoints(tenant_id, last_log_id);
+
+CREATE TABLE IF NOT EXISTS document_types (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    allowed_content_types TEXT[] DEFAULT '{}',
+    max_size_bytes BIGINT NOT NULL,
+    status VARCHAR(50) DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    UNIQUE (tenant_id, name)
+);
+
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS document_type_id INTEGER REFERENCES document_types(id) ON DELETE RESTRICT;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS content_type VARCHAR(255);
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS size_bytes BIGINT;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS checksum VARCHAR(64);
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS uploaded_by INTEGER REFERENCES employees(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_documents_employee ON documents(tenant_id, employee_id);
+
+CREATE TABLE IF NOT EXISTS document_versions (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    document_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    file_path VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    issue_date DATE,
+    expiry_date DATE,
+    uploaded_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE,
+    FOREIGN KEY (uploaded_by) REFERENCES employees(id) ON DELETE SET NULL,
+    UNIQUE (document_id, version)
+);
+
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS current_version INTEGER DEFAULT 1;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS issue_date DATE;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS expiry_date DATE;
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS expiry_reminded_at TIMESTAMP;
+
+ALTER TABLE document_types ADD COLUMN IF NOT EXISTS expiry_reminder_days INTEGER DEFAULT 30;
+
+INSERT INTO document_versions (tenant_id, document_id, version, file_path, file_name, content_type, size_bytes, checksum, uploaded_by, created_at)
+SELECT d.tenant_id, d.id, 1, d.file_path, COALESCE(d.file_name, 'document'), COALESCE(d.content_type, 'application/octet-stream'), COALESCE(d.size_bytes, 0), COALESCE(d.checksum, ''), d.uploaded_by, d.created_at
+FROM documents d
+WHERE d.file_path IS NOT NULL
+ON CONFLICT (document_id, version) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_documents_expiry ON documents(expiry_date) WHERE expiry_date IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS document_requirements (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    document_type_id INTEGER NOT NULL,
+    designation_id INTEGER,
+    country VARCHAR(2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (document_type_id) REFERENCES document_types(id) ON DELETE CASCADE,
+    FOREIGN KEY (designation_id) REFERENCES designations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_requirements_tenant ON document_requirements(tenant_id);
+
+CREATE TABLE IF NOT EXISTS letter_templates (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(50) NOT NULL DEFAULT 'other',
+    document_type_id INTEGER,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(50) DEFAULT 'active',
+    created_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (document_type_id) REFERENCES document_types(id) ON DELETE SET NULL,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL,
+    UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS signature_requests (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    document_id INTEGER NOT NULL,
+    document_version INTEGER NOT NULL,
+    document_checksum VARCHAR(64) NOT NULL,
+    kind VARCHAR(50) NOT NULL DEFAULT 'signature',
+    title VARCHAR(255) NOT NULL,
+    message TEXT,
+    policy_name VARCHAR(100),
+    policy_version VARCHAR(50),
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    sealed_version INTEGER,
+    sealed_checksum VARCHAR(64),
+    created_by INTEGER,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES employees(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_signature_requests_tenant ON signature_requests(tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_signature_requests_policy ON signature_requests(tenant_id, policy_name, policy_version) WHERE policy_name IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS signature_request_signers (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL,
+    request_id INTEGER NOT NULL,
+    employee_id INTEGER NOT NULL,
+    sign_order INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    signature_type VARCHAR(20),
+    typed_name VARCHAR(255),
+    signature_image_key VARCHAR(500),
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(500),
+    decline_reason TEXT,
+    notified_at TIMESTAMP,
+    signed_at TIMESTAMP,
+    declined_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    FOREIGN KEY (request_id) REFERENCES signature_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE CASCADE,
+    UNIQUE (request_id, employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_signature_signers_employee ON signature_request_signers(tenant_id, employee_id, status);
@@ -0,0 +1,213 @@
+package dto
+
+import "time"
+
+// ApprovalWorkflowRequest creates or replaces a workflow. Steps run in the
+// order given, except that a Parallel step runs alongside the step before it;
+// each step's ApproverType is role (which needs ApproverRoleID), manager or
+// department_head. Status is active or inactive and defaults to active.
+type ApprovalWorkflowRequest struct {
+	Name       string                `json:"name" validate:"required"`
+	EntityType string                `json:"entity_type" validate:"required"`
+	Status     string                `json:"status"`
+	Steps      []ApprovalStepRequest `json:"steps" validate:"required"`
+}
+
+// ApprovalStepRequest is one step of a workflow. ApprovalMode is any (the
+// default), all, or quorum, which needs RequiredApprovals. The step is left
+// out for records that do not meet all of its Conditions. Pending approvals
+// are reminded ReminderHours after they are assigned and escalate to the next
+// level after SLAHours; zero turns either off.
+type ApprovalStepRequest struct {
+	ApproverType      string              `json:"approver_type" validate:"required"`
+	ApproverRoleID    *int                `json:"approver_role_id"`
+	Description       string              `json:"description"`
+	Parallel          bool                `json:"parallel"`
+	ApprovalMode      string              `json:"approval_mode"`
+	RequiredApprovals int                 `json:"required_approvals"`
+	SkipIfRequester   bool                `json:"skip_if_requester"`
+	Conditions        []ApprovalCondition `json:"conditions"`
+	ReminderHours     int                 `json:"reminder_hours"`
+	SLAHours          int                 `json:"sla_hours"`
+}
+
+// ApprovalCondition compares a field of the record, such as days_requested,
+// with Value. Operator is one of eq, ne, gt, gte, lt, lte, in and not_in; in
+// and not_in take a list of values.
+type ApprovalCondition struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+type ApprovalWorkflowResponse struct {
+	ID         int                    `json:"id"`
+	Name       string                 `json:"name"`
+	EntityType string                 `json:"entity_type"`
+	Status     string                 `json:"status"`
+	Steps      []ApprovalStepResponse `json:"steps"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+type ApprovalStepResponse struct {
+	ID                int                 `json:"id"`
+	StepOrder         int                 `json:"step_order"`
+	ApproverType      string              `json:"approver_type"`
+	ApproverRoleID    *int                `json:"approver_role_id,omitempty"`
+	ApproverRole      string              `json:"approver_role,omitempty"`
+	Description       string              `json:"description"`
+	ApprovalMode      string              `json:"approval_mode"`
+	RequiredApprovals int                 `json:"required_approvals,omitempty"`
+	SkipIfRequester   bool                `json:"skip_if_requester"`
+	Conditions        []ApprovalCondition `json:"conditions"`
+	ReminderHours     int                 `json:"reminder_hours,omitempty"`
+	SLAHours          int                 `json:"sla_hours,omitempty"`
+}
+
+// ApprovalDecisionRequest decides an approval request. When Version is set
+// the decision is refused with a conflict unless the request is still at
+// that version, so a decision is never based on a stale view.
+type ApprovalDecisionRequest struct {
+	Comment string `json:"comment"`
+	Version int    `json:"version"`
+}
+
+// ApprovalInboxFilter narrows the caller's pending approvals. AssignedVia is
+// direct, role or delegation, Overdue keeps approvals past their SLA, and
+// From and To (YYYY-MM-DD) bound the day each approval was assigned.
+type ApprovalInboxFilter struct {
+	EntityType  string
+	RequesterID int
+	AssignedVia string
+	Overdue     bool
+	From        string
+	To          string
+}
+
+// ApprovalInboxResponse lists the filtered items. Counts cover every pending
+// item, whatever the filter.
+type ApprovalInboxResponse struct {
+	Items  []ApprovalInboxItemResponse `json:"items"`
+	Counts ApprovalInboxCounts         `json:"counts"`
+}
+
+type ApprovalInboxCounts struct {
+	Total        int            `json:"total"`
+	Overdue      int            `json:"overdue"`
+	ByEntityType map[string]int `json:"by_entity_type"`
+}
+
+// ApprovalInboxItemResponse is one approval waiting on the caller. Version
+// is the request's version, to send back with the decision. Details holds
+// the fields of the record approval steps can test, such as days_requested.
+type ApprovalInboxItemResponse struct {
+	ApprovalID        int                    `json:"approval_id"`
+	RequestID         int                    `json:"request_id"`
+	Version           int                    `json:"version"`
+	EntityType        string                 `json:"entity_type"`
+	EntityID          int                    `json:"entity_id"`
+	RequesterID       int                    `json:"requester_id"`
+	RequesterName     string                 `json:"requester_name"`
+	StepOrder         int                    `json:"step_order"`
+	StepDescription   string                 `json:"step_description"`
+	ApprovalMode      string                 `json:"approval_mode"`
+	AssignedVia       string                 `json:"assigned_via"`
+	DelegatedFromID   *int                   `json:"delegated_from_id,omitempty"`
+	DelegatedFromName string                 `json:"delegated_from_name,omitempty"`
+	Escalated         bool                   `json:"escalated"`
+	Overdue           bool                   `json:"overdue"`
+	EscalateAt        *time.Time             `json:"escalate_at"`
+	AssignedAt        time.Time              `json:"assigned_at"`
+	SubmittedAt       time.Time              `json:"submitted_at"`
+	Details           map[string]interface{} `json:"details"`
+}
+
+// ApprovalBulkDecisionRequest approves or rejects several approval requests
+// with the same comment. Action is approve or reject, and each item carries
+// the version of the request the caller saw.
+type ApprovalBulkDecisionRequest struct {
+	Action  string                     `json:"action" validate:"required"`
+	Comment string                     `json:"comment"`
+	Items   []ApprovalBulkDecisionItem `json:"items" validate:"required"`
+}
+
+type ApprovalBulkDecisionItem struct {
+	RequestID int `json:"request_id"`
+	Version   int `json:"version"`
+}
+
+// ApprovalBulkDecisionResponse reports each item on its own, since one item
+// failing does not stop the others
+type ApprovalBulkDecisionResponse struct {
+	Succeeded int                          `json:"succeeded"`
+	Failed    int                          `json:"failed"`
+	Results   []ApprovalBulkDecisionResult `json:"results"`
+}
+
+type ApprovalBulkDecisionResult struct {
+	RequestID int    `json:"request_id"`
+	Success   bool   `json:"success"`
+	Status    string `json:"status,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ApprovalRequestResponse struct {
+	ID               int                `json:"id"`
+	WorkflowID       *int               `json:"workflow_id"`
+	EntityType       string             `json:"entity_type"`
+	EntityID         int                `json:"entity_id"`
+	RequesterID      int                `json:"requester_id"`
+	RequesterName    string             `json:"requester_name"`
+	Status           string             `json:"status"`
+	CurrentStepOrder int                `json:"current_step_order"`
+	Version          int                `json:"version"`
+	Approvals        []ApprovalResponse `json:"approvals"`
+	CompletedAt      *time.Time         `json:"completed_at"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+type ApprovalResponse struct {
+	ID              int        `json:"id"`
+	StepOrder       int        `json:"step_order"`
+	ApproverID      int        `json:"approver_id"`
+	ApproverName    string     `json:"approver_name"`
+	Status          string     `json:"status"`
+	Comments        string     `json:"comments"`
+	DelegatedFromID *int       `json:"delegated_from_id,omitempty"`
+	EscalatedFromID *int       `json:"escalated_from_id,omitempty"`
+	EscalateAt      *time.Time `json:"escalate_at,omitempty"`
+	DecidedAt       *time.Time `json:"decided_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// ApprovalDelegationRequest passes the delegator's approvals to DelegateID
+// from StartDate to EndDate (YYYY-MM-DD). DelegatorID defaults to the caller
+// and only HR may set it to someone else. An empty EntityType delegates
+// approvals of every entity type.
+type ApprovalDelegationRequest struct {
+	DelegatorID *int   `json:"delegator_id"`
+	DelegateID  int    `json:"delegate_id" validate:"required"`
+	EntityType  string `json:"entity_type"`
+	StartDate   string `json:"start_date" validate:"required"`
+	EndDate     string `json:"end_date" validate:"required"`
+	Reason      string `json:"reason"`
+}
+
+type ApprovalDelegationResponse struct {
+	ID            int        `json:"id"`
+	DelegatorID   int        `json:"delegator_id"`
+	DelegatorName string     `json:"delegator_name"`
+	DelegateID    int        `json:"delegate_id"`
+	DelegateName  string     `json:"delegate_name"`
+	EntityType    string     `json:"entity_type,omitempty"`
+	StartDate     time.Time  `json:"start_date"`
+	EndDate       time.Time  `json:"end_date"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status"`
+	CreatedBy     *int       `json:"created_by"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,89 @@
+package dto
+
+import "time"
+
+// AuditLogFilter narrows the audit log. From and To take an RFC 3339 time or
+// a YYYY-MM-DD date, To including the whole day. Cursor is the next_cursor of
+// the previous page.
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   int
+	ActorID    int
+	Action     string
+	From       string
+	To         string
+	Cursor     string
+	Limit      int
+}
+
+type AuditLogResponse struct {
+	ID         int                    `json:"id"`
+	ActorID    *int                   `json:"actor_id"`
+	ActorName  string                 `json:"actor_name,omitempty"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   int                    `json:"entity_id"`
+	Action     string                 `json:"action"`
+	OldData    map[string]interface{} `json:"old_data,omitempty"`
+	NewData    map[string]interface{} `json:"new_data,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	IPAddress  string                 `json:"ip_address,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// AuditLogPage is one page of the audit log, newest first. NextCursor is
+// empty on the last page.
+type AuditLogPage struct {
+	Items      []*AuditLogResponse `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// AuditFieldChange is one field that differs between the old and new data of
+// an audit row. Old is absent for created fields and New for removed ones.
+type AuditFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+type AuditTimelineEntry struct {
+	ID        int                `json:"id"`
+	ActorID   *int               `json:"actor_id"`
+	ActorName string             `json:"actor_name,omitempty"`
+	Action    string             `json:"action"`
+	Changes   []AuditFieldChange `json:"changes"`
+	RequestID string             `json:"request_id,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// AuditTimelineResponse is the history of one entity, oldest first
+type AuditTimelineResponse struct {
+	EntityType string                `json:"entity_type"`
+	EntityID   int                   `json:"entity_id"`
+	Entries    []*AuditTimelineEntry `json:"entries"`
+}
+
+// AuditChainBreak is the first place a tenant's audit chain fails to verify.
+// LogID is the audit row at fault, or CheckpointID the checkpoint that no
+// longer matches the chain.
+type AuditChainBreak struct {
+	LogID        int    `json:"log_id,omitempty"`
+	CheckpointID int    `json:"checkpoint_id,omitempty"`
+	Reason       string `json:"reason"`
+	ExpectedHash string `json:"expected_hash,omitempty"`
+	ActualHash   string `json:"actual_hash,omitempty"`
+}
+
+// AuditChainVerification is the result of walking a tenant's audit chain.
+// UnchainedRows are rows written before the chain existed, which cannot be
+// verified. SignaturesVerified is false when no signing key is configured.
+type AuditChainVerification struct {
+	TenantID           int              `json:"tenant_id"`
+	Valid              bool             `json:"valid"`
+	CheckedRows        int              `json:"checked_rows"`
+	UnchainedRows      int              `json:"unchained_rows"`
+	LastLogID          int              `json:"last_log_id,omitempty"`
+	LastHash           string           `json:"last_hash,omitempty"`
+	CheckedCheckpoints int              `json:"checked_checkpoints"`
+	SignaturesVerified bool             `json:"signatures_verified"`
+	BrokenLink         *AuditChainBreak `json:"broken_link,omitempty"`
+}
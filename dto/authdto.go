@@ -3,6 +3,7 @@ package dto
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	OTPCode  string `json:"otp_code,omitempty"`
 }
 
 type LoginResponse struct {
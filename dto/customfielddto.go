@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type CustomFieldRules struct {
+	MinLength *int     `json:"min_length,omitempty"`
+	MaxLength *int     `json:"max_length,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	MinDate   string   `json:"min_date,omitempty"`
+	MaxDate   string   `json:"max_date,omitempty"`
+}
+
+type CustomFieldRequest struct {
+	Key        string           `json:"key" validate:"required"`
+	Label      string           `json:"label" validate:"required"`
+	FieldType  string           `json:"field_type" validate:"required"`
+	Required   bool             `json:"required"`
+	Options    []string         `json:"options"`
+	Rules      CustomFieldRules `json:"rules"`
+	Visibility string           `json:"visibility"`
+	SortOrder  int              `json:"sort_order"`
+}
+
+type CustomFieldResponse struct {
+	ID         int              `json:"id"`
+	Key        string           `json:"key"`
+	Label      string           `json:"label"`
+	FieldType  string           `json:"field_type"`
+	Required   bool             `json:"required"`
+	Options    []string         `json:"options,omitempty"`
+	Rules      CustomFieldRules `json:"rules"`
+	Visibility string           `json:"visibility"`
+	SortOrder  int              `json:"sort_order"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
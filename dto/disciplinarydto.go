@@ -0,0 +1,74 @@
+package dto
+
+import "time"
+
+// OpenDisciplinaryCaseRequest opens a case about an employee at the query
+// stage, linking any memos already written about it
+type OpenDisciplinaryCaseRequest struct {
+	EmployeeID  int    `json:"employee_id" validate:"required"`
+	Title       string `json:"title" validate:"required"`
+	Description string `json:"description"`
+	MemoIDs     []int  `json:"memo_ids"`
+}
+
+// DisciplinaryStageRequest moves a case on to Stage. A hearing needs
+// HearingAt in RFC 3339 format and the outcome stage needs Outcome, which
+// closes the case.
+type DisciplinaryStageRequest struct {
+	Stage           string `json:"stage" validate:"required"`
+	Note            string `json:"note"`
+	HearingAt       string `json:"hearing_at"`
+	HearingLocation string `json:"hearing_location"`
+	Outcome         string `json:"outcome"`
+	OutcomeNotes    string `json:"outcome_notes"`
+}
+
+type LinkCaseMemoRequest struct {
+	MemoID int `json:"memo_id" validate:"required"`
+}
+
+type DisciplinaryCaseFilter struct {
+	EmployeeID int
+	Status     string
+	Stage      string
+}
+
+type DisciplinaryCaseEventResponse struct {
+	ID             int       `json:"id"`
+	Stage          string    `json:"stage"`
+	Note           string    `json:"note,omitempty"`
+	RecordedBy     *int      `json:"recorded_by,omitempty"`
+	RecordedByName string    `json:"recorded_by_name,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type DisciplinaryCaseResponse struct {
+	ID              int                             `json:"id"`
+	EmployeeID      int                             `json:"employee_id"`
+	EmployeeName    string                          `json:"employee_name"`
+	Title           string                          `json:"title"`
+	Description     string                          `json:"description,omitempty"`
+	Stage           string                          `json:"stage"`
+	Status          string                          `json:"status"`
+	HearingAt       *time.Time                      `json:"hearing_at,omitempty"`
+	HearingLocation string                          `json:"hearing_location,omitempty"`
+	Outcome         string                          `json:"outcome,omitempty"`
+	OutcomeNotes    string                          `json:"outcome_notes,omitempty"`
+	OpenedBy        *int                            `json:"opened_by,omitempty"`
+	ClosedAt        *time.Time                      `json:"closed_at,omitempty"`
+	Events          []DisciplinaryCaseEventResponse `json:"events,omitempty"`
+	Memos           []*MemoResponse                 `json:"memos,omitempty"`
+	CreatedAt       time.Time                       `json:"created_at"`
+	UpdatedAt       time.Time                       `json:"updated_at"`
+}
+
+// DisciplinarySummaryResponse is what HR reviews before a termination: the
+// employee's warnings that have not lapsed, how many have, and their open
+// cases
+type DisciplinarySummaryResponse struct {
+	EmployeeID     int                         `json:"employee_id"`
+	EmployeeName   string                      `json:"employee_name"`
+	ActiveWarnings []*MemoResponse             `json:"active_warnings"`
+	LapsedWarnings int                         `json:"lapsed_warnings"`
+	OpenCases      []*DisciplinaryCaseResponse `json:"open_cases"`
+}
@@ -0,0 +1,112 @@
+package dto
+
+import "time"
+
+// DocumentTypeRequest creates or replaces a document type.
+// AllowedContentTypes lists the media types accepted, such as
+// application/pdf; an empty list accepts any. MaxSizeBytes defaults to 10 MB.
+// Documents are flagged, and their employee and HR reminded,
+// ExpiryReminderDays before they expire; it defaults to 30 and 0 turns
+// reminders off.
+type DocumentTypeRequest struct {
+	Name                string   `json:"name" validate:"required"`
+	Description         string   `json:"description"`
+	AllowedContentTypes []string `json:"allowed_content_types"`
+	MaxSizeBytes        int64    `json:"max_size_bytes"`
+	ExpiryReminderDays  *int     `json:"expiry_reminder_days"`
+	Status              string   `json:"status"`
+}
+
+type DocumentTypeResponse struct {
+	ID                  int       `json:"id"`
+	Name                string    `json:"name"`
+	Description         string    `json:"description,omitempty"`
+	AllowedContentTypes []string  `json:"allowed_content_types"`
+	MaxSizeBytes        int64     `json:"max_size_bytes"`
+	ExpiryReminderDays  int       `json:"expiry_reminder_days"`
+	Status              string    `json:"status"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// DocumentUpload describes an uploaded file, read from a multipart form.
+// IssueDate and ExpiryDate are optional YYYY-MM-DD dates. DocumentTypeID is
+// ignored for a new version, which keeps its document's type.
+type DocumentUpload struct {
+	DocumentTypeID int
+	FileName       string
+	ContentType    string
+	Size           int64
+	IssueDate      string
+	ExpiryDate     string
+}
+
+// DocumentResponse describes a stored document and its current version.
+// Checksum is the hex SHA-256 of its content. ExpiryStatus is valid, expiring
+// or expired, and empty for documents that do not expire.
+type DocumentResponse struct {
+	ID             int        `json:"id"`
+	EmployeeID     *int       `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name,omitempty"`
+	DocumentTypeID *int       `json:"document_type_id"`
+	DocumentType   string     `json:"document_type"`
+	Version        int        `json:"version"`
+	IssueDate      *time.Time `json:"issue_date"`
+	ExpiryDate     *time.Time `json:"expiry_date"`
+	ExpiryStatus   string     `json:"expiry_status,omitempty"`
+	FileName       string     `json:"file_name"`
+	ContentType    string     `json:"content_type"`
+	SizeBytes      int64      `json:"size_bytes"`
+	Checksum       string     `json:"checksum"`
+	UploadedBy     *int       `json:"uploaded_by"`
+	UploadedByName string     `json:"uploaded_by_name,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type DocumentVersionResponse struct {
+	Version        int        `json:"version"`
+	FileName       string     `json:"file_name"`
+	ContentType    string     `json:"content_type"`
+	SizeBytes      int64      `json:"size_bytes"`
+	Checksum       string     `json:"checksum"`
+	IssueDate      *time.Time `json:"issue_date"`
+	ExpiryDate     *time.Time `json:"expiry_date"`
+	UploadedBy     *int       `json:"uploaded_by"`
+	UploadedByName string     `json:"uploaded_by_name,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// DocumentRequirementRequest makes a document type mandatory for employees
+// of DesignationID in Country, an ISO 3166-1 alpha-2 code or English name.
+// Either may be left out to match every designation or country.
+type DocumentRequirementRequest struct {
+	DocumentTypeID int    `json:"document_type_id" validate:"required"`
+	DesignationID  *int   `json:"designation_id"`
+	Country        string `json:"country"`
+}
+
+type DocumentRequirementResponse struct {
+	ID              int       `json:"id"`
+	DocumentTypeID  int       `json:"document_type_id"`
+	DocumentType    string    `json:"document_type"`
+	DesignationID   *int      `json:"designation_id"`
+	DesignationName string    `json:"designation_name,omitempty"`
+	Country         string    `json:"country,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// MissingDocument is a mandatory document type an employee does not hold.
+// Reason is missing, or expired when they only hold expired copies.
+type MissingDocument struct {
+	DocumentTypeID int    `json:"document_type_id"`
+	DocumentType   string `json:"document_type"`
+	Reason         string `json:"reason"`
+}
+
+type MissingDocumentsResponse struct {
+	EmployeeID    int               `json:"employee_id"`
+	EmployeeName  string            `json:"employee_name"`
+	DesignationID int               `json:"designation_id"`
+	Country       string            `json:"country,omitempty"`
+	Missing       []MissingDocument `json:"missing"`
+}
@@ -1,18 +1,64 @@
 package dto
 
+import "time"
+
 type CreateEmployeeRequest struct {
-	Email         string `json:"email" validate:"required,email"`
-	FirstName     string `json:"first_name" validate:"required"`
-	LastName      string `json:"last_name"`
-	Password      string `json:"password" validate:"required,min=8"`
-	DepartmentID  int    `json:"department_id" validate:"required"`
-	DesignationID int    `json:"designation_id" validate:"required"`
-	RoleID        int    `json:"role_id" validate:"required"`
+	Email          string `json:"email" validate:"required,email"`
+	FirstName      string `json:"first_name" validate:"required"`
+	LastName       string `json:"last_name"`
+	Password       string `json:"password" validate:"required_without=SendInvitation,omitempty,min=8"`
+	DepartmentID   int    `json:"department_id" validate:"required"`
+	DesignationID  int    `json:"designation_id" validate:"required"`
+	RoleID         int    `json:"role_id" validate:"required"`
+	SendInvitation bool   `json:"send_invitation"`
+	EmploymentType string `json:"employment_type"`
+
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+// UpdateEmployeeRequest changes an employee's basic details. Fields left nil
+// are unchanged; a custom field set to null is cleared.
+type UpdateEmployeeRequest struct {
+	FirstName    *string                `json:"first_name"`
+	LastName     *string                `json:"last_name"`
+	Phone        *string                `json:"phone"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
 }
 
 type EmployeeResponse struct {
-	ID    int    `json:"id"`
-	Email string `json:"email"`
-	Name  string `json:"name"`
-	Role  string `json:"role"`
+	ID           int    `json:"id"`
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	Role         string `json:"role"`
+	Status       string `json:"status,omitempty"`
+	InvitationID int    `json:"invitation_id,omitempty"`
+
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+}
+
+type EmployeeFilter struct {
+	Status        string `json:"status,omitempty"`
+	DepartmentID  int    `json:"department_id,omitempty"`
+	DesignationID int    `json:"designation_id,omitempty"`
+	ManagerID     int    `json:"manager_id,omitempty"`
+	Search        string `json:"search,omitempty"`
+
+	// CustomFields matches employees whose custom field value equals the
+	// given string, keyed by field key
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+}
+
+type EmployeeSummaryResponse struct {
+	ID            int        `json:"id"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	Email         string     `json:"email"`
+	Phone         string     `json:"phone"`
+	DepartmentID  int        `json:"department_id"`
+	DesignationID int        `json:"designation_id"`
+	ManagerID     *int       `json:"manager_id"`
+	Status        string     `json:"status"`
+	HireDate      *time.Time `json:"hire_date"`
+
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
 }
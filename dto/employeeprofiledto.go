@@ -0,0 +1,90 @@
+package dto
+
+import "time"
+
+type PersonalDetailsRequest struct {
+	DateOfBirth   *string `json:"date_of_birth"`
+	Gender        string  `json:"gender"`
+	Nationality   string  `json:"nationality"`
+	MaritalStatus string  `json:"marital_status"`
+	NationalID    string  `json:"national_id"`
+	TaxNumber     string  `json:"tax_number"`
+}
+
+type PersonalDetailsResponse struct {
+	EmployeeID    int        `json:"employee_id"`
+	DateOfBirth   *time.Time `json:"date_of_birth,omitempty"`
+	Gender        string     `json:"gender"`
+	Nationality   string     `json:"nationality"`
+	MaritalStatus string     `json:"marital_status,omitempty"`
+	NationalID    string     `json:"national_id,omitempty"`
+	TaxNumber     string     `json:"tax_number,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type AddressRequest struct {
+	AddressType string `json:"address_type"`
+	Line1       string `json:"line1" validate:"required"`
+	Line2       string `json:"line2"`
+	City        string `json:"city" validate:"required"`
+	State       string `json:"state"`
+	PostalCode  string `json:"postal_code"`
+	Country     string `json:"country"`
+	ValidFrom   string `json:"valid_from"`
+}
+
+type AddressResponse struct {
+	ID          int        `json:"id"`
+	EmployeeID  int        `json:"employee_id"`
+	AddressType string     `json:"address_type"`
+	Line1       string     `json:"line1"`
+	Line2       string     `json:"line2,omitempty"`
+	City        string     `json:"city"`
+	State       string     `json:"state,omitempty"`
+	PostalCode  string     `json:"postal_code,omitempty"`
+	Country     string     `json:"country"`
+	ValidFrom   time.Time  `json:"valid_from"`
+	ValidTo     *time.Time `json:"valid_to"`
+	IsCurrent   bool       `json:"is_current"`
+}
+
+type EmergencyContactRequest struct {
+	Name         string `json:"name" validate:"required"`
+	Relationship string `json:"relationship" validate:"required"`
+	Phone        string `json:"phone" validate:"required"`
+	Email        string `json:"email"`
+	IsPrimary    bool   `json:"is_primary"`
+}
+
+type EmergencyContactResponse struct {
+	ID           int    `json:"id"`
+	EmployeeID   int    `json:"employee_id"`
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email,omitempty"`
+	IsPrimary    bool   `json:"is_primary"`
+}
+
+type BankAccountRequest struct {
+	BankName      string `json:"bank_name" validate:"required"`
+	AccountName   string `json:"account_name" validate:"required"`
+	AccountNumber string `json:"account_number"`
+	BankCode      string `json:"bank_code"`
+	IBAN          string `json:"iban"`
+	Currency      string `json:"currency"`
+	IsPrimary     bool   `json:"is_primary"`
+}
+
+type BankAccountResponse struct {
+	ID            int       `json:"id"`
+	EmployeeID    int       `json:"employee_id"`
+	BankName      string    `json:"bank_name"`
+	AccountName   string    `json:"account_name"`
+	AccountNumber string    `json:"account_number,omitempty"`
+	BankCode      string    `json:"bank_code,omitempty"`
+	IBAN          string    `json:"iban,omitempty"`
+	Currency      string    `json:"currency,omitempty"`
+	IsPrimary     bool      `json:"is_primary"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// EmploymentChangeRequest schedules a change to an employee's job. Only the
+// fields that are set change; ClearManager removes the reporting manager.
+type EmploymentChangeRequest struct {
+	ChangeType     string   `json:"change_type" validate:"required"`
+	EffectiveDate  string   `json:"effective_date" validate:"required"`
+	DepartmentID   *int     `json:"department_id"`
+	DesignationID  *int     `json:"designation_id"`
+	ManagerID      *int     `json:"manager_id"`
+	ClearManager   bool     `json:"clear_manager"`
+	EmploymentType *string  `json:"employment_type"`
+	Salary         *float64 `json:"salary"`
+	SalaryCurrency *string  `json:"salary_currency"`
+	Reason         string   `json:"reason"`
+}
+
+type EmploymentHistoryResponse struct {
+	ID             int        `json:"id"`
+	EmployeeID     int        `json:"employee_id"`
+	ChangeType     string     `json:"change_type"`
+	EffectiveDate  time.Time  `json:"effective_date"`
+	ChangedFields  []string   `json:"changed_fields"`
+	DepartmentID   *int       `json:"department_id,omitempty"`
+	DesignationID  *int       `json:"designation_id,omitempty"`
+	ManagerID      *int       `json:"manager_id,omitempty"`
+	EmploymentType string     `json:"employment_type,omitempty"`
+	Salary         *float64   `json:"salary,omitempty"`
+	SalaryCurrency string     `json:"salary_currency,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	Status         string     `json:"status"`
+	CreatedBy      *int       `json:"created_by"`
+	AppliedAt      *time.Time `json:"applied_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// EmploymentStateResponse is an employee's job as it stood on a given date
+type EmploymentStateResponse struct {
+	EmployeeID     int      `json:"employee_id"`
+	FirstName      string   `json:"first_name"`
+	LastName       string   `json:"last_name"`
+	AsOf           string   `json:"as_of"`
+	DepartmentID   int      `json:"department_id"`
+	DesignationID  int      `json:"designation_id"`
+	ManagerID      *int     `json:"manager_id"`
+	EmploymentType string   `json:"employment_type"`
+	Salary         *float64 `json:"salary,omitempty"`
+	SalaryCurrency string   `json:"salary_currency,omitempty"`
+}
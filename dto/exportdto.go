@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type EmployeeExportRequest struct {
+	Format  string         `json:"format" validate:"required"`
+	Columns []string       `json:"columns"`
+	Filter  EmployeeFilter `json:"filter"`
+}
+
+type ExportJobResponse struct {
+	ID          int        `json:"id"`
+	EntityType  string     `json:"entity_type"`
+	Format      string     `json:"format"`
+	Columns     []string   `json:"columns"`
+	Status      string     `json:"status"`
+	FileName    string     `json:"file_name,omitempty"`
+	RowCount    int        `json:"row_count"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// HolidayRequest adds or changes a holiday. An empty location applies it to
+// the whole tenant; with a location it overrides the tenant's holiday on that
+// date, and IsDayOff false keeps the location working. IsDayOff defaults to
+// true.
+type HolidayRequest struct {
+	Date     string `json:"date" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	Location string `json:"location"`
+	IsDayOff *bool  `json:"is_day_off"`
+}
+
+type HolidayResponse struct {
+	ID        int       `json:"id"`
+	Date      time.Time `json:"date"`
+	Name      string    `json:"name"`
+	Location  string    `json:"location"`
+	IsDayOff  bool      `json:"is_day_off"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoadHolidaysRequest loads a country's bundled public holidays for a year.
+// Country defaults to the company's country and Year to the current year.
+type LoadHolidaysRequest struct {
+	Country  string `json:"country"`
+	Year     int    `json:"year"`
+	Location string `json:"location"`
+}
+
+// HolidayImportResponse counts the holidays added by a load or import;
+// dates that already had a holiday are skipped.
+type HolidayImportResponse struct {
+	Added   int `json:"added"`
+	Skipped int `json:"skipped"`
+}
+
+type WorkLocationRequest struct {
+	Location string `json:"location"`
+}
+
+type WorkLocationResponse struct {
+	EmployeeID int    `json:"employee_id"`
+	Location   string `json:"location"`
+}
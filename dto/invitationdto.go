@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// AcceptInvitationRequest accepts an invitation. Enabling MFA needs the
+// secret from the MFA setup step and a current code from the authenticator
+// app it was added to.
+type AcceptInvitationRequest struct {
+	Token     string `json:"token" validate:"required"`
+	Password  string `json:"password" validate:"required,min=8"`
+	EnableMFA bool   `json:"enable_mfa"`
+	MFACode   string `json:"mfa_code"`
+}
+
+type AcceptInvitationResponse struct {
+	EmployeeID int    `json:"employee_id"`
+	Email      string `json:"email"`
+	MFAEnabled bool   `json:"mfa_enabled"`
+}
+
+type InvitationMFARequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type InvitationMFAResponse struct {
+	MFASecret          string `json:"mfa_secret"`
+	MFAProvisioningURI string `json:"mfa_provisioning_uri"`
+}
+
+type InvitationDetailsResponse struct {
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type InvitationResponse struct {
+	ID         int        `json:"id"`
+	EmployeeID int        `json:"employee_id"`
+	Status     string     `json:"status"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentCount  int        `json:"sent_count"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
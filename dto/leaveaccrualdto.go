@@ -0,0 +1,86 @@
+package dto
+
+import "time"
+
+// LeaveAccrualPolicyRequest replaces how a leave type is earned. Frequency is
+// annual, monthly or pay_period; PayPeriodsPerYear is required for
+// pay_period. ProrateFirstYear defaults to true. Without tiers the leave
+// type's days per year apply; a nil CarryOverMaxDays carries the whole balance
+// into the next leave year and a nil CarryOverExpiryMonths keeps carried days
+// for the whole year.
+type LeaveAccrualPolicyRequest struct {
+	Frequency             string             `json:"frequency" validate:"required"`
+	PayPeriodsPerYear     int                `json:"pay_periods_per_year"`
+	ProrateFirstYear      *bool              `json:"prorate_first_year"`
+	CarryOverMaxDays      *float64           `json:"carry_over_max_days"`
+	CarryOverExpiryMonths *int               `json:"carry_over_expiry_months"`
+	Tiers                 []LeaveAccrualTier `json:"tiers"`
+}
+
+type LeaveAccrualTier struct {
+	MinTenureMonths int     `json:"min_tenure_months"`
+	DaysPerYear     float64 `json:"days_per_year"`
+}
+
+type LeaveAccrualPolicyResponse struct {
+	ID                    int                `json:"id"`
+	LeaveTypeID           int                `json:"leave_type_id"`
+	Frequency             string             `json:"frequency"`
+	PayPeriodsPerYear     int                `json:"pay_periods_per_year,omitempty"`
+	ProrateFirstYear      bool               `json:"prorate_first_year"`
+	CarryOverMaxDays      *float64           `json:"carry_over_max_days"`
+	CarryOverExpiryMonths *int               `json:"carry_over_expiry_months"`
+	Tiers                 []LeaveAccrualTier `json:"tiers"`
+	CreatedAt             time.Time          `json:"created_at"`
+	UpdatedAt             time.Time          `json:"updated_at"`
+}
+
+type LeaveYearRequest struct {
+	StartMonth int `json:"start_month" validate:"required"`
+}
+
+type LeaveYearResponse struct {
+	StartMonth  int    `json:"start_month"`
+	CurrentYear int    `json:"current_year"`
+	StartsOn    string `json:"starts_on"`
+	EndsOn      string `json:"ends_on"`
+}
+
+// LeaveAdjustmentRequest adds days to (or, when negative, removes days from)
+// an employee's balance. Year defaults to the current leave year.
+type LeaveAdjustmentRequest struct {
+	EmployeeID  int     `json:"employee_id" validate:"required"`
+	LeaveTypeID int     `json:"leave_type_id" validate:"required"`
+	Year        int     `json:"year"`
+	Days        float64 `json:"days" validate:"required"`
+	Note        string  `json:"note" validate:"required"`
+}
+
+type LeaveLedgerEntryResponse struct {
+	ID             int       `json:"id"`
+	LeaveTypeID    int       `json:"leave_type_id"`
+	Year           int       `json:"year"`
+	EntryType      string    `json:"entry_type"`
+	Days           float64   `json:"days"`
+	PeriodKey      string    `json:"period_key,omitempty"`
+	LeaveRequestID *int      `json:"leave_request_id,omitempty"`
+	Note           string    `json:"note,omitempty"`
+	CreatedBy      *int      `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type LeaveBalanceResponse struct {
+	LeaveTypeID   int     `json:"leave_type_id"`
+	LeaveType     string  `json:"leave_type"`
+	Year          int     `json:"year"`
+	BalanceDays   float64 `json:"balance_days"`
+	PendingDays   float64 `json:"pending_days"`
+	AvailableDays float64 `json:"available_days"`
+}
+
+// LeaveAccrualRunResponse counts the ledger entries an accrual run added
+type LeaveAccrualRunResponse struct {
+	Accruals   int `json:"accruals"`
+	CarryOvers int `json:"carry_overs"`
+	Expiries   int `json:"expiries"`
+}
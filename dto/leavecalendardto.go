@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// LeaveCalendarFilter narrows the team leave calendar. From defaults to today
+// and To to four weeks after From. A non-zero ManagerID shows that manager's
+// reporting subtree; Status is approved or pending and defaults to both.
+type LeaveCalendarFilter struct {
+	From         string
+	To           string
+	DepartmentID int
+	ManagerID    int
+	Status       string
+}
+
+type LeaveCalendarEntryResponse struct {
+	LeaveRequestID int       `json:"leave_request_id"`
+	EmployeeID     int       `json:"employee_id"`
+	EmployeeName   string    `json:"employee_name"`
+	DepartmentID   int       `json:"department_id"`
+	LeaveType      string    `json:"leave_type"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	DaysRequested  float64   `json:"days_requested"`
+	HalfDay        bool      `json:"half_day"`
+	Status         string    `json:"status"`
+}
+
+// CalendarFeedResponse holds a new subscription link. The token is only
+// shown once; creating another feed replaces it.
+type CalendarFeedResponse struct {
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+// CreateLeaveRequestRequest asks for leave between two dates inclusive. The
+// number of days is worked out by the server from the work week and holidays.
+type CreateLeaveRequestRequest struct {
+	LeaveTypeID   int    `json:"leave_type_id" validate:"required"`
+	StartDate     string `json:"start_date" validate:"required"`
+	EndDate       string `json:"end_date" validate:"required"`
+	HalfDay       bool   `json:"half_day"`
+	Reason        string `json:"reason"`
+	AttachmentURL string `json:"attachment_url"`
+}
+
+type LeaveDecisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+type CancelLeaveRequestRequest struct {
+	Reason string `json:"reason"`
+}
+
+type LeaveRequestFilter struct {
+	Status     string
+	EmployeeID int
+}
+
+type LeaveRequestResponse struct {
+	ID                 int        `json:"id"`
+	EmployeeID         int        `json:"employee_id"`
+	EmployeeName       string     `json:"employee_name"`
+	LeaveTypeID        int        `json:"leave_type_id"`
+	LeaveType          string     `json:"leave_type"`
+	StartDate          time.Time  `json:"start_date"`
+	EndDate            time.Time  `json:"end_date"`
+	LeaveYear          int        `json:"leave_year"`
+	DaysRequested      float64    `json:"days_requested"`
+	HalfDay            bool       `json:"half_day"`
+	Reason             string     `json:"reason,omitempty"`
+	AttachmentURL      string     `json:"attachment_url,omitempty"`
+	Status             string     `json:"status"`
+	ApprovalWorkflowID *int       `json:"approval_workflow_id,omitempty"`
+	DecidedBy          *int       `json:"decided_by,omitempty"`
+	DecidedAt          *time.Time `json:"decided_at,omitempty"`
+	DecisionComment    string     `json:"decision_comment,omitempty"`
+	CancelledBy        *int       `json:"cancelled_by,omitempty"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason string     `json:"cancellation_reason,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// WorkWeekRequest lists the days the company works, e.g. "monday"
+type WorkWeekRequest struct {
+	WorkDays []string `json:"work_days" validate:"required"`
+}
+
+type WorkWeekResponse struct {
+	WorkDays []string `json:"work_days"`
+}
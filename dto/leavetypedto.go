@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+// LeaveTypeRequest creates or replaces a leave type. IsPaid defaults to true
+// when omitted; a nil MaxConsecutiveDays means there is no limit and an empty
+// EligibleGender means every employee may take the leave.
+type LeaveTypeRequest struct {
+	Name               string `json:"name" validate:"required"`
+	Description        string `json:"description"`
+	DaysPerYear        int    `json:"days_per_year"`
+	IsPaid             *bool  `json:"is_paid"`
+	RequiresAttachment bool   `json:"requires_attachment"`
+	MinNoticeDays      int    `json:"min_notice_days"`
+	MaxConsecutiveDays *int   `json:"max_consecutive_days"`
+	EligibleGender     string `json:"eligible_gender"`
+	MinTenureMonths    int    `json:"min_tenure_months"`
+	AllowHalfDay       bool   `json:"allow_half_day"`
+	Status             string `json:"status"`
+}
+
+type LeaveTypeResponse struct {
+	ID                 int       `json:"id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description,omitempty"`
+	DaysPerYear        int       `json:"days_per_year"`
+	IsPaid             bool      `json:"is_paid"`
+	RequiresAttachment bool      `json:"requires_attachment"`
+	MinNoticeDays      int       `json:"min_notice_days"`
+	MaxConsecutiveDays *int      `json:"max_consecutive_days"`
+	EligibleGender     string    `json:"eligible_gender,omitempty"`
+	MinTenureMonths    int       `json:"min_tenure_months"`
+	AllowHalfDay       bool      `json:"allow_half_day"`
+	Status             string    `json:"status"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
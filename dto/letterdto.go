@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+// LetterTemplateRequest creates or replaces a letter template. Category is
+// offer, confirmation, employment_verification or other. Title and Body are
+// plain text with {{placeholder}} fields; blank lines separate paragraphs.
+// Generated letters are saved as documents of DocumentTypeID, or of a type
+// named after the template when it is left out.
+type LetterTemplateRequest struct {
+	Name           string `json:"name" validate:"required"`
+	Category       string `json:"category"`
+	DocumentTypeID *int   `json:"document_type_id"`
+	Title          string `json:"title" validate:"required"`
+	Body           string `json:"body" validate:"required"`
+	Status         string `json:"status"`
+}
+
+type LetterTemplateResponse struct {
+	ID             int       `json:"id"`
+	Name           string    `json:"name"`
+	Category       string    `json:"category"`
+	DocumentTypeID *int      `json:"document_type_id"`
+	DocumentType   string    `json:"document_type,omitempty"`
+	Title          string    `json:"title"`
+	Body           string    `json:"body"`
+	Status         string    `json:"status"`
+	CreatedBy      *int      `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// LetterPlaceholder is a field templates may use as {{key}}
+type LetterPlaceholder struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+// GenerateLetterRequest renders a template for one employee. Format is pdf,
+// the default, or html.
+type GenerateLetterRequest struct {
+	EmployeeID int    `json:"employee_id" validate:"required"`
+	Format     string `json:"format"`
+}
+
+// BulkGenerateLettersRequest renders a template for every employee matching
+// Filter
+type BulkGenerateLettersRequest struct {
+	Filter EmployeeFilter `json:"filter"`
+	Format string         `json:"format"`
+}
+
+type LetterFailure struct {
+	EmployeeID   int    `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Error        string `json:"error"`
+}
+
+type BulkGenerateLettersResponse struct {
+	Generated []*DocumentResponse `json:"generated"`
+	Failed    []LetterFailure     `json:"failed"`
+}
@@ -0,0 +1,84 @@
+package dto
+
+import "time"
+
+// MemoTypeRequest creates or replaces a memo type. Category is general (the
+// default), query, commendation or warning. IsDisciplinary defaults to true
+// for queries and warnings when omitted. A warning lapses ValidityMonths
+// after it is issued; nil means it never lapses.
+type MemoTypeRequest struct {
+	Name                    string `json:"name" validate:"required"`
+	Description             string `json:"description"`
+	Category                string `json:"category"`
+	IsDisciplinary          *bool  `json:"is_disciplinary"`
+	RequiresAcknowledgement bool   `json:"requires_acknowledgement"`
+	ValidityMonths          *int   `json:"validity_months"`
+	Status                  string `json:"status"`
+}
+
+type MemoTypeResponse struct {
+	ID                      int       `json:"id"`
+	Name                    string    `json:"name"`
+	Description             string    `json:"description,omitempty"`
+	Category                string    `json:"category"`
+	IsDisciplinary          bool      `json:"is_disciplinary"`
+	RequiresAcknowledgement bool      `json:"requires_acknowledgement"`
+	ValidityMonths          *int      `json:"validity_months"`
+	Status                  string    `json:"status"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+// CreateMemoRequest writes a memo about EmployeeID, who defaults to the
+// author
+type CreateMemoRequest struct {
+	EmployeeID  int    `json:"employee_id"`
+	MemoTypeID  int    `json:"memo_type_id" validate:"required"`
+	Title       string `json:"title" validate:"required"`
+	Description string `json:"description"`
+}
+
+type MemoDecisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+type AcknowledgeMemoRequest struct {
+	Comment string `json:"comment"`
+}
+
+type MemoFilter struct {
+	Status     string
+	EmployeeID int
+	AuthorID   int
+	MemoTypeID int
+	Category   string
+	CaseID     int
+}
+
+type MemoResponse struct {
+	ID                      int        `json:"id"`
+	EmployeeID              int        `json:"employee_id"`
+	EmployeeName            string     `json:"employee_name"`
+	AuthorID                *int       `json:"author_id"`
+	AuthorName              string     `json:"author_name,omitempty"`
+	MemoTypeID              int        `json:"memo_type_id"`
+	MemoType                string     `json:"memo_type"`
+	Category                string     `json:"category"`
+	IsDisciplinary          bool       `json:"is_disciplinary"`
+	Title                   string     `json:"title"`
+	Description             string     `json:"description,omitempty"`
+	Status                  string     `json:"status"`
+	ApprovalWorkflowID      *int       `json:"approval_workflow_id,omitempty"`
+	DecidedBy               *int       `json:"decided_by,omitempty"`
+	DecidedAt               *time.Time `json:"decided_at,omitempty"`
+	DecisionComment         string     `json:"decision_comment,omitempty"`
+	CancelledBy             *int       `json:"cancelled_by,omitempty"`
+	CancelledAt             *time.Time `json:"cancelled_at,omitempty"`
+	RequiresAcknowledgement bool       `json:"requires_acknowledgement"`
+	AcknowledgedAt          *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgementComment  string     `json:"acknowledgement_comment,omitempty"`
+	ExpiresAt               *time.Time `json:"expires_at,omitempty"`
+	CaseID                  *int       `json:"case_id,omitempty"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+}
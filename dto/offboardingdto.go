@@ -0,0 +1,85 @@
+package dto
+
+import "time"
+
+type OffboardingChecklistItemRequest struct {
+	Title              string `json:"title" validate:"required"`
+	Description        string `json:"description"`
+	AssigneeType       string `json:"assignee_type" validate:"required"`
+	AssigneeEmployeeID *int   `json:"assignee_employee_id"`
+	DueOffsetDays      int    `json:"due_offset_days"`
+	SortOrder          int    `json:"sort_order"`
+}
+
+type OffboardingChecklistItemResponse struct {
+	ID                 int       `json:"id"`
+	Title              string    `json:"title"`
+	Description        string    `json:"description,omitempty"`
+	AssigneeType       string    `json:"assignee_type"`
+	AssigneeEmployeeID *int      `json:"assignee_employee_id,omitempty"`
+	DueOffsetDays      int       `json:"due_offset_days"`
+	SortOrder          int       `json:"sort_order"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type StartOffboardingRequest struct {
+	LastWorkingDay string `json:"last_working_day" validate:"required"`
+	SuccessorID    int    `json:"successor_id" validate:"required"`
+	Reason         string `json:"reason"`
+}
+
+type CompleteOffboardingTaskRequest struct {
+	Notes string `json:"notes"`
+}
+
+type OffboardingTaskResponse struct {
+	ID            int        `json:"id"`
+	OffboardingID int        `json:"offboarding_id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	AssigneeType  string     `json:"assignee_type"`
+	AssigneeID    *int       `json:"assignee_id"`
+	DueDate       *time.Time `json:"due_date"`
+	Status        string     `json:"status"`
+	Notes         string     `json:"notes,omitempty"`
+	CompletedBy   *int       `json:"completed_by,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// LeaveSettlementLine is the settlement for one leave type: the remaining
+// balance less the part of the annual entitlement not yet earned by the last
+// working day. A negative figure is leave taken in advance and owed back.
+type LeaveSettlementLine struct {
+	LeaveTypeID    int     `json:"leave_type_id"`
+	LeaveType      string  `json:"leave_type"`
+	Entitlement    float64 `json:"entitlement"`
+	Accrued        float64 `json:"accrued"`
+	Balance        float64 `json:"balance"`
+	SettlementDays float64 `json:"settlement_days"`
+}
+
+type LeaveSettlementResponse struct {
+	Year     int                   `json:"year"`
+	Lines    []LeaveSettlementLine `json:"lines"`
+	Days     float64               `json:"days"`
+	Amount   *float64              `json:"amount,omitempty"`
+	Currency string                `json:"currency,omitempty"`
+}
+
+type OffboardingResponse struct {
+	ID                  int                        `json:"id"`
+	EmployeeID          int                        `json:"employee_id"`
+	LastWorkingDay      time.Time                  `json:"last_working_day"`
+	Reason              string                     `json:"reason,omitempty"`
+	SuccessorID         int                        `json:"successor_id"`
+	Status              string                     `json:"status"`
+	ReassignedReports   int                        `json:"reassigned_reports"`
+	ReassignedApprovals int                        `json:"reassigned_approvals"`
+	Settlement          *LeaveSettlementResponse   `json:"settlement,omitempty"`
+	Tasks               []*OffboardingTaskResponse `json:"tasks,omitempty"`
+	InitiatedBy         *int                       `json:"initiated_by"`
+	CompletedAt         *time.Time                 `json:"completed_at,omitempty"`
+	CancelledAt         *time.Time                 `json:"cancelled_at,omitempty"`
+	CreatedAt           time.Time                  `json:"created_at"`
+}
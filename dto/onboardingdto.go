@@ -0,0 +1,74 @@
+package dto
+
+import "time"
+
+type OnboardingTemplateTaskRequest struct {
+	Title              string `json:"title" validate:"required"`
+	Description        string `json:"description"`
+	AssigneeType       string `json:"assignee_type" validate:"required"`
+	AssigneeEmployeeID *int   `json:"assignee_employee_id"`
+	DueOffsetDays      int    `json:"due_offset_days"`
+}
+
+// OnboardingTemplateRequest creates or replaces a template. A template with
+// neither department nor designation applies to every new hire not matched
+// by a more specific one.
+type OnboardingTemplateRequest struct {
+	Name          string                          `json:"name" validate:"required"`
+	DepartmentID  *int                            `json:"department_id"`
+	DesignationID *int                            `json:"designation_id"`
+	Status        string                          `json:"status"`
+	Tasks         []OnboardingTemplateTaskRequest `json:"tasks"`
+}
+
+type OnboardingTemplateTaskResponse struct {
+	ID                 int    `json:"id"`
+	Title              string `json:"title"`
+	Description        string `json:"description,omitempty"`
+	AssigneeType       string `json:"assignee_type"`
+	AssigneeEmployeeID *int   `json:"assignee_employee_id,omitempty"`
+	DueOffsetDays      int    `json:"due_offset_days"`
+}
+
+type OnboardingTemplateResponse struct {
+	ID            int                               `json:"id"`
+	Name          string                            `json:"name"`
+	DepartmentID  *int                              `json:"department_id"`
+	DesignationID *int                              `json:"designation_id"`
+	Status        string                            `json:"status"`
+	Tasks         []*OnboardingTemplateTaskResponse `json:"tasks"`
+	CreatedAt     time.Time                         `json:"created_at"`
+	UpdatedAt     time.Time                         `json:"updated_at"`
+}
+
+type CompleteOnboardingTaskRequest struct {
+	Notes string `json:"notes"`
+}
+
+type OnboardingTaskResponse struct {
+	ID           int        `json:"id"`
+	EmployeeID   int        `json:"employee_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description,omitempty"`
+	AssigneeType string     `json:"assignee_type"`
+	AssigneeID   *int       `json:"assignee_id"`
+	DueDate      time.Time  `json:"due_date"`
+	Status       string     `json:"status"`
+	Overdue      bool       `json:"overdue"`
+	Notes        string     `json:"notes,omitempty"`
+	CompletedBy  *int       `json:"completed_by,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// OnboardingProgressResponse summarises one new hire's onboarding
+type OnboardingProgressResponse struct {
+	EmployeeID     int        `json:"employee_id"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	StartDate      time.Time  `json:"start_date"`
+	TotalTasks     int        `json:"total_tasks"`
+	CompletedTasks int        `json:"completed_tasks"`
+	OverdueTasks   int        `json:"overdue_tasks"`
+	PercentDone    int        `json:"percent_done"`
+	NextDueDate    *time.Time `json:"next_due_date"`
+}
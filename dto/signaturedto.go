@@ -0,0 +1,101 @@
+package dto
+
+import "time"
+
+// SignatureRequestRequest asks employees to sign, or acknowledge, the current
+// version of a document. Kind is signature, the default, or acknowledgement.
+// Acknowledgements record PolicyName and PolicyVersion, such as "Employee
+// handbook" and "2026.1", and may go to AllEmployees instead of a list of
+// signers. Signers without an order sign one after the other, in the order
+// given.
+type SignatureRequestRequest struct {
+	DocumentID    int             `json:"document_id" validate:"required"`
+	Kind          string          `json:"kind"`
+	Title         string          `json:"title"`
+	Message       string          `json:"message"`
+	PolicyName    string          `json:"policy_name"`
+	PolicyVersion string          `json:"policy_version"`
+	Signers       []SignerRequest `json:"signers"`
+	AllEmployees  bool            `json:"all_employees"`
+}
+
+// SignerRequest adds a signer to a signature request. Signers with a lower
+// Order sign first; signers sharing an order sign in any order.
+type SignerRequest struct {
+	EmployeeID int `json:"employee_id" validate:"required"`
+	Order      int `json:"order"`
+}
+
+type SignatureSignerResponse struct {
+	ID            int        `json:"id"`
+	EmployeeID    int        `json:"employee_id"`
+	EmployeeName  string     `json:"employee_name"`
+	Order         int        `json:"order"`
+	Status        string     `json:"status"`
+	SignatureType string     `json:"signature_type,omitempty"`
+	TypedName     string     `json:"typed_name,omitempty"`
+	IPAddress     string     `json:"ip_address,omitempty"`
+	DeclineReason string     `json:"decline_reason,omitempty"`
+	SignedAt      *time.Time `json:"signed_at"`
+	DeclinedAt    *time.Time `json:"declined_at,omitempty"`
+}
+
+type SignatureRequestResponse struct {
+	ID               int                       `json:"id"`
+	DocumentID       int                       `json:"document_id"`
+	DocumentVersion  int                       `json:"document_version"`
+	DocumentName     string                    `json:"document_name"`
+	DocumentChecksum string                    `json:"document_checksum"`
+	Kind             string                    `json:"kind"`
+	Title            string                    `json:"title"`
+	Message          string                    `json:"message,omitempty"`
+	PolicyName       string                    `json:"policy_name,omitempty"`
+	PolicyVersion    string                    `json:"policy_version,omitempty"`
+	Status           string                    `json:"status"`
+	SealedVersion    *int                      `json:"sealed_version"`
+	SealedChecksum   string                    `json:"sealed_checksum,omitempty"`
+	CreatedBy        *int                      `json:"created_by"`
+	CreatedByName    string                    `json:"created_by_name"`
+	CompletedAt      *time.Time                `json:"completed_at"`
+	CreatedAt        time.Time                 `json:"created_at"`
+	Signers          []SignatureSignerResponse `json:"signers"`
+}
+
+// SignDocumentRequest signs a signature request. SignatureType is typed,
+// with the signer's TypedName, or drawn, with Image holding a PNG as base64
+// or as a data URL. Consent confirms the signer agrees to sign
+// electronically. IPAddress and UserAgent are those of the HTTP request.
+type SignDocumentRequest struct {
+	SignatureType string `json:"signature_type" validate:"required"`
+	TypedName     string `json:"typed_name"`
+	Image         string `json:"image"`
+	Consent       bool   `json:"consent"`
+	IPAddress     string `json:"-"`
+	UserAgent     string `json:"-"`
+}
+
+// DeclineSignatureRequest declines to sign a signature request
+type DeclineSignatureRequest struct {
+	Reason    string `json:"reason"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// PolicyAcknowledgementGap is an employee who has not acknowledged a policy
+// version. Status is pending, when a request is waiting for them, or
+// not_requested.
+type PolicyAcknowledgementGap struct {
+	EmployeeID   int        `json:"employee_id"`
+	EmployeeName string     `json:"employee_name"`
+	Status       string     `json:"status"`
+	RequestID    *int       `json:"request_id"`
+	RequestedAt  *time.Time `json:"requested_at"`
+}
+
+type PolicyAcknowledgementReport struct {
+	PolicyName     string                     `json:"policy_name"`
+	PolicyVersion  string                     `json:"policy_version"`
+	TotalEmployees int                        `json:"total_employees"`
+	Acknowledged   int                        `json:"acknowledged"`
+	Outstanding    []PolicyAcknowledgementGap `json:"outstanding"`
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type ApprovalHandler struct {
+	approvalService services.IApprovalService
+}
+
+func NewApprovalHandler(approvalService services.IApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService: approvalService,
+	}
+}
+
+func (ah *ApprovalHandler) ListWorkflows(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	workflows, err := ah.approvalService.ListWorkflows(r.Context(), claims.TenantID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval workflows retrieved successfully",
+		Data:    workflows,
+	})
+}
+
+func (ah *ApprovalHandler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid approval workflow ID")
+		return
+	}
+
+	workflow, err := ah.approvalService.GetWorkflow(r.Context(), claims.TenantID, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval workflow retrieved successfully",
+		Data:    workflow,
+	})
+}
+
+func (ah *ApprovalHandler) CreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ApprovalWorkflowRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	workflow, err := ah.approvalService.CreateWorkflow(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Approval workflow created successfully",
+		Data:    workflow,
+	})
+}
+
+func (ah *ApprovalHandler) UpdateWorkflow(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid approval workflow ID")
+		return
+	}
+
+	var req dto.ApprovalWorkflowRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	workflow, err := ah.approvalService.UpdateWorkflow(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval workflow updated successfully",
+		Data:    workflow,
+	})
+}
+
+func (ah *ApprovalHandler) DeleteWorkflow(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid approval workflow ID")
+		return
+	}
+
+	if err := ah.approvalService.DeleteWorkflow(r.Context(), claims.TenantID, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval workflow deleted successfully",
+	})
+}
+
+// ListEntityApprovalRequests returns the approval history of the record named
+// by the entity_type and entity_id query parameters
+func (ah *ApprovalHandler) ListEntityApprovalRequests(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	entityID, err := utils.QueryInt(r, "entity_id")
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	requests, err := ah.approvalService.ListEntityApprovalRequests(r.Context(), actor, r.URL.Query().Get("entity_type"), entityID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval requests retrieved successfully",
+		Data:    requests,
+	})
+}
+
+func (ah *ApprovalHandler) GetApprovalRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid approval request ID")
+		return
+	}
+
+	request, err := ah.approvalService.GetApprovalRequest(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval request retrieved successfully",
+		Data:    request,
+	})
+}
+
+func (ah *ApprovalHandler) ApproveRequest(w http.ResponseWriter, r *http.Request) {
+	ah.decide(w, r, ah.approvalService.ApproveRequest, "Approval request approved successfully")
+}
+
+func (ah *ApprovalHandler) RejectRequest(w http.ResponseWriter, r *http.Request) {
+	ah.decide(w, r, ah.approvalService.RejectRequest, "Approval request rejected successfully")
+}
+
+type approvalDecisionFunc func(ctx context.Context, actor services.Actor, id int, req *dto.ApprovalDecisionRequest) (*dto.ApprovalRequestResponse, error)
+
+func (ah *ApprovalHandler) decide(w http.ResponseWriter, r *http.Request, decide approvalDecisionFunc, message string) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid approval request ID")
+		return
+	}
+
+	// The comment is optional, so an empty body is accepted
+	var req dto.ApprovalDecisionRequest
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	request, err := decide(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    request,
+	})
+}
+
+func (ah *ApprovalHandler) ListDelegations(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	delegations, err := ah.approvalService.ListDelegations(r.Context(), claims.TenantID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval delegations retrieved successfully",
+		Data:    delegations,
+	})
+}
+
+// ListMyDelegations returns the delegations the caller gave or received
+func (ah *ApprovalHandler) ListMyDelegations(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	delegations, err := ah.approvalService.ListMyDelegations(r.Context(), actor)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval delegations retrieved successfully",
+		Data:    delegations,
+	})
+}
+
+func (ah *ApprovalHandler) CreateDelegation(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ApprovalDelegationRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	delegation, err := ah.approvalService.CreateDelegation(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Approval delegation created successfully",
+		Data:    delegation,
+	})
+}
+
+func (ah *ApprovalHandler) RevokeDelegation(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid approval delegation ID")
+		return
+	}
+
+	delegation, err := ah.approvalService.RevokeDelegation(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval delegation revoked successfully",
+		Data:    delegation,
+	})
+}
+
+// ListMyApprovals returns the caller's approval inbox
+func (ah *ApprovalHandler) ListMyApprovals(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &dto.ApprovalInboxFilter{
+		EntityType:  query.Get("entity_type"),
+		AssignedVia: query.Get("assigned_via"),
+		Overdue:     query.Get("overdue") == "true",
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+	}
+	var err error
+	if filter.RequesterID, err = utils.QueryInt(r, "requester_id"); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	inbox, err := ah.approvalService.ListMyApprovals(r.Context(), actor, filter)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Pending approvals retrieved successfully",
+		Data:    inbox,
+	})
+}
+
+// BulkDecide approves or rejects several approval requests, reporting each
+// one's outcome
+func (ah *ApprovalHandler) BulkDecide(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ApprovalBulkDecisionRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := ah.approvalService.BulkDecide(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Approval decisions processed",
+		Data:    result,
+	})
+}
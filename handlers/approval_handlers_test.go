@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockApprovalService struct {
+	Actor      services.Actor
+	TenantID   int
+	ID         int
+	EntityType string
+	EntityID   int
+	Workflow   *dto.ApprovalWorkflowRequest
+	Decision   *dto.ApprovalDecisionRequest
+	Decided    string
+	Delegation *dto.ApprovalDelegationRequest
+	Filter     *dto.ApprovalInboxFilter
+	Bulk       *dto.ApprovalBulkDecisionRequest
+	Err        error
+}
+
+func (m *MockApprovalService) ListWorkflows(ctx context.Context, tenantID int) ([]*dto.ApprovalWorkflowResponse, error) {
+	m.TenantID = tenantID
+	return []*dto.ApprovalWorkflowResponse{}, m.Err
+}
+
+func (m *MockApprovalService) GetWorkflow(ctx context.Context, tenantID int, id int) (*dto.ApprovalWorkflowResponse, error) {
+	m.TenantID = tenantID
+	m.ID = id
+	return &dto.ApprovalWorkflowResponse{ID: id}, m.Err
+}
+
+func (m *MockApprovalService) CreateWorkflow(ctx context.Context, tenantID int, req *dto.ApprovalWorkflowRequest) (*dto.ApprovalWorkflowResponse, error) {
+	m.TenantID = tenantID
+	m.Workflow = req
+	return &dto.ApprovalWorkflowResponse{ID: 1, Name: req.Name}, m.Err
+}
+
+func (m *MockApprovalService) UpdateWorkflow(ctx context.Context, tenantID int, id int, req *dto.ApprovalWorkflowRequest) (*dto.ApprovalWorkflowResponse, error) {
+	m.TenantID = tenantID
+	m.ID = id
+	m.Workflow = req
+	return &dto.ApprovalWorkflowResponse{ID: id, Name: req.Name}, m.Err
+}
+
+func (m *MockApprovalService) DeleteWorkflow(ctx context.Context, tenantID int, id int) error {
+	m.TenantID = tenantID
+	m.ID = id
+	return m.Err
+}
+
+func (m *MockApprovalService) GetApprovalRequest(ctx context.Context, actor services.Actor, id int) (*dto.ApprovalRequestResponse, error) {
+	m.Actor = actor
+	m.ID = id
+	return &dto.ApprovalRequestResponse{ID: id}, m.Err
+}
+
+func (m *MockApprovalService) ListEntityApprovalRequests(ctx context.Context, actor services.Actor, entityType string, entityID int) ([]*dto.ApprovalRequestResponse, error) {
+	m.Actor = actor
+	m.EntityType = entityType
+	m.EntityID = entityID
+	return []*dto.ApprovalRequestResponse{}, m.Err
+}
+
+func (m *MockApprovalService) ApproveRequest(ctx context.Context, actor services.Actor, id int, req *dto.ApprovalDecisionRequest) (*dto.ApprovalRequestResponse, error) {
+	m.Actor = actor
+	m.ID = id
+	m.Decision = req
+	m.Decided = "approved"
+	return &dto.ApprovalRequestResponse{ID: id, Status: "approved"}, m.Err
+}
+
+func (m *MockApprovalService) RejectRequest(ctx context.Context, actor services.Actor, id int, req *dto.ApprovalDecisionRequest) (*dto.ApprovalRequestResponse, error) {
+	m.Actor = actor
+	m.ID = id
+	m.Decision = req
+	m.Decided = "rejected"
+	return &dto.ApprovalRequestResponse{ID: id, Status: "rejected"}, m.Err
+}
+
+func (m *MockApprovalService) ListDelegations(ctx context.Context, tenantID int) ([]*dto.ApprovalDelegationResponse, error) {
+	m.TenantID = tenantID
+	return []*dto.ApprovalDelegationResponse{}, m.Err
+}
+
+func (m *MockApprovalService) ListMyDelegations(ctx context.Context, actor services.Actor) ([]*dto.ApprovalDelegationResponse, error) {
+	m.Actor = actor
+	return []*dto.ApprovalDelegationResponse{}, m.Err
+}
+
+func (m *MockApprovalService) CreateDelegation(ctx context.Context, actor services.Actor, req *dto.ApprovalDelegationRequest) (*dto.ApprovalDelegationResponse, error) {
+	m.Actor = actor
+	m.Delegation = req
+	return &dto.ApprovalDelegationResponse{ID: 1, DelegatorID: actor.EmployeeID, DelegateID: req.DelegateID}, m.Err
+}
+
+func (m *MockApprovalService) RevokeDelegation(ctx context.Context, actor services.Actor, id int) (*dto.ApprovalDelegationResponse, error) {
+	m.Actor = actor
+	m.ID = id
+	return &dto.ApprovalDelegationResponse{ID: id, Status: "revoked"}, m.Err
+}
+
+func (m *MockApprovalService) ListMyApprovals(ctx context.Context, actor services.Actor, filter *dto.ApprovalInboxFilter) (*dto.ApprovalInboxResponse, error) {
+	m.Actor = actor
+	m.Filter = filter
+	return &dto.ApprovalInboxResponse{Items: []dto.ApprovalInboxItemResponse{}}, m.Err
+}
+
+func (m *MockApprovalService) BulkDecide(ctx context.Context, actor services.Actor, req *dto.ApprovalBulkDecisionRequest) (*dto.ApprovalBulkDecisionResponse, error) {
+	m.Actor = actor
+	m.Bulk = req
+	return &dto.ApprovalBulkDecisionResponse{Succeeded: len(req.Items)}, m.Err
+}
+
+func TestCreateWorkflow(t *testing.T) {
+	t.Run("returns 201 with the workflow", func(t *testing.T) {
+		mockService := &MockApprovalService{}
+
+		body := `{"name":"Leave approval","entity_type":"leave_request","steps":[{"approver_type":"manager"}]}`
+		request, _ := http.NewRequest(http.MethodPost, "/hr/approval-workflows", strings.NewReader(body))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: mockService}
+		handler.CreateWorkflow(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+		if mockService.Workflow == nil || len(mockService.Workflow.Steps) != 1 || mockService.Workflow.Steps[0].ApproverType != "manager" {
+			t.Errorf("got workflow %+v, want the request body", mockService.Workflow)
+		}
+	})
+
+	t.Run("returns 400 for a validation error", func(t *testing.T) {
+		mockService := &MockApprovalService{Err: &utils.ValidationError{Field: "steps", Message: "At least one step is required"}}
+
+		request, _ := http.NewRequest(http.MethodPost, "/hr/approval-workflows", strings.NewReader(`{"name":"x","entity_type":"memo"}`))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: mockService}
+		handler.CreateWorkflow(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestListEntityApprovalRequests(t *testing.T) {
+	mockService := &MockApprovalService{}
+
+	request, _ := http.NewRequest(http.MethodGet, "/approval-requests?entity_type=leave_request&entity_id=12", nil)
+	request = withEmployeeClaims(request, 5)
+
+	response := httptest.NewRecorder()
+
+	handler := &ApprovalHandler{approvalService: mockService}
+	handler.ListEntityApprovalRequests(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.EntityType != "leave_request" || mockService.EntityID != 12 {
+		t.Errorf("got %s %d, want leave_request 12", mockService.EntityType, mockService.EntityID)
+	}
+}
+
+func TestDecideApprovalRequest(t *testing.T) {
+	t.Run("approves with an empty body", func(t *testing.T) {
+		mockService := &MockApprovalService{}
+
+		request, _ := http.NewRequest(http.MethodPost, "/approval-requests/4/approve", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 7), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: mockService}
+		handler.ApproveRequest(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Decided != "approved" || mockService.ID != 4 || mockService.Actor.EmployeeID != 7 {
+			t.Errorf("got %s of %d by %d, want approval of 4 by 7", mockService.Decided, mockService.ID, mockService.Actor.EmployeeID)
+		}
+	})
+
+	t.Run("passes the comment when rejecting", func(t *testing.T) {
+		mockService := &MockApprovalService{}
+
+		request, _ := http.NewRequest(http.MethodPost, "/approval-requests/4/reject", strings.NewReader(`{"comment":"Too busy"}`))
+		request = mux.SetURLVars(withEmployeeClaims(request, 7), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: mockService}
+		handler.RejectRequest(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Decided != "rejected" || mockService.Decision.Comment != "Too busy" {
+			t.Errorf("got %s with %+v, want a rejection with the comment", mockService.Decided, mockService.Decision)
+		}
+	})
+
+	t.Run("passes the version the caller saw", func(t *testing.T) {
+		mockService := &MockApprovalService{}
+
+		request, _ := http.NewRequest(http.MethodPost, "/approval-requests/4/approve", strings.NewReader(`{"version":3}`))
+		request = mux.SetURLVars(withEmployeeClaims(request, 7), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: mockService}
+		handler.ApproveRequest(response, request)
+
+		if mockService.Decision == nil || mockService.Decision.Version != 3 {
+			t.Errorf("got %+v, want version 3", mockService.Decision)
+		}
+	})
+
+	t.Run("returns 409 when the request changed", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/approval-requests/4/approve", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 7), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: &MockApprovalService{Err: services.ErrConflict}}
+		handler.ApproveRequest(response, request)
+
+		if response.Code != http.StatusConflict {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("returns 403 for someone who is not an approver", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/approval-requests/4/approve", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 9), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: &MockApprovalService{Err: services.ErrForbidden}}
+		handler.ApproveRequest(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestCreateDelegation(t *testing.T) {
+	t.Run("returns 201 with the delegation", func(t *testing.T) {
+		mockService := &MockApprovalService{}
+
+		body := `{"delegate_id":8,"start_date":"2026-03-12","end_date":"2026-03-20","reason":"Holiday"}`
+		request, _ := http.NewRequest(http.MethodPost, "/me/approval-delegations", strings.NewReader(body))
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: mockService}
+		handler.CreateDelegation(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+		if mockService.Actor.EmployeeID != 5 || mockService.Delegation == nil || mockService.Delegation.DelegateID != 8 || mockService.Delegation.EndDate != "2026-03-20" {
+			t.Errorf("got %+v from %d, want the request body from 5", mockService.Delegation, mockService.Actor.EmployeeID)
+		}
+	})
+
+	t.Run("returns 403 when delegating for someone else", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/me/approval-delegations", strings.NewReader(`{"delegator_id":7,"delegate_id":8}`))
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: &MockApprovalService{Err: services.ErrForbidden}}
+		handler.CreateDelegation(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestRevokeDelegation(t *testing.T) {
+	mockService := &MockApprovalService{}
+
+	request, _ := http.NewRequest(http.MethodPost, "/me/approval-delegations/3/revoke", nil)
+	request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+	response := httptest.NewRecorder()
+
+	handler := &ApprovalHandler{approvalService: mockService}
+	handler.RevokeDelegation(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.ID != 3 || mockService.Actor.EmployeeID != 5 {
+		t.Errorf("got delegation %d revoked by %d, want 3 by 5", mockService.ID, mockService.Actor.EmployeeID)
+	}
+}
+
+func TestListMyApprovals(t *testing.T) {
+	t.Run("passes the filters", func(t *testing.T) {
+		mockService := &MockApprovalService{}
+
+		request, _ := http.NewRequest(http.MethodGet, "/me/approvals?entity_type=memo&assigned_via=delegation&overdue=true&requester_id=12&from=2026-03-01", nil)
+		request = withEmployeeClaims(request, 7)
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: mockService}
+		handler.ListMyApprovals(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		want := dto.ApprovalInboxFilter{EntityType: "memo", AssignedVia: "delegation", Overdue: true, RequesterID: 12, From: "2026-03-01"}
+		if mockService.Filter == nil || *mockService.Filter != want || mockService.Actor.EmployeeID != 7 {
+			t.Errorf("got filter %+v for %d, want %+v for 7", mockService.Filter, mockService.Actor.EmployeeID, want)
+		}
+	})
+
+	t.Run("returns 400 for a bad requester ID", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/me/approvals?requester_id=abc", nil)
+		request = withEmployeeClaims(request, 7)
+
+		response := httptest.NewRecorder()
+
+		handler := &ApprovalHandler{approvalService: &MockApprovalService{}}
+		handler.ListMyApprovals(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestBulkDecide(t *testing.T) {
+	mockService := &MockApprovalService{}
+
+	body := `{"action":"approve","comment":"Fine","items":[{"request_id":4,"version":2},{"request_id":6,"version":1}]}`
+	request, _ := http.NewRequest(http.MethodPost, "/me/approvals/bulk", strings.NewReader(body))
+	request = withEmployeeClaims(request, 7)
+
+	response := httptest.NewRecorder()
+
+	handler := &ApprovalHandler{approvalService: mockService}
+	handler.BulkDecide(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.Bulk == nil || mockService.Bulk.Action != "approve" || len(mockService.Bulk.Items) != 2 || mockService.Bulk.Items[0].Version != 2 {
+		t.Errorf("got %+v, want the request body", mockService.Bulk)
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type AuditHandler struct {
+	auditService services.IAuditService
+}
+
+func NewAuditHandler(auditService services.IAuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+func parseAuditLogFilter(r *http.Request) (*dto.AuditLogFilter, error) {
+	query := r.URL.Query()
+	filter := &dto.AuditLogFilter{
+		EntityType: query.Get("entity_type"),
+		Action:     query.Get("action"),
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+		Cursor:     query.Get("cursor"),
+	}
+	var err error
+	if filter.EntityID, err = utils.QueryInt(r, "entity_id"); err != nil {
+		return nil, err
+	}
+	if filter.ActorID, err = utils.QueryInt(r, "actor_id"); err != nil {
+		return nil, err
+	}
+	if filter.Limit, err = utils.QueryInt(r, "limit"); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func (ah *AuditHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseAuditLogFilter(r)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	page, err := ah.auditService.ListAuditLogs(r.Context(), actor, filter)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Audit logs retrieved successfully",
+		Data:    page,
+	})
+}
+
+// GetEntityTimeline returns the field-level history of one entity
+func (ah *AuditHandler) GetEntityTimeline(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	entityType := r.URL.Query().Get("entity_type")
+	entityID, err := utils.QueryInt(r, "entity_id")
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	if entityType == "" || entityID == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "entity_type and entity_id are required")
+		return
+	}
+
+	timeline, err := ah.auditService.GetEntityTimeline(r.Context(), actor, entityType, entityID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Audit timeline retrieved successfully",
+		Data:    timeline,
+	})
+}
+
+// exportResponseWriter sets the download headers when the export starts
+// writing, so an error found before then can still be sent as JSON
+type exportResponseWriter struct {
+	w        http.ResponseWriter
+	format   string
+	fileName string
+	started  bool
+}
+
+func (e *exportResponseWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.w.Header().Set("Content-Type", utils.ExportContentType(e.format))
+		e.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, e.fileName, e.format))
+	}
+	return e.w.Write(p)
+}
+
+// ExportAuditLogs streams the filtered audit log to the client
+func (ah *AuditHandler) ExportAuditLogs(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseAuditLogFilter(r)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = utils.FormatCSV
+	}
+
+	out := &exportResponseWriter{w: w, format: format, fileName: "audit_logs"}
+	if err := ah.auditService.ExportAuditLogs(r.Context(), actor, filter, format, out); err != nil {
+		if !out.started {
+			respondServiceError(w, err)
+		}
+		// Part of the body may already be on the wire, so the status can no
+		// longer be changed; the truncated file is the best signal we can give.
+		return
+	}
+	if !out.started {
+		// Nothing matched and the format has no header line
+		out.Write(nil)
+	}
+}
+
+// VerifyAuditChain walks the tenant's audit chain and reports the first
+// broken link
+func (ah *AuditHandler) VerifyAuditChain(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := ah.auditService.VerifyAuditChain(r.Context(), actor)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	message := "Audit chain verified successfully"
+	if !result.Valid {
+		message = "Audit chain is broken"
+	}
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	})
+}
+
+// ExportCheckpoints downloads the tenant's signed audit checkpoints
+func (ah *AuditHandler) ExportCheckpoints(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = utils.FormatJSONL
+	}
+
+	out := &exportResponseWriter{w: w, format: format, fileName: "audit_checkpoints"}
+	if err := ah.auditService.ExportCheckpoints(r.Context(), actor, format, out); err != nil {
+		if !out.started {
+			respondServiceError(w, err)
+		}
+		return
+	}
+	if !out.started {
+		out.Write(nil)
+	}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+type MockAuditService struct {
+	Actor          services.Actor
+	Filter         *dto.AuditLogFilter
+	EntityType     string
+	EntityID       int
+	Format         string
+	PageResult     *dto.AuditLogPage
+	TimelineResult *dto.AuditTimelineResponse
+	ExportOutput   string
+	VerifyResult   *dto.AuditChainVerification
+	Err            error
+}
+
+func (m *MockAuditService) ListAuditLogs(ctx context.Context, actor services.Actor, filter *dto.AuditLogFilter) (*dto.AuditLogPage, error) {
+	m.Actor = actor
+	m.Filter = filter
+	return m.PageResult, m.Err
+}
+
+func (m *MockAuditService) GetEntityTimeline(ctx context.Context, actor services.Actor, entityType string, entityID int) (*dto.AuditTimelineResponse, error) {
+	m.Actor = actor
+	m.EntityType = entityType
+	m.EntityID = entityID
+	return m.TimelineResult, m.Err
+}
+
+func (m *MockAuditService) ExportAuditLogs(ctx context.Context, actor services.Actor, filter *dto.AuditLogFilter, format string, w io.Writer) error {
+	m.Actor = actor
+	m.Filter = filter
+	m.Format = format
+	if m.Err != nil {
+		return m.Err
+	}
+	_, err := io.WriteString(w, m.ExportOutput)
+	return err
+}
+
+func (m *MockAuditService) VerifyAuditChain(ctx context.Context, actor services.Actor) (*dto.AuditChainVerification, error) {
+	m.Actor = actor
+	return m.VerifyResult, m.Err
+}
+
+func (m *MockAuditService) ExportCheckpoints(ctx context.Context, actor services.Actor, format string, w io.Writer) error {
+	m.Actor = actor
+	m.Format = format
+	if m.Err != nil {
+		return m.Err
+	}
+	_, err := io.WriteString(w, m.ExportOutput)
+	return err
+}
+
+func TestListAuditLogs(t *testing.T) {
+	t.Run("passes the filters and cursor", func(t *testing.T) {
+		mockService := &MockAuditService{PageResult: &dto.AuditLogPage{}}
+
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs?entity_type=employee&entity_id=7&actor_id=2&action=update&from=2026-01-01&to=2026-01-31&cursor=MTA&limit=20", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: mockService}
+		handler.ListAuditLogs(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		filter := mockService.Filter
+		if filter.EntityType != "employee" || filter.EntityID != 7 || filter.ActorID != 2 || filter.Action != "update" ||
+			filter.From != "2026-01-01" || filter.To != "2026-01-31" || filter.Cursor != "MTA" || filter.Limit != 20 {
+			t.Errorf("got filter %+v, want every query parameter passed on", filter)
+		}
+	})
+
+	t.Run("returns 400 for a bad limit", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs?limit=many", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: &MockAuditService{}}
+		handler.ListAuditLogs(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns 403 without access", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: &MockAuditService{Err: services.ErrForbidden}}
+		handler.ListAuditLogs(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestGetEntityTimeline(t *testing.T) {
+	t.Run("passes the entity", func(t *testing.T) {
+		mockService := &MockAuditService{TimelineResult: &dto.AuditTimelineResponse{EntityType: "department", EntityID: 3}}
+
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs/timeline?entity_type=department&entity_id=3", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: mockService}
+		handler.GetEntityTimeline(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.EntityType != "department" || mockService.EntityID != 3 {
+			t.Errorf("got %s %d, want department 3", mockService.EntityType, mockService.EntityID)
+		}
+	})
+
+	t.Run("requires the entity", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs/timeline?entity_type=department", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: &MockAuditService{}}
+		handler.GetEntityTimeline(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestExportAuditLogs(t *testing.T) {
+	t.Run("streams the export as a download", func(t *testing.T) {
+		mockService := &MockAuditService{ExportOutput: "{\"id\":\"1\"}\n"}
+
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs/export?format=jsonl&entity_type=role", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: mockService}
+		handler.ExportAuditLogs(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if got := response.Header().Get("Content-Disposition"); got != `attachment; filename="audit_logs.jsonl"` {
+			t.Errorf("got Content-Disposition %q, want the jsonl attachment", got)
+		}
+		if response.Body.String() != mockService.ExportOutput || mockService.Filter.EntityType != "role" {
+			t.Errorf("got body %q and filter %+v, want the export of roles", response.Body.String(), mockService.Filter)
+		}
+	})
+
+	t.Run("returns 403 as JSON without the export permission", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs/export", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: &MockAuditService{Err: services.ErrForbidden}}
+		handler.ExportAuditLogs(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+		if got := response.Header().Get("Content-Disposition"); got != "" {
+			t.Errorf("got Content-Disposition %q, want none", got)
+		}
+	})
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	t.Run("reports a broken chain", func(t *testing.T) {
+		mockService := &MockAuditService{VerifyResult: &dto.AuditChainVerification{
+			TenantID:   1,
+			BrokenLink: &dto.AuditChainBreak{LogID: 12, Reason: "Row content does not match its hash"},
+		}}
+
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs/verify", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: mockService}
+		handler.VerifyAuditChain(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if !strings.Contains(response.Body.String(), `"log_id":12`) {
+			t.Errorf("got body %s, want the broken link", response.Body.String())
+		}
+	})
+
+	t.Run("returns 403 without access", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/audit-logs/verify", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &AuditHandler{auditService: &MockAuditService{Err: services.ErrForbidden}}
+		handler.VerifyAuditChain(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestExportCheckpoints(t *testing.T) {
+	mockService := &MockAuditService{ExportOutput: "{\"id\":\"1\"}\n"}
+
+	request, _ := http.NewRequest(http.MethodGet, "/audit-logs/checkpoints/export", nil)
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &AuditHandler{auditService: mockService}
+	handler.ExportCheckpoints(response, request)
+
+	if mockService.Format != "jsonl" {
+		t.Errorf("got format %q, want jsonl by default", mockService.Format)
+	}
+	if got := response.Header().Get("Content-Disposition"); got != `attachment; filename="audit_checkpoints.jsonl"` {
+		t.Errorf("got Content-Disposition %q, want the jsonl attachment", got)
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/middleware"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// requireClaims returns the authenticated caller's claims, writing a 401
+// response and returning false when the request carries none.
+func requireClaims(w http.ResponseWriter, r *http.Request) (*middleware.Claims, bool) {
+	claims, ok := middleware.GetUserClaims(r.Context())
+	if !ok || claims == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Could not retrieve user claims")
+		return nil, false
+	}
+	return claims, true
+}
+
+// requireActor is requireClaims converted to the service-layer actor
+func requireActor(w http.ResponseWriter, r *http.Request) (services.Actor, bool) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return services.Actor{}, false
+	}
+	return services.Actor{
+		EmployeeID: claims.ID,
+		TenantID:   claims.TenantID,
+		Role:       claims.Role,
+	}, true
+}
+
+// requestOrigin returns the client IP address, as found by the request ID
+// middleware, and the user agent of a request
+func requestOrigin(r *http.Request) (string, string) {
+	info, _ := middleware.GetRequestInfo(r.Context())
+	return info.IPAddress, r.UserAgent()
+}
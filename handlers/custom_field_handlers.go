@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type CustomFieldHandler struct {
+	customFieldService services.ICustomFieldService
+}
+
+func NewCustomFieldHandler(customFieldService services.ICustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService: customFieldService,
+	}
+}
+
+func (ch *CustomFieldHandler) ListCustomFields(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	fields, err := ch.customFieldService.ListCustomFields(r.Context(), claims.TenantID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Custom fields retrieved successfully",
+		Data:    fields,
+	})
+}
+
+func (ch *CustomFieldHandler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CustomFieldRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	field, err := ch.customFieldService.CreateCustomField(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Custom field created successfully",
+		Data:    field,
+	})
+}
+
+func (ch *CustomFieldHandler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid custom field ID")
+		return
+	}
+
+	var req dto.CustomFieldRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	field, err := ch.customFieldService.UpdateCustomField(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Custom field updated successfully",
+		Data:    field,
+	})
+}
+
+func (ch *CustomFieldHandler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid custom field ID")
+		return
+	}
+
+	if err := ch.customFieldService.DeleteCustomField(r.Context(), claims.TenantID, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Custom field deleted successfully",
+	})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockCustomFieldService struct {
+	TenantID   int
+	Request    *dto.CustomFieldRequest
+	Result     *dto.CustomFieldResponse
+	ListResult []*dto.CustomFieldResponse
+	DeletedID  int
+	Err        error
+}
+
+func (m *MockCustomFieldService) ListCustomFields(ctx context.Context, tenantID int) ([]*dto.CustomFieldResponse, error) {
+	m.TenantID = tenantID
+	return m.ListResult, m.Err
+}
+
+func (m *MockCustomFieldService) CreateCustomField(ctx context.Context, tenantID int, req *dto.CustomFieldRequest) (*dto.CustomFieldResponse, error) {
+	m.TenantID = tenantID
+	m.Request = req
+	return m.Result, m.Err
+}
+
+func (m *MockCustomFieldService) UpdateCustomField(ctx context.Context, tenantID int, id int, req *dto.CustomFieldRequest) (*dto.CustomFieldResponse, error) {
+	m.Request = req
+	return m.Result, m.Err
+}
+
+func (m *MockCustomFieldService) DeleteCustomField(ctx context.Context, tenantID int, id int) error {
+	m.DeletedID = id
+	return m.Err
+}
+
+func TestCreateCustomField(t *testing.T) {
+	t.Run("returns 201 when the field is created", func(t *testing.T) {
+		mockService := &MockCustomFieldService{
+			Result: &dto.CustomFieldResponse{ID: 1, Key: "shirt_size", FieldType: "enum"},
+		}
+
+		body, _ := json.Marshal(dto.CustomFieldRequest{
+			Key:       "shirt_size",
+			Label:     "T-shirt size",
+			FieldType: "enum",
+			Options:   []string{"S", "M", "L"},
+		})
+		request, _ := http.NewRequest(http.MethodPost, "/custom-fields", bytes.NewReader(body))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &CustomFieldHandler{customFieldService: mockService}
+		handler.CreateCustomField(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+
+		if mockService.TenantID != 1 || len(mockService.Request.Options) != 3 {
+			t.Errorf("got tenant %d and request %+v, want tenant 1 with three options", mockService.TenantID, mockService.Request)
+		}
+	})
+
+	t.Run("returns 400 when validation fails", func(t *testing.T) {
+		mockService := &MockCustomFieldService{
+			Err: &utils.ValidationError{Field: "options", Message: "Enum fields need at least one option"},
+		}
+
+		body, _ := json.Marshal(dto.CustomFieldRequest{Key: "shirt_size", Label: "T-shirt size", FieldType: "enum"})
+		request, _ := http.NewRequest(http.MethodPost, "/custom-fields", bytes.NewReader(body))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &CustomFieldHandler{customFieldService: mockService}
+		handler.CreateCustomField(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns 401 when claims are missing", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/custom-fields", bytes.NewReader([]byte(`{}`)))
+
+		response := httptest.NewRecorder()
+
+		handler := &CustomFieldHandler{customFieldService: &MockCustomFieldService{}}
+		handler.CreateCustomField(response, request)
+
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestDeleteCustomField(t *testing.T) {
+	t.Run("returns 200 when the field is deleted", func(t *testing.T) {
+		mockService := &MockCustomFieldService{}
+
+		request, _ := http.NewRequest(http.MethodDelete, "/custom-fields/3", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &CustomFieldHandler{customFieldService: mockService}
+		handler.DeleteCustomField(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		if mockService.DeletedID != 3 {
+			t.Errorf("got deleted id %d, want 3", mockService.DeletedID)
+		}
+	})
+
+	t.Run("returns 404 when the field does not exist", func(t *testing.T) {
+		mockService := &MockCustomFieldService{Err: services.ErrNotFound}
+
+		request, _ := http.NewRequest(http.MethodDelete, "/custom-fields/3", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &CustomFieldHandler{customFieldService: mockService}
+		handler.DeleteCustomField(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
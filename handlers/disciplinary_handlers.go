@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type DisciplinaryHandler struct {
+	disciplinaryService services.IDisciplinaryService
+}
+
+func NewDisciplinaryHandler(disciplinaryService services.IDisciplinaryService) *DisciplinaryHandler {
+	return &DisciplinaryHandler{
+		disciplinaryService: disciplinaryService,
+	}
+}
+
+func (dh *DisciplinaryHandler) ListCases(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &dto.DisciplinaryCaseFilter{Status: query.Get("status"), Stage: query.Get("stage")}
+	var err error
+	if filter.EmployeeID, err = utils.QueryInt(r, "employee_id"); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	cases, err := dh.disciplinaryService.ListCases(r.Context(), claims.TenantID, filter)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Disciplinary cases retrieved successfully",
+		Data:    cases,
+	})
+}
+
+func (dh *DisciplinaryHandler) GetCase(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid case ID")
+		return
+	}
+
+	c, err := dh.disciplinaryService.GetCase(r.Context(), claims.TenantID, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Disciplinary case retrieved successfully",
+		Data:    c,
+	})
+}
+
+func (dh *DisciplinaryHandler) OpenCase(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.OpenDisciplinaryCaseRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	c, err := dh.disciplinaryService.OpenCase(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Disciplinary case opened successfully",
+		Data:    c,
+	})
+}
+
+func (dh *DisciplinaryHandler) RecordStage(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid case ID")
+		return
+	}
+
+	var req dto.DisciplinaryStageRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	c, err := dh.disciplinaryService.RecordStage(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Case stage recorded successfully",
+		Data:    c,
+	})
+}
+
+func (dh *DisciplinaryHandler) LinkMemo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid case ID")
+		return
+	}
+
+	var req dto.LinkCaseMemoRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	c, err := dh.disciplinaryService.LinkMemo(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo linked successfully",
+		Data:    c,
+	})
+}
+
+// GetDisciplinarySummary returns an employee's warnings still in force and
+// open cases, for HR reviewing a termination
+func (dh *DisciplinaryHandler) GetDisciplinarySummary(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	summary, err := dh.disciplinaryService.GetDisciplinarySummary(r.Context(), claims.TenantID, employeeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Disciplinary summary retrieved successfully",
+		Data:    summary,
+	})
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+type MockDisciplinaryService struct {
+	Actor         services.Actor
+	TenantID      int
+	ID            int
+	Filter        *dto.DisciplinaryCaseFilter
+	OpenRequest   *dto.OpenDisciplinaryCaseRequest
+	StageRequest  *dto.DisciplinaryStageRequest
+	LinkRequest   *dto.LinkCaseMemoRequest
+	Result        *dto.DisciplinaryCaseResponse
+	ListResult    []*dto.DisciplinaryCaseResponse
+	SummaryResult *dto.DisciplinarySummaryResponse
+	Err           error
+}
+
+func (m *MockDisciplinaryService) ListCases(ctx context.Context, tenantID int, filter *dto.DisciplinaryCaseFilter) ([]*dto.DisciplinaryCaseResponse, error) {
+	m.TenantID = tenantID
+	m.Filter = filter
+	return m.ListResult, m.Err
+}
+
+func (m *MockDisciplinaryService) GetCase(ctx context.Context, tenantID int, id int) (*dto.DisciplinaryCaseResponse, error) {
+	m.ID = id
+	return m.Result, m.Err
+}
+
+func (m *MockDisciplinaryService) OpenCase(ctx context.Context, actor services.Actor, req *dto.OpenDisciplinaryCaseRequest) (*dto.DisciplinaryCaseResponse, error) {
+	m.Actor = actor
+	m.OpenRequest = req
+	return m.Result, m.Err
+}
+
+func (m *MockDisciplinaryService) RecordStage(ctx context.Context, actor services.Actor, id int, req *dto.DisciplinaryStageRequest) (*dto.DisciplinaryCaseResponse, error) {
+	m.Actor = actor
+	m.ID = id
+	m.StageRequest = req
+	return m.Result, m.Err
+}
+
+func (m *MockDisciplinaryService) LinkMemo(ctx context.Context, tenantID int, id int, req *dto.LinkCaseMemoRequest) (*dto.DisciplinaryCaseResponse, error) {
+	m.ID = id
+	m.LinkRequest = req
+	return m.Result, m.Err
+}
+
+func (m *MockDisciplinaryService) GetDisciplinarySummary(ctx context.Context, tenantID int, employeeID int) (*dto.DisciplinarySummaryResponse, error) {
+	m.TenantID = tenantID
+	m.ID = employeeID
+	return m.SummaryResult, m.Err
+}
+
+func TestListDisciplinaryCases(t *testing.T) {
+	t.Run("passes the filters", func(t *testing.T) {
+		mockService := &MockDisciplinaryService{}
+
+		request, _ := http.NewRequest(http.MethodGet, "/disciplinary-cases?status=open&stage=hearing_scheduled&employee_id=7", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &DisciplinaryHandler{disciplinaryService: mockService}
+		handler.ListCases(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		filter := mockService.Filter
+		if filter.Status != "open" || filter.Stage != "hearing_scheduled" || filter.EmployeeID != 7 {
+			t.Errorf("got filter %+v, want open cases about employee 7 awaiting a hearing", filter)
+		}
+	})
+
+	t.Run("returns 400 for a bad employee id", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/disciplinary-cases?employee_id=abc", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &DisciplinaryHandler{disciplinaryService: &MockDisciplinaryService{}}
+		handler.ListCases(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestOpenDisciplinaryCase(t *testing.T) {
+	mockService := &MockDisciplinaryService{Result: &dto.DisciplinaryCaseResponse{ID: 3, Stage: "query_issued"}}
+
+	body := []byte(`{"employee_id": 7, "title": "Absence without leave", "memo_ids": [4, 5]}`)
+	request, _ := http.NewRequest(http.MethodPost, "/disciplinary-cases", bytes.NewReader(body))
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &DisciplinaryHandler{disciplinaryService: mockService}
+	handler.OpenCase(response, request)
+
+	if response.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+	}
+	if mockService.Actor.EmployeeID != 1 || mockService.OpenRequest.EmployeeID != 7 || len(mockService.OpenRequest.MemoIDs) != 2 {
+		t.Errorf("got actor %+v and request %+v, want HR opening a case about 7 with two memos", mockService.Actor, mockService.OpenRequest)
+	}
+}
+
+func TestRecordDisciplinaryStage(t *testing.T) {
+	t.Run("passes the stage", func(t *testing.T) {
+		mockService := &MockDisciplinaryService{Result: &dto.DisciplinaryCaseResponse{ID: 3, Stage: "hearing_scheduled"}}
+
+		body := []byte(`{"stage": "hearing_scheduled", "hearing_at": "2030-01-10T10:00:00Z", "hearing_location": "Room 2"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/disciplinary-cases/3/stage", bytes.NewReader(body))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &DisciplinaryHandler{disciplinaryService: mockService}
+		handler.RecordStage(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.ID != 3 || mockService.StageRequest.HearingAt != "2030-01-10T10:00:00Z" {
+			t.Errorf("got id %d and request %+v, want case 3 with the hearing time", mockService.ID, mockService.StageRequest)
+		}
+	})
+
+	t.Run("returns 409 when the case moved on", func(t *testing.T) {
+		mockService := &MockDisciplinaryService{Err: services.ErrConflict}
+
+		body := []byte(`{"stage": "response_received"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/disciplinary-cases/3/stage", bytes.NewReader(body))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &DisciplinaryHandler{disciplinaryService: mockService}
+		handler.RecordStage(response, request)
+
+		if response.Code != http.StatusConflict {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusConflict)
+		}
+	})
+}
+
+func TestGetDisciplinarySummary(t *testing.T) {
+	mockService := &MockDisciplinaryService{SummaryResult: &dto.DisciplinarySummaryResponse{EmployeeID: 7, LapsedWarnings: 1}}
+
+	request, _ := http.NewRequest(http.MethodGet, "/employees/7/disciplinary-summary", nil)
+	request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "7"})
+
+	response := httptest.NewRecorder()
+
+	handler := &DisciplinaryHandler{disciplinaryService: mockService}
+	handler.GetDisciplinarySummary(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.ID != 7 {
+		t.Errorf("got employee %d, want 7", mockService.ID)
+	}
+}
@@ -0,0 +1,512 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+const (
+	// maxDocumentUploadBytes leaves room for the rest of the form around the
+	// largest file a document type may accept
+	maxDocumentUploadBytes = services.MaxDocumentBytes + 1<<20
+	// documentFormMemory is how much of an upload is buffered in memory
+	// before the rest goes to a temporary file
+	documentFormMemory = 8 << 20
+)
+
+type DocumentHandler struct {
+	documentService services.IDocumentService
+}
+
+func NewDocumentHandler(documentService services.IDocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		documentService: documentService,
+	}
+}
+
+func (dh *DocumentHandler) ListDocumentTypes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	documentTypes, err := dh.documentService.ListDocumentTypes(r.Context(), claims.TenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Document types retrieved successfully",
+		Data:    documentTypes,
+	})
+}
+
+// ListActiveDocumentTypes returns the document types employees can upload
+func (dh *DocumentHandler) ListActiveDocumentTypes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	documentTypes, err := dh.documentService.ListDocumentTypes(r.Context(), claims.TenantID, "active")
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Document types retrieved successfully",
+		Data:    documentTypes,
+	})
+}
+
+func (dh *DocumentHandler) GetDocumentType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document type ID")
+		return
+	}
+
+	documentType, err := dh.documentService.GetDocumentType(r.Context(), claims.TenantID, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Document type retrieved successfully",
+		Data:    documentType,
+	})
+}
+
+func (dh *DocumentHandler) CreateDocumentType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.DocumentTypeRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	documentType, err := dh.documentService.CreateDocumentType(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Document type created successfully",
+		Data:    documentType,
+	})
+}
+
+func (dh *DocumentHandler) UpdateDocumentType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document type ID")
+		return
+	}
+
+	var req dto.DocumentTypeRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	documentType, err := dh.documentService.UpdateDocumentType(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Document type updated successfully",
+		Data:    documentType,
+	})
+}
+
+func (dh *DocumentHandler) ListEmployeeDocuments(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	documents, err := dh.documentService.ListEmployeeDocuments(r.Context(), actor, employeeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Documents retrieved successfully",
+		Data:    documents,
+	})
+}
+
+// readDocumentUpload reads an uploaded file from a multipart form with the
+// file in the "file" field and optional "issue_date" and "expiry_date"
+// fields. The caller must close the file and remove the form once done.
+func readDocumentUpload(w http.ResponseWriter, r *http.Request) (*dto.DocumentUpload, multipart.File, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentUploadBytes)
+	if err := r.ParseMultipartForm(documentFormMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "The file cannot be larger than 25 MB")
+			return nil, nil, false
+		}
+		utils.RespondWithError(w, http.StatusBadRequest, "A multipart form with a file is required")
+		return nil, nil, false
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		r.MultipartForm.RemoveAll()
+		utils.RespondWithError(w, http.StatusBadRequest, "A file is required")
+		return nil, nil, false
+	}
+
+	upload := &dto.DocumentUpload{
+		FileName:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		IssueDate:   r.FormValue("issue_date"),
+		ExpiryDate:  r.FormValue("expiry_date"),
+	}
+	return upload, file, true
+}
+
+// UploadDocument takes a multipart form with the file in the "file" field and
+// its type in "document_type_id"
+func (dh *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	upload, file, ok := readDocumentUpload(w, r)
+	if !ok {
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	defer file.Close()
+
+	upload.DocumentTypeID, err = strconv.Atoi(r.FormValue("document_type_id"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document type ID")
+		return
+	}
+
+	document, err := dh.documentService.UploadDocument(r.Context(), actor, employeeID, upload, file)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Document uploaded successfully",
+		Data:    document,
+	})
+}
+
+// writeDocumentFile streams a stored file to the client as an attachment
+func writeDocumentFile(w http.ResponseWriter, content io.Reader, fileName string, contentType string, size int64, checksum string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	if checksum != "" {
+		w.Header().Set("X-Checksum-Sha256", checksum)
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, content)
+}
+
+// DownloadDocument streams the current version of a document to the client
+func (dh *DocumentHandler) DownloadDocument(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	content, document, err := dh.documentService.OpenDocument(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	defer content.Close()
+
+	writeDocumentFile(w, content, document.FileName, document.ContentType, document.SizeBytes, document.Checksum)
+}
+
+func (dh *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	if err := dh.documentService.DeleteDocument(r.Context(), actor, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Document deleted successfully",
+	})
+}
+
+// UploadDocumentVersion takes a multipart form like UploadDocument, without
+// the document type, and makes the file the document's current version
+func (dh *DocumentHandler) UploadDocumentVersion(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	upload, file, ok := readDocumentUpload(w, r)
+	if !ok {
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	defer file.Close()
+
+	document, err := dh.documentService.UploadDocumentVersion(r.Context(), actor, id, upload, file)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Document version uploaded successfully",
+		Data:    document,
+	})
+}
+
+func (dh *DocumentHandler) ListDocumentVersions(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	versions, err := dh.documentService.ListDocumentVersions(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Document versions retrieved successfully",
+		Data:    versions,
+	})
+}
+
+func (dh *DocumentHandler) DownloadDocumentVersion(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+	version, err := utils.ParseIntParam(r, "version")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document version")
+		return
+	}
+
+	content, documentVersion, err := dh.documentService.OpenDocumentVersion(r.Context(), actor, id, version)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	defer content.Close()
+
+	writeDocumentFile(w, content, documentVersion.FileName, documentVersion.ContentType, documentVersion.SizeBytes, documentVersion.Checksum)
+}
+
+// ListExpiringDocuments returns the documents that have expired or expire
+// within the within_days query parameter, or within the reminder window of
+// their type when it is left out
+func (dh *DocumentHandler) ListExpiringDocuments(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	withinDays, err := utils.QueryInt(r, "within_days")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid within_days")
+		return
+	}
+
+	documents, err := dh.documentService.ListExpiringDocuments(r.Context(), actor, withinDays)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Expiring documents retrieved successfully",
+		Data:    documents,
+	})
+}
+
+func (dh *DocumentHandler) ListDocumentRequirements(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	requirements, err := dh.documentService.ListDocumentRequirements(r.Context(), actor)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Document requirements retrieved successfully",
+		Data:    requirements,
+	})
+}
+
+func (dh *DocumentHandler) CreateDocumentRequirement(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.DocumentRequirementRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	requirement, err := dh.documentService.CreateDocumentRequirement(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Document requirement created successfully",
+		Data:    requirement,
+	})
+}
+
+func (dh *DocumentHandler) DeleteDocumentRequirement(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid document requirement ID")
+		return
+	}
+
+	if err := dh.documentService.DeleteDocumentRequirement(r.Context(), actor, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Document requirement deleted successfully",
+	})
+}
+
+// MissingDocuments lists the employees missing a mandatory document type
+func (dh *DocumentHandler) MissingDocuments(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	reports, err := dh.documentService.MissingDocumentsReport(r.Context(), actor)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Missing documents retrieved successfully",
+		Data:    reports,
+	})
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+type MockDocumentService struct {
+	Actor      services.Actor
+	EmployeeID int
+	DocumentID int
+	Version    int
+	WithinDays int
+	Upload     *dto.DocumentUpload
+	Content    string
+	Document   *models.Document
+	Err        error
+}
+
+func (m *MockDocumentService) ListDocumentTypes(ctx context.Context, tenantID int, status string) ([]*dto.DocumentTypeResponse, error) {
+	return []*dto.DocumentTypeResponse{}, m.Err
+}
+
+func (m *MockDocumentService) GetDocumentType(ctx context.Context, tenantID int, id int) (*dto.DocumentTypeResponse, error) {
+	return &dto.DocumentTypeResponse{ID: id}, m.Err
+}
+
+func (m *MockDocumentService) CreateDocumentType(ctx context.Context, tenantID int, req *dto.DocumentTypeRequest) (*dto.DocumentTypeResponse, error) {
+	return &dto.DocumentTypeResponse{Name: req.Name}, m.Err
+}
+
+func (m *MockDocumentService) UpdateDocumentType(ctx context.Context, tenantID int, id int, req *dto.DocumentTypeRequest) (*dto.DocumentTypeResponse, error) {
+	return &dto.DocumentTypeResponse{ID: id, Name: req.Name}, m.Err
+}
+
+func (m *MockDocumentService) ListEmployeeDocuments(ctx context.Context, actor services.Actor, employeeID int) ([]*dto.DocumentResponse, error) {
+	m.Actor = actor
+	m.EmployeeID = employeeID
+	return []*dto.DocumentResponse{}, m.Err
+}
+
+func (m *MockDocumentService) UploadDocument(ctx context.Context, actor services.Actor, employeeID int, upload *dto.DocumentUpload, file io.Reader) (*dto.DocumentResponse, error) {
+	m.Actor = actor
+	m.EmployeeID = employeeID
+	m.Upload = upload
+	content, _ := io.ReadAll(file)
+	m.Content = string(content)
+	return &dto.DocumentResponse{ID: 1}, m.Err
+}
+
+func (m *MockDocumentService) OpenDocument(ctx context.Context, actor services.Actor, id int) (io.ReadCloser, *models.Document, error) {
+	m.Actor = actor
+	m.DocumentID = id
+	if m.Err != nil {
+		return nil, nil, m.Err
+	}
+	return io.NopCloser(bytes.NewBufferString(m.Content)), m.Document, nil
+}
+
+func (m *MockDocumentService) DeleteDocument(ctx context.Context, actor services.Actor, id int) error {
+	m.Actor = actor
+	m.DocumentID = id
+	return m.Err
+}
+
+func (m *MockDocumentService) UploadDocumentVersion(ctx context.Context, actor services.Actor, id int, upload *dto.DocumentUpload, file io.Reader) (*dto.DocumentResponse, error) {
+	m.Actor = actor
+	m.DocumentID = id
+	m.Upload = upload
+	content, _ := io.ReadAll(file)
+	m.Content = string(content)
+	return &dto.DocumentResponse{ID: id, Version: 2}, m.Err
+}
+
+func (m *MockDocumentService) ListDocumentVersions(ctx context.Context, actor services.Actor, id int) ([]*dto.DocumentVersionResponse, error) {
+	m.Actor = actor
+	m.DocumentID = id
+	return []*dto.DocumentVersionResponse{}, m.Err
+}
+
+func (m *MockDocumentService) OpenDocumentVersion(ctx context.Context, actor services.Actor, id int, version int) (io.ReadCloser, *models.DocumentVersion, error) {
+	m.Actor = actor
+	m.DocumentID = id
+	m.Version = version
+	if m.Err != nil {
+		return nil, nil, m.Err
+	}
+	return io.NopCloser(bytes.NewBufferString(m.Content)), &models.DocumentVersion{DocumentID: id, Version: version, FileName: "v.pdf", ContentType: "application/pdf"}, nil
+}
+
+func (m *MockDocumentService) ListExpiringDocuments(ctx context.Context, actor services.Actor, withinDays int) ([]*dto.DocumentResponse, error) {
+	m.Actor = actor
+	m.WithinDays = withinDays
+	return []*dto.DocumentResponse{}, m.Err
+}
+
+func (m *MockDocumentService) ListDocumentRequirements(ctx context.Context, actor services.Actor) ([]*dto.DocumentRequirementResponse, error) {
+	m.Actor = actor
+	return []*dto.DocumentRequirementResponse{}, m.Err
+}
+
+func (m *MockDocumentService) CreateDocumentRequirement(ctx context.Context, actor services.Actor, req *dto.DocumentRequirementRequest) (*dto.DocumentRequirementResponse, error) {
+	m.Actor = actor
+	return &dto.DocumentRequirementResponse{DocumentTypeID: req.DocumentTypeID, Country: req.Country}, m.Err
+}
+
+func (m *MockDocumentService) DeleteDocumentRequirement(ctx context.Context, actor services.Actor, id int) error {
+	m.Actor = actor
+	return m.Err
+}
+
+func (m *MockDocumentService) MissingDocumentsReport(ctx context.Context, actor services.Actor) ([]*dto.MissingDocumentsResponse, error) {
+	m.Actor = actor
+	return []*dto.MissingDocumentsResponse{}, m.Err
+}
+
+func TestUploadDocument(t *testing.T) {
+	t.Run("passes the file and its type", func(t *testing.T) {
+		mockService := &MockDocumentService{}
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("document_type_id", "3")
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="offer.pdf"`)
+		header.Set("Content-Type", "application/pdf")
+		part, _ := writer.CreatePart(header)
+		part.Write([]byte("%PDF-1.7"))
+		writer.Close()
+
+		request, _ := http.NewRequest(http.MethodPost, "/employees/7/documents", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request = withEmployeeClaims(request, 7)
+		request = mux.SetURLVars(request, map[string]string{"id": "7"})
+
+		response := httptest.NewRecorder()
+
+		handler := &DocumentHandler{documentService: mockService}
+		handler.UploadDocument(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+		upload := mockService.Upload
+		if mockService.EmployeeID != 7 || upload.DocumentTypeID != 3 || upload.FileName != "offer.pdf" ||
+			upload.ContentType != "application/pdf" || upload.Size != 8 || mockService.Content != "%PDF-1.7" {
+			t.Errorf("got employee %d, upload %+v and content %q, want the PDF for employee 7", mockService.EmployeeID, upload, mockService.Content)
+		}
+	})
+
+	t.Run("returns 400 without a file", func(t *testing.T) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("document_type_id", "3")
+		writer.Close()
+
+		request, _ := http.NewRequest(http.MethodPost, "/employees/7/documents", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request = withEmployeeClaims(request, 7)
+		request = mux.SetURLVars(request, map[string]string{"id": "7"})
+
+		response := httptest.NewRecorder()
+
+		handler := &DocumentHandler{documentService: &MockDocumentService{}}
+		handler.UploadDocument(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestDownloadDocument(t *testing.T) {
+	t.Run("streams the file with its checksum", func(t *testing.T) {
+		mockService := &MockDocumentService{
+			Content:  "%PDF-1.7",
+			Document: &models.Document{ID: 4, FileName: "offer letter.pdf", ContentType: "application/pdf", SizeBytes: 8, Checksum: "abc123"},
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/documents/4/download", nil)
+		request = withEmployeeClaims(request, 7)
+		request = mux.SetURLVars(request, map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &DocumentHandler{documentService: mockService}
+		handler.DownloadDocument(response, request)
+
+		if response.Code != http.StatusOK || response.Body.String() != "%PDF-1.7" {
+			t.Errorf("got status %d and body %q, want the file", response.Code, response.Body.String())
+		}
+		if got := response.Header().Get("Content-Disposition"); got != `attachment; filename="offer letter.pdf"` {
+			t.Errorf("got Content-Disposition %q, want the file name", got)
+		}
+		if response.Header().Get("Content-Type") != "application/pdf" || response.Header().Get("X-Checksum-Sha256") != "abc123" {
+			t.Errorf("got headers %v, want the content type and checksum", response.Header())
+		}
+	})
+
+	t.Run("returns 403 for another employee's document", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/documents/4/download", nil)
+		request = withEmployeeClaims(request, 8)
+		request = mux.SetURLVars(request, map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &DocumentHandler{documentService: &MockDocumentService{Err: services.ErrForbidden}}
+		handler.DownloadDocument(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestUploadDocumentVersion(t *testing.T) {
+	t.Run("passes the file and its dates", func(t *testing.T) {
+		mockService := &MockDocumentService{}
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("issue_date", "2026-01-01")
+		writer.WriteField("expiry_date", "2027-01-01")
+		part, _ := writer.CreateFormFile("file", "permit.pdf")
+		part.Write([]byte("renewed"))
+		writer.Close()
+
+		request, _ := http.NewRequest(http.MethodPost, "/documents/4/versions", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request = withEmployeeClaims(request, 7)
+		request = mux.SetURLVars(request, map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &DocumentHandler{documentService: mockService}
+		handler.UploadDocumentVersion(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+		upload := mockService.Upload
+		if mockService.DocumentID != 4 || upload.FileName != "permit.pdf" || upload.IssueDate != "2026-01-01" ||
+			upload.ExpiryDate != "2027-01-01" || mockService.Content != "renewed" {
+			t.Errorf("got document %d, upload %+v and content %q, want the renewed permit for document 4", mockService.DocumentID, upload, mockService.Content)
+		}
+	})
+}
+
+func TestDownloadDocumentVersion(t *testing.T) {
+	mockService := &MockDocumentService{Content: "first"}
+
+	request, _ := http.NewRequest(http.MethodGet, "/documents/4/versions/1/download", nil)
+	request = withEmployeeClaims(request, 7)
+	request = mux.SetURLVars(request, map[string]string{"id": "4", "version": "1"})
+
+	response := httptest.NewRecorder()
+
+	handler := &DocumentHandler{documentService: mockService}
+	handler.DownloadDocumentVersion(response, request)
+
+	if response.Code != http.StatusOK || response.Body.String() != "first" {
+		t.Errorf("got status %d and body %q, want the first version", response.Code, response.Body.String())
+	}
+	if mockService.DocumentID != 4 || mockService.Version != 1 {
+		t.Errorf("got document %d version %d, want document 4 version 1", mockService.DocumentID, mockService.Version)
+	}
+}
+
+func TestListExpiringDocuments(t *testing.T) {
+	t.Run("passes the window", func(t *testing.T) {
+		mockService := &MockDocumentService{}
+
+		request, _ := http.NewRequest(http.MethodGet, "/hr/documents/expiring?within_days=60", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &DocumentHandler{documentService: mockService}
+		handler.ListExpiringDocuments(response, request)
+
+		if response.Code != http.StatusOK || mockService.WithinDays != 60 {
+			t.Errorf("got status %d and window %d, want 200 and 60", response.Code, mockService.WithinDays)
+		}
+	})
+
+	t.Run("returns 400 for an invalid window", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/hr/documents/expiring?within_days=soon", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &DocumentHandler{documentService: &MockDocumentService{}}
+		handler.ListExpiringDocuments(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestMissingDocuments(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "/hr/documents/missing", nil)
+	request = withEmployeeClaims(request, 7)
+
+	response := httptest.NewRecorder()
+
+	handler := &DocumentHandler{documentService: &MockDocumentService{Err: services.ErrForbidden}}
+	handler.MissingDocuments(response, request)
+
+	if response.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+	}
+}
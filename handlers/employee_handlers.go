@@ -8,6 +8,7 @@ import (
 	"github.com/falasefemi2/peopleos/dto"
 	"github.com/falasefemi2/peopleos/models"
 	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
 )
 
 type EmployeeHandler struct {
@@ -21,6 +22,11 @@ func NewEmployeeHandler(employeeService services.IEmployeeService) *EmployeeHand
 }
 
 func (eh *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 
 	var req dto.CreateEmployeeRequest
@@ -51,7 +57,7 @@ func (eh *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if len(req.Password) < 8 {
+	if !req.SendInvitation && len(req.Password) < 8 {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.APIResponse{
 			Success: false,
@@ -60,7 +66,7 @@ func (eh *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	employee, err := eh.employeeService.CreateEmployee(r.Context(), &req)
+	employee, err := eh.employeeService.CreateEmployee(r.Context(), claims.TenantID, claims.ID, &req)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.APIResponse{
@@ -70,10 +76,110 @@ func (eh *EmployeeHandler) CreateEmployee(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	message := "Employee created successfully"
+	if req.SendInvitation {
+		message = "Employee created and invitation sent"
+	}
+
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(models.APIResponse{
 		Success: true,
-		Message: "Employee created successfully",
+		Message: message,
 		Data:    employee,
 	})
 }
+
+// parseEmployeeFilter reads the employee listing filters from the query string
+func parseEmployeeFilter(r *http.Request) (*dto.EmployeeFilter, error) {
+	query := r.URL.Query()
+	filter := &dto.EmployeeFilter{
+		Status: query.Get("status"),
+		Search: query.Get("search"),
+	}
+
+	var err error
+	if filter.DepartmentID, err = utils.QueryInt(r, "department_id"); err != nil {
+		return nil, err
+	}
+	if filter.DesignationID, err = utils.QueryInt(r, "designation_id"); err != nil {
+		return nil, err
+	}
+	if filter.ManagerID, err = utils.QueryInt(r, "manager_id"); err != nil {
+		return nil, err
+	}
+
+	// Custom fields are filtered as cf.<key>=<value>
+	for name, values := range query {
+		if key, ok := strings.CutPrefix(name, "cf."); ok && key != "" && len(values) > 0 {
+			if filter.CustomFields == nil {
+				filter.CustomFields = map[string]string{}
+			}
+			filter.CustomFields[key] = values[0]
+		}
+	}
+
+	return filter, nil
+}
+
+func (eh *EmployeeHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var req dto.UpdateEmployeeRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	employee, err := eh.employeeService.UpdateEmployee(r.Context(), actor, employeeID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Employee updated successfully",
+		Data:    employee,
+	})
+}
+
+func (eh *EmployeeHandler) ListEmployees(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, err := parseEmployeeFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	employees, err := eh.employeeService.ListEmployees(r.Context(), actor, filter)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.APIResponse{
+		Success: true,
+		Message: "Employees retrieved successfully",
+		Data:    employees,
+	})
+}
@@ -8,8 +8,13 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/mux"
+
 	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/middleware"
 	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
 )
 
 func TestCreateEmployee(t *testing.T) {
@@ -36,6 +41,7 @@ func TestCreateEmployee(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 		request, _ := http.NewRequest(http.MethodPost, "/employees", bytes.NewReader(body))
 		request.Header.Set("Content-Type", "application/json")
+		request = withHRClaims(request)
 
 		response := httptest.NewRecorder()
 
@@ -59,6 +65,7 @@ func TestCreateEmployee(t *testing.T) {
 
 		request, _ := http.NewRequest(http.MethodPost, "/employees", bytes.NewReader([]byte("invalid json")))
 		request.Header.Set("Content-Type", "application/json")
+		request = withHRClaims(request)
 
 		response := httptest.NewRecorder()
 
@@ -93,26 +100,258 @@ func TestCreateEmployee(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 		request, _ := http.NewRequest(http.MethodPost, "/employees", bytes.NewReader(body))
 		request.Header.Set("Content-Type", "application/json")
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: mockEmployeeService}
+		handler.CreateEmployee(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns 201 without a password when sending an invitation", func(t *testing.T) {
+		mockEmployeeService := &MockEmployeeService{
+			CreateEmployeeResult: &dto.EmployeeResponse{
+				ID:           3,
+				Email:        "new.hire@company.com",
+				Status:       "draft",
+				InvitationID: 9,
+			},
+		}
+
+		reqBody := dto.CreateEmployeeRequest{
+			Email:          "new.hire@company.com",
+			FirstName:      "New",
+			LastName:       "Hire",
+			DepartmentID:   1,
+			DesignationID:  1,
+			RoleID:         2,
+			SendInvitation: true,
+		}
+
+		body, _ := json.Marshal(reqBody)
+		request, _ := http.NewRequest(http.MethodPost, "/employees", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request = withHRClaims(request)
 
 		response := httptest.NewRecorder()
 
 		handler := &EmployeeHandler{employeeService: mockEmployeeService}
 		handler.CreateEmployee(response, request)
 
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+
+		if !mockEmployeeService.CreateEmployeeCalledWith.SendInvitation {
+			t.Errorf("got send_invitation false, want true")
+		}
+	})
+
+	t.Run("returns 400 when password is short and no invitation is sent", func(t *testing.T) {
+		reqBody := dto.CreateEmployeeRequest{
+			Email:         "hr@company.com",
+			FirstName:     "HR",
+			Password:      "short",
+			DepartmentID:  1,
+			DesignationID: 1,
+			RoleID:        2,
+		}
+
+		body, _ := json.Marshal(reqBody)
+		request, _ := http.NewRequest(http.MethodPost, "/employees", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: &MockEmployeeService{}}
+		handler.CreateEmployee(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestListEmployees(t *testing.T) {
+	t.Run("returns 200 with employees for the caller's tenant", func(t *testing.T) {
+		mockEmployeeService := &MockEmployeeService{
+			ListEmployeesResult: []*dto.EmployeeSummaryResponse{
+				{ID: 1, FirstName: "Ada", Email: "ada@company.com", Status: "active"},
+			},
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees?status=active&department_id=3", nil)
+		request = request.WithContext(middleware.WithUserClaims(request.Context(), &middleware.Claims{ID: 1, TenantID: 7, Role: "HR"}))
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: mockEmployeeService}
+		handler.ListEmployees(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		if mockEmployeeService.ListEmployeesTenantID != 7 {
+			t.Errorf("got tenant %d, want %d", mockEmployeeService.ListEmployeesTenantID, 7)
+		}
+
+		if mockEmployeeService.ListEmployeesFilter.DepartmentID != 3 || mockEmployeeService.ListEmployeesFilter.Status != "active" {
+			t.Errorf("got filter %+v, want department 3 and status active", mockEmployeeService.ListEmployeesFilter)
+		}
+	})
+
+	t.Run("passes custom field filters to the service", func(t *testing.T) {
+		mockEmployeeService := &MockEmployeeService{}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees?cf.shirt_size=M&cf.union_member=true", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: mockEmployeeService}
+		handler.ListEmployees(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		customFields := mockEmployeeService.ListEmployeesFilter.CustomFields
+		if customFields["shirt_size"] != "M" || customFields["union_member"] != "true" {
+			t.Errorf("got custom field filter %v, want shirt_size M and union_member true", customFields)
+		}
+	})
+
+	t.Run("returns 400 when a custom field filter is rejected", func(t *testing.T) {
+		mockEmployeeService := &MockEmployeeService{
+			ListEmployeesError: &utils.ValidationError{Field: "cf.unknown", Message: "Unknown custom field unknown"},
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees?cf.unknown=x", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: mockEmployeeService}
+		handler.ListEmployees(response, request)
+
 		if response.Code != http.StatusBadRequest {
 			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
 		}
 	})
+
+	t.Run("returns 400 when a numeric filter is invalid", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/employees?department_id=abc", nil)
+		request = request.WithContext(middleware.WithUserClaims(request.Context(), &middleware.Claims{ID: 1, TenantID: 7, Role: "HR"}))
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: &MockEmployeeService{}}
+		handler.ListEmployees(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns 401 when claims are missing", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/employees", nil)
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: &MockEmployeeService{}}
+		handler.ListEmployees(response, request)
+
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestUpdateEmployee(t *testing.T) {
+	t.Run("returns 200 with the updated employee", func(t *testing.T) {
+		mockEmployeeService := &MockEmployeeService{
+			UpdateEmployeeResult: &dto.EmployeeSummaryResponse{ID: 4, CustomFields: map[string]interface{}{"shirt_size": "L"}},
+		}
+
+		body := []byte(`{"custom_fields": {"shirt_size": "L", "union_member": null}}`)
+		request, _ := http.NewRequest(http.MethodPut, "/employees/4", bytes.NewReader(body))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: mockEmployeeService}
+		handler.UpdateEmployee(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		req := mockEmployeeService.UpdateEmployeeCalledWith
+		if req == nil || req.CustomFields["shirt_size"] != "L" {
+			t.Fatalf("got request %+v, want shirt_size L", req)
+		}
+		if value, ok := req.CustomFields["union_member"]; !ok || value != nil {
+			t.Errorf("got union_member %v, want explicit null", value)
+		}
+	})
+
+	t.Run("returns 404 when the employee does not exist", func(t *testing.T) {
+		mockEmployeeService := &MockEmployeeService{UpdateEmployeeError: services.ErrNotFound}
+
+		request, _ := http.NewRequest(http.MethodPut, "/employees/99", bytes.NewReader([]byte(`{}`)))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "99"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeHandler{employeeService: mockEmployeeService}
+		handler.UpdateEmployee(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
 }
 
 type MockEmployeeService struct {
-	CreateEmployeeResult *dto.EmployeeResponse
-	CreateEmployeeError  error
+	CreateEmployeeResult     *dto.EmployeeResponse
+	CreateEmployeeError      error
+	CreateEmployeeCalledWith *dto.CreateEmployeeRequest
+	ListEmployeesResult      []*dto.EmployeeSummaryResponse
+	ListEmployeesError       error
+	ListEmployeesTenantID    int
+	ListEmployeesFilter      *dto.EmployeeFilter
+	UpdateEmployeeResult     *dto.EmployeeSummaryResponse
+	UpdateEmployeeError      error
+	UpdateEmployeeCalledWith *dto.UpdateEmployeeRequest
 }
 
-func (m *MockEmployeeService) CreateEmployee(ctx context.Context, req *dto.CreateEmployeeRequest) (*dto.EmployeeResponse, error) {
+func (m *MockEmployeeService) CreateEmployee(ctx context.Context, tenantID int, actorID int, req *dto.CreateEmployeeRequest) (*dto.EmployeeResponse, error) {
+	m.CreateEmployeeCalledWith = req
 	if m.CreateEmployeeError != nil {
 		return nil, m.CreateEmployeeError
 	}
 	return m.CreateEmployeeResult, nil
 }
+
+func (m *MockEmployeeService) UpdateEmployee(ctx context.Context, actor services.Actor, employeeID int, req *dto.UpdateEmployeeRequest) (*dto.EmployeeSummaryResponse, error) {
+	m.UpdateEmployeeCalledWith = req
+	if m.UpdateEmployeeError != nil {
+		return nil, m.UpdateEmployeeError
+	}
+	return m.UpdateEmployeeResult, nil
+}
+
+func (m *MockEmployeeService) ListEmployees(ctx context.Context, actor services.Actor, filter *dto.EmployeeFilter) ([]*dto.EmployeeSummaryResponse, error) {
+	m.ListEmployeesTenantID = actor.TenantID
+	m.ListEmployeesFilter = filter
+	if m.ListEmployeesError != nil {
+		return nil, m.ListEmployeesError
+	}
+	return m.ListEmployeesResult, nil
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type EmployeeProfileHandler struct {
+	profileService services.IEmployeeProfileService
+}
+
+func NewEmployeeProfileHandler(profileService services.IEmployeeProfileService) *EmployeeProfileHandler {
+	return &EmployeeProfileHandler{
+		profileService: profileService,
+	}
+}
+
+func (ph *EmployeeProfileHandler) GetPersonalDetails(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	details, err := ph.profileService.GetPersonalDetails(r.Context(), actor, employeeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Personal details found",
+		Data:    details,
+	})
+}
+
+func (ph *EmployeeProfileHandler) UpdatePersonalDetails(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var req dto.PersonalDetailsRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	details, err := ph.profileService.UpdatePersonalDetails(r.Context(), actor, employeeID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Personal details updated successfully",
+		Data:    details,
+	})
+}
+
+func (ph *EmployeeProfileHandler) ListAddresses(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	includeHistory := r.URL.Query().Get("history") == "true"
+	addresses, err := ph.profileService.ListAddresses(r.Context(), actor, employeeID, includeHistory)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Addresses retrieved successfully",
+		Data:    addresses,
+	})
+}
+
+func (ph *EmployeeProfileHandler) AddAddress(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var req dto.AddressRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	address, err := ph.profileService.AddAddress(r.Context(), actor, employeeID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Address added successfully",
+		Data:    address,
+	})
+}
+
+func (ph *EmployeeProfileHandler) ListEmergencyContacts(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	contacts, err := ph.profileService.ListEmergencyContacts(r.Context(), actor, employeeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Emergency contacts retrieved successfully",
+		Data:    contacts,
+	})
+}
+
+// SaveEmergencyContact handles both POST (create) and PUT (update by contactId)
+func (ph *EmployeeProfileHandler) SaveEmergencyContact(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	contactID := 0
+	if r.Method == http.MethodPut {
+		if contactID, err = utils.ParseIntParam(r, "contactId"); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid contact ID")
+			return
+		}
+	}
+
+	var req dto.EmergencyContactRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	contact, err := ph.profileService.SaveEmergencyContact(r.Context(), actor, employeeID, contactID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if contactID == 0 {
+		status = http.StatusCreated
+	}
+
+	utils.RespondWithJSON(w, status, utils.APIResponse{
+		Success: true,
+		Message: "Emergency contact saved successfully",
+		Data:    contact,
+	})
+}
+
+func (ph *EmployeeProfileHandler) DeleteEmergencyContact(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	contactID, err := utils.ParseIntParam(r, "contactId")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid contact ID")
+		return
+	}
+
+	if err := ph.profileService.DeleteEmergencyContact(r.Context(), actor, employeeID, contactID); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Emergency contact deleted successfully",
+	})
+}
+
+func (ph *EmployeeProfileHandler) ListBankAccounts(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	accounts, err := ph.profileService.ListBankAccounts(r.Context(), actor, employeeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Bank accounts retrieved successfully",
+		Data:    accounts,
+	})
+}
+
+// SaveBankAccount handles both POST (create) and PUT (update by accountId)
+func (ph *EmployeeProfileHandler) SaveBankAccount(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	accountID := 0
+	if r.Method == http.MethodPut {
+		if accountID, err = utils.ParseIntParam(r, "accountId"); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid bank account ID")
+			return
+		}
+	}
+
+	var req dto.BankAccountRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	account, err := ph.profileService.SaveBankAccount(r.Context(), actor, employeeID, accountID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if accountID == 0 {
+		status = http.StatusCreated
+	}
+
+	utils.RespondWithJSON(w, status, utils.APIResponse{
+		Success: true,
+		Message: "Bank account saved successfully",
+		Data:    account,
+	})
+}
+
+func (ph *EmployeeProfileHandler) DeleteBankAccount(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	accountID, err := utils.ParseIntParam(r, "accountId")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid bank account ID")
+		return
+	}
+
+	if err := ph.profileService.DeleteBankAccount(r.Context(), actor, employeeID, accountID); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Bank account deleted successfully",
+	})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/middleware"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockEmployeeProfileService struct {
+	Actor                 services.Actor
+	PersonalDetailsResult *dto.PersonalDetailsResponse
+	BankAccountsResult    []*dto.BankAccountResponse
+	SaveBankAccountResult *dto.BankAccountResponse
+	SavedContactID        int
+	Err                   error
+}
+
+func (m *MockEmployeeProfileService) GetPersonalDetails(ctx context.Context, actor services.Actor, employeeID int) (*dto.PersonalDetailsResponse, error) {
+	m.Actor = actor
+	return m.PersonalDetailsResult, m.Err
+}
+
+func (m *MockEmployeeProfileService) UpdatePersonalDetails(ctx context.Context, actor services.Actor, employeeID int, req *dto.PersonalDetailsRequest) (*dto.PersonalDetailsResponse, error) {
+	return m.PersonalDetailsResult, m.Err
+}
+
+func (m *MockEmployeeProfileService) ListAddresses(ctx context.Context, actor services.Actor, employeeID int, includeHistory bool) ([]*dto.AddressResponse, error) {
+	return nil, m.Err
+}
+
+func (m *MockEmployeeProfileService) AddAddress(ctx context.Context, actor services.Actor, employeeID int, req *dto.AddressRequest) (*dto.AddressResponse, error) {
+	return nil, m.Err
+}
+
+func (m *MockEmployeeProfileService) ListEmergencyContacts(ctx context.Context, actor services.Actor, employeeID int) ([]*dto.EmergencyContactResponse, error) {
+	return nil, m.Err
+}
+
+func (m *MockEmployeeProfileService) SaveEmergencyContact(ctx context.Context, actor services.Actor, employeeID int, contactID int, req *dto.EmergencyContactRequest) (*dto.EmergencyContactResponse, error) {
+	m.SavedContactID = contactID
+	return &dto.EmergencyContactResponse{ID: contactID}, m.Err
+}
+
+func (m *MockEmployeeProfileService) DeleteEmergencyContact(ctx context.Context, actor services.Actor, employeeID int, contactID int) error {
+	return m.Err
+}
+
+func (m *MockEmployeeProfileService) ListBankAccounts(ctx context.Context, actor services.Actor, employeeID int) ([]*dto.BankAccountResponse, error) {
+	return m.BankAccountsResult, m.Err
+}
+
+func (m *MockEmployeeProfileService) SaveBankAccount(ctx context.Context, actor services.Actor, employeeID int, accountID int, req *dto.BankAccountRequest) (*dto.BankAccountResponse, error) {
+	return m.SaveBankAccountResult, m.Err
+}
+
+func (m *MockEmployeeProfileService) DeleteBankAccount(ctx context.Context, actor services.Actor, employeeID int, accountID int) error {
+	return m.Err
+}
+
+func withEmployeeClaims(r *http.Request, employeeID int) *http.Request {
+	return r.WithContext(middleware.WithUserClaims(r.Context(), &middleware.Claims{ID: employeeID, TenantID: 1, Role: "Employee"}))
+}
+
+func TestGetPersonalDetails(t *testing.T) {
+	t.Run("passes the caller as actor and returns 200", func(t *testing.T) {
+		mockProfileService := &MockEmployeeProfileService{
+			PersonalDetailsResult: &dto.PersonalDetailsResponse{EmployeeID: 5, Gender: "female"},
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees/5/personal-details", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "5"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeProfileHandler{profileService: mockProfileService}
+		handler.GetPersonalDetails(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		if mockProfileService.Actor.EmployeeID != 5 || mockProfileService.Actor.Role != "Employee" {
+			t.Errorf("got actor %+v, want employee 5", mockProfileService.Actor)
+		}
+	})
+
+	t.Run("returns 404 when the employee does not exist", func(t *testing.T) {
+		mockProfileService := &MockEmployeeProfileService{Err: services.ErrNotFound}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees/99/personal-details", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "99"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeProfileHandler{profileService: mockProfileService}
+		handler.GetPersonalDetails(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestListBankAccounts(t *testing.T) {
+	t.Run("returns 403 when the caller may not see bank details", func(t *testing.T) {
+		mockProfileService := &MockEmployeeProfileService{Err: services.ErrForbidden}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees/5/bank-accounts", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 6), map[string]string{"id": "5"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeProfileHandler{profileService: mockProfileService}
+		handler.ListBankAccounts(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestSaveBankAccount(t *testing.T) {
+	t.Run("returns 201 when a bank account is created", func(t *testing.T) {
+		mockProfileService := &MockEmployeeProfileService{
+			SaveBankAccountResult: &dto.BankAccountResponse{ID: 1, BankName: "Test Bank"},
+		}
+
+		body, _ := json.Marshal(dto.BankAccountRequest{BankName: "Test Bank", AccountName: "Ada", AccountNumber: "0123456789"})
+		request, _ := http.NewRequest(http.MethodPost, "/employees/5/bank-accounts", bytes.NewReader(body))
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "5"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeProfileHandler{profileService: mockProfileService}
+		handler.SaveBankAccount(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+	})
+
+	t.Run("returns 400 when validation fails", func(t *testing.T) {
+		mockProfileService := &MockEmployeeProfileService{
+			Err: &utils.ValidationError{Field: "account_number", Message: "Account number is not valid for NG"},
+		}
+
+		body, _ := json.Marshal(dto.BankAccountRequest{BankName: "Test Bank", AccountName: "Ada", AccountNumber: "12"})
+		request, _ := http.NewRequest(http.MethodPost, "/employees/5/bank-accounts", bytes.NewReader(body))
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "5"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeProfileHandler{profileService: mockProfileService}
+		handler.SaveBankAccount(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestSaveEmergencyContact(t *testing.T) {
+	t.Run("updates the contact named in the path on PUT", func(t *testing.T) {
+		mockProfileService := &MockEmployeeProfileService{}
+
+		body, _ := json.Marshal(dto.EmergencyContactRequest{Name: "Bola", Relationship: "Sister", Phone: "+2348012345678"})
+		request, _ := http.NewRequest(http.MethodPut, "/employees/5/emergency-contacts/3", bytes.NewReader(body))
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "5", "contactId": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmployeeProfileHandler{profileService: mockProfileService}
+		handler.SaveEmergencyContact(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		if mockProfileService.SavedContactID != 3 {
+			t.Errorf("got contact %d, want %d", mockProfileService.SavedContactID, 3)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type EmploymentHistoryHandler struct {
+	employmentService services.IEmploymentHistoryService
+}
+
+func NewEmploymentHistoryHandler(employmentService services.IEmploymentHistoryService) *EmploymentHistoryHandler {
+	return &EmploymentHistoryHandler{
+		employmentService: employmentService,
+	}
+}
+
+func (hh *EmploymentHistoryHandler) ListHistory(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	history, err := hh.employmentService.ListHistory(r.Context(), actor, employeeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Employment history retrieved successfully",
+		Data:    history,
+	})
+}
+
+func (hh *EmploymentHistoryHandler) ScheduleChange(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var req dto.EmploymentChangeRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	change, err := hh.employmentService.ScheduleChange(r.Context(), actor, employeeID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	message := "Employment change scheduled successfully"
+	if change.Status == "applied" {
+		message = "Employment change applied successfully"
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    change,
+	})
+}
+
+func (hh *EmploymentHistoryHandler) CancelChange(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	changeID, err := utils.ParseIntParam(r, "changeId")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid change ID")
+		return
+	}
+
+	change, err := hh.employmentService.CancelChange(r.Context(), actor, employeeID, changeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Employment change cancelled successfully",
+		Data:    change,
+	})
+}
+
+// GetEmploymentAsOf returns one employee's job on the as_of date (default today)
+func (hh *EmploymentHistoryHandler) GetEmploymentAsOf(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	state, err := hh.employmentService.GetEmploymentAsOf(r.Context(), actor, employeeID, r.URL.Query().Get("as_of"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Employment retrieved successfully",
+		Data:    state,
+	})
+}
+
+// GetOrgAsOf returns the whole organisation as it stood on the as_of date
+func (hh *EmploymentHistoryHandler) GetOrgAsOf(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	states, err := hh.employmentService.GetOrgAsOf(r.Context(), actor, r.URL.Query().Get("as_of"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Organisation retrieved successfully",
+		Data:    states,
+	})
+}
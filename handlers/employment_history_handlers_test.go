@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+type MockEmploymentHistoryService struct {
+	Actor         services.Actor
+	Request       *dto.EmploymentChangeRequest
+	AsOf          string
+	ChangeResult  *dto.EmploymentHistoryResponse
+	HistoryResult []*dto.EmploymentHistoryResponse
+	StateResult   *dto.EmploymentStateResponse
+	OrgResult     []*dto.EmploymentStateResponse
+	CancelledID   int
+	Err           error
+}
+
+func (m *MockEmploymentHistoryService) ListHistory(ctx context.Context, actor services.Actor, employeeID int) ([]*dto.EmploymentHistoryResponse, error) {
+	m.Actor = actor
+	return m.HistoryResult, m.Err
+}
+
+func (m *MockEmploymentHistoryService) ScheduleChange(ctx context.Context, actor services.Actor, employeeID int, req *dto.EmploymentChangeRequest) (*dto.EmploymentHistoryResponse, error) {
+	m.Actor = actor
+	m.Request = req
+	return m.ChangeResult, m.Err
+}
+
+func (m *MockEmploymentHistoryService) CancelChange(ctx context.Context, actor services.Actor, employeeID int, changeID int) (*dto.EmploymentHistoryResponse, error) {
+	m.CancelledID = changeID
+	return m.ChangeResult, m.Err
+}
+
+func (m *MockEmploymentHistoryService) GetEmploymentAsOf(ctx context.Context, actor services.Actor, employeeID int, date string) (*dto.EmploymentStateResponse, error) {
+	m.AsOf = date
+	return m.StateResult, m.Err
+}
+
+func (m *MockEmploymentHistoryService) GetOrgAsOf(ctx context.Context, actor services.Actor, date string) ([]*dto.EmploymentStateResponse, error) {
+	m.AsOf = date
+	return m.OrgResult, m.Err
+}
+
+func TestScheduleEmploymentChange(t *testing.T) {
+	t.Run("returns 201 when a future change is scheduled", func(t *testing.T) {
+		mockService := &MockEmploymentHistoryService{
+			ChangeResult: &dto.EmploymentHistoryResponse{ID: 8, ChangeType: "promotion", Status: "scheduled"},
+		}
+
+		body := []byte(`{"change_type": "promotion", "effective_date": "2027-01-01", "designation_id": 5}`)
+		request, _ := http.NewRequest(http.MethodPost, "/employees/4/employment-history", bytes.NewReader(body))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmploymentHistoryHandler{employmentService: mockService}
+		handler.ScheduleChange(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+
+		if mockService.Request == nil || mockService.Request.DesignationID == nil || *mockService.Request.DesignationID != 5 {
+			t.Errorf("got request %+v, want designation 5", mockService.Request)
+		}
+	})
+
+	t.Run("returns 403 when the caller is not HR", func(t *testing.T) {
+		mockService := &MockEmploymentHistoryService{Err: services.ErrForbidden}
+
+		body := []byte(`{"change_type": "promotion", "effective_date": "2027-01-01", "designation_id": 5}`)
+		request, _ := http.NewRequest(http.MethodPost, "/employees/4/employment-history", bytes.NewReader(body))
+		request = mux.SetURLVars(withEmployeeClaims(request, 4), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmploymentHistoryHandler{employmentService: mockService}
+		handler.ScheduleChange(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestCancelEmploymentChange(t *testing.T) {
+	t.Run("returns 404 when the change is not scheduled", func(t *testing.T) {
+		mockService := &MockEmploymentHistoryService{Err: services.ErrNotFound}
+
+		request, _ := http.NewRequest(http.MethodDelete, "/employees/4/employment-history/8", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4", "changeId": "8"})
+
+		response := httptest.NewRecorder()
+
+		handler := &EmploymentHistoryHandler{employmentService: mockService}
+		handler.CancelChange(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+
+		if mockService.CancelledID != 8 {
+			t.Errorf("got change %d, want 8", mockService.CancelledID)
+		}
+	})
+}
+
+func TestGetOrgAsOf(t *testing.T) {
+	t.Run("passes the as_of date to the service", func(t *testing.T) {
+		mockService := &MockEmploymentHistoryService{
+			OrgResult: []*dto.EmploymentStateResponse{{EmployeeID: 1, AsOf: "2024-12-31"}},
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/org?as_of=2024-12-31", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &EmploymentHistoryHandler{employmentService: mockService}
+		handler.GetOrgAsOf(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		if mockService.AsOf != "2024-12-31" {
+			t.Errorf("got as_of %q, want 2024-12-31", mockService.AsOf)
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// respondServiceError maps well-known service errors to HTTP status codes,
+// falling back to 400 for anything else.
+func respondServiceError(w http.ResponseWriter, err error) {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		utils.RespondWithError(w, http.StatusBadRequest, validationErr.Message)
+	case errors.Is(err, services.ErrForbidden):
+		utils.RespondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrConflict):
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type ExportHandler struct {
+	exportService services.IExportService
+}
+
+func NewExportHandler(exportService services.IExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportEmployees streams the filtered employee list straight to the client
+func (xh *ExportHandler) ExportEmployees(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseEmployeeFilter(r)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := dto.EmployeeExportRequest{
+		Format: r.URL.Query().Get("format"),
+		Filter: *filter,
+	}
+	if req.Format == "" {
+		req.Format = utils.FormatCSV
+	}
+	if columns := r.URL.Query().Get("columns"); columns != "" {
+		req.Columns = strings.Split(columns, ",")
+	}
+
+	w.Header().Set("Content-Type", utils.ExportContentType(req.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="employees.%s"`, req.Format))
+
+	if err := xh.exportService.ExportEmployees(r.Context(), claims.TenantID, &req, w); err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			w.Header().Del("Content-Disposition")
+			utils.RespondWithError(w, http.StatusBadRequest, validationErr.Message)
+			return
+		}
+		// Part of the body may already be on the wire, so the status can no
+		// longer be changed; the truncated file is the best signal we can give.
+		return
+	}
+}
+
+func (xh *ExportHandler) CreateEmployeeExportJob(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.EmployeeExportRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	job, err := xh.exportService.CreateEmployeeExportJob(r.Context(), claims.TenantID, claims.ID, &req)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			utils.RespondWithError(w, http.StatusBadRequest, validationErr.Message)
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusAccepted, utils.APIResponse{
+		Success: true,
+		Message: "Export job created",
+		Data:    job,
+	})
+}
+
+func (xh *ExportHandler) GetExportJob(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid export job ID")
+		return
+	}
+
+	job, err := xh.exportService.GetExportJob(r.Context(), claims.TenantID, id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Export job not found")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Export job found",
+		Data:    job,
+	})
+}
+
+func (xh *ExportHandler) DownloadExportJob(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid export job ID")
+		return
+	}
+
+	file, job, err := xh.exportService.OpenExportJobFile(r.Context(), claims.TenantID, id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer file.Close()
+
+	fileName := fmt.Sprintf("export_%d.%s", job.ID, job.Format)
+	if job.FileName != nil {
+		fileName = *job.FileName
+	}
+
+	w.Header().Set("Content-Type", utils.ExportContentType(job.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, file)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/middleware"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockExportService struct {
+	ExportEmployeesCalledWith *dto.EmployeeExportRequest
+	ExportEmployeesOutput     string
+	ExportEmployeesError      error
+	CreateJobResult           *dto.ExportJobResponse
+	CreateJobError            error
+	GetJobResult              *dto.ExportJobResponse
+	GetJobError               error
+	OpenFileContent           string
+	OpenFileJob               *models.ExportJob
+	OpenFileError             error
+}
+
+func (m *MockExportService) ExportEmployees(ctx context.Context, tenantID int, req *dto.EmployeeExportRequest, w io.Writer) error {
+	m.ExportEmployeesCalledWith = req
+	if m.ExportEmployeesError != nil {
+		return m.ExportEmployeesError
+	}
+	_, err := io.WriteString(w, m.ExportEmployeesOutput)
+	return err
+}
+
+func (m *MockExportService) CreateEmployeeExportJob(ctx context.Context, tenantID int, requestedBy int, req *dto.EmployeeExportRequest) (*dto.ExportJobResponse, error) {
+	if m.CreateJobError != nil {
+		return nil, m.CreateJobError
+	}
+	return m.CreateJobResult, nil
+}
+
+func (m *MockExportService) GetExportJob(ctx context.Context, tenantID int, jobID int) (*dto.ExportJobResponse, error) {
+	if m.GetJobError != nil {
+		return nil, m.GetJobError
+	}
+	return m.GetJobResult, nil
+}
+
+func (m *MockExportService) OpenExportJobFile(ctx context.Context, tenantID int, jobID int) (io.ReadCloser, *models.ExportJob, error) {
+	if m.OpenFileError != nil {
+		return nil, nil, m.OpenFileError
+	}
+	return io.NopCloser(strings.NewReader(m.OpenFileContent)), m.OpenFileJob, nil
+}
+
+func withHRClaims(r *http.Request) *http.Request {
+	return r.WithContext(middleware.WithUserClaims(r.Context(), &middleware.Claims{ID: 1, TenantID: 1, Role: "HR"}))
+}
+
+func TestExportEmployees(t *testing.T) {
+	t.Run("streams the export with the requested format and columns", func(t *testing.T) {
+		mockExportService := &MockExportService{ExportEmployeesOutput: "id,email\n1,ada@company.com\n"}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees/export?format=csv&columns=id,email&status=active", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &ExportHandler{exportService: mockExportService}
+		handler.ExportEmployees(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		if got := response.Header().Get("Content-Type"); got != "text/csv" {
+			t.Errorf("got content type %q, want %q", got, "text/csv")
+		}
+
+		if response.Body.String() != mockExportService.ExportEmployeesOutput {
+			t.Errorf("got body %q, want %q", response.Body.String(), mockExportService.ExportEmployeesOutput)
+		}
+
+		calledWith := mockExportService.ExportEmployeesCalledWith
+		if len(calledWith.Columns) != 2 || calledWith.Filter.Status != "active" {
+			t.Errorf("got request %+v, want two columns and status filter", calledWith)
+		}
+	})
+
+	t.Run("returns 400 when the export request is invalid", func(t *testing.T) {
+		mockExportService := &MockExportService{
+			ExportEmployeesError: &utils.ValidationError{Field: "format", Message: "Format must be one of csv, xlsx or jsonl"},
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees/export?format=pdf", nil)
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &ExportHandler{exportService: mockExportService}
+		handler.ExportEmployees(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestCreateEmployeeExportJob(t *testing.T) {
+	t.Run("returns 202 when the job is queued", func(t *testing.T) {
+		mockExportService := &MockExportService{
+			CreateJobResult: &dto.ExportJobResponse{ID: 4, Format: "xlsx", Status: "pending"},
+		}
+
+		body, _ := json.Marshal(dto.EmployeeExportRequest{Format: "xlsx"})
+		request, _ := http.NewRequest(http.MethodPost, "/employees/exports", bytes.NewReader(body))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &ExportHandler{exportService: mockExportService}
+		handler.CreateEmployeeExportJob(response, request)
+
+		if response.Code != http.StatusAccepted {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusAccepted)
+		}
+	})
+
+	t.Run("returns 400 when request body is invalid", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/employees/exports", bytes.NewReader([]byte("invalid json")))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &ExportHandler{exportService: &MockExportService{}}
+		handler.CreateEmployeeExportJob(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestDownloadExportJob(t *testing.T) {
+	t.Run("returns the stored export file", func(t *testing.T) {
+		fileName := "employees_4.jsonl"
+		mockExportService := &MockExportService{
+			OpenFileContent: "{\"id\":\"1\"}\n",
+			OpenFileJob:     &models.ExportJob{ID: 4, Format: "jsonl", FileName: &fileName},
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/exports/4/download", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &ExportHandler{exportService: mockExportService}
+		handler.DownloadExportJob(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		if got := response.Header().Get("Content-Disposition"); !strings.Contains(got, fileName) {
+			t.Errorf("got disposition %q, want it to contain %q", got, fileName)
+		}
+	})
+
+	t.Run("returns 404 when the job is not ready", func(t *testing.T) {
+		mockExportService := &MockExportService{OpenFileError: io.ErrUnexpectedEOF}
+
+		request, _ := http.NewRequest(http.MethodGet, "/exports/4/download", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &ExportHandler{exportService: mockExportService}
+		handler.DownloadExportJob(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
@@ -1,14 +1,11 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 
-<<<<<<< HEAD
 	"github.com/falasefemi2/peopleos/models"
-=======
-	"github.com/falasefemi2/peopleos/dto"
 	"github.com/falasefemi2/peopleos/utils"
->>>>>>> c84adbd5fea15cfee43772c5a62f177c37a8ebec
 )
 
 // HealthCheck is a simple health check endpoint
@@ -23,7 +20,6 @@ func HealthCheck(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-<<<<<<< HEAD
 // AdminHandler is a placeholder for an admin-only endpoint
 func AdminHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -35,24 +31,4 @@ func AdminHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	json.NewEncoder(w).Encode(response)
-=======
-// CreateCompany creates a new company (placeholder)
-func CreateCompany(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
-		return
-	}
-
-	var req dto.CreateCompanyRequest
-	if err := utils.DecodeJSONBody(r, &req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
-
-	// TODO: Implement company creation logic
-	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
-		Success: true,
-		Message: "Company creation endpoint ready (implementation coming)",
-	})
->>>>>>> c84adbd5fea15cfee43772c5a62f177c37a8ebec
 }
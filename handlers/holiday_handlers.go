@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// maxCalendarUploadBytes caps the size of an imported iCalendar file
+const maxCalendarUploadBytes = 1 << 20
+
+type HolidayHandler struct {
+	holidayService services.IHolidayService
+}
+
+func NewHolidayHandler(holidayService services.IHolidayService) *HolidayHandler {
+	return &HolidayHandler{
+		holidayService: holidayService,
+	}
+}
+
+// ListHolidays returns every holiday in the range, with each location's
+// overrides
+func (hh *HolidayHandler) ListHolidays(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	holidays, err := hh.holidayService.ListHolidays(r.Context(), claims.TenantID, query.Get("from"), query.Get("to"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Holidays retrieved successfully",
+		Data:    holidays,
+	})
+}
+
+// ListEffectiveHolidays returns the days off in the range at a location
+func (hh *HolidayHandler) ListEffectiveHolidays(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	holidays, err := hh.holidayService.ListEffectiveHolidays(r.Context(), claims.TenantID, query.Get("location"), query.Get("from"), query.Get("to"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Holidays retrieved successfully",
+		Data:    holidays,
+	})
+}
+
+func (hh *HolidayHandler) ListMyHolidays(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	holidays, err := hh.holidayService.ListMyHolidays(r.Context(), actor, query.Get("from"), query.Get("to"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Holidays retrieved successfully",
+		Data:    holidays,
+	})
+}
+
+func (hh *HolidayHandler) CreateHoliday(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.HolidayRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	holiday, err := hh.holidayService.CreateHoliday(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Holiday created successfully",
+		Data:    holiday,
+	})
+}
+
+func (hh *HolidayHandler) UpdateHoliday(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid holiday ID")
+		return
+	}
+
+	var req dto.HolidayRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	holiday, err := hh.holidayService.UpdateHoliday(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Holiday updated successfully",
+		Data:    holiday,
+	})
+}
+
+func (hh *HolidayHandler) DeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid holiday ID")
+		return
+	}
+
+	if err := hh.holidayService.DeleteHoliday(r.Context(), claims.TenantID, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Holiday deleted successfully",
+	})
+}
+
+func (hh *HolidayHandler) LoadBundledHolidays(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	// Every field has a default, so an empty body is accepted
+	var req dto.LoadHolidaysRequest
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	result, err := hh.holidayService.LoadBundledHolidays(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Holidays loaded successfully",
+		Data:    result,
+	})
+}
+
+// ImportICalendar takes the calendar either as the "file" field of a
+// multipart form or as the raw request body
+func (hh *HolidayHandler) ImportICalendar(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCalendarUploadBytes)
+	var calendar io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "A calendar file of at most 1 MB is required")
+			return
+		}
+		defer file.Close()
+		calendar = file
+	}
+
+	result, err := hh.holidayService.ImportICalendar(r.Context(), claims.TenantID, r.URL.Query().Get("location"), calendar)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "The calendar file cannot be larger than 1 MB")
+		return
+	}
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Holidays imported successfully",
+		Data:    result,
+	})
+}
+
+func (hh *HolidayHandler) UpdateWorkLocation(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var req dto.WorkLocationRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	location, err := hh.holidayService.UpdateWorkLocation(r.Context(), claims.TenantID, employeeID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Work location updated successfully",
+		Data:    location,
+	})
+}
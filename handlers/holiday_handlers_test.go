@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockHolidayService struct {
+	Actor      services.Actor
+	TenantID   int
+	ID         int
+	EmployeeID int
+	Location   string
+	From       string
+	To         string
+	Calendar   string
+	Request    *dto.HolidayRequest
+	Load       *dto.LoadHolidaysRequest
+	Holiday    *dto.HolidayResponse
+	Holidays   []*dto.HolidayResponse
+	Err        error
+}
+
+func (m *MockHolidayService) ListHolidays(ctx context.Context, tenantID int, from string, to string) ([]*dto.HolidayResponse, error) {
+	m.TenantID = tenantID
+	m.From = from
+	m.To = to
+	return m.Holidays, m.Err
+}
+
+func (m *MockHolidayService) ListEffectiveHolidays(ctx context.Context, tenantID int, location string, from string, to string) ([]*dto.HolidayResponse, error) {
+	m.Location = location
+	m.From = from
+	m.To = to
+	return m.Holidays, m.Err
+}
+
+func (m *MockHolidayService) ListMyHolidays(ctx context.Context, actor services.Actor, from string, to string) ([]*dto.HolidayResponse, error) {
+	m.Actor = actor
+	m.From = from
+	m.To = to
+	return m.Holidays, m.Err
+}
+
+func (m *MockHolidayService) CreateHoliday(ctx context.Context, tenantID int, req *dto.HolidayRequest) (*dto.HolidayResponse, error) {
+	m.Request = req
+	return m.Holiday, m.Err
+}
+
+func (m *MockHolidayService) UpdateHoliday(ctx context.Context, tenantID int, id int, req *dto.HolidayRequest) (*dto.HolidayResponse, error) {
+	m.ID = id
+	m.Request = req
+	return m.Holiday, m.Err
+}
+
+func (m *MockHolidayService) DeleteHoliday(ctx context.Context, tenantID int, id int) error {
+	m.ID = id
+	return m.Err
+}
+
+func (m *MockHolidayService) LoadBundledHolidays(ctx context.Context, tenantID int, req *dto.LoadHolidaysRequest) (*dto.HolidayImportResponse, error) {
+	m.Load = req
+	return &dto.HolidayImportResponse{}, m.Err
+}
+
+func (m *MockHolidayService) ImportICalendar(ctx context.Context, tenantID int, location string, r io.Reader) (*dto.HolidayImportResponse, error) {
+	m.Location = location
+	calendar, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m.Calendar = string(calendar)
+	return &dto.HolidayImportResponse{Added: 1}, m.Err
+}
+
+func (m *MockHolidayService) UpdateWorkLocation(ctx context.Context, tenantID int, employeeID int, req *dto.WorkLocationRequest) (*dto.WorkLocationResponse, error) {
+	m.EmployeeID = employeeID
+	m.Location = req.Location
+	return &dto.WorkLocationResponse{EmployeeID: employeeID, Location: req.Location}, m.Err
+}
+
+const testCalendar = "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20260320\r\nSUMMARY:Eid al-Fitr\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+func TestListHolidays(t *testing.T) {
+	mockService := &MockHolidayService{}
+
+	request, _ := http.NewRequest(http.MethodGet, "/hr/holidays?from=2026-01-01&to=2026-06-30", nil)
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &HolidayHandler{holidayService: mockService}
+	handler.ListHolidays(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.From != "2026-01-01" || mockService.To != "2026-06-30" {
+		t.Errorf("got range %s to %s, want the range from the query", mockService.From, mockService.To)
+	}
+}
+
+func TestListMyHolidays(t *testing.T) {
+	mockService := &MockHolidayService{Err: &utils.ValidationError{Field: "to", Message: "To cannot be before from"}}
+
+	request, _ := http.NewRequest(http.MethodGet, "/me/holidays?from=2026-03-01&to=2026-02-01", nil)
+	request = withEmployeeClaims(request, 5)
+
+	response := httptest.NewRecorder()
+
+	handler := &HolidayHandler{holidayService: mockService}
+	handler.ListMyHolidays(response, request)
+
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+	}
+	if mockService.Actor.EmployeeID != 5 {
+		t.Errorf("got employee %d, want 5", mockService.Actor.EmployeeID)
+	}
+}
+
+func TestCreateHoliday(t *testing.T) {
+	mockService := &MockHolidayService{Holiday: &dto.HolidayResponse{ID: 1, Name: "Boxing Day"}}
+
+	body := []byte(`{"date": "2026-12-26", "name": "Boxing Day", "location": "Lagos", "is_day_off": false}`)
+	request, _ := http.NewRequest(http.MethodPost, "/hr/holidays", bytes.NewReader(body))
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &HolidayHandler{holidayService: mockService}
+	handler.CreateHoliday(response, request)
+
+	if response.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+	}
+	if mockService.Request.Location != "Lagos" || mockService.Request.IsDayOff == nil || *mockService.Request.IsDayOff {
+		t.Errorf("got request %+v, want a working day in Lagos", mockService.Request)
+	}
+}
+
+func TestDeleteHoliday(t *testing.T) {
+	mockService := &MockHolidayService{Err: services.ErrNotFound}
+
+	request, _ := http.NewRequest(http.MethodDelete, "/hr/holidays/3", nil)
+	request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "3"})
+
+	response := httptest.NewRecorder()
+
+	handler := &HolidayHandler{holidayService: mockService}
+	handler.DeleteHoliday(response, request)
+
+	if response.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+	}
+	if mockService.ID != 3 {
+		t.Errorf("got holiday %d, want 3", mockService.ID)
+	}
+}
+
+func TestLoadBundledHolidays(t *testing.T) {
+	mockService := &MockHolidayService{}
+
+	request, _ := http.NewRequest(http.MethodPost, "/hr/holidays/load", nil)
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &HolidayHandler{holidayService: mockService}
+	handler.LoadBundledHolidays(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.Load == nil || mockService.Load.Country != "" || mockService.Load.Year != 0 {
+		t.Errorf("got request %+v, want the defaults", mockService.Load)
+	}
+}
+
+func TestImportICalendar(t *testing.T) {
+	t.Run("reads the raw body", func(t *testing.T) {
+		mockService := &MockHolidayService{}
+
+		request, _ := http.NewRequest(http.MethodPost, "/hr/holidays/import?location=Lagos", bytes.NewReader([]byte(testCalendar)))
+		request.Header.Set("Content-Type", "text/calendar")
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &HolidayHandler{holidayService: mockService}
+		handler.ImportICalendar(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Location != "Lagos" || mockService.Calendar != testCalendar {
+			t.Errorf("got location %q and calendar %q, want the query location and the body", mockService.Location, mockService.Calendar)
+		}
+	})
+
+	t.Run("reads a multipart file", func(t *testing.T) {
+		mockService := &MockHolidayService{}
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, _ := writer.CreateFormFile("file", "holidays.ics")
+		part.Write([]byte(testCalendar))
+		writer.Close()
+
+		request, _ := http.NewRequest(http.MethodPost, "/hr/holidays/import", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &HolidayHandler{holidayService: mockService}
+		handler.ImportICalendar(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Calendar != testCalendar {
+			t.Errorf("got calendar %q, want the uploaded file", mockService.Calendar)
+		}
+	})
+
+	t.Run("returns 400 without a file field", func(t *testing.T) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("name", "holidays")
+		writer.Close()
+
+		request, _ := http.NewRequest(http.MethodPost, "/hr/holidays/import", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &HolidayHandler{holidayService: &MockHolidayService{}}
+		handler.ImportICalendar(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestUpdateWorkLocation(t *testing.T) {
+	mockService := &MockHolidayService{}
+
+	body := []byte(`{"location": "Lagos"}`)
+	request, _ := http.NewRequest(http.MethodPut, "/hr/employees/9/work-location", bytes.NewReader(body))
+	request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "9"})
+
+	response := httptest.NewRecorder()
+
+	handler := &HolidayHandler{holidayService: mockService}
+	handler.UpdateWorkLocation(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.EmployeeID != 9 || mockService.Location != "Lagos" {
+		t.Errorf("got employee %d and location %q, want 9 and Lagos", mockService.EmployeeID, mockService.Location)
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type InvitationHandler struct {
+	invitationService services.IInvitationService
+}
+
+func NewInvitationHandler(invitationService services.IInvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+	}
+}
+
+// GetInvitation lets the invitee confirm who the link was issued to before
+// choosing a password.
+func (ih *InvitationHandler) GetInvitation(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invitation token is required")
+		return
+	}
+
+	invitation, err := ih.invitationService.GetInvitationDetails(r.Context(), token)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Invitation found",
+		Data:    invitation,
+	})
+}
+
+// SetUpInvitationMFA issues the MFA secret the invitee confirms with a code
+// when accepting the invitation.
+func (ih *InvitationHandler) SetUpInvitationMFA(w http.ResponseWriter, r *http.Request) {
+	var req dto.InvitationMFARequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if strings.TrimSpace(req.Token) == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invitation token is required")
+		return
+	}
+
+	result, err := ih.invitationService.SetUpInvitationMFA(r.Context(), req.Token)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Add the secret to your authenticator app and accept the invitation with a code",
+		Data:    result,
+	})
+}
+
+func (ih *InvitationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req dto.AcceptInvitationRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if strings.TrimSpace(req.Token) == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invitation token is required")
+		return
+	}
+
+	if len(req.Password) < 8 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Password must be at least 8 characters")
+		return
+	}
+
+	result, err := ih.invitationService.AcceptInvitation(r.Context(), &req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Invitation accepted",
+		Data:    result,
+	})
+}
+
+func (ih *InvitationHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	invitation, err := ih.invitationService.ResendInvitation(r.Context(), claims.TenantID, id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Invitation resent",
+		Data:    invitation,
+	})
+}
+
+func (ih *InvitationHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	invitation, err := ih.invitationService.RevokeInvitation(r.Context(), claims.TenantID, id)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Invitation revoked",
+		Data:    invitation,
+	})
+}
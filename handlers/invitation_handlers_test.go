@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type MockInvitationService struct {
+	DetailsResult *dto.InvitationDetailsResponse
+	DetailsError  error
+	MFAResult     *dto.InvitationMFAResponse
+	MFAError      error
+	AcceptResult  *dto.AcceptInvitationResponse
+	AcceptError   error
+	ResendResult  *dto.InvitationResponse
+	ResendError   error
+	RevokeResult  *dto.InvitationResponse
+	RevokeError   error
+}
+
+func (m *MockInvitationService) GetInvitationDetails(ctx context.Context, token string) (*dto.InvitationDetailsResponse, error) {
+	if m.DetailsError != nil {
+		return nil, m.DetailsError
+	}
+	return m.DetailsResult, nil
+}
+
+func (m *MockInvitationService) SetUpInvitationMFA(ctx context.Context, token string) (*dto.InvitationMFAResponse, error) {
+	if m.MFAError != nil {
+		return nil, m.MFAError
+	}
+	return m.MFAResult, nil
+}
+
+func (m *MockInvitationService) AcceptInvitation(ctx context.Context, req *dto.AcceptInvitationRequest) (*dto.AcceptInvitationResponse, error) {
+	if m.AcceptError != nil {
+		return nil, m.AcceptError
+	}
+	return m.AcceptResult, nil
+}
+
+func (m *MockInvitationService) ResendInvitation(ctx context.Context, tenantID int, invitationID int) (*dto.InvitationResponse, error) {
+	if m.ResendError != nil {
+		return nil, m.ResendError
+	}
+	return m.ResendResult, nil
+}
+
+func (m *MockInvitationService) RevokeInvitation(ctx context.Context, tenantID int, invitationID int) (*dto.InvitationResponse, error) {
+	if m.RevokeError != nil {
+		return nil, m.RevokeError
+	}
+	return m.RevokeResult, nil
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	t.Run("returns 200 when the invitation is accepted", func(t *testing.T) {
+		mockInvitationService := &MockInvitationService{
+			AcceptResult: &dto.AcceptInvitationResponse{EmployeeID: 3, Email: "new.hire@company.com"},
+		}
+
+		body, _ := json.Marshal(dto.AcceptInvitationRequest{Token: "signed-token", Password: "password123"})
+		request, _ := http.NewRequest(http.MethodPost, "/invitations/accept", bytes.NewReader(body))
+
+		response := httptest.NewRecorder()
+
+		handler := &InvitationHandler{invitationService: mockInvitationService}
+		handler.AcceptInvitation(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		var apiResponse models.APIResponse
+		json.NewDecoder(response.Body).Decode(&apiResponse)
+
+		if !apiResponse.Success {
+			t.Errorf("got success %v, want true", apiResponse.Success)
+		}
+	})
+
+	t.Run("returns 400 when the password is too short", func(t *testing.T) {
+		body, _ := json.Marshal(dto.AcceptInvitationRequest{Token: "signed-token", Password: "short"})
+		request, _ := http.NewRequest(http.MethodPost, "/invitations/accept", bytes.NewReader(body))
+
+		response := httptest.NewRecorder()
+
+		handler := &InvitationHandler{invitationService: &MockInvitationService{}}
+		handler.AcceptInvitation(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns 400 when the token is invalid or expired", func(t *testing.T) {
+		mockInvitationService := &MockInvitationService{AcceptError: fmt.Errorf("invalid or expired invitation")}
+
+		body, _ := json.Marshal(dto.AcceptInvitationRequest{Token: "expired-token", Password: "password123"})
+		request, _ := http.NewRequest(http.MethodPost, "/invitations/accept", bytes.NewReader(body))
+
+		response := httptest.NewRecorder()
+
+		handler := &InvitationHandler{invitationService: mockInvitationService}
+		handler.AcceptInvitation(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestGetInvitation(t *testing.T) {
+	t.Run("returns 404 when the token does not resolve", func(t *testing.T) {
+		mockInvitationService := &MockInvitationService{DetailsError: fmt.Errorf("invalid or expired invitation")}
+
+		request, _ := http.NewRequest(http.MethodGet, "/invitations?token=bad", nil)
+
+		response := httptest.NewRecorder()
+
+		handler := &InvitationHandler{invitationService: mockInvitationService}
+		handler.GetInvitation(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestSetUpInvitationMFA(t *testing.T) {
+	t.Run("returns the secret to add to an authenticator app", func(t *testing.T) {
+		mockInvitationService := &MockInvitationService{
+			MFAResult: &dto.InvitationMFAResponse{MFASecret: "JBSWY3DPEHPK3PXP", MFAProvisioningURI: "otpauth://totp/PeopleOS:new.hire@company.com"},
+		}
+
+		body, _ := json.Marshal(dto.InvitationMFARequest{Token: "valid-token"})
+		request, _ := http.NewRequest(http.MethodPost, "/invitations/mfa", bytes.NewReader(body))
+
+		response := httptest.NewRecorder()
+
+		handler := &InvitationHandler{invitationService: mockInvitationService}
+		handler.SetUpInvitationMFA(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("returns 400 without a token", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/invitations/mfa", bytes.NewReader([]byte(`{}`)))
+
+		response := httptest.NewRecorder()
+
+		handler := &InvitationHandler{invitationService: &MockInvitationService{}}
+		handler.SetUpInvitationMFA(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	t.Run("returns 200 when a pending invitation is revoked", func(t *testing.T) {
+		mockInvitationService := &MockInvitationService{
+			RevokeResult: &dto.InvitationResponse{ID: 9, Status: "revoked"},
+		}
+
+		request, _ := http.NewRequest(http.MethodPost, "/invitations/9/revoke", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "9"})
+
+		response := httptest.NewRecorder()
+
+		handler := &InvitationHandler{invitationService: mockInvitationService}
+		handler.RevokeInvitation(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("returns 400 when the invitation is no longer pending", func(t *testing.T) {
+		mockInvitationService := &MockInvitationService{RevokeError: fmt.Errorf("invitation is no longer pending")}
+
+		request, _ := http.NewRequest(http.MethodPost, "/invitations/9/revoke", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "9"})
+
+		response := httptest.NewRecorder()
+
+		handler := &InvitationHandler{invitationService: mockInvitationService}
+		handler.RevokeInvitation(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type LeaveAccrualHandler struct {
+	accrualService services.ILeaveAccrualService
+}
+
+func NewLeaveAccrualHandler(accrualService services.ILeaveAccrualService) *LeaveAccrualHandler {
+	return &LeaveAccrualHandler{
+		accrualService: accrualService,
+	}
+}
+
+func (ah *LeaveAccrualHandler) GetAccrualPolicy(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	leaveTypeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave type ID")
+		return
+	}
+
+	policy, err := ah.accrualService.GetAccrualPolicy(r.Context(), claims.TenantID, leaveTypeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Accrual policy retrieved successfully",
+		Data:    policy,
+	})
+}
+
+func (ah *LeaveAccrualHandler) SaveAccrualPolicy(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	leaveTypeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave type ID")
+		return
+	}
+
+	var req dto.LeaveAccrualPolicyRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	policy, err := ah.accrualService.SaveAccrualPolicy(r.Context(), claims.TenantID, leaveTypeID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Accrual policy saved successfully",
+		Data:    policy,
+	})
+}
+
+func (ah *LeaveAccrualHandler) DeleteAccrualPolicy(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	leaveTypeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave type ID")
+		return
+	}
+
+	if err := ah.accrualService.DeleteAccrualPolicy(r.Context(), claims.TenantID, leaveTypeID); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Accrual policy deleted successfully",
+	})
+}
+
+func (ah *LeaveAccrualHandler) GetLeaveYear(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	leaveYear, err := ah.accrualService.GetLeaveYear(r.Context(), claims.TenantID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave year retrieved successfully",
+		Data:    leaveYear,
+	})
+}
+
+func (ah *LeaveAccrualHandler) UpdateLeaveYear(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.LeaveYearRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	leaveYear, err := ah.accrualService.UpdateLeaveYear(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave year updated successfully",
+		Data:    leaveYear,
+	})
+}
+
+// RunAccruals brings the tenant's balances up to date without waiting for the
+// scheduled run
+func (ah *LeaveAccrualHandler) RunAccruals(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := ah.accrualService.RunAccruals(r.Context(), claims.TenantID, time.Now())
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave accruals run successfully",
+		Data:    result,
+	})
+}
+
+func (ah *LeaveAccrualHandler) AdjustLeaveBalance(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.LeaveAdjustmentRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := ah.accrualService.AdjustLeaveBalance(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Leave balance adjusted successfully",
+		Data:    entry,
+	})
+}
+
+func (ah *LeaveAccrualHandler) ListEmployeeLeaveBalances(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	ah.listLeaveBalances(w, r, actor, employeeID)
+}
+
+func (ah *LeaveAccrualHandler) ListMyLeaveBalances(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	ah.listLeaveBalances(w, r, actor, actor.EmployeeID)
+}
+
+func (ah *LeaveAccrualHandler) listLeaveBalances(w http.ResponseWriter, r *http.Request, actor services.Actor, employeeID int) {
+	year, err := utils.QueryInt(r, "year")
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	balances, err := ah.accrualService.ListLeaveBalances(r.Context(), actor, employeeID, year)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave balances retrieved successfully",
+		Data:    balances,
+	})
+}
+
+func (ah *LeaveAccrualHandler) ListEmployeeLeaveLedger(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+	year, err := utils.QueryInt(r, "year")
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	leaveTypeID, err := utils.QueryInt(r, "leave_type_id")
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	entries, err := ah.accrualService.ListLeaveLedger(r.Context(), actor, employeeID, leaveTypeID, year)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave ledger retrieved successfully",
+		Data:    entries,
+	})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+type MockLeaveAccrualService struct {
+	Actor         services.Actor
+	TenantID      int
+	EmployeeID    int
+	LeaveTypeID   int
+	Year          int
+	PolicyRequest *dto.LeaveAccrualPolicyRequest
+	Adjustment    *dto.LeaveAdjustmentRequest
+	Policy        *dto.LeaveAccrualPolicyResponse
+	Balances      []*dto.LeaveBalanceResponse
+	Entries       []*dto.LeaveLedgerEntryResponse
+	Entry         *dto.LeaveLedgerEntryResponse
+	Err           error
+}
+
+func (m *MockLeaveAccrualService) GetAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int) (*dto.LeaveAccrualPolicyResponse, error) {
+	m.LeaveTypeID = leaveTypeID
+	return m.Policy, m.Err
+}
+
+func (m *MockLeaveAccrualService) SaveAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int, req *dto.LeaveAccrualPolicyRequest) (*dto.LeaveAccrualPolicyResponse, error) {
+	m.TenantID = tenantID
+	m.LeaveTypeID = leaveTypeID
+	m.PolicyRequest = req
+	return m.Policy, m.Err
+}
+
+func (m *MockLeaveAccrualService) DeleteAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int) error {
+	m.LeaveTypeID = leaveTypeID
+	return m.Err
+}
+
+func (m *MockLeaveAccrualService) GetLeaveYear(ctx context.Context, tenantID int) (*dto.LeaveYearResponse, error) {
+	return &dto.LeaveYearResponse{}, m.Err
+}
+
+func (m *MockLeaveAccrualService) UpdateLeaveYear(ctx context.Context, tenantID int, req *dto.LeaveYearRequest) (*dto.LeaveYearResponse, error) {
+	return &dto.LeaveYearResponse{StartMonth: req.StartMonth}, m.Err
+}
+
+func (m *MockLeaveAccrualService) ListLeaveBalances(ctx context.Context, actor services.Actor, employeeID int, year int) ([]*dto.LeaveBalanceResponse, error) {
+	m.Actor = actor
+	m.EmployeeID = employeeID
+	m.Year = year
+	return m.Balances, m.Err
+}
+
+func (m *MockLeaveAccrualService) ListLeaveLedger(ctx context.Context, actor services.Actor, employeeID int, leaveTypeID int, year int) ([]*dto.LeaveLedgerEntryResponse, error) {
+	m.EmployeeID = employeeID
+	m.LeaveTypeID = leaveTypeID
+	m.Year = year
+	return m.Entries, m.Err
+}
+
+func (m *MockLeaveAccrualService) AdjustLeaveBalance(ctx context.Context, actor services.Actor, req *dto.LeaveAdjustmentRequest) (*dto.LeaveLedgerEntryResponse, error) {
+	m.Actor = actor
+	m.Adjustment = req
+	return m.Entry, m.Err
+}
+
+func (m *MockLeaveAccrualService) RunAccruals(ctx context.Context, tenantID int, now time.Time) (*dto.LeaveAccrualRunResponse, error) {
+	m.TenantID = tenantID
+	return &dto.LeaveAccrualRunResponse{}, m.Err
+}
+
+func TestSaveAccrualPolicy(t *testing.T) {
+	mockService := &MockLeaveAccrualService{Policy: &dto.LeaveAccrualPolicyResponse{ID: 1, Frequency: "monthly"}}
+
+	body := []byte(`{"frequency": "monthly", "carry_over_max_days": 5, "carry_over_expiry_months": 3, "tiers": [{"min_tenure_months": 60, "days_per_year": 25}]}`)
+	request, _ := http.NewRequest(http.MethodPut, "/hr/leave-types/4/accrual-policy", bytes.NewReader(body))
+	request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4"})
+
+	response := httptest.NewRecorder()
+
+	handler := &LeaveAccrualHandler{accrualService: mockService}
+	handler.SaveAccrualPolicy(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	req := mockService.PolicyRequest
+	if mockService.LeaveTypeID != 4 || req.CarryOverMaxDays == nil || *req.CarryOverMaxDays != 5 || len(req.Tiers) != 1 {
+		t.Errorf("got leave type %d and request %+v, want the policy from the body", mockService.LeaveTypeID, req)
+	}
+}
+
+func TestAdjustLeaveBalance(t *testing.T) {
+	mockService := &MockLeaveAccrualService{Entry: &dto.LeaveLedgerEntryResponse{ID: 1, EntryType: "adjustment", Days: -2}}
+
+	body := []byte(`{"employee_id": 7, "leave_type_id": 2, "days": -2, "note": "Correction"}`)
+	request, _ := http.NewRequest(http.MethodPost, "/hr/leave-balances/adjustments", bytes.NewReader(body))
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &LeaveAccrualHandler{accrualService: mockService}
+	handler.AdjustLeaveBalance(response, request)
+
+	if response.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+	}
+	if mockService.Adjustment.EmployeeID != 7 || mockService.Adjustment.Days != -2 {
+		t.Errorf("got adjustment %+v, want -2 days for employee 7", mockService.Adjustment)
+	}
+}
+
+func TestListMyLeaveBalances(t *testing.T) {
+	t.Run("lists the caller's balances for the year", func(t *testing.T) {
+		mockService := &MockLeaveAccrualService{}
+
+		request, _ := http.NewRequest(http.MethodGet, "/me/leave-balances?year=2025", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveAccrualHandler{accrualService: mockService}
+		handler.ListMyLeaveBalances(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.EmployeeID != 5 || mockService.Year != 2025 {
+			t.Errorf("got employee %d and year %d, want 5 and 2025", mockService.EmployeeID, mockService.Year)
+		}
+	})
+
+	t.Run("returns 400 for a bad year", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/me/leave-balances?year=last", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveAccrualHandler{accrualService: &MockLeaveAccrualService{}}
+		handler.ListMyLeaveBalances(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestListEmployeeLeaveLedger(t *testing.T) {
+	mockService := &MockLeaveAccrualService{Err: services.ErrForbidden}
+
+	request, _ := http.NewRequest(http.MethodGet, "/employees/9/leave-ledger?leave_type_id=2", nil)
+	request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "9"})
+
+	response := httptest.NewRecorder()
+
+	handler := &LeaveAccrualHandler{accrualService: mockService}
+	handler.ListEmployeeLeaveLedger(response, request)
+
+	if response.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+	}
+	if mockService.EmployeeID != 9 || mockService.LeaveTypeID != 2 {
+		t.Errorf("got employee %d and leave type %d, want 9 and 2", mockService.EmployeeID, mockService.LeaveTypeID)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type LeaveCalendarHandler struct {
+	calendarService services.ILeaveCalendarService
+}
+
+func NewLeaveCalendarHandler(calendarService services.ILeaveCalendarService) *LeaveCalendarHandler {
+	return &LeaveCalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+func (ch *LeaveCalendarHandler) ListLeaveCalendar(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &dto.LeaveCalendarFilter{
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+		Status: query.Get("status"),
+	}
+	var err error
+	if filter.DepartmentID, err = utils.QueryInt(r, "department_id"); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	if filter.ManagerID, err = utils.QueryInt(r, "manager_id"); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	entries, err := ch.calendarService.ListLeaveCalendar(r.Context(), actor, filter)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave calendar retrieved successfully",
+		Data:    entries,
+	})
+}
+
+func (ch *LeaveCalendarHandler) CreateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	feed, err := ch.calendarService.CreateCalendarFeed(r.Context(), actor)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Calendar feed created successfully",
+		Data:    feed,
+	})
+}
+
+func (ch *LeaveCalendarHandler) RevokeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	if err := ch.calendarService.RevokeCalendarFeed(r.Context(), actor); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Calendar feed revoked successfully",
+	})
+}
+
+// GetCalendarFeed serves the iCalendar subscription. Calendar apps cannot
+// send a bearer token, so the secret token in the URL authenticates instead.
+func (ch *LeaveCalendarHandler) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	calendar, err := ch.calendarService.RenderCalendarFeed(r.Context(), r.URL.Query().Get("token"))
+	if errors.Is(err, services.ErrInvalidCalendarFeed) {
+		utils.RespondWithError(w, http.StatusNotFound, "Calendar feed not found")
+		return
+	}
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="leave.ics"`)
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	w.WriteHeader(http.StatusOK)
+	w.Write(calendar)
+}
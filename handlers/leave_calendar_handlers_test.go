@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+type MockLeaveCalendarService struct {
+	Actor    services.Actor
+	Filter   *dto.LeaveCalendarFilter
+	Token    string
+	Calendar []byte
+	Entries  []*dto.LeaveCalendarEntryResponse
+	Err      error
+}
+
+func (m *MockLeaveCalendarService) ListLeaveCalendar(ctx context.Context, actor services.Actor, filter *dto.LeaveCalendarFilter) ([]*dto.LeaveCalendarEntryResponse, error) {
+	m.Actor = actor
+	m.Filter = filter
+	return m.Entries, m.Err
+}
+
+func (m *MockLeaveCalendarService) CreateCalendarFeed(ctx context.Context, actor services.Actor) (*dto.CalendarFeedResponse, error) {
+	m.Actor = actor
+	return &dto.CalendarFeedResponse{URL: "http://localhost:8080/calendar/leave.ics?token=abc", Token: "abc"}, m.Err
+}
+
+func (m *MockLeaveCalendarService) RevokeCalendarFeed(ctx context.Context, actor services.Actor) error {
+	m.Actor = actor
+	return m.Err
+}
+
+func (m *MockLeaveCalendarService) RenderCalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	m.Token = token
+	return m.Calendar, m.Err
+}
+
+func TestListLeaveCalendar(t *testing.T) {
+	t.Run("passes the filter to the service", func(t *testing.T) {
+		mockService := &MockLeaveCalendarService{}
+
+		request, _ := http.NewRequest(http.MethodGet, "/calendar/leave?from=2026-03-01&to=2026-03-31&department_id=3&manager_id=9&status=pending", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveCalendarHandler{calendarService: mockService}
+		handler.ListLeaveCalendar(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		filter := mockService.Filter
+		if filter.From != "2026-03-01" || filter.To != "2026-03-31" || filter.DepartmentID != 3 || filter.ManagerID != 9 || filter.Status != "pending" {
+			t.Errorf("got filter %+v, want the filter from the query", filter)
+		}
+		if mockService.Actor.EmployeeID != 5 {
+			t.Errorf("got employee %d, want 5", mockService.Actor.EmployeeID)
+		}
+	})
+
+	t.Run("returns 400 for a bad department", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/calendar/leave?department_id=sales", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveCalendarHandler{calendarService: &MockLeaveCalendarService{}}
+		handler.ListLeaveCalendar(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("returns 403 outside the caller's reporting line", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/calendar/leave?manager_id=2", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveCalendarHandler{calendarService: &MockLeaveCalendarService{Err: services.ErrForbidden}}
+		handler.ListLeaveCalendar(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestCreateCalendarFeed(t *testing.T) {
+	mockService := &MockLeaveCalendarService{}
+
+	request, _ := http.NewRequest(http.MethodPost, "/me/calendar-feed", nil)
+	request = withEmployeeClaims(request, 5)
+
+	response := httptest.NewRecorder()
+
+	handler := &LeaveCalendarHandler{calendarService: mockService}
+	handler.CreateCalendarFeed(response, request)
+
+	if response.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+	}
+	if mockService.Actor.EmployeeID != 5 {
+		t.Errorf("got employee %d, want 5", mockService.Actor.EmployeeID)
+	}
+}
+
+func TestGetCalendarFeed(t *testing.T) {
+	t.Run("serves the calendar without claims", func(t *testing.T) {
+		mockService := &MockLeaveCalendarService{Calendar: []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")}
+
+		request, _ := http.NewRequest(http.MethodGet, "/calendar/leave.ics?token=abc", nil)
+		response := httptest.NewRecorder()
+
+		handler := &LeaveCalendarHandler{calendarService: mockService}
+		handler.GetCalendarFeed(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Token != "abc" {
+			t.Errorf("got token %q, want abc", mockService.Token)
+		}
+		if got := response.Header().Get("Content-Type"); got != "text/calendar; charset=utf-8" {
+			t.Errorf("got content type %q, want text/calendar", got)
+		}
+	})
+
+	t.Run("returns 404 for an unknown token", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/calendar/leave.ics?token=revoked", nil)
+		response := httptest.NewRecorder()
+
+		handler := &LeaveCalendarHandler{calendarService: &MockLeaveCalendarService{Err: services.ErrInvalidCalendarFeed}}
+		handler.GetCalendarFeed(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type LeaveRequestHandler struct {
+	leaveRequestService services.ILeaveRequestService
+}
+
+func NewLeaveRequestHandler(leaveRequestService services.ILeaveRequestService) *LeaveRequestHandler {
+	return &LeaveRequestHandler{
+		leaveRequestService: leaveRequestService,
+	}
+}
+
+func (rh *LeaveRequestHandler) SubmitLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateLeaveRequestRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	request, err := rh.leaveRequestService.SubmitLeaveRequest(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Leave request submitted successfully",
+		Data:    request,
+	})
+}
+
+func (rh *LeaveRequestHandler) ListMyLeaveRequests(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := rh.leaveRequestService.ListMyLeaveRequests(r.Context(), actor, r.URL.Query().Get("status"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave requests retrieved successfully",
+		Data:    requests,
+	})
+}
+
+func (rh *LeaveRequestHandler) ListLeaveRequests(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	filter := &dto.LeaveRequestFilter{Status: r.URL.Query().Get("status")}
+	var err error
+	if filter.EmployeeID, err = utils.QueryInt(r, "employee_id"); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	requests, err := rh.leaveRequestService.ListLeaveRequests(r.Context(), actor, filter)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave requests retrieved successfully",
+		Data:    requests,
+	})
+}
+
+func (rh *LeaveRequestHandler) GetLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave request ID")
+		return
+	}
+
+	request, err := rh.leaveRequestService.GetLeaveRequest(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave request retrieved successfully",
+		Data:    request,
+	})
+}
+
+func (rh *LeaveRequestHandler) ApproveLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	rh.decide(w, r, rh.leaveRequestService.ApproveLeaveRequest, "Leave request approved successfully")
+}
+
+func (rh *LeaveRequestHandler) RejectLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	rh.decide(w, r, rh.leaveRequestService.RejectLeaveRequest, "Leave request rejected successfully")
+}
+
+type leaveDecisionFunc func(ctx context.Context, actor services.Actor, id int, req *dto.LeaveDecisionRequest) (*dto.LeaveRequestResponse, error)
+
+func (rh *LeaveRequestHandler) decide(w http.ResponseWriter, r *http.Request, decide leaveDecisionFunc, message string) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave request ID")
+		return
+	}
+
+	// The comment is optional, so an empty body is accepted
+	var req dto.LeaveDecisionRequest
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	request, err := decide(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    request,
+	})
+}
+
+func (rh *LeaveRequestHandler) CancelLeaveRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave request ID")
+		return
+	}
+
+	// The reason is optional, so an empty body is accepted
+	var req dto.CancelLeaveRequestRequest
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	request, err := rh.leaveRequestService.CancelLeaveRequest(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave request cancelled successfully",
+		Data:    request,
+	})
+}
+
+func (rh *LeaveRequestHandler) GetWorkWeek(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	workWeek, err := rh.leaveRequestService.GetWorkWeek(r.Context(), claims.TenantID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Work week retrieved successfully",
+		Data:    workWeek,
+	})
+}
+
+func (rh *LeaveRequestHandler) UpdateWorkWeek(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.WorkWeekRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	workWeek, err := rh.leaveRequestService.UpdateWorkWeek(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Work week updated successfully",
+		Data:    workWeek,
+	})
+}
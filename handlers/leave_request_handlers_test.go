@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockLeaveRequestService struct {
+	Actor         services.Actor
+	TenantID      int
+	ID            int
+	Status        string
+	Request       *dto.CreateLeaveRequestRequest
+	Decision      *dto.LeaveDecisionRequest
+	Cancellation  *dto.CancelLeaveRequestRequest
+	Filter        *dto.LeaveRequestFilter
+	WorkWeek      *dto.WorkWeekRequest
+	Result        *dto.LeaveRequestResponse
+	ListResult    []*dto.LeaveRequestResponse
+	WorkWeekValue *dto.WorkWeekResponse
+	Err           error
+}
+
+func (m *MockLeaveRequestService) SubmitLeaveRequest(ctx context.Context, actor services.Actor, req *dto.CreateLeaveRequestRequest) (*dto.LeaveRequestResponse, error) {
+	m.Actor = actor
+	m.Request = req
+	return m.Result, m.Err
+}
+
+func (m *MockLeaveRequestService) ListMyLeaveRequests(ctx context.Context, actor services.Actor, status string) ([]*dto.LeaveRequestResponse, error) {
+	m.Actor = actor
+	m.Status = status
+	return m.ListResult, m.Err
+}
+
+func (m *MockLeaveRequestService) ListLeaveRequests(ctx context.Context, actor services.Actor, filter *dto.LeaveRequestFilter) ([]*dto.LeaveRequestResponse, error) {
+	m.Actor = actor
+	m.Filter = filter
+	return m.ListResult, m.Err
+}
+
+func (m *MockLeaveRequestService) GetLeaveRequest(ctx context.Context, actor services.Actor, id int) (*dto.LeaveRequestResponse, error) {
+	m.ID = id
+	return m.Result, m.Err
+}
+
+func (m *MockLeaveRequestService) ApproveLeaveRequest(ctx context.Context, actor services.Actor, id int, req *dto.LeaveDecisionRequest) (*dto.LeaveRequestResponse, error) {
+	m.ID = id
+	m.Decision = req
+	return m.Result, m.Err
+}
+
+func (m *MockLeaveRequestService) RejectLeaveRequest(ctx context.Context, actor services.Actor, id int, req *dto.LeaveDecisionRequest) (*dto.LeaveRequestResponse, error) {
+	m.ID = id
+	m.Decision = req
+	return m.Result, m.Err
+}
+
+func (m *MockLeaveRequestService) CancelLeaveRequest(ctx context.Context, actor services.Actor, id int, req *dto.CancelLeaveRequestRequest) (*dto.LeaveRequestResponse, error) {
+	m.ID = id
+	m.Cancellation = req
+	return m.Result, m.Err
+}
+
+func (m *MockLeaveRequestService) GetWorkWeek(ctx context.Context, tenantID int) (*dto.WorkWeekResponse, error) {
+	m.TenantID = tenantID
+	return m.WorkWeekValue, m.Err
+}
+
+func (m *MockLeaveRequestService) UpdateWorkWeek(ctx context.Context, tenantID int, req *dto.WorkWeekRequest) (*dto.WorkWeekResponse, error) {
+	m.TenantID = tenantID
+	m.WorkWeek = req
+	return m.WorkWeekValue, m.Err
+}
+
+func TestSubmitLeaveRequest(t *testing.T) {
+	t.Run("returns 201 for the caller", func(t *testing.T) {
+		mockService := &MockLeaveRequestService{
+			Result: &dto.LeaveRequestResponse{ID: 1, Status: "pending"},
+		}
+
+		body := []byte(`{"leave_type_id": 2, "start_date": "2026-03-09", "end_date": "2026-03-13", "reason": "Holiday"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/me/leave-requests", bytes.NewReader(body))
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveRequestHandler{leaveRequestService: mockService}
+		handler.SubmitLeaveRequest(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+		if mockService.Actor.EmployeeID != 5 || mockService.Request.LeaveTypeID != 2 {
+			t.Errorf("got actor %+v and request %+v, want employee 5 and leave type 2", mockService.Actor, mockService.Request)
+		}
+	})
+
+	t.Run("returns 400 when the balance is too low", func(t *testing.T) {
+		mockService := &MockLeaveRequestService{
+			Err: &utils.ValidationError{Field: "leave_type_id", Message: "Insufficient leave balance"},
+		}
+
+		body := []byte(`{"leave_type_id": 2, "start_date": "2026-03-09", "end_date": "2026-03-13"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/me/leave-requests", bytes.NewReader(body))
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveRequestHandler{leaveRequestService: mockService}
+		handler.SubmitLeaveRequest(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestListLeaveRequests(t *testing.T) {
+	t.Run("passes the filters", func(t *testing.T) {
+		mockService := &MockLeaveRequestService{}
+
+		request, _ := http.NewRequest(http.MethodGet, "/leave-requests?status=pending&employee_id=7", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveRequestHandler{leaveRequestService: mockService}
+		handler.ListLeaveRequests(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Filter.Status != "pending" || mockService.Filter.EmployeeID != 7 {
+			t.Errorf("got filter %+v, want pending for employee 7", mockService.Filter)
+		}
+	})
+
+	t.Run("returns 400 for a bad employee id", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/leave-requests?employee_id=abc", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveRequestHandler{leaveRequestService: &MockLeaveRequestService{}}
+		handler.ListLeaveRequests(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestApproveLeaveRequest(t *testing.T) {
+	t.Run("returns 403 when the caller may not decide", func(t *testing.T) {
+		mockService := &MockLeaveRequestService{Err: services.ErrForbidden}
+
+		request, _ := http.NewRequest(http.MethodPost, "/leave-requests/3/approve", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveRequestHandler{leaveRequestService: mockService}
+		handler.ApproveLeaveRequest(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("passes the comment", func(t *testing.T) {
+		mockService := &MockLeaveRequestService{Result: &dto.LeaveRequestResponse{ID: 3, Status: "approved"}}
+
+		body := []byte(`{"comment": "Enjoy"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/leave-requests/3/approve", bytes.NewReader(body))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveRequestHandler{leaveRequestService: mockService}
+		handler.ApproveLeaveRequest(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.ID != 3 || mockService.Decision.Comment != "Enjoy" {
+			t.Errorf("got id %d and decision %+v, want 3 with the comment", mockService.ID, mockService.Decision)
+		}
+	})
+}
+
+func TestCancelLeaveRequest(t *testing.T) {
+	mockService := &MockLeaveRequestService{Result: &dto.LeaveRequestResponse{ID: 3, Status: "cancelled"}}
+
+	request, _ := http.NewRequest(http.MethodPost, "/me/leave-requests/3/cancel", nil)
+	request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+	response := httptest.NewRecorder()
+
+	handler := &LeaveRequestHandler{leaveRequestService: mockService}
+	handler.CancelLeaveRequest(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.Cancellation == nil || mockService.Cancellation.Reason != "" {
+		t.Errorf("got cancellation %+v, want an empty reason", mockService.Cancellation)
+	}
+}
+
+func TestUpdateWorkWeek(t *testing.T) {
+	mockService := &MockLeaveRequestService{
+		WorkWeekValue: &dto.WorkWeekResponse{WorkDays: []string{"sunday", "monday"}},
+	}
+
+	body := []byte(`{"work_days": ["sunday", "monday"]}`)
+	request, _ := http.NewRequest(http.MethodPut, "/hr/work-week", bytes.NewReader(body))
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &LeaveRequestHandler{leaveRequestService: mockService}
+	handler.UpdateWorkWeek(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if len(mockService.WorkWeek.WorkDays) != 2 {
+		t.Errorf("got work week %+v, want two days", mockService.WorkWeek)
+	}
+}
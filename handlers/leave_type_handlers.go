@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type LeaveTypeHandler struct {
+	leaveTypeService services.ILeaveTypeService
+}
+
+func NewLeaveTypeHandler(leaveTypeService services.ILeaveTypeService) *LeaveTypeHandler {
+	return &LeaveTypeHandler{
+		leaveTypeService: leaveTypeService,
+	}
+}
+
+func (lh *LeaveTypeHandler) ListLeaveTypes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	leaveTypes, err := lh.leaveTypeService.ListLeaveTypes(r.Context(), claims.TenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave types retrieved successfully",
+		Data:    leaveTypes,
+	})
+}
+
+func (lh *LeaveTypeHandler) GetLeaveType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave type ID")
+		return
+	}
+
+	leaveType, err := lh.leaveTypeService.GetLeaveType(r.Context(), claims.TenantID, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave type retrieved successfully",
+		Data:    leaveType,
+	})
+}
+
+func (lh *LeaveTypeHandler) CreateLeaveType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.LeaveTypeRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	leaveType, err := lh.leaveTypeService.CreateLeaveType(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Leave type created successfully",
+		Data:    leaveType,
+	})
+}
+
+func (lh *LeaveTypeHandler) UpdateLeaveType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave type ID")
+		return
+	}
+
+	var req dto.LeaveTypeRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	leaveType, err := lh.leaveTypeService.UpdateLeaveType(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave type updated successfully",
+		Data:    leaveType,
+	})
+}
+
+func (lh *LeaveTypeHandler) DeleteLeaveType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid leave type ID")
+		return
+	}
+
+	if err := lh.leaveTypeService.DeleteLeaveType(r.Context(), claims.TenantID, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave type deleted successfully",
+	})
+}
+
+func (lh *LeaveTypeHandler) ListMyLeaveTypes(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	leaveTypes, err := lh.leaveTypeService.ListEligibleLeaveTypes(r.Context(), actor)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Leave types retrieved successfully",
+		Data:    leaveTypes,
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockLeaveTypeService struct {
+	Actor      services.Actor
+	TenantID   int
+	ID         int
+	Status     string
+	Request    *dto.LeaveTypeRequest
+	Result     *dto.LeaveTypeResponse
+	ListResult []*dto.LeaveTypeResponse
+	Err        error
+}
+
+func (m *MockLeaveTypeService) ListLeaveTypes(ctx context.Context, tenantID int, status string) ([]*dto.LeaveTypeResponse, error) {
+	m.TenantID = tenantID
+	m.Status = status
+	return m.ListResult, m.Err
+}
+
+func (m *MockLeaveTypeService) GetLeaveType(ctx context.Context, tenantID int, id int) (*dto.LeaveTypeResponse, error) {
+	m.ID = id
+	return m.Result, m.Err
+}
+
+func (m *MockLeaveTypeService) CreateLeaveType(ctx context.Context, tenantID int, req *dto.LeaveTypeRequest) (*dto.LeaveTypeResponse, error) {
+	m.TenantID = tenantID
+	m.Request = req
+	return m.Result, m.Err
+}
+
+func (m *MockLeaveTypeService) UpdateLeaveType(ctx context.Context, tenantID int, id int, req *dto.LeaveTypeRequest) (*dto.LeaveTypeResponse, error) {
+	m.ID = id
+	m.Request = req
+	return m.Result, m.Err
+}
+
+func (m *MockLeaveTypeService) DeleteLeaveType(ctx context.Context, tenantID int, id int) error {
+	m.ID = id
+	return m.Err
+}
+
+func (m *MockLeaveTypeService) ListEligibleLeaveTypes(ctx context.Context, actor services.Actor) ([]*dto.LeaveTypeResponse, error) {
+	m.Actor = actor
+	return m.ListResult, m.Err
+}
+
+func TestCreateLeaveType(t *testing.T) {
+	t.Run("returns 201 with the rules from the body", func(t *testing.T) {
+		mockService := &MockLeaveTypeService{
+			Result: &dto.LeaveTypeResponse{ID: 1, Name: "Maternity", IsPaid: true},
+		}
+
+		body := []byte(`{"name": "Maternity", "days_per_year": 84, "is_paid": false, "eligible_gender": "female", "min_tenure_months": 6, "max_consecutive_days": 84, "allow_half_day": true}`)
+		request, _ := http.NewRequest(http.MethodPost, "/hr/leave-types", bytes.NewReader(body))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveTypeHandler{leaveTypeService: mockService}
+		handler.CreateLeaveType(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+
+		req := mockService.Request
+		if req.IsPaid == nil || *req.IsPaid || req.EligibleGender != "female" || req.MinTenureMonths != 6 || req.MaxConsecutiveDays == nil || !req.AllowHalfDay {
+			t.Errorf("got request %+v, want the rules from the body", req)
+		}
+	})
+
+	t.Run("returns 400 when validation fails", func(t *testing.T) {
+		mockService := &MockLeaveTypeService{
+			Err: &utils.ValidationError{Field: "name", Message: "A leave type with this name already exists"},
+		}
+
+		request, _ := http.NewRequest(http.MethodPost, "/hr/leave-types", bytes.NewReader([]byte(`{"name": "Annual"}`)))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &LeaveTypeHandler{leaveTypeService: mockService}
+		handler.CreateLeaveType(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestGetLeaveType(t *testing.T) {
+	mockService := &MockLeaveTypeService{Err: fmt.Errorf("leave type %w", services.ErrNotFound)}
+
+	request, _ := http.NewRequest(http.MethodGet, "/hr/leave-types/9", nil)
+	request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "9"})
+
+	response := httptest.NewRecorder()
+
+	handler := &LeaveTypeHandler{leaveTypeService: mockService}
+	handler.GetLeaveType(response, request)
+
+	if response.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+	}
+	if mockService.ID != 9 {
+		t.Errorf("got id %d, want 9", mockService.ID)
+	}
+}
+
+func TestListMyLeaveTypes(t *testing.T) {
+	mockService := &MockLeaveTypeService{
+		ListResult: []*dto.LeaveTypeResponse{{ID: 1, Name: "Annual"}},
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "/me/leave-types", nil)
+	request = withEmployeeClaims(request, 5)
+
+	response := httptest.NewRecorder()
+
+	handler := &LeaveTypeHandler{leaveTypeService: mockService}
+	handler.ListMyLeaveTypes(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.Actor.EmployeeID != 5 {
+		t.Errorf("got actor %+v, want employee 5", mockService.Actor)
+	}
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type LetterHandler struct {
+	letterService services.ILetterService
+}
+
+func NewLetterHandler(letterService services.ILetterService) *LetterHandler {
+	return &LetterHandler{
+		letterService: letterService,
+	}
+}
+
+func (lh *LetterHandler) ListLetterTemplates(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := lh.letterService.ListLetterTemplates(r.Context(), claims.TenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Letter templates retrieved successfully",
+		Data:    templates,
+	})
+}
+
+// ListLetterPlaceholders returns the fields letter templates may use
+func (lh *LetterHandler) ListLetterPlaceholders(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Letter placeholders retrieved successfully",
+		Data:    services.LetterPlaceholders(),
+	})
+}
+
+func (lh *LetterHandler) GetLetterTemplate(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid letter template ID")
+		return
+	}
+
+	template, err := lh.letterService.GetLetterTemplate(r.Context(), claims.TenantID, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Letter template retrieved successfully",
+		Data:    template,
+	})
+}
+
+func (lh *LetterHandler) CreateLetterTemplate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.LetterTemplateRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := lh.letterService.CreateLetterTemplate(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Letter template created successfully",
+		Data:    template,
+	})
+}
+
+func (lh *LetterHandler) UpdateLetterTemplate(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid letter template ID")
+		return
+	}
+
+	var req dto.LetterTemplateRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := lh.letterService.UpdateLetterTemplate(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Letter template updated successfully",
+		Data:    template,
+	})
+}
+
+// PreviewLetter renders a template for an employee and returns the file
+// without saving it
+func (lh *LetterHandler) PreviewLetter(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid letter template ID")
+		return
+	}
+
+	var req dto.GenerateLetterRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	letter, err := lh.letterService.PreviewLetter(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	contentType := letter.ContentType
+	if contentType == "text/html" {
+		contentType = "text/html; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": letter.FileName}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(letter.Content)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Previews hold employee data and are rendered from tenant templates, so
+	// nothing in them may run
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	w.WriteHeader(http.StatusOK)
+	w.Write(letter.Content)
+}
+
+// GenerateLetter renders a template for an employee and saves it as one of
+// their documents
+func (lh *LetterHandler) GenerateLetter(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid letter template ID")
+		return
+	}
+
+	var req dto.GenerateLetterRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	document, err := lh.letterService.GenerateLetter(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Letter generated successfully",
+		Data:    document,
+	})
+}
+
+// BulkGenerateLetters generates a letter for every employee matching a filter
+func (lh *LetterHandler) BulkGenerateLetters(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid letter template ID")
+		return
+	}
+
+	var req dto.BulkGenerateLettersRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := lh.letterService.BulkGenerateLetters(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: strconv.Itoa(len(result.Generated)) + " letters generated",
+		Data:    result,
+	})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+type MockLetterService struct {
+	Actor       services.Actor
+	TemplateID  int
+	Request     *dto.GenerateLetterRequest
+	BulkRequest *dto.BulkGenerateLettersRequest
+	Letter      *services.RenderedLetter
+	Err         error
+}
+
+func (m *MockLetterService) ListLetterTemplates(ctx context.Context, tenantID int, status string) ([]*dto.LetterTemplateResponse, error) {
+	return []*dto.LetterTemplateResponse{}, m.Err
+}
+
+func (m *MockLetterService) GetLetterTemplate(ctx context.Context, tenantID int, id int) (*dto.LetterTemplateResponse, error) {
+	return &dto.LetterTemplateResponse{ID: id}, m.Err
+}
+
+func (m *MockLetterService) CreateLetterTemplate(ctx context.Context, actor services.Actor, req *dto.LetterTemplateRequest) (*dto.LetterTemplateResponse, error) {
+	m.Actor = actor
+	return &dto.LetterTemplateResponse{Name: req.Name}, m.Err
+}
+
+func (m *MockLetterService) UpdateLetterTemplate(ctx context.Context, tenantID int, id int, req *dto.LetterTemplateRequest) (*dto.LetterTemplateResponse, error) {
+	return &dto.LetterTemplateResponse{ID: id, Name: req.Name}, m.Err
+}
+
+func (m *MockLetterService) PreviewLetter(ctx context.Context, actor services.Actor, id int, req *dto.GenerateLetterRequest) (*services.RenderedLetter, error) {
+	m.Actor = actor
+	m.TemplateID = id
+	m.Request = req
+	return m.Letter, m.Err
+}
+
+func (m *MockLetterService) GenerateLetter(ctx context.Context, actor services.Actor, id int, req *dto.GenerateLetterRequest) (*dto.DocumentResponse, error) {
+	m.Actor = actor
+	m.TemplateID = id
+	m.Request = req
+	return &dto.DocumentResponse{ID: 1}, m.Err
+}
+
+func (m *MockLetterService) BulkGenerateLetters(ctx context.Context, actor services.Actor, id int, req *dto.BulkGenerateLettersRequest) (*dto.BulkGenerateLettersResponse, error) {
+	m.Actor = actor
+	m.TemplateID = id
+	m.BulkRequest = req
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &dto.BulkGenerateLettersResponse{
+		Generated: []*dto.DocumentResponse{{ID: 1}, {ID: 2}},
+		Failed:    []dto.LetterFailure{{EmployeeID: 3, Error: "Letter template is inactive"}},
+	}, nil
+}
+
+func TestPreviewLetter(t *testing.T) {
+	t.Run("returns the rendered letter inline", func(t *testing.T) {
+		mockService := &MockLetterService{
+			Letter: &services.RenderedLetter{FileName: "Offer - Ada Obi.html", ContentType: "text/html", Content: []byte("<p>Dear Ada</p>")},
+		}
+
+		body, _ := json.Marshal(dto.GenerateLetterRequest{EmployeeID: 7, Format: "html"})
+		request, _ := http.NewRequest(http.MethodPost, "/hr/letter-templates/2/preview", bytes.NewReader(body))
+		request = withHRClaims(request)
+		request = mux.SetURLVars(request, map[string]string{"id": "2"})
+
+		response := httptest.NewRecorder()
+
+		handler := &LetterHandler{letterService: mockService}
+		handler.PreviewLetter(response, request)
+
+		if response.Code != http.StatusOK || response.Body.String() != "<p>Dear Ada</p>" {
+			t.Errorf("got status %d and body %q, want the letter", response.Code, response.Body.String())
+		}
+		if got := response.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Errorf("got Content-Type %q, want UTF-8 HTML", got)
+		}
+		if got := response.Header().Get("Content-Disposition"); got != `inline; filename="Offer - Ada Obi.html"` {
+			t.Errorf("got Content-Disposition %q, want the file inline", got)
+		}
+		if mockService.TemplateID != 2 || mockService.Request.EmployeeID != 7 || mockService.Request.Format != "html" {
+			t.Errorf("got template %d and request %+v, want template 2 for employee 7", mockService.TemplateID, mockService.Request)
+		}
+	})
+
+	t.Run("returns 404 for an unknown employee", func(t *testing.T) {
+		body, _ := json.Marshal(dto.GenerateLetterRequest{EmployeeID: 99})
+		request, _ := http.NewRequest(http.MethodPost, "/hr/letter-templates/2/preview", bytes.NewReader(body))
+		request = withHRClaims(request)
+		request = mux.SetURLVars(request, map[string]string{"id": "2"})
+
+		response := httptest.NewRecorder()
+
+		handler := &LetterHandler{letterService: &MockLetterService{Err: services.ErrNotFound}}
+		handler.PreviewLetter(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestBulkGenerateLetters(t *testing.T) {
+	mockService := &MockLetterService{}
+
+	body, _ := json.Marshal(dto.BulkGenerateLettersRequest{Filter: dto.EmployeeFilter{DepartmentID: 4, Status: "active"}})
+	request, _ := http.NewRequest(http.MethodPost, "/hr/letter-templates/2/generate-bulk", bytes.NewReader(body))
+	request = withHRClaims(request)
+	request = mux.SetURLVars(request, map[string]string{"id": "2"})
+
+	response := httptest.NewRecorder()
+
+	handler := &LetterHandler{letterService: mockService}
+	handler.BulkGenerateLetters(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if filter := mockService.BulkRequest.Filter; filter.DepartmentID != 4 || filter.Status != "active" {
+		t.Errorf("got filter %+v, want active employees of department 4", filter)
+	}
+
+	var result struct {
+		Message string
+		Data    dto.BulkGenerateLettersResponse
+	}
+	json.NewDecoder(response.Body).Decode(&result)
+	if result.Message != "2 letters generated" || len(result.Data.Failed) != 1 {
+		t.Errorf("got %+v, want two letters and one failure", result)
+	}
+}
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MemoHandler struct {
+	memoService services.IMemoService
+}
+
+func NewMemoHandler(memoService services.IMemoService) *MemoHandler {
+	return &MemoHandler{
+		memoService: memoService,
+	}
+}
+
+func (mh *MemoHandler) ListMemoTypes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	memoTypes, err := mh.memoService.ListMemoTypes(r.Context(), claims.TenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo types retrieved successfully",
+		Data:    memoTypes,
+	})
+}
+
+// ListActiveMemoTypes returns the memo types employees can write memos of
+func (mh *MemoHandler) ListActiveMemoTypes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	memoTypes, err := mh.memoService.ListMemoTypes(r.Context(), claims.TenantID, "active")
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo types retrieved successfully",
+		Data:    memoTypes,
+	})
+}
+
+func (mh *MemoHandler) GetMemoType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid memo type ID")
+		return
+	}
+
+	memoType, err := mh.memoService.GetMemoType(r.Context(), claims.TenantID, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo type retrieved successfully",
+		Data:    memoType,
+	})
+}
+
+func (mh *MemoHandler) CreateMemoType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MemoTypeRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	memoType, err := mh.memoService.CreateMemoType(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Memo type created successfully",
+		Data:    memoType,
+	})
+}
+
+func (mh *MemoHandler) UpdateMemoType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid memo type ID")
+		return
+	}
+
+	var req dto.MemoTypeRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	memoType, err := mh.memoService.UpdateMemoType(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo type updated successfully",
+		Data:    memoType,
+	})
+}
+
+func (mh *MemoHandler) DeleteMemoType(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid memo type ID")
+		return
+	}
+
+	if err := mh.memoService.DeleteMemoType(r.Context(), claims.TenantID, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo type deleted successfully",
+	})
+}
+
+func (mh *MemoHandler) CreateMemo(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateMemoRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	memo, err := mh.memoService.CreateMemo(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Memo created successfully",
+		Data:    memo,
+	})
+}
+
+func (mh *MemoHandler) ListMemos(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &dto.MemoFilter{Status: query.Get("status"), Category: query.Get("category")}
+	var err error
+	if filter.EmployeeID, err = utils.QueryInt(r, "employee_id"); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	if filter.AuthorID, err = utils.QueryInt(r, "author_id"); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	if filter.MemoTypeID, err = utils.QueryInt(r, "memo_type_id"); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	memos, err := mh.memoService.ListMemos(r.Context(), actor, filter)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memos retrieved successfully",
+		Data:    memos,
+	})
+}
+
+func (mh *MemoHandler) ListMyMemos(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	memos, err := mh.memoService.ListMyMemos(r.Context(), actor, r.URL.Query().Get("status"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memos retrieved successfully",
+		Data:    memos,
+	})
+}
+
+func (mh *MemoHandler) GetMemo(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid memo ID")
+		return
+	}
+
+	memo, err := mh.memoService.GetMemo(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo retrieved successfully",
+		Data:    memo,
+	})
+}
+
+func (mh *MemoHandler) ApproveMemo(w http.ResponseWriter, r *http.Request) {
+	mh.decide(w, r, mh.memoService.ApproveMemo, "Memo approved successfully")
+}
+
+func (mh *MemoHandler) RejectMemo(w http.ResponseWriter, r *http.Request) {
+	mh.decide(w, r, mh.memoService.RejectMemo, "Memo rejected successfully")
+}
+
+type memoDecisionFunc func(ctx context.Context, actor services.Actor, id int, req *dto.MemoDecisionRequest) (*dto.MemoResponse, error)
+
+func (mh *MemoHandler) decide(w http.ResponseWriter, r *http.Request, decide memoDecisionFunc, message string) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid memo ID")
+		return
+	}
+
+	// The comment is optional, so an empty body is accepted
+	var req dto.MemoDecisionRequest
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	memo, err := decide(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    memo,
+	})
+}
+
+func (mh *MemoHandler) CancelMemo(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid memo ID")
+		return
+	}
+
+	memo, err := mh.memoService.CancelMemo(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo cancelled successfully",
+		Data:    memo,
+	})
+}
+
+func (mh *MemoHandler) AcknowledgeMemo(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid memo ID")
+		return
+	}
+
+	// The comment is optional, so an empty body is accepted
+	var req dto.AcknowledgeMemoRequest
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	memo, err := mh.memoService.AcknowledgeMemo(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Memo acknowledged successfully",
+		Data:    memo,
+	})
+}
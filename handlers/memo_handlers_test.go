@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockMemoService struct {
+	Actor          services.Actor
+	TenantID       int
+	ID             int
+	Status         string
+	TypeRequest    *dto.MemoTypeRequest
+	Request        *dto.CreateMemoRequest
+	Decision       *dto.MemoDecisionRequest
+	Acknowledgment *dto.AcknowledgeMemoRequest
+	Filter         *dto.MemoFilter
+	TypeResult     *dto.MemoTypeResponse
+	TypeList       []*dto.MemoTypeResponse
+	Result         *dto.MemoResponse
+	ListResult     []*dto.MemoResponse
+	Err            error
+}
+
+func (m *MockMemoService) ListMemoTypes(ctx context.Context, tenantID int, status string) ([]*dto.MemoTypeResponse, error) {
+	m.TenantID = tenantID
+	m.Status = status
+	return m.TypeList, m.Err
+}
+
+func (m *MockMemoService) GetMemoType(ctx context.Context, tenantID int, id int) (*dto.MemoTypeResponse, error) {
+	m.ID = id
+	return m.TypeResult, m.Err
+}
+
+func (m *MockMemoService) CreateMemoType(ctx context.Context, tenantID int, req *dto.MemoTypeRequest) (*dto.MemoTypeResponse, error) {
+	m.TenantID = tenantID
+	m.TypeRequest = req
+	return m.TypeResult, m.Err
+}
+
+func (m *MockMemoService) UpdateMemoType(ctx context.Context, tenantID int, id int, req *dto.MemoTypeRequest) (*dto.MemoTypeResponse, error) {
+	m.ID = id
+	m.TypeRequest = req
+	return m.TypeResult, m.Err
+}
+
+func (m *MockMemoService) DeleteMemoType(ctx context.Context, tenantID int, id int) error {
+	m.ID = id
+	return m.Err
+}
+
+func (m *MockMemoService) CreateMemo(ctx context.Context, actor services.Actor, req *dto.CreateMemoRequest) (*dto.MemoResponse, error) {
+	m.Actor = actor
+	m.Request = req
+	return m.Result, m.Err
+}
+
+func (m *MockMemoService) ListMemos(ctx context.Context, actor services.Actor, filter *dto.MemoFilter) ([]*dto.MemoResponse, error) {
+	m.Actor = actor
+	m.Filter = filter
+	return m.ListResult, m.Err
+}
+
+func (m *MockMemoService) ListMyMemos(ctx context.Context, actor services.Actor, status string) ([]*dto.MemoResponse, error) {
+	m.Actor = actor
+	m.Status = status
+	return m.ListResult, m.Err
+}
+
+func (m *MockMemoService) GetMemo(ctx context.Context, actor services.Actor, id int) (*dto.MemoResponse, error) {
+	m.ID = id
+	return m.Result, m.Err
+}
+
+func (m *MockMemoService) ApproveMemo(ctx context.Context, actor services.Actor, id int, req *dto.MemoDecisionRequest) (*dto.MemoResponse, error) {
+	m.ID = id
+	m.Decision = req
+	return m.Result, m.Err
+}
+
+func (m *MockMemoService) RejectMemo(ctx context.Context, actor services.Actor, id int, req *dto.MemoDecisionRequest) (*dto.MemoResponse, error) {
+	m.ID = id
+	m.Decision = req
+	return m.Result, m.Err
+}
+
+func (m *MockMemoService) CancelMemo(ctx context.Context, actor services.Actor, id int) (*dto.MemoResponse, error) {
+	m.ID = id
+	return m.Result, m.Err
+}
+
+func (m *MockMemoService) AcknowledgeMemo(ctx context.Context, actor services.Actor, id int, req *dto.AcknowledgeMemoRequest) (*dto.MemoResponse, error) {
+	m.Actor = actor
+	m.ID = id
+	m.Acknowledgment = req
+	return m.Result, m.Err
+}
+
+func TestCreateMemoType(t *testing.T) {
+	t.Run("returns 201", func(t *testing.T) {
+		mockService := &MockMemoService{TypeResult: &dto.MemoTypeResponse{ID: 1, Name: "Query", Category: "query"}}
+
+		body := []byte(`{"name": "Query", "category": "query", "requires_acknowledgement": true}`)
+		request, _ := http.NewRequest(http.MethodPost, "/hr/memo-types", bytes.NewReader(body))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &MemoHandler{memoService: mockService}
+		handler.CreateMemoType(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+		if mockService.TypeRequest.Category != "query" || !mockService.TypeRequest.RequiresAcknowledgement {
+			t.Errorf("got request %+v, want a query that needs acknowledging", mockService.TypeRequest)
+		}
+	})
+
+	t.Run("returns 400 for a duplicate name", func(t *testing.T) {
+		mockService := &MockMemoService{Err: &utils.ValidationError{Field: "name", Message: "A memo type with this name already exists"}}
+
+		body := []byte(`{"name": "Query"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/hr/memo-types", bytes.NewReader(body))
+		request = withHRClaims(request)
+
+		response := httptest.NewRecorder()
+
+		handler := &MemoHandler{memoService: mockService}
+		handler.CreateMemoType(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestListActiveMemoTypes(t *testing.T) {
+	mockService := &MockMemoService{}
+
+	request, _ := http.NewRequest(http.MethodGet, "/me/memo-types", nil)
+	request = withEmployeeClaims(request, 5)
+
+	response := httptest.NewRecorder()
+
+	handler := &MemoHandler{memoService: mockService}
+	handler.ListActiveMemoTypes(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.Status != "active" {
+		t.Errorf("got status filter %q, want active", mockService.Status)
+	}
+}
+
+func TestCreateMemo(t *testing.T) {
+	t.Run("returns 201 for the author", func(t *testing.T) {
+		mockService := &MockMemoService{Result: &dto.MemoResponse{ID: 4, Status: "pending"}}
+
+		body := []byte(`{"employee_id": 7, "memo_type_id": 2, "title": "Lateness"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/memos", bytes.NewReader(body))
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &MemoHandler{memoService: mockService}
+		handler.CreateMemo(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+		if mockService.Actor.EmployeeID != 5 || mockService.Request.EmployeeID != 7 || mockService.Request.MemoTypeID != 2 {
+			t.Errorf("got actor %+v and request %+v, want employee 5 writing about 7", mockService.Actor, mockService.Request)
+		}
+	})
+
+	t.Run("returns 403 for a disciplinary memo about someone else's report", func(t *testing.T) {
+		mockService := &MockMemoService{Err: services.ErrForbidden}
+
+		body := []byte(`{"employee_id": 7, "memo_type_id": 2, "title": "Lateness"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/memos", bytes.NewReader(body))
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &MemoHandler{memoService: mockService}
+		handler.CreateMemo(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestListMemos(t *testing.T) {
+	t.Run("passes the filters", func(t *testing.T) {
+		mockService := &MockMemoService{}
+
+		request, _ := http.NewRequest(http.MethodGet, "/memos?status=approved&category=warning&employee_id=7&memo_type_id=2", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &MemoHandler{memoService: mockService}
+		handler.ListMemos(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		filter := mockService.Filter
+		if filter.Status != "approved" || filter.Category != "warning" || filter.EmployeeID != 7 || filter.MemoTypeID != 2 {
+			t.Errorf("got filter %+v, want approved warnings of type 2 about employee 7", filter)
+		}
+	})
+
+	t.Run("returns 400 for a bad author id", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/memos?author_id=abc", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &MemoHandler{memoService: &MockMemoService{}}
+		handler.ListMemos(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestGetMemo(t *testing.T) {
+	mockService := &MockMemoService{Err: services.ErrForbidden}
+
+	request, _ := http.NewRequest(http.MethodGet, "/memos/4", nil)
+	request = mux.SetURLVars(withEmployeeClaims(request, 9), map[string]string{"id": "4"})
+
+	response := httptest.NewRecorder()
+
+	handler := &MemoHandler{memoService: mockService}
+	handler.GetMemo(response, request)
+
+	if response.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+	}
+	if mockService.ID != 4 {
+		t.Errorf("got id %d, want 4", mockService.ID)
+	}
+}
+
+func TestRejectMemo(t *testing.T) {
+	mockService := &MockMemoService{Result: &dto.MemoResponse{ID: 4, Status: "rejected"}}
+
+	body := []byte(`{"comment": "Too harsh"}`)
+	request, _ := http.NewRequest(http.MethodPost, "/memos/4/reject", bytes.NewReader(body))
+	request = mux.SetURLVars(withEmployeeClaims(request, 3), map[string]string{"id": "4"})
+
+	response := httptest.NewRecorder()
+
+	handler := &MemoHandler{memoService: mockService}
+	handler.RejectMemo(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.ID != 4 || mockService.Decision.Comment != "Too harsh" {
+		t.Errorf("got id %d and decision %+v, want 4 with the comment", mockService.ID, mockService.Decision)
+	}
+}
+
+func TestAcknowledgeMemo(t *testing.T) {
+	t.Run("accepts an empty body", func(t *testing.T) {
+		mockService := &MockMemoService{Result: &dto.MemoResponse{ID: 4, Status: "approved"}}
+
+		request, _ := http.NewRequest(http.MethodPost, "/me/memos/4/acknowledge", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 7), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &MemoHandler{memoService: mockService}
+		handler.AcknowledgeMemo(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Actor.EmployeeID != 7 || mockService.Acknowledgment == nil {
+			t.Errorf("got actor %+v and acknowledgement %+v, want employee 7 with an empty comment", mockService.Actor, mockService.Acknowledgment)
+		}
+	})
+
+	t.Run("returns 400 when already acknowledged", func(t *testing.T) {
+		mockService := &MockMemoService{Err: &utils.ValidationError{Field: "status", Message: "Memo is already acknowledged"}}
+
+		body := []byte(`{"comment": "Noted"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/me/memos/4/acknowledge", bytes.NewReader(body))
+		request = mux.SetURLVars(withEmployeeClaims(request, 7), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &MemoHandler{memoService: mockService}
+		handler.AcknowledgeMemo(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
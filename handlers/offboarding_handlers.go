@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type OffboardingHandler struct {
+	offboardingService services.IOffboardingService
+}
+
+func NewOffboardingHandler(offboardingService services.IOffboardingService) *OffboardingHandler {
+	return &OffboardingHandler{
+		offboardingService: offboardingService,
+	}
+}
+
+func (oh *OffboardingHandler) ListChecklistItems(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := oh.offboardingService.ListChecklistItems(r.Context(), claims.TenantID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Offboarding checklist retrieved successfully",
+		Data:    items,
+	})
+}
+
+func (oh *OffboardingHandler) CreateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.OffboardingChecklistItemRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	item, err := oh.offboardingService.CreateChecklistItem(r.Context(), claims.TenantID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Offboarding checklist item created successfully",
+		Data:    item,
+	})
+}
+
+func (oh *OffboardingHandler) UpdateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid checklist item ID")
+		return
+	}
+
+	var req dto.OffboardingChecklistItemRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	item, err := oh.offboardingService.UpdateChecklistItem(r.Context(), claims.TenantID, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Offboarding checklist item updated successfully",
+		Data:    item,
+	})
+}
+
+func (oh *OffboardingHandler) DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	claims, ok := requireClaims(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid checklist item ID")
+		return
+	}
+
+	if err := oh.offboardingService.DeleteChecklistItem(r.Context(), claims.TenantID, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Offboarding checklist item deleted successfully",
+	})
+}
+
+func (oh *OffboardingHandler) StartOffboarding(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	var req dto.StartOffboardingRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	offboarding, err := oh.offboardingService.StartOffboarding(r.Context(), actor, employeeID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Offboarding started successfully",
+		Data:    offboarding,
+	})
+}
+
+func (oh *OffboardingHandler) GetOffboarding(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	offboarding, err := oh.offboardingService.GetOffboarding(r.Context(), actor, employeeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Offboarding retrieved successfully",
+		Data:    offboarding,
+	})
+}
+
+func (oh *OffboardingHandler) ListOffboardings(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	offboardings, err := oh.offboardingService.ListOffboardings(r.Context(), actor, r.URL.Query().Get("status"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Offboardings retrieved successfully",
+		Data:    offboardings,
+	})
+}
+
+func (oh *OffboardingHandler) CancelOffboarding(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	offboarding, err := oh.offboardingService.CancelOffboarding(r.Context(), actor, employeeID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Offboarding cancelled successfully",
+		Data:    offboarding,
+	})
+}
+
+func (oh *OffboardingHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	employeeID, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+
+	taskID, err := utils.ParseIntParam(r, "taskId")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	// Notes are optional, so an empty body is accepted
+	var req dto.CompleteOffboardingTaskRequest
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	task, err := oh.offboardingService.CompleteTask(r.Context(), actor, employeeID, taskID, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Offboarding task completed successfully",
+		Data:    task,
+	})
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockOffboardingService struct {
+	Actor              services.Actor
+	StartRequest       *dto.StartOffboardingRequest
+	TaskRequest        *dto.CompleteOffboardingTaskRequest
+	EmployeeID         int
+	TaskID             int
+	Result             *dto.OffboardingResponse
+	TaskResult         *dto.OffboardingTaskResponse
+	ItemResult         *dto.OffboardingChecklistItemResponse
+	ItemsResult        []*dto.OffboardingChecklistItemResponse
+	OffboardingsResult []*dto.OffboardingResponse
+	Err                error
+}
+
+func (m *MockOffboardingService) ListChecklistItems(ctx context.Context, tenantID int) ([]*dto.OffboardingChecklistItemResponse, error) {
+	return m.ItemsResult, m.Err
+}
+
+func (m *MockOffboardingService) CreateChecklistItem(ctx context.Context, tenantID int, req *dto.OffboardingChecklistItemRequest) (*dto.OffboardingChecklistItemResponse, error) {
+	return m.ItemResult, m.Err
+}
+
+func (m *MockOffboardingService) UpdateChecklistItem(ctx context.Context, tenantID int, id int, req *dto.OffboardingChecklistItemRequest) (*dto.OffboardingChecklistItemResponse, error) {
+	return m.ItemResult, m.Err
+}
+
+func (m *MockOffboardingService) DeleteChecklistItem(ctx context.Context, tenantID int, id int) error {
+	return m.Err
+}
+
+func (m *MockOffboardingService) StartOffboarding(ctx context.Context, actor services.Actor, employeeID int, req *dto.StartOffboardingRequest) (*dto.OffboardingResponse, error) {
+	m.Actor = actor
+	m.EmployeeID = employeeID
+	m.StartRequest = req
+	return m.Result, m.Err
+}
+
+func (m *MockOffboardingService) GetOffboarding(ctx context.Context, actor services.Actor, employeeID int) (*dto.OffboardingResponse, error) {
+	m.Actor = actor
+	m.EmployeeID = employeeID
+	return m.Result, m.Err
+}
+
+func (m *MockOffboardingService) ListOffboardings(ctx context.Context, actor services.Actor, status string) ([]*dto.OffboardingResponse, error) {
+	return m.OffboardingsResult, m.Err
+}
+
+func (m *MockOffboardingService) CancelOffboarding(ctx context.Context, actor services.Actor, employeeID int) (*dto.OffboardingResponse, error) {
+	m.EmployeeID = employeeID
+	return m.Result, m.Err
+}
+
+func (m *MockOffboardingService) CompleteTask(ctx context.Context, actor services.Actor, employeeID int, taskID int, req *dto.CompleteOffboardingTaskRequest) (*dto.OffboardingTaskResponse, error) {
+	m.Actor = actor
+	m.EmployeeID = employeeID
+	m.TaskID = taskID
+	m.TaskRequest = req
+	return m.TaskResult, m.Err
+}
+
+func TestStartOffboarding(t *testing.T) {
+	t.Run("returns 201 when the offboarding starts", func(t *testing.T) {
+		mockService := &MockOffboardingService{
+			Result: &dto.OffboardingResponse{ID: 1, EmployeeID: 4, SuccessorID: 9, Status: "scheduled"},
+		}
+
+		body := []byte(`{"last_working_day": "2026-03-31", "successor_id": 9, "reason": "Resigned"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/employees/4/offboarding", bytes.NewReader(body))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OffboardingHandler{offboardingService: mockService}
+		handler.StartOffboarding(response, request)
+
+		if response.Code != http.StatusCreated {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+		}
+
+		if mockService.EmployeeID != 4 || mockService.StartRequest.SuccessorID != 9 || mockService.StartRequest.LastWorkingDay != "2026-03-31" {
+			t.Errorf("got employee %d and request %+v, want employee 4 succeeded by 9 on 2026-03-31", mockService.EmployeeID, mockService.StartRequest)
+		}
+	})
+
+	t.Run("returns 400 when validation fails", func(t *testing.T) {
+		mockService := &MockOffboardingService{
+			Err: &utils.ValidationError{Field: "successor_id", Message: "An employee cannot succeed themselves"},
+		}
+
+		body := []byte(`{"last_working_day": "2026-03-31", "successor_id": 4}`)
+		request, _ := http.NewRequest(http.MethodPost, "/employees/4/offboarding", bytes.NewReader(body))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OffboardingHandler{offboardingService: mockService}
+		handler.StartOffboarding(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestGetOffboarding(t *testing.T) {
+	t.Run("returns 401 without claims", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/employees/4/offboarding", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OffboardingHandler{offboardingService: &MockOffboardingService{}}
+		handler.GetOffboarding(response, request)
+
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("returns 403 for unrelated employees", func(t *testing.T) {
+		mockService := &MockOffboardingService{Err: services.ErrForbidden}
+
+		request, _ := http.NewRequest(http.MethodGet, "/employees/4/offboarding", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "4"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OffboardingHandler{offboardingService: mockService}
+		handler.GetOffboarding(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestCompleteOffboardingTask(t *testing.T) {
+	t.Run("accepts an empty body", func(t *testing.T) {
+		mockService := &MockOffboardingService{
+			TaskResult: &dto.OffboardingTaskResponse{ID: 3, Status: "done"},
+		}
+
+		request, _ := http.NewRequest(http.MethodPost, "/employees/4/offboarding/tasks/3/complete", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 9), map[string]string{"id": "4", "taskId": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OffboardingHandler{offboardingService: mockService}
+		handler.CompleteTask(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+
+		if mockService.EmployeeID != 4 || mockService.TaskID != 3 || mockService.Actor.EmployeeID != 9 {
+			t.Errorf("got employee %d, task %d, actor %d, want 4, 3, 9", mockService.EmployeeID, mockService.TaskID, mockService.Actor.EmployeeID)
+		}
+	})
+
+	t.Run("returns 404 when the task belongs to another offboarding", func(t *testing.T) {
+		mockService := &MockOffboardingService{Err: services.ErrNotFound}
+
+		body := []byte(`{"notes": "Laptop returned"}`)
+		request, _ := http.NewRequest(http.MethodPost, "/employees/4/offboarding/tasks/30/complete", bytes.NewReader(body))
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "4", "taskId": "30"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OffboardingHandler{offboardingService: mockService}
+		handler.CompleteTask(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+
+		if mockService.TaskRequest.Notes != "Laptop returned" {
+			t.Errorf("got notes %q, want %q", mockService.TaskRequest.Notes, "Laptop returned")
+		}
+	})
+}
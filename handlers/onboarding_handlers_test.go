@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+)
+
+type MockOnboardingService struct {
+	Actor           services.Actor
+	Status          string
+	TaskID          int
+	TaskRequest     *dto.CompleteOnboardingTaskRequest
+	TemplateRequest *dto.OnboardingTemplateRequest
+	TemplateResult  *dto.OnboardingTemplateResponse
+	TemplatesResult []*dto.OnboardingTemplateResponse
+	TaskResult      *dto.OnboardingTaskResponse
+	TasksResult     []*dto.OnboardingTaskResponse
+	ProgressResult  []*dto.OnboardingProgressResponse
+	Err             error
+}
+
+func (m *MockOnboardingService) ListTemplates(ctx context.Context, tenantID int) ([]*dto.OnboardingTemplateResponse, error) {
+	return m.TemplatesResult, m.Err
+}
+
+func (m *MockOnboardingService) CreateTemplate(ctx context.Context, tenantID int, req *dto.OnboardingTemplateRequest) (*dto.OnboardingTemplateResponse, error) {
+	m.TemplateRequest = req
+	return m.TemplateResult, m.Err
+}
+
+func (m *MockOnboardingService) UpdateTemplate(ctx context.Context, tenantID int, id int, req *dto.OnboardingTemplateRequest) (*dto.OnboardingTemplateResponse, error) {
+	m.TemplateRequest = req
+	return m.TemplateResult, m.Err
+}
+
+func (m *MockOnboardingService) DeleteTemplate(ctx context.Context, tenantID int, id int) error {
+	return m.Err
+}
+
+func (m *MockOnboardingService) ListMyTasks(ctx context.Context, actor services.Actor, status string) ([]*dto.OnboardingTaskResponse, error) {
+	m.Actor = actor
+	m.Status = status
+	return m.TasksResult, m.Err
+}
+
+func (m *MockOnboardingService) CompleteTask(ctx context.Context, actor services.Actor, taskID int, req *dto.CompleteOnboardingTaskRequest) (*dto.OnboardingTaskResponse, error) {
+	m.Actor = actor
+	m.TaskID = taskID
+	m.TaskRequest = req
+	return m.TaskResult, m.Err
+}
+
+func (m *MockOnboardingService) GetEmployeeOnboarding(ctx context.Context, actor services.Actor, employeeID int) ([]*dto.OnboardingTaskResponse, error) {
+	m.Actor = actor
+	return m.TasksResult, m.Err
+}
+
+func (m *MockOnboardingService) GetProgress(ctx context.Context, actor services.Actor) ([]*dto.OnboardingProgressResponse, error) {
+	m.Actor = actor
+	return m.ProgressResult, m.Err
+}
+
+func TestCreateOnboardingTemplate(t *testing.T) {
+	mockService := &MockOnboardingService{
+		TemplateResult: &dto.OnboardingTemplateResponse{ID: 1, Name: "Engineering", Status: "active"},
+	}
+
+	body := []byte(`{"name": "Engineering", "department_id": 3, "tasks": [{"title": "Provision laptop", "assignee_type": "it", "due_offset_days": -2}]}`)
+	request, _ := http.NewRequest(http.MethodPost, "/hr/onboarding-templates", bytes.NewReader(body))
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &OnboardingHandler{onboardingService: mockService}
+	handler.CreateTemplate(response, request)
+
+	if response.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusCreated)
+	}
+
+	req := mockService.TemplateRequest
+	if req == nil || len(req.Tasks) != 1 || req.Tasks[0].DueOffsetDays != -2 || req.DepartmentID == nil || *req.DepartmentID != 3 {
+		t.Errorf("got request %+v, want one IT task two days before the start for department 3", req)
+	}
+}
+
+func TestListMyTasks(t *testing.T) {
+	t.Run("lists the caller's tasks", func(t *testing.T) {
+		mockService := &MockOnboardingService{
+			TasksResult: []*dto.OnboardingTaskResponse{{ID: 3, Title: "Read handbook", Status: "pending", Overdue: true}},
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/me/tasks?status=pending", nil)
+		request = withEmployeeClaims(request, 5)
+
+		response := httptest.NewRecorder()
+
+		handler := &OnboardingHandler{onboardingService: mockService}
+		handler.ListMyTasks(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Actor.EmployeeID != 5 || mockService.Status != "pending" {
+			t.Errorf("got actor %+v status %q, want employee 5 and pending", mockService.Actor, mockService.Status)
+		}
+	})
+
+	t.Run("returns 401 without claims", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/me/tasks", nil)
+
+		response := httptest.NewRecorder()
+
+		handler := &OnboardingHandler{onboardingService: &MockOnboardingService{}}
+		handler.ListMyTasks(response, request)
+
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestCompleteOnboardingTask(t *testing.T) {
+	t.Run("accepts an empty body", func(t *testing.T) {
+		mockService := &MockOnboardingService{
+			TaskResult: &dto.OnboardingTaskResponse{ID: 3, Status: "done"},
+		}
+
+		request, _ := http.NewRequest(http.MethodPost, "/me/tasks/3/complete", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OnboardingHandler{onboardingService: mockService}
+		handler.CompleteTask(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.TaskID != 3 || mockService.TaskRequest.Notes != "" {
+			t.Errorf("got task %d with %+v, want task 3 without notes", mockService.TaskID, mockService.TaskRequest)
+		}
+	})
+
+	t.Run("returns 403 when the caller is not the assignee", func(t *testing.T) {
+		mockService := &MockOnboardingService{Err: services.ErrForbidden}
+
+		request, _ := http.NewRequest(http.MethodPost, "/me/tasks/3/complete", bytes.NewReader([]byte(`{"notes": "done"}`)))
+		request = mux.SetURLVars(withEmployeeClaims(request, 6), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OnboardingHandler{onboardingService: mockService}
+		handler.CompleteTask(response, request)
+
+		if response.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("returns 404 for an unknown task", func(t *testing.T) {
+		mockService := &MockOnboardingService{Err: fmt.Errorf("onboarding task %w", services.ErrNotFound)}
+
+		request, _ := http.NewRequest(http.MethodPost, "/me/tasks/99/complete", nil)
+		request = mux.SetURLVars(withHRClaims(request), map[string]string{"id": "99"})
+
+		response := httptest.NewRecorder()
+
+		handler := &OnboardingHandler{onboardingService: mockService}
+		handler.CompleteTask(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type SignatureHandler struct {
+	signatureService services.ISignatureService
+}
+
+func NewSignatureHandler(signatureService services.ISignatureService) *SignatureHandler {
+	return &SignatureHandler{
+		signatureService: signatureService,
+	}
+}
+
+// ListSignatureRequests returns the tenant's signature requests, filtered by
+// the status and kind query parameters
+func (sh *SignatureHandler) ListSignatureRequests(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	requests, err := sh.signatureService.ListSignatureRequests(r.Context(), actor, query.Get("status"), query.Get("kind"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Signature requests retrieved successfully",
+		Data:    requests,
+	})
+}
+
+func (sh *SignatureHandler) CreateSignatureRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.SignatureRequestRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	request, err := sh.signatureService.CreateSignatureRequest(r.Context(), actor, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, utils.APIResponse{
+		Success: true,
+		Message: "Signature request created successfully",
+		Data:    request,
+	})
+}
+
+func (sh *SignatureHandler) CancelSignatureRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid signature request ID")
+		return
+	}
+
+	if err := sh.signatureService.CancelSignatureRequest(r.Context(), actor, id); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Signature request cancelled successfully",
+	})
+}
+
+// SealSignatureRequest seals a completed request whose sealing failed
+func (sh *SignatureHandler) SealSignatureRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid signature request ID")
+		return
+	}
+
+	request, err := sh.signatureService.SealSignatureRequest(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Signature request sealed successfully",
+		Data:    request,
+	})
+}
+
+// ListMySignatureRequests returns the requests the caller is a signer of,
+// filtered by the status query parameter
+func (sh *SignatureHandler) ListMySignatureRequests(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := sh.signatureService.ListMySignatureRequests(r.Context(), actor, r.URL.Query().Get("status"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Signature requests retrieved successfully",
+		Data:    requests,
+	})
+}
+
+func (sh *SignatureHandler) GetSignatureRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid signature request ID")
+		return
+	}
+
+	request, err := sh.signatureService.GetSignatureRequest(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Signature request retrieved successfully",
+		Data:    request,
+	})
+}
+
+// DownloadSignatureDocument streams the document version a request asks to
+// be signed
+func (sh *SignatureHandler) DownloadSignatureDocument(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid signature request ID")
+		return
+	}
+
+	content, version, err := sh.signatureService.OpenSignatureDocument(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	defer content.Close()
+
+	writeDocumentFile(w, content, version.FileName, version.ContentType, version.SizeBytes, version.Checksum)
+}
+
+// DownloadSealedDocument streams the sealed PDF of a completed request
+func (sh *SignatureHandler) DownloadSealedDocument(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid signature request ID")
+		return
+	}
+
+	content, version, err := sh.signatureService.OpenSealedDocument(r.Context(), actor, id)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	defer content.Close()
+
+	writeDocumentFile(w, content, version.FileName, version.ContentType, version.SizeBytes, version.Checksum)
+}
+
+// SignSignatureRequest signs a request as the caller, recording the IP
+// address and user agent of the request
+func (sh *SignatureHandler) SignSignatureRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid signature request ID")
+		return
+	}
+
+	var req dto.SignDocumentRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.IPAddress, req.UserAgent = requestOrigin(r)
+
+	request, err := sh.signatureService.SignSignatureRequest(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Signature recorded successfully",
+		Data:    request,
+	})
+}
+
+func (sh *SignatureHandler) DeclineSignatureRequest(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := utils.ParseIntParam(r, "id")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid signature request ID")
+		return
+	}
+
+	// The reason is optional, so an empty body is accepted
+	var req dto.DeclineSignatureRequest
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	req.IPAddress, req.UserAgent = requestOrigin(r)
+
+	request, err := sh.signatureService.DeclineSignatureRequest(r.Context(), actor, id, &req)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Signature request declined",
+		Data:    request,
+	})
+}
+
+// PolicyAcknowledgements lists the employees who have not acknowledged the
+// policy version given by the policy and version query parameters
+func (sh *SignatureHandler) PolicyAcknowledgements(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requireActor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	report, err := sh.signatureService.PolicyAcknowledgementReport(r.Context(), actor, query.Get("policy"), query.Get("version"))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.APIResponse{
+		Success: true,
+		Message: "Policy acknowledgements retrieved successfully",
+		Data:    report,
+	})
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/middleware"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/services"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type MockSignatureService struct {
+	Actor          services.Actor
+	RequestID      int
+	SignRequest    *dto.SignDocumentRequest
+	DeclineRequest *dto.DeclineSignatureRequest
+	PolicyName     string
+	PolicyVersion  string
+	Err            error
+}
+
+func (m *MockSignatureService) ListSignatureRequests(ctx context.Context, actor services.Actor, status string, kind string) ([]*dto.SignatureRequestResponse, error) {
+	m.Actor = actor
+	return []*dto.SignatureRequestResponse{}, m.Err
+}
+
+func (m *MockSignatureService) CreateSignatureRequest(ctx context.Context, actor services.Actor, req *dto.SignatureRequestRequest) (*dto.SignatureRequestResponse, error) {
+	m.Actor = actor
+	return &dto.SignatureRequestResponse{DocumentID: req.DocumentID}, m.Err
+}
+
+func (m *MockSignatureService) CancelSignatureRequest(ctx context.Context, actor services.Actor, id int) error {
+	m.Actor = actor
+	m.RequestID = id
+	return m.Err
+}
+
+func (m *MockSignatureService) SealSignatureRequest(ctx context.Context, actor services.Actor, id int) (*dto.SignatureRequestResponse, error) {
+	m.Actor = actor
+	m.RequestID = id
+	return &dto.SignatureRequestResponse{ID: id}, m.Err
+}
+
+func (m *MockSignatureService) ListMySignatureRequests(ctx context.Context, actor services.Actor, status string) ([]*dto.SignatureRequestResponse, error) {
+	m.Actor = actor
+	return []*dto.SignatureRequestResponse{}, m.Err
+}
+
+func (m *MockSignatureService) GetSignatureRequest(ctx context.Context, actor services.Actor, id int) (*dto.SignatureRequestResponse, error) {
+	m.Actor = actor
+	m.RequestID = id
+	return &dto.SignatureRequestResponse{ID: id}, m.Err
+}
+
+func (m *MockSignatureService) OpenSignatureDocument(ctx context.Context, actor services.Actor, id int) (io.ReadCloser, *models.DocumentVersion, error) {
+	m.Actor = actor
+	m.RequestID = id
+	if m.Err != nil {
+		return nil, nil, m.Err
+	}
+	return io.NopCloser(strings.NewReader("contract")), &models.DocumentVersion{FileName: "contract.pdf", ContentType: "application/pdf", SizeBytes: 8}, nil
+}
+
+func (m *MockSignatureService) OpenSealedDocument(ctx context.Context, actor services.Actor, id int) (io.ReadCloser, *models.DocumentVersion, error) {
+	m.Actor = actor
+	m.RequestID = id
+	if m.Err != nil {
+		return nil, nil, m.Err
+	}
+	return io.NopCloser(strings.NewReader("%PDF-1.4")), &models.DocumentVersion{FileName: "contract (signed).pdf", ContentType: "application/pdf", SizeBytes: 8}, nil
+}
+
+func (m *MockSignatureService) SignSignatureRequest(ctx context.Context, actor services.Actor, id int, req *dto.SignDocumentRequest) (*dto.SignatureRequestResponse, error) {
+	m.Actor = actor
+	m.RequestID = id
+	m.SignRequest = req
+	return &dto.SignatureRequestResponse{ID: id}, m.Err
+}
+
+func (m *MockSignatureService) DeclineSignatureRequest(ctx context.Context, actor services.Actor, id int, req *dto.DeclineSignatureRequest) (*dto.SignatureRequestResponse, error) {
+	m.Actor = actor
+	m.RequestID = id
+	m.DeclineRequest = req
+	return &dto.SignatureRequestResponse{ID: id}, m.Err
+}
+
+func (m *MockSignatureService) PolicyAcknowledgementReport(ctx context.Context, actor services.Actor, policyName string, policyVersion string) (*dto.PolicyAcknowledgementReport, error) {
+	m.Actor = actor
+	m.PolicyName = policyName
+	m.PolicyVersion = policyVersion
+	return &dto.PolicyAcknowledgementReport{PolicyName: policyName, PolicyVersion: policyVersion}, m.Err
+}
+
+func TestSignSignatureRequest(t *testing.T) {
+	t.Run("records the IP address and user agent of the request", func(t *testing.T) {
+		mockService := &MockSignatureService{}
+
+		body, _ := json.Marshal(dto.SignDocumentRequest{SignatureType: "typed", TypedName: "Ada Obi", Consent: true})
+		request, _ := http.NewRequest(http.MethodPost, "/signature-requests/3/sign", bytes.NewReader(body))
+		request.Header.Set("User-Agent", "Firefox/130")
+		request = request.WithContext(middleware.WithRequestInfo(request.Context(), middleware.RequestInfo{RequestID: "req-1", IPAddress: "203.0.113.7"}))
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &SignatureHandler{signatureService: mockService}
+		handler.SignSignatureRequest(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", response.Code, http.StatusOK)
+		}
+		if mockService.Actor.EmployeeID != 5 || mockService.RequestID != 3 {
+			t.Errorf("got actor %d and request %d, want employee 5 signing request 3", mockService.Actor.EmployeeID, mockService.RequestID)
+		}
+		if got := mockService.SignRequest; got.IPAddress != "203.0.113.7" || got.UserAgent != "Firefox/130" || got.TypedName != "Ada Obi" {
+			t.Errorf("got %+v, want the typed signature with the request's origin", got)
+		}
+	})
+
+	t.Run("ignores an IP address in the body", func(t *testing.T) {
+		mockService := &MockSignatureService{}
+
+		request, _ := http.NewRequest(http.MethodPost, "/signature-requests/3/sign",
+			strings.NewReader(`{"signature_type":"typed","typed_name":"Ada Obi","consent":true,"IPAddress":"10.0.0.1"}`))
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &SignatureHandler{signatureService: mockService}
+		handler.SignSignatureRequest(response, request)
+
+		if got := mockService.SignRequest.IPAddress; got != "" {
+			t.Errorf("got IP address %q, want it taken from the request only", got)
+		}
+	})
+
+	t.Run("ignores X-Forwarded-For from a peer that is not a trusted proxy", func(t *testing.T) {
+		mockService := &MockSignatureService{}
+		trustedProxies, _ := middleware.ParseTrustedProxies("10.0.0.0/8")
+
+		body, _ := json.Marshal(dto.SignDocumentRequest{SignatureType: "typed", TypedName: "Ada Obi", Consent: true})
+		request, _ := http.NewRequest(http.MethodPost, "/signature-requests/3/sign", bytes.NewReader(body))
+		request.RemoteAddr = "198.51.100.9:52344"
+		request.Header.Set("X-Forwarded-For", "203.0.113.7")
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &SignatureHandler{signatureService: mockService}
+		middleware.RequestIDMiddleware(trustedProxies)(http.HandlerFunc(handler.SignSignatureRequest)).ServeHTTP(response, request)
+
+		if got := mockService.SignRequest.IPAddress; got != "198.51.100.9" {
+			t.Errorf("got IP address %q, want the peer's address 198.51.100.9", got)
+		}
+	})
+
+	t.Run("reads the client from X-Forwarded-For sent by a trusted proxy", func(t *testing.T) {
+		mockService := &MockSignatureService{}
+		trustedProxies, _ := middleware.ParseTrustedProxies("10.0.0.0/8")
+
+		body, _ := json.Marshal(dto.SignDocumentRequest{SignatureType: "typed", TypedName: "Ada Obi", Consent: true})
+		request, _ := http.NewRequest(http.MethodPost, "/signature-requests/3/sign", bytes.NewReader(body))
+		request.RemoteAddr = "10.0.0.2:52344"
+		request.Header.Set("X-Forwarded-For", "192.0.2.1, 203.0.113.7")
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &SignatureHandler{signatureService: mockService}
+		middleware.RequestIDMiddleware(trustedProxies)(http.HandlerFunc(handler.SignSignatureRequest)).ServeHTTP(response, request)
+
+		if got := mockService.SignRequest.IPAddress; got != "203.0.113.7" {
+			t.Errorf("got IP address %q, want 203.0.113.7, the address the proxy saw", got)
+		}
+	})
+
+	t.Run("returns 400 for an invalid signature", func(t *testing.T) {
+		mockService := &MockSignatureService{Err: &utils.ValidationError{Field: "consent", Message: "You must agree to sign electronically"}}
+
+		body, _ := json.Marshal(dto.SignDocumentRequest{SignatureType: "typed", TypedName: "Ada Obi"})
+		request, _ := http.NewRequest(http.MethodPost, "/signature-requests/3/sign", bytes.NewReader(body))
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &SignatureHandler{signatureService: mockService}
+		handler.SignSignatureRequest(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestDeclineSignatureRequest(t *testing.T) {
+	mockService := &MockSignatureService{}
+
+	request, _ := http.NewRequest(http.MethodPost, "/signature-requests/3/decline", nil)
+	request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+	response := httptest.NewRecorder()
+
+	handler := &SignatureHandler{signatureService: mockService}
+	handler.DeclineSignatureRequest(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.DeclineRequest == nil || mockService.DeclineRequest.Reason != "" {
+		t.Errorf("got %+v, want an empty body accepted", mockService.DeclineRequest)
+	}
+}
+
+func TestDownloadSealedDocument(t *testing.T) {
+	t.Run("streams the sealed PDF", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/signature-requests/3/sealed", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &SignatureHandler{signatureService: &MockSignatureService{}}
+		handler.DownloadSealedDocument(response, request)
+
+		if response.Code != http.StatusOK || response.Body.String() != "%PDF-1.4" {
+			t.Errorf("got status %d and body %q, want the sealed PDF", response.Code, response.Body.String())
+		}
+		if got := response.Header().Get("Content-Disposition"); got != `attachment; filename="contract (signed).pdf"` {
+			t.Errorf("got Content-Disposition %q, want the sealed file name", got)
+		}
+	})
+
+	t.Run("returns 404 before the request is sealed", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/signature-requests/3/sealed", nil)
+		request = mux.SetURLVars(withEmployeeClaims(request, 5), map[string]string{"id": "3"})
+
+		response := httptest.NewRecorder()
+
+		handler := &SignatureHandler{signatureService: &MockSignatureService{Err: services.ErrNotFound}}
+		handler.DownloadSealedDocument(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestPolicyAcknowledgements(t *testing.T) {
+	mockService := &MockSignatureService{}
+
+	request, _ := http.NewRequest(http.MethodGet, "/hr/policy-acknowledgements?policy=Employee+handbook&version=2026.1", nil)
+	request = withHRClaims(request)
+
+	response := httptest.NewRecorder()
+
+	handler := &SignatureHandler{signatureService: mockService}
+	handler.PolicyAcknowledgements(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusOK)
+	}
+	if mockService.PolicyName != "Employee handbook" || mockService.PolicyVersion != "2026.1" {
+		t.Errorf("got policy %q version %q, want the query parameters", mockService.PolicyName, mockService.PolicyVersion)
+	}
+}
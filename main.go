@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/falasefemi2/peopleos/config"
 	"github.com/falasefemi2/peopleos/database"
@@ -27,6 +32,12 @@ func main() {
 		log.Fatalf("Migration failed: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "verify-audit" {
+		code := runVerifyAudit(pool, os.Args[2:])
+		pool.Close()
+		os.Exit(code)
+	}
+
 	fmt.Println("Initializing repositories...")
 	companyRepo := repositories.NewCompanyRepository(pool)
 	tenantRepo := repositories.NewTenantRepository(pool)
@@ -34,8 +45,49 @@ func main() {
 	employeeRepo := repositories.NewEmployeeRepository(pool)
 	departmentRepo := repositories.NewDepartmentRepository(pool)
 	designationRepo := repositories.NewDesignationRepository(pool)
+	exportJobRepo := repositories.NewExportJobRepository(pool)
+	invitationRepo := repositories.NewInvitationRepository(pool)
+	profileRepo := repositories.NewEmployeeProfileRepository(pool)
+	customFieldRepo := repositories.NewCustomFieldRepository(pool)
+	employmentHistoryRepo := repositories.NewEmploymentHistoryRepository(pool)
+	offboardingRepo := repositories.NewOffboardingRepository(pool)
+	onboardingRepo := repositories.NewOnboardingRepository(pool)
+	leaveTypeRepo := repositories.NewLeaveTypeRepository(pool)
+	leaveRequestRepo := repositories.NewLeaveRequestRepository(pool)
+	leaveAccrualRepo := repositories.NewLeaveAccrualRepository(pool)
+	holidayRepo := repositories.NewHolidayRepository(pool)
+	leaveCalendarRepo := repositories.NewLeaveCalendarRepository(pool)
+	approvalRepo := repositories.NewApprovalRepository(pool)
+	memoRepo := repositories.NewMemoRepository(pool)
+	disciplinaryRepo := repositories.NewDisciplinaryRepository(pool)
+	auditRepo := repositories.NewAuditRepository(pool)
+	documentRepo := repositories.NewDocumentRepository(pool)
+	letterRepo := repositories.NewLetterRepository(pool)
+	signatureRepo := repositories.NewSignatureRepository(pool)
 
 	fmt.Println("Initializing services...")
+	var mailer services.Mailer = services.NewLogMailer()
+	if smtpHost := config.GetEnv("SMTP_HOST", ""); smtpHost != "" {
+		mailer = services.NewSMTPMailer(
+			smtpHost,
+			config.GetEnv("SMTP_PORT", "587"),
+			config.GetEnv("SMTP_USERNAME", ""),
+			config.GetEnv("SMTP_PASSWORD", ""),
+			config.GetEnv("SMTP_FROM", "no-reply@peopleos.local"),
+		)
+	}
+
+	var documentStorage services.DocumentStorage = services.NewLocalDocumentStorage(config.GetEnv("DOCUMENT_DIR", "documents"))
+	if bucket := config.GetEnv("S3_BUCKET", ""); bucket != "" {
+		documentStorage = services.NewS3DocumentStorage(
+			config.GetEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			config.GetEnv("S3_REGION", "us-east-1"),
+			bucket,
+			config.GetEnv("S3_ACCESS_KEY", ""),
+			config.GetEnv("S3_SECRET_KEY", ""),
+		)
+	}
+
 	companyService := services.NewCompanyService(
 		companyRepo,
 		tenantRepo,
@@ -45,17 +97,95 @@ func main() {
 		designationRepo,
 	)
 	authService := services.NewAuthService(employeeRepo)
-	employeeService := services.NewEmployeeService(employeeRepo, roleRepo)
+	invitationService := services.NewInvitationService(
+		invitationRepo,
+		employeeRepo,
+		mailer,
+		config.GetEnv("INVITATION_SECRET", "change-this-invitation-secret"),
+		config.GetEnv("APP_BASE_URL", "http://localhost:8080"),
+		72*time.Hour,
+	)
+	customFieldService := services.NewCustomFieldService(customFieldRepo)
+	employmentService := services.NewEmploymentHistoryService(employmentHistoryRepo, employeeRepo, departmentRepo, designationRepo)
+	onboardingService := services.NewOnboardingService(onboardingRepo, employeeRepo, departmentRepo, designationRepo, mailer)
+	employeeService := services.NewEmployeeService(employeeRepo, roleRepo, invitationService, customFieldService, employmentService, onboardingService)
+	profileService := services.NewEmployeeProfileService(employeeRepo, profileRepo, companyRepo)
+	offboardingService := services.NewOffboardingService(offboardingRepo, employeeRepo)
+	leaveTypeService := services.NewLeaveTypeService(leaveTypeRepo, employeeRepo, profileRepo)
+	holidayService := services.NewHolidayService(holidayRepo, companyRepo)
+	approvalService := services.NewApprovalService(approvalRepo, mailer)
+	leaveRequestService := services.NewLeaveRequestService(leaveRequestRepo, leaveTypeRepo, employeeRepo, leaveTypeService, holidayService, approvalService)
+	approvalService.RegisterCallback(services.ApprovalEntityLeaveRequest, leaveRequestService)
+	memoService := services.NewMemoService(memoRepo, employeeRepo, approvalService)
+	approvalService.RegisterCallback(services.ApprovalEntityMemo, memoService)
+	disciplinaryService := services.NewDisciplinaryService(disciplinaryRepo, memoRepo, employeeRepo)
+	auditSigningKey, err := services.ParseAuditSigningKey(config.GetEnv("AUDIT_SIGNING_KEY", ""))
+	if err != nil {
+		log.Fatalf("Invalid AUDIT_SIGNING_KEY: %v", err)
+	}
+	auditService := services.NewAuditService(auditRepo, roleRepo, auditSigningKey)
+	documentService := services.NewDocumentService(documentRepo, employeeRepo, companyRepo, documentStorage, mailer)
+	letterService := services.NewLetterService(letterRepo, documentRepo, companyRepo, documentStorage)
+	signatureService := services.NewSignatureService(signatureRepo, documentRepo, documentStorage, mailer)
+	leaveAccrualService := services.NewLeaveAccrualService(leaveAccrualRepo, leaveRequestRepo, leaveTypeRepo, employeeRepo)
+	leaveCalendarService := services.NewLeaveCalendarService(leaveCalendarRepo, config.GetEnv("APP_BASE_URL", "http://localhost:8080"))
+	exportService := services.NewExportService(employeeRepo, exportJobRepo, customFieldService, config.GetEnv("EXPORT_DIR", "exports"))
 
 	fmt.Println("Initializing handlers...")
 	companyHandler := handlers.NewCompanyHandler(companyService)
 	authHandler := handlers.NewAuthHandler(authService)
 	employeeHandler := handlers.NewEmployeeHandler(employeeService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	profileHandler := handlers.NewEmployeeProfileHandler(profileService)
+	customFieldHandler := handlers.NewCustomFieldHandler(customFieldService)
+	employmentHandler := handlers.NewEmploymentHistoryHandler(employmentService)
+	offboardingHandler := handlers.NewOffboardingHandler(offboardingService)
+	onboardingHandler := handlers.NewOnboardingHandler(onboardingService)
+	leaveTypeHandler := handlers.NewLeaveTypeHandler(leaveTypeService)
+	leaveRequestHandler := handlers.NewLeaveRequestHandler(leaveRequestService)
+	leaveAccrualHandler := handlers.NewLeaveAccrualHandler(leaveAccrualService)
+	holidayHandler := handlers.NewHolidayHandler(holidayService)
+	leaveCalendarHandler := handlers.NewLeaveCalendarHandler(leaveCalendarService)
+	approvalHandler := handlers.NewApprovalHandler(approvalService)
+	memoHandler := handlers.NewMemoHandler(memoService)
+	disciplinaryHandler := handlers.NewDisciplinaryHandler(disciplinaryService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	documentHandler := handlers.NewDocumentHandler(documentService)
+	letterHandler := handlers.NewLetterHandler(letterService)
+	signatureHandler := handlers.NewSignatureHandler(signatureService)
+
+	// Background jobs stop with the server on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Println("Starting background jobs...")
+	go employmentService.RunScheduler(ctx, time.Hour)
+	go offboardingService.RunScheduler(ctx, time.Hour)
+	go onboardingService.RunScheduler(ctx, time.Hour)
+	go leaveAccrualService.RunScheduler(ctx, time.Hour)
+	go approvalService.RunScheduler(ctx, 15*time.Minute)
+	go auditService.RunScheduler(ctx, time.Hour)
+	go documentService.RunScheduler(ctx, 24*time.Hour)
+	// An export cut short by shutdown is requeued, so main waits for it
+	exportDone := make(chan struct{})
+	go func() {
+		exportService.RunScheduler(ctx, time.Minute)
+		close(exportDone)
+	}()
+
+	// Client addresses are read from X-Forwarded-For only when the request
+	// came through one of these proxies
+	trustedProxies, err := middleware.ParseTrustedProxies(config.GetEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	router := mux.NewRouter()
 
 	// Apply global middleware
 	router.Use(middleware.RecoveryMiddleware)
+	router.Use(middleware.RequestIDMiddleware(trustedProxies))
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.CORSMiddleware)
 
@@ -71,27 +201,356 @@ func main() {
 
 	// ============ AUTH ROUTES (PUBLIC) ============
 	router.HandleFunc("/auth/login", authHandler.Login).Methods("POST")
+	router.HandleFunc("/invitations", invitationHandler.GetInvitation).Methods("GET")
+	router.HandleFunc("/invitations/mfa", invitationHandler.SetUpInvitationMFA).Methods("POST")
+	router.HandleFunc("/invitations/accept", invitationHandler.AcceptInvitation).Methods("POST")
 
 	// ============ SUPER ADMIN ROUTES ============
 	superAdminRouter := router.PathPrefix("/admin").Subrouter()
 	superAdminRouter.Use(middleware.AuthenticationMiddleware)
+	superAdminRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
 	superAdminRouter.Use(middleware.RoleMiddleware("Super Admin"))
 	superAdminRouter.HandleFunc("/", handlers.AdminHandler).Methods("GET")
 
 	// ============ HR ROUTES ============
 	hrRouter := router.PathPrefix("/hr").Subrouter()
 	hrRouter.Use(middleware.AuthenticationMiddleware)
+	hrRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
 	hrRouter.Use(middleware.RoleMiddleware("HR"))
 	hrRouter.HandleFunc("/employees", employeeHandler.CreateEmployee).Methods("POST")
+	hrRouter.HandleFunc("/employees", employeeHandler.ListEmployees).Methods("GET")
+	hrRouter.HandleFunc("/employees/{id}", employeeHandler.UpdateEmployee).Methods("PUT")
+	hrRouter.HandleFunc("/employees/{id}/employment-history", employmentHandler.ScheduleChange).Methods("POST")
+	hrRouter.HandleFunc("/employees/{id}/employment-history/{changeId}", employmentHandler.CancelChange).Methods("DELETE")
+	hrRouter.HandleFunc("/org", employmentHandler.GetOrgAsOf).Methods("GET")
+	hrRouter.HandleFunc("/employees/{id}/offboarding", offboardingHandler.StartOffboarding).Methods("POST")
+	hrRouter.HandleFunc("/employees/{id}/offboarding", offboardingHandler.CancelOffboarding).Methods("DELETE")
+	hrRouter.HandleFunc("/offboardings", offboardingHandler.ListOffboardings).Methods("GET")
+	hrRouter.HandleFunc("/offboarding-checklist", offboardingHandler.ListChecklistItems).Methods("GET")
+	hrRouter.HandleFunc("/offboarding-checklist", offboardingHandler.CreateChecklistItem).Methods("POST")
+	hrRouter.HandleFunc("/offboarding-checklist/{id}", offboardingHandler.UpdateChecklistItem).Methods("PUT")
+	hrRouter.HandleFunc("/offboarding-checklist/{id}", offboardingHandler.DeleteChecklistItem).Methods("DELETE")
+	hrRouter.HandleFunc("/onboarding-templates", onboardingHandler.ListTemplates).Methods("GET")
+	hrRouter.HandleFunc("/onboarding-templates", onboardingHandler.CreateTemplate).Methods("POST")
+	hrRouter.HandleFunc("/onboarding-templates/{id}", onboardingHandler.UpdateTemplate).Methods("PUT")
+	hrRouter.HandleFunc("/onboarding-templates/{id}", onboardingHandler.DeleteTemplate).Methods("DELETE")
+	hrRouter.HandleFunc("/onboarding/progress", onboardingHandler.GetProgress).Methods("GET")
+	hrRouter.HandleFunc("/leave-types", leaveTypeHandler.ListLeaveTypes).Methods("GET")
+	hrRouter.HandleFunc("/leave-types", leaveTypeHandler.CreateLeaveType).Methods("POST")
+	hrRouter.HandleFunc("/leave-types/{id}", leaveTypeHandler.GetLeaveType).Methods("GET")
+	hrRouter.HandleFunc("/leave-types/{id}", leaveTypeHandler.UpdateLeaveType).Methods("PUT")
+	hrRouter.HandleFunc("/leave-types/{id}", leaveTypeHandler.DeleteLeaveType).Methods("DELETE")
+	hrRouter.HandleFunc("/work-week", leaveRequestHandler.GetWorkWeek).Methods("GET")
+	hrRouter.HandleFunc("/work-week", leaveRequestHandler.UpdateWorkWeek).Methods("PUT")
+	hrRouter.HandleFunc("/leave-types/{id}/accrual-policy", leaveAccrualHandler.GetAccrualPolicy).Methods("GET")
+	hrRouter.HandleFunc("/leave-types/{id}/accrual-policy", leaveAccrualHandler.SaveAccrualPolicy).Methods("PUT")
+	hrRouter.HandleFunc("/leave-types/{id}/accrual-policy", leaveAccrualHandler.DeleteAccrualPolicy).Methods("DELETE")
+	hrRouter.HandleFunc("/leave-year", leaveAccrualHandler.GetLeaveYear).Methods("GET")
+	hrRouter.HandleFunc("/leave-year", leaveAccrualHandler.UpdateLeaveYear).Methods("PUT")
+	hrRouter.HandleFunc("/leave-accruals/run", leaveAccrualHandler.RunAccruals).Methods("POST")
+	hrRouter.HandleFunc("/leave-balances/adjustments", leaveAccrualHandler.AdjustLeaveBalance).Methods("POST")
+	hrRouter.HandleFunc("/holidays", holidayHandler.ListHolidays).Methods("GET")
+	hrRouter.HandleFunc("/holidays", holidayHandler.CreateHoliday).Methods("POST")
+	hrRouter.HandleFunc("/holidays/effective", holidayHandler.ListEffectiveHolidays).Methods("GET")
+	hrRouter.HandleFunc("/holidays/load", holidayHandler.LoadBundledHolidays).Methods("POST")
+	hrRouter.HandleFunc("/holidays/import", holidayHandler.ImportICalendar).Methods("POST")
+	hrRouter.HandleFunc("/holidays/{id}", holidayHandler.UpdateHoliday).Methods("PUT")
+	hrRouter.HandleFunc("/holidays/{id}", holidayHandler.DeleteHoliday).Methods("DELETE")
+	hrRouter.HandleFunc("/employees/{id}/work-location", holidayHandler.UpdateWorkLocation).Methods("PUT")
+	hrRouter.HandleFunc("/employees/export", exportHandler.ExportEmployees).Methods("GET")
+	hrRouter.HandleFunc("/employees/exports", exportHandler.CreateEmployeeExportJob).Methods("POST")
+	hrRouter.HandleFunc("/exports/{id}", exportHandler.GetExportJob).Methods("GET")
+	hrRouter.HandleFunc("/exports/{id}/download", exportHandler.DownloadExportJob).Methods("GET")
+	hrRouter.HandleFunc("/invitations/{id}/resend", invitationHandler.ResendInvitation).Methods("POST")
+	hrRouter.HandleFunc("/invitations/{id}/revoke", invitationHandler.RevokeInvitation).Methods("POST")
+	hrRouter.HandleFunc("/custom-fields", customFieldHandler.ListCustomFields).Methods("GET")
+	hrRouter.HandleFunc("/custom-fields", customFieldHandler.CreateCustomField).Methods("POST")
+	hrRouter.HandleFunc("/custom-fields/{id}", customFieldHandler.UpdateCustomField).Methods("PUT")
+	hrRouter.HandleFunc("/custom-fields/{id}", customFieldHandler.DeleteCustomField).Methods("DELETE")
+	hrRouter.HandleFunc("/approval-workflows", approvalHandler.ListWorkflows).Methods("GET")
+	hrRouter.HandleFunc("/approval-workflows", approvalHandler.CreateWorkflow).Methods("POST")
+	hrRouter.HandleFunc("/approval-workflows/{id}", approvalHandler.GetWorkflow).Methods("GET")
+	hrRouter.HandleFunc("/approval-workflows/{id}", approvalHandler.UpdateWorkflow).Methods("PUT")
+	hrRouter.HandleFunc("/approval-workflows/{id}", approvalHandler.DeleteWorkflow).Methods("DELETE")
+	hrRouter.HandleFunc("/approval-delegations", approvalHandler.ListDelegations).Methods("GET")
+	hrRouter.HandleFunc("/approval-delegations", approvalHandler.CreateDelegation).Methods("POST")
+	hrRouter.HandleFunc("/approval-delegations/{id}/revoke", approvalHandler.RevokeDelegation).Methods("POST")
+	hrRouter.HandleFunc("/memo-types", memoHandler.ListMemoTypes).Methods("GET")
+	hrRouter.HandleFunc("/memo-types", memoHandler.CreateMemoType).Methods("POST")
+	hrRouter.HandleFunc("/memo-types/{id}", memoHandler.GetMemoType).Methods("GET")
+	hrRouter.HandleFunc("/memo-types/{id}", memoHandler.UpdateMemoType).Methods("PUT")
+	hrRouter.HandleFunc("/memo-types/{id}", memoHandler.DeleteMemoType).Methods("DELETE")
+	hrRouter.HandleFunc("/disciplinary-cases", disciplinaryHandler.ListCases).Methods("GET")
+	hrRouter.HandleFunc("/disciplinary-cases", disciplinaryHandler.OpenCase).Methods("POST")
+	hrRouter.HandleFunc("/disciplinary-cases/{id}", disciplinaryHandler.GetCase).Methods("GET")
+	hrRouter.HandleFunc("/disciplinary-cases/{id}/stage", disciplinaryHandler.RecordStage).Methods("POST")
+	hrRouter.HandleFunc("/disciplinary-cases/{id}/memos", disciplinaryHandler.LinkMemo).Methods("POST")
+	hrRouter.HandleFunc("/employees/{id}/disciplinary-summary", disciplinaryHandler.GetDisciplinarySummary).Methods("GET")
+	hrRouter.HandleFunc("/document-types", documentHandler.ListDocumentTypes).Methods("GET")
+	hrRouter.HandleFunc("/document-types", documentHandler.CreateDocumentType).Methods("POST")
+	hrRouter.HandleFunc("/document-types/{id}", documentHandler.GetDocumentType).Methods("GET")
+	hrRouter.HandleFunc("/document-types/{id}", documentHandler.UpdateDocumentType).Methods("PUT")
+	hrRouter.HandleFunc("/document-requirements", documentHandler.ListDocumentRequirements).Methods("GET")
+	hrRouter.HandleFunc("/document-requirements", documentHandler.CreateDocumentRequirement).Methods("POST")
+	hrRouter.HandleFunc("/document-requirements/{id}", documentHandler.DeleteDocumentRequirement).Methods("DELETE")
+	hrRouter.HandleFunc("/documents/expiring", documentHandler.ListExpiringDocuments).Methods("GET")
+	hrRouter.HandleFunc("/documents/missing", documentHandler.MissingDocuments).Methods("GET")
+	hrRouter.HandleFunc("/letter-templates", letterHandler.ListLetterTemplates).Methods("GET")
+	hrRouter.HandleFunc("/letter-templates", letterHandler.CreateLetterTemplate).Methods("POST")
+	hrRouter.HandleFunc("/letter-templates/placeholders", letterHandler.ListLetterPlaceholders).Methods("GET")
+	hrRouter.HandleFunc("/letter-templates/{id}", letterHandler.GetLetterTemplate).Methods("GET")
+	hrRouter.HandleFunc("/letter-templates/{id}", letterHandler.UpdateLetterTemplate).Methods("PUT")
+	hrRouter.HandleFunc("/letter-templates/{id}/preview", letterHandler.PreviewLetter).Methods("POST")
+	hrRouter.HandleFunc("/letter-templates/{id}/generate", letterHandler.GenerateLetter).Methods("POST")
+	hrRouter.HandleFunc("/letter-templates/{id}/generate-bulk", letterHandler.BulkGenerateLetters).Methods("POST")
+	hrRouter.HandleFunc("/signature-requests", signatureHandler.ListSignatureRequests).Methods("GET")
+	hrRouter.HandleFunc("/signature-requests", signatureHandler.CreateSignatureRequest).Methods("POST")
+	hrRouter.HandleFunc("/signature-requests/{id}/cancel", signatureHandler.CancelSignatureRequest).Methods("POST")
+	hrRouter.HandleFunc("/signature-requests/{id}/seal", signatureHandler.SealSignatureRequest).Methods("POST")
+	hrRouter.HandleFunc("/policy-acknowledgements", signatureHandler.PolicyAcknowledgements).Methods("GET")
 
 	// ============ SUPER ADMIN CAN ALSO CREATE EMPLOYEES ============
 	superAdminRouter.HandleFunc("/employees", employeeHandler.CreateEmployee).Methods("POST")
+	superAdminRouter.HandleFunc("/employees", employeeHandler.ListEmployees).Methods("GET")
+	superAdminRouter.HandleFunc("/employees/{id}", employeeHandler.UpdateEmployee).Methods("PUT")
+	superAdminRouter.HandleFunc("/employees/{id}/employment-history", employmentHandler.ScheduleChange).Methods("POST")
+	superAdminRouter.HandleFunc("/employees/{id}/employment-history/{changeId}", employmentHandler.CancelChange).Methods("DELETE")
+	superAdminRouter.HandleFunc("/org", employmentHandler.GetOrgAsOf).Methods("GET")
+	superAdminRouter.HandleFunc("/employees/{id}/offboarding", offboardingHandler.StartOffboarding).Methods("POST")
+	superAdminRouter.HandleFunc("/employees/{id}/offboarding", offboardingHandler.CancelOffboarding).Methods("DELETE")
+	superAdminRouter.HandleFunc("/offboardings", offboardingHandler.ListOffboardings).Methods("GET")
+	superAdminRouter.HandleFunc("/offboarding-checklist", offboardingHandler.ListChecklistItems).Methods("GET")
+	superAdminRouter.HandleFunc("/offboarding-checklist", offboardingHandler.CreateChecklistItem).Methods("POST")
+	superAdminRouter.HandleFunc("/offboarding-checklist/{id}", offboardingHandler.UpdateChecklistItem).Methods("PUT")
+	superAdminRouter.HandleFunc("/offboarding-checklist/{id}", offboardingHandler.DeleteChecklistItem).Methods("DELETE")
+	superAdminRouter.HandleFunc("/onboarding-templates", onboardingHandler.ListTemplates).Methods("GET")
+	superAdminRouter.HandleFunc("/onboarding-templates", onboardingHandler.CreateTemplate).Methods("POST")
+	superAdminRouter.HandleFunc("/onboarding-templates/{id}", onboardingHandler.UpdateTemplate).Methods("PUT")
+	superAdminRouter.HandleFunc("/onboarding-templates/{id}", onboardingHandler.DeleteTemplate).Methods("DELETE")
+	superAdminRouter.HandleFunc("/onboarding/progress", onboardingHandler.GetProgress).Methods("GET")
+	superAdminRouter.HandleFunc("/leave-types", leaveTypeHandler.ListLeaveTypes).Methods("GET")
+	superAdminRouter.HandleFunc("/leave-types", leaveTypeHandler.CreateLeaveType).Methods("POST")
+	superAdminRouter.HandleFunc("/leave-types/{id}", leaveTypeHandler.GetLeaveType).Methods("GET")
+	superAdminRouter.HandleFunc("/leave-types/{id}", leaveTypeHandler.UpdateLeaveType).Methods("PUT")
+	superAdminRouter.HandleFunc("/leave-types/{id}", leaveTypeHandler.DeleteLeaveType).Methods("DELETE")
+	superAdminRouter.HandleFunc("/work-week", leaveRequestHandler.GetWorkWeek).Methods("GET")
+	superAdminRouter.HandleFunc("/work-week", leaveRequestHandler.UpdateWorkWeek).Methods("PUT")
+	superAdminRouter.HandleFunc("/leave-types/{id}/accrual-policy", leaveAccrualHandler.GetAccrualPolicy).Methods("GET")
+	superAdminRouter.HandleFunc("/leave-types/{id}/accrual-policy", leaveAccrualHandler.SaveAccrualPolicy).Methods("PUT")
+	superAdminRouter.HandleFunc("/leave-types/{id}/accrual-policy", leaveAccrualHandler.DeleteAccrualPolicy).Methods("DELETE")
+	superAdminRouter.HandleFunc("/leave-year", leaveAccrualHandler.GetLeaveYear).Methods("GET")
+	superAdminRouter.HandleFunc("/leave-year", leaveAccrualHandler.UpdateLeaveYear).Methods("PUT")
+	superAdminRouter.HandleFunc("/leave-accruals/run", leaveAccrualHandler.RunAccruals).Methods("POST")
+	superAdminRouter.HandleFunc("/leave-balances/adjustments", leaveAccrualHandler.AdjustLeaveBalance).Methods("POST")
+	superAdminRouter.HandleFunc("/holidays", holidayHandler.ListHolidays).Methods("GET")
+	superAdminRouter.HandleFunc("/holidays", holidayHandler.CreateHoliday).Methods("POST")
+	superAdminRouter.HandleFunc("/holidays/effective", holidayHandler.ListEffectiveHolidays).Methods("GET")
+	superAdminRouter.HandleFunc("/holidays/load", holidayHandler.LoadBundledHolidays).Methods("POST")
+	superAdminRouter.HandleFunc("/holidays/import", holidayHandler.ImportICalendar).Methods("POST")
+	superAdminRouter.HandleFunc("/holidays/{id}", holidayHandler.UpdateHoliday).Methods("PUT")
+	superAdminRouter.HandleFunc("/holidays/{id}", holidayHandler.DeleteHoliday).Methods("DELETE")
+	superAdminRouter.HandleFunc("/employees/{id}/work-location", holidayHandler.UpdateWorkLocation).Methods("PUT")
+	superAdminRouter.HandleFunc("/employees/export", exportHandler.ExportEmployees).Methods("GET")
+	superAdminRouter.HandleFunc("/employees/exports", exportHandler.CreateEmployeeExportJob).Methods("POST")
+	superAdminRouter.HandleFunc("/exports/{id}", exportHandler.GetExportJob).Methods("GET")
+	superAdminRouter.HandleFunc("/exports/{id}/download", exportHandler.DownloadExportJob).Methods("GET")
+	superAdminRouter.HandleFunc("/invitations/{id}/resend", invitationHandler.ResendInvitation).Methods("POST")
+	superAdminRouter.HandleFunc("/invitations/{id}/revoke", invitationHandler.RevokeInvitation).Methods("POST")
+	superAdminRouter.HandleFunc("/custom-fields", customFieldHandler.ListCustomFields).Methods("GET")
+	superAdminRouter.HandleFunc("/custom-fields", customFieldHandler.CreateCustomField).Methods("POST")
+	superAdminRouter.HandleFunc("/custom-fields/{id}", customFieldHandler.UpdateCustomField).Methods("PUT")
+	superAdminRouter.HandleFunc("/custom-fields/{id}", customFieldHandler.DeleteCustomField).Methods("DELETE")
+	superAdminRouter.HandleFunc("/approval-workflows", approvalHandler.ListWorkflows).Methods("GET")
+	superAdminRouter.HandleFunc("/approval-workflows", approvalHandler.CreateWorkflow).Methods("POST")
+	superAdminRouter.HandleFunc("/approval-workflows/{id}", approvalHandler.GetWorkflow).Methods("GET")
+	superAdminRouter.HandleFunc("/approval-workflows/{id}", approvalHandler.UpdateWorkflow).Methods("PUT")
+	superAdminRouter.HandleFunc("/approval-workflows/{id}", approvalHandler.DeleteWorkflow).Methods("DELETE")
+	superAdminRouter.HandleFunc("/approval-delegations", approvalHandler.ListDelegations).Methods("GET")
+	superAdminRouter.HandleFunc("/approval-delegations", approvalHandler.CreateDelegation).Methods("POST")
+	superAdminRouter.HandleFunc("/approval-delegations/{id}/revoke", approvalHandler.RevokeDelegation).Methods("POST")
+	superAdminRouter.HandleFunc("/memo-types", memoHandler.ListMemoTypes).Methods("GET")
+	superAdminRouter.HandleFunc("/memo-types", memoHandler.CreateMemoType).Methods("POST")
+	superAdminRouter.HandleFunc("/memo-types/{id}", memoHandler.GetMemoType).Methods("GET")
+	superAdminRouter.HandleFunc("/memo-types/{id}", memoHandler.UpdateMemoType).Methods("PUT")
+	superAdminRouter.HandleFunc("/memo-types/{id}", memoHandler.DeleteMemoType).Methods("DELETE")
+	superAdminRouter.HandleFunc("/disciplinary-cases", disciplinaryHandler.ListCases).Methods("GET")
+	superAdminRouter.HandleFunc("/disciplinary-cases", disciplinaryHandler.OpenCase).Methods("POST")
+	superAdminRouter.HandleFunc("/disciplinary-cases/{id}", disciplinaryHandler.GetCase).Methods("GET")
+	superAdminRouter.HandleFunc("/disciplinary-cases/{id}/stage", disciplinaryHandler.RecordStage).Methods("POST")
+	superAdminRouter.HandleFunc("/disciplinary-cases/{id}/memos", disciplinaryHandler.LinkMemo).Methods("POST")
+	superAdminRouter.HandleFunc("/employees/{id}/disciplinary-summary", disciplinaryHandler.GetDisciplinarySummary).Methods("GET")
+	superAdminRouter.HandleFunc("/document-types", documentHandler.ListDocumentTypes).Methods("GET")
+	superAdminRouter.HandleFunc("/document-types", documentHandler.CreateDocumentType).Methods("POST")
+	superAdminRouter.HandleFunc("/document-types/{id}", documentHandler.GetDocumentType).Methods("GET")
+	superAdminRouter.HandleFunc("/document-types/{id}", documentHandler.UpdateDocumentType).Methods("PUT")
+	superAdminRouter.HandleFunc("/document-requirements", documentHandler.ListDocumentRequirements).Methods("GET")
+	superAdminRouter.HandleFunc("/document-requirements", documentHandler.CreateDocumentRequirement).Methods("POST")
+	superAdminRouter.HandleFunc("/document-requirements/{id}", documentHandler.DeleteDocumentRequirement).Methods("DELETE")
+	superAdminRouter.HandleFunc("/documents/expiring", documentHandler.ListExpiringDocuments).Methods("GET")
+	superAdminRouter.HandleFunc("/documents/missing", documentHandler.MissingDocuments).Methods("GET")
+	superAdminRouter.HandleFunc("/letter-templates", letterHandler.ListLetterTemplates).Methods("GET")
+	superAdminRouter.HandleFunc("/letter-templates", letterHandler.CreateLetterTemplate).Methods("POST")
+	superAdminRouter.HandleFunc("/letter-templates/placeholders", letterHandler.ListLetterPlaceholders).Methods("GET")
+	superAdminRouter.HandleFunc("/letter-templates/{id}", letterHandler.GetLetterTemplate).Methods("GET")
+	superAdminRouter.HandleFunc("/letter-templates/{id}", letterHandler.UpdateLetterTemplate).Methods("PUT")
+	superAdminRouter.HandleFunc("/letter-templates/{id}/preview", letterHandler.PreviewLetter).Methods("POST")
+	superAdminRouter.HandleFunc("/letter-templates/{id}/generate", letterHandler.GenerateLetter).Methods("POST")
+	superAdminRouter.HandleFunc("/letter-templates/{id}/generate-bulk", letterHandler.BulkGenerateLetters).Methods("POST")
+	superAdminRouter.HandleFunc("/signature-requests", signatureHandler.ListSignatureRequests).Methods("GET")
+	superAdminRouter.HandleFunc("/signature-requests", signatureHandler.CreateSignatureRequest).Methods("POST")
+	superAdminRouter.HandleFunc("/signature-requests/{id}/cancel", signatureHandler.CancelSignatureRequest).Methods("POST")
+	superAdminRouter.HandleFunc("/signature-requests/{id}/seal", signatureHandler.SealSignatureRequest).Methods("POST")
+	superAdminRouter.HandleFunc("/policy-acknowledgements", signatureHandler.PolicyAcknowledgements).Methods("GET")
+
+	// ============ EMPLOYEE PROFILE ROUTES ============
+	// Access to each section is decided per caller by the profile service
+	employeeRouter := router.PathPrefix("/employees").Subrouter()
+	employeeRouter.Use(middleware.AuthenticationMiddleware)
+	employeeRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	employeeRouter.HandleFunc("/{id}/personal-details", profileHandler.GetPersonalDetails).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/personal-details", profileHandler.UpdatePersonalDetails).Methods("PUT")
+	employeeRouter.HandleFunc("/{id}/addresses", profileHandler.ListAddresses).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/addresses", profileHandler.AddAddress).Methods("POST")
+	employeeRouter.HandleFunc("/{id}/emergency-contacts", profileHandler.ListEmergencyContacts).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/emergency-contacts", profileHandler.SaveEmergencyContact).Methods("POST")
+	employeeRouter.HandleFunc("/{id}/emergency-contacts/{contactId}", profileHandler.SaveEmergencyContact).Methods("PUT")
+	employeeRouter.HandleFunc("/{id}/emergency-contacts/{contactId}", profileHandler.DeleteEmergencyContact).Methods("DELETE")
+	employeeRouter.HandleFunc("/{id}/bank-accounts", profileHandler.ListBankAccounts).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/bank-accounts", profileHandler.SaveBankAccount).Methods("POST")
+	employeeRouter.HandleFunc("/{id}/bank-accounts/{accountId}", profileHandler.SaveBankAccount).Methods("PUT")
+	employeeRouter.HandleFunc("/{id}/bank-accounts/{accountId}", profileHandler.DeleteBankAccount).Methods("DELETE")
+	employeeRouter.HandleFunc("/{id}/employment-history", employmentHandler.ListHistory).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/employment", employmentHandler.GetEmploymentAsOf).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/offboarding", offboardingHandler.GetOffboarding).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/offboarding/tasks/{taskId}/complete", offboardingHandler.CompleteTask).Methods("POST")
+	employeeRouter.HandleFunc("/{id}/onboarding", onboardingHandler.GetEmployeeOnboarding).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/leave-balances", leaveAccrualHandler.ListEmployeeLeaveBalances).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/leave-ledger", leaveAccrualHandler.ListEmployeeLeaveLedger).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/documents", documentHandler.ListEmployeeDocuments).Methods("GET")
+	employeeRouter.HandleFunc("/{id}/documents", documentHandler.UploadDocument).Methods("POST")
+
+	// ============ SELF-SERVICE ROUTES ============
+	meRouter := router.PathPrefix("/me").Subrouter()
+	meRouter.Use(middleware.AuthenticationMiddleware)
+	meRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	meRouter.HandleFunc("/tasks", onboardingHandler.ListMyTasks).Methods("GET")
+	meRouter.HandleFunc("/tasks/{id}/complete", onboardingHandler.CompleteTask).Methods("POST")
+	meRouter.HandleFunc("/leave-types", leaveTypeHandler.ListMyLeaveTypes).Methods("GET")
+	meRouter.HandleFunc("/leave-requests", leaveRequestHandler.SubmitLeaveRequest).Methods("POST")
+	meRouter.HandleFunc("/leave-requests", leaveRequestHandler.ListMyLeaveRequests).Methods("GET")
+	meRouter.HandleFunc("/leave-requests/{id}/cancel", leaveRequestHandler.CancelLeaveRequest).Methods("POST")
+	meRouter.HandleFunc("/leave-balances", leaveAccrualHandler.ListMyLeaveBalances).Methods("GET")
+	meRouter.HandleFunc("/holidays", holidayHandler.ListMyHolidays).Methods("GET")
+	meRouter.HandleFunc("/calendar-feed", leaveCalendarHandler.CreateCalendarFeed).Methods("POST")
+	meRouter.HandleFunc("/calendar-feed", leaveCalendarHandler.RevokeCalendarFeed).Methods("DELETE")
+	meRouter.HandleFunc("/approvals", approvalHandler.ListMyApprovals).Methods("GET")
+	meRouter.HandleFunc("/approvals/bulk", approvalHandler.BulkDecide).Methods("POST")
+	meRouter.HandleFunc("/approval-delegations", approvalHandler.ListMyDelegations).Methods("GET")
+	meRouter.HandleFunc("/approval-delegations", approvalHandler.CreateDelegation).Methods("POST")
+	meRouter.HandleFunc("/approval-delegations/{id}/revoke", approvalHandler.RevokeDelegation).Methods("POST")
+	meRouter.HandleFunc("/memo-types", memoHandler.ListActiveMemoTypes).Methods("GET")
+	meRouter.HandleFunc("/memos", memoHandler.ListMyMemos).Methods("GET")
+	meRouter.HandleFunc("/memos/{id}/acknowledge", memoHandler.AcknowledgeMemo).Methods("POST")
+	meRouter.HandleFunc("/signature-requests", signatureHandler.ListMySignatureRequests).Methods("GET")
+
+	// ============ LEAVE REQUEST ROUTES ============
+	// Managers see their direct reports' requests and HR sees every request
+	leaveRouter := router.PathPrefix("/leave-requests").Subrouter()
+	leaveRouter.Use(middleware.AuthenticationMiddleware)
+	leaveRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	leaveRouter.HandleFunc("", leaveRequestHandler.ListLeaveRequests).Methods("GET")
+	leaveRouter.HandleFunc("/{id}", leaveRequestHandler.GetLeaveRequest).Methods("GET")
+	leaveRouter.HandleFunc("/{id}/approve", leaveRequestHandler.ApproveLeaveRequest).Methods("POST")
+	leaveRouter.HandleFunc("/{id}/reject", leaveRequestHandler.RejectLeaveRequest).Methods("POST")
+	leaveRouter.HandleFunc("/{id}/cancel", leaveRequestHandler.CancelLeaveRequest).Methods("POST")
+
+	// ============ MEMO ROUTES ============
+	// Disciplinary memos are only seen by HR, their subject and the subject's
+	// managers; approvers decide memos going through an approval workflow
+	memoRouter := router.PathPrefix("/memos").Subrouter()
+	memoRouter.Use(middleware.AuthenticationMiddleware)
+	memoRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	memoRouter.HandleFunc("", memoHandler.ListMemos).Methods("GET")
+	memoRouter.HandleFunc("", memoHandler.CreateMemo).Methods("POST")
+	memoRouter.HandleFunc("/{id}", memoHandler.GetMemo).Methods("GET")
+	memoRouter.HandleFunc("/{id}/approve", memoHandler.ApproveMemo).Methods("POST")
+	memoRouter.HandleFunc("/{id}/reject", memoHandler.RejectMemo).Methods("POST")
+	memoRouter.HandleFunc("/{id}/cancel", memoHandler.CancelMemo).Methods("POST")
+	memoRouter.HandleFunc("/{id}/acknowledge", memoHandler.AcknowledgeMemo).Methods("POST")
+
+	// ============ DOCUMENT ROUTES ============
+	// Documents follow the employee's profile: HR and the employee see them,
+	// and only HR deletes them
+	documentRouter := router.PathPrefix("/documents").Subrouter()
+	documentRouter.Use(middleware.AuthenticationMiddleware)
+	documentRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	documentRouter.HandleFunc("/types", documentHandler.ListActiveDocumentTypes).Methods("GET")
+	documentRouter.HandleFunc("/{id}/download", documentHandler.DownloadDocument).Methods("GET")
+	documentRouter.HandleFunc("/{id}/versions", documentHandler.ListDocumentVersions).Methods("GET")
+	documentRouter.HandleFunc("/{id}/versions", documentHandler.UploadDocumentVersion).Methods("POST")
+	documentRouter.HandleFunc("/{id}/versions/{version}/download", documentHandler.DownloadDocumentVersion).Methods("GET")
+	documentRouter.HandleFunc("/{id}", documentHandler.DeleteDocument).Methods("DELETE")
+
+	// ============ SIGNATURE ROUTES ============
+	// HR and a request's signers see it and the document it asks to be
+	// signed; only signers sign or decline
+	signatureRouter := router.PathPrefix("/signature-requests").Subrouter()
+	signatureRouter.Use(middleware.AuthenticationMiddleware)
+	signatureRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	signatureRouter.HandleFunc("/{id}", signatureHandler.GetSignatureRequest).Methods("GET")
+	signatureRouter.HandleFunc("/{id}/document", signatureHandler.DownloadSignatureDocument).Methods("GET")
+	signatureRouter.HandleFunc("/{id}/sealed", signatureHandler.DownloadSealedDocument).Methods("GET")
+	signatureRouter.HandleFunc("/{id}/sign", signatureHandler.SignSignatureRequest).Methods("POST")
+	signatureRouter.HandleFunc("/{id}/decline", signatureHandler.DeclineSignatureRequest).Methods("POST")
+
+	// ============ AUDIT LOG ROUTES ============
+	// HR read the audit log; auditors and every export need the audit_logs
+	// permission through their role
+	auditRouter := router.PathPrefix("/audit-logs").Subrouter()
+	auditRouter.Use(middleware.AuthenticationMiddleware)
+	auditRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	auditRouter.HandleFunc("", auditHandler.ListAuditLogs).Methods("GET")
+	auditRouter.HandleFunc("/timeline", auditHandler.GetEntityTimeline).Methods("GET")
+	auditRouter.HandleFunc("/export", auditHandler.ExportAuditLogs).Methods("GET")
+	auditRouter.HandleFunc("/verify", auditHandler.VerifyAuditChain).Methods("GET")
+	auditRouter.HandleFunc("/checkpoints/export", auditHandler.ExportCheckpoints).Methods("GET")
+
+	// ============ LEAVE CALENDAR ROUTES ============
+	// The feed is read by calendar apps, which authenticate with the token in
+	// the URL, so it is registered ahead of the authenticated subrouter
+	router.HandleFunc("/calendar/leave.ics", leaveCalendarHandler.GetCalendarFeed).Methods("GET")
+	calendarRouter := router.PathPrefix("/calendar").Subrouter()
+	calendarRouter.Use(middleware.AuthenticationMiddleware)
+	calendarRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	calendarRouter.HandleFunc("/leave", leaveCalendarHandler.ListLeaveCalendar).Methods("GET")
+
+	// ============ APPROVAL ROUTES ============
+	// Requesters, approvers and HR see an approval; only its approvers decide
+	approvalRouter := router.PathPrefix("/approval-requests").Subrouter()
+	approvalRouter.Use(middleware.AuthenticationMiddleware)
+	approvalRouter.Use(middleware.SessionRevocationMiddleware(authService.IsSessionRevoked))
+	approvalRouter.HandleFunc("", approvalHandler.ListEntityApprovalRequests).Methods("GET")
+	approvalRouter.HandleFunc("/{id}", approvalHandler.GetApprovalRequest).Methods("GET")
+	approvalRouter.HandleFunc("/{id}/approve", approvalHandler.ApproveRequest).Methods("POST")
+	approvalRouter.HandleFunc("/{id}/reject", approvalHandler.RejectRequest).Methods("POST")
 
 	port := ":8080"
 	fmt.Printf("\n✓ Server starting on http://localhost%s\n", port)
-	fmt.Println("Press Ctrl+C to stop the server\n")
+	fmt.Println("Press Ctrl+C to stop the server")
+
+	server := &http.Server{Addr: port, Handler: router}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server shutdown error: %v", err)
+		}
+	}()
 
-	if err := http.ListenAndServe(port, router); err != nil {
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Server error: %v", err)
 	}
+	<-exportDone
+	fmt.Println("Server stopped")
 }
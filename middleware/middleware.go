@@ -1,45 +1,159 @@
 package middleware
 
 import (
-<<<<<<< HEAD
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-=======
->>>>>>> c84adbd5fea15cfee43772c5a62f177c37a8ebec
 	"log"
+	"net"
 	"net/http"
 	"runtime/debug"
 	"strings"
 	"time"
-<<<<<<< HEAD
 
 	"github.com/dgrijalva/jwt-go"
-=======
-	"github.com/falasefemi2/peopleos/utils"
->>>>>>> c84adbd5fea15cfee43772c5a62f177c37a8ebec
 )
 
 type contextKey string
 
-const userContextKey = contextKey("user")
+const (
+	userContextKey    = contextKey("user")
+	requestContextKey = contextKey("request")
+)
+
+// RequestInfo identifies the HTTP request a change was made in
+type RequestInfo struct {
+	RequestID string
+	IPAddress string
+}
 
 // Claims represents the JWT claims
 type Claims struct {
-	ID   int    `json:"id"`
-	Role string `json:"role"`
+	ID       int    `json:"id"`
+	TenantID int    `json:"tenant_id"`
+	Role     string `json:"role"`
 	jwt.StandardClaims
 }
 
 var jwtKey = []byte("your-secret-key") // Replace with a secure key in production
 
+// RequestIDMiddleware tags each request with an ID and the client's IP
+// address. The ID is taken from the X-Request-ID header when the client sent
+// a usable one and is echoed back in the response. X-Forwarded-For is only
+// read from requests sent by one of trustedProxies, as anyone else can set
+// it to any address.
+func RequestIDMiddleware(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get("X-Request-ID")
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set("X-Request-ID", requestID)
+
+			ctx := WithRequestInfo(r.Context(), RequestInfo{RequestID: requestID, IPAddress: clientIP(r, trustedProxies)})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ParseTrustedProxies parses a comma separated list of the IP addresses and
+// CIDR ranges of the proxies in front of the server
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	proxies := []*net.IPNet{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			entry = fmt.Sprintf("%s/%d", ip, bits)
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 100 {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// clientIP returns the address of the client. When the request came from a
+// trusted proxy, that is the last address in X-Forwarded-For not of a trusted
+// proxy: addresses before it were sent by the client and may be forged.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(net.ParseIP(host), trustedProxies) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		host = ip.String()
+		if !isTrustedProxy(ip, trustedProxies) {
+			break
+		}
+	}
+	return host
+}
+
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // LoggingMiddleware logs all HTTP requests
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		next.ServeHTTP(w, r)
 		duration := time.Since(start)
-		log.Printf("[%s] %s %s - %v", r.Method, r.RequestURI, r.RemoteAddr, duration)
+		info, _ := GetRequestInfo(r.Context())
+		log.Printf("[%s] %s %s %s - %v", r.Method, r.RequestURI, r.RemoteAddr, info.RequestID, duration)
 	})
 }
 
@@ -51,14 +165,7 @@ func RecoveryMiddleware(next http.Handler) http.Handler {
 				log.Printf("PANIC: %v\n%s", err, debug.Stack())
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusInternalServerError)
-<<<<<<< HEAD
 				fmt.Fprintf(w, `{"success":false,"error":"Internal server error","code":500}`)
-=======
-
-				// In production, don't expose stack traces
-				// fmt.Fprintf(w, `{"success":false,"error":"Internal server error","code":500}`)
-				utils.RespondWithError(w, http.StatusInternalServerError, "Internal server error")
->>>>>>> c84adbd5fea15cfee43772c5a62f177c37a8ebec
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -70,7 +177,7 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -105,6 +212,29 @@ func AuthenticationMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// SessionRevocationMiddleware rejects tokens whose holder has had their
+// access revoked since the token was issued. It must run after
+// AuthenticationMiddleware.
+func SessionRevocationMiddleware(isRevoked func(ctx context.Context, tenantID int, employeeID int, issuedAt time.Time) (bool, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(userContextKey).(*Claims)
+			if !ok {
+				http.Error(w, `{"success":false,"error":"Could not retrieve user claims"}`, http.StatusInternalServerError)
+				return
+			}
+
+			revoked, err := isRevoked(r.Context(), claims.TenantID, claims.ID, time.Unix(claims.IssuedAt, 0))
+			if err != nil || revoked {
+				http.Error(w, `{"success":false,"error":"Session has been revoked"}`, http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // RoleMiddleware checks for a specific role
 func RoleMiddleware(role string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -131,6 +261,28 @@ func RoleMiddleware(role string) func(http.Handler) http.Handler {
 	}
 }
 
+// GetUserClaims returns the JWT claims stored by AuthenticationMiddleware
+func GetUserClaims(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(userContextKey).(*Claims)
+	return claims, ok
+}
+
+// WithUserClaims returns a copy of ctx carrying the given claims
+func WithUserClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, userContextKey, claims)
+}
+
+// GetRequestInfo returns the request details stored by RequestIDMiddleware
+func GetRequestInfo(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestContextKey).(RequestInfo)
+	return info, ok
+}
+
+// WithRequestInfo returns a copy of ctx carrying the given request details
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestContextKey, info)
+}
+
 // ChainMiddleware chains multiple middlewares
 func ChainMiddleware(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	for i := len(middlewares) - 1; i >= 0; i-- {
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+// ApprovalWorkflow is the ordered steps a tenant's records of one entity type
+// go through before they are approved
+type ApprovalWorkflow struct {
+	ID         int            `db:"id" json:"id"`
+	TenantID   int            `db:"tenant_id" json:"tenant_id"`
+	Name       string         `db:"name" json:"name"`
+	EntityType string         `db:"entity_type" json:"entity_type"`
+	Status     string         `db:"status" json:"status"`
+	Steps      []ApprovalStep `json:"steps"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// ApprovalStep is one source of approvers in a workflow. Steps with the same
+// StepOrder run in parallel.
+type ApprovalStep struct {
+	ID                int                     `db:"id" json:"id"`
+	WorkflowID        int                     `db:"workflow_id" json:"workflow_id"`
+	StepOrder         int                     `db:"step_order" json:"step_order"`
+	ApproverType      string                  `db:"approver_type" json:"approver_type"`
+	ApproverRoleID    *int                    `db:"approver_role_id" json:"approver_role_id"`
+	ApproverRoleName  string                  `json:"approver_role"`
+	Description       string                  `db:"description" json:"description"`
+	ApprovalMode      string                  `db:"approval_mode" json:"approval_mode"`
+	RequiredApprovals int                     `db:"required_approvals" json:"required_approvals"`
+	SkipIfRequester   bool                    `db:"skip_if_requester" json:"skip_if_requester"`
+	Conditions        []dto.ApprovalCondition `db:"conditions" json:"conditions"`
+	ReminderHours     int                     `db:"reminder_hours" json:"reminder_hours"`
+	SLAHours          int                     `db:"sla_hours" json:"sla_hours"`
+}
+
+func (a *ApprovalWorkflow) ToResponse() *dto.ApprovalWorkflowResponse {
+	steps := make([]dto.ApprovalStepResponse, len(a.Steps))
+	for i, step := range a.Steps {
+		steps[i] = dto.ApprovalStepResponse{
+			ID:                step.ID,
+			StepOrder:         step.StepOrder,
+			ApproverType:      step.ApproverType,
+			ApproverRoleID:    step.ApproverRoleID,
+			ApproverRole:      step.ApproverRoleName,
+			Description:       step.Description,
+			ApprovalMode:      step.ApprovalMode,
+			RequiredApprovals: step.RequiredApprovals,
+			SkipIfRequester:   step.SkipIfRequester,
+			Conditions:        step.Conditions,
+			ReminderHours:     step.ReminderHours,
+			SLAHours:          step.SLAHours,
+		}
+	}
+
+	return &dto.ApprovalWorkflowResponse{
+		ID:         a.ID,
+		Name:       a.Name,
+		EntityType: a.EntityType,
+		Status:     a.Status,
+		Steps:      steps,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+	}
+}
+
+// ApprovalRequest is a workflow running for one record. Version goes up with
+// every decision so concurrent decisions cannot both apply.
+type ApprovalRequest struct {
+	ID               int        `db:"id" json:"id"`
+	TenantID         int        `db:"tenant_id" json:"tenant_id"`
+	WorkflowID       *int       `db:"workflow_id" json:"workflow_id"`
+	EntityType       string     `db:"entity_type" json:"entity_type"`
+	EntityID         int        `db:"entity_id" json:"entity_id"`
+	RequesterID      int        `db:"requester_id" json:"requester_id"`
+	RequesterName    string     `json:"requester_name"`
+	Status           string     `db:"status" json:"status"`
+	CurrentStepOrder int        `db:"current_step_order" json:"current_step_order"`
+	Version          int        `db:"version" json:"version"`
+	Approvals        []Approval `json:"approvals"`
+	CompletedAt      *time.Time `db:"completed_at" json:"completed_at"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Approval is one approver's part in a step of an approval request.
+// RemindAt and EscalateAt are when the scheduler reminds the approver and
+// escalates the approval while it is pending.
+type Approval struct {
+	ID                int        `db:"id" json:"id"`
+	ApprovalRequestID int        `db:"approval_request_id" json:"approval_request_id"`
+	ApprovalStepID    *int       `db:"approval_step_id" json:"approval_step_id"`
+	StepOrder         int        `db:"step_order" json:"step_order"`
+	ApproverID        int        `db:"approver_id" json:"approver_id"`
+	ApproverName      string     `json:"approver_name"`
+	Status            string     `db:"status" json:"status"`
+	Comments          string     `db:"comments" json:"comments"`
+	DelegatedFromID   *int       `db:"delegated_from_id" json:"delegated_from_id"`
+	EscalatedFromID   *int       `db:"escalated_from_id" json:"escalated_from_id"`
+	RemindAt          *time.Time `db:"remind_at" json:"remind_at"`
+	RemindedAt        *time.Time `db:"reminded_at" json:"reminded_at"`
+	EscalateAt        *time.Time `db:"escalate_at" json:"escalate_at"`
+	DecidedAt         *time.Time `db:"decided_at" json:"decided_at"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (a *ApprovalRequest) ToResponse() *dto.ApprovalRequestResponse {
+	approvals := make([]dto.ApprovalResponse, len(a.Approvals))
+	for i, approval := range a.Approvals {
+		approvals[i] = dto.ApprovalResponse{
+			ID:              approval.ID,
+			StepOrder:       approval.StepOrder,
+			ApproverID:      approval.ApproverID,
+			ApproverName:    approval.ApproverName,
+			Status:          approval.Status,
+			Comments:        approval.Comments,
+			DelegatedFromID: approval.DelegatedFromID,
+			EscalatedFromID: approval.EscalatedFromID,
+			EscalateAt:      approval.EscalateAt,
+			DecidedAt:       approval.DecidedAt,
+			CreatedAt:       approval.CreatedAt,
+		}
+	}
+
+	return &dto.ApprovalRequestResponse{
+		ID:               a.ID,
+		WorkflowID:       a.WorkflowID,
+		EntityType:       a.EntityType,
+		EntityID:         a.EntityID,
+		RequesterID:      a.RequesterID,
+		RequesterName:    a.RequesterName,
+		Status:           a.Status,
+		CurrentStepOrder: a.CurrentStepOrder,
+		Version:          a.Version,
+		Approvals:        approvals,
+		CompletedAt:      a.CompletedAt,
+		CreatedAt:        a.CreatedAt,
+		UpdatedAt:        a.UpdatedAt,
+	}
+}
+
+// ApprovalDelegation passes a delegator's approvals to a delegate between two
+// dates, for one entity type or, when EntityType is empty, for all of them
+type ApprovalDelegation struct {
+	ID            int        `db:"id" json:"id"`
+	TenantID      int        `db:"tenant_id" json:"tenant_id"`
+	DelegatorID   int        `db:"delegator_id" json:"delegator_id"`
+	DelegatorName string     `json:"delegator_name"`
+	DelegateID    int        `db:"delegate_id" json:"delegate_id"`
+	DelegateName  string     `json:"delegate_name"`
+	EntityType    string     `db:"entity_type" json:"entity_type"`
+	StartDate     time.Time  `db:"start_date" json:"start_date"`
+	EndDate       time.Time  `db:"end_date" json:"end_date"`
+	Reason        string     `db:"reason" json:"reason"`
+	CreatedBy     *int       `db:"created_by" json:"created_by"`
+	RevokedAt     *time.Time `db:"revoked_at" json:"revoked_at"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Status is revoked, scheduled, active or expired as of the given date
+func (a *ApprovalDelegation) Status(date time.Time) string {
+	switch {
+	case a.RevokedAt != nil:
+		return "revoked"
+	case date.Before(a.StartDate):
+		return "scheduled"
+	case date.After(a.EndDate):
+		return "expired"
+	default:
+		return "active"
+	}
+}
+
+func (a *ApprovalDelegation) ToResponse(date time.Time) *dto.ApprovalDelegationResponse {
+	return &dto.ApprovalDelegationResponse{
+		ID:            a.ID,
+		DelegatorID:   a.DelegatorID,
+		DelegatorName: a.DelegatorName,
+		DelegateID:    a.DelegateID,
+		DelegateName:  a.DelegateName,
+		EntityType:    a.EntityType,
+		StartDate:     a.StartDate,
+		EndDate:       a.EndDate,
+		Reason:        a.Reason,
+		Status:        a.Status(date),
+		CreatedBy:     a.CreatedBy,
+		RevokedAt:     a.RevokedAt,
+		CreatedAt:     a.CreatedAt,
+		UpdatedAt:     a.UpdatedAt,
+	}
+}
+
+// ApprovalInboxItem is a pending approval of a request's current stage, with
+// what an approver needs to pick it up
+type ApprovalInboxItem struct {
+	ApprovalID        int
+	RequestID         int
+	Version           int
+	EntityType        string
+	EntityID          int
+	RequesterID       int
+	RequesterName     string
+	StepOrder         int
+	StepDescription   string
+	ApprovalMode      string
+	ApproverType      string
+	DelegatedFromID   *int
+	DelegatedFromName string
+	EscalatedFromID   *int
+	EscalateAt        *time.Time
+	AssignedAt        time.Time
+	SubmittedAt       time.Time
+}
+
+// AssignedVia is delegation for an approval passed on by its approver, role
+// for one from a role step and direct otherwise
+func (a *ApprovalInboxItem) AssignedVia() string {
+	switch {
+	case a.DelegatedFromID != nil:
+		return "delegation"
+	case a.ApproverType == "role" && a.EscalatedFromID == nil:
+		return "role"
+	default:
+		return "direct"
+	}
+}
+
+// Overdue reports whether the approval has passed its SLA
+func (a *ApprovalInboxItem) Overdue(now time.Time) bool {
+	return a.EscalateAt != nil && !now.Before(*a.EscalateAt)
+}
+
+func (a *ApprovalInboxItem) ToResponse(now time.Time, details map[string]interface{}) dto.ApprovalInboxItemResponse {
+	return dto.ApprovalInboxItemResponse{
+		ApprovalID:        a.ApprovalID,
+		RequestID:         a.RequestID,
+		Version:           a.Version,
+		EntityType:        a.EntityType,
+		EntityID:          a.EntityID,
+		RequesterID:       a.RequesterID,
+		RequesterName:     a.RequesterName,
+		StepOrder:         a.StepOrder,
+		StepDescription:   a.StepDescription,
+		ApprovalMode:      a.ApprovalMode,
+		AssignedVia:       a.AssignedVia(),
+		DelegatedFromID:   a.DelegatedFromID,
+		DelegatedFromName: a.DelegatedFromName,
+		Escalated:         a.EscalatedFromID != nil,
+		Overdue:           a.Overdue(now),
+		EscalateAt:        a.EscalateAt,
+		AssignedAt:        a.AssignedAt,
+		SubmittedAt:       a.SubmittedAt,
+		Details:           details,
+	}
+}
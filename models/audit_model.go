@@ -0,0 +1,96 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type AuditLog struct {
+	ID         int                    `db:"id" json:"id"`
+	TenantID   *int                   `db:"tenant_id" json:"tenant_id"`
+	ActorID    *int                   `db:"actor_id" json:"actor_id"`
+	ActorName  string                 `json:"actor_name"`
+	EntityType string                 `db:"entity_type" json:"entity_type"`
+	EntityID   int                    `db:"entity_id" json:"entity_id"`
+	Action     string                 `db:"action" json:"action"`
+	OldData    map[string]interface{} `db:"old_data" json:"old_data"`
+	NewData    map[string]interface{} `db:"new_data" json:"new_data"`
+	RequestID  string                 `db:"request_id" json:"request_id"`
+	IPAddress  string                 `db:"ip_address" json:"ip_address"`
+	PrevHash   string                 `db:"prev_hash" json:"prev_hash"`
+	Hash       string                 `db:"hash" json:"hash"`
+	CreatedAt  time.Time              `db:"created_at" json:"created_at"`
+}
+
+// ComputeHash returns the SHA-256 over the row's content and PrevHash, the
+// hash of the tenant's previous row, hex encoded. The content is encoded as
+// JSON with sorted keys, and CreatedAt in UTC, so a row read back from the
+// database hashes the same as when it was written.
+func (a *AuditLog) ComputeHash() (string, error) {
+	tenantID := 0
+	if a.TenantID != nil {
+		tenantID = *a.TenantID
+	}
+	content, err := json.Marshal(struct {
+		TenantID   int                    `json:"tenant_id"`
+		ActorID    *int                   `json:"actor_id"`
+		EntityType string                 `json:"entity_type"`
+		EntityID   int                    `json:"entity_id"`
+		Action     string                 `json:"action"`
+		OldData    map[string]interface{} `json:"old_data"`
+		NewData    map[string]interface{} `json:"new_data"`
+		RequestID  string                 `json:"request_id"`
+		IPAddress  string                 `json:"ip_address"`
+		CreatedAt  string                 `json:"created_at"`
+		PrevHash   string                 `json:"prev_hash"`
+	}{
+		TenantID:   tenantID,
+		ActorID:    a.ActorID,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Action:     a.Action,
+		OldData:    a.OldData,
+		NewData:    a.NewData,
+		RequestID:  a.RequestID,
+		IPAddress:  a.IPAddress,
+		CreatedAt:  a.CreatedAt.UTC().Format(time.RFC3339Nano),
+		PrevHash:   a.PrevHash,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (a *AuditLog) ToResponse() *dto.AuditLogResponse {
+	return &dto.AuditLogResponse{
+		ID:         a.ID,
+		ActorID:    a.ActorID,
+		ActorName:  a.ActorName,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Action:     a.Action,
+		OldData:    a.OldData,
+		NewData:    a.NewData,
+		RequestID:  a.RequestID,
+		IPAddress:  a.IPAddress,
+		CreatedAt:  a.CreatedAt,
+	}
+}
+
+// AuditCheckpoint is a signed record of the head of a tenant's audit chain.
+// TenantID is zero for the chain of rows without a tenant.
+type AuditCheckpoint struct {
+	ID        int       `db:"id" json:"id"`
+	TenantID  int       `db:"tenant_id" json:"tenant_id"`
+	LastLogID int       `db:"last_log_id" json:"last_log_id"`
+	LastHash  string    `db:"last_hash" json:"last_hash"`
+	Signature string    `db:"signature" json:"signature"`
+	PublicKey string    `db:"public_key" json:"public_key"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type CustomFieldDefinition struct {
+	ID         int                  `db:"id" json:"id"`
+	TenantID   int                  `db:"tenant_id" json:"tenant_id"`
+	EntityType string               `db:"entity_type" json:"entity_type"`
+	Key        string               `db:"field_key" json:"key"`
+	Label      string               `db:"label" json:"label"`
+	FieldType  string               `db:"field_type" json:"field_type"`
+	Required   bool                 `db:"required" json:"required"`
+	Options    []string             `db:"options" json:"options"`
+	Rules      dto.CustomFieldRules `db:"rules" json:"rules"`
+	Visibility string               `db:"visibility" json:"visibility"`
+	SortOrder  int                  `db:"sort_order" json:"sort_order"`
+	CreatedAt  time.Time            `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time            `db:"updated_at" json:"updated_at"`
+}
+
+func (c *CustomFieldDefinition) ToResponse() *dto.CustomFieldResponse {
+	return &dto.CustomFieldResponse{
+		ID:         c.ID,
+		Key:        c.Key,
+		Label:      c.Label,
+		FieldType:  c.FieldType,
+		Required:   c.Required,
+		Options:    c.Options,
+		Rules:      c.Rules,
+		Visibility: c.Visibility,
+		SortOrder:  c.SortOrder,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type DisciplinaryCaseEvent struct {
+	ID             int       `db:"id" json:"id"`
+	CaseID         int       `db:"case_id" json:"case_id"`
+	Stage          string    `db:"stage" json:"stage"`
+	Note           string    `db:"note" json:"note"`
+	RecordedBy     *int      `db:"recorded_by" json:"recorded_by"`
+	RecordedByName string    `json:"recorded_by_name"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+func (e *DisciplinaryCaseEvent) ToResponse() dto.DisciplinaryCaseEventResponse {
+	return dto.DisciplinaryCaseEventResponse{
+		ID:             e.ID,
+		Stage:          e.Stage,
+		Note:           e.Note,
+		RecordedBy:     e.RecordedBy,
+		RecordedByName: e.RecordedByName,
+		CreatedAt:      e.CreatedAt,
+	}
+}
+
+type DisciplinaryCase struct {
+	ID              int                     `db:"id" json:"id"`
+	TenantID        int                     `db:"tenant_id" json:"tenant_id"`
+	EmployeeID      int                     `db:"employee_id" json:"employee_id"`
+	EmployeeName    string                  `json:"employee_name"`
+	Title           string                  `db:"title" json:"title"`
+	Description     string                  `db:"description" json:"description"`
+	Stage           string                  `db:"stage" json:"stage"`
+	Status          string                  `db:"status" json:"status"`
+	HearingAt       *time.Time              `db:"hearing_at" json:"hearing_at"`
+	HearingLocation string                  `db:"hearing_location" json:"hearing_location"`
+	Outcome         string                  `db:"outcome" json:"outcome"`
+	OutcomeNotes    string                  `db:"outcome_notes" json:"outcome_notes"`
+	OpenedBy        *int                    `db:"opened_by" json:"opened_by"`
+	ClosedAt        *time.Time              `db:"closed_at" json:"closed_at"`
+	Events          []DisciplinaryCaseEvent `json:"events"`
+	CreatedAt       time.Time               `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time               `db:"updated_at" json:"updated_at"`
+}
+
+func (c *DisciplinaryCase) ToResponse() *dto.DisciplinaryCaseResponse {
+	var events []dto.DisciplinaryCaseEventResponse
+	for i := range c.Events {
+		events = append(events, c.Events[i].ToResponse())
+	}
+	return &dto.DisciplinaryCaseResponse{
+		ID:              c.ID,
+		EmployeeID:      c.EmployeeID,
+		EmployeeName:    c.EmployeeName,
+		Title:           c.Title,
+		Description:     c.Description,
+		Stage:           c.Stage,
+		Status:          c.Status,
+		HearingAt:       c.HearingAt,
+		HearingLocation: c.HearingLocation,
+		Outcome:         c.Outcome,
+		OutcomeNotes:    c.OutcomeNotes,
+		OpenedBy:        c.OpenedBy,
+		ClosedAt:        c.ClosedAt,
+		Events:          events,
+		CreatedAt:       c.CreatedAt,
+		UpdatedAt:       c.UpdatedAt,
+	}
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type DocumentType struct {
+	ID                  int       `db:"id" json:"id"`
+	TenantID            int       `db:"tenant_id" json:"tenant_id"`
+	Name                string    `db:"name" json:"name"`
+	Description         string    `db:"description" json:"description"`
+	AllowedContentTypes []string  `db:"allowed_content_types" json:"allowed_content_types"`
+	MaxSizeBytes        int64     `db:"max_size_bytes" json:"max_size_bytes"`
+	ExpiryReminderDays  int       `db:"expiry_reminder_days" json:"expiry_reminder_days"`
+	Status              string    `db:"status" json:"status"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (d *DocumentType) ToResponse() *dto.DocumentTypeResponse {
+	return &dto.DocumentTypeResponse{
+		ID:                  d.ID,
+		Name:                d.Name,
+		Description:         d.Description,
+		AllowedContentTypes: d.AllowedContentTypes,
+		MaxSizeBytes:        d.MaxSizeBytes,
+		ExpiryReminderDays:  d.ExpiryReminderDays,
+		Status:              d.Status,
+		CreatedAt:           d.CreatedAt,
+		UpdatedAt:           d.UpdatedAt,
+	}
+}
+
+// Document is an uploaded file. EmployeeID is nil for company documents and
+// StorageKey locates the file of the current version in document storage.
+// ExpiryReminderDays comes from the document type.
+type Document struct {
+	ID                 int        `db:"id" json:"id"`
+	TenantID           int        `db:"tenant_id" json:"tenant_id"`
+	EmployeeID         *int       `db:"employee_id" json:"employee_id"`
+	EmployeeName       string     `json:"employee_name"`
+	DocumentTypeID     *int       `db:"document_type_id" json:"document_type_id"`
+	DocumentType       string     `db:"document_type" json:"document_type"`
+	CurrentVersion     int        `db:"current_version" json:"current_version"`
+	StorageKey         string     `db:"file_path" json:"-"`
+	FileName           string     `db:"file_name" json:"file_name"`
+	ContentType        string     `db:"content_type" json:"content_type"`
+	SizeBytes          int64      `db:"size_bytes" json:"size_bytes"`
+	Checksum           string     `db:"checksum" json:"checksum"`
+	IssueDate          *time.Time `db:"issue_date" json:"issue_date"`
+	ExpiryDate         *time.Time `db:"expiry_date" json:"expiry_date"`
+	ExpiryReminderDays int        `json:"expiry_reminder_days"`
+	UploadedBy         *int       `db:"uploaded_by" json:"uploaded_by"`
+	UploadedByName     string     `json:"uploaded_by_name"`
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (d *Document) ToResponse() *dto.DocumentResponse {
+	return &dto.DocumentResponse{
+		ID:             d.ID,
+		EmployeeID:     d.EmployeeID,
+		EmployeeName:   d.EmployeeName,
+		DocumentTypeID: d.DocumentTypeID,
+		DocumentType:   d.DocumentType,
+		Version:        d.CurrentVersion,
+		IssueDate:      d.IssueDate,
+		ExpiryDate:     d.ExpiryDate,
+		FileName:       d.FileName,
+		ContentType:    d.ContentType,
+		SizeBytes:      d.SizeBytes,
+		Checksum:       d.Checksum,
+		UploadedBy:     d.UploadedBy,
+		UploadedByName: d.UploadedByName,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+// DocumentVersion is one upload of a document
+type DocumentVersion struct {
+	ID             int        `db:"id" json:"id"`
+	TenantID       int        `db:"tenant_id" json:"tenant_id"`
+	DocumentID     int        `db:"document_id" json:"document_id"`
+	Version        int        `db:"version" json:"version"`
+	StorageKey     string     `db:"file_path" json:"-"`
+	FileName       string     `db:"file_name" json:"file_name"`
+	ContentType    string     `db:"content_type" json:"content_type"`
+	SizeBytes      int64      `db:"size_bytes" json:"size_bytes"`
+	Checksum       string     `db:"checksum" json:"checksum"`
+	IssueDate      *time.Time `db:"issue_date" json:"issue_date"`
+	ExpiryDate     *time.Time `db:"expiry_date" json:"expiry_date"`
+	UploadedBy     *int       `db:"uploaded_by" json:"uploaded_by"`
+	UploadedByName string     `json:"uploaded_by_name"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (v *DocumentVersion) ToResponse() *dto.DocumentVersionResponse {
+	return &dto.DocumentVersionResponse{
+		Version:        v.Version,
+		FileName:       v.FileName,
+		ContentType:    v.ContentType,
+		SizeBytes:      v.SizeBytes,
+		Checksum:       v.Checksum,
+		IssueDate:      v.IssueDate,
+		ExpiryDate:     v.ExpiryDate,
+		UploadedBy:     v.UploadedBy,
+		UploadedByName: v.UploadedByName,
+		CreatedAt:      v.CreatedAt,
+	}
+}
+
+// DocumentRequirement makes a document type mandatory for the employees of
+// DesignationID in Country; nil and empty match every designation and
+// country
+type DocumentRequirement struct {
+	ID              int       `db:"id" json:"id"`
+	TenantID        int       `db:"tenant_id" json:"tenant_id"`
+	DocumentTypeID  int       `db:"document_type_id" json:"document_type_id"`
+	DocumentType    string    `json:"document_type"`
+	DesignationID   *int      `db:"designation_id" json:"designation_id"`
+	DesignationName string    `json:"designation_name"`
+	Country         string    `db:"country" json:"country"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+}
+
+func (r *DocumentRequirement) ToResponse() *dto.DocumentRequirementResponse {
+	return &dto.DocumentRequirementResponse{
+		ID:              r.ID,
+		DocumentTypeID:  r.DocumentTypeID,
+		DocumentType:    r.DocumentType,
+		DesignationID:   r.DesignationID,
+		DesignationName: r.DesignationName,
+		Country:         r.Country,
+		CreatedAt:       r.CreatedAt,
+	}
+}
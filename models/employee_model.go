@@ -1,20 +1,54 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
 
 type Employee struct {
-	ID            int        `db:"id" json:"id"`
-	TenantID      int        `db:"tenant_id" json:"tenant_id"`
-	FirstName     string     `db:"first_name" json:"first_name"`
-	LastName      string     `db:"last_name" json:"last_name"`
-	Email         string     `db:"email" json:"email"`
-	Phone         string     `db:"phone" json:"phone"`
-	DepartmentID  int        `db:"department_id" json:"department_id"`
-	DesignationID int        `db:"designation_id" json:"designation_id"`
-	ManagerID     *int       `db:"manager_id" json:"manager_id"`
-	Status        string     `db:"status" json:"status"`
-	HireDate      *time.Time `db:"hire_date" json:"hire_date"`
-	PasswordHash  string     `db:"password_hash" json:"password_hash,omitempty"`
-	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+	ID             int                    `db:"id" json:"id"`
+	TenantID       int                    `db:"tenant_id" json:"tenant_id"`
+	FirstName      string                 `db:"first_name" json:"first_name"`
+	LastName       string                 `db:"last_name" json:"last_name"`
+	Email          string                 `db:"email" json:"email"`
+	Phone          string                 `db:"phone" json:"phone"`
+	DepartmentID   int                    `db:"department_id" json:"department_id"`
+	DesignationID  int                    `db:"designation_id" json:"designation_id"`
+	ManagerID      *int                   `db:"manager_id" json:"manager_id"`
+	Status         string                 `db:"status" json:"status"`
+	HireDate       *time.Time             `db:"hire_date" json:"hire_date"`
+	EmploymentType string                 `db:"employment_type" json:"employment_type"`
+	Salary         *float64               `db:"salary" json:"-"`
+	SalaryCurrency string                 `db:"salary_currency" json:"-"`
+	PasswordHash   string                 `db:"password_hash" json:"password_hash,omitempty"`
+	MFASecret      *string                `db:"mfa_secret" json:"-"`
+	MFAEnabled     bool                   `db:"mfa_enabled" json:"mfa_enabled"`
+	CustomFields   map[string]interface{} `db:"custom_fields" json:"custom_fields"`
+	CreatedAt      time.Time              `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time              `db:"updated_at" json:"updated_at"`
+}
+
+func (e *Employee) ToSummaryResponse() *dto.EmployeeSummaryResponse {
+	return &dto.EmployeeSummaryResponse{
+		ID:            e.ID,
+		FirstName:     e.FirstName,
+		LastName:      e.LastName,
+		Email:         e.Email,
+		Phone:         e.Phone,
+		DepartmentID:  e.DepartmentID,
+		DesignationID: e.DesignationID,
+		ManagerID:     e.ManagerID,
+		Status:        e.Status,
+		HireDate:      e.HireDate,
+	}
+}
+
+// EmployeeExportRow is an employee joined with the names of its related records
+type EmployeeExportRow struct {
+	Employee
+	DepartmentName  string `db:"department_name" json:"department_name"`
+	DesignationName string `db:"designation_name" json:"designation_name"`
+	ManagerName     string `db:"manager_name" json:"manager_name"`
+	RoleName        string `db:"role_name" json:"role_name"`
 }
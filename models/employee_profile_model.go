@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type EmployeePersonalDetails struct {
+	ID            int        `db:"id" json:"id"`
+	TenantID      int        `db:"tenant_id" json:"tenant_id"`
+	EmployeeID    int        `db:"employee_id" json:"employee_id"`
+	DateOfBirth   *time.Time `db:"date_of_birth" json:"date_of_birth"`
+	Gender        string     `db:"gender" json:"gender"`
+	Nationality   string     `db:"nationality" json:"nationality"`
+	MaritalStatus string     `db:"marital_status" json:"marital_status"`
+	NationalID    string     `db:"national_id" json:"national_id"`
+	TaxNumber     string     `db:"tax_number" json:"tax_number"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (p *EmployeePersonalDetails) ToResponse() *dto.PersonalDetailsResponse {
+	return &dto.PersonalDetailsResponse{
+		EmployeeID:    p.EmployeeID,
+		DateOfBirth:   p.DateOfBirth,
+		Gender:        p.Gender,
+		Nationality:   p.Nationality,
+		MaritalStatus: p.MaritalStatus,
+		NationalID:    p.NationalID,
+		TaxNumber:     p.TaxNumber,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+type EmployeeAddress struct {
+	ID          int        `db:"id" json:"id"`
+	TenantID    int        `db:"tenant_id" json:"tenant_id"`
+	EmployeeID  int        `db:"employee_id" json:"employee_id"`
+	AddressType string     `db:"address_type" json:"address_type"`
+	Line1       string     `db:"line1" json:"line1"`
+	Line2       string     `db:"line2" json:"line2"`
+	City        string     `db:"city" json:"city"`
+	State       string     `db:"state" json:"state"`
+	PostalCode  string     `db:"postal_code" json:"postal_code"`
+	Country     string     `db:"country" json:"country"`
+	ValidFrom   time.Time  `db:"valid_from" json:"valid_from"`
+	ValidTo     *time.Time `db:"valid_to" json:"valid_to"`
+	IsCurrent   bool       `db:"is_current" json:"is_current"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (a *EmployeeAddress) ToResponse() *dto.AddressResponse {
+	return &dto.AddressResponse{
+		ID:          a.ID,
+		EmployeeID:  a.EmployeeID,
+		AddressType: a.AddressType,
+		Line1:       a.Line1,
+		Line2:       a.Line2,
+		City:        a.City,
+		State:       a.State,
+		PostalCode:  a.PostalCode,
+		Country:     a.Country,
+		ValidFrom:   a.ValidFrom,
+		ValidTo:     a.ValidTo,
+		IsCurrent:   a.IsCurrent,
+	}
+}
+
+type EmployeeEmergencyContact struct {
+	ID           int       `db:"id" json:"id"`
+	TenantID     int       `db:"tenant_id" json:"tenant_id"`
+	EmployeeID   int       `db:"employee_id" json:"employee_id"`
+	Name         string    `db:"name" json:"name"`
+	Relationship string    `db:"relationship" json:"relationship"`
+	Phone        string    `db:"phone" json:"phone"`
+	Email        string    `db:"email" json:"email"`
+	IsPrimary    bool      `db:"is_primary" json:"is_primary"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (c *EmployeeEmergencyContact) ToResponse() *dto.EmergencyContactResponse {
+	return &dto.EmergencyContactResponse{
+		ID:           c.ID,
+		EmployeeID:   c.EmployeeID,
+		Name:         c.Name,
+		Relationship: c.Relationship,
+		Phone:        c.Phone,
+		Email:        c.Email,
+		IsPrimary:    c.IsPrimary,
+	}
+}
+
+type EmployeeBankAccount struct {
+	ID            int       `db:"id" json:"id"`
+	TenantID      int       `db:"tenant_id" json:"tenant_id"`
+	EmployeeID    int       `db:"employee_id" json:"employee_id"`
+	BankName      string    `db:"bank_name" json:"bank_name"`
+	AccountName   string    `db:"account_name" json:"account_name"`
+	AccountNumber string    `db:"account_number" json:"account_number"`
+	BankCode      string    `db:"bank_code" json:"bank_code"`
+	IBAN          string    `db:"iban" json:"iban"`
+	Currency      string    `db:"currency" json:"currency"`
+	IsPrimary     bool      `db:"is_primary" json:"is_primary"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (b *EmployeeBankAccount) ToResponse() *dto.BankAccountResponse {
+	return &dto.BankAccountResponse{
+		ID:            b.ID,
+		EmployeeID:    b.EmployeeID,
+		BankName:      b.BankName,
+		AccountName:   b.AccountName,
+		AccountNumber: b.AccountNumber,
+		BankCode:      b.BankCode,
+		IBAN:          b.IBAN,
+		Currency:      b.Currency,
+		IsPrimary:     b.IsPrimary,
+		UpdatedAt:     b.UpdatedAt,
+	}
+}
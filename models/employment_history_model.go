@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+// EmploymentHistory is one effective-dated change to an employee's job.
+// ChangedFields names the columns the record sets; a listed column holding
+// NULL (e.g. manager_id) clears the value.
+type EmploymentHistory struct {
+	ID             int        `db:"id" json:"id"`
+	TenantID       int        `db:"tenant_id" json:"tenant_id"`
+	EmployeeID     int        `db:"employee_id" json:"employee_id"`
+	ChangeType     string     `db:"change_type" json:"change_type"`
+	EffectiveDate  time.Time  `db:"effective_date" json:"effective_date"`
+	ChangedFields  []string   `db:"changed_fields" json:"changed_fields"`
+	DepartmentID   *int       `db:"department_id" json:"department_id"`
+	DesignationID  *int       `db:"designation_id" json:"designation_id"`
+	ManagerID      *int       `db:"manager_id" json:"manager_id"`
+	EmploymentType string     `db:"employment_type" json:"employment_type"`
+	Salary         *float64   `db:"salary" json:"salary"`
+	SalaryCurrency string     `db:"salary_currency" json:"salary_currency"`
+	Reason         string     `db:"reason" json:"reason"`
+	Status         string     `db:"status" json:"status"`
+	CreatedBy      *int       `db:"created_by" json:"created_by"`
+	AppliedAt      *time.Time `db:"applied_at" json:"applied_at"`
+	CancelledAt    *time.Time `db:"cancelled_at" json:"cancelled_at"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (h *EmploymentHistory) ToResponse() *dto.EmploymentHistoryResponse {
+	return &dto.EmploymentHistoryResponse{
+		ID:             h.ID,
+		EmployeeID:     h.EmployeeID,
+		ChangeType:     h.ChangeType,
+		EffectiveDate:  h.EffectiveDate,
+		ChangedFields:  h.ChangedFields,
+		DepartmentID:   h.DepartmentID,
+		DesignationID:  h.DesignationID,
+		ManagerID:      h.ManagerID,
+		EmploymentType: h.EmploymentType,
+		Salary:         h.Salary,
+		SalaryCurrency: h.SalaryCurrency,
+		Reason:         h.Reason,
+		Status:         h.Status,
+		CreatedBy:      h.CreatedBy,
+		AppliedAt:      h.AppliedAt,
+		CreatedAt:      h.CreatedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type ExportJob struct {
+	ID          int        `db:"id" json:"id"`
+	TenantID    int        `db:"tenant_id" json:"tenant_id"`
+	RequestedBy *int       `db:"requested_by" json:"requested_by"`
+	EntityType  string     `db:"entity_type" json:"entity_type"`
+	Format      string     `db:"format" json:"format"`
+	Columns     string     `db:"columns" json:"columns"`
+	Filters     []byte     `db:"filters" json:"filters"`
+	Status      string     `db:"status" json:"status"`
+	FilePath    *string    `db:"file_path" json:"file_path"`
+	FileName    *string    `db:"file_name" json:"file_name"`
+	RowCount    int        `db:"row_count" json:"row_count"`
+	Error       *string    `db:"error" json:"error"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (j *ExportJob) ToResponse() *dto.ExportJobResponse {
+	response := &dto.ExportJobResponse{
+		ID:          j.ID,
+		EntityType:  j.EntityType,
+		Format:      j.Format,
+		Status:      j.Status,
+		RowCount:    j.RowCount,
+		CompletedAt: j.CompletedAt,
+		CreatedAt:   j.CreatedAt,
+	}
+	if j.Columns != "" {
+		response.Columns = strings.Split(j.Columns, ",")
+	}
+	if j.FileName != nil {
+		response.FileName = *j.FileName
+	}
+	if j.Error != nil {
+		response.Error = *j.Error
+	}
+	return response
+}
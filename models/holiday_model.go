@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+// Holiday is a day off for the tenant, or for one of its locations when
+// Location is set
+type Holiday struct {
+	ID        int       `db:"id" json:"id"`
+	TenantID  int       `db:"tenant_id" json:"tenant_id"`
+	Date      time.Time `db:"holiday_date" json:"date"`
+	Name      string    `db:"name" json:"name"`
+	Location  string    `db:"location" json:"location"`
+	IsDayOff  bool      `db:"is_day_off" json:"is_day_off"`
+	Source    string    `db:"source" json:"source"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (h *Holiday) ToResponse() *dto.HolidayResponse {
+	return &dto.HolidayResponse{
+		ID:        h.ID,
+		Date:      h.Date,
+		Name:      h.Name,
+		Location:  h.Location,
+		IsDayOff:  h.IsDayOff,
+		Source:    h.Source,
+		CreatedAt: h.CreatedAt,
+		UpdatedAt: h.UpdatedAt,
+	}
+}
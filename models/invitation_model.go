@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type EmployeeInvitation struct {
+	ID         int        `db:"id" json:"id"`
+	TenantID   int        `db:"tenant_id" json:"tenant_id"`
+	EmployeeID int        `db:"employee_id" json:"employee_id"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	Status     string     `db:"status" json:"status"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	SentCount  int        `db:"sent_count" json:"sent_count"`
+	LastSentAt *time.Time `db:"last_sent_at" json:"last_sent_at"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"accepted_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+	InvitedBy  *int       `db:"invited_by" json:"invited_by"`
+	MFASecret  *string    `db:"mfa_secret" json:"-"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (i *EmployeeInvitation) ToResponse() *dto.InvitationResponse {
+	return &dto.InvitationResponse{
+		ID:         i.ID,
+		EmployeeID: i.EmployeeID,
+		Status:     i.Status,
+		ExpiresAt:  i.ExpiresAt,
+		SentCount:  i.SentCount,
+		LastSentAt: i.LastSentAt,
+		AcceptedAt: i.AcceptedAt,
+		RevokedAt:  i.RevokedAt,
+		CreatedAt:  i.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type LeaveAccrualPolicy struct {
+	ID                    int                `db:"id" json:"id"`
+	TenantID              int                `db:"tenant_id" json:"tenant_id"`
+	LeaveTypeID           int                `db:"leave_type_id" json:"leave_type_id"`
+	Frequency             string             `db:"frequency" json:"frequency"`
+	PayPeriodsPerYear     int                `db:"pay_periods_per_year" json:"pay_periods_per_year"`
+	ProrateFirstYear      bool               `db:"prorate_first_year" json:"prorate_first_year"`
+	CarryOverMaxDays      *float64           `db:"carry_over_max_days" json:"carry_over_max_days"`
+	CarryOverExpiryMonths *int               `db:"carry_over_expiry_months" json:"carry_over_expiry_months"`
+	Tiers                 []LeaveAccrualTier `json:"tiers"`
+	CreatedAt             time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt             time.Time          `db:"updated_at" json:"updated_at"`
+}
+
+// LeaveAccrualTier is the yearly entitlement from MinTenureMonths of service
+type LeaveAccrualTier struct {
+	MinTenureMonths int     `db:"min_tenure_months" json:"min_tenure_months"`
+	DaysPerYear     float64 `db:"days_per_year" json:"days_per_year"`
+}
+
+func (l *LeaveAccrualPolicy) ToResponse() *dto.LeaveAccrualPolicyResponse {
+	tiers := make([]dto.LeaveAccrualTier, len(l.Tiers))
+	for i, tier := range l.Tiers {
+		tiers[i] = dto.LeaveAccrualTier{
+			MinTenureMonths: tier.MinTenureMonths,
+			DaysPerYear:     tier.DaysPerYear,
+		}
+	}
+
+	return &dto.LeaveAccrualPolicyResponse{
+		ID:                    l.ID,
+		LeaveTypeID:           l.LeaveTypeID,
+		Frequency:             l.Frequency,
+		PayPeriodsPerYear:     l.PayPeriodsPerYear,
+		ProrateFirstYear:      l.ProrateFirstYear,
+		CarryOverMaxDays:      l.CarryOverMaxDays,
+		CarryOverExpiryMonths: l.CarryOverExpiryMonths,
+		Tiers:                 tiers,
+		CreatedAt:             l.CreatedAt,
+		UpdatedAt:             l.UpdatedAt,
+	}
+}
+
+// LeaveLedgerEntry is one change to an employee's balance of a leave type for
+// a leave year. Days are negative when the balance goes down.
+type LeaveLedgerEntry struct {
+	ID             int       `db:"id" json:"id"`
+	TenantID       int       `db:"tenant_id" json:"tenant_id"`
+	EmployeeID     int       `db:"employee_id" json:"employee_id"`
+	LeaveTypeID    int       `db:"leave_type_id" json:"leave_type_id"`
+	Year           int       `db:"year" json:"year"`
+	EntryType      string    `db:"entry_type" json:"entry_type"`
+	Days           float64   `db:"days" json:"days"`
+	PeriodKey      string    `db:"period_key" json:"period_key"`
+	LeaveRequestID *int      `db:"leave_request_id" json:"leave_request_id"`
+	Note           string    `db:"note" json:"note"`
+	CreatedBy      *int      `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+func (l *LeaveLedgerEntry) ToResponse() *dto.LeaveLedgerEntryResponse {
+	return &dto.LeaveLedgerEntryResponse{
+		ID:             l.ID,
+		LeaveTypeID:    l.LeaveTypeID,
+		Year:           l.Year,
+		EntryType:      l.EntryType,
+		Days:           l.Days,
+		PeriodKey:      l.PeriodKey,
+		LeaveRequestID: l.LeaveRequestID,
+		Note:           l.Note,
+		CreatedBy:      l.CreatedBy,
+		CreatedAt:      l.CreatedAt,
+	}
+}
+
+type LeaveBalance struct {
+	EmployeeID    int     `db:"employee_id" json:"employee_id"`
+	LeaveTypeID   int     `db:"leave_type_id" json:"leave_type_id"`
+	LeaveTypeName string  `json:"leave_type"`
+	Year          int     `db:"year" json:"year"`
+	BalanceDays   float64 `db:"balance_days" json:"balance_days"`
+	PendingDays   float64 `json:"pending_days"`
+}
+
+func (l *LeaveBalance) ToResponse() *dto.LeaveBalanceResponse {
+	return &dto.LeaveBalanceResponse{
+		LeaveTypeID:   l.LeaveTypeID,
+		LeaveType:     l.LeaveTypeName,
+		Year:          l.Year,
+		BalanceDays:   l.BalanceDays,
+		PendingDays:   l.PendingDays,
+		AvailableDays: l.BalanceDays - l.PendingDays,
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+// LeaveCalendarEntry is an approved or pending leave request as shown on the
+// team calendar
+type LeaveCalendarEntry struct {
+	LeaveRequestID int       `db:"id" json:"leave_request_id"`
+	EmployeeID     int       `db:"employee_id" json:"employee_id"`
+	EmployeeName   string    `json:"employee_name"`
+	DepartmentID   int       `db:"department_id" json:"department_id"`
+	LeaveTypeName  string    `json:"leave_type"`
+	StartDate      time.Time `db:"start_date" json:"start_date"`
+	EndDate        time.Time `db:"end_date" json:"end_date"`
+	DaysRequested  float64   `db:"days_requested" json:"days_requested"`
+	HalfDay        bool      `db:"half_day" json:"half_day"`
+	Status         string    `db:"status" json:"status"`
+}
+
+func (l *LeaveCalendarEntry) ToResponse() *dto.LeaveCalendarEntryResponse {
+	return &dto.LeaveCalendarEntryResponse{
+		LeaveRequestID: l.LeaveRequestID,
+		EmployeeID:     l.EmployeeID,
+		EmployeeName:   l.EmployeeName,
+		DepartmentID:   l.DepartmentID,
+		LeaveType:      l.LeaveTypeName,
+		StartDate:      l.StartDate,
+		EndDate:        l.EndDate,
+		DaysRequested:  l.DaysRequested,
+		HalfDay:        l.HalfDay,
+		Status:         l.Status,
+	}
+}
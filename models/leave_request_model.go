@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type LeaveRequest struct {
+	ID                 int        `db:"id" json:"id"`
+	TenantID           int        `db:"tenant_id" json:"tenant_id"`
+	EmployeeID         int        `db:"employee_id" json:"employee_id"`
+	EmployeeName       string     `json:"employee_name"`
+	LeaveTypeID        int        `db:"leave_type_id" json:"leave_type_id"`
+	LeaveTypeName      string     `json:"leave_type"`
+	StartDate          time.Time  `db:"start_date" json:"start_date"`
+	EndDate            time.Time  `db:"end_date" json:"end_date"`
+	LeaveYear          int        `db:"leave_year" json:"leave_year"`
+	DaysRequested      float64    `db:"days_requested" json:"days_requested"`
+	HalfDay            bool       `db:"half_day" json:"half_day"`
+	Reason             string     `db:"reason" json:"reason"`
+	AttachmentURL      string     `db:"attachment_url" json:"attachment_url"`
+	Status             string     `db:"status" json:"status"`
+	ApprovalWorkflowID *int       `db:"approval_workflow_id" json:"approval_workflow_id"`
+	DeductedDays       float64    `db:"deducted_days" json:"deducted_days"`
+	DecidedBy          *int       `db:"decided_by" json:"decided_by"`
+	DecidedAt          *time.Time `db:"decided_at" json:"decided_at"`
+	DecisionComment    string     `db:"decision_comment" json:"decision_comment"`
+	CancelledBy        *int       `db:"cancelled_by" json:"cancelled_by"`
+	CancelledAt        *time.Time `db:"cancelled_at" json:"cancelled_at"`
+	CancellationReason string     `db:"cancellation_reason" json:"cancellation_reason"`
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (l *LeaveRequest) ToResponse() *dto.LeaveRequestResponse {
+	return &dto.LeaveRequestResponse{
+		ID:                 l.ID,
+		EmployeeID:         l.EmployeeID,
+		EmployeeName:       l.EmployeeName,
+		LeaveTypeID:        l.LeaveTypeID,
+		LeaveType:          l.LeaveTypeName,
+		StartDate:          l.StartDate,
+		EndDate:            l.EndDate,
+		LeaveYear:          l.LeaveYear,
+		DaysRequested:      l.DaysRequested,
+		HalfDay:            l.HalfDay,
+		Reason:             l.Reason,
+		AttachmentURL:      l.AttachmentURL,
+		Status:             l.Status,
+		ApprovalWorkflowID: l.ApprovalWorkflowID,
+		DecidedBy:          l.DecidedBy,
+		DecidedAt:          l.DecidedAt,
+		DecisionComment:    l.DecisionComment,
+		CancelledBy:        l.CancelledBy,
+		CancelledAt:        l.CancelledAt,
+		CancellationReason: l.CancellationReason,
+		CreatedAt:          l.CreatedAt,
+		UpdatedAt:          l.UpdatedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type LeaveType struct {
+	ID                 int       `db:"id" json:"id"`
+	TenantID           int       `db:"tenant_id" json:"tenant_id"`
+	Name               string    `db:"name" json:"name"`
+	Description        string    `db:"description" json:"description"`
+	DaysPerYear        int       `db:"days_per_year" json:"days_per_year"`
+	IsPaid             bool      `db:"is_paid" json:"is_paid"`
+	RequiresAttachment bool      `db:"requires_attachment" json:"requires_attachment"`
+	MinNoticeDays      int       `db:"min_notice_days" json:"min_notice_days"`
+	MaxConsecutiveDays *int      `db:"max_consecutive_days" json:"max_consecutive_days"`
+	EligibleGender     string    `db:"eligible_gender" json:"eligible_gender"`
+	MinTenureMonths    int       `db:"min_tenure_months" json:"min_tenure_months"`
+	AllowHalfDay       bool      `db:"allow_half_day" json:"allow_half_day"`
+	Status             string    `db:"status" json:"status"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (l *LeaveType) ToResponse() *dto.LeaveTypeResponse {
+	return &dto.LeaveTypeResponse{
+		ID:                 l.ID,
+		Name:               l.Name,
+		Description:        l.Description,
+		DaysPerYear:        l.DaysPerYear,
+		IsPaid:             l.IsPaid,
+		RequiresAttachment: l.RequiresAttachment,
+		MinNoticeDays:      l.MinNoticeDays,
+		MaxConsecutiveDays: l.MaxConsecutiveDays,
+		EligibleGender:     l.EligibleGender,
+		MinTenureMonths:    l.MinTenureMonths,
+		AllowHalfDay:       l.AllowHalfDay,
+		Status:             l.Status,
+		CreatedAt:          l.CreatedAt,
+		UpdatedAt:          l.UpdatedAt,
+	}
+}
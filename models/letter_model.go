@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+// LetterTemplate is the text of a generated letter. Title and Body may hold
+// {{placeholder}} fields.
+type LetterTemplate struct {
+	ID             int       `db:"id" json:"id"`
+	TenantID       int       `db:"tenant_id" json:"tenant_id"`
+	Name           string    `db:"name" json:"name"`
+	Category       string    `db:"category" json:"category"`
+	DocumentTypeID *int      `db:"document_type_id" json:"document_type_id"`
+	DocumentType   string    `json:"document_type"`
+	Title          string    `db:"title" json:"title"`
+	Body           string    `db:"body" json:"body"`
+	Status         string    `db:"status" json:"status"`
+	CreatedBy      *int      `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (l *LetterTemplate) ToResponse() *dto.LetterTemplateResponse {
+	return &dto.LetterTemplateResponse{
+		ID:             l.ID,
+		Name:           l.Name,
+		Category:       l.Category,
+		DocumentTypeID: l.DocumentTypeID,
+		DocumentType:   l.DocumentType,
+		Title:          l.Title,
+		Body:           l.Body,
+		Status:         l.Status,
+		CreatedBy:      l.CreatedBy,
+		CreatedAt:      l.CreatedAt,
+		UpdatedAt:      l.UpdatedAt,
+	}
+}
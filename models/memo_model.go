@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type MemoType struct {
+	ID                      int       `db:"id" json:"id"`
+	TenantID                int       `db:"tenant_id" json:"tenant_id"`
+	Name                    string    `db:"name" json:"name"`
+	Description             string    `db:"description" json:"description"`
+	Category                string    `db:"category" json:"category"`
+	IsDisciplinary          bool      `db:"is_disciplinary" json:"is_disciplinary"`
+	RequiresAcknowledgement bool      `db:"requires_acknowledgement" json:"requires_acknowledgement"`
+	ValidityMonths          *int      `db:"validity_months" json:"validity_months"`
+	Status                  string    `db:"status" json:"status"`
+	CreatedAt               time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt               time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (m *MemoType) ToResponse() *dto.MemoTypeResponse {
+	return &dto.MemoTypeResponse{
+		ID:                      m.ID,
+		Name:                    m.Name,
+		Description:             m.Description,
+		Category:                m.Category,
+		IsDisciplinary:          m.IsDisciplinary,
+		RequiresAcknowledgement: m.RequiresAcknowledgement,
+		ValidityMonths:          m.ValidityMonths,
+		Status:                  m.Status,
+		CreatedAt:               m.CreatedAt,
+		UpdatedAt:               m.UpdatedAt,
+	}
+}
+
+// Memo is a memo about EmployeeID. The category, disciplinary and
+// acknowledgement fields come from its memo type.
+type Memo struct {
+	ID                      int        `db:"id" json:"id"`
+	TenantID                int        `db:"tenant_id" json:"tenant_id"`
+	EmployeeID              int        `db:"employee_id" json:"employee_id"`
+	EmployeeName            string     `json:"employee_name"`
+	AuthorID                *int       `db:"author_id" json:"author_id"`
+	AuthorName              string     `json:"author_name"`
+	MemoTypeID              int        `db:"memo_type_id" json:"memo_type_id"`
+	MemoTypeName            string     `json:"memo_type"`
+	Category                string     `json:"category"`
+	IsDisciplinary          bool       `json:"is_disciplinary"`
+	RequiresAcknowledgement bool       `json:"requires_acknowledgement"`
+	Title                   string     `db:"title" json:"title"`
+	Description             string     `db:"description" json:"description"`
+	Status                  string     `db:"status" json:"status"`
+	ApprovalWorkflowID      *int       `db:"approval_workflow_id" json:"approval_workflow_id"`
+	DecidedBy               *int       `db:"decided_by" json:"decided_by"`
+	DecidedAt               *time.Time `db:"decided_at" json:"decided_at"`
+	DecisionComment         string     `db:"decision_comment" json:"decision_comment"`
+	CancelledBy             *int       `db:"cancelled_by" json:"cancelled_by"`
+	CancelledAt             *time.Time `db:"cancelled_at" json:"cancelled_at"`
+	AcknowledgedAt          *time.Time `db:"acknowledged_at" json:"acknowledged_at"`
+	AcknowledgementComment  string     `db:"acknowledgement_comment" json:"acknowledgement_comment"`
+	ExpiresAt               *time.Time `db:"expires_at" json:"expires_at"`
+	CaseID                  *int       `db:"case_id" json:"case_id"`
+	CreatedAt               time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt               time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (m *Memo) ToResponse() *dto.MemoResponse {
+	return &dto.MemoResponse{
+		ID:                      m.ID,
+		EmployeeID:              m.EmployeeID,
+		EmployeeName:            m.EmployeeName,
+		AuthorID:                m.AuthorID,
+		AuthorName:              m.AuthorName,
+		MemoTypeID:              m.MemoTypeID,
+		MemoType:                m.MemoTypeName,
+		Category:                m.Category,
+		IsDisciplinary:          m.IsDisciplinary,
+		Title:                   m.Title,
+		Description:             m.Description,
+		Status:                  m.Status,
+		ApprovalWorkflowID:      m.ApprovalWorkflowID,
+		DecidedBy:               m.DecidedBy,
+		DecidedAt:               m.DecidedAt,
+		DecisionComment:         m.DecisionComment,
+		CancelledBy:             m.CancelledBy,
+		CancelledAt:             m.CancelledAt,
+		RequiresAcknowledgement: m.RequiresAcknowledgement,
+		AcknowledgedAt:          m.AcknowledgedAt,
+		AcknowledgementComment:  m.AcknowledgementComment,
+		ExpiresAt:               m.ExpiresAt,
+		CaseID:                  m.CaseID,
+		CreatedAt:               m.CreatedAt,
+		UpdatedAt:               m.UpdatedAt,
+	}
+}
+
+// ActiveWarning reports whether the memo is an issued warning that has not
+// lapsed at now
+func (m *Memo) ActiveWarning(now time.Time) bool {
+	return m.Category == "warning" && m.Status == "approved" && (m.ExpiresAt == nil || m.ExpiresAt.After(now))
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type OffboardingChecklistItem struct {
+	ID                 int       `db:"id" json:"id"`
+	TenantID           int       `db:"tenant_id" json:"tenant_id"`
+	Title              string    `db:"title" json:"title"`
+	Description        string    `db:"description" json:"description"`
+	AssigneeType       string    `db:"assignee_type" json:"assignee_type"`
+	AssigneeEmployeeID *int      `db:"assignee_employee_id" json:"assignee_employee_id"`
+	DueOffsetDays      int       `db:"due_offset_days" json:"due_offset_days"`
+	SortOrder          int       `db:"sort_order" json:"sort_order"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (o *OffboardingChecklistItem) ToResponse() *dto.OffboardingChecklistItemResponse {
+	return &dto.OffboardingChecklistItemResponse{
+		ID:                 o.ID,
+		Title:              o.Title,
+		Description:        o.Description,
+		AssigneeType:       o.AssigneeType,
+		AssigneeEmployeeID: o.AssigneeEmployeeID,
+		DueOffsetDays:      o.DueOffsetDays,
+		SortOrder:          o.SortOrder,
+		CreatedAt:          o.CreatedAt,
+		UpdatedAt:          o.UpdatedAt,
+	}
+}
+
+// Offboarding tracks one employee's exit. The leave settlement is frozen onto
+// the row when the offboarding completes.
+type Offboarding struct {
+	ID                    int                          `db:"id" json:"id"`
+	TenantID              int                          `db:"tenant_id" json:"tenant_id"`
+	EmployeeID            int                          `db:"employee_id" json:"employee_id"`
+	LastWorkingDay        time.Time                    `db:"last_working_day" json:"last_working_day"`
+	Reason                string                       `db:"reason" json:"reason"`
+	SuccessorID           int                          `db:"successor_id" json:"successor_id"`
+	Status                string                       `db:"status" json:"status"`
+	ReassignedReports     int                          `db:"reassigned_reports" json:"reassigned_reports"`
+	ReassignedApprovals   int                          `db:"reassigned_approvals" json:"reassigned_approvals"`
+	LeaveSettlement       *dto.LeaveSettlementResponse `db:"leave_settlement" json:"leave_settlement"`
+	LeaveSettlementDays   *float64                     `db:"leave_settlement_days" json:"leave_settlement_days"`
+	LeaveSettlementAmount *float64                     `db:"leave_settlement_amount" json:"leave_settlement_amount"`
+	SettlementCurrency    string                       `db:"settlement_currency" json:"settlement_currency"`
+	InitiatedBy           *int                         `db:"initiated_by" json:"initiated_by"`
+	CompletedAt           *time.Time                   `db:"completed_at" json:"completed_at"`
+	CancelledAt           *time.Time                   `db:"cancelled_at" json:"cancelled_at"`
+	CreatedAt             time.Time                    `db:"created_at" json:"created_at"`
+	UpdatedAt             time.Time                    `db:"updated_at" json:"updated_at"`
+}
+
+func (o *Offboarding) ToResponse() *dto.OffboardingResponse {
+	return &dto.OffboardingResponse{
+		ID:                  o.ID,
+		EmployeeID:          o.EmployeeID,
+		LastWorkingDay:      o.LastWorkingDay,
+		Reason:              o.Reason,
+		SuccessorID:         o.SuccessorID,
+		Status:              o.Status,
+		ReassignedReports:   o.ReassignedReports,
+		ReassignedApprovals: o.ReassignedApprovals,
+		Settlement:          o.LeaveSettlement,
+		InitiatedBy:         o.InitiatedBy,
+		CompletedAt:         o.CompletedAt,
+		CancelledAt:         o.CancelledAt,
+		CreatedAt:           o.CreatedAt,
+	}
+}
+
+type OffboardingTask struct {
+	ID            int        `db:"id" json:"id"`
+	TenantID      int        `db:"tenant_id" json:"tenant_id"`
+	OffboardingID int        `db:"offboarding_id" json:"offboarding_id"`
+	Title         string     `db:"title" json:"title"`
+	Description   string     `db:"description" json:"description"`
+	AssigneeType  string     `db:"assignee_type" json:"assignee_type"`
+	AssigneeID    *int       `db:"assignee_id" json:"assignee_id"`
+	DueDate       *time.Time `db:"due_date" json:"due_date"`
+	Status        string     `db:"status" json:"status"`
+	Notes         string     `db:"notes" json:"notes"`
+	CompletedBy   *int       `db:"completed_by" json:"completed_by"`
+	CompletedAt   *time.Time `db:"completed_at" json:"completed_at"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (o *OffboardingTask) ToResponse() *dto.OffboardingTaskResponse {
+	return &dto.OffboardingTaskResponse{
+		ID:            o.ID,
+		OffboardingID: o.OffboardingID,
+		Title:         o.Title,
+		Description:   o.Description,
+		AssigneeType:  o.AssigneeType,
+		AssigneeID:    o.AssigneeID,
+		DueDate:       o.DueDate,
+		Status:        o.Status,
+		Notes:         o.Notes,
+		CompletedBy:   o.CompletedBy,
+		CompletedAt:   o.CompletedAt,
+	}
+}
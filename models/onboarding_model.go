@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+type OnboardingTemplate struct {
+	ID            int                      `db:"id" json:"id"`
+	TenantID      int                      `db:"tenant_id" json:"tenant_id"`
+	Name          string                   `db:"name" json:"name"`
+	DepartmentID  *int                     `db:"department_id" json:"department_id"`
+	DesignationID *int                     `db:"designation_id" json:"designation_id"`
+	Status        string                   `db:"status" json:"status"`
+	Tasks         []OnboardingTemplateTask `json:"tasks"`
+	CreatedAt     time.Time                `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time                `db:"updated_at" json:"updated_at"`
+}
+
+type OnboardingTemplateTask struct {
+	ID                 int    `db:"id" json:"id"`
+	TemplateID         int    `db:"template_id" json:"template_id"`
+	Title              string `db:"title" json:"title"`
+	Description        string `db:"description" json:"description"`
+	AssigneeType       string `db:"assignee_type" json:"assignee_type"`
+	AssigneeEmployeeID *int   `db:"assignee_employee_id" json:"assignee_employee_id"`
+	DueOffsetDays      int    `db:"due_offset_days" json:"due_offset_days"`
+	SortOrder          int    `db:"sort_order" json:"sort_order"`
+}
+
+func (o *OnboardingTemplate) ToResponse() *dto.OnboardingTemplateResponse {
+	tasks := make([]*dto.OnboardingTemplateTaskResponse, len(o.Tasks))
+	for i, task := range o.Tasks {
+		tasks[i] = &dto.OnboardingTemplateTaskResponse{
+			ID:                 task.ID,
+			Title:              task.Title,
+			Description:        task.Description,
+			AssigneeType:       task.AssigneeType,
+			AssigneeEmployeeID: task.AssigneeEmployeeID,
+			DueOffsetDays:      task.DueOffsetDays,
+		}
+	}
+	return &dto.OnboardingTemplateResponse{
+		ID:            o.ID,
+		Name:          o.Name,
+		DepartmentID:  o.DepartmentID,
+		DesignationID: o.DesignationID,
+		Status:        o.Status,
+		Tasks:         tasks,
+		CreatedAt:     o.CreatedAt,
+		UpdatedAt:     o.UpdatedAt,
+	}
+}
+
+type OnboardingTask struct {
+	ID             int        `db:"id" json:"id"`
+	TenantID       int        `db:"tenant_id" json:"tenant_id"`
+	EmployeeID     int        `db:"employee_id" json:"employee_id"`
+	TemplateID     *int       `db:"template_id" json:"template_id"`
+	Title          string     `db:"title" json:"title"`
+	Description    string     `db:"description" json:"description"`
+	AssigneeType   string     `db:"assignee_type" json:"assignee_type"`
+	AssigneeID     *int       `db:"assignee_id" json:"assignee_id"`
+	DueDate        time.Time  `db:"due_date" json:"due_date"`
+	Status         string     `db:"status" json:"status"`
+	Notes          string     `db:"notes" json:"notes"`
+	CompletedBy    *int       `db:"completed_by" json:"completed_by"`
+	CompletedAt    *time.Time `db:"completed_at" json:"completed_at"`
+	LastRemindedAt *time.Time `db:"last_reminded_at" json:"last_reminded_at"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (o *OnboardingTask) ToResponse() *dto.OnboardingTaskResponse {
+	return &dto.OnboardingTaskResponse{
+		ID:           o.ID,
+		EmployeeID:   o.EmployeeID,
+		Title:        o.Title,
+		Description:  o.Description,
+		AssigneeType: o.AssigneeType,
+		AssigneeID:   o.AssigneeID,
+		DueDate:      o.DueDate,
+		Status:       o.Status,
+		Notes:        o.Notes,
+		CompletedBy:  o.CompletedBy,
+		CompletedAt:  o.CompletedAt,
+	}
+}
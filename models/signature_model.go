@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+)
+
+// SignatureRequest asks employees to sign, or acknowledge, DocumentVersion of
+// a document. DocumentChecksum is the SHA-256 of that version when the
+// request was made. SealedVersion is the document version holding the sealed
+// PDF once every signer has signed.
+type SignatureRequest struct {
+	ID               int               `db:"id" json:"id"`
+	TenantID         int               `db:"tenant_id" json:"tenant_id"`
+	DocumentID       int               `db:"document_id" json:"document_id"`
+	DocumentVersion  int               `db:"document_version" json:"document_version"`
+	DocumentName     string            `json:"document_name"`
+	DocumentChecksum string            `db:"document_checksum" json:"document_checksum"`
+	Kind             string            `db:"kind" json:"kind"`
+	Title            string            `db:"title" json:"title"`
+	Message          string            `db:"message" json:"message"`
+	PolicyName       string            `db:"policy_name" json:"policy_name"`
+	PolicyVersion    string            `db:"policy_version" json:"policy_version"`
+	Status           string            `db:"status" json:"status"`
+	SealedVersion    *int              `db:"sealed_version" json:"sealed_version"`
+	SealedChecksum   string            `db:"sealed_checksum" json:"sealed_checksum"`
+	CreatedBy        *int              `db:"created_by" json:"created_by"`
+	CreatedByName    string            `json:"created_by_name"`
+	CompletedAt      *time.Time        `db:"completed_at" json:"completed_at"`
+	CreatedAt        time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time         `db:"updated_at" json:"updated_at"`
+	Signers          []SignatureSigner `json:"signers"`
+}
+
+func (s *SignatureRequest) ToResponse() *dto.SignatureRequestResponse {
+	signers := make([]dto.SignatureSignerResponse, len(s.Signers))
+	for i := range s.Signers {
+		signers[i] = *s.Signers[i].ToResponse()
+	}
+	return &dto.SignatureRequestResponse{
+		ID:               s.ID,
+		DocumentID:       s.DocumentID,
+		DocumentVersion:  s.DocumentVersion,
+		DocumentName:     s.DocumentName,
+		DocumentChecksum: s.DocumentChecksum,
+		Kind:             s.Kind,
+		Title:            s.Title,
+		Message:          s.Message,
+		PolicyName:       s.PolicyName,
+		PolicyVersion:    s.PolicyVersion,
+		Status:           s.Status,
+		SealedVersion:    s.SealedVersion,
+		SealedChecksum:   s.SealedChecksum,
+		CreatedBy:        s.CreatedBy,
+		CreatedByName:    s.CreatedByName,
+		CompletedAt:      s.CompletedAt,
+		CreatedAt:        s.CreatedAt,
+		Signers:          signers,
+	}
+}
+
+// SignatureSigner is an employee asked to sign a request. Signers sign in
+// SignOrder; those sharing an order may sign in any order. SignatureImageKey
+// locates a drawn signature in document storage.
+type SignatureSigner struct {
+	ID                int        `db:"id" json:"id"`
+	TenantID          int        `db:"tenant_id" json:"tenant_id"`
+	RequestID         int        `db:"request_id" json:"request_id"`
+	EmployeeID        int        `db:"employee_id" json:"employee_id"`
+	EmployeeName      string     `json:"employee_name"`
+	EmployeeEmail     string     `json:"employee_email"`
+	SignOrder         int        `db:"sign_order" json:"sign_order"`
+	Status            string     `db:"status" json:"status"`
+	SignatureType     string     `db:"signature_type" json:"signature_type"`
+	TypedName         string     `db:"typed_name" json:"typed_name"`
+	SignatureImageKey string     `db:"signature_image_key" json:"-"`
+	IPAddress         string     `db:"ip_address" json:"ip_address"`
+	UserAgent         string     `db:"user_agent" json:"user_agent"`
+	DeclineReason     string     `db:"decline_reason" json:"decline_reason"`
+	NotifiedAt        *time.Time `db:"notified_at" json:"notified_at"`
+	SignedAt          *time.Time `db:"signed_at" json:"signed_at"`
+	DeclinedAt        *time.Time `db:"declined_at" json:"declined_at"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (s *SignatureSigner) ToResponse() *dto.SignatureSignerResponse {
+	return &dto.SignatureSignerResponse{
+		ID:            s.ID,
+		EmployeeID:    s.EmployeeID,
+		EmployeeName:  s.EmployeeName,
+		Order:         s.SignOrder,
+		Status:        s.Status,
+		SignatureType: s.SignatureType,
+		TypedName:     s.TypedName,
+		IPAddress:     s.IPAddress,
+		DeclineReason: s.DeclineReason,
+		SignedAt:      s.SignedAt,
+		DeclinedAt:    s.DeclinedAt,
+	}
+}
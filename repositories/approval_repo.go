@@ -0,0 +1,1445 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+// ErrApprovalConflict is returned when an approval request changed after it
+// was read, so a decision based on what was read cannot be applied.
+var ErrApprovalConflict = errors.New("approval request has changed")
+
+type ApprovalRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewApprovalRepository(pool *pgxpool.Pool) *ApprovalRepository {
+	return &ApprovalRepository{
+		pool: pool,
+	}
+}
+
+const approvalWorkflowColumns = `id, tenant_id, name, entity_type, COALESCE(status, 'active'), created_at, updated_at`
+
+func scanApprovalWorkflow(row pgx.Row) (*models.ApprovalWorkflow, error) {
+	var workflow models.ApprovalWorkflow
+	err := row.Scan(
+		&workflow.ID,
+		&workflow.TenantID,
+		&workflow.Name,
+		&workflow.EntityType,
+		&workflow.Status,
+		&workflow.CreatedAt,
+		&workflow.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+const approvalRequestColumns = `id, tenant_id, workflow_id, entity_type, entity_id, requester_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = requester_id), ''),
+	status, COALESCE(current_step_order, 0), version, completed_at, created_at, updated_at`
+
+func scanApprovalRequest(row pgx.Row) (*models.ApprovalRequest, error) {
+	var request models.ApprovalRequest
+	err := row.Scan(
+		&request.ID,
+		&request.TenantID,
+		&request.WorkflowID,
+		&request.EntityType,
+		&request.EntityID,
+		&request.RequesterID,
+		&request.RequesterName,
+		&request.Status,
+		&request.CurrentStepOrder,
+		&request.Version,
+		&request.CompletedAt,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+const approvalColumns = `id, approval_request_id, approval_step_id, COALESCE(step_order, 0), approver_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = approver_id), ''),
+	COALESCE(status, 'pending'), COALESCE(comments, ''), delegated_from_id, escalated_from_id,
+	remind_at, reminded_at, escalate_at, decided_at, created_at`
+
+func scanApproval(row pgx.Row) (*models.Approval, error) {
+	var approval models.Approval
+	err := row.Scan(
+		&approval.ID,
+		&approval.ApprovalRequestID,
+		&approval.ApprovalStepID,
+		&approval.StepOrder,
+		&approval.ApproverID,
+		&approval.ApproverName,
+		&approval.Status,
+		&approval.Comments,
+		&approval.DelegatedFromID,
+		&approval.EscalatedFromID,
+		&approval.RemindAt,
+		&approval.RemindedAt,
+		&approval.EscalateAt,
+		&approval.DecidedAt,
+		&approval.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// listSteps returns the steps of each workflow in order
+func (a *ApprovalRepository) listSteps(ctx context.Context, workflowIDs []int) (map[int][]models.ApprovalStep, error) {
+	query := `
+	SELECT s.id, s.workflow_id, s.step_order, COALESCE(s.approver_type, 'role'), s.approver_role_id, COALESCE(r.name, ''), COALESCE(s.description, ''),
+		COALESCE(s.approval_mode, 'any'), COALESCE(s.required_approvals, 0), COALESCE(s.skip_if_requester, FALSE), COALESCE(s.conditions, '[]'::jsonb),
+		COALESCE(s.reminder_hours, 0), COALESCE(s.sla_hours, 0)
+	FROM approval_steps s
+	LEFT JOIN roles r ON r.id = s.approver_role_id
+	WHERE s.workflow_id = ANY($1)
+	ORDER BY s.workflow_id, s.step_order, s.id
+	`
+
+	rows, err := a.pool.Query(ctx, query, workflowIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	steps := map[int][]models.ApprovalStep{}
+	for rows.Next() {
+		var step models.ApprovalStep
+		err := rows.Scan(
+			&step.ID,
+			&step.WorkflowID,
+			&step.StepOrder,
+			&step.ApproverType,
+			&step.ApproverRoleID,
+			&step.ApproverRoleName,
+			&step.Description,
+			&step.ApprovalMode,
+			&step.RequiredApprovals,
+			&step.SkipIfRequester,
+			&step.Conditions,
+			&step.ReminderHours,
+			&step.SLAHours,
+		)
+		if err != nil {
+			return nil, err
+		}
+		steps[step.WorkflowID] = append(steps[step.WorkflowID], step)
+	}
+
+	return steps, rows.Err()
+}
+
+func (a *ApprovalRepository) withSteps(ctx context.Context, workflows []models.ApprovalWorkflow) error {
+	ids := make([]int, len(workflows))
+	for i, workflow := range workflows {
+		ids[i] = workflow.ID
+	}
+	steps, err := a.listSteps(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range workflows {
+		workflows[i].Steps = steps[workflows[i].ID]
+		if workflows[i].Steps == nil {
+			workflows[i].Steps = []models.ApprovalStep{}
+		}
+	}
+	return nil
+}
+
+func (a *ApprovalRepository) getWorkflow(ctx context.Context, query string, args ...interface{}) (*models.ApprovalWorkflow, error) {
+	workflow, err := scanApprovalWorkflow(a.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		return nil, err
+	}
+	workflows := []models.ApprovalWorkflow{*workflow}
+	if err := a.withSteps(ctx, workflows); err != nil {
+		return nil, err
+	}
+	return &workflows[0], nil
+}
+
+func (a *ApprovalRepository) ListWorkflows(ctx context.Context, tenantID int) ([]models.ApprovalWorkflow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + approvalWorkflowColumns + `
+	FROM approval_workflows
+	WHERE tenant_id = $1
+	ORDER BY entity_type
+	`
+
+	rows, err := a.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workflows := []models.ApprovalWorkflow{}
+	for rows.Next() {
+		workflow, err := scanApprovalWorkflow(rows)
+		if err != nil {
+			return nil, err
+		}
+		workflows = append(workflows, *workflow)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := a.withSteps(ctx, workflows); err != nil {
+		return nil, err
+	}
+	return workflows, nil
+}
+
+func (a *ApprovalRepository) GetWorkflow(ctx context.Context, tenantID int, id int) (*models.ApprovalWorkflow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + approvalWorkflowColumns + `
+	FROM approval_workflows
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	return a.getWorkflow(ctx, query, tenantID, id)
+}
+
+// GetActiveWorkflow returns the active workflow for the tenant's records of
+// the entity type
+func (a *ApprovalRepository) GetActiveWorkflow(ctx context.Context, tenantID int, entityType string) (*models.ApprovalWorkflow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + approvalWorkflowColumns + `
+	FROM approval_workflows
+	WHERE tenant_id = $1 AND entity_type = $2 AND status = 'active'
+	`
+
+	return a.getWorkflow(ctx, query, tenantID, entityType)
+}
+
+func insertApprovalSteps(ctx context.Context, tx pgx.Tx, tenantID int, workflowID int, steps []models.ApprovalStep) error {
+	query := `
+	INSERT INTO approval_steps (workflow_id, step_order, approver_type, approver_role_id, description, approval_mode, required_approvals, skip_if_requester, conditions, reminder_hours, sla_hours)
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, 0), $8, $9, NULLIF($10, 0), NULLIF($11, 0))
+	RETURNING id
+	`
+
+	for _, step := range steps {
+		var id int
+		err := tx.QueryRow(ctx, query,
+			workflowID,
+			step.StepOrder,
+			step.ApproverType,
+			step.ApproverRoleID,
+			step.Description,
+			step.ApprovalMode,
+			step.RequiredApprovals,
+			step.SkipIfRequester,
+			step.Conditions,
+			step.ReminderHours,
+			step.SLAHours,
+		).Scan(&id)
+		if err != nil {
+			return err
+		}
+		if err := auditRow(ctx, tx, tenantID, AuditEntityApprovalStep, "approval_steps", id, AuditActionCreate, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateWorkflow returns pgx.ErrNoRows when the tenant already has a workflow
+// for the entity type
+func (a *ApprovalRepository) CreateWorkflow(ctx context.Context, workflow *models.ApprovalWorkflow) (*models.ApprovalWorkflow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, workflow.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO approval_workflows (tenant_id, name, entity_type, status)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (tenant_id, entity_type) DO NOTHING
+	RETURNING id
+	`
+
+	var id int
+	if err := tx.QueryRow(ctx, query, workflow.TenantID, workflow.Name, workflow.EntityType, workflow.Status).Scan(&id); err != nil {
+		return nil, err
+	}
+	if err := auditRow(ctx, tx, workflow.TenantID, AuditEntityApprovalWorkflow, "approval_workflows", id, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+	if err := insertApprovalSteps(ctx, tx, workflow.TenantID, id, workflow.Steps); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a.GetWorkflow(ctx, workflow.TenantID, id)
+}
+
+// UpdateWorkflow renames the workflow, changes its status and replaces its
+// steps
+func (a *ApprovalRepository) UpdateWorkflow(ctx context.Context, workflow *models.ApprovalWorkflow) (*models.ApprovalWorkflow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, workflow.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `name = $3, status = $4, updated_at = CURRENT_TIMESTAMP`
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityApprovalWorkflow, "approval_workflows", set, `tenant_id = $1 AND id = $2`, workflow.TenantID, workflow.ID, workflow.Name, workflow.Status)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	if _, err := deleteAuditedRows(ctx, tx, workflow.TenantID, AuditEntityApprovalStep, "approval_steps", `workflow_id = $1`, workflow.ID); err != nil {
+		return nil, err
+	}
+	if err := insertApprovalSteps(ctx, tx, workflow.TenantID, workflow.ID, workflow.Steps); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a.GetWorkflow(ctx, workflow.TenantID, workflow.ID)
+}
+
+func (a *ApprovalRepository) DeleteWorkflow(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// The steps would go with the workflow, but are deleted first to be audited
+	stepsWhere := `workflow_id IN (SELECT id FROM approval_workflows WHERE tenant_id = $1 AND id = $2)`
+	if _, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityApprovalStep, "approval_steps", stepsWhere, tenantID, id); err != nil {
+		return err
+	}
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityApprovalWorkflow, "approval_workflows", `tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CountPendingRequests returns how many approvals are running on the workflow
+func (a *ApprovalRepository) CountPendingRequests(ctx context.Context, workflowID int) (int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT COUNT(*)
+	FROM approval_requests
+	WHERE workflow_id = $1 AND status = 'pending'
+	`
+
+	var count int
+	err := a.pool.QueryRow(ctx, query, workflowID).Scan(&count)
+	return count, err
+}
+
+func (a *ApprovalRepository) RoleExists(ctx context.Context, tenantID int, roleID int) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	var exists bool
+	err := a.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE tenant_id = $1 AND id = $2)`, tenantID, roleID).Scan(&exists)
+	return exists, err
+}
+
+// ListRoleHolders returns the employees holding a role who have not left
+func (a *ApprovalRepository) ListRoleHolders(ctx context.Context, tenantID int, roleID int) ([]int, error) {
+	query := `
+	SELECT id
+	FROM employees
+	WHERE tenant_id = $1 AND role_id = $2 AND status <> 'terminated'
+	ORDER BY id
+	`
+
+	return a.listIDs(ctx, query, tenantID, roleID)
+}
+
+// ListRoleHoldersByName is ListRoleHolders for a role looked up by name
+func (a *ApprovalRepository) ListRoleHoldersByName(ctx context.Context, tenantID int, roleName string) ([]int, error) {
+	query := `
+	SELECT e.id
+	FROM employees e
+	JOIN roles r ON r.id = e.role_id
+	WHERE e.tenant_id = $1 AND r.name = $2 AND e.status <> 'terminated'
+	ORDER BY e.id
+	`
+
+	return a.listIDs(ctx, query, tenantID, roleName)
+}
+
+func (a *ApprovalRepository) listIDs(ctx context.Context, query string, args ...interface{}) ([]int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	rows, err := a.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetReportingLine returns the employee's manager and the head of their
+// department, either of which may be unset
+func (a *ApprovalRepository) GetReportingLine(ctx context.Context, tenantID int, employeeID int) (*int, *int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT e.manager_id, d.hod_id
+	FROM employees e
+	LEFT JOIN departments d ON d.id = e.department_id
+	WHERE e.tenant_id = $1 AND e.id = $2
+	`
+
+	var managerID, hodID *int
+	err := a.pool.QueryRow(ctx, query, tenantID, employeeID).Scan(&managerID, &hodID)
+	return managerID, hodID, err
+}
+
+// listApprovals returns the approvals of each request in step order
+func (a *ApprovalRepository) listApprovals(ctx context.Context, requestIDs []int) (map[int][]models.Approval, error) {
+	query := `
+	SELECT ` + approvalColumns + `
+	FROM approvals
+	WHERE approval_request_id = ANY($1)
+	ORDER BY approval_request_id, step_order, id
+	`
+
+	rows, err := a.pool.Query(ctx, query, requestIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := map[int][]models.Approval{}
+	for rows.Next() {
+		approval, err := scanApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		approvals[approval.ApprovalRequestID] = append(approvals[approval.ApprovalRequestID], *approval)
+	}
+
+	return approvals, rows.Err()
+}
+
+func (a *ApprovalRepository) listApprovalRequests(ctx context.Context, query string, args ...interface{}) ([]models.ApprovalRequest, error) {
+	rows, err := a.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.ApprovalRequest{}
+	ids := []int{}
+	for rows.Next() {
+		request, err := scanApprovalRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+		ids = append(ids, request.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	approvals, err := a.listApprovals(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range requests {
+		requests[i].Approvals = approvals[requests[i].ID]
+		if requests[i].Approvals == nil {
+			requests[i].Approvals = []models.Approval{}
+		}
+	}
+	return requests, nil
+}
+
+func (a *ApprovalRepository) getApprovalRequest(ctx context.Context, query string, args ...interface{}) (*models.ApprovalRequest, error) {
+	requests, err := a.listApprovalRequests(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return &requests[0], nil
+}
+
+func (a *ApprovalRepository) GetApprovalRequest(ctx context.Context, tenantID int, id int) (*models.ApprovalRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + approvalRequestColumns + `
+	FROM approval_requests
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	return a.getApprovalRequest(ctx, query, tenantID, id)
+}
+
+// GetPendingApprovalRequest returns the approval running for a record
+func (a *ApprovalRepository) GetPendingApprovalRequest(ctx context.Context, tenantID int, entityType string, entityID int) (*models.ApprovalRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + approvalRequestColumns + `
+	FROM approval_requests
+	WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3 AND status = 'pending'
+	`
+
+	return a.getApprovalRequest(ctx, query, tenantID, entityType, entityID)
+}
+
+// ListEntityApprovalRequests returns every approval a record has been
+// through, newest first
+func (a *ApprovalRepository) ListEntityApprovalRequests(ctx context.Context, tenantID int, entityType string, entityID int) ([]models.ApprovalRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + approvalRequestColumns + `
+	FROM approval_requests
+	WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3
+	ORDER BY created_at DESC, id DESC
+	`
+
+	return a.listApprovalRequests(ctx, query, tenantID, entityType, entityID)
+}
+
+// insertApprovals adds approvals to a request, also linking leave requests and
+// memos through the columns that predate approval requests. Approvals that
+// are not pending, such as skipped ones, are stamped as decided.
+func insertApprovals(ctx context.Context, tx pgx.Tx, tenantID int, requestID int, entityType string, entityID int, approvals []models.Approval) error {
+	query := `
+	INSERT INTO approvals (tenant_id, approval_request_id, approval_step_id, step_order, approver_id, status, comments, decided_at, leave_request_id, memo_id,
+		delegated_from_id, escalated_from_id, remind_at, escalate_at)
+	VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''),
+		CASE WHEN $6 <> 'pending' THEN CURRENT_TIMESTAMP END,
+		CASE WHEN $8 = 'leave_request' THEN $9::int END,
+		CASE WHEN $8 = 'memo' THEN $9::int END,
+		$10, $11, $12, $13)
+	RETURNING id
+	`
+
+	for _, approval := range approvals {
+		var id int
+		err := tx.QueryRow(ctx, query,
+			tenantID,
+			requestID,
+			approval.ApprovalStepID,
+			approval.StepOrder,
+			approval.ApproverID,
+			approval.Status,
+			approval.Comments,
+			entityType,
+			entityID,
+			approval.DelegatedFromID,
+			approval.EscalatedFromID,
+			approval.RemindAt,
+			approval.EscalateAt,
+		).Scan(&id)
+		if err != nil {
+			return err
+		}
+		if err := auditRow(ctx, tx, tenantID, AuditEntityApproval, "approvals", id, AuditActionCreate, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateApprovalRequest starts an approval for a record with the approvals of
+// its first step. A request created already finished, because no step applied
+// to the record, runs onComplete in its transaction, so the owning module's
+// changes are saved together with the request.
+func (a *ApprovalRepository) CreateApprovalRequest(ctx context.Context, request *models.ApprovalRequest, approvals []models.Approval, onComplete func(ctx context.Context, tx pgx.Tx) error) (*models.ApprovalRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, request.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO approval_requests (tenant_id, workflow_id, entity_type, entity_id, requester_id, status, current_step_order, completed_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, CASE WHEN $6 <> 'pending' THEN CURRENT_TIMESTAMP END)
+	RETURNING id
+	`
+
+	var id int
+	err = tx.QueryRow(ctx, query,
+		request.TenantID,
+		request.WorkflowID,
+		request.EntityType,
+		request.EntityID,
+		request.RequesterID,
+		request.Status,
+		request.CurrentStepOrder,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auditRow(ctx, tx, request.TenantID, AuditEntityApprovalRequest, "approval_requests", id, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+	if err := insertApprovals(ctx, tx, request.TenantID, id, request.EntityType, request.EntityID, approvals); err != nil {
+		return nil, err
+	}
+
+	if request.Status != "pending" && onComplete != nil {
+		if err := onComplete(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a.GetApprovalRequest(ctx, request.TenantID, id)
+}
+
+// ApprovalTransition is what one decision does to an approval request: the
+// approvals whose status changes, the approvals of the step it moves on to,
+// and the request's new status and step
+type ApprovalTransition struct {
+	TenantID     int
+	RequestID    int
+	Version      int
+	Decisions    []models.Approval
+	NewApprovals []models.Approval
+	Status       string
+	StepOrder    int
+}
+
+// ApplyApprovalTransition records a decision in one transaction. It fails
+// with ErrApprovalConflict when the request is no longer at the version the
+// transition was worked out from. When the request finishes, onComplete runs
+// in the same transaction, so the owning module's changes commit together
+// with the decision and an error from it undoes the decision.
+func (a *ApprovalRepository) ApplyApprovalTransition(ctx context.Context, transition *ApprovalTransition, onComplete func(ctx context.Context, tx pgx.Tx) error) (*models.ApprovalRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, transition.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	requestSet := `status = $4, current_step_order = $5, version = version + 1,
+		completed_at = CASE WHEN $4 <> 'pending' THEN CURRENT_TIMESTAMP END, updated_at = CURRENT_TIMESTAMP`
+
+	entityType, entityID, err := advanceApprovalRequest(ctx, tx, transition.TenantID, transition.RequestID, transition.Version, requestSet, transition.Status, transition.StepOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	decisionSet := `status = $2, comments = NULLIF($3, ''), decided_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+
+	for _, decision := range transition.Decisions {
+		ids, err := updateAuditedRows(ctx, tx, AuditEntityApproval, "approvals", decisionSet, `id = $1 AND status = 'pending'`, decision.ID, decision.Status, decision.Comments)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, ErrApprovalConflict
+		}
+	}
+	if err := insertApprovals(ctx, tx, transition.TenantID, transition.RequestID, entityType, entityID, transition.NewApprovals); err != nil {
+		return nil, err
+	}
+
+	if transition.Status != "pending" && onComplete != nil {
+		if err := onComplete(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a.GetApprovalRequest(ctx, transition.TenantID, transition.RequestID)
+}
+
+// advanceApprovalRequest applies set, written against args from $4 on, to
+// the pending request at version and goes up a version with it. It returns
+// the record the request is for, or ErrApprovalConflict when the request is
+// no longer pending at that version.
+func advanceApprovalRequest(ctx context.Context, tx pgx.Tx, tenantID int, requestID int, version int, set string, args ...interface{}) (string, int, error) {
+	where := `tenant_id = $1 AND id = $2 AND version = $3 AND status = 'pending'`
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityApprovalRequest, "approval_requests", set, where, append([]interface{}{tenantID, requestID, version}, args...)...)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(ids) == 0 {
+		return "", 0, ErrApprovalConflict
+	}
+
+	var entityType string
+	var entityID int
+	err = tx.QueryRow(ctx, `SELECT entity_type, entity_id FROM approval_requests WHERE id = $1`, requestID).Scan(&entityType, &entityID)
+	return entityType, entityID, err
+}
+
+// CancelApprovalRequest stops the approval running for a record
+func (a *ApprovalRepository) CancelApprovalRequest(ctx context.Context, tenantID int, entityType string, entityID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	requestSet := `status = 'cancelled', version = version + 1, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	requestWhere := `tenant_id = $1 AND entity_type = $2 AND entity_id = $3 AND status = 'pending'`
+
+	requestIDs, err := updateAuditedRows(ctx, tx, AuditEntityApprovalRequest, "approval_requests", requestSet, requestWhere, tenantID, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if len(requestIDs) == 0 {
+		return pgx.ErrNoRows
+	}
+
+	approvalSet := `status = 'cancelled', updated_at = CURRENT_TIMESTAMP`
+	approvalWhere := `approval_request_id = ANY($1) AND status = 'pending'`
+
+	if _, err := updateAuditedRows(ctx, tx, AuditEntityApproval, "approvals", approvalSet, approvalWhere, requestIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+const approvalDelegationColumns = `id, tenant_id, delegator_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = delegator_id), ''),
+	delegate_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = delegate_id), ''),
+	COALESCE(entity_type, ''), start_date, end_date, COALESCE(reason, ''), created_by, revoked_at, created_at, updated_at`
+
+func scanApprovalDelegation(row pgx.Row) (*models.ApprovalDelegation, error) {
+	var delegation models.ApprovalDelegation
+	err := row.Scan(
+		&delegation.ID,
+		&delegation.TenantID,
+		&delegation.DelegatorID,
+		&delegation.DelegatorName,
+		&delegation.DelegateID,
+		&delegation.DelegateName,
+		&delegation.EntityType,
+		&delegation.StartDate,
+		&delegation.EndDate,
+		&delegation.Reason,
+		&delegation.CreatedBy,
+		&delegation.RevokedAt,
+		&delegation.CreatedAt,
+		&delegation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+// ListDelegations returns the tenant's delegations, latest first. When
+// employeeID is set only the delegations the employee gave or received are
+// returned.
+func (a *ApprovalRepository) ListDelegations(ctx context.Context, tenantID int, employeeID int) ([]models.ApprovalDelegation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + approvalDelegationColumns + `
+	FROM approval_delegations
+	WHERE tenant_id = $1 AND ($2 = 0 OR delegator_id = $2 OR delegate_id = $2)
+	ORDER BY start_date DESC, id DESC
+	`
+
+	rows, err := a.pool.Query(ctx, query, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	delegations := []models.ApprovalDelegation{}
+	for rows.Next() {
+		delegation, err := scanApprovalDelegation(rows)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, *delegation)
+	}
+
+	return delegations, rows.Err()
+}
+
+func (a *ApprovalRepository) GetDelegation(ctx context.Context, tenantID int, id int) (*models.ApprovalDelegation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + approvalDelegationColumns + `
+	FROM approval_delegations
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	return scanApprovalDelegation(a.pool.QueryRow(ctx, query, tenantID, id))
+}
+
+func (a *ApprovalRepository) CreateDelegation(ctx context.Context, delegation *models.ApprovalDelegation) (*models.ApprovalDelegation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO approval_delegations (tenant_id, delegator_id, delegate_id, entity_type, start_date, end_date, reason, created_by)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), $8)
+	RETURNING id
+	`
+
+	tx, err := beginAudited(ctx, a.pool, delegation.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, query,
+		delegation.TenantID,
+		delegation.DelegatorID,
+		delegation.DelegateID,
+		delegation.EntityType,
+		delegation.StartDate,
+		delegation.EndDate,
+		delegation.Reason,
+		delegation.CreatedBy,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, delegation.TenantID, AuditEntityApprovalDelegation, "approval_delegations", id, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a.GetDelegation(ctx, delegation.TenantID, id)
+}
+
+// RevokeDelegation ends a delegation that has not been revoked. Approvals
+// already passed to the delegate stay with them.
+func (a *ApprovalRepository) RevokeDelegation(ctx context.Context, tenantID int, id int) (*models.ApprovalDelegation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityApprovalDelegation, "approval_delegations", set, `tenant_id = $1 AND id = $2 AND revoked_at IS NULL`, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a.GetDelegation(ctx, tenantID, id)
+}
+
+// HasOverlappingDelegation reports whether the delegator already delegates
+// approvals of the entity type on any day between start and end. An empty
+// entity type overlaps every delegation.
+func (a *ApprovalRepository) HasOverlappingDelegation(ctx context.Context, tenantID int, delegatorID int, entityType string, start time.Time, end time.Time) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT EXISTS (
+		SELECT 1
+		FROM approval_delegations
+		WHERE tenant_id = $1 AND delegator_id = $2 AND revoked_at IS NULL
+		  AND start_date <= $5 AND end_date >= $4
+		  AND (entity_type IS NULL OR $3 = '' OR entity_type = $3)
+	)
+	`
+
+	var exists bool
+	err := a.pool.QueryRow(ctx, query, tenantID, delegatorID, entityType, start, end).Scan(&exists)
+	return exists, err
+}
+
+// IsActiveEmployee reports whether the employee belongs to the tenant and has
+// not left
+func (a *ApprovalRepository) IsActiveEmployee(ctx context.Context, tenantID int, employeeID int) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	var exists bool
+	err := a.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM employees WHERE tenant_id = $1 AND id = $2 AND status <> 'terminated')`, tenantID, employeeID).Scan(&exists)
+	return exists, err
+}
+
+// ListActiveDelegates maps each approver who delegates approvals of the
+// entity type on the date to their delegate. A delegation for the entity type
+// wins over one covering every type.
+func (a *ApprovalRepository) ListActiveDelegates(ctx context.Context, tenantID int, entityType string, approverIDs []int, date time.Time) (map[int]int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT DISTINCT ON (d.delegator_id) d.delegator_id, d.delegate_id
+	FROM approval_delegations d
+	JOIN employees e ON e.id = d.delegate_id
+	WHERE d.tenant_id = $1 AND d.delegator_id = ANY($2) AND d.revoked_at IS NULL
+	  AND $3 BETWEEN d.start_date AND d.end_date
+	  AND (d.entity_type IS NULL OR d.entity_type = $4)
+	  AND e.status <> 'terminated'
+	ORDER BY d.delegator_id, d.entity_type NULLS LAST, d.id DESC
+	`
+
+	rows, err := a.pool.Query(ctx, query, tenantID, approverIDs, date, entityType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	delegates := map[int]int{}
+	for rows.Next() {
+		var delegatorID, delegateID int
+		if err := rows.Scan(&delegatorID, &delegateID); err != nil {
+			return nil, err
+		}
+		delegates[delegatorID] = delegateID
+	}
+
+	return delegates, rows.Err()
+}
+
+// ApprovalNotice is a pending approval the scheduler tells its approver about
+type ApprovalNotice struct {
+	ApprovalID    int
+	RequestID     int
+	TenantID      int
+	EntityType    string
+	EntityID      int
+	RequesterName string
+	ApproverEmail string
+	EscalateAt    *time.Time
+}
+
+// approvalNoticesQuery selects the notices of the approvals with the given IDs
+const approvalNoticesQuery = `
+	SELECT a.id, a.approval_request_id, r.tenant_id, r.entity_type, r.entity_id,
+		COALESCE(q.first_name || ' ' || q.last_name, ''), e.email, a.escalate_at
+	FROM approvals a
+	JOIN approval_requests r ON r.id = a.approval_request_id
+	JOIN employees e ON e.id = a.approver_id
+	LEFT JOIN employees q ON q.id = r.requester_id
+	WHERE a.id = ANY($1)
+	ORDER BY a.id
+	`
+
+func (a *ApprovalRepository) listNotices(ctx context.Context, query string, args ...interface{}) ([]ApprovalNotice, error) {
+	rows, err := a.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notices := []ApprovalNotice{}
+	for rows.Next() {
+		var notice ApprovalNotice
+		err := rows.Scan(
+			&notice.ApprovalID,
+			&notice.RequestID,
+			&notice.TenantID,
+			&notice.EntityType,
+			&notice.EntityID,
+			&notice.RequesterName,
+			&notice.ApproverEmail,
+			&notice.EscalateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notices = append(notices, notice)
+	}
+
+	return notices, rows.Err()
+}
+
+// ReassignDelegatedApprovals moves the pending approvals of approvers who
+// delegate on the date to their delegates and returns the moved approvals.
+// Approvals are not moved to the requester or to someone already approving
+// the same step. Each request moved goes up a version so a decision worked
+// out before the move cannot apply.
+func (a *ApprovalRepository) ReassignDelegatedApprovals(ctx context.Context, date time.Time) ([]ApprovalNotice, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	dueFrom := `
+	FROM approvals a
+	JOIN approval_requests r ON r.id = a.approval_request_id
+	JOIN approval_delegations d ON d.tenant_id = a.tenant_id AND d.delegator_id = a.approver_id
+	JOIN employees de ON de.id = d.delegate_id
+	WHERE a.status = 'pending' AND r.status = 'pending' AND d.revoked_at IS NULL
+	  AND $1 BETWEEN d.start_date AND d.end_date
+	  AND (d.entity_type IS NULL OR d.entity_type = r.entity_type)
+	  AND de.status <> 'terminated'
+	  AND d.delegate_id <> r.requester_id
+	  AND NOT EXISTS (
+		SELECT 1 FROM approvals o
+		WHERE o.approval_request_id = a.approval_request_id AND o.step_order = a.step_order AND o.approver_id = d.delegate_id
+	  )`
+
+	tenantIDs, err := queryTenantIDs(ctx, a.pool, `SELECT DISTINCT a.tenant_id`+dueFrom, date)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := beginAudited(ctx, a.pool, tenantIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	dueQuery := `SELECT a.id, a.approval_request_id, a.step_order, d.delegate_id` + dueFrom + `
+	  AND a.tenant_id = ANY($2)
+	ORDER BY a.id
+	FOR UPDATE OF a
+	`
+
+	rows, err := tx.Query(ctx, dueQuery, date, tenantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	type move struct {
+		approvalID int
+		requestID  int
+		stepOrder  int
+		delegateID int
+	}
+	var moves []move
+	for rows.Next() {
+		var m move
+		if err := rows.Scan(&m.approvalID, &m.requestID, &m.stepOrder, &m.delegateID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		moves = append(moves, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	moveSet := `approver_id = $2, delegated_from_id = COALESCE(delegated_from_id, approver_id), updated_at = CURRENT_TIMESTAMP`
+
+	// An approval moves once, and a delegate gets one approval of a step
+	moved := map[int]bool{}
+	bumped := map[int]bool{}
+	taken := map[[3]int]bool{}
+	var approvalIDs, requestIDs []int
+	for _, m := range moves {
+		step := [3]int{m.requestID, m.stepOrder, m.delegateID}
+		if moved[m.approvalID] || taken[step] {
+			continue
+		}
+		if _, err := updateAuditedRows(ctx, tx, AuditEntityApproval, "approvals", moveSet, `id = $1`, m.approvalID, m.delegateID); err != nil {
+			return nil, err
+		}
+		moved[m.approvalID] = true
+		taken[step] = true
+		approvalIDs = append(approvalIDs, m.approvalID)
+		if !bumped[m.requestID] {
+			bumped[m.requestID] = true
+			requestIDs = append(requestIDs, m.requestID)
+		}
+	}
+
+	bumpSet := `version = version + 1, updated_at = CURRENT_TIMESTAMP`
+	if _, err := updateAuditedRows(ctx, tx, AuditEntityApprovalRequest, "approval_requests", bumpSet, `id = ANY($1)`, requestIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a.listNotices(ctx, approvalNoticesQuery, approvalIDs)
+}
+
+// ClaimDueReminders marks up to limit pending approvals whose reminder is due
+// at now as reminded and returns them. Rows locked by another instance are
+// skipped, so each reminder is sent once even when several schedulers run.
+func (a *ApprovalRepository) ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]ApprovalNotice, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	dueFrom := `
+		FROM approvals a
+		JOIN approval_requests r ON r.id = a.approval_request_id
+		WHERE a.status = 'pending' AND r.status = 'pending' AND a.reminded_at IS NULL AND a.remind_at <= $1`
+
+	tenantIDs, err := queryTenantIDs(ctx, a.pool, `SELECT DISTINCT a.tenant_id`+dueFrom, now)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := beginAudited(ctx, a.pool, tenantIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	dueWhere := `id IN (
+		SELECT a.id` + dueFrom + ` AND a.tenant_id = ANY($3)
+		ORDER BY a.remind_at, a.id
+		LIMIT $2
+		FOR UPDATE OF a SKIP LOCKED
+	)`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityApproval, "approvals", `reminded_at = CURRENT_TIMESTAMP`, dueWhere, now, limit, tenantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a.listNotices(ctx, approvalNoticesQuery, ids)
+}
+
+// DueEscalation is a pending approval that has passed its escalation time
+type DueEscalation struct {
+	TenantID   int
+	RequestID  int
+	ApprovalID int
+}
+
+// ListDueEscalations returns up to limit pending approvals of pending
+// requests that were due to escalate at now, oldest first
+func (a *ApprovalRepository) ListDueEscalations(ctx context.Context, now time.Time, limit int) ([]DueEscalation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT r.tenant_id, r.id, a.id
+	FROM approvals a
+	JOIN approval_requests r ON r.id = a.approval_request_id
+	WHERE a.status = 'pending' AND r.status = 'pending' AND a.escalate_at <= $1
+	ORDER BY a.escalate_at, a.id
+	LIMIT $2
+	`
+
+	rows, err := a.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	escalations := []DueEscalation{}
+	for rows.Next() {
+		var escalation DueEscalation
+		if err := rows.Scan(&escalation.TenantID, &escalation.RequestID, &escalation.ApprovalID); err != nil {
+			return nil, err
+		}
+		escalations = append(escalations, escalation)
+	}
+
+	return escalations, rows.Err()
+}
+
+// EscalateApproval marks a pending approval escalated and adds the approval
+// that replaces it in one transaction. Like ApplyApprovalTransition it fails
+// with ErrApprovalConflict when the request moved on from the version the
+// escalation was worked out from, which also keeps two schedulers from
+// escalating the same approval.
+func (a *ApprovalRepository) EscalateApproval(ctx context.Context, tenantID int, requestID int, version int, approvalID int, replacement models.Approval) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	entityType, entityID, err := advanceApprovalRequest(ctx, tx, tenantID, requestID, version, `version = version + 1, updated_at = CURRENT_TIMESTAMP`)
+	if err != nil {
+		return err
+	}
+
+	approvalSet := `status = 'escalated', decided_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	approvalWhere := `id = $1 AND approval_request_id = $2 AND status = 'pending'`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityApproval, "approvals", approvalSet, approvalWhere, approvalID, requestID)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrApprovalConflict
+	}
+	if err := insertApprovals(ctx, tx, tenantID, requestID, entityType, entityID, []models.Approval{replacement}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ClearEscalation stops a pending approval that has nowhere to escalate to
+// from coming up again
+func (a *ApprovalRepository) ClearEscalation(ctx context.Context, tenantID int, approvalID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, a.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `escalate_at = NULL, updated_at = CURRENT_TIMESTAMP`
+	if _, err := updateAuditedRows(ctx, tx, AuditEntityApproval, "approvals", set, `tenant_id = $1 AND id = $2 AND status = 'pending'`, tenantID, approvalID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (a *ApprovalRepository) GetEmployeeEmail(ctx context.Context, tenantID int, employeeID int) (string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	var email string
+	err := a.pool.QueryRow(ctx, `SELECT email FROM employees WHERE tenant_id = $1 AND id = $2`, tenantID, employeeID).Scan(&email)
+	return email, err
+}
+
+// ListInbox returns the employee's pending approvals in the current stage of
+// pending requests, oldest first
+func (a *ApprovalRepository) ListInbox(ctx context.Context, tenantID int, approverID int) ([]models.ApprovalInboxItem, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT a.id, r.id, r.version, r.entity_type, r.entity_id, r.requester_id,
+		COALESCE(q.first_name || ' ' || q.last_name, ''),
+		COALESCE(a.step_order, 0), COALESCE(s.description, ''), COALESCE(s.approval_mode, 'any'), COALESCE(s.approver_type, ''),
+		a.delegated_from_id, COALESCE(df.first_name || ' ' || df.last_name, ''), a.escalated_from_id,
+		a.escalate_at, a.created_at, r.created_at
+	FROM approvals a
+	JOIN approval_requests r ON r.id = a.approval_request_id
+	LEFT JOIN approval_steps s ON s.id = a.approval_step_id
+	LEFT JOIN employees q ON q.id = r.requester_id
+	LEFT JOIN employees df ON df.id = a.delegated_from_id
+	WHERE r.tenant_id = $1 AND a.approver_id = $2 AND a.status = 'pending'
+	  AND r.status = 'pending' AND a.step_order = r.current_step_order
+	ORDER BY a.created_at, a.id
+	`
+
+	rows, err := a.pool.Query(ctx, query, tenantID, approverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.ApprovalInboxItem{}
+	for rows.Next() {
+		var item models.ApprovalInboxItem
+		err := rows.Scan(
+			&item.ApprovalID,
+			&item.RequestID,
+			&item.Version,
+			&item.EntityType,
+			&item.EntityID,
+			&item.RequesterID,
+			&item.RequesterName,
+			&item.StepOrder,
+			&item.StepDescription,
+			&item.ApprovalMode,
+			&item.ApproverType,
+			&item.DelegatedFromID,
+			&item.DelegatedFromName,
+			&item.EscalatedFromID,
+			&item.EscalateAt,
+			&item.AssignedAt,
+			&item.SubmittedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
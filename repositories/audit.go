@@ -0,0 +1,361 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/middleware"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+// Audited entity types
+const (
+	AuditEntityCompany     = "company"
+	AuditEntityEmployee    = "employee"
+	AuditEntityDepartment  = "department"
+	AuditEntityDesignation = "designation"
+	AuditEntityRole        = "role"
+	AuditEntityPermission  = "permission"
+	AuditEntityTenant      = "tenant"
+	// Employment history, offboardings and the approvals of approval requests
+	AuditEntityEmploymentHistory = "employment_history"
+	AuditEntityOffboarding       = "offboarding"
+	AuditEntityApproval          = "approval"
+	// Employee profiles, custom fields, invitations and exports
+	AuditEntityPersonalDetails  = "personal_details"
+	AuditEntityAddress          = "address"
+	AuditEntityEmergencyContact = "emergency_contact"
+	AuditEntityBankAccount      = "bank_account"
+	AuditEntityCustomField      = "custom_field"
+	AuditEntityInvitation       = "invitation"
+	AuditEntityExportJob        = "export_job"
+	// Onboarding and offboarding
+	AuditEntityOnboardingTemplate     = "onboarding_template"
+	AuditEntityOnboardingTemplateTask = "onboarding_template_task"
+	AuditEntityOnboardingTask         = "onboarding_task"
+	AuditEntityOffboardingTask        = "offboarding_task"
+	AuditEntityOffboardingChecklist   = "offboarding_checklist_item"
+	// Leave
+	AuditEntityLeaveType          = "leave_type"
+	AuditEntityLeaveRequest       = "leave_request"
+	AuditEntityLeaveAccrualPolicy = "leave_accrual_policy"
+	AuditEntityLeaveAccrualTier   = "leave_accrual_tier"
+	AuditEntityLeaveLedgerEntry   = "leave_ledger_entry"
+	AuditEntityLeaveBalance       = "leave_balance"
+	AuditEntityHoliday            = "holiday"
+	AuditEntityCalendarFeed       = "calendar_feed"
+	// Approval workflows, requests and delegations
+	AuditEntityApprovalWorkflow   = "approval_workflow"
+	AuditEntityApprovalStep       = "approval_step"
+	AuditEntityApprovalRequest    = "approval_request"
+	AuditEntityApprovalDelegation = "approval_delegation"
+	// Memos and disciplinary cases
+	AuditEntityMemoType              = "memo_type"
+	AuditEntityMemo                  = "memo"
+	AuditEntityDisciplinaryCase      = "disciplinary_case"
+	AuditEntityDisciplinaryCaseEvent = "disciplinary_case_event"
+	// Documents and letter templates
+	AuditEntityDocumentType        = "document_type"
+	AuditEntityDocument            = "document"
+	AuditEntityDocumentVersion     = "document_version"
+	AuditEntityDocumentRequirement = "document_requirement"
+	AuditEntityLetterTemplate      = "letter_template"
+	// Signature requests and their signers
+	AuditEntitySignatureRequest = "signature_request"
+	AuditEntitySignatureSigner  = "signature_signer"
+)
+
+// Audit actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+const auditRedacted = "[REDACTED]"
+
+// auditChainLock is the first key of the transaction advisory lock taken on
+// a tenant's audit chain, the second being the tenant ID
+const auditChainLock = 4601
+
+// beginAudited starts a transaction on pool holding the audit chain locks of
+// tenantIDs. A transaction recording audit rows must take its chain locks
+// before it locks any row: taken later, it could wait on the chain while
+// holding a row the transaction holding the chain is waiting on.
+func beginAudited(ctx context.Context, pool *pgxpool.Pool, tenantIDs ...int) (pgx.Tx, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockAuditChains(ctx, tx, tenantIDs...); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// rowTenantID returns the tenant of the row of table with the id. A change
+// made by ID alone reads it first, to begin its transaction holding the
+// tenant's chain lock.
+func rowTenantID(ctx context.Context, pool *pgxpool.Pool, table string, id int) (int, error) {
+	var tenantID int
+	err := pool.QueryRow(ctx, `SELECT COALESCE(tenant_id, 0) FROM `+table+` WHERE id = $1`, id).Scan(&tenantID)
+	return tenantID, err
+}
+
+// queryTenantIDs returns the tenant IDs query selects. A job changing rows
+// across tenants reads which tenants have rows due first, so it can begin
+// its transaction holding their chain locks, and then changes only the rows
+// of those tenants.
+func queryTenantIDs(ctx context.Context, pool *pgxpool.Pool, query string, args ...interface{}) ([]int, error) {
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenantIDs := []int{}
+	for rows.Next() {
+		var tenantID int
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, err
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	return tenantIDs, rows.Err()
+}
+
+// lockAuditChains takes the audit chain locks of tenantIDs in ascending
+// order, so transactions locking several chains cannot deadlock on them
+func lockAuditChains(ctx context.Context, tx pgx.Tx, tenantIDs ...int) error {
+	ids := append([]int(nil), tenantIDs...)
+	sort.Ints(ids)
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, auditChainLock, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditSecretKeys are the parts of a column name that mark it as a secret,
+// or bank details, never written to the audit log
+var auditSecretKeys = []string{"password", "secret", "token", "account_number", "iban"}
+
+func isAuditSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range auditSecretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAudit replaces the values of secret keys, at any depth, so a
+// snapshot shows that a secret was set or changed but not its value
+func redactAudit(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isAuditSecret(key) && item != nil {
+				redacted[key] = auditRedacted
+			} else {
+				redacted[key] = redactAudit(item)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactAudit(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// snapshotRow returns the row of table with the given id as JSON and locks
+// it for the rest of the transaction. It returns pgx.ErrNoRows when there is
+// no such row.
+func snapshotRow(ctx context.Context, tx pgx.Tx, table string, id int) (map[string]interface{}, error) {
+	query := `SELECT to_jsonb(t) FROM ` + table + ` t WHERE t.id = $1 FOR UPDATE`
+
+	var snapshot map[string]interface{}
+	if err := tx.QueryRow(ctx, query, id).Scan(&snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// recordAudit writes an audit row for a change made in tx, so the row is
+// only kept when the change is. The actor comes from the JWT claims and the
+// request ID and IP address from the request in ctx; changes made outside a
+// request, such as by scheduled jobs, have none. tenantID is zero for
+// changes not yet tied to a tenant.
+//
+// The row is chained to the tenant's previous row by hash. The chain is
+// locked until tx ends so concurrent changes append one after the other;
+// tx should already hold the lock, having been begun with beginAudited, and
+// taking it again here is then a no-op.
+func recordAudit(ctx context.Context, tx pgx.Tx, tenantID int, entityType string, entityID int, action string, oldData map[string]interface{}, newData map[string]interface{}) error {
+	log := &models.AuditLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		// Postgres keeps microseconds, so the time is rounded to what will
+		// be read back
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if tenantID != 0 {
+		log.TenantID = &tenantID
+	}
+	if claims, ok := middleware.GetUserClaims(ctx); ok {
+		log.ActorID = &claims.ID
+	}
+	info, _ := middleware.GetRequestInfo(ctx)
+	log.RequestID = info.RequestID
+	log.IPAddress = info.IPAddress
+
+	var oldValue, newValue interface{}
+	if oldData != nil {
+		log.OldData = redactAudit(oldData).(map[string]interface{})
+		oldValue = log.OldData
+	}
+	if newData != nil {
+		log.NewData = redactAudit(newData).(map[string]interface{})
+		newValue = log.NewData
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, auditChainLock, tenantID); err != nil {
+		return err
+	}
+	err := tx.QueryRow(ctx, `
+	SELECT COALESCE(hash, '')
+	FROM audit_logs
+	WHERE tenant_id IS NOT DISTINCT FROM NULLIF($1, 0)
+	ORDER BY id DESC
+	LIMIT 1
+	`, tenantID).Scan(&log.PrevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if log.Hash, err = log.ComputeHash(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO audit_logs (tenant_id, actor_id, entity_type, entity_id, action, old_data, new_data, request_id, ip_address, prev_hash, hash, created_at)
+	VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12)
+	`
+
+	_, err = tx.Exec(ctx, query, tenantID, log.ActorID, entityType, entityID, action, oldValue, newValue, log.RequestID, log.IPAddress, log.PrevHash, log.Hash, log.CreatedAt)
+	return err
+}
+
+// auditRow records a change to the row of table with the given id, taking
+// its new state from the database. oldData is nil for a create.
+func auditRow(ctx context.Context, tx pgx.Tx, tenantID int, entityType string, table string, id int, action string, oldData map[string]interface{}) error {
+	newData, err := snapshotRow(ctx, tx, table, id)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, tenantID, entityType, id, action, oldData, newData)
+}
+
+// deleteAuditedRows deletes the rows of table matching where, a condition
+// on args, and records the deletion of each. It returns how many rows were
+// deleted.
+func deleteAuditedRows(ctx context.Context, tx pgx.Tx, tenantID int, entityType string, table string, where string, args ...interface{}) (int, error) {
+	rows, err := tx.Query(ctx, `DELETE FROM `+table+` t WHERE `+where+` RETURNING t.id, to_jsonb(t)`, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := map[int]map[string]interface{}{}
+	var ids []int
+	for rows.Next() {
+		var id int
+		var before map[string]interface{}
+		if err := rows.Scan(&id, &before); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		deleted[id] = before
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := recordAudit(ctx, tx, tenantID, entityType, id, AuditActionDelete, deleted[id], nil); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+// updateAuditedRows applies set to the rows of table matching where, both
+// written against args, and records the update of each under the row's own
+// tenant. It returns the IDs of the updated rows.
+func updateAuditedRows(ctx context.Context, tx pgx.Tx, entityType string, table string, set string, where string, args ...interface{}) ([]int, error) {
+	query := `
+	WITH old AS (
+		SELECT o.id, to_jsonb(o) AS data
+		FROM ` + table + ` o
+		WHERE ` + where + `
+		FOR UPDATE
+	)
+	UPDATE ` + table + ` t
+	SET ` + set + `
+	FROM old
+	WHERE t.id = old.id
+	RETURNING t.id, t.tenant_id, old.data
+	`
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	type updatedRow struct {
+		id       int
+		tenantID int
+		before   map[string]interface{}
+	}
+	var updated []updatedRow
+	for rows.Next() {
+		var row updatedRow
+		if err := rows.Scan(&row.id, &row.tenantID, &row.before); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		updated = append(updated, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(updated))
+	for _, row := range updated {
+		if err := auditRow(ctx, tx, row.tenantID, entityType, table, row.id, AuditActionUpdate, row.before); err != nil {
+			return nil, err
+		}
+		ids = append(ids, row.id)
+	}
+	return ids, nil
+}
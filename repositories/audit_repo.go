@@ -0,0 +1,366 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		pool: pool,
+	}
+}
+
+// AuditLogQuery selects audit rows. Zero fields match everything; BeforeID
+// continues a listing after the row with that ID.
+type AuditLogQuery struct {
+	EntityType string
+	EntityID   int
+	ActorID    int
+	Action     string
+	From       *time.Time
+	To         *time.Time
+	BeforeID   int
+}
+
+const auditLogColumns = `id, tenant_id, actor_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = actor_id), ''),
+	entity_type, entity_id, action, old_data, new_data, COALESCE(request_id, ''), COALESCE(ip_address, ''),
+	COALESCE(prev_hash, ''), COALESCE(hash, ''), created_at`
+
+func scanAuditLog(row pgx.Row) (*models.AuditLog, error) {
+	var a models.AuditLog
+	err := row.Scan(
+		&a.ID,
+		&a.TenantID,
+		&a.ActorID,
+		&a.ActorName,
+		&a.EntityType,
+		&a.EntityID,
+		&a.Action,
+		&a.OldData,
+		&a.NewData,
+		&a.RequestID,
+		&a.IPAddress,
+		&a.PrevHash,
+		&a.Hash,
+		&a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func buildAuditLogQuery(tenantID int, q *AuditLogQuery) (string, []interface{}) {
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if q.EntityType != "" {
+		args = append(args, q.EntityType)
+		conditions += fmt.Sprintf(" AND entity_type = $%d", len(args))
+	}
+	if q.EntityID != 0 {
+		args = append(args, q.EntityID)
+		conditions += fmt.Sprintf(" AND entity_id = $%d", len(args))
+	}
+	if q.ActorID != 0 {
+		args = append(args, q.ActorID)
+		conditions += fmt.Sprintf(" AND actor_id = $%d", len(args))
+	}
+	if q.Action != "" {
+		args = append(args, q.Action)
+		conditions += fmt.Sprintf(" AND action = $%d", len(args))
+	}
+	if q.From != nil {
+		args = append(args, *q.From)
+		conditions += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if q.To != nil {
+		args = append(args, *q.To)
+		conditions += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if q.BeforeID != 0 {
+		args = append(args, q.BeforeID)
+		conditions += fmt.Sprintf(" AND id < $%d", len(args))
+	}
+	return conditions, args
+}
+
+// ListAuditLogs returns up to limit matching audit rows, newest first
+func (a *AuditRepository) ListAuditLogs(ctx context.Context, tenantID int, q *AuditLogQuery, limit int) ([]models.AuditLog, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	conditions, args := buildAuditLogQuery(tenantID, q)
+	args = append(args, limit)
+	query := `
+	SELECT ` + auditLogColumns + `
+	FROM audit_logs
+	WHERE ` + conditions + `
+	ORDER BY id DESC
+	` + fmt.Sprintf("LIMIT $%d", len(args))
+
+	rows, err := a.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []models.AuditLog{}
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *log)
+	}
+
+	return logs, rows.Err()
+}
+
+// ListEntityAuditLogs returns the audit rows of one entity, oldest first
+func (a *AuditRepository) ListEntityAuditLogs(ctx context.Context, tenantID int, entityType string, entityID int) ([]models.AuditLog, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + auditLogColumns + `
+	FROM audit_logs
+	WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3
+	ORDER BY id
+	`
+
+	rows, err := a.pool.Query(ctx, query, tenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []models.AuditLog{}
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *log)
+	}
+
+	return logs, rows.Err()
+}
+
+// StreamAuditLogs calls fn for every matching audit row, oldest first
+func (a *AuditRepository) StreamAuditLogs(ctx context.Context, tenantID int, q *AuditLogQuery, fn func(log *models.AuditLog) error) error {
+	conditions, args := buildAuditLogQuery(tenantID, q)
+	query := `
+	SELECT ` + auditLogColumns + `
+	FROM audit_logs
+	WHERE ` + conditions + `
+	ORDER BY id
+	`
+
+	rows, err := a.pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ListAuditChains returns the tenants that have audit rows, zero standing
+// for the rows without a tenant
+func (a *AuditRepository) ListAuditChains(ctx context.Context) ([]int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT DISTINCT COALESCE(tenant_id, 0)
+	FROM audit_logs
+	ORDER BY 1
+	`
+
+	rows, err := a.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenantIDs := []int{}
+	for rows.Next() {
+		var tenantID int
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, err
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	return tenantIDs, rows.Err()
+}
+
+// StreamAuditChain calls fn for every audit row of the tenant in chain
+// order. tenantID zero streams the rows without a tenant.
+func (a *AuditRepository) StreamAuditChain(ctx context.Context, tenantID int, fn func(log *models.AuditLog) error) error {
+	query := `
+	SELECT ` + auditLogColumns + `
+	FROM audit_logs
+	WHERE tenant_id IS NOT DISTINCT FROM NULLIF($1, 0)
+	ORDER BY id
+	`
+
+	rows, err := a.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+const auditCheckpointColumns = `id, COALESCE(tenant_id, 0), last_log_id, last_hash, signature, public_key, created_at`
+
+func scanAuditCheckpoint(row pgx.Row) (*models.AuditCheckpoint, error) {
+	var c models.AuditCheckpoint
+	err := row.Scan(
+		&c.ID,
+		&c.TenantID,
+		&c.LastLogID,
+		&c.LastHash,
+		&c.Signature,
+		&c.PublicKey,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListUncheckpointedHeads returns, for every chain with hashed rows added
+// since its last checkpoint, an unsigned checkpoint of its newest row
+func (a *AuditRepository) ListUncheckpointedHeads(ctx context.Context) ([]models.AuditCheckpoint, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT head.tenant_id, head.id, head.hash
+	FROM (
+		SELECT DISTINCT ON (COALESCE(tenant_id, 0)) COALESCE(tenant_id, 0) AS tenant_id, id, hash
+		FROM audit_logs
+		WHERE hash IS NOT NULL
+		ORDER BY COALESCE(tenant_id, 0), id DESC
+	) head
+	WHERE head.id > COALESCE((
+		SELECT MAX(c.last_log_id) FROM audit_checkpoints c
+		WHERE COALESCE(c.tenant_id, 0) = head.tenant_id
+	), 0)
+	ORDER BY head.tenant_id
+	`
+
+	rows, err := a.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	heads := []models.AuditCheckpoint{}
+	for rows.Next() {
+		var c models.AuditCheckpoint
+		if err := rows.Scan(&c.TenantID, &c.LastLogID, &c.LastHash); err != nil {
+			return nil, err
+		}
+		heads = append(heads, c)
+	}
+
+	return heads, rows.Err()
+}
+
+func (a *AuditRepository) CreateCheckpoint(ctx context.Context, c *models.AuditCheckpoint) (*models.AuditCheckpoint, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO audit_checkpoints (tenant_id, last_log_id, last_hash, signature, public_key, created_at)
+	VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6)
+	RETURNING ` + auditCheckpointColumns
+
+	return scanAuditCheckpoint(a.pool.QueryRow(ctx, query, c.TenantID, c.LastLogID, c.LastHash, c.Signature, c.PublicKey, c.CreatedAt))
+}
+
+// ListCheckpoints returns the checkpoints of the tenant's chain, oldest
+// first. tenantID zero lists those of the rows without a tenant.
+func (a *AuditRepository) ListCheckpoints(ctx context.Context, tenantID int) ([]models.AuditCheckpoint, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + auditCheckpointColumns + `
+	FROM audit_checkpoints
+	WHERE tenant_id IS NOT DISTINCT FROM NULLIF($1, 0)
+	ORDER BY id
+	`
+
+	rows, err := a.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checkpoints := []models.AuditCheckpoint{}
+	for rows.Next() {
+		c, err := scanAuditCheckpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, *c)
+	}
+
+	return checkpoints, rows.Err()
+}
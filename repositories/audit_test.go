@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeQuery matches SQL that changes rows, capturing the table
+var writeQuery = regexp.MustCompile(`(?is)\b(?:INSERT\s+INTO|DELETE\s+FROM)\s+(\w+)|\bUPDATE\s+(\w+)(?:\s+(?:AS\s+)?\w+)?\s+SET\b`)
+
+// unauditedTables are the tables of the audit log itself
+var unauditedTables = map[string]bool{"audit_logs": true, "audit_checkpoints": true}
+
+// unauditedWrites are the writes a function may leave out of the audit log,
+// by function and table. Reading a calendar feed only stamps when it was
+// last read, which nobody changed and every feed poll would otherwise log
+var unauditedWrites = map[string]string{"LeaveCalendarRepository.UseCalendarFeed": "calendar_feeds"}
+
+// auditCalls are the audit helpers and the position of their table argument
+var auditCalls = map[string]int{"snapshotRow": 2, "auditRow": 4, "deleteAuditedRows": 4, "updateAuditedRows": 3}
+
+// repoFunc is a function or method of the package as seen by the audit test
+type repoFunc struct {
+	name    string
+	writes  map[string]bool
+	audited map[string]bool
+	calls   []string
+	// begins is set when the function begins a transaction on its
+	// repository's pool itself rather than through beginAudited
+	begins bool
+}
+
+// funcKey names a function, qualifying a method with its receiver type so
+// methods of different repositories with the same name are told apart
+func funcKey(decl *ast.FuncDecl) (key string, recvType string) {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name, ""
+	}
+	recv := decl.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + decl.Name.Name, ident.Name
+	}
+	return decl.Name.Name, ""
+}
+
+func parseRepoFuncs(t *testing.T) map[string]*repoFunc {
+	t.Helper()
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funcs := map[string]*repoFunc{}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, d := range file.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok || decl.Body == nil {
+				continue
+			}
+			key, recvType := funcKey(decl)
+			fn := &repoFunc{name: key, writes: map[string]bool{}, audited: map[string]bool{}}
+			recvName := ""
+			if recvType != "" && len(decl.Recv.List[0].Names) > 0 {
+				recvName = decl.Recv.List[0].Names[0].Name
+			}
+
+			ast.Inspect(decl.Body, func(n ast.Node) bool {
+				switch node := n.(type) {
+				case *ast.BasicLit:
+					if node.Kind != token.STRING {
+						return true
+					}
+					query, err := strconv.Unquote(node.Value)
+					if err != nil {
+						return true
+					}
+					for _, match := range writeQuery.FindAllStringSubmatch(query, -1) {
+						if table := match[1] + match[2]; !unauditedTables[table] {
+							fn.writes[table] = true
+						}
+					}
+				case *ast.CallExpr:
+					switch callee := node.Fun.(type) {
+					case *ast.Ident:
+						fn.calls = append(fn.calls, callee.Name)
+						if i, ok := auditCalls[callee.Name]; ok && i < len(node.Args) {
+							if lit, ok := node.Args[i].(*ast.BasicLit); ok {
+								table, _ := strconv.Unquote(lit.Value)
+								fn.audited[table] = true
+							}
+						}
+					case *ast.SelectorExpr:
+						if x, ok := callee.X.(*ast.Ident); ok && x.Name == recvName {
+							fn.calls = append(fn.calls, recvType+"."+callee.Sel.Name)
+						}
+						if pool, ok := callee.X.(*ast.SelectorExpr); ok && pool.Sel.Name == "pool" && callee.Sel.Name == "Begin" {
+							fn.begins = true
+						}
+					}
+				}
+				return true
+			})
+			funcs[fn.name] = fn
+		}
+	}
+	return funcs
+}
+
+// TestMutatingRepositoryMethodsAudit checks that every table a function
+// writes to is audited in that function, a function it calls or every
+// function calling it, so each change shares its audit row's transaction
+func TestMutatingRepositoryMethodsAudit(t *testing.T) {
+	funcs := parseRepoFuncs(t)
+	if _, ok := funcs["CompanyRepository.CreateCompany"]; !ok {
+		t.Fatal("got no repository methods, want the package parsed")
+	}
+
+	callers := map[string][]string{}
+	for _, fn := range funcs {
+		for _, callee := range fn.calls {
+			callers[callee] = append(callers[callee], fn.name)
+		}
+	}
+
+	var audits func(name string, table string, seen map[string]bool) bool
+	audits = func(name string, table string, seen map[string]bool) bool {
+		fn, ok := funcs[name]
+		if !ok || seen[name] {
+			return false
+		}
+		seen[name] = true
+		if fn.audited[table] {
+			return true
+		}
+		for _, callee := range fn.calls {
+			if audits(callee, table, seen) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var unaudited []string
+	for name, fn := range funcs {
+		for table := range fn.writes {
+			if unauditedWrites[name] == table {
+				continue
+			}
+			if audits(name, table, map[string]bool{}) {
+				continue
+			}
+			audited := len(callers[name]) > 0
+			for _, caller := range callers[name] {
+				if !audits(caller, table, map[string]bool{}) {
+					audited = false
+				}
+			}
+			if !audited {
+				unaudited = append(unaudited, name+" writes to "+table)
+			}
+		}
+	}
+	sort.Strings(unaudited)
+
+	for _, write := range unaudited {
+		t.Errorf("%s without recording an audit row", write)
+	}
+}
+
+// TestAuditedTransactionsLockChainFirst checks that every transaction that
+// records an audit row is begun with beginAudited, so the tenant's audit
+// chain lock is taken before any row lock and never waited on while holding
+// one
+func TestAuditedTransactionsLockChainFirst(t *testing.T) {
+	funcs := parseRepoFuncs(t)
+
+	var records func(name string, seen map[string]bool) bool
+	records = func(name string, seen map[string]bool) bool {
+		fn, ok := funcs[name]
+		if !ok || seen[name] {
+			return false
+		}
+		seen[name] = true
+		for _, callee := range fn.calls {
+			if callee == "recordAudit" || records(callee, seen) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var unlocked []string
+	for name, fn := range funcs {
+		if fn.begins && records(name, map[string]bool{}) {
+			unlocked = append(unlocked, name)
+		}
+	}
+	sort.Strings(unlocked)
+
+	for _, name := range unlocked {
+		t.Errorf("%s records audit rows in a transaction not begun with beginAudited", name)
+	}
+}
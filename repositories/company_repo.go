@@ -2,9 +2,11 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/falasefemi2/peopleos/dto"
@@ -28,16 +30,22 @@ func (c *CompanyRepository) CreateCompany(ctx context.Context, company *models.C
 		defer cancel()
 	}
 
+	tx, err := beginAudited(ctx, c.pool, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 	INSERT INTO companies (name, industry, country, timezone)
 	VALUES ($1, $2, $3, $4)
 	RETURNING id, name, industry, country, timezone, created_at, updated_at
 	`
 
-	row := c.pool.QueryRow(ctx, query, company.Name, company.Industry, company.Country, company.Timezone)
+	row := tx.QueryRow(ctx, query, company.Name, company.Industry, company.Country, company.Timezone)
 
 	var createdCompany models.Company
-	err := row.Scan(
+	err = row.Scan(
 		&createdCompany.ID,
 		&createdCompany.Name,
 		&createdCompany.Industry,
@@ -51,6 +59,14 @@ func (c *CompanyRepository) CreateCompany(ctx context.Context, company *models.C
 		return nil, err
 	}
 
+	// The company has no tenant until one is created for it
+	if err := auditRow(ctx, tx, 0, AuditEntityCompany, "companies", createdCompany.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &createdCompany, nil
 }
 
@@ -127,6 +143,22 @@ func (c *CompanyRepository) UpdateCompany(ctx context.Context, companyID int, re
 		defer cancel()
 	}
 
+	tenantID, err := c.companyTenantID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := beginAudited(ctx, c.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "companies", companyID)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 	UPDATE companies
 	SET name = $1, industry = $2, country = $3, timezone = $4, updated_at = CURRENT_TIMESTAMP
@@ -134,10 +166,10 @@ func (c *CompanyRepository) UpdateCompany(ctx context.Context, companyID int, re
 	RETURNING id, name, industry, country, timezone, created_at, updated_at
 	`
 
-	row := c.pool.QueryRow(ctx, query, request.Name, request.Industry, request.Country, request.Timezone, companyID)
+	row := tx.QueryRow(ctx, query, request.Name, request.Industry, request.Country, request.Timezone, companyID)
 
 	var updatedCompany models.Company
-	err := row.Scan(
+	err = row.Scan(
 		&updatedCompany.ID,
 		&updatedCompany.Name,
 		&updatedCompany.Industry,
@@ -151,9 +183,26 @@ func (c *CompanyRepository) UpdateCompany(ctx context.Context, companyID int, re
 		return nil, err
 	}
 
+	if err := auditRow(ctx, tx, tenantID, AuditEntityCompany, "companies", companyID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &updatedCompany, nil
 }
 
+// companyTenantID returns the tenant of the company, or zero before it has one
+func (c *CompanyRepository) companyTenantID(ctx context.Context, companyID int) (int, error) {
+	var tenantID int
+	err := c.pool.QueryRow(ctx, `SELECT id FROM tenants WHERE company_id = $1`, companyID).Scan(&tenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return tenantID, err
+}
+
 func (c *CompanyRepository) DeleteCompany(ctx context.Context, companyID int) error {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -161,12 +210,32 @@ func (c *CompanyRepository) DeleteCompany(ctx context.Context, companyID int) er
 		defer cancel()
 	}
 
+	tx, err := beginAudited(ctx, c.pool, 0)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "companies", companyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("company not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	// The tenant and everything in it go with the company, so the record of
+	// the deletion belongs to no tenant
+	if err := recordAudit(ctx, tx, 0, AuditEntityCompany, companyID, AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+
 	query := `
 	DELETE FROM companies
 	WHERE id = $1
 	`
 
-	result, err := c.pool.Exec(ctx, query, companyID)
+	result, err := tx.Exec(ctx, query, companyID)
 	if err != nil {
 		return err
 	}
@@ -175,5 +244,39 @@ func (c *CompanyRepository) DeleteCompany(ctx context.Context, companyID int) er
 		return fmt.Errorf("company not found")
 	}
 
-	return nil
+	return tx.Commit(ctx)
+}
+
+func (c *CompanyRepository) GetCompanyByTenantID(ctx context.Context, tenantID int) (*models.Company, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT c.id, c.name, COALESCE(c.industry, ''), COALESCE(c.country, ''), COALESCE(c.timezone, ''), c.created_at, c.updated_at
+	FROM companies c
+	JOIN tenants t ON t.company_id = c.id
+	WHERE t.id = $1
+	`
+
+	row := c.pool.QueryRow(ctx, query, tenantID)
+
+	var company models.Company
+	err := row.Scan(
+		&company.ID,
+		&company.Name,
+		&company.Industry,
+		&company.Country,
+		&company.Timezone,
+		&company.CreatedAt,
+		&company.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &company, nil
 }
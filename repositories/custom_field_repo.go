@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type CustomFieldRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCustomFieldRepository(pool *pgxpool.Pool) *CustomFieldRepository {
+	return &CustomFieldRepository{
+		pool: pool,
+	}
+}
+
+const customFieldColumns = `id, tenant_id, entity_type, field_key, label, field_type, COALESCE(required, FALSE), options, COALESCE(rules, '{}'::jsonb), COALESCE(visibility, 'everyone'), COALESCE(sort_order, 0), created_at, updated_at`
+
+func scanCustomField(row pgx.Row) (*models.CustomFieldDefinition, error) {
+	var field models.CustomFieldDefinition
+	err := row.Scan(
+		&field.ID,
+		&field.TenantID,
+		&field.EntityType,
+		&field.Key,
+		&field.Label,
+		&field.FieldType,
+		&field.Required,
+		&field.Options,
+		&field.Rules,
+		&field.Visibility,
+		&field.SortOrder,
+		&field.CreatedAt,
+		&field.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+func (c *CustomFieldRepository) ListCustomFields(ctx context.Context, tenantID int, entityType string) ([]models.CustomFieldDefinition, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + customFieldColumns + `
+	FROM custom_field_definitions
+	WHERE tenant_id = $1 AND entity_type = $2
+	ORDER BY sort_order, id
+	`
+
+	rows, err := c.pool.Query(ctx, query, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []models.CustomFieldDefinition{}
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, *field)
+	}
+
+	return fields, rows.Err()
+}
+
+func (c *CustomFieldRepository) GetCustomFieldByID(ctx context.Context, tenantID int, id int) (*models.CustomFieldDefinition, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + customFieldColumns + `
+	FROM custom_field_definitions
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := c.pool.QueryRow(ctx, query, tenantID, id)
+	return scanCustomField(row)
+}
+
+func (c *CustomFieldRepository) CreateCustomField(ctx context.Context, field *models.CustomFieldDefinition) (*models.CustomFieldDefinition, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO custom_field_definitions (tenant_id, entity_type, field_key, label, field_type, required, options, rules, visibility, sort_order)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	RETURNING ` + customFieldColumns
+
+	tx, err := beginAudited(ctx, c.pool, field.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query, field.TenantID, field.EntityType, field.Key, field.Label, field.FieldType, field.Required, field.Options, field.Rules, field.Visibility, field.SortOrder)
+	created, err := scanCustomField(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityCustomField, "custom_field_definitions", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// UpdateCustomField saves everything except the key and type, which are fixed
+// once values may have been stored against them.
+func (c *CustomFieldRepository) UpdateCustomField(ctx context.Context, field *models.CustomFieldDefinition) (*models.CustomFieldDefinition, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE custom_field_definitions
+	SET label = $1, required = $2, options = $3, rules = $4, visibility = $5, sort_order = $6, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $7 AND id = $8
+	RETURNING ` + customFieldColumns
+
+	tx, err := beginAudited(ctx, c.pool, field.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "custom_field_definitions", field.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query, field.Label, field.Required, field.Options, field.Rules, field.Visibility, field.SortOrder, field.TenantID, field.ID)
+	updated, err := scanCustomField(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, updated.TenantID, AuditEntityCustomField, "custom_field_definitions", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeleteCustomField removes the definition and strips its stored values from
+// every employee in the tenant.
+func (c *CustomFieldRepository) DeleteCustomField(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, c.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "custom_field_definitions", id)
+	if err != nil {
+		return err
+	}
+
+	deleteQuery := `
+	DELETE FROM custom_field_definitions
+	WHERE tenant_id = $1 AND id = $2
+	RETURNING field_key
+	`
+
+	var key string
+	if err := tx.QueryRow(ctx, deleteQuery, tenantID, id).Scan(&key); err != nil {
+		return err
+	}
+
+	if err := recordAudit(ctx, tx, tenantID, AuditEntityCustomField, id, AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+
+	stripSet := `custom_fields = custom_fields - $1::text`
+	stripWhere := `tenant_id = $2 AND custom_fields ? $1::text`
+
+	if _, err := updateAuditedRows(ctx, tx, AuditEntityEmployee, "employees", stripSet, stripWhere, key, tenantID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -25,16 +25,22 @@ func (d *DepartmentRepository) CreateDepartment(ctx context.Context, department
 		defer cancel()
 	}
 
+	tx, err := beginAudited(ctx, d.pool, department.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 	INSERT INTO departments (tenant_id, name, status)
 	VALUES ($1, $2, $3)
 	RETURNING id, tenant_id, name, hod_id, status, created_at, updated_at
 	`
 
-	row := d.pool.QueryRow(ctx, query, department.TenantID, department.Name, department.Status)
+	row := tx.QueryRow(ctx, query, department.TenantID, department.Name, department.Status)
 
 	var createdDepartment models.Department
-	err := row.Scan(
+	err = row.Scan(
 		&createdDepartment.ID,
 		&createdDepartment.TenantID,
 		&createdDepartment.Name,
@@ -48,6 +54,13 @@ func (d *DepartmentRepository) CreateDepartment(ctx context.Context, department
 		return nil, err
 	}
 
+	if err := auditRow(ctx, tx, createdDepartment.TenantID, AuditEntityDepartment, "departments", createdDepartment.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &createdDepartment, nil
 }
 
@@ -91,6 +104,17 @@ func (d *DepartmentRepository) UpdateDepartment(ctx context.Context, departmentI
 		defer cancel()
 	}
 
+	tx, err := beginAudited(ctx, d.pool, department.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "departments", departmentID)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 	UPDATE departments
 	SET name = $1, hod_id = $2, status = $3, updated_at = CURRENT_TIMESTAMP
@@ -98,10 +122,10 @@ func (d *DepartmentRepository) UpdateDepartment(ctx context.Context, departmentI
 	RETURNING id, tenant_id, name, hod_id, status, created_at, updated_at
 	`
 
-	row := d.pool.QueryRow(ctx, query, department.Name, department.HodID, department.Status, departmentID)
+	row := tx.QueryRow(ctx, query, department.Name, department.HodID, department.Status, departmentID)
 
 	var updatedDepartment models.Department
-	err := row.Scan(
+	err = row.Scan(
 		&updatedDepartment.ID,
 		&updatedDepartment.TenantID,
 		&updatedDepartment.Name,
@@ -115,6 +139,13 @@ func (d *DepartmentRepository) UpdateDepartment(ctx context.Context, departmentI
 		return nil, err
 	}
 
+	if err := auditRow(ctx, tx, updatedDepartment.TenantID, AuditEntityDepartment, "departments", departmentID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &updatedDepartment, nil
 }
 
@@ -125,19 +156,35 @@ func (d *DepartmentRepository) DeleteDepartment(ctx context.Context, departmentI
 		defer cancel()
 	}
 
+	tenantID, err := rowTenantID(ctx, d.pool, "departments", departmentID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := beginAudited(ctx, d.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "departments", departmentID)
+	if err != nil {
+		return err
+	}
+
 	query := `
 	DELETE FROM departments
 	WHERE id = $1
+	RETURNING tenant_id
 	`
 
-	result, err := d.pool.Exec(ctx, query, departmentID)
-	if err != nil {
+	if err := tx.QueryRow(ctx, query, departmentID).Scan(&tenantID); err != nil {
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return nil
+	if err := recordAudit(ctx, tx, tenantID, AuditEntityDepartment, departmentID, AuditActionDelete, before, nil); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit(ctx)
 }
@@ -25,16 +25,22 @@ func (d *DesignationRepository) CreateDesignation(ctx context.Context, designati
 		defer cancel()
 	}
 
+	tx, err := beginAudited(ctx, d.pool, designation.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 	INSERT INTO designations (tenant_id, name, level, description)
 	VALUES ($1, $2, $3, $4)
 	RETURNING id, tenant_id, name, level, description, created_at, updated_at
 	`
 
-	row := d.pool.QueryRow(ctx, query, designation.TenantID, designation.Name, designation.Level, designation.Description)
+	row := tx.QueryRow(ctx, query, designation.TenantID, designation.Name, designation.Level, designation.Description)
 
 	var createdDesignation models.Designation
-	err := row.Scan(
+	err = row.Scan(
 		&createdDesignation.ID,
 		&createdDesignation.TenantID,
 		&createdDesignation.Name,
@@ -48,6 +54,13 @@ func (d *DesignationRepository) CreateDesignation(ctx context.Context, designati
 		return nil, err
 	}
 
+	if err := auditRow(ctx, tx, createdDesignation.TenantID, AuditEntityDesignation, "designations", createdDesignation.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &createdDesignation, nil
 }
 
@@ -91,6 +104,17 @@ func (d *DesignationRepository) UpdateDesignation(ctx context.Context, designati
 		defer cancel()
 	}
 
+	tx, err := beginAudited(ctx, d.pool, designation.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "designations", designationID)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 	UPDATE designations
 	SET name = $1, level = $2, description = $3, updated_at = CURRENT_TIMESTAMP
@@ -98,10 +122,10 @@ func (d *DesignationRepository) UpdateDesignation(ctx context.Context, designati
 	RETURNING id, tenant_id, name, level, description, created_at, updated_at
 	`
 
-	row := d.pool.QueryRow(ctx, query, designation.Name, designation.Level, designation.Description, designationID)
+	row := tx.QueryRow(ctx, query, designation.Name, designation.Level, designation.Description, designationID)
 
 	var updatedDesignation models.Designation
-	err := row.Scan(
+	err = row.Scan(
 		&updatedDesignation.ID,
 		&updatedDesignation.TenantID,
 		&updatedDesignation.Name,
@@ -115,6 +139,13 @@ func (d *DesignationRepository) UpdateDesignation(ctx context.Context, designati
 		return nil, err
 	}
 
+	if err := auditRow(ctx, tx, updatedDesignation.TenantID, AuditEntityDesignation, "designations", designationID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &updatedDesignation, nil
 }
 
@@ -125,19 +156,35 @@ func (d *DesignationRepository) DeleteDesignation(ctx context.Context, designati
 		defer cancel()
 	}
 
+	tenantID, err := rowTenantID(ctx, d.pool, "designations", designationID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := beginAudited(ctx, d.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "designations", designationID)
+	if err != nil {
+		return err
+	}
+
 	query := `
 	DELETE FROM designations
 	WHERE id = $1
+	RETURNING tenant_id
 	`
 
-	result, err := d.pool.Exec(ctx, query, designationID)
-	if err != nil {
+	if err := tx.QueryRow(ctx, query, designationID).Scan(&tenantID); err != nil {
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return nil
+	if err := recordAudit(ctx, tx, tenantID, AuditEntityDesignation, designationID, AuditActionDelete, before, nil); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit(ctx)
 }
@@ -0,0 +1,311 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type DisciplinaryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDisciplinaryRepository(pool *pgxpool.Pool) *DisciplinaryRepository {
+	return &DisciplinaryRepository{
+		pool: pool,
+	}
+}
+
+const disciplinaryCaseColumns = `id, tenant_id, employee_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = employee_id), ''),
+	title, COALESCE(description, ''), stage, status, hearing_at, COALESCE(hearing_location, ''),
+	COALESCE(outcome, ''), COALESCE(outcome_notes, ''), opened_by, closed_at, created_at, updated_at`
+
+func scanDisciplinaryCase(row pgx.Row) (*models.DisciplinaryCase, error) {
+	var c models.DisciplinaryCase
+	err := row.Scan(
+		&c.ID,
+		&c.TenantID,
+		&c.EmployeeID,
+		&c.EmployeeName,
+		&c.Title,
+		&c.Description,
+		&c.Stage,
+		&c.Status,
+		&c.HearingAt,
+		&c.HearingLocation,
+		&c.Outcome,
+		&c.OutcomeNotes,
+		&c.OpenedBy,
+		&c.ClosedAt,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListCases returns the tenant's disciplinary cases matching filter, newest
+// first, without their events
+func (d *DisciplinaryRepository) ListCases(ctx context.Context, tenantID int, filter *dto.DisciplinaryCaseFilter) ([]models.DisciplinaryCase, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter.EmployeeID != 0 {
+		args = append(args, filter.EmployeeID)
+		conditions += fmt.Sprintf(" AND employee_id = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.Stage != "" {
+		args = append(args, filter.Stage)
+		conditions += fmt.Sprintf(" AND stage = $%d", len(args))
+	}
+
+	query := `
+	SELECT ` + disciplinaryCaseColumns + `
+	FROM disciplinary_cases
+	WHERE ` + conditions + `
+	ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := d.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cases := []models.DisciplinaryCase{}
+	for rows.Next() {
+		c, err := scanDisciplinaryCase(rows)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, *c)
+	}
+
+	return cases, rows.Err()
+}
+
+func (d *DisciplinaryRepository) listEvents(ctx context.Context, caseID int) ([]models.DisciplinaryCaseEvent, error) {
+	query := `
+	SELECT id, case_id, stage, COALESCE(note, ''), recorded_by,
+		COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = recorded_by), ''),
+		created_at
+	FROM disciplinary_case_events
+	WHERE case_id = $1
+	ORDER BY created_at, id
+	`
+
+	rows, err := d.pool.Query(ctx, query, caseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.DisciplinaryCaseEvent{}
+	for rows.Next() {
+		var event models.DisciplinaryCaseEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.CaseID,
+			&event.Stage,
+			&event.Note,
+			&event.RecordedBy,
+			&event.RecordedByName,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetCase returns a disciplinary case with the events of its stages
+func (d *DisciplinaryRepository) GetCase(ctx context.Context, tenantID int, id int) (*models.DisciplinaryCase, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + disciplinaryCaseColumns + `
+	FROM disciplinary_cases
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	c, err := scanDisciplinaryCase(d.pool.QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		return nil, err
+	}
+	if c.Events, err = d.listEvents(ctx, c.ID); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func insertCaseEvent(ctx context.Context, tx pgx.Tx, tenantID int, caseID int, stage string, note string, recordedBy int) error {
+	query := `
+	INSERT INTO disciplinary_case_events (case_id, stage, note, recorded_by)
+	VALUES ($1, $2, NULLIF($3, ''), $4)
+	RETURNING id
+	`
+
+	var id int
+	if err := tx.QueryRow(ctx, query, caseID, stage, note, recordedBy).Scan(&id); err != nil {
+		return err
+	}
+	return auditRow(ctx, tx, tenantID, AuditEntityDisciplinaryCaseEvent, "disciplinary_case_events", id, AuditActionCreate, nil)
+}
+
+// linkCaseMemos links memos about the case's employee that belong to no
+// other case. It returns pgx.ErrNoRows when any of them could not be linked.
+func linkCaseMemos(ctx context.Context, tx pgx.Tx, tenantID int, caseID int, memoIDs []int) error {
+	if len(memoIDs) == 0 {
+		return nil
+	}
+
+	set := `case_id = $2, updated_at = CURRENT_TIMESTAMP`
+	where := `tenant_id = $1 AND id = ANY($3) AND (case_id IS NULL OR case_id = $2)
+	  AND employee_id = (SELECT employee_id FROM disciplinary_cases WHERE tenant_id = $1 AND id = $2)`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityMemo, "memos", set, where, tenantID, caseID, memoIDs)
+	if err != nil {
+		return err
+	}
+	if len(ids) != len(memoIDs) {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// CreateCase opens a case at its first stage and links the memos to it. It
+// returns pgx.ErrNoRows when a memo is not about the employee or belongs to
+// another case.
+func (d *DisciplinaryRepository) CreateCase(ctx context.Context, c *models.DisciplinaryCase, memoIDs []int, note string) (*models.DisciplinaryCase, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, d.pool, c.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO disciplinary_cases (tenant_id, employee_id, title, description, stage, status, opened_by)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+	RETURNING id
+	`
+
+	var id int
+	err = tx.QueryRow(ctx, query, c.TenantID, c.EmployeeID, c.Title, c.Description, c.Stage, c.Status, c.OpenedBy).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auditRow(ctx, tx, c.TenantID, AuditEntityDisciplinaryCase, "disciplinary_cases", id, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+	if err := insertCaseEvent(ctx, tx, c.TenantID, id, c.Stage, note, *c.OpenedBy); err != nil {
+		return nil, err
+	}
+	if err := linkCaseMemos(ctx, tx, c.TenantID, id, memoIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return d.GetCase(ctx, c.TenantID, id)
+}
+
+// RecordStage saves the case at its new stage and records the event. It
+// returns pgx.ErrNoRows when the case has moved on from fromStage or closed
+// since it was read.
+func (d *DisciplinaryRepository) RecordStage(ctx context.Context, c *models.DisciplinaryCase, fromStage string, note string, recordedBy int) (*models.DisciplinaryCase, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, d.pool, c.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `stage = $4, status = $5, hearing_at = $6, hearing_location = NULLIF($7, ''), outcome = NULLIF($8, ''), outcome_notes = NULLIF($9, ''),
+		closed_at = CASE WHEN $5 = 'closed' THEN CURRENT_TIMESTAMP END, updated_at = CURRENT_TIMESTAMP`
+	where := `tenant_id = $1 AND id = $2 AND stage = $3 AND status = 'open'`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityDisciplinaryCase, "disciplinary_cases", set, where,
+		c.TenantID,
+		c.ID,
+		fromStage,
+		c.Stage,
+		c.Status,
+		c.HearingAt,
+		c.HearingLocation,
+		c.Outcome,
+		c.OutcomeNotes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	if err := insertCaseEvent(ctx, tx, c.TenantID, c.ID, c.Stage, note, recordedBy); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return d.GetCase(ctx, c.TenantID, c.ID)
+}
+
+// LinkMemo links a memo about the case's employee to the case. It returns
+// pgx.ErrNoRows when the memo is about someone else or belongs to another
+// case.
+func (d *DisciplinaryRepository) LinkMemo(ctx context.Context, tenantID int, caseID int, memoID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, d.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := linkCaseMemos(ctx, tx, tenantID, caseID, []int{memoID}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,936 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type DocumentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDocumentRepository(pool *pgxpool.Pool) *DocumentRepository {
+	return &DocumentRepository{
+		pool: pool,
+	}
+}
+
+const documentTypeColumns = `id, tenant_id, name, COALESCE(description, ''), COALESCE(allowed_content_types, '{}'), max_size_bytes,
+	COALESCE(expiry_reminder_days, 30), COALESCE(status, 'active'), created_at, updated_at`
+
+func scanDocumentType(row pgx.Row) (*models.DocumentType, error) {
+	var documentType models.DocumentType
+	err := row.Scan(
+		&documentType.ID,
+		&documentType.TenantID,
+		&documentType.Name,
+		&documentType.Description,
+		&documentType.AllowedContentTypes,
+		&documentType.MaxSizeBytes,
+		&documentType.ExpiryReminderDays,
+		&documentType.Status,
+		&documentType.CreatedAt,
+		&documentType.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &documentType, nil
+}
+
+const documentColumns = `id, tenant_id, employee_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = employee_id), ''),
+	document_type_id, document_type, COALESCE(current_version, 1), COALESCE(file_path, ''), COALESCE(file_name, ''),
+	COALESCE(content_type, 'application/octet-stream'), COALESCE(size_bytes, 0), COALESCE(checksum, ''), issue_date, expiry_date,
+	COALESCE((SELECT dt.expiry_reminder_days FROM document_types dt WHERE dt.id = document_type_id), 30), uploaded_by,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = uploaded_by), ''),
+	created_at, updated_at`
+
+func scanDocument(row pgx.Row) (*models.Document, error) {
+	var document models.Document
+	err := row.Scan(
+		&document.ID,
+		&document.TenantID,
+		&document.EmployeeID,
+		&document.EmployeeName,
+		&document.DocumentTypeID,
+		&document.DocumentType,
+		&document.CurrentVersion,
+		&document.StorageKey,
+		&document.FileName,
+		&document.ContentType,
+		&document.SizeBytes,
+		&document.Checksum,
+		&document.IssueDate,
+		&document.ExpiryDate,
+		&document.ExpiryReminderDays,
+		&document.UploadedBy,
+		&document.UploadedByName,
+		&document.CreatedAt,
+		&document.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+const documentVersionColumns = `id, tenant_id, document_id, version, file_path, file_name, content_type, size_bytes, checksum,
+	issue_date, expiry_date, uploaded_by,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = uploaded_by), ''),
+	created_at`
+
+func scanDocumentVersion(row pgx.Row) (*models.DocumentVersion, error) {
+	var version models.DocumentVersion
+	err := row.Scan(
+		&version.ID,
+		&version.TenantID,
+		&version.DocumentID,
+		&version.Version,
+		&version.StorageKey,
+		&version.FileName,
+		&version.ContentType,
+		&version.SizeBytes,
+		&version.Checksum,
+		&version.IssueDate,
+		&version.ExpiryDate,
+		&version.UploadedBy,
+		&version.UploadedByName,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+const documentRequirementColumns = `id, tenant_id, document_type_id,
+	COALESCE((SELECT dt.name FROM document_types dt WHERE dt.id = document_type_id), ''),
+	designation_id, COALESCE((SELECT ds.name FROM designations ds WHERE ds.id = designation_id), ''),
+	COALESCE(country, ''), created_at`
+
+func scanDocumentRequirement(row pgx.Row) (*models.DocumentRequirement, error) {
+	var requirement models.DocumentRequirement
+	err := row.Scan(
+		&requirement.ID,
+		&requirement.TenantID,
+		&requirement.DocumentTypeID,
+		&requirement.DocumentType,
+		&requirement.DesignationID,
+		&requirement.DesignationName,
+		&requirement.Country,
+		&requirement.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &requirement, nil
+}
+
+func (d *DocumentRepository) listDocuments(ctx context.Context, query string, args ...interface{}) ([]models.Document, error) {
+	rows, err := d.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []models.Document{}
+	for rows.Next() {
+		document, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, *document)
+	}
+
+	return documents, rows.Err()
+}
+
+// ListDocumentTypes returns the tenant's document types, optionally filtered
+// by status.
+func (d *DocumentRepository) ListDocumentTypes(ctx context.Context, tenantID int, status string) ([]models.DocumentType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + documentTypeColumns + `
+	FROM document_types
+	WHERE tenant_id = $1 AND ($2 = '' OR COALESCE(status, 'active') = $2)
+	ORDER BY name, id
+	`
+
+	rows, err := d.pool.Query(ctx, query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documentTypes := []models.DocumentType{}
+	for rows.Next() {
+		documentType, err := scanDocumentType(rows)
+		if err != nil {
+			return nil, err
+		}
+		documentTypes = append(documentTypes, *documentType)
+	}
+
+	return documentTypes, rows.Err()
+}
+
+func (d *DocumentRepository) GetDocumentType(ctx context.Context, tenantID int, id int) (*models.DocumentType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + documentTypeColumns + `
+	FROM document_types
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := d.pool.QueryRow(ctx, query, tenantID, id)
+	return scanDocumentType(row)
+}
+
+func (d *DocumentRepository) CreateDocumentType(ctx context.Context, documentType *models.DocumentType) (*models.DocumentType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO document_types (tenant_id, name, description, allowed_content_types, max_size_bytes, expiry_reminder_days, status)
+	VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+	RETURNING ` + documentTypeColumns
+
+	tx, err := beginAudited(ctx, d.pool, documentType.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query,
+		documentType.TenantID,
+		documentType.Name,
+		documentType.Description,
+		documentType.AllowedContentTypes,
+		documentType.MaxSizeBytes,
+		documentType.ExpiryReminderDays,
+		documentType.Status,
+	)
+	created, err := scanDocumentType(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, documentType.TenantID, AuditEntityDocumentType, "document_types", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (d *DocumentRepository) UpdateDocumentType(ctx context.Context, documentType *models.DocumentType) (*models.DocumentType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE document_types
+	SET name = $1, description = NULLIF($2, ''), allowed_content_types = $3, max_size_bytes = $4, expiry_reminder_days = $5,
+		status = $6, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $7 AND id = $8
+	RETURNING ` + documentTypeColumns
+
+	tx, err := beginAudited(ctx, d.pool, documentType.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "document_types", documentType.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query,
+		documentType.Name,
+		documentType.Description,
+		documentType.AllowedContentTypes,
+		documentType.MaxSizeBytes,
+		documentType.ExpiryReminderDays,
+		documentType.Status,
+		documentType.TenantID,
+		documentType.ID,
+	)
+	updated, err := scanDocumentType(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, documentType.TenantID, AuditEntityDocumentType, "document_types", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// ListEmployeeDocuments returns the documents of an employee, newest first
+func (d *DocumentRepository) ListEmployeeDocuments(ctx context.Context, tenantID int, employeeID int) ([]models.Document, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + documentColumns + `
+	FROM documents
+	WHERE tenant_id = $1 AND employee_id = $2
+	ORDER BY created_at DESC, id DESC
+	`
+
+	return d.listDocuments(ctx, query, tenantID, employeeID)
+}
+
+func (d *DocumentRepository) GetDocument(ctx context.Context, tenantID int, id int) (*models.Document, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + documentColumns + `
+	FROM documents
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := d.pool.QueryRow(ctx, query, tenantID, id)
+	return scanDocument(row)
+}
+
+// CreateDocument stores a new document with its first version
+func (d *DocumentRepository) CreateDocument(ctx context.Context, document *models.Document) (*models.Document, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, d.pool, document.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO documents (tenant_id, employee_id, document_type_id, document_type, current_version, file_path, file_name, content_type,
+		size_bytes, checksum, issue_date, expiry_date, uploaded_by)
+	VALUES ($1, $2, $3, $4, 1, $5, $6, $7, $8, $9, $10, $11, $12)
+	RETURNING ` + documentColumns
+
+	created, err := scanDocument(tx.QueryRow(ctx, query,
+		document.TenantID,
+		document.EmployeeID,
+		document.DocumentTypeID,
+		document.DocumentType,
+		document.StorageKey,
+		document.FileName,
+		document.ContentType,
+		document.SizeBytes,
+		document.Checksum,
+		document.IssueDate,
+		document.ExpiryDate,
+		document.UploadedBy,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityDocument, "documents", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+	if err := insertDocumentVersion(ctx, tx, created); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// insertDocumentVersion records the current version of document
+func insertDocumentVersion(ctx context.Context, tx pgx.Tx, document *models.Document) error {
+	query := `
+	INSERT INTO document_versions (tenant_id, document_id, version, file_path, file_name, content_type, size_bytes, checksum,
+		issue_date, expiry_date, uploaded_by)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	RETURNING id
+	`
+
+	var id int
+	err := tx.QueryRow(ctx, query,
+		document.TenantID,
+		document.ID,
+		document.CurrentVersion,
+		document.StorageKey,
+		document.FileName,
+		document.ContentType,
+		document.SizeBytes,
+		document.Checksum,
+		document.IssueDate,
+		document.ExpiryDate,
+		document.UploadedBy,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+	return auditRow(ctx, tx, document.TenantID, AuditEntityDocumentVersion, "document_versions", id, AuditActionCreate, nil)
+}
+
+// AddDocumentVersion makes the file and dates of document a new version of
+// the stored document with the same ID and returns the updated document. The
+// expiry reminder is reset, as the new version has its own expiry date.
+func (d *DocumentRepository) AddDocumentVersion(ctx context.Context, document *models.Document) (*models.Document, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, d.pool, document.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var currentVersion int
+	err = tx.QueryRow(ctx, `
+	SELECT COALESCE(current_version, 1)
+	FROM documents
+	WHERE tenant_id = $1 AND id = $2
+	FOR UPDATE
+	`, document.TenantID, document.ID).Scan(&currentVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := snapshotRow(ctx, tx, "documents", document.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	UPDATE documents
+	SET current_version = $1, file_path = $2, file_name = $3, content_type = $4, size_bytes = $5, checksum = $6,
+		issue_date = $7, expiry_date = $8, uploaded_by = $9, expiry_reminded_at = NULL, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $10 AND id = $11
+	RETURNING ` + documentColumns
+
+	updated, err := scanDocument(tx.QueryRow(ctx, query,
+		currentVersion+1,
+		document.StorageKey,
+		document.FileName,
+		document.ContentType,
+		document.SizeBytes,
+		document.Checksum,
+		document.IssueDate,
+		document.ExpiryDate,
+		document.UploadedBy,
+		document.TenantID,
+		document.ID,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, updated.TenantID, AuditEntityDocument, "documents", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+	if err := insertDocumentVersion(ctx, tx, updated); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ListDocumentVersions returns every version of a document, newest first
+func (d *DocumentRepository) ListDocumentVersions(ctx context.Context, tenantID int, documentID int) ([]models.DocumentVersion, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + documentVersionColumns + `
+	FROM document_versions
+	WHERE tenant_id = $1 AND document_id = $2
+	ORDER BY version DESC
+	`
+
+	rows, err := d.pool.Query(ctx, query, tenantID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.DocumentVersion{}
+	for rows.Next() {
+		version, err := scanDocumentVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+
+	return versions, rows.Err()
+}
+
+func (d *DocumentRepository) GetDocumentVersion(ctx context.Context, tenantID int, documentID int, version int) (*models.DocumentVersion, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + documentVersionColumns + `
+	FROM document_versions
+	WHERE tenant_id = $1 AND document_id = $2 AND version = $3
+	`
+
+	row := d.pool.QueryRow(ctx, query, tenantID, documentID, version)
+	return scanDocumentVersion(row)
+}
+
+// DeleteDocument removes a document with its versions and returns the storage
+// keys of their files so those can be removed too
+func (d *DocumentRepository) DeleteDocument(ctx context.Context, tenantID int, id int) ([]string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, d.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+	SELECT file_path
+	FROM document_versions
+	WHERE tenant_id = $1 AND document_id = $2
+	`, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// The versions would go with the document, but are deleted first to be
+	// audited
+	if _, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityDocumentVersion, "document_versions", `tenant_id = $1 AND document_id = $2`, tenantID, id); err != nil {
+		return nil, err
+	}
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityDocument, "documents", `tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ListExpiringDocuments returns the documents that expired or expire within
+// withinDays of date, soonest first. A withinDays of zero uses the reminder
+// window of each document's type.
+func (d *DocumentRepository) ListExpiringDocuments(ctx context.Context, tenantID int, date time.Time, withinDays int) ([]models.Document, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + documentColumns + `
+	FROM documents
+	WHERE tenant_id = $1 AND expiry_date IS NOT NULL
+	  AND expiry_date <= $2::date + CASE WHEN $3 > 0 THEN $3
+		ELSE COALESCE((SELECT dt.expiry_reminder_days FROM document_types dt WHERE dt.id = document_type_id), 30) END
+	ORDER BY expiry_date, id
+	`
+
+	return d.listDocuments(ctx, query, tenantID, date, withinDays)
+}
+
+type ExpiryReminder struct {
+	Document      models.Document
+	EmployeeEmail string
+}
+
+// ClaimExpiryReminders marks up to limit employee documents that enter the
+// reminder window of their type on or before date, and were not reminded
+// since their current version was uploaded, as reminded and returns them.
+// Rows locked by another instance are skipped, so each reminder is sent once.
+func (d *DocumentRepository) ClaimExpiryReminders(ctx context.Context, date time.Time, limit int) ([]ExpiryReminder, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	dueFrom := `
+		FROM documents d
+		JOIN document_types dt ON dt.id = d.document_type_id
+		JOIN employees e ON e.id = d.employee_id
+		WHERE d.expiry_date IS NOT NULL AND d.expiry_reminded_at IS NULL
+		  AND COALESCE(dt.expiry_reminder_days, 30) > 0
+		  AND d.expiry_date <= $1::date + COALESCE(dt.expiry_reminder_days, 30)
+		  AND e.status <> 'terminated'`
+
+	tenantIDs, err := queryTenantIDs(ctx, d.pool, `SELECT DISTINCT d.tenant_id`+dueFrom, date)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := beginAudited(ctx, d.pool, tenantIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	dueWhere := `id IN (
+		SELECT d.id` + dueFrom + ` AND d.tenant_id = ANY($3)
+		ORDER BY d.expiry_date, d.id
+		LIMIT $2
+		FOR UPDATE OF d SKIP LOCKED
+	)`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityDocument, "documents", `expiry_reminded_at = CURRENT_TIMESTAMP`, dueWhere, date, limit, tenantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT ` + documentColumns + `, COALESCE((SELECT e.email FROM employees e WHERE e.id = employee_id), '')
+	FROM documents
+	WHERE id = ANY($1)
+	ORDER BY tenant_id, expiry_date, id
+	`
+
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []ExpiryReminder{}
+	for rows.Next() {
+		var reminder ExpiryReminder
+		document := &reminder.Document
+		err := rows.Scan(
+			&document.ID,
+			&document.TenantID,
+			&document.EmployeeID,
+			&document.EmployeeName,
+			&document.DocumentTypeID,
+			&document.DocumentType,
+			&document.CurrentVersion,
+			&document.StorageKey,
+			&document.FileName,
+			&document.ContentType,
+			&document.SizeBytes,
+			&document.Checksum,
+			&document.IssueDate,
+			&document.ExpiryDate,
+			&document.ExpiryReminderDays,
+			&document.UploadedBy,
+			&document.UploadedByName,
+			&document.CreatedAt,
+			&document.UpdatedAt,
+			&reminder.EmployeeEmail,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// ListRoleHolderEmails returns the email addresses of the active holders of
+// a role looked up by name
+func (d *DocumentRepository) ListRoleHolderEmails(ctx context.Context, tenantID int, roleName string) ([]string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT e.email
+	FROM employees e
+	JOIN roles r ON r.id = e.role_id
+	WHERE e.tenant_id = $1 AND r.name = $2 AND e.status <> 'terminated'
+	ORDER BY e.id
+	`
+
+	rows, err := d.pool.Query(ctx, query, tenantID, roleName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+
+	return emails, rows.Err()
+}
+
+func (d *DocumentRepository) ListDocumentRequirements(ctx context.Context, tenantID int) ([]models.DocumentRequirement, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + documentRequirementColumns + `
+	FROM document_requirements
+	WHERE tenant_id = $1
+	ORDER BY document_type_id, designation_id NULLS FIRST, country NULLS FIRST, id
+	`
+
+	rows, err := d.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requirements := []models.DocumentRequirement{}
+	for rows.Next() {
+		requirement, err := scanDocumentRequirement(rows)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, *requirement)
+	}
+
+	return requirements, rows.Err()
+}
+
+// CreateDocumentRequirement stores a requirement. It returns pgx.ErrNoRows
+// when its designation does not belong to the tenant.
+func (d *DocumentRepository) CreateDocumentRequirement(ctx context.Context, requirement *models.DocumentRequirement) (*models.DocumentRequirement, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO document_requirements (tenant_id, document_type_id, designation_id, country)
+	SELECT $1, $2, $3, NULLIF($4, '')
+	WHERE $3::int IS NULL OR EXISTS (SELECT 1 FROM designations WHERE tenant_id = $1 AND id = $3)
+	RETURNING ` + documentRequirementColumns
+
+	tx, err := beginAudited(ctx, d.pool, requirement.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query,
+		requirement.TenantID,
+		requirement.DocumentTypeID,
+		requirement.DesignationID,
+		requirement.Country,
+	)
+	created, err := scanDocumentRequirement(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, requirement.TenantID, AuditEntityDocumentRequirement, "document_requirements", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (d *DocumentRepository) DeleteDocumentRequirement(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, d.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityDocumentRequirement, "document_requirements", `tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RequirementSubject is an employee that document requirements apply to.
+// Country is that of their current home address, if any.
+type RequirementSubject struct {
+	EmployeeID    int
+	FirstName     string
+	LastName      string
+	DesignationID int
+	Country       string
+}
+
+// ListRequirementSubjects returns the tenant's employees that have not been
+// terminated
+func (d *DocumentRepository) ListRequirementSubjects(ctx context.Context, tenantID int) ([]RequirementSubject, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT e.id, e.first_name, e.last_name, e.designation_id,
+		COALESCE((
+			SELECT a.country
+			FROM employee_addresses a
+			WHERE a.employee_id = e.id AND a.is_current AND COALESCE(a.address_type, 'home') = 'home'
+			ORDER BY a.valid_from DESC, a.id DESC
+			LIMIT 1
+		), '')
+	FROM employees e
+	WHERE e.tenant_id = $1 AND e.status <> 'terminated'
+	ORDER BY e.last_name, e.first_name, e.id
+	`
+
+	rows, err := d.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []RequirementSubject{}
+	for rows.Next() {
+		var subject RequirementSubject
+		if err := rows.Scan(&subject.EmployeeID, &subject.FirstName, &subject.LastName, &subject.DesignationID, &subject.Country); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
+	}
+
+	return subjects, rows.Err()
+}
+
+// HeldDocument is a typed document an employee holds
+type HeldDocument struct {
+	EmployeeID     int
+	DocumentTypeID int
+	ExpiryDate     *time.Time
+}
+
+func (d *DocumentRepository) ListHeldDocuments(ctx context.Context, tenantID int) ([]HeldDocument, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT employee_id, document_type_id, expiry_date
+	FROM documents
+	WHERE tenant_id = $1 AND employee_id IS NOT NULL AND document_type_id IS NOT NULL
+	`
+
+	rows, err := d.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	held := []HeldDocument{}
+	for rows.Next() {
+		var document HeldDocument
+		if err := rows.Scan(&document.EmployeeID, &document.DocumentTypeID, &document.ExpiryDate); err != nil {
+			return nil, err
+		}
+		held = append(held, document)
+	}
+
+	return held, rows.Err()
+}
@@ -0,0 +1,507 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type EmployeeProfileRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEmployeeProfileRepository(pool *pgxpool.Pool) *EmployeeProfileRepository {
+	return &EmployeeProfileRepository{
+		pool: pool,
+	}
+}
+
+const personalDetailsColumns = `id, tenant_id, employee_id, date_of_birth, COALESCE(gender, ''), COALESCE(nationality, ''), COALESCE(marital_status, ''), COALESCE(national_id, ''), COALESCE(tax_number, ''), created_at, updated_at`
+
+func scanPersonalDetails(row pgx.Row) (*models.EmployeePersonalDetails, error) {
+	var details models.EmployeePersonalDetails
+	err := row.Scan(
+		&details.ID,
+		&details.TenantID,
+		&details.EmployeeID,
+		&details.DateOfBirth,
+		&details.Gender,
+		&details.Nationality,
+		&details.MaritalStatus,
+		&details.NationalID,
+		&details.TaxNumber,
+		&details.CreatedAt,
+		&details.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+func (p *EmployeeProfileRepository) GetPersonalDetails(ctx context.Context, tenantID int, employeeID int) (*models.EmployeePersonalDetails, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + personalDetailsColumns + `
+	FROM employee_personal_details
+	WHERE tenant_id = $1 AND employee_id = $2
+	`
+
+	row := p.pool.QueryRow(ctx, query, tenantID, employeeID)
+	return scanPersonalDetails(row)
+}
+
+func (p *EmployeeProfileRepository) UpsertPersonalDetails(ctx context.Context, details *models.EmployeePersonalDetails) (*models.EmployeePersonalDetails, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO employee_personal_details (tenant_id, employee_id, date_of_birth, gender, nationality, marital_status, national_id, tax_number)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (employee_id) DO UPDATE
+	SET date_of_birth = EXCLUDED.date_of_birth, gender = EXCLUDED.gender, nationality = EXCLUDED.nationality,
+		marital_status = EXCLUDED.marital_status, national_id = EXCLUDED.national_id, tax_number = EXCLUDED.tax_number,
+		updated_at = CURRENT_TIMESTAMP
+	RETURNING ` + personalDetailsColumns
+
+	tx, err := beginAudited(ctx, p.pool, details.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The details are created on the first save and updated after that
+	var before map[string]interface{}
+	err = tx.QueryRow(ctx, `SELECT to_jsonb(t) FROM employee_personal_details t WHERE t.employee_id = $1 FOR UPDATE`, details.EmployeeID).Scan(&before)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	action := AuditActionUpdate
+	if before == nil {
+		action = AuditActionCreate
+	}
+
+	row := tx.QueryRow(ctx, query, details.TenantID, details.EmployeeID, details.DateOfBirth, details.Gender, details.Nationality, details.MaritalStatus, details.NationalID, details.TaxNumber)
+	saved, err := scanPersonalDetails(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, saved.TenantID, AuditEntityPersonalDetails, "employee_personal_details", saved.ID, action, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+const addressColumns = `id, tenant_id, employee_id, address_type, line1, COALESCE(line2, ''), city, COALESCE(state, ''), COALESCE(postal_code, ''), country, valid_from, valid_to, is_current, created_at, updated_at`
+
+func scanAddress(row pgx.Row) (*models.EmployeeAddress, error) {
+	var address models.EmployeeAddress
+	err := row.Scan(
+		&address.ID,
+		&address.TenantID,
+		&address.EmployeeID,
+		&address.AddressType,
+		&address.Line1,
+		&address.Line2,
+		&address.City,
+		&address.State,
+		&address.PostalCode,
+		&address.Country,
+		&address.ValidFrom,
+		&address.ValidTo,
+		&address.IsCurrent,
+		&address.CreatedAt,
+		&address.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (p *EmployeeProfileRepository) ListAddresses(ctx context.Context, tenantID int, employeeID int, includeHistory bool) ([]models.EmployeeAddress, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + addressColumns + `
+	FROM employee_addresses
+	WHERE tenant_id = $1 AND employee_id = $2 AND (is_current OR $3)
+	ORDER BY address_type, valid_from DESC
+	`
+
+	rows, err := p.pool.Query(ctx, query, tenantID, employeeID, includeHistory)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []models.EmployeeAddress{}
+	for rows.Next() {
+		address, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, *address)
+	}
+
+	return addresses, rows.Err()
+}
+
+// AddAddress records a new address and closes the employee's current address
+// of the same type the day before the new one takes effect, keeping history.
+func (p *EmployeeProfileRepository) AddAddress(ctx context.Context, address *models.EmployeeAddress) (*models.EmployeeAddress, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, p.pool, address.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	closeSet := `is_current = FALSE, valid_to = GREATEST(valid_from, $1::date - 1), updated_at = CURRENT_TIMESTAMP`
+	closeWhere := `tenant_id = $2 AND employee_id = $3 AND address_type = $4 AND is_current`
+
+	if _, err := updateAuditedRows(ctx, tx, AuditEntityAddress, "employee_addresses", closeSet, closeWhere, address.ValidFrom, address.TenantID, address.EmployeeID, address.AddressType); err != nil {
+		return nil, err
+	}
+
+	insertQuery := `
+	INSERT INTO employee_addresses (tenant_id, employee_id, address_type, line1, line2, city, state, postal_code, country, valid_from, is_current)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, TRUE)
+	RETURNING ` + addressColumns
+
+	row := tx.QueryRow(ctx, insertQuery, address.TenantID, address.EmployeeID, address.AddressType, address.Line1, address.Line2, address.City, address.State, address.PostalCode, address.Country, address.ValidFrom)
+	created, err := scanAddress(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityAddress, "employee_addresses", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+const emergencyContactColumns = `id, tenant_id, employee_id, name, relationship, phone, COALESCE(email, ''), is_primary, created_at, updated_at`
+
+func scanEmergencyContact(row pgx.Row) (*models.EmployeeEmergencyContact, error) {
+	var contact models.EmployeeEmergencyContact
+	err := row.Scan(
+		&contact.ID,
+		&contact.TenantID,
+		&contact.EmployeeID,
+		&contact.Name,
+		&contact.Relationship,
+		&contact.Phone,
+		&contact.Email,
+		&contact.IsPrimary,
+		&contact.CreatedAt,
+		&contact.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (p *EmployeeProfileRepository) ListEmergencyContacts(ctx context.Context, tenantID int, employeeID int) ([]models.EmployeeEmergencyContact, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + emergencyContactColumns + `
+	FROM employee_emergency_contacts
+	WHERE tenant_id = $1 AND employee_id = $2
+	ORDER BY is_primary DESC, id
+	`
+
+	rows, err := p.pool.Query(ctx, query, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := []models.EmployeeEmergencyContact{}
+	for rows.Next() {
+		contact, err := scanEmergencyContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, *contact)
+	}
+
+	return contacts, rows.Err()
+}
+
+// SaveEmergencyContact inserts a contact when ID is zero and updates it
+// otherwise. Marking a contact primary demotes the employee's other contacts.
+func (p *EmployeeProfileRepository) SaveEmergencyContact(ctx context.Context, contact *models.EmployeeEmergencyContact) (*models.EmployeeEmergencyContact, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, p.pool, contact.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if contact.IsPrimary {
+		demoteSet := `is_primary = FALSE, updated_at = CURRENT_TIMESTAMP`
+		demoteWhere := `tenant_id = $1 AND employee_id = $2 AND id <> $3 AND is_primary`
+		if _, err := updateAuditedRows(ctx, tx, AuditEntityEmergencyContact, "employee_emergency_contacts", demoteSet, demoteWhere, contact.TenantID, contact.EmployeeID, contact.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var before map[string]interface{}
+	if contact.ID != 0 {
+		if before, err = snapshotRow(ctx, tx, "employee_emergency_contacts", contact.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var row pgx.Row
+	if contact.ID == 0 {
+		query := `
+		INSERT INTO employee_emergency_contacts (tenant_id, employee_id, name, relationship, phone, email, is_primary)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + emergencyContactColumns
+		row = tx.QueryRow(ctx, query, contact.TenantID, contact.EmployeeID, contact.Name, contact.Relationship, contact.Phone, contact.Email, contact.IsPrimary)
+	} else {
+		query := `
+		UPDATE employee_emergency_contacts
+		SET name = $1, relationship = $2, phone = $3, email = $4, is_primary = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE tenant_id = $6 AND employee_id = $7 AND id = $8
+		RETURNING ` + emergencyContactColumns
+		row = tx.QueryRow(ctx, query, contact.Name, contact.Relationship, contact.Phone, contact.Email, contact.IsPrimary, contact.TenantID, contact.EmployeeID, contact.ID)
+	}
+
+	saved, err := scanEmergencyContact(row)
+	if err != nil {
+		return nil, err
+	}
+
+	action := AuditActionUpdate
+	if before == nil {
+		action = AuditActionCreate
+	}
+	if err := auditRow(ctx, tx, saved.TenantID, AuditEntityEmergencyContact, "employee_emergency_contacts", saved.ID, action, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (p *EmployeeProfileRepository) DeleteEmergencyContact(ctx context.Context, tenantID int, employeeID int, contactID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, p.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityEmergencyContact, "employee_emergency_contacts", `tenant_id = $1 AND employee_id = $2 AND id = $3`, tenantID, employeeID, contactID)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+const bankAccountColumns = `id, tenant_id, employee_id, bank_name, account_name, COALESCE(account_number, ''), COALESCE(bank_code, ''), COALESCE(iban, ''), COALESCE(currency, ''), is_primary, created_at, updated_at`
+
+func scanBankAccount(row pgx.Row) (*models.EmployeeBankAccount, error) {
+	var account models.EmployeeBankAccount
+	err := row.Scan(
+		&account.ID,
+		&account.TenantID,
+		&account.EmployeeID,
+		&account.BankName,
+		&account.AccountName,
+		&account.AccountNumber,
+		&account.BankCode,
+		&account.IBAN,
+		&account.Currency,
+		&account.IsPrimary,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (p *EmployeeProfileRepository) ListBankAccounts(ctx context.Context, tenantID int, employeeID int) ([]models.EmployeeBankAccount, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + bankAccountColumns + `
+	FROM employee_bank_accounts
+	WHERE tenant_id = $1 AND employee_id = $2
+	ORDER BY is_primary DESC, id
+	`
+
+	rows, err := p.pool.Query(ctx, query, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []models.EmployeeBankAccount{}
+	for rows.Next() {
+		account, err := scanBankAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// SaveBankAccount inserts an account when ID is zero and updates it otherwise.
+// Only one account per employee can be the primary payroll account.
+func (p *EmployeeProfileRepository) SaveBankAccount(ctx context.Context, account *models.EmployeeBankAccount) (*models.EmployeeBankAccount, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, p.pool, account.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if account.IsPrimary {
+		demoteSet := `is_primary = FALSE, updated_at = CURRENT_TIMESTAMP`
+		demoteWhere := `tenant_id = $1 AND employee_id = $2 AND id <> $3 AND is_primary`
+		if _, err := updateAuditedRows(ctx, tx, AuditEntityBankAccount, "employee_bank_accounts", demoteSet, demoteWhere, account.TenantID, account.EmployeeID, account.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var before map[string]interface{}
+	if account.ID != 0 {
+		if before, err = snapshotRow(ctx, tx, "employee_bank_accounts", account.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var row pgx.Row
+	if account.ID == 0 {
+		query := `
+		INSERT INTO employee_bank_accounts (tenant_id, employee_id, bank_name, account_name, account_number, bank_code, iban, currency, is_primary)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + bankAccountColumns
+		row = tx.QueryRow(ctx, query, account.TenantID, account.EmployeeID, account.BankName, account.AccountName, account.AccountNumber, account.BankCode, account.IBAN, account.Currency, account.IsPrimary)
+	} else {
+		query := `
+		UPDATE employee_bank_accounts
+		SET bank_name = $1, account_name = $2, account_number = $3, bank_code = $4, iban = $5, currency = $6, is_primary = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE tenant_id = $8 AND employee_id = $9 AND id = $10
+		RETURNING ` + bankAccountColumns
+		row = tx.QueryRow(ctx, query, account.BankName, account.AccountName, account.AccountNumber, account.BankCode, account.IBAN, account.Currency, account.IsPrimary, account.TenantID, account.EmployeeID, account.ID)
+	}
+
+	saved, err := scanBankAccount(row)
+	if err != nil {
+		return nil, err
+	}
+
+	action := AuditActionUpdate
+	if before == nil {
+		action = AuditActionCreate
+	}
+	if err := auditRow(ctx, tx, saved.TenantID, AuditEntityBankAccount, "employee_bank_accounts", saved.ID, action, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (p *EmployeeProfileRepository) DeleteBankAccount(ctx context.Context, tenantID int, employeeID int, accountID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, p.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityBankAccount, "employee_bank_accounts", `tenant_id = $1 AND employee_id = $2 AND id = $3`, tenantID, employeeID, accountID)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
@@ -2,10 +2,15 @@ package repositories
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/peopleos/dto"
 	"github.com/falasefemi2/peopleos/models"
 )
 
@@ -27,15 +32,26 @@ func (e *EmployeeRepository) CreateEmployee(ctx context.Context, employee *model
 	}
 
 	query := `
-	INSERT INTO employees (tenant_id, first_name, last_name, email, phone, department_id, designation_id, manager_id, status, hire_date, password_hash)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-	RETURNING id, tenant_id, first_name, last_name, email, phone, department_id, designation_id, manager_id, status, hire_date, password_hash, created_at, updated_at
+	INSERT INTO employees (tenant_id, first_name, last_name, email, phone, department_id, designation_id, manager_id, status, hire_date, password_hash, custom_fields, employment_type)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	RETURNING id, tenant_id, first_name, last_name, email, phone, department_id, designation_id, manager_id, status, hire_date, COALESCE(employment_type, 'full_time'), password_hash, COALESCE(custom_fields, '{}'::jsonb), created_at, updated_at
 	`
 
-	row := e.pool.QueryRow(ctx, query, employee.TenantID, employee.FirstName, employee.LastName, employee.Email, employee.Phone, employee.DepartmentID, employee.DesignationID, employee.ManagerID, employee.Status, employee.HireDate, employee.PasswordHash)
+	customFields := employee.CustomFields
+	if customFields == nil {
+		customFields = map[string]interface{}{}
+	}
+
+	tx, err := beginAudited(ctx, e.pool, employee.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query, employee.TenantID, employee.FirstName, employee.LastName, employee.Email, employee.Phone, employee.DepartmentID, employee.DesignationID, employee.ManagerID, employee.Status, employee.HireDate, employee.PasswordHash, customFields, employee.EmploymentType)
 
 	var createdEmployee models.Employee
-	err := row.Scan(
+	err = row.Scan(
 		&createdEmployee.ID,
 		&createdEmployee.TenantID,
 		&createdEmployee.FirstName,
@@ -47,7 +63,9 @@ func (e *EmployeeRepository) CreateEmployee(ctx context.Context, employee *model
 		&createdEmployee.ManagerID,
 		&createdEmployee.Status,
 		&createdEmployee.HireDate,
+		&createdEmployee.EmploymentType,
 		&createdEmployee.PasswordHash,
+		&createdEmployee.CustomFields,
 		&createdEmployee.CreatedAt,
 		&createdEmployee.UpdatedAt,
 	)
@@ -56,6 +74,13 @@ func (e *EmployeeRepository) CreateEmployee(ctx context.Context, employee *model
 		return nil, err
 	}
 
+	if err := auditRow(ctx, tx, createdEmployee.TenantID, AuditEntityEmployee, "employees", createdEmployee.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &createdEmployee, nil
 }
 
@@ -107,7 +132,7 @@ func (e *EmployeeRepository) GetEmployeeByEmailWithRole(ctx context.Context, ema
 	}
 
 	query := `
-	SELECT e.id, e.tenant_id, e.first_name, e.last_name, e.email, e.phone, e.department_id, e.designation_id, e.manager_id, e.status, e.hire_date, e.password_hash, e.created_at, e.updated_at, r.name
+	SELECT e.id, e.tenant_id, e.first_name, e.last_name, e.email, e.phone, e.department_id, e.designation_id, e.manager_id, e.status, e.hire_date, e.password_hash, e.mfa_secret, COALESCE(e.mfa_enabled, FALSE), e.created_at, e.updated_at, r.name
 	FROM employees e
 	LEFT JOIN roles r ON e.role_id = r.id
 	WHERE e.email = $1
@@ -131,6 +156,8 @@ func (e *EmployeeRepository) GetEmployeeByEmailWithRole(ctx context.Context, ema
 		&employee.Status,
 		&employee.HireDate,
 		&employee.PasswordHash,
+		&employee.MFASecret,
+		&employee.MFAEnabled,
 		&employee.CreatedAt,
 		&employee.UpdatedAt,
 		&roleName,
@@ -143,6 +170,151 @@ func (e *EmployeeRepository) GetEmployeeByEmailWithRole(ctx context.Context, ema
 	return &employee, roleName, nil
 }
 
+func (e *EmployeeRepository) GetEmployeeByID(ctx context.Context, tenantID int, id int) (*models.Employee, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT id, tenant_id, first_name, last_name, email, COALESCE(phone, ''), department_id, designation_id, manager_id, status, hire_date, COALESCE(employment_type, 'full_time'), salary, COALESCE(salary_currency, ''), COALESCE(custom_fields, '{}'::jsonb), created_at, updated_at
+	FROM employees
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := e.pool.QueryRow(ctx, query, tenantID, id)
+
+	var employee models.Employee
+	err := row.Scan(
+		&employee.ID,
+		&employee.TenantID,
+		&employee.FirstName,
+		&employee.LastName,
+		&employee.Email,
+		&employee.Phone,
+		&employee.DepartmentID,
+		&employee.DesignationID,
+		&employee.ManagerID,
+		&employee.Status,
+		&employee.HireDate,
+		&employee.EmploymentType,
+		&employee.Salary,
+		&employee.SalaryCurrency,
+		&employee.CustomFields,
+		&employee.CreatedAt,
+		&employee.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &employee, nil
+}
+
+// UpdateEmployee saves the employee's basic details and custom field values.
+// It returns pgx.ErrNoRows when the tenant has no such employee.
+func (e *EmployeeRepository) UpdateEmployee(ctx context.Context, employee *models.Employee) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE employees
+	SET first_name = $1, last_name = $2, phone = $3, custom_fields = $4, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $5 AND id = $6
+	`
+
+	customFields := employee.CustomFields
+	if customFields == nil {
+		customFields = map[string]interface{}{}
+	}
+
+	tx, err := beginAudited(ctx, e.pool, employee.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "employees", employee.ID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, query, employee.FirstName, employee.LastName, employee.Phone, customFields, employee.TenantID, employee.ID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if err := auditRow(ctx, tx, employee.TenantID, AuditEntityEmployee, "employees", employee.ID, AuditActionUpdate, before); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetAccessRevokedAt returns when the employee's access was revoked, or nil
+// while it stands.
+func (e *EmployeeRepository) GetAccessRevokedAt(ctx context.Context, tenantID int, id int) (*time.Time, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT access_revoked_at
+	FROM employees
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	var revokedAt *time.Time
+	if err := e.pool.QueryRow(ctx, query, tenantID, id).Scan(&revokedAt); err != nil {
+		return nil, err
+	}
+	return revokedAt, nil
+}
+
+// DeleteEmployee removes an employee and, through their foreign keys, the
+// records tied to them. It is used to undo a creation that could not be
+// completed; pgx.ErrNoRows is returned when there is no such employee.
+func (e *EmployeeRepository) DeleteEmployee(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, e.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "employees", id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM employees WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if err := recordAudit(ctx, tx, tenantID, AuditEntityEmployee, id, AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 func (e *EmployeeRepository) AssignRoleToEmployee(ctx context.Context, employeeID int, roleID int) error {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -150,12 +322,182 @@ func (e *EmployeeRepository) AssignRoleToEmployee(ctx context.Context, employeeI
 		defer cancel()
 	}
 
+	tenantID, err := rowTenantID(ctx, e.pool, "employees", employeeID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := beginAudited(ctx, e.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "employees", employeeID)
+	if err != nil {
+		return err
+	}
+
 	query := `
 	UPDATE employees
 	SET role_id = $1, updated_at = CURRENT_TIMESTAMP
 	WHERE id = $2
+	RETURNING tenant_id
+	`
+
+	if err := tx.QueryRow(ctx, query, roleID, employeeID).Scan(&tenantID); err != nil {
+		return err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityEmployee, "employees", employeeID, AuditActionUpdate, before); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// buildEmployeeFilter appends the WHERE conditions for filter to a query over
+// employees aliased as "e" and returns the conditions and positional arguments.
+func buildEmployeeFilter(tenantID int, filter *dto.EmployeeFilter) (string, []interface{}) {
+	conditions := []string{"e.tenant_id = $1"}
+	args := []interface{}{tenantID}
+
+	if filter != nil {
+		if filter.Status != "" {
+			args = append(args, filter.Status)
+			conditions = append(conditions, fmt.Sprintf("e.status = $%d", len(args)))
+		}
+		if filter.DepartmentID != 0 {
+			args = append(args, filter.DepartmentID)
+			conditions = append(conditions, fmt.Sprintf("e.department_id = $%d", len(args)))
+		}
+		if filter.DesignationID != 0 {
+			args = append(args, filter.DesignationID)
+			conditions = append(conditions, fmt.Sprintf("e.designation_id = $%d", len(args)))
+		}
+		if filter.ManagerID != 0 {
+			args = append(args, filter.ManagerID)
+			conditions = append(conditions, fmt.Sprintf("e.manager_id = $%d", len(args)))
+		}
+		if search := strings.TrimSpace(filter.Search); search != "" {
+			args = append(args, "%"+search+"%")
+			conditions = append(conditions, fmt.Sprintf("(e.first_name ILIKE $%d OR e.last_name ILIKE $%d OR e.email ILIKE $%d)", len(args), len(args), len(args)))
+		}
+
+		// Sorted so the generated SQL is stable for the same filter
+		keys := make([]string, 0, len(filter.CustomFields))
+		for key := range filter.CustomFields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			args = append(args, key, filter.CustomFields[key])
+			conditions = append(conditions, fmt.Sprintf("e.custom_fields ->> $%d = $%d", len(args)-1, len(args)))
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (e *EmployeeRepository) ListEmployees(ctx context.Context, tenantID int, filter *dto.EmployeeFilter) ([]models.Employee, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	where, args := buildEmployeeFilter(tenantID, filter)
+	query := `
+	SELECT e.id, e.tenant_id, e.first_name, e.last_name, e.email, COALESCE(e.phone, ''), e.department_id, e.designation_id, e.manager_id, e.status, e.hire_date, COALESCE(e.custom_fields, '{}'::jsonb), e.created_at, e.updated_at
+	FROM employees e
+	WHERE ` + where + `
+	ORDER BY e.id
 	`
 
-	_, err := e.pool.Exec(ctx, query, roleID, employeeID)
-	return err
+	rows, err := e.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []models.Employee{}
+	for rows.Next() {
+		var employee models.Employee
+		err := rows.Scan(
+			&employee.ID,
+			&employee.TenantID,
+			&employee.FirstName,
+			&employee.LastName,
+			&employee.Email,
+			&employee.Phone,
+			&employee.DepartmentID,
+			&employee.DesignationID,
+			&employee.ManagerID,
+			&employee.Status,
+			&employee.HireDate,
+			&employee.CustomFields,
+			&employee.CreatedAt,
+			&employee.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		employees = append(employees, employee)
+	}
+
+	return employees, rows.Err()
+}
+
+// StreamEmployeeExportRows calls fn for every employee matching filter, one row
+// at a time, so that large exports never hold the full result set in memory.
+func (e *EmployeeRepository) StreamEmployeeExportRows(ctx context.Context, tenantID int, filter *dto.EmployeeFilter, fn func(row *models.EmployeeExportRow) error) error {
+	where, args := buildEmployeeFilter(tenantID, filter)
+	query := `
+	SELECT e.id, e.tenant_id, e.first_name, e.last_name, e.email, COALESCE(e.phone, ''), e.department_id, e.designation_id, e.manager_id, e.status, e.hire_date, COALESCE(e.custom_fields, '{}'::jsonb), e.created_at, e.updated_at,
+		COALESCE(d.name, ''), COALESCE(g.name, ''), COALESCE(TRIM(m.first_name || ' ' || m.last_name), ''), COALESCE(r.name, '')
+	FROM employees e
+	LEFT JOIN departments d ON e.department_id = d.id
+	LEFT JOIN designations g ON e.designation_id = g.id
+	LEFT JOIN employees m ON e.manager_id = m.id
+	LEFT JOIN roles r ON e.role_id = r.id
+	WHERE ` + where + `
+	ORDER BY e.id
+	`
+
+	rows, err := e.pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.EmployeeExportRow
+		err := rows.Scan(
+			&row.ID,
+			&row.TenantID,
+			&row.FirstName,
+			&row.LastName,
+			&row.Email,
+			&row.Phone,
+			&row.DepartmentID,
+			&row.DesignationID,
+			&row.ManagerID,
+			&row.Status,
+			&row.HireDate,
+			&row.CustomFields,
+			&row.CreatedAt,
+			&row.UpdatedAt,
+			&row.DepartmentName,
+			&row.DesignationName,
+			&row.ManagerName,
+			&row.RoleName,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
@@ -0,0 +1,326 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type EmploymentHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEmploymentHistoryRepository(pool *pgxpool.Pool) *EmploymentHistoryRepository {
+	return &EmploymentHistoryRepository{
+		pool: pool,
+	}
+}
+
+const employmentHistoryColumns = `id, tenant_id, employee_id, change_type, effective_date, changed_fields, department_id, designation_id, manager_id, COALESCE(employment_type, ''), salary, COALESCE(salary_currency, ''), COALESCE(reason, ''), status, created_by, applied_at, cancelled_at, created_at, updated_at`
+
+func scanEmploymentHistory(row pgx.Row) (*models.EmploymentHistory, error) {
+	var record models.EmploymentHistory
+	err := row.Scan(
+		&record.ID,
+		&record.TenantID,
+		&record.EmployeeID,
+		&record.ChangeType,
+		&record.EffectiveDate,
+		&record.ChangedFields,
+		&record.DepartmentID,
+		&record.DesignationID,
+		&record.ManagerID,
+		&record.EmploymentType,
+		&record.Salary,
+		&record.SalaryCurrency,
+		&record.Reason,
+		&record.Status,
+		&record.CreatedBy,
+		&record.AppliedAt,
+		&record.CancelledAt,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func collectEmploymentHistory(rows pgx.Rows) ([]models.EmploymentHistory, error) {
+	defer rows.Close()
+
+	records := []models.EmploymentHistory{}
+	for rows.Next() {
+		record, err := scanEmploymentHistory(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+func (h *EmploymentHistoryRepository) CreateEmploymentChange(ctx context.Context, record *models.EmploymentHistory) (*models.EmploymentHistory, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO employment_history (tenant_id, employee_id, change_type, effective_date, changed_fields, department_id, designation_id, manager_id, employment_type, salary, salary_currency, reason, status, created_by, applied_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, NULLIF($11, ''), NULLIF($12, ''), $13, $14, $15)
+	RETURNING ` + employmentHistoryColumns
+
+	tx, err := beginAudited(ctx, h.pool, record.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query, record.TenantID, record.EmployeeID, record.ChangeType, record.EffectiveDate, record.ChangedFields, record.DepartmentID, record.DesignationID, record.ManagerID, record.EmploymentType, record.Salary, record.SalaryCurrency, record.Reason, record.Status, record.CreatedBy, record.AppliedAt)
+	created, err := scanEmploymentHistory(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityEmploymentHistory, "employment_history", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// ListEmploymentHistory returns every record for the employee, including
+// scheduled and cancelled ones, in effective order.
+func (h *EmploymentHistoryRepository) ListEmploymentHistory(ctx context.Context, tenantID int, employeeID int) ([]models.EmploymentHistory, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + employmentHistoryColumns + `
+	FROM employment_history
+	WHERE tenant_id = $1 AND employee_id = $2
+	ORDER BY effective_date, id
+	`
+
+	rows, err := h.pool.Query(ctx, query, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	return collectEmploymentHistory(rows)
+}
+
+// ListTenantHistoryUntil returns the non-cancelled records of every employee in
+// the tenant effective on or before date, grouped by employee in effective order.
+func (h *EmploymentHistoryRepository) ListTenantHistoryUntil(ctx context.Context, tenantID int, date time.Time) ([]models.EmploymentHistory, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + employmentHistoryColumns + `
+	FROM employment_history
+	WHERE tenant_id = $1 AND status <> 'cancelled' AND effective_date <= $2
+	ORDER BY employee_id, effective_date, id
+	`
+
+	rows, err := h.pool.Query(ctx, query, tenantID, date)
+	if err != nil {
+		return nil, err
+	}
+	return collectEmploymentHistory(rows)
+}
+
+// CancelEmploymentChange cancels a change that has not yet been applied
+func (h *EmploymentHistoryRepository) CancelEmploymentChange(ctx context.Context, tenantID int, employeeID int, id int) (*models.EmploymentHistory, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE employment_history
+	SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $1 AND employee_id = $2 AND id = $3 AND status = 'scheduled'
+	RETURNING ` + employmentHistoryColumns
+
+	tx, err := beginAudited(ctx, h.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "employment_history", id)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query, tenantID, employeeID, id)
+	cancelled, err := scanEmploymentHistory(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityEmploymentHistory, "employment_history", id, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return cancelled, nil
+}
+
+// DueEmployee identifies an employee with scheduled changes ready to apply
+type DueEmployee struct {
+	TenantID   int
+	EmployeeID int
+}
+
+func (h *EmploymentHistoryRepository) ListDueEmployees(ctx context.Context, date time.Time) ([]DueEmployee, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT DISTINCT tenant_id, employee_id
+	FROM employment_history
+	WHERE status = 'scheduled' AND effective_date <= $1
+	`
+
+	rows, err := h.pool.Query(ctx, query, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []DueEmployee{}
+	for rows.Next() {
+		var d DueEmployee
+		if err := rows.Scan(&d.TenantID, &d.EmployeeID); err != nil {
+			return nil, err
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+// ApplyEmploymentChanges recomputes the employee's current job from their
+// history as of date and marks due scheduled records applied, all in one
+// transaction. resolve folds the history onto the employee. The employee row
+// is locked with SKIP LOCKED so that concurrent schedulers on other instances
+// pass over an employee already being processed; applied reports whether
+// this call did the work.
+func (h *EmploymentHistoryRepository) ApplyEmploymentChanges(ctx context.Context, tenantID int, employeeID int, date time.Time, resolve func(employee *models.Employee, history []models.EmploymentHistory)) (applied bool, err error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, h.pool, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	lockQuery := `
+	SELECT id, tenant_id, department_id, designation_id, manager_id, COALESCE(employment_type, 'full_time'), salary, COALESCE(salary_currency, '')
+	FROM employees
+	WHERE tenant_id = $1 AND id = $2
+	FOR UPDATE SKIP LOCKED
+	`
+
+	var employee models.Employee
+	err = tx.QueryRow(ctx, lockQuery, tenantID, employeeID).Scan(
+		&employee.ID,
+		&employee.TenantID,
+		&employee.DepartmentID,
+		&employee.DesignationID,
+		&employee.ManagerID,
+		&employee.EmploymentType,
+		&employee.Salary,
+		&employee.SalaryCurrency,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	before, err := snapshotRow(ctx, tx, "employees", employeeID)
+	if err != nil {
+		return false, err
+	}
+
+	historyQuery := `
+	SELECT ` + employmentHistoryColumns + `
+	FROM employment_history
+	WHERE tenant_id = $1 AND employee_id = $2 AND status <> 'cancelled' AND effective_date <= $3
+	ORDER BY effective_date, id
+	`
+
+	rows, err := tx.Query(ctx, historyQuery, tenantID, employeeID, date)
+	if err != nil {
+		return false, err
+	}
+	history, err := collectEmploymentHistory(rows)
+	if err != nil {
+		return false, err
+	}
+
+	resolve(&employee, history)
+
+	updateQuery := `
+	UPDATE employees
+	SET department_id = $1, designation_id = $2, manager_id = $3, employment_type = $4, salary = $5, salary_currency = NULLIF($6, ''), updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $7 AND id = $8
+	  AND (department_id, designation_id, manager_id, employment_type, salary, salary_currency)
+		IS DISTINCT FROM ($1, $2, $3, $4, $5, NULLIF($6, ''))
+	`
+
+	// Only an actual change to the employee's job is saved and audited
+	result, err := tx.Exec(ctx, updateQuery, employee.DepartmentID, employee.DesignationID, employee.ManagerID, employee.EmploymentType, employee.Salary, employee.SalaryCurrency, tenantID, employeeID)
+	if err != nil {
+		return false, err
+	}
+	if result.RowsAffected() > 0 {
+		if err := auditRow(ctx, tx, tenantID, AuditEntityEmployee, "employees", employeeID, AuditActionUpdate, before); err != nil {
+			return false, err
+		}
+	}
+
+	markSet := `status = 'applied', applied_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	markWhere := `tenant_id = $1 AND employee_id = $2 AND status = 'scheduled' AND effective_date <= $3`
+
+	if _, err := updateAuditedRows(ctx, tx, AuditEntityEmploymentHistory, "employment_history", markSet, markWhere, tenantID, employeeID, date); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type ExportJobRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExportJobRepository(pool *pgxpool.Pool) *ExportJobRepository {
+	return &ExportJobRepository{
+		pool: pool,
+	}
+}
+
+const exportJobColumns = `id, tenant_id, requested_by, entity_type, format, COALESCE(columns, ''), filters, status, file_path, file_name, row_count, error, completed_at, created_at, updated_at`
+
+func scanExportJob(row pgx.Row) (*models.ExportJob, error) {
+	var job models.ExportJob
+	err := row.Scan(
+		&job.ID,
+		&job.TenantID,
+		&job.RequestedBy,
+		&job.EntityType,
+		&job.Format,
+		&job.Columns,
+		&job.Filters,
+		&job.Status,
+		&job.FilePath,
+		&job.FileName,
+		&job.RowCount,
+		&job.Error,
+		&job.CompletedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (e *ExportJobRepository) CreateExportJob(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO export_jobs (tenant_id, requested_by, entity_type, format, columns, filters, status)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING ` + exportJobColumns
+
+	tx, err := beginAudited(ctx, e.pool, job.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query, job.TenantID, job.RequestedBy, job.EntityType, job.Format, job.Columns, job.Filters, job.Status)
+	created, err := scanExportJob(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityExportJob, "export_jobs", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (e *ExportJobRepository) GetExportJobByID(ctx context.Context, tenantID int, id int) (*models.ExportJob, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + exportJobColumns + `
+	FROM export_jobs
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := e.pool.QueryRow(ctx, query, tenantID, id)
+	return scanExportJob(row)
+}
+
+// ClaimPendingExportJob marks the oldest pending job running and returns it.
+// The job is locked with SKIP LOCKED so that workers on other instances
+// claim different jobs; pgx.ErrNoRows is returned when none is pending.
+func (e *ExportJobRepository) ClaimPendingExportJob(ctx context.Context) (*models.ExportJob, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tenantIDs, err := queryTenantIDs(ctx, e.pool, `SELECT DISTINCT tenant_id FROM export_jobs WHERE status = 'pending'`)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := beginAudited(ctx, e.pool, tenantIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	claimSet := `status = 'running', updated_at = CURRENT_TIMESTAMP`
+	claimWhere := `id = (
+		SELECT id
+		FROM export_jobs
+		WHERE status = 'pending' AND tenant_id = ANY($1)
+		ORDER BY id
+		FOR UPDATE SKIP LOCKED
+		LIMIT 1
+	)`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityExportJob, "export_jobs", claimSet, claimWhere, tenantIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	row := tx.QueryRow(ctx, `SELECT `+exportJobColumns+` FROM export_jobs WHERE id = $1`, ids[0])
+	job, err := scanExportJob(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// RequeueExportJob returns a running job to pending, for a job interrupted
+// by shutdown
+func (e *ExportJobRepository) RequeueExportJob(ctx context.Context, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	return e.updateExportJob(ctx, id, `status = 'pending', updated_at = CURRENT_TIMESTAMP`, `id = $1 AND status = 'running'`, id)
+}
+
+// FailStaleExportJobs marks jobs that have been running since before the
+// given time failed. Such jobs were left behind by a process that stopped
+// without finishing them.
+func (e *ExportJobRepository) FailStaleExportJobs(ctx context.Context, before time.Time, message string) (int64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tenantIDs, err := queryTenantIDs(ctx, e.pool, `SELECT DISTINCT tenant_id FROM export_jobs WHERE status = 'running' AND updated_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := beginAudited(ctx, e.pool, tenantIDs...)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	failSet := `status = 'failed', error = $1, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	failWhere := `status = 'running' AND updated_at < $2 AND tenant_id = ANY($3)`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityExportJob, "export_jobs", failSet, failWhere, message, before, tenantIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), nil
+}
+
+func (e *ExportJobRepository) MarkExportJobCompleted(ctx context.Context, id int, filePath string, fileName string, rowCount int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	set := `status = 'completed', file_path = $1, file_name = $2, row_count = $3, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	return e.updateExportJob(ctx, id, set, `id = $4`, filePath, fileName, rowCount, id)
+}
+
+func (e *ExportJobRepository) MarkExportJobFailed(ctx context.Context, id int, message string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	set := `status = 'failed', error = $1, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	return e.updateExportJob(ctx, id, set, `id = $2`, message, id)
+}
+
+// updateExportJob applies set to the job with the id when it matches where
+// and audits it
+func (e *ExportJobRepository) updateExportJob(ctx context.Context, id int, set string, where string, args ...interface{}) error {
+	tenantID, err := rowTenantID(ctx, e.pool, "export_jobs", id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	tx, err := beginAudited(ctx, e.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := updateAuditedRows(ctx, tx, AuditEntityExportJob, "export_jobs", set, where, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
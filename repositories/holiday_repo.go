@@ -0,0 +1,305 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type HolidayRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewHolidayRepository(pool *pgxpool.Pool) *HolidayRepository {
+	return &HolidayRepository{
+		pool: pool,
+	}
+}
+
+const holidayColumns = `id, tenant_id, holiday_date, name, location, COALESCE(is_day_off, TRUE), COALESCE(source, 'custom'), created_at, COALESCE(updated_at, created_at)`
+
+func scanHoliday(row pgx.Row) (*models.Holiday, error) {
+	var holiday models.Holiday
+	err := row.Scan(
+		&holiday.ID,
+		&holiday.TenantID,
+		&holiday.Date,
+		&holiday.Name,
+		&holiday.Location,
+		&holiday.IsDayOff,
+		&holiday.Source,
+		&holiday.CreatedAt,
+		&holiday.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+// ListHolidays returns the tenant's holidays for every location between from
+// and to inclusive, ordered by date with the tenant-wide holiday first
+func (h *HolidayRepository) ListHolidays(ctx context.Context, tenantID int, from time.Time, to time.Time) ([]models.Holiday, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + holidayColumns + `
+	FROM holidays
+	WHERE tenant_id = $1 AND holiday_date BETWEEN $2 AND $3
+	ORDER BY holiday_date, location
+	`
+
+	rows, err := h.pool.Query(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holidays := []models.Holiday{}
+	for rows.Next() {
+		holiday, err := scanHoliday(rows)
+		if err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, *holiday)
+	}
+
+	return holidays, rows.Err()
+}
+
+func (h *HolidayRepository) GetHoliday(ctx context.Context, tenantID int, id int) (*models.Holiday, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + holidayColumns + `
+	FROM holidays
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	return scanHoliday(h.pool.QueryRow(ctx, query, tenantID, id))
+}
+
+// CreateHoliday returns pgx.ErrNoRows when the location already has a holiday
+// on the date
+func (h *HolidayRepository) CreateHoliday(ctx context.Context, holiday *models.Holiday) (*models.Holiday, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO holidays (tenant_id, holiday_date, name, location, is_day_off, source)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (tenant_id, holiday_date, location) DO NOTHING
+	RETURNING ` + holidayColumns
+
+	tx, err := beginAudited(ctx, h.pool, holiday.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := scanHoliday(tx.QueryRow(ctx, query,
+		holiday.TenantID,
+		holiday.Date,
+		holiday.Name,
+		holiday.Location,
+		holiday.IsDayOff,
+		holiday.Source,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityHoliday, "holidays", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (h *HolidayRepository) UpdateHoliday(ctx context.Context, holiday *models.Holiday) (*models.Holiday, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE holidays
+	SET holiday_date = $3, name = $4, location = $5, is_day_off = $6, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $1 AND id = $2
+	RETURNING ` + holidayColumns
+
+	tx, err := beginAudited(ctx, h.pool, holiday.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "holidays", holiday.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := scanHoliday(tx.QueryRow(ctx, query,
+		holiday.TenantID,
+		holiday.ID,
+		holiday.Date,
+		holiday.Name,
+		holiday.Location,
+		holiday.IsDayOff,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, updated.TenantID, AuditEntityHoliday, "holidays", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (h *HolidayRepository) DeleteHoliday(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, h.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityHoliday, "holidays", `tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return tx.Commit(ctx)
+}
+
+// AddHolidays inserts holidays in one transaction, leaving alone the dates the
+// location already has a holiday on, and returns how many were added
+func (h *HolidayRepository) AddHolidays(ctx context.Context, holidays []models.Holiday) (int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tenantIDs := make([]int, 0, len(holidays))
+	for _, holiday := range holidays {
+		tenantIDs = append(tenantIDs, holiday.TenantID)
+	}
+
+	tx, err := beginAudited(ctx, h.pool, tenantIDs...)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO holidays (tenant_id, holiday_date, name, location, is_day_off, source)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (tenant_id, holiday_date, location) DO NOTHING
+	RETURNING id
+	`
+
+	added := 0
+	for _, holiday := range holidays {
+		var id int
+		err := tx.QueryRow(ctx, query,
+			holiday.TenantID,
+			holiday.Date,
+			holiday.Name,
+			holiday.Location,
+			holiday.IsDayOff,
+			holiday.Source,
+		).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if err := auditRow(ctx, tx, holiday.TenantID, AuditEntityHoliday, "holidays", id, AuditActionCreate, nil); err != nil {
+			return 0, err
+		}
+		added++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// GetWorkLocation returns where the employee works, or an empty string when
+// it is not set
+func (h *HolidayRepository) GetWorkLocation(ctx context.Context, tenantID int, employeeID int) (string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT COALESCE(work_location, '')
+	FROM employees
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	var location string
+	err := h.pool.QueryRow(ctx, query, tenantID, employeeID).Scan(&location)
+	return location, err
+}
+
+func (h *HolidayRepository) UpdateWorkLocation(ctx context.Context, tenantID int, employeeID int, location string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, h.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `work_location = NULLIF($3, ''), updated_at = CURRENT_TIMESTAMP`
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityEmployee, "employees", set, `tenant_id = $1 AND id = $2`, tenantID, employeeID, location)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return pgx.ErrNoRows
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,293 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type InvitationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewInvitationRepository(pool *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{
+		pool: pool,
+	}
+}
+
+const invitationColumns = `id, tenant_id, employee_id, token_hash, status, expires_at, sent_count, last_sent_at, accepted_at, revoked_at, invited_by, mfa_secret, created_at, updated_at`
+
+func scanInvitation(row pgx.Row) (*models.EmployeeInvitation, error) {
+	var invitation models.EmployeeInvitation
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.TenantID,
+		&invitation.EmployeeID,
+		&invitation.TokenHash,
+		&invitation.Status,
+		&invitation.ExpiresAt,
+		&invitation.SentCount,
+		&invitation.LastSentAt,
+		&invitation.AcceptedAt,
+		&invitation.RevokedAt,
+		&invitation.InvitedBy,
+		&invitation.MFASecret,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (i *InvitationRepository) CreateInvitation(ctx context.Context, invitation *models.EmployeeInvitation) (*models.EmployeeInvitation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO employee_invitations (tenant_id, employee_id, token_hash, status, expires_at, invited_by)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING ` + invitationColumns
+
+	tx, err := beginAudited(ctx, i.pool, invitation.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query, invitation.TenantID, invitation.EmployeeID, invitation.TokenHash, invitation.Status, invitation.ExpiresAt, invitation.InvitedBy)
+	created, err := scanInvitation(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityInvitation, "employee_invitations", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (i *InvitationRepository) GetInvitationByID(ctx context.Context, id int) (*models.EmployeeInvitation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + invitationColumns + `
+	FROM employee_invitations
+	WHERE id = $1
+	`
+
+	row := i.pool.QueryRow(ctx, query, id)
+	return scanInvitation(row)
+}
+
+func (i *InvitationRepository) GetTenantInvitationByID(ctx context.Context, tenantID int, id int) (*models.EmployeeInvitation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + invitationColumns + `
+	FROM employee_invitations
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := i.pool.QueryRow(ctx, query, tenantID, id)
+	return scanInvitation(row)
+}
+
+// RotateInvitationToken replaces the token of a pending invitation so that any
+// previously sent link stops working, and records the send.
+func (i *InvitationRepository) RotateInvitationToken(ctx context.Context, id int, tokenHash string, expiresAt time.Time) (*models.EmployeeInvitation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE employee_invitations
+	SET token_hash = $1, expires_at = $2, sent_count = sent_count + 1, last_sent_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $3 AND status = 'pending'
+	RETURNING ` + invitationColumns
+
+	return i.updatePendingInvitation(ctx, id, query, tokenHash, expiresAt, id)
+}
+
+// SetInvitationMFASecret keeps the MFA secret offered to the invitee of a
+// pending invitation until they accept it
+func (i *InvitationRepository) SetInvitationMFASecret(ctx context.Context, id int, secret string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE employee_invitations
+	SET mfa_secret = $1, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $2 AND status = 'pending'
+	RETURNING ` + invitationColumns
+
+	_, err := i.updatePendingInvitation(ctx, id, query, secret, id)
+	return err
+}
+
+// AcceptInvitation marks a pending invitation accepted and activates its
+// draft employee with their own password and optional MFA secret, in one
+// transaction so that a link is only used up when the employee is activated.
+func (i *InvitationRepository) AcceptInvitation(ctx context.Context, id int, passwordHash string, mfaSecret *string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tenantID, err := rowTenantID(ctx, i.pool, "employee_invitations", id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("invitation is no longer pending")
+	}
+	if err != nil {
+		return err
+	}
+
+	tx, err := beginAudited(ctx, i.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	invitationBefore, err := snapshotRow(ctx, tx, "employee_invitations", id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("invitation is no longer pending")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Claiming the invitation first stops a link being redeemed twice
+	invitationQuery := `
+	UPDATE employee_invitations
+	SET status = 'accepted', accepted_at = CURRENT_TIMESTAMP, mfa_secret = NULL, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND status = 'pending'
+	RETURNING tenant_id, employee_id
+	`
+
+	var employeeID int
+	err = tx.QueryRow(ctx, invitationQuery, id).Scan(&tenantID, &employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("invitation is no longer pending")
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityInvitation, "employee_invitations", id, AuditActionUpdate, invitationBefore); err != nil {
+		return err
+	}
+
+	before, err := snapshotRow(ctx, tx, "employees", employeeID)
+	if err != nil {
+		return err
+	}
+
+	employeeQuery := `
+	UPDATE employees
+	SET password_hash = $1, mfa_secret = $2, mfa_enabled = $3, status = 'active', updated_at = CURRENT_TIMESTAMP
+	WHERE id = $4 AND status = 'draft'
+	`
+
+	result, err := tx.Exec(ctx, employeeQuery, passwordHash, mfaSecret, mfaSecret != nil, employeeID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("employee is not awaiting activation")
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityEmployee, "employees", employeeID, AuditActionUpdate, before); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (i *InvitationRepository) RevokeInvitation(ctx context.Context, tenantID int, id int) (*models.EmployeeInvitation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE employee_invitations
+	SET status = 'revoked', revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $1 AND id = $2 AND status = 'pending'
+	RETURNING ` + invitationColumns
+
+	return i.updatePendingInvitation(ctx, id, query, tenantID, id)
+}
+
+// updatePendingInvitation runs query, an update of the pending invitation
+// with the given id returning its columns, and audits the change
+func (i *InvitationRepository) updatePendingInvitation(ctx context.Context, id int, query string, args ...interface{}) (*models.EmployeeInvitation, error) {
+	tenantID, err := rowTenantID(ctx, i.pool, "employee_invitations", id)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("invitation is no longer pending")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := beginAudited(ctx, i.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "employee_invitations", id)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("invitation is no longer pending")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := scanInvitation(tx.QueryRow(ctx, query, args...))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("invitation is no longer pending")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, invitation.TenantID, AuditEntityInvitation, "employee_invitations", id, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
@@ -0,0 +1,845 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+// Ledger entry types
+const (
+	LedgerAccrual     = "accrual"
+	LedgerDeduction   = "deduction"
+	LedgerRestoration = "restoration"
+	LedgerAdjustment  = "adjustment"
+	LedgerCarryOver   = "carry_over"
+	LedgerExpiry      = "expiry"
+)
+
+// Period keys of the entries written when a leave year is rolled over and
+// when the days it carried over expire
+const (
+	rolloverPeriodKey        = "rollover"
+	carryOverExpiryPeriodKey = "carry_over"
+)
+
+type LeaveAccrualRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLeaveAccrualRepository(pool *pgxpool.Pool) *LeaveAccrualRepository {
+	return &LeaveAccrualRepository{
+		pool: pool,
+	}
+}
+
+const leaveAccrualPolicyColumns = `id, tenant_id, leave_type_id, frequency, COALESCE(pay_periods_per_year, 0), COALESCE(prorate_first_year, TRUE), carry_over_max_days::float8, carry_over_expiry_months, created_at, updated_at`
+
+func scanLeaveAccrualPolicy(row pgx.Row) (*models.LeaveAccrualPolicy, error) {
+	var policy models.LeaveAccrualPolicy
+	err := row.Scan(
+		&policy.ID,
+		&policy.TenantID,
+		&policy.LeaveTypeID,
+		&policy.Frequency,
+		&policy.PayPeriodsPerYear,
+		&policy.ProrateFirstYear,
+		&policy.CarryOverMaxDays,
+		&policy.CarryOverExpiryMonths,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+const leaveLedgerEntryColumns = `id, tenant_id, employee_id, leave_type_id, year, entry_type, days::float8, COALESCE(period_key, ''), leave_request_id, COALESCE(note, ''), created_by, created_at`
+
+func scanLeaveLedgerEntry(row pgx.Row) (*models.LeaveLedgerEntry, error) {
+	var entry models.LeaveLedgerEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.TenantID,
+		&entry.EmployeeID,
+		&entry.LeaveTypeID,
+		&entry.Year,
+		&entry.EntryType,
+		&entry.Days,
+		&entry.PeriodKey,
+		&entry.LeaveRequestID,
+		&entry.Note,
+		&entry.CreatedBy,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// insertLeaveLedgerEntry records the entry and applies it to the balance it
+// belongs to. An entry whose period key is already recorded is skipped and
+// reported as pgx.ErrNoRows.
+func insertLeaveLedgerEntry(ctx context.Context, tx pgx.Tx, entry *models.LeaveLedgerEntry) (*models.LeaveLedgerEntry, error) {
+	insertQuery := `
+	INSERT INTO leave_ledger_entries (tenant_id, employee_id, leave_type_id, year, entry_type, days, period_key, leave_request_id, note, created_by)
+	VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, NULLIF($9, ''), $10)
+	ON CONFLICT (employee_id, leave_type_id, year, entry_type, period_key) DO NOTHING
+	RETURNING ` + leaveLedgerEntryColumns
+
+	row := tx.QueryRow(ctx, insertQuery,
+		entry.TenantID,
+		entry.EmployeeID,
+		entry.LeaveTypeID,
+		entry.Year,
+		entry.EntryType,
+		entry.Days,
+		entry.PeriodKey,
+		entry.LeaveRequestID,
+		entry.Note,
+		entry.CreatedBy,
+	)
+	created, err := scanLeaveLedgerEntry(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityLeaveLedgerEntry, "leave_ledger_entries", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	balanceID, err := leaveBalanceID(ctx, tx, created.TenantID, created.EmployeeID, created.LeaveTypeID, created.Year)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := snapshotRow(ctx, tx, "leave_balances", balanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	balanceQuery := `
+	UPDATE leave_balances
+	SET balance_days = balance_days + $1, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $2
+	`
+
+	if _, err := tx.Exec(ctx, balanceQuery, created.Days, balanceID); err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityLeaveBalance, "leave_balances", balanceID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// leaveBalanceID returns the ID of the employee's balance of a leave type for
+// the year, creating an empty one if there is none
+func leaveBalanceID(ctx context.Context, tx pgx.Tx, tenantID int, employeeID int, leaveTypeID int, year int) (int, error) {
+	createQuery := `
+	INSERT INTO leave_balances (employee_id, leave_type_id, year, balance_days)
+	VALUES ($1, $2, $3, 0)
+	ON CONFLICT (employee_id, leave_type_id, year) DO NOTHING
+	RETURNING id
+	`
+
+	var id int
+	err := tx.QueryRow(ctx, createQuery, employeeID, leaveTypeID, year).Scan(&id)
+	if err == nil {
+		return id, auditRow(ctx, tx, tenantID, AuditEntityLeaveBalance, "leave_balances", id, AuditActionCreate, nil)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	query := `
+	SELECT id
+	FROM leave_balances
+	WHERE employee_id = $1 AND leave_type_id = $2 AND year = $3
+	`
+
+	err = tx.QueryRow(ctx, query, employeeID, leaveTypeID, year).Scan(&id)
+	return id, err
+}
+
+// lockLeaveBalance locks the employee's balance of a leave type for the year,
+// creating an empty one if there is none, and returns it
+func lockLeaveBalance(ctx context.Context, tx pgx.Tx, tenantID int, employeeID int, leaveTypeID int, year int) (float64, error) {
+	id, err := leaveBalanceID(ctx, tx, tenantID, employeeID, leaveTypeID, year)
+	if err != nil {
+		return 0, err
+	}
+
+	lockQuery := `
+	SELECT COALESCE(balance_days, 0)::float8
+	FROM leave_balances
+	WHERE id = $1
+	FOR UPDATE
+	`
+
+	var balance float64
+	err = tx.QueryRow(ctx, lockQuery, id).Scan(&balance)
+	return balance, err
+}
+
+func (l *LeaveAccrualRepository) GetAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int) (*models.LeaveAccrualPolicy, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + leaveAccrualPolicyColumns + `
+	FROM leave_accrual_policies
+	WHERE tenant_id = $1 AND leave_type_id = $2
+	`
+
+	policy, err := scanLeaveAccrualPolicy(l.pool.QueryRow(ctx, query, tenantID, leaveTypeID))
+	if err != nil {
+		return nil, err
+	}
+
+	tiers, err := l.listTiers(ctx, []int{policy.ID})
+	if err != nil {
+		return nil, err
+	}
+	policy.Tiers = tiers[policy.ID]
+	if policy.Tiers == nil {
+		policy.Tiers = []models.LeaveAccrualTier{}
+	}
+	return policy, nil
+}
+
+// listTiers returns the tiers of each policy ordered by tenure
+func (l *LeaveAccrualRepository) listTiers(ctx context.Context, policyIDs []int) (map[int][]models.LeaveAccrualTier, error) {
+	query := `
+	SELECT policy_id, min_tenure_months, days_per_year::float8
+	FROM leave_accrual_tiers
+	WHERE policy_id = ANY($1)
+	ORDER BY policy_id, min_tenure_months
+	`
+
+	rows, err := l.pool.Query(ctx, query, policyIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := map[int][]models.LeaveAccrualTier{}
+	for rows.Next() {
+		var policyID int
+		var tier models.LeaveAccrualTier
+		if err := rows.Scan(&policyID, &tier.MinTenureMonths, &tier.DaysPerYear); err != nil {
+			return nil, err
+		}
+		tiers[policyID] = append(tiers[policyID], tier)
+	}
+
+	return tiers, rows.Err()
+}
+
+// SaveAccrualPolicy creates or replaces the leave type's policy together with
+// its tiers
+func (l *LeaveAccrualRepository) SaveAccrualPolicy(ctx context.Context, policy *models.LeaveAccrualPolicy) (*models.LeaveAccrualPolicy, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, policy.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The policy is created on the first save and replaced after that
+	var before map[string]interface{}
+	err = tx.QueryRow(ctx, `SELECT to_jsonb(t) FROM leave_accrual_policies t WHERE t.leave_type_id = $1 FOR UPDATE`, policy.LeaveTypeID).Scan(&before)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	action := AuditActionUpdate
+	if before == nil {
+		action = AuditActionCreate
+	}
+
+	upsertQuery := `
+	INSERT INTO leave_accrual_policies (tenant_id, leave_type_id, frequency, pay_periods_per_year, prorate_first_year, carry_over_max_days, carry_over_expiry_months)
+	VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7)
+	ON CONFLICT (leave_type_id)
+	DO UPDATE SET frequency = EXCLUDED.frequency, pay_periods_per_year = EXCLUDED.pay_periods_per_year, prorate_first_year = EXCLUDED.prorate_first_year,
+		carry_over_max_days = EXCLUDED.carry_over_max_days, carry_over_expiry_months = EXCLUDED.carry_over_expiry_months, updated_at = CURRENT_TIMESTAMP
+	RETURNING ` + leaveAccrualPolicyColumns
+
+	row := tx.QueryRow(ctx, upsertQuery,
+		policy.TenantID,
+		policy.LeaveTypeID,
+		policy.Frequency,
+		policy.PayPeriodsPerYear,
+		policy.ProrateFirstYear,
+		policy.CarryOverMaxDays,
+		policy.CarryOverExpiryMonths,
+	)
+	saved, err := scanLeaveAccrualPolicy(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, saved.TenantID, AuditEntityLeaveAccrualPolicy, "leave_accrual_policies", saved.ID, action, before); err != nil {
+		return nil, err
+	}
+
+	if _, err := deleteAuditedRows(ctx, tx, saved.TenantID, AuditEntityLeaveAccrualTier, "leave_accrual_tiers", `policy_id = $1`, saved.ID); err != nil {
+		return nil, err
+	}
+
+	tierQuery := `
+	INSERT INTO leave_accrual_tiers (policy_id, min_tenure_months, days_per_year)
+	VALUES ($1, $2, $3)
+	RETURNING id
+	`
+
+	for _, tier := range policy.Tiers {
+		var tierID int
+		if err := tx.QueryRow(ctx, tierQuery, saved.ID, tier.MinTenureMonths, tier.DaysPerYear).Scan(&tierID); err != nil {
+			return nil, err
+		}
+		if err := auditRow(ctx, tx, saved.TenantID, AuditEntityLeaveAccrualTier, "leave_accrual_tiers", tierID, AuditActionCreate, nil); err != nil {
+			return nil, err
+		}
+	}
+	saved.Tiers = policy.Tiers
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (l *LeaveAccrualRepository) DeleteAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// The tiers would go with the policy, but are deleted first to be audited
+	tiersWhere := `policy_id IN (SELECT id FROM leave_accrual_policies WHERE tenant_id = $1 AND leave_type_id = $2)`
+	if _, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityLeaveAccrualTier, "leave_accrual_tiers", tiersWhere, tenantID, leaveTypeID); err != nil {
+		return err
+	}
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityLeaveAccrualPolicy, "leave_accrual_policies", `tenant_id = $1 AND leave_type_id = $2`, tenantID, leaveTypeID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AccrualPolicyRow is a policy of an active leave type together with what the
+// accrual run needs to know about the leave type and its tenant
+type AccrualPolicyRow struct {
+	Policy              models.LeaveAccrualPolicy
+	DaysPerYear         int
+	EligibleGender      string
+	LeaveYearStartMonth time.Month
+}
+
+// ListAccrualPolicies returns the policies of active leave types, for one
+// tenant or, when tenantID is zero, for every tenant
+func (l *LeaveAccrualRepository) ListAccrualPolicies(ctx context.Context, tenantID int) ([]AccrualPolicyRow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT p.id, p.tenant_id, p.leave_type_id, p.frequency, COALESCE(p.pay_periods_per_year, 0), COALESCE(p.prorate_first_year, TRUE),
+		p.carry_over_max_days::float8, p.carry_over_expiry_months, p.created_at, p.updated_at,
+		COALESCE(lt.days_per_year, 0), COALESCE(lt.eligible_gender, ''), COALESCE(c.leave_year_start_month, 1)
+	FROM leave_accrual_policies p
+	JOIN leave_types lt ON lt.id = p.leave_type_id
+	JOIN tenants t ON t.id = p.tenant_id
+	JOIN companies c ON c.id = t.company_id
+	WHERE ($1 = 0 OR p.tenant_id = $1) AND COALESCE(lt.status, 'active') = 'active'
+	ORDER BY p.tenant_id, p.id
+	`
+
+	rows, err := l.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := []AccrualPolicyRow{}
+	policyIDs := []int{}
+	for rows.Next() {
+		var p AccrualPolicyRow
+		var startMonth int
+		err := rows.Scan(
+			&p.Policy.ID,
+			&p.Policy.TenantID,
+			&p.Policy.LeaveTypeID,
+			&p.Policy.Frequency,
+			&p.Policy.PayPeriodsPerYear,
+			&p.Policy.ProrateFirstYear,
+			&p.Policy.CarryOverMaxDays,
+			&p.Policy.CarryOverExpiryMonths,
+			&p.Policy.CreatedAt,
+			&p.Policy.UpdatedAt,
+			&p.DaysPerYear,
+			&p.EligibleGender,
+			&startMonth,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p.LeaveYearStartMonth = time.Month(startMonth)
+		policies = append(policies, p)
+		policyIDs = append(policyIDs, p.Policy.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tiers, err := l.listTiers(ctx, policyIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range policies {
+		policies[i].Policy.Tiers = tiers[policies[i].Policy.ID]
+	}
+	return policies, nil
+}
+
+// AccrualEmployee is an employee who earns leave, with what decides how much
+type AccrualEmployee struct {
+	ID       int
+	HireDate *time.Time
+	Gender   string
+}
+
+// ListAccrualEmployees returns the tenant's employees who have not been
+// terminated
+func (l *LeaveAccrualRepository) ListAccrualEmployees(ctx context.Context, tenantID int) ([]AccrualEmployee, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT e.id, e.hire_date, COALESCE(pd.gender, '')
+	FROM employees e
+	LEFT JOIN employee_personal_details pd ON pd.employee_id = e.id AND pd.tenant_id = e.tenant_id
+	WHERE e.tenant_id = $1 AND COALESCE(e.status, '') <> 'terminated'
+	ORDER BY e.id
+	`
+
+	rows, err := l.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []AccrualEmployee{}
+	for rows.Next() {
+		var employee AccrualEmployee
+		if err := rows.Scan(&employee.ID, &employee.HireDate, &employee.Gender); err != nil {
+			return nil, err
+		}
+		employees = append(employees, employee)
+	}
+
+	return employees, rows.Err()
+}
+
+// ListAccruedPeriods returns, per employee, the periods of the leave year
+// already accrued for the leave type
+func (l *LeaveAccrualRepository) ListAccruedPeriods(ctx context.Context, leaveTypeID int, year int) (map[int]map[string]bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT employee_id, period_key
+	FROM leave_ledger_entries
+	WHERE leave_type_id = $1 AND year = $2 AND entry_type = 'accrual' AND period_key IS NOT NULL
+	`
+
+	rows, err := l.pool.Query(ctx, query, leaveTypeID, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accrued := map[int]map[string]bool{}
+	for rows.Next() {
+		var employeeID int
+		var periodKey string
+		if err := rows.Scan(&employeeID, &periodKey); err != nil {
+			return nil, err
+		}
+		if accrued[employeeID] == nil {
+			accrued[employeeID] = map[string]bool{}
+		}
+		accrued[employeeID][periodKey] = true
+	}
+
+	return accrued, rows.Err()
+}
+
+// AddLedgerEntry records an entry that is not checked against the balance,
+// such as an accrual. It returns pgx.ErrNoRows when the entry's period key is
+// already recorded.
+func (l *LeaveAccrualRepository) AddLedgerEntry(ctx context.Context, entry *models.LeaveLedgerEntry) (*models.LeaveLedgerEntry, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, entry.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := insertLeaveLedgerEntry(ctx, tx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// AdjustBalance records a manual adjustment and fails with
+// ErrInsufficientBalance when it would take the balance below zero
+func (l *LeaveAccrualRepository) AdjustBalance(ctx context.Context, entry *models.LeaveLedgerEntry) (*models.LeaveLedgerEntry, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, entry.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	balance, err := lockLeaveBalance(ctx, tx, entry.TenantID, entry.EmployeeID, entry.LeaveTypeID, entry.Year)
+	if err != nil {
+		return nil, err
+	}
+	if balance+entry.Days < 0 {
+		return nil, ErrInsufficientBalance
+	}
+
+	created, err := insertLeaveLedgerEntry(ctx, tx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ListRolloverCandidates returns the employees whose balance of the leave
+// type for fromYear has not been rolled over yet
+func (l *LeaveAccrualRepository) ListRolloverCandidates(ctx context.Context, leaveTypeID int, fromYear int) ([]int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT lb.employee_id
+	FROM leave_balances lb
+	JOIN employees e ON e.id = lb.employee_id
+	WHERE lb.leave_type_id = $1 AND lb.year = $2 AND COALESCE(e.status, '') <> 'terminated'
+	  AND NOT EXISTS (
+		SELECT 1 FROM leave_ledger_entries le
+		WHERE le.employee_id = lb.employee_id AND le.leave_type_id = lb.leave_type_id AND le.year = lb.year
+		  AND le.entry_type = 'carry_over' AND le.period_key = 'rollover'
+	  )
+	ORDER BY lb.employee_id
+	`
+
+	return l.listEmployeeIDs(ctx, query, leaveTypeID, fromYear)
+}
+
+// ListCarryOverExpiryCandidates returns the employees who carried days of the
+// leave type into year and have not had them expired yet
+func (l *LeaveAccrualRepository) ListCarryOverExpiryCandidates(ctx context.Context, leaveTypeID int, year int) ([]int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT le.employee_id
+	FROM leave_ledger_entries le
+	WHERE le.leave_type_id = $1 AND le.year = $2 AND le.entry_type = 'carry_over' AND le.period_key = 'rollover' AND le.days > 0
+	  AND NOT EXISTS (
+		SELECT 1 FROM leave_ledger_entries ex
+		WHERE ex.employee_id = le.employee_id AND ex.leave_type_id = le.leave_type_id AND ex.year = le.year
+		  AND ex.entry_type = 'expiry' AND ex.period_key = 'carry_over'
+	  )
+	ORDER BY le.employee_id
+	`
+
+	return l.listEmployeeIDs(ctx, query, leaveTypeID, year)
+}
+
+func (l *LeaveAccrualRepository) listEmployeeIDs(ctx context.Context, query string, args ...interface{}) ([]int, error) {
+	rows, err := l.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// RolloverBalance closes the employee's balance of a leave type for fromYear.
+// Up to maxCarry days (all of them when maxCarry is nil) move into the next
+// year and the rest expire. It returns the days carried and expired, or
+// pgx.ErrNoRows when the balance was already rolled over.
+func (l *LeaveAccrualRepository) RolloverBalance(ctx context.Context, tenantID int, employeeID int, leaveTypeID int, fromYear int, maxCarry *float64) (float64, float64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	balance, err := lockLeaveBalance(ctx, tx, tenantID, employeeID, leaveTypeID, fromYear)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	carried := 0.0
+	if balance > 0 {
+		carried = balance
+		if maxCarry != nil && carried > *maxCarry {
+			carried = *maxCarry
+		}
+	}
+	expired := 0.0
+	if balance > carried {
+		expired = balance - carried
+	}
+
+	// The entry closing the old year is written even when nothing moves, so
+	// that the balance is only ever rolled over once
+	entries := []models.LeaveLedgerEntry{{
+		Year: fromYear, EntryType: LedgerCarryOver, Days: -carried, Note: fmt.Sprintf("Carried over to %d", fromYear+1),
+	}}
+	if expired > 0 {
+		entries = append(entries, models.LeaveLedgerEntry{
+			Year: fromYear, EntryType: LedgerExpiry, Days: -expired, Note: "Expired at the end of the leave year",
+		})
+	}
+	if carried > 0 {
+		entries = append(entries, models.LeaveLedgerEntry{
+			Year: fromYear + 1, EntryType: LedgerCarryOver, Days: carried, Note: fmt.Sprintf("Carried over from %d", fromYear),
+		})
+	}
+
+	for i := range entries {
+		entries[i].TenantID = tenantID
+		entries[i].EmployeeID = employeeID
+		entries[i].LeaveTypeID = leaveTypeID
+		entries[i].PeriodKey = rolloverPeriodKey
+		if _, err := insertLeaveLedgerEntry(ctx, tx, &entries[i]); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	return carried, expired, nil
+}
+
+// ExpireCarryOver removes the days carried into year that the employee has
+// not used. Leave taken in the year uses carried days first. It returns the
+// days expired, or pgx.ErrNoRows when they were already expired.
+func (l *LeaveAccrualRepository) ExpireCarryOver(ctx context.Context, tenantID int, employeeID int, leaveTypeID int, year int) (float64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	balance, err := lockLeaveBalance(ctx, tx, tenantID, employeeID, leaveTypeID, year)
+	if err != nil {
+		return 0, err
+	}
+
+	usageQuery := `
+	SELECT
+		COALESCE(SUM(days) FILTER (WHERE entry_type = 'carry_over' AND period_key = 'rollover'), 0)::float8,
+		COALESCE(-SUM(days) FILTER (WHERE entry_type IN ('deduction', 'restoration')), 0)::float8
+	FROM leave_ledger_entries
+	WHERE employee_id = $1 AND leave_type_id = $2 AND year = $3
+	`
+
+	var carried, used float64
+	if err := tx.QueryRow(ctx, usageQuery, employeeID, leaveTypeID, year).Scan(&carried, &used); err != nil {
+		return 0, err
+	}
+
+	expired := carried - used
+	if expired > balance {
+		expired = balance
+	}
+	if expired < 0 {
+		expired = 0
+	}
+
+	// Recorded even when nothing is left to expire so the check runs once
+	_, err = insertLeaveLedgerEntry(ctx, tx, &models.LeaveLedgerEntry{
+		TenantID:    tenantID,
+		EmployeeID:  employeeID,
+		LeaveTypeID: leaveTypeID,
+		Year:        year,
+		EntryType:   LedgerExpiry,
+		Days:        -expired,
+		PeriodKey:   carryOverExpiryPeriodKey,
+		Note:        "Unused carried-over days expired",
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return expired, nil
+}
+
+// ListLedgerEntries returns the employee's ledger for a leave year, optionally
+// limited to one leave type, oldest first
+func (l *LeaveAccrualRepository) ListLedgerEntries(ctx context.Context, tenantID int, employeeID int, leaveTypeID int, year int) ([]models.LeaveLedgerEntry, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + leaveLedgerEntryColumns + `
+	FROM leave_ledger_entries
+	WHERE tenant_id = $1 AND employee_id = $2 AND year = $3 AND ($4 = 0 OR leave_type_id = $4)
+	ORDER BY created_at, id
+	`
+
+	rows, err := l.pool.Query(ctx, query, tenantID, employeeID, year, leaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.LeaveLedgerEntry{}
+	for rows.Next() {
+		entry, err := scanLeaveLedgerEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ListLeaveBalances returns the employee's balances for a leave year together
+// with the days held by their pending requests
+func (l *LeaveAccrualRepository) ListLeaveBalances(ctx context.Context, tenantID int, employeeID int, year int) ([]models.LeaveBalance, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT lb.employee_id, lt.id, lt.name, lb.year, COALESCE(lb.balance_days, 0)::float8,
+		COALESCE((SELECT SUM(lr.days_requested) FROM leave_requests lr
+			WHERE lr.tenant_id = $1 AND lr.employee_id = lb.employee_id AND lr.leave_type_id = lt.id AND lr.status = 'pending'
+			  AND COALESCE(lr.leave_year, EXTRACT(YEAR FROM lr.start_date)::int) = lb.year), 0)::float8
+	FROM leave_balances lb
+	JOIN leave_types lt ON lt.id = lb.leave_type_id
+	WHERE lt.tenant_id = $1 AND lb.employee_id = $2 AND lb.year = $3
+	ORDER BY lt.name, lt.id
+	`
+
+	rows, err := l.pool.Query(ctx, query, tenantID, employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []models.LeaveBalance{}
+	for rows.Next() {
+		var balance models.LeaveBalance
+		if err := rows.Scan(&balance.EmployeeID, &balance.LeaveTypeID, &balance.LeaveTypeName, &balance.Year, &balance.BalanceDays, &balance.PendingDays); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, rows.Err()
+}
@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type LeaveCalendarRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLeaveCalendarRepository(pool *pgxpool.Pool) *LeaveCalendarRepository {
+	return &LeaveCalendarRepository{
+		pool: pool,
+	}
+}
+
+// subtreeQuery selects the employee $2 and everyone reporting to them,
+// directly or indirectly
+const subtreeQuery = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM employees WHERE tenant_id = $1 AND id = $2
+		UNION
+		SELECT e.id FROM employees e JOIN subtree s ON e.manager_id = s.id WHERE e.tenant_id = $1
+	)
+	`
+
+// ListCalendarLeave returns leave in the statuses that overlaps from to to
+// inclusive. A non-zero rootID limits it to that employee's reporting subtree
+// and a non-zero departmentID to one department.
+func (l *LeaveCalendarRepository) ListCalendarLeave(ctx context.Context, tenantID int, rootID int, departmentID int, statuses []string, from time.Time, to time.Time) ([]models.LeaveCalendarEntry, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	args := []interface{}{tenantID, rootID, statuses, from, to}
+	conditions := "lr.tenant_id = $1 AND lr.status = ANY($3) AND lr.start_date <= $5 AND lr.end_date >= $4"
+	if rootID != 0 {
+		conditions += " AND lr.employee_id IN (SELECT id FROM subtree)"
+	}
+	if departmentID != 0 {
+		args = append(args, departmentID)
+		conditions += fmt.Sprintf(" AND e.department_id = $%d", len(args))
+	}
+
+	query := subtreeQuery + `
+	SELECT lr.id, lr.employee_id, e.first_name || ' ' || e.last_name, e.department_id, COALESCE(lt.name, ''),
+		lr.start_date, lr.end_date, COALESCE(lr.days_requested, 0)::float8, COALESCE(lr.half_day, FALSE), lr.status
+	FROM leave_requests lr
+	JOIN employees e ON e.id = lr.employee_id
+	LEFT JOIN leave_types lt ON lt.id = lr.leave_type_id
+	WHERE ` + conditions + `
+	ORDER BY lr.start_date, e.first_name, e.last_name, lr.id
+	`
+
+	rows, err := l.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.LeaveCalendarEntry{}
+	for rows.Next() {
+		var entry models.LeaveCalendarEntry
+		err := rows.Scan(
+			&entry.LeaveRequestID,
+			&entry.EmployeeID,
+			&entry.EmployeeName,
+			&entry.DepartmentID,
+			&entry.LeaveTypeName,
+			&entry.StartDate,
+			&entry.EndDate,
+			&entry.DaysRequested,
+			&entry.HalfDay,
+			&entry.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// IsInSubtree reports whether employeeID reports to rootID, directly or
+// indirectly, or is rootID
+func (l *LeaveCalendarRepository) IsInSubtree(ctx context.Context, tenantID int, rootID int, employeeID int) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := subtreeQuery + `
+	SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $3)
+	`
+
+	var found bool
+	err := l.pool.QueryRow(ctx, query, tenantID, rootID, employeeID).Scan(&found)
+	return found, err
+}
+
+// SaveCalendarFeed issues the employee's feed token, replacing any earlier one
+func (l *LeaveCalendarRepository) SaveCalendarFeed(ctx context.Context, tenantID int, employeeID int, tokenHash string) (time.Time, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO calendar_feeds (tenant_id, employee_id, token_hash)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (employee_id)
+	DO UPDATE SET token_hash = EXCLUDED.token_hash, last_used_at = NULL, created_at = CURRENT_TIMESTAMP
+	RETURNING id, created_at
+	`
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The feed is created on the first save and replaced after that
+	var before map[string]interface{}
+	err = tx.QueryRow(ctx, `SELECT to_jsonb(t) FROM calendar_feeds t WHERE t.employee_id = $1 FOR UPDATE`, employeeID).Scan(&before)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, err
+	}
+	action := AuditActionUpdate
+	if before == nil {
+		action = AuditActionCreate
+	}
+
+	var id int
+	var createdAt time.Time
+	if err := tx.QueryRow(ctx, query, tenantID, employeeID, tokenHash).Scan(&id, &createdAt); err != nil {
+		return time.Time{}, err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityCalendarFeed, "calendar_feeds", id, action, before); err != nil {
+		return time.Time{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return time.Time{}, err
+	}
+	return createdAt, nil
+}
+
+func (l *LeaveCalendarRepository) DeleteCalendarFeed(ctx context.Context, tenantID int, employeeID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityCalendarFeed, "calendar_feeds", `tenant_id = $1 AND employee_id = $2`, tenantID, employeeID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CalendarFeedOwner is the employee a feed token was issued to
+type CalendarFeedOwner struct {
+	TenantID   int
+	EmployeeID int
+	Role       string
+	Status     string
+}
+
+// UseCalendarFeed returns the owner of the feed with the token hash and
+// records that the feed was read
+func (l *LeaveCalendarRepository) UseCalendarFeed(ctx context.Context, tokenHash string) (*CalendarFeedOwner, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE calendar_feeds f
+	SET last_used_at = CURRENT_TIMESTAMP
+	FROM employees e
+	LEFT JOIN roles r ON r.id = e.role_id
+	WHERE f.token_hash = $1 AND e.id = f.employee_id
+	RETURNING f.tenant_id, f.employee_id, COALESCE(r.name, ''), e.status
+	`
+
+	var owner CalendarFeedOwner
+	err := l.pool.QueryRow(ctx, query, tokenHash).Scan(&owner.TenantID, &owner.EmployeeID, &owner.Role, &owner.Status)
+	if err != nil {
+		return nil, err
+	}
+	return &owner, nil
+}
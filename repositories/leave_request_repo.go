@@ -0,0 +1,568 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+// ErrInsufficientBalance is returned when approving leave would take the
+// employee's balance below zero.
+var ErrInsufficientBalance = errors.New("insufficient leave balance")
+
+// ErrLeaveOverlap is returned when a new request shares a day with the
+// employee's pending or approved leave.
+var ErrLeaveOverlap = errors.New("leave overlaps an existing request")
+
+// InsufficientBalanceError is the ErrInsufficientBalance returned when
+// submitting leave, with the days the employee still has available.
+type InsufficientBalanceError struct {
+	Available float64
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("%s: %.2f days available", ErrInsufficientBalance, e.Available)
+}
+
+func (e *InsufficientBalanceError) Is(target error) bool {
+	return target == ErrInsufficientBalance
+}
+
+// leaveSubmissionLock is the first key of the transaction advisory lock
+// serializing an employee's leave submissions, the second being their ID
+const leaveSubmissionLock = 4602
+
+type LeaveRequestRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLeaveRequestRepository(pool *pgxpool.Pool) *LeaveRequestRepository {
+	return &LeaveRequestRepository{
+		pool: pool,
+	}
+}
+
+const leaveRequestColumns = `id, tenant_id, employee_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = employee_id), ''),
+	leave_type_id,
+	COALESCE((SELECT lt.name FROM leave_types lt WHERE lt.id = leave_type_id), ''),
+	start_date, end_date, COALESCE(leave_year, EXTRACT(YEAR FROM start_date)::int), COALESCE(days_requested, 0)::float8, COALESCE(half_day, FALSE), COALESCE(reason, ''), COALESCE(attachment_url, ''),
+	status, approval_workflow_id, COALESCE(deducted_days, 0)::float8, decided_by, decided_at, COALESCE(decision_comment, ''),
+	cancelled_by, cancelled_at, COALESCE(cancellation_reason, ''), created_at, updated_at`
+
+func scanLeaveRequest(row pgx.Row) (*models.LeaveRequest, error) {
+	var request models.LeaveRequest
+	err := row.Scan(
+		&request.ID,
+		&request.TenantID,
+		&request.EmployeeID,
+		&request.EmployeeName,
+		&request.LeaveTypeID,
+		&request.LeaveTypeName,
+		&request.StartDate,
+		&request.EndDate,
+		&request.LeaveYear,
+		&request.DaysRequested,
+		&request.HalfDay,
+		&request.Reason,
+		&request.AttachmentURL,
+		&request.Status,
+		&request.ApprovalWorkflowID,
+		&request.DeductedDays,
+		&request.DecidedBy,
+		&request.DecidedAt,
+		&request.DecisionComment,
+		&request.CancelledBy,
+		&request.CancelledAt,
+		&request.CancellationReason,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetWorkDays returns the days of the week the tenant's company works
+func (l *LeaveRequestRepository) GetWorkDays(ctx context.Context, tenantID int) ([]time.Weekday, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT COALESCE(c.work_days, '{1,2,3,4,5}')
+	FROM companies c
+	JOIN tenants t ON t.company_id = c.id
+	WHERE t.id = $1
+	`
+
+	var days []int32
+	if err := l.pool.QueryRow(ctx, query, tenantID).Scan(&days); err != nil {
+		return nil, err
+	}
+
+	workDays := make([]time.Weekday, len(days))
+	for i, day := range days {
+		workDays[i] = time.Weekday(day)
+	}
+	return workDays, nil
+}
+
+func (l *LeaveRequestRepository) UpdateWorkDays(ctx context.Context, tenantID int, workDays []time.Weekday) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	days := make([]int32, len(workDays))
+	for i, day := range workDays {
+		days[i] = int32(day)
+	}
+
+	return l.updateTenantCompany(ctx, tenantID, `work_days = $1`, days)
+}
+
+// GetLeaveYearStartMonth returns the month the tenant's leave year starts in
+func (l *LeaveRequestRepository) GetLeaveYearStartMonth(ctx context.Context, tenantID int) (time.Month, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT COALESCE(c.leave_year_start_month, 1)
+	FROM companies c
+	JOIN tenants t ON t.company_id = c.id
+	WHERE t.id = $1
+	`
+
+	var month int
+	if err := l.pool.QueryRow(ctx, query, tenantID).Scan(&month); err != nil {
+		return 0, err
+	}
+	return time.Month(month), nil
+}
+
+func (l *LeaveRequestRepository) UpdateLeaveYearStartMonth(ctx context.Context, tenantID int, month time.Month) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	return l.updateTenantCompany(ctx, tenantID, `leave_year_start_month = $1`, int(month))
+}
+
+// updateTenantCompany applies set, a change of the company's leave settings
+// to value, to the company of the tenant and audits it
+func (l *LeaveRequestRepository) updateTenantCompany(ctx context.Context, tenantID int, set string, value interface{}) error {
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var companyID int
+	if err := tx.QueryRow(ctx, `SELECT company_id FROM tenants WHERE id = $1`, tenantID).Scan(&companyID); err != nil {
+		return err
+	}
+
+	before, err := snapshotRow(ctx, tx, "companies", companyID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE companies
+	SET ` + set + `, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $2
+	`
+
+	if _, err := tx.Exec(ctx, query, value, companyID); err != nil {
+		return err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityCompany, "companies", companyID, AuditActionUpdate, before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateLeaveRequest inserts a pending request. It fails with ErrLeaveOverlap
+// when the employee has a pending or approved request sharing a day with it
+// and, when checkBalance is set, with an InsufficientBalanceError when the
+// balance of the leave year less the days held by pending requests does not
+// cover it. The checks and the insert run in one transaction under a lock on
+// the employee's submissions, so concurrent submissions cannot both pass.
+func (l *LeaveRequestRepository) CreateLeaveRequest(ctx context.Context, request *models.LeaveRequest, checkBalance bool) (*models.LeaveRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, request.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, leaveSubmissionLock, request.EmployeeID); err != nil {
+		return nil, err
+	}
+
+	overlapQuery := `
+	SELECT EXISTS (
+		SELECT 1 FROM leave_requests
+		WHERE tenant_id = $1 AND employee_id = $2 AND status IN ('pending', 'approved')
+		  AND start_date <= $4 AND end_date >= $3
+	)
+	`
+
+	var overlaps bool
+	if err := tx.QueryRow(ctx, overlapQuery, request.TenantID, request.EmployeeID, request.StartDate, request.EndDate).Scan(&overlaps); err != nil {
+		return nil, err
+	}
+	if overlaps {
+		return nil, ErrLeaveOverlap
+	}
+
+	if checkBalance {
+		// Decisions lock the balance too, so pending days cannot be approved
+		// away while they are counted
+		balance, err := lockLeaveBalance(ctx, tx, request.TenantID, request.EmployeeID, request.LeaveTypeID, request.LeaveYear)
+		if err != nil {
+			return nil, err
+		}
+
+		pendingQuery := `
+		SELECT COALESCE(SUM(days_requested), 0)::float8
+		FROM leave_requests
+		WHERE tenant_id = $1 AND employee_id = $2 AND leave_type_id = $3 AND status = 'pending'
+		  AND COALESCE(leave_year, EXTRACT(YEAR FROM start_date)::int) = $4
+		`
+
+		var pending float64
+		if err := tx.QueryRow(ctx, pendingQuery, request.TenantID, request.EmployeeID, request.LeaveTypeID, request.LeaveYear).Scan(&pending); err != nil {
+			return nil, err
+		}
+		if request.DaysRequested > balance-pending {
+			return nil, &InsufficientBalanceError{Available: balance - pending}
+		}
+	}
+
+	query := `
+	INSERT INTO leave_requests (tenant_id, employee_id, leave_type_id, start_date, end_date, leave_year, days_requested, half_day, reason, attachment_url, status)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), 'pending')
+	RETURNING ` + leaveRequestColumns
+
+	row := tx.QueryRow(ctx, query,
+		request.TenantID,
+		request.EmployeeID,
+		request.LeaveTypeID,
+		request.StartDate,
+		request.EndDate,
+		request.LeaveYear,
+		request.DaysRequested,
+		request.HalfDay,
+		request.Reason,
+		request.AttachmentURL,
+	)
+	created, err := scanLeaveRequest(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityLeaveRequest, "leave_requests", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (l *LeaveRequestRepository) GetLeaveRequest(ctx context.Context, tenantID int, id int) (*models.LeaveRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + leaveRequestColumns + `
+	FROM leave_requests
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := l.pool.QueryRow(ctx, query, tenantID, id)
+	return scanLeaveRequest(row)
+}
+
+// ListLeaveRequests returns the tenant's leave requests matching filter. A
+// non-zero managerID limits the list to that manager's direct reports.
+func (l *LeaveRequestRepository) ListLeaveRequests(ctx context.Context, tenantID int, managerID int, filter *dto.LeaveRequestFilter) ([]models.LeaveRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if managerID != 0 {
+		args = append(args, managerID)
+		conditions += fmt.Sprintf(" AND employee_id IN (SELECT id FROM employees WHERE tenant_id = $1 AND manager_id = $%d)", len(args))
+	}
+	if filter.EmployeeID != 0 {
+		args = append(args, filter.EmployeeID)
+		conditions += fmt.Sprintf(" AND employee_id = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	query := `
+	SELECT ` + leaveRequestColumns + `
+	FROM leave_requests
+	WHERE ` + conditions + `
+	ORDER BY start_date DESC, id DESC
+	`
+
+	rows, err := l.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.LeaveRequest{}
+	for rows.Next() {
+		request, err := scanLeaveRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	return requests, rows.Err()
+}
+
+// DecideLeaveRequest approves or rejects a pending request. Approving records
+// a deduction of deductDays from the balance of the request's leave year and
+// fails with ErrInsufficientBalance when the balance does not cover it. When
+// outer is set, such as the transaction deciding the request's approval, the
+// decision is made in a savepoint of it and is only saved when outer commits.
+func (l *LeaveRequestRepository) DecideLeaveRequest(ctx context.Context, outer pgx.Tx, tenantID int, id int, status string, decidedBy int, comment string, deductDays float64) (*models.LeaveRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginTx(ctx, l.pool, outer, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	lockQuery := `
+	SELECT employee_id, leave_type_id, COALESCE(leave_year, EXTRACT(YEAR FROM start_date)::int)
+	FROM leave_requests
+	WHERE tenant_id = $1 AND id = $2 AND status = 'pending'
+	FOR UPDATE
+	`
+
+	var employeeID, leaveTypeID, year int
+	if err := tx.QueryRow(ctx, lockQuery, tenantID, id).Scan(&employeeID, &leaveTypeID, &year); err != nil {
+		return nil, err
+	}
+
+	before, err := snapshotRow(ctx, tx, "leave_requests", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if deductDays > 0 {
+		balance, err := lockLeaveBalance(ctx, tx, tenantID, employeeID, leaveTypeID, year)
+		if err != nil {
+			return nil, err
+		}
+		if balance < deductDays {
+			return nil, ErrInsufficientBalance
+		}
+
+		_, err = insertLeaveLedgerEntry(ctx, tx, &models.LeaveLedgerEntry{
+			TenantID:       tenantID,
+			EmployeeID:     employeeID,
+			LeaveTypeID:    leaveTypeID,
+			Year:           year,
+			EntryType:      LedgerDeduction,
+			Days:           -deductDays,
+			LeaveRequestID: &id,
+			CreatedBy:      &decidedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updateQuery := `
+	UPDATE leave_requests
+	SET status = $1, decided_by = $2, decided_at = CURRENT_TIMESTAMP, decision_comment = NULLIF($3, ''), deducted_days = $4, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $5
+	RETURNING ` + leaveRequestColumns
+
+	row := tx.QueryRow(ctx, updateQuery, status, decidedBy, comment, deductDays, id)
+	decided, err := scanLeaveRequest(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityLeaveRequest, "leave_requests", id, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return decided, nil
+}
+
+// CancelLeaveRequest cancels a pending or approved request and records the
+// restoration of whatever its approval deducted from the balance.
+func (l *LeaveRequestRepository) CancelLeaveRequest(ctx context.Context, tenantID int, id int, cancelledBy int, reason string) (*models.LeaveRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	lockQuery := `
+	SELECT employee_id, leave_type_id, COALESCE(leave_year, EXTRACT(YEAR FROM start_date)::int), COALESCE(deducted_days, 0)::float8
+	FROM leave_requests
+	WHERE tenant_id = $1 AND id = $2 AND status IN ('pending', 'approved')
+	FOR UPDATE
+	`
+
+	var employeeID, leaveTypeID, year int
+	var deducted float64
+	if err := tx.QueryRow(ctx, lockQuery, tenantID, id).Scan(&employeeID, &leaveTypeID, &year, &deducted); err != nil {
+		return nil, err
+	}
+
+	before, err := snapshotRow(ctx, tx, "leave_requests", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if deducted > 0 {
+		_, err := insertLeaveLedgerEntry(ctx, tx, &models.LeaveLedgerEntry{
+			TenantID:       tenantID,
+			EmployeeID:     employeeID,
+			LeaveTypeID:    leaveTypeID,
+			Year:           year,
+			EntryType:      LedgerRestoration,
+			Days:           deducted,
+			LeaveRequestID: &id,
+			Note:           reason,
+			CreatedBy:      &cancelledBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	updateQuery := `
+	UPDATE leave_requests
+	SET status = 'cancelled', cancelled_by = $1, cancelled_at = CURRENT_TIMESTAMP, cancellation_reason = NULLIF($2, ''), deducted_days = 0, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $3
+	RETURNING ` + leaveRequestColumns
+
+	row := tx.QueryRow(ctx, updateQuery, cancelledBy, reason, id)
+	cancelled, err := scanLeaveRequest(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityLeaveRequest, "leave_requests", id, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// SetApprovalWorkflow records the approval workflow deciding a request
+func (l *LeaveRequestRepository) SetApprovalWorkflow(ctx context.Context, tenantID int, id int, workflowID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `approval_workflow_id = $3, updated_at = CURRENT_TIMESTAMP`
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityLeaveRequest, "leave_requests", set, `tenant_id = $1 AND id = $2`, tenantID, id, workflowID)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteLeaveRequest removes a request that never got as far as being
+// decided, such as one whose approval could not be started
+func (l *LeaveRequestRepository) DeleteLeaveRequest(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityLeaveRequest, "leave_requests", `tenant_id = $1 AND id = $2 AND status = 'pending'`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type LeaveTypeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLeaveTypeRepository(pool *pgxpool.Pool) *LeaveTypeRepository {
+	return &LeaveTypeRepository{
+		pool: pool,
+	}
+}
+
+const leaveTypeColumns = `id, tenant_id, name, COALESCE(description, ''), COALESCE(days_per_year, 0), COALESCE(is_paid, TRUE), COALESCE(requires_attachment, FALSE), COALESCE(min_notice_days, 0), max_consecutive_days, COALESCE(eligible_gender, ''), COALESCE(min_tenure_months, 0), COALESCE(allow_half_day, FALSE), COALESCE(status, 'active'), created_at, updated_at`
+
+func scanLeaveType(row pgx.Row) (*models.LeaveType, error) {
+	var leaveType models.LeaveType
+	err := row.Scan(
+		&leaveType.ID,
+		&leaveType.TenantID,
+		&leaveType.Name,
+		&leaveType.Description,
+		&leaveType.DaysPerYear,
+		&leaveType.IsPaid,
+		&leaveType.RequiresAttachment,
+		&leaveType.MinNoticeDays,
+		&leaveType.MaxConsecutiveDays,
+		&leaveType.EligibleGender,
+		&leaveType.MinTenureMonths,
+		&leaveType.AllowHalfDay,
+		&leaveType.Status,
+		&leaveType.CreatedAt,
+		&leaveType.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &leaveType, nil
+}
+
+// ListLeaveTypes returns the tenant's leave types, optionally filtered by
+// status.
+func (l *LeaveTypeRepository) ListLeaveTypes(ctx context.Context, tenantID int, status string) ([]models.LeaveType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + leaveTypeColumns + `
+	FROM leave_types
+	WHERE tenant_id = $1 AND ($2 = '' OR COALESCE(status, 'active') = $2)
+	ORDER BY name, id
+	`
+
+	rows, err := l.pool.Query(ctx, query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaveTypes := []models.LeaveType{}
+	for rows.Next() {
+		leaveType, err := scanLeaveType(rows)
+		if err != nil {
+			return nil, err
+		}
+		leaveTypes = append(leaveTypes, *leaveType)
+	}
+
+	return leaveTypes, rows.Err()
+}
+
+func (l *LeaveTypeRepository) GetLeaveTypeByID(ctx context.Context, tenantID int, id int) (*models.LeaveType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + leaveTypeColumns + `
+	FROM leave_types
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := l.pool.QueryRow(ctx, query, tenantID, id)
+	return scanLeaveType(row)
+}
+
+func (l *LeaveTypeRepository) CreateLeaveType(ctx context.Context, leaveType *models.LeaveType) (*models.LeaveType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO leave_types (tenant_id, name, description, days_per_year, is_paid, requires_attachment, min_notice_days, max_consecutive_days, eligible_gender, min_tenure_months, allow_half_day, status)
+	VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12)
+	RETURNING ` + leaveTypeColumns
+
+	tx, err := beginAudited(ctx, l.pool, leaveType.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query,
+		leaveType.TenantID,
+		leaveType.Name,
+		leaveType.Description,
+		leaveType.DaysPerYear,
+		leaveType.IsPaid,
+		leaveType.RequiresAttachment,
+		leaveType.MinNoticeDays,
+		leaveType.MaxConsecutiveDays,
+		leaveType.EligibleGender,
+		leaveType.MinTenureMonths,
+		leaveType.AllowHalfDay,
+		leaveType.Status,
+	)
+	created, err := scanLeaveType(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityLeaveType, "leave_types", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (l *LeaveTypeRepository) UpdateLeaveType(ctx context.Context, leaveType *models.LeaveType) (*models.LeaveType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE leave_types
+	SET name = $1, description = NULLIF($2, ''), days_per_year = $3, is_paid = $4, requires_attachment = $5, min_notice_days = $6,
+		max_consecutive_days = $7, eligible_gender = NULLIF($8, ''), min_tenure_months = $9, allow_half_day = $10, status = $11, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $12 AND id = $13
+	RETURNING ` + leaveTypeColumns
+
+	tx, err := beginAudited(ctx, l.pool, leaveType.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "leave_types", leaveType.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query,
+		leaveType.Name,
+		leaveType.Description,
+		leaveType.DaysPerYear,
+		leaveType.IsPaid,
+		leaveType.RequiresAttachment,
+		leaveType.MinNoticeDays,
+		leaveType.MaxConsecutiveDays,
+		leaveType.EligibleGender,
+		leaveType.MinTenureMonths,
+		leaveType.AllowHalfDay,
+		leaveType.Status,
+		leaveType.TenantID,
+		leaveType.ID,
+	)
+	updated, err := scanLeaveType(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, updated.TenantID, AuditEntityLeaveType, "leave_types", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// LeaveTypeInUse reports whether any leave request refers to the leave type
+func (l *LeaveTypeRepository) LeaveTypeInUse(ctx context.Context, tenantID int, id int) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT EXISTS (SELECT 1 FROM leave_requests WHERE tenant_id = $1 AND leave_type_id = $2)
+	`
+
+	var inUse bool
+	err := l.pool.QueryRow(ctx, query, tenantID, id).Scan(&inUse)
+	return inUse, err
+}
+
+func (l *LeaveTypeRepository) DeleteLeaveType(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, l.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityLeaveType, "leave_types", `tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,262 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type LetterRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLetterRepository(pool *pgxpool.Pool) *LetterRepository {
+	return &LetterRepository{
+		pool: pool,
+	}
+}
+
+const letterTemplateColumns = `id, tenant_id, name, category, document_type_id,
+	COALESCE((SELECT dt.name FROM document_types dt WHERE dt.id = document_type_id), ''),
+	title, body, COALESCE(status, 'active'), created_by, created_at, updated_at`
+
+func scanLetterTemplate(row pgx.Row) (*models.LetterTemplate, error) {
+	var template models.LetterTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.TenantID,
+		&template.Name,
+		&template.Category,
+		&template.DocumentTypeID,
+		&template.DocumentType,
+		&template.Title,
+		&template.Body,
+		&template.Status,
+		&template.CreatedBy,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ListLetterTemplates returns the tenant's letter templates, optionally
+// filtered by status
+func (l *LetterRepository) ListLetterTemplates(ctx context.Context, tenantID int, status string) ([]models.LetterTemplate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + letterTemplateColumns + `
+	FROM letter_templates
+	WHERE tenant_id = $1 AND ($2 = '' OR COALESCE(status, 'active') = $2)
+	ORDER BY name, id
+	`
+
+	rows, err := l.pool.Query(ctx, query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.LetterTemplate{}
+	for rows.Next() {
+		template, err := scanLetterTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	return templates, rows.Err()
+}
+
+func (l *LetterRepository) GetLetterTemplate(ctx context.Context, tenantID int, id int) (*models.LetterTemplate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + letterTemplateColumns + `
+	FROM letter_templates
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := l.pool.QueryRow(ctx, query, tenantID, id)
+	return scanLetterTemplate(row)
+}
+
+func (l *LetterRepository) CreateLetterTemplate(ctx context.Context, template *models.LetterTemplate) (*models.LetterTemplate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO letter_templates (tenant_id, name, category, document_type_id, title, body, status, created_by)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING ` + letterTemplateColumns
+
+	tx, err := beginAudited(ctx, l.pool, template.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query,
+		template.TenantID,
+		template.Name,
+		template.Category,
+		template.DocumentTypeID,
+		template.Title,
+		template.Body,
+		template.Status,
+		template.CreatedBy,
+	)
+	created, err := scanLetterTemplate(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, template.TenantID, AuditEntityLetterTemplate, "letter_templates", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (l *LetterRepository) UpdateLetterTemplate(ctx context.Context, template *models.LetterTemplate) (*models.LetterTemplate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE letter_templates
+	SET name = $1, category = $2, document_type_id = $3, title = $4, body = $5, status = $6, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $7 AND id = $8
+	RETURNING ` + letterTemplateColumns
+
+	tx, err := beginAudited(ctx, l.pool, template.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "letter_templates", template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query,
+		template.Name,
+		template.Category,
+		template.DocumentTypeID,
+		template.Title,
+		template.Body,
+		template.Status,
+		template.TenantID,
+		template.ID,
+	)
+	updated, err := scanLetterTemplate(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, template.TenantID, AuditEntityLetterTemplate, "letter_templates", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// ListLetterSubjects returns the employees matching filter, or only
+// employeeID when it is set, joined with the names letters refer to. At most
+// limit rows are returned.
+func (l *LetterRepository) ListLetterSubjects(ctx context.Context, tenantID int, filter *dto.EmployeeFilter, employeeID int, limit int) ([]models.EmployeeExportRow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	where, args := buildEmployeeFilter(tenantID, filter)
+	if employeeID != 0 {
+		args = append(args, employeeID)
+		where += fmt.Sprintf(" AND e.id = $%d", len(args))
+	}
+	args = append(args, limit)
+	query := `
+	SELECT e.id, e.tenant_id, e.first_name, e.last_name, e.email, COALESCE(e.phone, ''), e.department_id, e.designation_id, e.manager_id, e.status, e.hire_date,
+		COALESCE(e.employment_type, 'full_time'), e.salary, COALESCE(e.salary_currency, ''), COALESCE(e.custom_fields, '{}'::jsonb), e.created_at, e.updated_at,
+		COALESCE(d.name, ''), COALESCE(g.name, ''), COALESCE(TRIM(m.first_name || ' ' || m.last_name), '')
+	FROM employees e
+	LEFT JOIN departments d ON e.department_id = d.id
+	LEFT JOIN designations g ON e.designation_id = g.id
+	LEFT JOIN employees m ON e.manager_id = m.id
+	WHERE ` + where + `
+	ORDER BY e.id
+	LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err := l.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []models.EmployeeExportRow{}
+	for rows.Next() {
+		var row models.EmployeeExportRow
+		err := rows.Scan(
+			&row.ID,
+			&row.TenantID,
+			&row.FirstName,
+			&row.LastName,
+			&row.Email,
+			&row.Phone,
+			&row.DepartmentID,
+			&row.DesignationID,
+			&row.ManagerID,
+			&row.Status,
+			&row.HireDate,
+			&row.EmploymentType,
+			&row.Salary,
+			&row.SalaryCurrency,
+			&row.CustomFields,
+			&row.CreatedAt,
+			&row.UpdatedAt,
+			&row.DepartmentName,
+			&row.DesignationName,
+			&row.ManagerName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, row)
+	}
+
+	return subjects, rows.Err()
+}
@@ -0,0 +1,592 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type MemoRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMemoRepository(pool *pgxpool.Pool) *MemoRepository {
+	return &MemoRepository{
+		pool: pool,
+	}
+}
+
+const memoTypeColumns = `id, tenant_id, name, COALESCE(description, ''), COALESCE(category, 'general'), COALESCE(is_disciplinary, FALSE), COALESCE(requires_acknowledgement, FALSE), validity_months, COALESCE(status, 'active'), created_at, updated_at`
+
+func scanMemoType(row pgx.Row) (*models.MemoType, error) {
+	var memoType models.MemoType
+	err := row.Scan(
+		&memoType.ID,
+		&memoType.TenantID,
+		&memoType.Name,
+		&memoType.Description,
+		&memoType.Category,
+		&memoType.IsDisciplinary,
+		&memoType.RequiresAcknowledgement,
+		&memoType.ValidityMonths,
+		&memoType.Status,
+		&memoType.CreatedAt,
+		&memoType.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &memoType, nil
+}
+
+// memoColumns selects a memo aliased m together with its memo type aliased mt
+const memoColumns = `m.id, m.tenant_id, m.employee_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = m.employee_id), ''),
+	m.author_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = m.author_id), ''),
+	m.memo_type_id, mt.name, COALESCE(mt.category, 'general'), COALESCE(mt.is_disciplinary, FALSE), COALESCE(mt.requires_acknowledgement, FALSE),
+	m.title, COALESCE(m.description, ''), COALESCE(m.status, 'pending'), m.approval_workflow_id,
+	m.decided_by, m.decided_at, COALESCE(m.decision_comment, ''), m.cancelled_by, m.cancelled_at,
+	m.acknowledged_at, COALESCE(m.acknowledgement_comment, ''), m.expires_at, m.case_id, m.created_at, m.updated_at`
+
+// memoFrom joins a memo to its memo type for memoColumns
+const memoFrom = `m JOIN memo_types mt ON mt.id = m.memo_type_id`
+
+func scanMemo(row pgx.Row) (*models.Memo, error) {
+	var memo models.Memo
+	err := row.Scan(
+		&memo.ID,
+		&memo.TenantID,
+		&memo.EmployeeID,
+		&memo.EmployeeName,
+		&memo.AuthorID,
+		&memo.AuthorName,
+		&memo.MemoTypeID,
+		&memo.MemoTypeName,
+		&memo.Category,
+		&memo.IsDisciplinary,
+		&memo.RequiresAcknowledgement,
+		&memo.Title,
+		&memo.Description,
+		&memo.Status,
+		&memo.ApprovalWorkflowID,
+		&memo.DecidedBy,
+		&memo.DecidedAt,
+		&memo.DecisionComment,
+		&memo.CancelledBy,
+		&memo.CancelledAt,
+		&memo.AcknowledgedAt,
+		&memo.AcknowledgementComment,
+		&memo.ExpiresAt,
+		&memo.CaseID,
+		&memo.CreatedAt,
+		&memo.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &memo, nil
+}
+
+// ListMemoTypes returns the tenant's memo types, optionally filtered by
+// status.
+func (m *MemoRepository) ListMemoTypes(ctx context.Context, tenantID int, status string) ([]models.MemoType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + memoTypeColumns + `
+	FROM memo_types
+	WHERE tenant_id = $1 AND ($2 = '' OR COALESCE(status, 'active') = $2)
+	ORDER BY name, id
+	`
+
+	rows, err := m.pool.Query(ctx, query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memoTypes := []models.MemoType{}
+	for rows.Next() {
+		memoType, err := scanMemoType(rows)
+		if err != nil {
+			return nil, err
+		}
+		memoTypes = append(memoTypes, *memoType)
+	}
+
+	return memoTypes, rows.Err()
+}
+
+func (m *MemoRepository) GetMemoType(ctx context.Context, tenantID int, id int) (*models.MemoType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + memoTypeColumns + `
+	FROM memo_types
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := m.pool.QueryRow(ctx, query, tenantID, id)
+	return scanMemoType(row)
+}
+
+func (m *MemoRepository) CreateMemoType(ctx context.Context, memoType *models.MemoType) (*models.MemoType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO memo_types (tenant_id, name, description, category, is_disciplinary, requires_acknowledgement, validity_months, status)
+	VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8)
+	RETURNING ` + memoTypeColumns
+
+	tx, err := beginAudited(ctx, m.pool, memoType.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query,
+		memoType.TenantID,
+		memoType.Name,
+		memoType.Description,
+		memoType.Category,
+		memoType.IsDisciplinary,
+		memoType.RequiresAcknowledgement,
+		memoType.ValidityMonths,
+		memoType.Status,
+	)
+	created, err := scanMemoType(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityMemoType, "memo_types", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (m *MemoRepository) UpdateMemoType(ctx context.Context, memoType *models.MemoType) (*models.MemoType, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE memo_types
+	SET name = $1, description = NULLIF($2, ''), category = $3, is_disciplinary = $4, requires_acknowledgement = $5, validity_months = $6, status = $7, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $8 AND id = $9
+	RETURNING ` + memoTypeColumns
+
+	tx, err := beginAudited(ctx, m.pool, memoType.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "memo_types", memoType.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query,
+		memoType.Name,
+		memoType.Description,
+		memoType.Category,
+		memoType.IsDisciplinary,
+		memoType.RequiresAcknowledgement,
+		memoType.ValidityMonths,
+		memoType.Status,
+		memoType.TenantID,
+		memoType.ID,
+	)
+	updated, err := scanMemoType(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, memoType.TenantID, AuditEntityMemoType, "memo_types", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// MemoTypeInUse reports whether any memo is of the memo type
+func (m *MemoRepository) MemoTypeInUse(ctx context.Context, tenantID int, id int) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT EXISTS (SELECT 1 FROM memos WHERE tenant_id = $1 AND memo_type_id = $2)
+	`
+
+	var inUse bool
+	err := m.pool.QueryRow(ctx, query, tenantID, id).Scan(&inUse)
+	return inUse, err
+}
+
+func (m *MemoRepository) DeleteMemoType(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, m.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityMemoType, "memo_types", `tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// IsInManagerChain reports whether managerID manages employeeID, directly or
+// through other managers
+func (m *MemoRepository) IsInManagerChain(ctx context.Context, tenantID int, employeeID int, managerID int) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := subtreeQuery + `
+	SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $3 AND id <> $2)
+	`
+
+	var inChain bool
+	err := m.pool.QueryRow(ctx, query, tenantID, managerID, employeeID).Scan(&inChain)
+	return inChain, err
+}
+
+func (m *MemoRepository) CreateMemo(ctx context.Context, memo *models.Memo) (*models.Memo, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, m.pool, memo.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO memos (tenant_id, employee_id, author_id, memo_type_id, title, description, status)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), 'pending')
+	RETURNING id
+	`
+
+	var id int
+	err = tx.QueryRow(ctx, query,
+		memo.TenantID,
+		memo.EmployeeID,
+		memo.AuthorID,
+		memo.MemoTypeID,
+		memo.Title,
+		memo.Description,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, memo.TenantID, AuditEntityMemo, "memos", id, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	created, err := getMemo(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// getMemo returns the memo with the ID as tx sees it
+func getMemo(ctx context.Context, tx pgx.Tx, id int) (*models.Memo, error) {
+	query := `
+	SELECT ` + memoColumns + `
+	FROM memos ` + memoFrom + `
+	WHERE m.id = $1
+	`
+
+	return scanMemo(tx.QueryRow(ctx, query, id))
+}
+
+// updateMemo applies set to the tenant's memo when it also matches cond,
+// both written against args from $3 on, audits the change and returns the
+// memo. It returns pgx.ErrNoRows when no memo matches.
+func updateMemo(ctx context.Context, tx pgx.Tx, tenantID int, id int, set string, cond string, args ...interface{}) (*models.Memo, error) {
+	where := `tenant_id = $1 AND id = $2`
+	if cond != "" {
+		where += ` AND ` + cond
+	}
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityMemo, "memos", set, where, append([]interface{}{tenantID, id}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return getMemo(ctx, tx, id)
+}
+
+func (m *MemoRepository) GetMemo(ctx context.Context, tenantID int, id int) (*models.Memo, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + memoColumns + `
+	FROM memos ` + memoFrom + `
+	WHERE m.tenant_id = $1 AND m.id = $2
+	`
+
+	row := m.pool.QueryRow(ctx, query, tenantID, id)
+	return scanMemo(row)
+}
+
+// ListMemos returns the tenant's memos matching filter. A non-zero viewerID
+// limits the list to memos about the viewer or anyone reporting to them,
+// directly or indirectly, and the non-disciplinary memos the viewer wrote.
+func (m *MemoRepository) ListMemos(ctx context.Context, tenantID int, viewerID int, filter *dto.MemoFilter) ([]models.Memo, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	conditions := "m.tenant_id = $1"
+	args := []interface{}{tenantID, viewerID}
+	if viewerID != 0 {
+		conditions += " AND (m.employee_id IN (SELECT id FROM subtree) OR (m.author_id = $2 AND NOT COALESCE(mt.is_disciplinary, FALSE)))"
+	}
+	if filter.EmployeeID != 0 {
+		args = append(args, filter.EmployeeID)
+		conditions += fmt.Sprintf(" AND m.employee_id = $%d", len(args))
+	}
+	if filter.AuthorID != 0 {
+		args = append(args, filter.AuthorID)
+		conditions += fmt.Sprintf(" AND m.author_id = $%d", len(args))
+	}
+	if filter.MemoTypeID != 0 {
+		args = append(args, filter.MemoTypeID)
+		conditions += fmt.Sprintf(" AND m.memo_type_id = $%d", len(args))
+	}
+	if filter.CaseID != 0 {
+		args = append(args, filter.CaseID)
+		conditions += fmt.Sprintf(" AND m.case_id = $%d", len(args))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions += fmt.Sprintf(" AND COALESCE(mt.category, 'general') = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions += fmt.Sprintf(" AND COALESCE(m.status, 'pending') = $%d", len(args))
+	}
+
+	query := subtreeQuery + `
+	SELECT ` + memoColumns + `
+	FROM memos ` + memoFrom + `
+	WHERE ` + conditions + `
+	ORDER BY m.created_at DESC, m.id DESC
+	`
+
+	rows, err := m.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memos := []models.Memo{}
+	for rows.Next() {
+		memo, err := scanMemo(rows)
+		if err != nil {
+			return nil, err
+		}
+		memos = append(memos, *memo)
+	}
+
+	return memos, rows.Err()
+}
+
+// DecideMemo records the approval or rejection of a pending memo. An approved
+// memo lapses after the validity of its memo type, if it has one. When outer
+// is set the decision is made in a savepoint of it.
+func (m *MemoRepository) DecideMemo(ctx context.Context, outer pgx.Tx, tenantID int, id int, status string, decidedBy int, comment string) (*models.Memo, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginTx(ctx, m.pool, outer, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `status = $3, decided_by = $4, decided_at = CURRENT_TIMESTAMP, decision_comment = NULLIF($5, ''), updated_at = CURRENT_TIMESTAMP,
+		expires_at = CASE WHEN $3 = 'approved' THEN CURRENT_TIMESTAMP + (
+			SELECT make_interval(months => mt.validity_months) FROM memo_types mt WHERE mt.id = memo_type_id
+		) END`
+
+	decided, err := updateMemo(ctx, tx, tenantID, id, set, `status = 'pending'`, status, decidedBy, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return decided, nil
+}
+
+// CancelMemo withdraws a pending memo
+func (m *MemoRepository) CancelMemo(ctx context.Context, tenantID int, id int, cancelledBy int) (*models.Memo, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, m.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `status = 'cancelled', cancelled_by = $3, cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	cancelled, err := updateMemo(ctx, tx, tenantID, id, set, `status = 'pending'`, cancelledBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// AcknowledgeMemo records that the subject of an approved memo has read it.
+// A memo is only acknowledged once.
+func (m *MemoRepository) AcknowledgeMemo(ctx context.Context, tenantID int, id int, comment string) (*models.Memo, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, m.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `acknowledged_at = CURRENT_TIMESTAMP, acknowledgement_comment = NULLIF($3, ''), updated_at = CURRENT_TIMESTAMP`
+	acknowledged, err := updateMemo(ctx, tx, tenantID, id, set, `status = 'approved' AND acknowledged_at IS NULL`, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return acknowledged, nil
+}
+
+// SetApprovalWorkflow records the approval workflow deciding a memo
+func (m *MemoRepository) SetApprovalWorkflow(ctx context.Context, tenantID int, id int, workflowID int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, m.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `approval_workflow_id = $3, updated_at = CURRENT_TIMESTAMP`
+	if _, err := updateMemo(ctx, tx, tenantID, id, set, "", workflowID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteMemo removes a memo that never got as far as being decided, such as
+// one whose approval could not be started
+func (m *MemoRepository) DeleteMemo(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, m.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityMemo, "memos", `tenant_id = $1 AND id = $2 AND status = 'pending'`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,753 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type OffboardingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOffboardingRepository(pool *pgxpool.Pool) *OffboardingRepository {
+	return &OffboardingRepository{
+		pool: pool,
+	}
+}
+
+const checklistItemColumns = `id, tenant_id, title, COALESCE(description, ''), assignee_type, assignee_employee_id, COALESCE(due_offset_days, 0), COALESCE(sort_order, 0), created_at, updated_at`
+
+func scanChecklistItem(row pgx.Row) (*models.OffboardingChecklistItem, error) {
+	var item models.OffboardingChecklistItem
+	err := row.Scan(
+		&item.ID,
+		&item.TenantID,
+		&item.Title,
+		&item.Description,
+		&item.AssigneeType,
+		&item.AssigneeEmployeeID,
+		&item.DueOffsetDays,
+		&item.SortOrder,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+const offboardingColumns = `id, tenant_id, employee_id, last_working_day, COALESCE(reason, ''), successor_id, status, COALESCE(reassigned_reports, 0), COALESCE(reassigned_approvals, 0), leave_settlement, leave_settlement_days, leave_settlement_amount, COALESCE(settlement_currency, ''), initiated_by, completed_at, cancelled_at, created_at, updated_at`
+
+func scanOffboarding(row pgx.Row) (*models.Offboarding, error) {
+	var offboarding models.Offboarding
+	err := row.Scan(
+		&offboarding.ID,
+		&offboarding.TenantID,
+		&offboarding.EmployeeID,
+		&offboarding.LastWorkingDay,
+		&offboarding.Reason,
+		&offboarding.SuccessorID,
+		&offboarding.Status,
+		&offboarding.ReassignedReports,
+		&offboarding.ReassignedApprovals,
+		&offboarding.LeaveSettlement,
+		&offboarding.LeaveSettlementDays,
+		&offboarding.LeaveSettlementAmount,
+		&offboarding.SettlementCurrency,
+		&offboarding.InitiatedBy,
+		&offboarding.CompletedAt,
+		&offboarding.CancelledAt,
+		&offboarding.CreatedAt,
+		&offboarding.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &offboarding, nil
+}
+
+const offboardingTaskColumns = `id, tenant_id, offboarding_id, title, COALESCE(description, ''), assignee_type, assignee_id, due_date, status, COALESCE(notes, ''), completed_by, completed_at, created_at, updated_at`
+
+func scanOffboardingTask(row pgx.Row) (*models.OffboardingTask, error) {
+	var task models.OffboardingTask
+	err := row.Scan(
+		&task.ID,
+		&task.TenantID,
+		&task.OffboardingID,
+		&task.Title,
+		&task.Description,
+		&task.AssigneeType,
+		&task.AssigneeID,
+		&task.DueDate,
+		&task.Status,
+		&task.Notes,
+		&task.CompletedBy,
+		&task.CompletedAt,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (o *OffboardingRepository) ListChecklistItems(ctx context.Context, tenantID int) ([]models.OffboardingChecklistItem, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + checklistItemColumns + `
+	FROM offboarding_checklist_items
+	WHERE tenant_id = $1
+	ORDER BY sort_order, id
+	`
+
+	rows, err := o.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.OffboardingChecklistItem{}
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+func (o *OffboardingRepository) CreateChecklistItem(ctx context.Context, item *models.OffboardingChecklistItem) (*models.OffboardingChecklistItem, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	INSERT INTO offboarding_checklist_items (tenant_id, title, description, assignee_type, assignee_employee_id, due_offset_days, sort_order)
+	VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+	RETURNING ` + checklistItemColumns
+
+	tx, err := beginAudited(ctx, o.pool, item.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query, item.TenantID, item.Title, item.Description, item.AssigneeType, item.AssigneeEmployeeID, item.DueOffsetDays, item.SortOrder)
+	created, err := scanChecklistItem(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, item.TenantID, AuditEntityOffboardingChecklist, "offboarding_checklist_items", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (o *OffboardingRepository) UpdateChecklistItem(ctx context.Context, item *models.OffboardingChecklistItem) (*models.OffboardingChecklistItem, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE offboarding_checklist_items
+	SET title = $1, description = NULLIF($2, ''), assignee_type = $3, assignee_employee_id = $4, due_offset_days = $5, sort_order = $6, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $7 AND id = $8
+	RETURNING ` + checklistItemColumns
+
+	tx, err := beginAudited(ctx, o.pool, item.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "offboarding_checklist_items", item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query, item.Title, item.Description, item.AssigneeType, item.AssigneeEmployeeID, item.DueOffsetDays, item.SortOrder, item.TenantID, item.ID)
+	updated, err := scanChecklistItem(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, item.TenantID, AuditEntityOffboardingChecklist, "offboarding_checklist_items", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeleteChecklistItem removes the item from the tenant's checklist. Tasks
+// already generated from it are kept.
+func (o *OffboardingRepository) DeleteChecklistItem(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, o.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityOffboardingChecklist, "offboarding_checklist_items", `tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateOffboarding saves the offboarding together with its generated tasks
+func (o *OffboardingRepository) CreateOffboarding(ctx context.Context, offboarding *models.Offboarding, tasks []models.OffboardingTask) (*models.Offboarding, []models.OffboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, o.pool, offboarding.TenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	offboardingQuery := `
+	INSERT INTO offboardings (tenant_id, employee_id, last_working_day, reason, successor_id, status, initiated_by)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, 'scheduled', $6)
+	RETURNING ` + offboardingColumns
+
+	row := tx.QueryRow(ctx, offboardingQuery, offboarding.TenantID, offboarding.EmployeeID, offboarding.LastWorkingDay, offboarding.Reason, offboarding.SuccessorID, offboarding.InitiatedBy)
+	created, err := scanOffboarding(row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityOffboarding, "offboardings", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, nil, err
+	}
+
+	taskQuery := `
+	INSERT INTO offboarding_tasks (tenant_id, offboarding_id, title, description, assignee_type, assignee_id, due_date, status)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, 'pending')
+	RETURNING ` + offboardingTaskColumns
+
+	createdTasks := make([]models.OffboardingTask, 0, len(tasks))
+	for _, task := range tasks {
+		row := tx.QueryRow(ctx, taskQuery, created.TenantID, created.ID, task.Title, task.Description, task.AssigneeType, task.AssigneeID, task.DueDate)
+		createdTask, err := scanOffboardingTask(row)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := auditRow(ctx, tx, created.TenantID, AuditEntityOffboardingTask, "offboarding_tasks", createdTask.ID, AuditActionCreate, nil); err != nil {
+			return nil, nil, err
+		}
+		createdTasks = append(createdTasks, *createdTask)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return created, createdTasks, nil
+}
+
+// GetOffboardingByEmployee returns the employee's current offboarding, i.e.
+// the latest one that was not cancelled.
+func (o *OffboardingRepository) GetOffboardingByEmployee(ctx context.Context, tenantID int, employeeID int) (*models.Offboarding, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + offboardingColumns + `
+	FROM offboardings
+	WHERE tenant_id = $1 AND employee_id = $2 AND status <> 'cancelled'
+	ORDER BY id DESC
+	LIMIT 1
+	`
+
+	row := o.pool.QueryRow(ctx, query, tenantID, employeeID)
+	return scanOffboarding(row)
+}
+
+func (o *OffboardingRepository) ListOffboardings(ctx context.Context, tenantID int, status string) ([]models.Offboarding, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + offboardingColumns + `
+	FROM offboardings
+	WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+	ORDER BY last_working_day, id
+	`
+
+	rows, err := o.pool.Query(ctx, query, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	offboardings := []models.Offboarding{}
+	for rows.Next() {
+		offboarding, err := scanOffboarding(rows)
+		if err != nil {
+			return nil, err
+		}
+		offboardings = append(offboardings, *offboarding)
+	}
+
+	return offboardings, rows.Err()
+}
+
+// CancelOffboarding cancels an offboarding that has not yet completed
+func (o *OffboardingRepository) CancelOffboarding(ctx context.Context, tenantID int, employeeID int) (*models.Offboarding, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, o.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP`
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityOffboarding, "offboardings", set, `tenant_id = $1 AND employee_id = $2 AND status = 'scheduled'`, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	query := `
+	SELECT ` + offboardingColumns + `
+	FROM offboardings
+	WHERE id = $1
+	`
+
+	cancelled, err := scanOffboarding(tx.QueryRow(ctx, query, ids[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+func (o *OffboardingRepository) ListOffboardingTasks(ctx context.Context, tenantID int, offboardingID int) ([]models.OffboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + offboardingTaskColumns + `
+	FROM offboarding_tasks
+	WHERE tenant_id = $1 AND offboarding_id = $2
+	ORDER BY due_date NULLS LAST, id
+	`
+
+	rows, err := o.pool.Query(ctx, query, tenantID, offboardingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.OffboardingTask{}
+	for rows.Next() {
+		task, err := scanOffboardingTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (o *OffboardingRepository) GetOffboardingTask(ctx context.Context, tenantID int, id int) (*models.OffboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + offboardingTaskColumns + `
+	FROM offboarding_tasks
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := o.pool.QueryRow(ctx, query, tenantID, id)
+	return scanOffboardingTask(row)
+}
+
+func (o *OffboardingRepository) CompleteOffboardingTask(ctx context.Context, tenantID int, id int, completedBy int, notes string) (*models.OffboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE offboarding_tasks
+	SET status = 'done', notes = NULLIF($1, ''), completed_by = $2, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $3 AND id = $4 AND status = 'pending'
+	RETURNING ` + offboardingTaskColumns
+
+	tx, err := beginAudited(ctx, o.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "offboarding_tasks", id)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query, notes, completedBy, tenantID, id)
+	completed, err := scanOffboardingTask(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityOffboardingTask, "offboarding_tasks", completed.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return completed, nil
+}
+
+// ListDueOffboardings returns scheduled offboardings whose last working day
+// ended before date.
+func (o *OffboardingRepository) ListDueOffboardings(ctx context.Context, date time.Time) ([]models.Offboarding, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + offboardingColumns + `
+	FROM offboardings
+	WHERE status = 'scheduled' AND last_working_day < $1
+	ORDER BY last_working_day, id
+	`
+
+	rows, err := o.pool.Query(ctx, query, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	offboardings := []models.Offboarding{}
+	for rows.Next() {
+		offboarding, err := scanOffboarding(rows)
+		if err != nil {
+			return nil, err
+		}
+		offboardings = append(offboardings, *offboarding)
+	}
+
+	return offboardings, rows.Err()
+}
+
+// LeaveBalanceRow is an employee's balance of one leave type for one year
+type LeaveBalanceRow struct {
+	LeaveTypeID   int
+	LeaveTypeName string
+	DaysPerYear   float64
+	BalanceDays   float64
+}
+
+// ListLeaveBalances returns the employee's balances of paid leave types for
+// the year; unpaid leave is never settled.
+func (o *OffboardingRepository) ListLeaveBalances(ctx context.Context, tenantID int, employeeID int, year int) ([]LeaveBalanceRow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT lt.id, lt.name, COALESCE(lt.days_per_year, 0)::float8, COALESCE(lb.balance_days, 0)::float8
+	FROM leave_balances lb
+	JOIN leave_types lt ON lt.id = lb.leave_type_id
+	WHERE lt.tenant_id = $1 AND lb.employee_id = $2 AND lb.year = $3 AND COALESCE(lt.is_paid, TRUE)
+	ORDER BY lt.name
+	`
+
+	rows, err := o.pool.Query(ctx, query, tenantID, employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []LeaveBalanceRow{}
+	for rows.Next() {
+		var b LeaveBalanceRow
+		if err := rows.Scan(&b.LeaveTypeID, &b.LeaveTypeName, &b.DaysPerYear, &b.BalanceDays); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// CompleteOffboarding finishes a scheduled offboarding in one transaction:
+// the leaver's direct reports move to the successor (recorded as manager
+// changes effective the day after the last working day), their pending
+// approvals move to the successor, their access is revoked, and the leave
+// settlement returned by settle is frozen onto the offboarding. The row is
+// locked with SKIP LOCKED so concurrent schedulers pass over an offboarding
+// already being processed; completed reports whether this call did the work.
+// Every row changed is written to the audit log in the same transaction.
+func (o *OffboardingRepository) CompleteOffboarding(ctx context.Context, tenantID int, id int, settle func(offboarding *models.Offboarding) (*dto.LeaveSettlementResponse, error)) (completed bool, err error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, o.pool, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	lockQuery := `
+	SELECT ` + offboardingColumns + `
+	FROM offboardings
+	WHERE tenant_id = $1 AND id = $2 AND status = 'scheduled'
+	FOR UPDATE SKIP LOCKED
+	`
+
+	offboarding, err := scanOffboarding(tx.QueryRow(ctx, lockQuery, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	settlement, err := settle(offboarding)
+	if err != nil {
+		return false, err
+	}
+
+	var leaverManagerID *int
+	managerQuery := `
+	SELECT manager_id
+	FROM employees
+	WHERE tenant_id = $1 AND id = $2
+	FOR UPDATE
+	`
+
+	if err := tx.QueryRow(ctx, managerQuery, tenantID, offboarding.EmployeeID).Scan(&leaverManagerID); err != nil {
+		return false, err
+	}
+
+	reportsQuery := `
+	SELECT id
+	FROM employees
+	WHERE tenant_id = $1 AND manager_id = $2
+	FOR UPDATE
+	`
+
+	rows, err := tx.Query(ctx, reportsQuery, tenantID, offboarding.EmployeeID)
+	if err != nil {
+		return false, err
+	}
+	reportIDs := []int{}
+	for rows.Next() {
+		var reportID int
+		if err := rows.Scan(&reportID); err != nil {
+			rows.Close()
+			return false, err
+		}
+		reportIDs = append(reportIDs, reportID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	historyQuery := `
+	INSERT INTO employment_history (tenant_id, employee_id, change_type, effective_date, changed_fields, manager_id, reason, status, created_by, applied_at)
+	VALUES ($1, $2, 'manager_change', $3, ARRAY['manager_id'], $4, 'Previous manager left the organisation', 'applied', $5, CURRENT_TIMESTAMP)
+	RETURNING id
+	`
+	reassignQuery := `
+	UPDATE employees
+	SET manager_id = $1, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $2 AND id = $3
+	`
+
+	effectiveDate := offboarding.LastWorkingDay.AddDate(0, 0, 1)
+	for _, reportID := range reportIDs {
+		// A successor who reported to the leaver moves up to the leaver's manager
+		newManagerID := &offboarding.SuccessorID
+		if reportID == offboarding.SuccessorID {
+			newManagerID = leaverManagerID
+		}
+
+		var historyID int
+		if err := tx.QueryRow(ctx, historyQuery, tenantID, reportID, effectiveDate, newManagerID, offboarding.InitiatedBy).Scan(&historyID); err != nil {
+			return false, err
+		}
+		if err := auditRow(ctx, tx, tenantID, AuditEntityEmploymentHistory, "employment_history", historyID, AuditActionCreate, nil); err != nil {
+			return false, err
+		}
+
+		before, err := snapshotRow(ctx, tx, "employees", reportID)
+		if err != nil {
+			return false, err
+		}
+		if _, err := tx.Exec(ctx, reassignQuery, newManagerID, tenantID, reportID); err != nil {
+			return false, err
+		}
+		if err := auditRow(ctx, tx, tenantID, AuditEntityEmployee, "employees", reportID, AuditActionUpdate, before); err != nil {
+			return false, err
+		}
+	}
+
+	pendingApprovalsQuery := `
+	SELECT id
+	FROM approvals
+	WHERE tenant_id = $1 AND approver_id = $2 AND status = 'pending'
+	ORDER BY id
+	FOR UPDATE
+	`
+
+	rows, err = tx.Query(ctx, pendingApprovalsQuery, tenantID, offboarding.EmployeeID)
+	if err != nil {
+		return false, err
+	}
+	approvalIDs := []int{}
+	for rows.Next() {
+		var approvalID int
+		if err := rows.Scan(&approvalID); err != nil {
+			rows.Close()
+			return false, err
+		}
+		approvalIDs = append(approvalIDs, approvalID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	approvalsQuery := `
+	UPDATE approvals
+	SET approver_id = $1, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $2
+	`
+
+	for _, approvalID := range approvalIDs {
+		before, err := snapshotRow(ctx, tx, "approvals", approvalID)
+		if err != nil {
+			return false, err
+		}
+		if _, err := tx.Exec(ctx, approvalsQuery, offboarding.SuccessorID, approvalID); err != nil {
+			return false, err
+		}
+		if err := auditRow(ctx, tx, tenantID, AuditEntityApproval, "approvals", approvalID, AuditActionUpdate, before); err != nil {
+			return false, err
+		}
+	}
+
+	leaverBefore, err := snapshotRow(ctx, tx, "employees", offboarding.EmployeeID)
+	if err != nil {
+		return false, err
+	}
+
+	revokeQuery := `
+	UPDATE employees
+	SET status = 'terminated', access_revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	if _, err := tx.Exec(ctx, revokeQuery, tenantID, offboarding.EmployeeID); err != nil {
+		return false, err
+	}
+	if err := auditRow(ctx, tx, tenantID, AuditEntityEmployee, "employees", offboarding.EmployeeID, AuditActionUpdate, leaverBefore); err != nil {
+		return false, err
+	}
+
+	offboardingBefore, err := snapshotRow(ctx, tx, "offboardings", offboarding.ID)
+	if err != nil {
+		return false, err
+	}
+
+	completeQuery := `
+	UPDATE offboardings
+	SET status = 'completed', reassigned_reports = $1, reassigned_approvals = $2, leave_settlement = $3, leave_settlement_days = $4, leave_settlement_amount = $5, settlement_currency = NULLIF($6, ''), completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $7
+	`
+
+	if _, err := tx.Exec(ctx, completeQuery, len(reportIDs), len(approvalIDs), settlement, settlement.Days, settlement.Amount, settlement.Currency, offboarding.ID); err != nil {
+		return false, err
+	}
+	if err := auditRow(ctx, tx, tenantID, AuditEntityOffboarding, "offboardings", offboarding.ID, AuditActionUpdate, offboardingBefore); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
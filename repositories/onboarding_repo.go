@@ -0,0 +1,619 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type OnboardingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOnboardingRepository(pool *pgxpool.Pool) *OnboardingRepository {
+	return &OnboardingRepository{
+		pool: pool,
+	}
+}
+
+const onboardingTemplateColumns = `id, tenant_id, name, department_id, designation_id, status, created_at, updated_at`
+
+func scanOnboardingTemplate(row pgx.Row) (*models.OnboardingTemplate, error) {
+	var template models.OnboardingTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.TenantID,
+		&template.Name,
+		&template.DepartmentID,
+		&template.DesignationID,
+		&template.Status,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+const onboardingTemplateTaskColumns = `id, template_id, title, COALESCE(description, ''), assignee_type, assignee_employee_id, COALESCE(due_offset_days, 0), COALESCE(sort_order, 0)`
+
+func scanOnboardingTemplateTask(row pgx.Row) (*models.OnboardingTemplateTask, error) {
+	var task models.OnboardingTemplateTask
+	err := row.Scan(
+		&task.ID,
+		&task.TemplateID,
+		&task.Title,
+		&task.Description,
+		&task.AssigneeType,
+		&task.AssigneeEmployeeID,
+		&task.DueOffsetDays,
+		&task.SortOrder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+const onboardingTaskColumns = `id, tenant_id, employee_id, template_id, title, COALESCE(description, ''), assignee_type, assignee_id, due_date, status, COALESCE(notes, ''), completed_by, completed_at, last_reminded_at, created_at, updated_at`
+
+func scanOnboardingTask(row pgx.Row) (*models.OnboardingTask, error) {
+	var task models.OnboardingTask
+	err := row.Scan(
+		&task.ID,
+		&task.TenantID,
+		&task.EmployeeID,
+		&task.TemplateID,
+		&task.Title,
+		&task.Description,
+		&task.AssigneeType,
+		&task.AssigneeID,
+		&task.DueDate,
+		&task.Status,
+		&task.Notes,
+		&task.CompletedBy,
+		&task.CompletedAt,
+		&task.LastRemindedAt,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ListTemplates returns the tenant's templates with their tasks
+func (o *OnboardingRepository) ListTemplates(ctx context.Context, tenantID int) ([]models.OnboardingTemplate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	templateQuery := `
+	SELECT ` + onboardingTemplateColumns + `
+	FROM onboarding_templates
+	WHERE tenant_id = $1
+	ORDER BY name, id
+	`
+
+	rows, err := o.pool.Query(ctx, templateQuery, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []models.OnboardingTemplate{}
+	index := map[int]int{}
+	for rows.Next() {
+		template, err := scanOnboardingTemplate(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		template.Tasks = []models.OnboardingTemplateTask{}
+		index[template.ID] = len(templates)
+		templates = append(templates, *template)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	taskQuery := `
+	SELECT ` + onboardingTemplateTaskColumns + `
+	FROM onboarding_template_tasks
+	WHERE template_id IN (SELECT id FROM onboarding_templates WHERE tenant_id = $1)
+	ORDER BY template_id, sort_order, id
+	`
+
+	taskRows, err := o.pool.Query(ctx, taskQuery, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer taskRows.Close()
+
+	for taskRows.Next() {
+		task, err := scanOnboardingTemplateTask(taskRows)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[task.TemplateID]; ok {
+			templates[i].Tasks = append(templates[i].Tasks, *task)
+		}
+	}
+
+	return templates, taskRows.Err()
+}
+
+func insertOnboardingTemplateTasks(ctx context.Context, tx pgx.Tx, tenantID int, templateID int, tasks []models.OnboardingTemplateTask) ([]models.OnboardingTemplateTask, error) {
+	query := `
+	INSERT INTO onboarding_template_tasks (template_id, title, description, assignee_type, assignee_employee_id, due_offset_days, sort_order)
+	VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+	RETURNING ` + onboardingTemplateTaskColumns
+
+	created := make([]models.OnboardingTemplateTask, 0, len(tasks))
+	for _, task := range tasks {
+		row := tx.QueryRow(ctx, query, templateID, task.Title, task.Description, task.AssigneeType, task.AssigneeEmployeeID, task.DueOffsetDays, task.SortOrder)
+		createdTask, err := scanOnboardingTemplateTask(row)
+		if err != nil {
+			return nil, err
+		}
+		if err := auditRow(ctx, tx, tenantID, AuditEntityOnboardingTemplateTask, "onboarding_template_tasks", createdTask.ID, AuditActionCreate, nil); err != nil {
+			return nil, err
+		}
+		created = append(created, *createdTask)
+	}
+	return created, nil
+}
+
+// CreateTemplate saves the template together with its tasks
+func (o *OnboardingRepository) CreateTemplate(ctx context.Context, template *models.OnboardingTemplate) (*models.OnboardingTemplate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, o.pool, template.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO onboarding_templates (tenant_id, name, department_id, designation_id, status)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING ` + onboardingTemplateColumns
+
+	row := tx.QueryRow(ctx, query, template.TenantID, template.Name, template.DepartmentID, template.DesignationID, template.Status)
+	created, err := scanOnboardingTemplate(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, created.TenantID, AuditEntityOnboardingTemplate, "onboarding_templates", created.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	created.Tasks, err = insertOnboardingTemplateTasks(ctx, tx, created.TenantID, created.ID, template.Tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateTemplate replaces the template and its full task list. Tasks already
+// spawned for new hires are kept.
+func (o *OnboardingRepository) UpdateTemplate(ctx context.Context, template *models.OnboardingTemplate) (*models.OnboardingTemplate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, o.pool, template.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "onboarding_templates", template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updateQuery := `
+	UPDATE onboarding_templates
+	SET name = $1, department_id = $2, designation_id = $3, status = $4, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $5 AND id = $6
+	RETURNING ` + onboardingTemplateColumns
+
+	row := tx.QueryRow(ctx, updateQuery, template.Name, template.DepartmentID, template.DesignationID, template.Status, template.TenantID, template.ID)
+	updated, err := scanOnboardingTemplate(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, updated.TenantID, AuditEntityOnboardingTemplate, "onboarding_templates", updated.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if _, err := deleteAuditedRows(ctx, tx, updated.TenantID, AuditEntityOnboardingTemplateTask, "onboarding_template_tasks", `template_id = $1`, updated.ID); err != nil {
+		return nil, err
+	}
+
+	updated.Tasks, err = insertOnboardingTemplateTasks(ctx, tx, updated.TenantID, updated.ID, template.Tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (o *OnboardingRepository) DeleteTemplate(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, o.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// The tasks would go with the template, but are deleted first to be
+	// audited
+	tasksWhere := `template_id IN (SELECT id FROM onboarding_templates WHERE tenant_id = $1 AND id = $2)`
+	if _, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityOnboardingTemplateTask, "onboarding_template_tasks", tasksWhere, tenantID, id); err != nil {
+		return err
+	}
+
+	deleted, err := deleteAuditedRows(ctx, tx, tenantID, AuditEntityOnboardingTemplate, "onboarding_templates", `tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateOnboardingTasks saves the tasks spawned for one new hire
+func (o *OnboardingRepository) CreateOnboardingTasks(ctx context.Context, tasks []models.OnboardingTask) ([]models.OnboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tenantIDs := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		tenantIDs = append(tenantIDs, task.TenantID)
+	}
+
+	tx, err := beginAudited(ctx, o.pool, tenantIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO onboarding_tasks (tenant_id, employee_id, template_id, title, description, assignee_type, assignee_id, due_date, status)
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, 'pending')
+	RETURNING ` + onboardingTaskColumns
+
+	created := make([]models.OnboardingTask, 0, len(tasks))
+	for _, task := range tasks {
+		row := tx.QueryRow(ctx, query, task.TenantID, task.EmployeeID, task.TemplateID, task.Title, task.Description, task.AssigneeType, task.AssigneeID, task.DueDate)
+		createdTask, err := scanOnboardingTask(row)
+		if err != nil {
+			return nil, err
+		}
+		if err := auditRow(ctx, tx, createdTask.TenantID, AuditEntityOnboardingTask, "onboarding_tasks", createdTask.ID, AuditActionCreate, nil); err != nil {
+			return nil, err
+		}
+		created = append(created, *createdTask)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (o *OnboardingRepository) listTasks(ctx context.Context, query string, args ...interface{}) ([]models.OnboardingTask, error) {
+	rows, err := o.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.OnboardingTask{}
+	for rows.Next() {
+		task, err := scanOnboardingTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (o *OnboardingRepository) ListTasksForEmployee(ctx context.Context, tenantID int, employeeID int) ([]models.OnboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + onboardingTaskColumns + `
+	FROM onboarding_tasks
+	WHERE tenant_id = $1 AND employee_id = $2
+	ORDER BY due_date, id
+	`
+
+	return o.listTasks(ctx, query, tenantID, employeeID)
+}
+
+// ListTasksForAssignee returns the tasks assigned to an employee, optionally
+// filtered by status.
+func (o *OnboardingRepository) ListTasksForAssignee(ctx context.Context, tenantID int, assigneeID int, status string) ([]models.OnboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + onboardingTaskColumns + `
+	FROM onboarding_tasks
+	WHERE tenant_id = $1 AND assignee_id = $2 AND ($3 = '' OR status = $3)
+	ORDER BY due_date, id
+	`
+
+	return o.listTasks(ctx, query, tenantID, assigneeID, status)
+}
+
+func (o *OnboardingRepository) GetTask(ctx context.Context, tenantID int, id int) (*models.OnboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + onboardingTaskColumns + `
+	FROM onboarding_tasks
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	row := o.pool.QueryRow(ctx, query, tenantID, id)
+	return scanOnboardingTask(row)
+}
+
+func (o *OnboardingRepository) CompleteTask(ctx context.Context, tenantID int, id int, completedBy int, notes string) (*models.OnboardingTask, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE onboarding_tasks
+	SET status = 'done', notes = NULLIF($1, ''), completed_by = $2, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $3 AND id = $4 AND status = 'pending'
+	RETURNING ` + onboardingTaskColumns
+
+	tx, err := beginAudited(ctx, o.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "onboarding_tasks", id)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(ctx, query, notes, completedBy, tenantID, id)
+	completed, err := scanOnboardingTask(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityOnboardingTask, "onboarding_tasks", completed.ID, AuditActionUpdate, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return completed, nil
+}
+
+// OverdueReminder is an overdue task claimed for a reminder, with what is
+// needed to write to its assignee.
+type OverdueReminder struct {
+	Task             models.OnboardingTask
+	AssigneeEmail    string
+	NewHireFirstName string
+	NewHireLastName  string
+}
+
+// ClaimOverdueReminders marks up to limit pending tasks that were due before
+// date, and were not reminded since remindedBefore, as reminded and returns
+// them. Rows locked by another instance are skipped, so each reminder is sent
+// once even when several schedulers run.
+func (o *OnboardingRepository) ClaimOverdueReminders(ctx context.Context, date time.Time, remindedBefore time.Time, limit int) ([]OverdueReminder, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	dueFrom := `
+		FROM onboarding_tasks d
+		WHERE d.status = 'pending' AND d.due_date < $1 AND d.assignee_id IS NOT NULL
+		  AND (d.last_reminded_at IS NULL OR d.last_reminded_at < $2)`
+
+	tenantIDs, err := queryTenantIDs(ctx, o.pool, `SELECT DISTINCT d.tenant_id`+dueFrom, date, remindedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := beginAudited(ctx, o.pool, tenantIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	set := `last_reminded_at = CURRENT_TIMESTAMP, reminder_count = COALESCE(reminder_count, 0) + 1`
+	dueWhere := `id IN (
+		SELECT d.id` + dueFrom + ` AND d.tenant_id = ANY($4)
+		ORDER BY d.due_date, d.id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	)`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntityOnboardingTask, "onboarding_tasks", set, dueWhere, date, remindedBefore, limit, tenantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT t.id, t.tenant_id, t.employee_id, t.template_id, t.title, COALESCE(t.description, ''), t.assignee_type, t.assignee_id, t.due_date, t.status, COALESCE(t.notes, ''), t.completed_by, t.completed_at, t.last_reminded_at, t.created_at, t.updated_at,
+		a.email, h.first_name, h.last_name
+	FROM onboarding_tasks t
+	JOIN employees a ON a.id = t.assignee_id
+	JOIN employees h ON h.id = t.employee_id
+	WHERE t.id = ANY($1)
+	ORDER BY t.due_date, t.id
+	`
+
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []OverdueReminder{}
+	for rows.Next() {
+		var reminder OverdueReminder
+		task := &reminder.Task
+		err := rows.Scan(
+			&task.ID,
+			&task.TenantID,
+			&task.EmployeeID,
+			&task.TemplateID,
+			&task.Title,
+			&task.Description,
+			&task.AssigneeType,
+			&task.AssigneeID,
+			&task.DueDate,
+			&task.Status,
+			&task.Notes,
+			&task.CompletedBy,
+			&task.CompletedAt,
+			&task.LastRemindedAt,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+			&reminder.AssigneeEmail,
+			&reminder.NewHireFirstName,
+			&reminder.NewHireLastName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// OnboardingProgressRow aggregates one new hire's onboarding tasks
+type OnboardingProgressRow struct {
+	EmployeeID     int
+	FirstName      string
+	LastName       string
+	StartDate      time.Time
+	TotalTasks     int
+	CompletedTasks int
+	OverdueTasks   int
+	NextDueDate    *time.Time
+}
+
+// ListOnboardingProgress summarises the tasks of every employee that has
+// onboarding tasks, most recent hires first.
+func (o *OnboardingRepository) ListOnboardingProgress(ctx context.Context, tenantID int, date time.Time) ([]OnboardingProgressRow, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT e.id, e.first_name, e.last_name, COALESCE(e.hire_date, MIN(t.created_at)::date),
+		COUNT(*),
+		COUNT(*) FILTER (WHERE t.status = 'done'),
+		COUNT(*) FILTER (WHERE t.status = 'pending' AND t.due_date < $2),
+		MIN(t.due_date) FILTER (WHERE t.status = 'pending')
+	FROM onboarding_tasks t
+	JOIN employees e ON e.id = t.employee_id
+	WHERE t.tenant_id = $1
+	GROUP BY e.id, e.first_name, e.last_name, e.hire_date
+	ORDER BY 4 DESC, e.id
+	`
+
+	rows, err := o.pool.Query(ctx, query, tenantID, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := []OnboardingProgressRow{}
+	for rows.Next() {
+		var row OnboardingProgressRow
+		err := rows.Scan(
+			&row.EmployeeID,
+			&row.FirstName,
+			&row.LastName,
+			&row.StartDate,
+			&row.TotalTasks,
+			&row.CompletedTasks,
+			&row.OverdueTasks,
+			&row.NextDueDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, row)
+	}
+
+	return progress, rows.Err()
+}
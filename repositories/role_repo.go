@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/falasefemi2/peopleos/models"
@@ -26,16 +28,22 @@ func (r *RoleRepository) CreateRole(ctx context.Context, role *models.Role) (*mo
 		defer cancel()
 	}
 
+	tx, err := beginAudited(ctx, r.pool, role.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 	INSERT INTO roles (tenant_id, name, description)
 	VALUES ($1, $2, $3)
 	RETURNING id, tenant_id, name, description, created_at, updated_at
 	`
 
-	row := r.pool.QueryRow(ctx, query, role.TenantID, role.Name, role.Description)
+	row := tx.QueryRow(ctx, query, role.TenantID, role.Name, role.Description)
 
 	var createdRole models.Role
-	err := row.Scan(
+	err = row.Scan(
 		&createdRole.ID,
 		&createdRole.TenantID,
 		&createdRole.Name,
@@ -48,6 +56,13 @@ func (r *RoleRepository) CreateRole(ctx context.Context, role *models.Role) (*mo
 		return nil, err
 	}
 
+	if err := auditRow(ctx, tx, createdRole.TenantID, AuditEntityRole, "roles", createdRole.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return &createdRole, nil
 }
 
@@ -82,3 +97,65 @@ func (r *RoleRepository) GetRoleByName(ctx context.Context, tenantID int, name s
 
 	return &role, nil
 }
+
+// GrantPermission lets holders of the role perform action on resource
+func (r *RoleRepository) GrantPermission(ctx context.Context, tenantID int, roleID int, resource string, action string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, r.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO permissions (role_id, action, resource)
+	SELECT id, $3, $4 FROM roles WHERE tenant_id = $1 AND id = $2
+	ON CONFLICT (role_id, resource, action) DO NOTHING
+	RETURNING id
+	`
+
+	var id int
+	err = tx.QueryRow(ctx, query, tenantID, roleID, action, resource).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Already granted
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityPermission, "permissions", id, AuditActionCreate, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// HasPermission reports whether the employee's role lets them perform action
+// on resource
+func (r *RoleRepository) HasPermission(ctx context.Context, tenantID int, employeeID int, resource string, action string) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT EXISTS (
+		SELECT 1
+		FROM employees e
+		JOIN permissions p ON p.role_id = e.role_id
+		WHERE e.tenant_id = $1 AND e.id = $2 AND p.resource = $3 AND p.action = $4
+	)
+	`
+
+	var allowed bool
+	if err := r.pool.QueryRow(ctx, query, tenantID, employeeID, resource, action).Scan(&allowed); err != nil {
+		return false, err
+	}
+	return allowed, nil
+}
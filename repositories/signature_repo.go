@@ -0,0 +1,680 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+type SignatureRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSignatureRepository(pool *pgxpool.Pool) *SignatureRepository {
+	return &SignatureRepository{
+		pool: pool,
+	}
+}
+
+const signatureRequestColumns = `id, tenant_id, document_id, document_version,
+	COALESCE((SELECT v.file_name FROM document_versions v WHERE v.document_id = signature_requests.document_id AND v.version = document_version), ''),
+	document_checksum, kind, title, COALESCE(message, ''), COALESCE(policy_name, ''), COALESCE(policy_version, ''), status,
+	sealed_version, COALESCE(sealed_checksum, ''), created_by,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = created_by), ''),
+	completed_at, created_at, updated_at`
+
+func scanSignatureRequest(row pgx.Row) (*models.SignatureRequest, error) {
+	var request models.SignatureRequest
+	err := row.Scan(
+		&request.ID,
+		&request.TenantID,
+		&request.DocumentID,
+		&request.DocumentVersion,
+		&request.DocumentName,
+		&request.DocumentChecksum,
+		&request.Kind,
+		&request.Title,
+		&request.Message,
+		&request.PolicyName,
+		&request.PolicyVersion,
+		&request.Status,
+		&request.SealedVersion,
+		&request.SealedChecksum,
+		&request.CreatedBy,
+		&request.CreatedByName,
+		&request.CompletedAt,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.Signers = []models.SignatureSigner{}
+	return &request, nil
+}
+
+const signatureSignerColumns = `id, tenant_id, request_id, employee_id,
+	COALESCE((SELECT e.first_name || ' ' || e.last_name FROM employees e WHERE e.id = employee_id), ''),
+	COALESCE((SELECT e.email FROM employees e WHERE e.id = employee_id), ''),
+	sign_order, status, COALESCE(signature_type, ''), COALESCE(typed_name, ''), COALESCE(signature_image_key, ''),
+	COALESCE(ip_address, ''), COALESCE(user_agent, ''), COALESCE(decline_reason, ''), notified_at, signed_at, declined_at, created_at`
+
+func scanSignatureSigner(row pgx.Row) (*models.SignatureSigner, error) {
+	var signer models.SignatureSigner
+	err := row.Scan(
+		&signer.ID,
+		&signer.TenantID,
+		&signer.RequestID,
+		&signer.EmployeeID,
+		&signer.EmployeeName,
+		&signer.EmployeeEmail,
+		&signer.SignOrder,
+		&signer.Status,
+		&signer.SignatureType,
+		&signer.TypedName,
+		&signer.SignatureImageKey,
+		&signer.IPAddress,
+		&signer.UserAgent,
+		&signer.DeclineReason,
+		&signer.NotifiedAt,
+		&signer.SignedAt,
+		&signer.DeclinedAt,
+		&signer.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &signer, nil
+}
+
+// listSignatureRequests runs a query selecting signatureRequestColumns and
+// loads the signers of every request it returns
+func (s *SignatureRepository) listSignatureRequests(ctx context.Context, tenantID int, query string, args ...interface{}) ([]models.SignatureRequest, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.SignatureRequest{}
+	for rows.Next() {
+		request, err := scanSignatureRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.attachSigners(ctx, tenantID, requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// attachSigners loads the signers of requests, in signing order
+func (s *SignatureRepository) attachSigners(ctx context.Context, tenantID int, requests []models.SignatureRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	ids := make([]int, len(requests))
+	index := make(map[int]int, len(requests))
+	for i := range requests {
+		ids[i] = requests[i].ID
+		index[requests[i].ID] = i
+	}
+
+	query := `
+	SELECT ` + signatureSignerColumns + `
+	FROM signature_request_signers
+	WHERE tenant_id = $1 AND request_id = ANY($2)
+	ORDER BY request_id, sign_order, id
+	`
+
+	rows, err := s.pool.Query(ctx, query, tenantID, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		signer, err := scanSignatureSigner(rows)
+		if err != nil {
+			return err
+		}
+		request := &requests[index[signer.RequestID]]
+		request.Signers = append(request.Signers, *signer)
+	}
+	return rows.Err()
+}
+
+// ListSignatureRequests returns the tenant's signature requests, newest
+// first. Empty filters match every request.
+func (s *SignatureRepository) ListSignatureRequests(ctx context.Context, tenantID int, status string, kind string) ([]models.SignatureRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + signatureRequestColumns + `
+	FROM signature_requests
+	WHERE tenant_id = $1 AND ($2 = '' OR status = $2) AND ($3 = '' OR kind = $3)
+	ORDER BY created_at DESC, id DESC
+	`
+
+	return s.listSignatureRequests(ctx, tenantID, query, tenantID, status, kind)
+}
+
+// ListEmployeeSignatureRequests returns the requests an employee is a signer
+// of, newest first. Empty status matches every request.
+func (s *SignatureRepository) ListEmployeeSignatureRequests(ctx context.Context, tenantID int, employeeID int, status string) ([]models.SignatureRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + signatureRequestColumns + `
+	FROM signature_requests
+	WHERE tenant_id = $1 AND ($3 = '' OR status = $3)
+		AND id IN (SELECT request_id FROM signature_request_signers WHERE tenant_id = $1 AND employee_id = $2)
+	ORDER BY created_at DESC, id DESC
+	`
+
+	return s.listSignatureRequests(ctx, tenantID, query, tenantID, employeeID, status)
+}
+
+// GetSignatureRequest returns a signature request with its signers
+func (s *SignatureRepository) GetSignatureRequest(ctx context.Context, tenantID int, id int) (*models.SignatureRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT ` + signatureRequestColumns + `
+	FROM signature_requests
+	WHERE tenant_id = $1 AND id = $2
+	`
+
+	requests, err := s.listSignatureRequests(ctx, tenantID, query, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return &requests[0], nil
+}
+
+// CreateSignatureRequest stores a signature request with its signers
+func (s *SignatureRepository) CreateSignatureRequest(ctx context.Context, request *models.SignatureRequest) (*models.SignatureRequest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, s.pool, request.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO signature_requests (tenant_id, document_id, document_version, document_checksum, kind, title, message,
+		policy_name, policy_version, created_by)
+	VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10)
+	RETURNING id
+	`
+
+	var id int
+	err = tx.QueryRow(ctx, query,
+		request.TenantID,
+		request.DocumentID,
+		request.DocumentVersion,
+		request.DocumentChecksum,
+		request.Kind,
+		request.Title,
+		request.Message,
+		request.PolicyName,
+		request.PolicyVersion,
+		request.CreatedBy,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	employeeIDs := make([]int, len(request.Signers))
+	orders := make([]int, len(request.Signers))
+	for i, signer := range request.Signers {
+		employeeIDs[i] = signer.EmployeeID
+		orders[i] = signer.SignOrder
+	}
+	rows, err := tx.Query(ctx, `
+	INSERT INTO signature_request_signers (tenant_id, request_id, employee_id, sign_order)
+	SELECT $1, $2, signer.employee_id, signer.sign_order
+	FROM unnest($3::int[], $4::int[]) AS signer(employee_id, sign_order)
+	RETURNING id
+	`, request.TenantID, id, employeeIDs, orders)
+	if err != nil {
+		return nil, err
+	}
+	var signerIDs []int
+	for rows.Next() {
+		var signerID int
+		if err := rows.Scan(&signerID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		signerIDs = append(signerIDs, signerID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := auditRow(ctx, tx, request.TenantID, AuditEntitySignatureRequest, "signature_requests", id, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+	for _, signerID := range signerIDs {
+		if err := auditRow(ctx, tx, request.TenantID, AuditEntitySignatureSigner, "signature_request_signers", signerID, AuditActionCreate, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s.GetSignatureRequest(ctx, request.TenantID, id)
+}
+
+// lockPendingRequest locks a pending signature request for the rest of tx.
+// It returns pgx.ErrNoRows when the request is not pending.
+func lockPendingRequest(ctx context.Context, tx pgx.Tx, tenantID int, id int) error {
+	var status string
+	err := tx.QueryRow(ctx, `
+	SELECT status
+	FROM signature_requests
+	WHERE tenant_id = $1 AND id = $2
+	FOR UPDATE
+	`, tenantID, id).Scan(&status)
+	if err != nil {
+		return err
+	}
+	if status != "pending" {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// SignSignatureRequest records the signature of signer and reports whether
+// it was the last one needed, in which case the request is completed. It
+// returns pgx.ErrNoRows when the request is no longer pending or it is not
+// the signer's turn.
+func (s *SignatureRepository) SignSignatureRequest(ctx context.Context, signer *models.SignatureSigner) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, s.pool, signer.TenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockPendingRequest(ctx, tx, signer.TenantID, signer.RequestID); err != nil {
+		return false, err
+	}
+	before, err := snapshotRow(ctx, tx, "signature_request_signers", signer.ID)
+	if err != nil {
+		return false, err
+	}
+
+	// Every signer with a lower order must have signed first
+	query := `
+	UPDATE signature_request_signers s
+	SET status = 'signed', signature_type = $1, typed_name = NULLIF($2, ''), signature_image_key = NULLIF($3, ''),
+		ip_address = NULLIF($4, ''), user_agent = NULLIF($5, ''), signed_at = $6
+	WHERE s.tenant_id = $7 AND s.request_id = $8 AND s.id = $9 AND s.status = 'pending'
+		AND NOT EXISTS (
+			SELECT 1
+			FROM signature_request_signers o
+			WHERE o.request_id = s.request_id AND o.sign_order < s.sign_order AND o.status <> 'signed'
+		)
+	`
+
+	tag, err := tx.Exec(ctx, query,
+		signer.SignatureType,
+		signer.TypedName,
+		signer.SignatureImageKey,
+		signer.IPAddress,
+		signer.UserAgent,
+		signer.SignedAt,
+		signer.TenantID,
+		signer.RequestID,
+		signer.ID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, pgx.ErrNoRows
+	}
+	if err := auditRow(ctx, tx, signer.TenantID, AuditEntitySignatureSigner, "signature_request_signers", signer.ID, AuditActionUpdate, before); err != nil {
+		return false, err
+	}
+
+	var remaining int
+	err = tx.QueryRow(ctx, `
+	SELECT COUNT(*)
+	FROM signature_request_signers
+	WHERE request_id = $1 AND status <> 'signed'
+	`, signer.RequestID).Scan(&remaining)
+	if err != nil {
+		return false, err
+	}
+
+	completed := remaining == 0
+	if completed {
+		if err := s.setRequestStatus(ctx, tx, signer.TenantID, signer.RequestID, "completed", signer.SignedAt); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return completed, nil
+}
+
+// DeclineSignatureRequest records that signer declined to sign, which ends
+// the request. It returns pgx.ErrNoRows when the request is no longer
+// pending or the signer has already signed.
+func (s *SignatureRepository) DeclineSignatureRequest(ctx context.Context, signer *models.SignatureSigner) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, s.pool, signer.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockPendingRequest(ctx, tx, signer.TenantID, signer.RequestID); err != nil {
+		return err
+	}
+	before, err := snapshotRow(ctx, tx, "signature_request_signers", signer.ID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE signature_request_signers
+	SET status = 'declined', decline_reason = NULLIF($1, ''), ip_address = NULLIF($2, ''), user_agent = NULLIF($3, ''), declined_at = $4
+	WHERE tenant_id = $5 AND request_id = $6 AND id = $7 AND status = 'pending'
+	`
+
+	tag, err := tx.Exec(ctx, query,
+		signer.DeclineReason,
+		signer.IPAddress,
+		signer.UserAgent,
+		signer.DeclinedAt,
+		signer.TenantID,
+		signer.RequestID,
+		signer.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	if err := auditRow(ctx, tx, signer.TenantID, AuditEntitySignatureSigner, "signature_request_signers", signer.ID, AuditActionUpdate, before); err != nil {
+		return err
+	}
+	if err := s.setRequestStatus(ctx, tx, signer.TenantID, signer.RequestID, "declined", nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CancelSignatureRequest withdraws a pending signature request. It returns
+// pgx.ErrNoRows when there is no such pending request.
+func (s *SignatureRepository) CancelSignatureRequest(ctx context.Context, tenantID int, id int) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, s.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockPendingRequest(ctx, tx, tenantID, id); err != nil {
+		return err
+	}
+	if err := s.setRequestStatus(ctx, tx, tenantID, id, "cancelled", nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// setRequestStatus moves a locked signature request to status, recording
+// completedAt for completed requests
+func (s *SignatureRepository) setRequestStatus(ctx context.Context, tx pgx.Tx, tenantID int, id int, status string, completedAt *time.Time) error {
+	before, err := snapshotRow(ctx, tx, "signature_requests", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+	UPDATE signature_requests
+	SET status = $1, completed_at = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $3 AND id = $4
+	`, status, completedAt, tenantID, id)
+	if err != nil {
+		return err
+	}
+	return auditRow(ctx, tx, tenantID, AuditEntitySignatureRequest, "signature_requests", id, AuditActionUpdate, before)
+}
+
+// SealSignatureRequest records the document version holding the sealed PDF
+// of a completed request. It returns pgx.ErrNoRows when the request is not
+// completed or has already been sealed.
+func (s *SignatureRepository) SealSignatureRequest(ctx context.Context, tenantID int, id int, sealedVersion int, sealedChecksum string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, s.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "signature_requests", id)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+	UPDATE signature_requests
+	SET sealed_version = $1, sealed_checksum = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE tenant_id = $3 AND id = $4 AND status = 'completed' AND sealed_version IS NULL
+	`, sealedVersion, sealedChecksum, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	if err := auditRow(ctx, tx, tenantID, AuditEntitySignatureRequest, "signature_requests", id, AuditActionUpdate, before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ClaimSignerNotifications marks the pending signers whose turn it is, and
+// who have not been told yet, as notified and returns them
+func (s *SignatureRepository) ClaimSignerNotifications(ctx context.Context, tenantID int, requestID int) ([]models.SignatureSigner, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	tx, err := beginAudited(ctx, s.pool, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	where := `tenant_id = $1 AND request_id = $2 AND status = 'pending' AND notified_at IS NULL
+		AND sign_order = (
+			SELECT MIN(x.sign_order)
+			FROM signature_request_signers x
+			WHERE x.request_id = $2 AND x.status <> 'signed'
+		)
+		AND EXISTS (SELECT 1 FROM signature_requests r WHERE r.id = $2 AND r.status = 'pending')`
+
+	ids, err := updateAuditedRows(ctx, tx, AuditEntitySignatureSigner, "signature_request_signers", `notified_at = CURRENT_TIMESTAMP`, where, tenantID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT ` + signatureSignerColumns + `
+	FROM signature_request_signers
+	WHERE id = ANY($1)
+	ORDER BY sign_order, id
+	`
+
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	signers := []models.SignatureSigner{}
+	for rows.Next() {
+		signer, err := scanSignatureSigner(rows)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, *signer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+// ListActiveEmployeeIDs returns which of ids belong to employees of the
+// tenant that have not been terminated, or every such employee when ids is
+// nil
+func (s *SignatureRepository) ListActiveEmployeeIDs(ctx context.Context, tenantID int, ids []int) ([]int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT id
+	FROM employees
+	WHERE tenant_id = $1 AND status <> 'terminated' AND ($2::int[] IS NULL OR id = ANY($2))
+	ORDER BY last_name, first_name, id
+	`
+
+	rows, err := s.pool.Query(ctx, query, tenantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	active := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		active = append(active, id)
+	}
+
+	return active, rows.Err()
+}
+
+// PolicySigner is an employee asked to acknowledge a policy version, with
+// the status of their part and of the request it belongs to
+type PolicySigner struct {
+	EmployeeID    int
+	RequestID     int
+	Status        string
+	RequestStatus string
+	RequestedAt   time.Time
+}
+
+// ListPolicySigners returns the signers of every acknowledgement request of a
+// policy version, oldest request first
+func (s *SignatureRepository) ListPolicySigners(ctx context.Context, tenantID int, policyName string, policyVersion string) ([]PolicySigner, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	SELECT s.employee_id, r.id, s.status, r.status, r.created_at
+	FROM signature_request_signers s
+	JOIN signature_requests r ON r.id = s.request_id
+	WHERE r.tenant_id = $1 AND r.kind = 'acknowledgement' AND LOWER(r.policy_name) = LOWER($2) AND r.policy_version = $3
+	ORDER BY r.created_at, r.id
+	`
+
+	rows, err := s.pool.Query(ctx, query, tenantID, policyName, policyVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	signers := []PolicySigner{}
+	for rows.Next() {
+		var signer PolicySigner
+		if err := rows.Scan(&signer.EmployeeID, &signer.RequestID, &signer.Status, &signer.RequestStatus, &signer.RequestedAt); err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+
+	return signers, rows.Err()
+}
@@ -32,10 +32,18 @@ func (t *TenanatRepository) CreateTenant(ctx context.Context, tenant *models.Ten
 	RETURNING id, company_id, super_admin_id, created_at, updated_at
 	`
 
-	row := t.pool.QueryRow(ctx, query, tenant.CompanyID, tenant.SuperAdminID)
+	// No other transaction can hold the chain of a tenant not yet created,
+	// so there is no chain lock to take first
+	tx, err := beginAudited(ctx, t.pool)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx, query, tenant.CompanyID, tenant.SuperAdminID)
 
 	var createdTenant models.Tenant
-	err := row.Scan(
+	err = row.Scan(
 		&createdTenant.ID,
 		&createdTenant.CompanyID,
 		&createdTenant.SuperAdminID,
@@ -47,6 +55,14 @@ func (t *TenanatRepository) CreateTenant(ctx context.Context, tenant *models.Ten
 		return nil, err
 	}
 
+	if err := auditRow(ctx, tx, createdTenant.ID, AuditEntityTenant, "tenants", createdTenant.ID, AuditActionCreate, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	return &createdTenant, nil
 }
 
@@ -63,6 +79,24 @@ func (t *TenanatRepository) UpdateTenantSuperAdmin(ctx context.Context, tenantID
 	WHERE id = $2
 	`
 
-	_, err := t.pool.Exec(ctx, query, superAdminID, tenantID)
-	return err
+	tx, err := beginAudited(ctx, t.pool, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshotRow(ctx, tx, "tenants", tenantID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, query, superAdminID, tenantID); err != nil {
+		return err
+	}
+
+	if err := auditRow(ctx, tx, tenantID, AuditEntityTenant, "tenants", tenantID, AuditActionUpdate, before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
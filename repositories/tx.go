@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// beginTx starts a transaction on pool holding the audit chain locks of
+// tenantIDs, or a savepoint of outer when it is set. Committing a savepoint
+// only releases it, so the changes made in it are saved or undone with outer.
+// outer is expected to hold the chain locks already.
+func beginTx(ctx context.Context, pool *pgxpool.Pool, outer pgx.Tx, tenantIDs ...int) (pgx.Tx, error) {
+	if outer != nil {
+		return outer.Begin(ctx)
+	}
+	return beginAudited(ctx, pool, tenantIDs...)
+}
@@ -0,0 +1,13 @@
+package services
+
+// Actor identifies the authenticated employee performing an operation
+type Actor struct {
+	EmployeeID int
+	TenantID   int
+	Role       string
+}
+
+// IsHR reports whether the actor has tenant-wide people management rights
+func (a Actor) IsHR() bool {
+	return a.Role == "HR" || a.Role == "Super Admin"
+}
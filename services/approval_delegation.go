@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// applyDelegates turns the approvers of a step into approvals, giving the
+// approvals of approvers who delegate to their delegates. An approver keeps
+// their approval when their delegate is the requester, and a delegate who is
+// already an approver of the step does not get a second approval.
+func applyDelegates(approvers []int, delegates map[int]int, requesterID int) []models.Approval {
+	approvals := []models.Approval{}
+	seen := map[int]bool{}
+	for _, approverID := range approvers {
+		approval := models.Approval{ApproverID: approverID}
+		if delegateID, ok := delegates[approverID]; ok && delegateID != requesterID {
+			delegatedFrom := approverID
+			approval.ApproverID = delegateID
+			approval.DelegatedFromID = &delegatedFrom
+		}
+		if seen[approval.ApproverID] {
+			continue
+		}
+		seen[approval.ApproverID] = true
+		approvals = append(approvals, approval)
+	}
+	return approvals
+}
+
+// validateDelegation checks everything about a delegation request that does
+// not need the database. The delegator defaults to the actor, and only HR can
+// delegate for someone else.
+func validateDelegation(actor Actor, req *dto.ApprovalDelegationRequest, now time.Time) (*models.ApprovalDelegation, error) {
+	delegatorID := actor.EmployeeID
+	if req.DelegatorID != nil {
+		delegatorID = *req.DelegatorID
+	}
+	if delegatorID != actor.EmployeeID && !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+	if req.DelegateID <= 0 {
+		return nil, &utils.ValidationError{Field: "delegate_id", Message: "Delegate is required"}
+	}
+	if req.DelegateID == delegatorID {
+		return nil, &utils.ValidationError{Field: "delegate_id", Message: "Approvals cannot be delegated to the delegator"}
+	}
+	if req.EntityType != "" && !containsString(validApprovalEntityTypes, req.EntityType) {
+		return nil, &utils.ValidationError{Field: "entity_type", Message: "Entity type must be one of " + strings.Join(validApprovalEntityTypes, ", ")}
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "start_date", Message: "Start date must be in YYYY-MM-DD format"}
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "end_date", Message: "End date must be in YYYY-MM-DD format"}
+	}
+	if endDate.Before(startDate) {
+		return nil, &utils.ValidationError{Field: "end_date", Message: "End date cannot be before the start date"}
+	}
+	if endDate.Before(today(now)) {
+		return nil, &utils.ValidationError{Field: "end_date", Message: "End date cannot be in the past"}
+	}
+
+	createdBy := actor.EmployeeID
+	return &models.ApprovalDelegation{
+		TenantID:    actor.TenantID,
+		DelegatorID: delegatorID,
+		DelegateID:  req.DelegateID,
+		EntityType:  req.EntityType,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Reason:      strings.TrimSpace(req.Reason),
+		CreatedBy:   &createdBy,
+	}, nil
+}
+
+func approvalDelegationResponses(delegations []models.ApprovalDelegation, now time.Time) []*dto.ApprovalDelegationResponse {
+	responses := make([]*dto.ApprovalDelegationResponse, len(delegations))
+	for i := range delegations {
+		responses[i] = delegations[i].ToResponse(today(now))
+	}
+	return responses
+}
+
+func (ap *ApprovalService) ListDelegations(ctx context.Context, tenantID int) ([]*dto.ApprovalDelegationResponse, error) {
+	delegations, err := ap.approvalRepo.ListDelegations(ctx, tenantID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error listing approval delegations: %w", err)
+	}
+	return approvalDelegationResponses(delegations, time.Now()), nil
+}
+
+// ListMyDelegations returns the delegations the actor gave or received
+func (ap *ApprovalService) ListMyDelegations(ctx context.Context, actor Actor) ([]*dto.ApprovalDelegationResponse, error) {
+	delegations, err := ap.approvalRepo.ListDelegations(ctx, actor.TenantID, actor.EmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing approval delegations: %w", err)
+	}
+	return approvalDelegationResponses(delegations, time.Now()), nil
+}
+
+// CreateDelegation passes the delegator's approvals to the delegate for a
+// date range. New approvals go to the delegate straight away and approvals
+// already pending move to them on the scheduler's next run. A delegator can
+// only delegate once for any day, and not to someone who is delegating their
+// own approvals then, so approvals never go round in a circle.
+func (ap *ApprovalService) CreateDelegation(ctx context.Context, actor Actor, req *dto.ApprovalDelegationRequest) (*dto.ApprovalDelegationResponse, error) {
+	now := time.Now()
+	delegation, err := validateDelegation(actor, req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []string{"delegator_id", "delegate_id"}
+	for i, employeeID := range []int{delegation.DelegatorID, delegation.DelegateID} {
+		active, err := ap.approvalRepo.IsActiveEmployee(ctx, actor.TenantID, employeeID)
+		if err != nil {
+			return nil, fmt.Errorf("error checking employee: %w", err)
+		}
+		if !active {
+			return nil, &utils.ValidationError{Field: fields[i], Message: "Employee not found"}
+		}
+	}
+
+	overlapping, err := ap.approvalRepo.HasOverlappingDelegation(ctx, actor.TenantID, delegation.DelegatorID, delegation.EntityType, delegation.StartDate, delegation.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("error checking approval delegations: %w", err)
+	}
+	if overlapping {
+		return nil, &utils.ValidationError{Field: "start_date", Message: "These approvals are already delegated for part of this period"}
+	}
+	delegateAway, err := ap.approvalRepo.HasOverlappingDelegation(ctx, actor.TenantID, delegation.DelegateID, delegation.EntityType, delegation.StartDate, delegation.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("error checking approval delegations: %w", err)
+	}
+	if delegateAway {
+		return nil, &utils.ValidationError{Field: "delegate_id", Message: "The delegate has delegated their own approvals for part of this period"}
+	}
+
+	created, err := ap.approvalRepo.CreateDelegation(ctx, delegation)
+	if err != nil {
+		return nil, fmt.Errorf("error creating approval delegation: %w", err)
+	}
+	return created.ToResponse(today(now)), nil
+}
+
+// RevokeDelegation ends a delegation early. The delegator, whoever set the
+// delegation up and HR can revoke it.
+func (ap *ApprovalService) RevokeDelegation(ctx context.Context, actor Actor, id int) (*dto.ApprovalDelegationResponse, error) {
+	delegation, err := ap.approvalRepo.GetDelegation(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "approval delegation")
+	}
+	createdByActor := delegation.CreatedBy != nil && *delegation.CreatedBy == actor.EmployeeID
+	if !actor.IsHR() && delegation.DelegatorID != actor.EmployeeID && !createdByActor {
+		return nil, ErrForbidden
+	}
+	if delegation.RevokedAt != nil {
+		return nil, &utils.ValidationError{Field: "id", Message: "Approval delegation is already revoked"}
+	}
+
+	revoked, err := ap.approvalRepo.RevokeDelegation(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "approval delegation")
+	}
+	return revoked.ToResponse(today(time.Now())), nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestApplyDelegates(t *testing.T) {
+	approverIDs := func(approvals []models.Approval) []int {
+		ids := []int{}
+		for _, approval := range approvals {
+			ids = append(ids, approval.ApproverID)
+		}
+		return ids
+	}
+
+	t.Run("gives delegated approvals to the delegate", func(t *testing.T) {
+		approvals := applyDelegates([]int{3, 4}, map[int]int{3: 8}, 5)
+		if got := approverIDs(approvals); !reflect.DeepEqual(got, []int{8, 4}) {
+			t.Fatalf("got approvers %v, want [8 4]", got)
+		}
+		if approvals[0].DelegatedFromID == nil || *approvals[0].DelegatedFromID != 3 || approvals[1].DelegatedFromID != nil {
+			t.Errorf("got delegated from %v and %v, want 3 and nil", approvals[0].DelegatedFromID, approvals[1].DelegatedFromID)
+		}
+	})
+
+	t.Run("keeps the approver when the delegate is the requester", func(t *testing.T) {
+		approvals := applyDelegates([]int{3}, map[int]int{3: 5}, 5)
+		if got := approverIDs(approvals); !reflect.DeepEqual(got, []int{3}) || approvals[0].DelegatedFromID != nil {
+			t.Errorf("got %+v, want approver 3 without delegation", approvals)
+		}
+	})
+
+	t.Run("does not give a delegate who already approves a second approval", func(t *testing.T) {
+		approvals := applyDelegates([]int{3, 4}, map[int]int{3: 4}, 5)
+		if got := approverIDs(approvals); !reflect.DeepEqual(got, []int{4}) {
+			t.Errorf("got approvers %v, want [4]", got)
+		}
+	})
+}
+
+func TestValidateDelegation(t *testing.T) {
+	now := date("2026-03-10")
+	employee := Actor{EmployeeID: 5, TenantID: 1, Role: "Employee"}
+	hr := Actor{EmployeeID: 2, TenantID: 1, Role: "HR"}
+	other := 7
+
+	t.Run("defaults the delegator to the actor", func(t *testing.T) {
+		delegation, err := validateDelegation(employee, &dto.ApprovalDelegationRequest{DelegateID: 8, StartDate: "2026-03-12", EndDate: "2026-03-20", Reason: " Holiday "}, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if delegation.DelegatorID != 5 || delegation.DelegateID != 8 || delegation.Reason != "Holiday" {
+			t.Errorf("got %+v, want 5 delegating to 8 with the trimmed reason", delegation)
+		}
+		if !delegation.EndDate.Equal(date("2026-03-20")) || *delegation.CreatedBy != 5 {
+			t.Errorf("got end %v created by %d, want 2026-03-20 by 5", delegation.EndDate, *delegation.CreatedBy)
+		}
+	})
+
+	t.Run("lets HR delegate for someone else", func(t *testing.T) {
+		delegation, err := validateDelegation(hr, &dto.ApprovalDelegationRequest{DelegatorID: &other, DelegateID: 8, StartDate: "2026-03-10", EndDate: "2026-03-10"}, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if delegation.DelegatorID != 7 || *delegation.CreatedBy != 2 {
+			t.Errorf("got delegator %d created by %d, want 7 by 2", delegation.DelegatorID, *delegation.CreatedBy)
+		}
+	})
+
+	t.Run("stops employees delegating for someone else", func(t *testing.T) {
+		_, err := validateDelegation(employee, &dto.ApprovalDelegationRequest{DelegatorID: &other, DelegateID: 8, StartDate: "2026-03-12", EndDate: "2026-03-20"}, now)
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("got %v, want ErrForbidden", err)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		req   dto.ApprovalDelegationRequest
+		field string
+	}{
+		{"missing delegate", dto.ApprovalDelegationRequest{StartDate: "2026-03-12", EndDate: "2026-03-20"}, "delegate_id"},
+		{"delegating to yourself", dto.ApprovalDelegationRequest{DelegateID: 5, StartDate: "2026-03-12", EndDate: "2026-03-20"}, "delegate_id"},
+		{"unknown entity type", dto.ApprovalDelegationRequest{DelegateID: 8, EntityType: "invoice", StartDate: "2026-03-12", EndDate: "2026-03-20"}, "entity_type"},
+		{"bad start date", dto.ApprovalDelegationRequest{DelegateID: 8, StartDate: "12/03/2026", EndDate: "2026-03-20"}, "start_date"},
+		{"end before start", dto.ApprovalDelegationRequest{DelegateID: 8, StartDate: "2026-03-20", EndDate: "2026-03-12"}, "end_date"},
+		{"ended in the past", dto.ApprovalDelegationRequest{DelegateID: 8, StartDate: "2026-03-01", EndDate: "2026-03-09"}, "end_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateDelegation(employee, &tt.req, now)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestApprovalDelegationStatus(t *testing.T) {
+	revokedAt := time.Now()
+	delegation := models.ApprovalDelegation{StartDate: date("2026-03-12"), EndDate: date("2026-03-20")}
+
+	tests := []struct {
+		on   string
+		want string
+	}{
+		{"2026-03-11", "scheduled"},
+		{"2026-03-12", "active"},
+		{"2026-03-20", "active"},
+		{"2026-03-21", "expired"},
+	}
+	for _, tt := range tests {
+		if got := delegation.Status(date(tt.on)); got != tt.want {
+			t.Errorf("on %s got %s, want %s", tt.on, got, tt.want)
+		}
+	}
+
+	delegation.RevokedAt = &revokedAt
+	if got := delegation.Status(date("2026-03-15")); got != "revoked" {
+		t.Errorf("got %s, want revoked", got)
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// maxBulkApprovalDecisions caps how many approval requests one bulk decision
+// can cover
+const maxBulkApprovalDecisions = 100
+
+var (
+	validAssignedVia         = []string{"direct", "role", "delegation"}
+	validApprovalBulkActions = map[string]string{
+		"approve": ApprovalStatusApproved,
+		"reject":  ApprovalStatusRejected,
+	}
+)
+
+// inboxRange parses the filter's optional date range
+func inboxRange(filter *dto.ApprovalInboxFilter) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if filter.From != "" {
+		parsed, err := time.Parse("2006-01-02", filter.From)
+		if err != nil {
+			return nil, nil, &utils.ValidationError{Field: "from", Message: "From must be in YYYY-MM-DD format"}
+		}
+		from = &parsed
+	}
+	if filter.To != "" {
+		parsed, err := time.Parse("2006-01-02", filter.To)
+		if err != nil {
+			return nil, nil, &utils.ValidationError{Field: "to", Message: "To must be in YYYY-MM-DD format"}
+		}
+		to = &parsed
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		return nil, nil, &utils.ValidationError{Field: "to", Message: "To cannot be before from"}
+	}
+	return from, to, nil
+}
+
+func validateInboxFilter(filter *dto.ApprovalInboxFilter) error {
+	if filter.EntityType != "" && !containsString(validApprovalEntityTypes, filter.EntityType) {
+		return &utils.ValidationError{Field: "entity_type", Message: "Entity type must be one of " + strings.Join(validApprovalEntityTypes, ", ")}
+	}
+	if filter.AssignedVia != "" && !containsString(validAssignedVia, filter.AssignedVia) {
+		return &utils.ValidationError{Field: "assigned_via", Message: "Assigned via must be one of " + strings.Join(validAssignedVia, ", ")}
+	}
+	return nil
+}
+
+// inboxMatches reports whether an inbox item passes the filter. From and To
+// are whole days, compared with the UTC day the approval was assigned.
+func inboxMatches(item *models.ApprovalInboxItem, filter *dto.ApprovalInboxFilter, from *time.Time, to *time.Time, now time.Time) bool {
+	assigned := today(item.AssignedAt)
+	switch {
+	case filter.EntityType != "" && item.EntityType != filter.EntityType,
+		filter.RequesterID != 0 && item.RequesterID != filter.RequesterID,
+		filter.AssignedVia != "" && item.AssignedVia() != filter.AssignedVia,
+		filter.Overdue && !item.Overdue(now),
+		from != nil && assigned.Before(*from),
+		to != nil && assigned.After(*to):
+		return false
+	}
+	return true
+}
+
+func countInbox(items []models.ApprovalInboxItem, now time.Time) dto.ApprovalInboxCounts {
+	counts := dto.ApprovalInboxCounts{ByEntityType: map[string]int{}}
+	for i := range items {
+		counts.Total++
+		counts.ByEntityType[items[i].EntityType]++
+		if items[i].Overdue(now) {
+			counts.Overdue++
+		}
+	}
+	return counts
+}
+
+// ListMyApprovals returns the approvals waiting on the actor across every
+// entity type, whether they were named directly, hold the step's role or
+// were delegated the approval, along with counts of everything pending
+func (ap *ApprovalService) ListMyApprovals(ctx context.Context, actor Actor, filter *dto.ApprovalInboxFilter) (*dto.ApprovalInboxResponse, error) {
+	if err := validateInboxFilter(filter); err != nil {
+		return nil, err
+	}
+	from, to, err := inboxRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := ap.approvalRepo.ListInbox(ctx, actor.TenantID, actor.EmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pending approvals: %w", err)
+	}
+
+	now := time.Now()
+	response := &dto.ApprovalInboxResponse{
+		Items:  []dto.ApprovalInboxItemResponse{},
+		Counts: countInbox(items, now),
+	}
+	for i := range items {
+		if !inboxMatches(&items[i], filter, from, to, now) {
+			continue
+		}
+		details, err := ap.attributes(ctx, actor.TenantID, items[i].EntityType, items[i].EntityID)
+		if err != nil {
+			return nil, err
+		}
+		response.Items = append(response.Items, items[i].ToResponse(now, details))
+	}
+	return response, nil
+}
+
+// bulkDecisionError is the message a bulk decision reports for an item that
+// failed
+func bulkDecisionError(requestID int, err error) string {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return validationErr.Message
+	case errors.Is(err, ErrForbidden), errors.Is(err, ErrNotFound), errors.Is(err, ErrConflict):
+		return err.Error()
+	default:
+		log.Printf("approval request %d: bulk decision failed: %v", requestID, err)
+		return "The decision could not be recorded"
+	}
+}
+
+// BulkDecide approves or rejects each listed approval request on its own, so
+// one that fails, for example because another approver acted on it first,
+// does not hold up the rest
+func (ap *ApprovalService) BulkDecide(ctx context.Context, actor Actor, req *dto.ApprovalBulkDecisionRequest) (*dto.ApprovalBulkDecisionResponse, error) {
+	status, ok := validApprovalBulkActions[req.Action]
+	if !ok {
+		return nil, &utils.ValidationError{Field: "action", Message: "Action must be approve or reject"}
+	}
+	if len(req.Items) == 0 {
+		return nil, &utils.ValidationError{Field: "items", Message: "At least one approval request is required"}
+	}
+	if len(req.Items) > maxBulkApprovalDecisions {
+		return nil, &utils.ValidationError{Field: "items", Message: fmt.Sprintf("At most %d approval requests can be decided at once", maxBulkApprovalDecisions)}
+	}
+	seen := map[int]bool{}
+	for _, item := range req.Items {
+		if item.RequestID <= 0 || item.Version <= 0 {
+			return nil, &utils.ValidationError{Field: "items", Message: "Each item needs a request ID and the version it was read at"}
+		}
+		if seen[item.RequestID] {
+			return nil, &utils.ValidationError{Field: "items", Message: fmt.Sprintf("Approval request %d is listed more than once", item.RequestID)}
+		}
+		seen[item.RequestID] = true
+	}
+
+	response := &dto.ApprovalBulkDecisionResponse{Results: []dto.ApprovalBulkDecisionResult{}}
+	for _, item := range req.Items {
+		result := dto.ApprovalBulkDecisionResult{RequestID: item.RequestID}
+		updated, err := ap.decideRequest(ctx, actor, item.RequestID, item.Version, status, req.Comment)
+		if err != nil {
+			result.Error = bulkDecisionError(item.RequestID, err)
+			response.Failed++
+		} else {
+			result.Success = true
+			result.Status = updated.Status
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestInboxMatches(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	delegator := 3
+	items := []models.ApprovalInboxItem{
+		{ApprovalID: 1, EntityType: ApprovalEntityLeaveRequest, RequesterID: 5, ApproverType: ApproverTypeManager, AssignedAt: date("2026-03-02")},
+		{ApprovalID: 2, EntityType: ApprovalEntityMemo, RequesterID: 6, ApproverType: ApproverTypeRole, EscalateAt: &past, AssignedAt: date("2026-03-05")},
+		{ApprovalID: 3, EntityType: ApprovalEntityLeaveRequest, RequesterID: 6, ApproverType: ApproverTypeRole, DelegatedFromID: &delegator, AssignedAt: date("2026-03-09")},
+	}
+
+	tests := []struct {
+		name   string
+		filter dto.ApprovalInboxFilter
+		want   []int
+	}{
+		{"no filter", dto.ApprovalInboxFilter{}, []int{1, 2, 3}},
+		{"entity type", dto.ApprovalInboxFilter{EntityType: ApprovalEntityLeaveRequest}, []int{1, 3}},
+		{"requester", dto.ApprovalInboxFilter{RequesterID: 6}, []int{2, 3}},
+		{"assigned via role", dto.ApprovalInboxFilter{AssignedVia: "role"}, []int{2}},
+		{"assigned via delegation", dto.ApprovalInboxFilter{AssignedVia: "delegation"}, []int{3}},
+		{"overdue", dto.ApprovalInboxFilter{Overdue: true}, []int{2}},
+		{"date range", dto.ApprovalInboxFilter{From: "2026-03-05", To: "2026-03-08"}, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := inboxRange(&tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := []int{}
+			for i := range items {
+				if inboxMatches(&items[i], &tt.filter, from, to, now) {
+					got = append(got, items[i].ApprovalID)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	counts := countInbox(items, now)
+	if counts.Total != 3 || counts.Overdue != 1 || counts.ByEntityType[ApprovalEntityLeaveRequest] != 2 || counts.ByEntityType[ApprovalEntityMemo] != 1 {
+		t.Errorf("got counts %+v, want 3 in total, 1 overdue, 2 leave requests and 1 memo", counts)
+	}
+}
+
+func TestValidateInboxFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter dto.ApprovalInboxFilter
+		field  string
+	}{
+		{"unknown entity type", dto.ApprovalInboxFilter{EntityType: "invoice"}, "entity_type"},
+		{"unknown assignment", dto.ApprovalInboxFilter{AssignedVia: "magic"}, "assigned_via"},
+		{"bad from", dto.ApprovalInboxFilter{From: "03/01/2026"}, "from"},
+		{"to before from", dto.ApprovalInboxFilter{From: "2026-03-05", To: "2026-03-01"}, "to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInboxFilter(&tt.filter)
+			if err == nil {
+				_, _, err = inboxRange(&tt.filter)
+			}
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestBulkDecideValidation(t *testing.T) {
+	service := &ApprovalService{}
+	actor := Actor{EmployeeID: 7, TenantID: 1}
+
+	tests := []struct {
+		name string
+		req  dto.ApprovalBulkDecisionRequest
+	}{
+		{"unknown action", dto.ApprovalBulkDecisionRequest{Action: "maybe", Items: []dto.ApprovalBulkDecisionItem{{RequestID: 1, Version: 1}}}},
+		{"no items", dto.ApprovalBulkDecisionRequest{Action: "approve"}},
+		{"item without a version", dto.ApprovalBulkDecisionRequest{Action: "approve", Items: []dto.ApprovalBulkDecisionItem{{RequestID: 1}}}},
+		{"duplicate item", dto.ApprovalBulkDecisionRequest{Action: "reject", Items: []dto.ApprovalBulkDecisionItem{{RequestID: 1, Version: 1}, {RequestID: 1, Version: 1}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.BulkDecide(context.Background(), actor, &tt.req)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("got error %v, want a validation error", err)
+			}
+		})
+	}
+}
+
+func TestBulkDecisionError(t *testing.T) {
+	if got := bulkDecisionError(1, ErrConflict); got != ErrConflict.Error() {
+		t.Errorf("got %q, want the conflict message", got)
+	}
+	if got := bulkDecisionError(1, &utils.ValidationError{Field: "status", Message: "Only pending approval requests can be approved"}); got != "Only pending approval requests can be approved" {
+		t.Errorf("got %q, want the validation message", got)
+	}
+	if got := bulkDecisionError(1, errors.New("connection reset")); got != "The decision could not be recorded" {
+		t.Errorf("got %q, want the internal error hidden", got)
+	}
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+// How many approvals settle a step: one, every approver, or a quorum of
+// RequiredApprovals
+const (
+	ApprovalModeAny    = "any"
+	ApprovalModeAll    = "all"
+	ApprovalModeQuorum = "quorum"
+)
+
+var (
+	validApprovalModes        = []string{ApprovalModeAny, ApprovalModeAll, ApprovalModeQuorum}
+	validConditionOperators   = []string{"eq", "ne", "gt", "gte", "lt", "lte", "in", "not_in"}
+	numericConditionOperators = []string{"gt", "gte", "lt", "lte"}
+)
+
+// approvalConditionFields lists the fields step conditions can test for each
+// entity type, with whether each is a number, text or a boolean
+var approvalConditionFields = map[string]map[string]string{
+	ApprovalEntityLeaveRequest: {
+		"days_requested":  "number",
+		"leave_type_id":   "number",
+		"leave_type":      "text",
+		"half_day":        "bool",
+		"department_id":   "number",
+		"designation_id":  "number",
+		"employment_type": "text",
+	},
+	ApprovalEntityMemo: {
+		"memo_type_id":   "number",
+		"memo_type":      "text",
+		"department_id":  "number",
+		"designation_id": "number",
+	},
+}
+
+func validateApprovalMode(mode string, required int) (string, int, error) {
+	if mode == "" {
+		mode = ApprovalModeAny
+	}
+	if !containsString(validApprovalModes, mode) {
+		return "", 0, fmt.Errorf("approval mode must be one of %s", strings.Join(validApprovalModes, ", "))
+	}
+	if mode != ApprovalModeQuorum {
+		return mode, 0, nil
+	}
+	if required < 1 {
+		return "", 0, errors.New("a quorum step needs required approvals of at least 1")
+	}
+	return mode, required, nil
+}
+
+func validateConditions(entityType string, conditions []dto.ApprovalCondition) ([]dto.ApprovalCondition, error) {
+	fields := approvalConditionFields[entityType]
+	valid := []dto.ApprovalCondition{}
+	for _, condition := range conditions {
+		kind, ok := fields[condition.Field]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a field conditions can test", condition.Field)
+		}
+		if !containsString(validConditionOperators, condition.Operator) {
+			return nil, fmt.Errorf("condition operator must be one of %s", strings.Join(validConditionOperators, ", "))
+		}
+
+		switch {
+		case condition.Operator == "in" || condition.Operator == "not_in":
+			values, ok := condition.Value.([]interface{})
+			if !ok || len(values) == 0 {
+				return nil, fmt.Errorf("the %s condition on %s needs a list of values", condition.Operator, condition.Field)
+			}
+		case containsString(numericConditionOperators, condition.Operator):
+			if _, ok := conditionNumber(condition.Value); !ok || kind != "number" {
+				return nil, fmt.Errorf("the %s condition on %s needs a numeric field and value", condition.Operator, condition.Field)
+			}
+		case condition.Value == nil:
+			return nil, fmt.Errorf("the condition on %s needs a value", condition.Field)
+		}
+		valid = append(valid, condition)
+	}
+	return valid, nil
+}
+
+func conditionNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func conditionValuesEqual(a interface{}, b interface{}) bool {
+	x, aNumber := conditionNumber(a)
+	y, bNumber := conditionNumber(b)
+	if aNumber && bNumber {
+		return x == y
+	}
+	return strings.EqualFold(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func conditionValueIn(value interface{}, list interface{}) bool {
+	values, _ := list.([]interface{})
+	for _, candidate := range values {
+		if conditionValuesEqual(value, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionHolds tests a condition against a record's attributes. A
+// condition on a field the record does not have never holds.
+func conditionHolds(condition dto.ApprovalCondition, attributes map[string]interface{}) bool {
+	actual, ok := attributes[condition.Field]
+	if !ok {
+		return false
+	}
+
+	switch condition.Operator {
+	case "eq":
+		return conditionValuesEqual(actual, condition.Value)
+	case "ne":
+		return !conditionValuesEqual(actual, condition.Value)
+	case "in":
+		return conditionValueIn(actual, condition.Value)
+	case "not_in":
+		return !conditionValueIn(actual, condition.Value)
+	}
+
+	x, ok := conditionNumber(actual)
+	if !ok {
+		return false
+	}
+	y, ok := conditionNumber(condition.Value)
+	if !ok {
+		return false
+	}
+	switch condition.Operator {
+	case "gt":
+		return x > y
+	case "gte":
+		return x >= y
+	case "lt":
+		return x < y
+	case "lte":
+		return x <= y
+	}
+	return false
+}
+
+// stepApplies reports whether a record meets all of a step's conditions
+func stepApplies(step models.ApprovalStep, attributes map[string]interface{}) bool {
+	for _, condition := range step.Conditions {
+		if !conditionHolds(condition, attributes) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextStage returns the steps of the first stage after the given step order
+// with at least one step that applies to the record, leaving out the steps
+// that do not apply
+func nextStage(workflow *models.ApprovalWorkflow, after int, attributes map[string]interface{}) []models.ApprovalStep {
+	stage := []models.ApprovalStep{}
+	for _, step := range workflow.Steps {
+		if step.StepOrder <= after || (len(stage) > 0 && step.StepOrder != stage[0].StepOrder) {
+			continue
+		}
+		if stepApplies(step, attributes) {
+			stage = append(stage, step)
+		}
+	}
+	return stage
+}
+
+func stepsByID(workflow *models.ApprovalWorkflow) map[int]models.ApprovalStep {
+	steps := map[int]models.ApprovalStep{}
+	for _, step := range workflow.Steps {
+		steps[step.ID] = step
+	}
+	return steps
+}
+
+// requiredApprovals returns how many of a step's approvers must approve it
+func requiredApprovals(step models.ApprovalStep, approvers int) int {
+	switch step.ApprovalMode {
+	case ApprovalModeAll:
+		return approvers
+	case ApprovalModeQuorum:
+		return step.RequiredApprovals
+	default:
+		return 1
+	}
+}
+
+// stageOutcome works out where a stage stands from its approvals. Each step
+// in the stage is settled by its own approval mode: the first decision settles
+// an any step, and the others are rejected as soon as they can no longer get
+// enough approvals. Approvals whose step is gone count as any steps, and
+// escalated approvals do not count, since the approvals that replaced them
+// do. The stage is approved once every step has enough approvals or was
+// skipped, and rejected as soon as any step is.
+func stageOutcome(approvals []models.Approval, steps map[int]models.ApprovalStep) string {
+	type tally struct {
+		approvers, approved, rejected, pending int
+		skipped                                bool
+	}
+	tallies := map[int]*tally{}
+	order := []int{}
+	for _, approval := range approvals {
+		key := 0
+		if approval.ApprovalStepID != nil {
+			key = *approval.ApprovalStepID
+		}
+		if approval.Status == ApprovalStatusEscalated {
+			continue
+		}
+		t, ok := tallies[key]
+		if !ok {
+			t = &tally{}
+			tallies[key] = t
+			order = append(order, key)
+		}
+		switch approval.Status {
+		case ApprovalStatusSkipped:
+			t.skipped = true
+			continue
+		case ApprovalStatusApproved:
+			t.approved++
+		case ApprovalStatusRejected:
+			t.rejected++
+		case ApprovalStatusPending:
+			t.pending++
+		}
+		t.approvers++
+	}
+
+	outcome := ApprovalStatusApproved
+	for _, key := range order {
+		t := tallies[key]
+		if t.skipped {
+			continue
+		}
+		step := steps[key]
+		required := requiredApprovals(step, t.approvers)
+		anyMode := step.ApprovalMode != ApprovalModeAll && step.ApprovalMode != ApprovalModeQuorum
+		switch {
+		case anyMode && t.rejected > 0, t.approved+t.pending < required:
+			return ApprovalStatusRejected
+		case t.approved < required:
+			outcome = ApprovalStatusPending
+		}
+	}
+	return outcome
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
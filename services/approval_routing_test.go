@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+func TestConditionHolds(t *testing.T) {
+	attributes := map[string]interface{}{
+		"days_requested": 12.5,
+		"department_id":  4,
+		"leave_type":     "Annual Leave",
+	}
+
+	tests := []struct {
+		name      string
+		condition dto.ApprovalCondition
+		want      bool
+	}{
+		{"greater than", dto.ApprovalCondition{Field: "days_requested", Operator: "gt", Value: 10.0}, true},
+		{"not greater than", dto.ApprovalCondition{Field: "days_requested", Operator: "gt", Value: 12.5}, false},
+		{"less than or equal", dto.ApprovalCondition{Field: "days_requested", Operator: "lte", Value: 12.5}, true},
+		{"in a set of numbers", dto.ApprovalCondition{Field: "department_id", Operator: "in", Value: []interface{}{2.0, 4.0}}, true},
+		{"not in a set", dto.ApprovalCondition{Field: "department_id", Operator: "not_in", Value: []interface{}{2.0, 4.0}}, false},
+		{"text ignores case", dto.ApprovalCondition{Field: "leave_type", Operator: "eq", Value: "annual leave"}, true},
+		{"not equal", dto.ApprovalCondition{Field: "leave_type", Operator: "ne", Value: "Sick Leave"}, true},
+		{"missing field", dto.ApprovalCondition{Field: "half_day", Operator: "eq", Value: true}, false},
+		{"numeric test on text", dto.ApprovalCondition{Field: "leave_type", Operator: "gt", Value: 1.0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conditionHolds(tt.condition, attributes); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextStage(t *testing.T) {
+	longLeave := []dto.ApprovalCondition{{Field: "days_requested", Operator: "gt", Value: 10.0}}
+	workflow := &models.ApprovalWorkflow{Steps: []models.ApprovalStep{
+		{ID: 1, StepOrder: 1},
+		{ID: 2, StepOrder: 2, Conditions: longLeave},
+		{ID: 3, StepOrder: 3},
+		{ID: 4, StepOrder: 3, Conditions: longLeave},
+	}}
+	ids := func(stage []models.ApprovalStep) []int {
+		got := []int{}
+		for _, step := range stage {
+			got = append(got, step.ID)
+		}
+		return got
+	}
+
+	short := map[string]interface{}{"days_requested": 3.0}
+	if got := ids(nextStage(workflow, 1, short)); len(got) != 1 || got[0] != 3 {
+		t.Errorf("got steps %v, want the conditional stage skipped and only step 3", got)
+	}
+	long := map[string]interface{}{"days_requested": 15.0}
+	if got := ids(nextStage(workflow, 1, long)); len(got) != 1 || got[0] != 2 {
+		t.Errorf("got steps %v, want step 2", got)
+	}
+	if got := ids(nextStage(workflow, 2, long)); len(got) != 2 {
+		t.Errorf("got steps %v, want both parallel steps of stage 3", got)
+	}
+	if got := nextStage(workflow, 3, long); len(got) != 0 {
+		t.Errorf("got %v, want no stage after the last", got)
+	}
+}
+
+func TestStageOutcome(t *testing.T) {
+	all, quorum := 1, 2
+	steps := map[int]models.ApprovalStep{
+		all:    {ID: all, ApprovalMode: ApprovalModeAll},
+		quorum: {ID: quorum, ApprovalMode: ApprovalModeQuorum, RequiredApprovals: 2},
+	}
+	approval := func(stepID int, status string) models.Approval {
+		id := stepID
+		return models.Approval{ApprovalStepID: &id, Status: status}
+	}
+
+	tests := []struct {
+		name      string
+		approvals []models.Approval
+		want      string
+	}{
+		{"all waits for everyone", []models.Approval{approval(all, ApprovalStatusApproved), approval(all, ApprovalStatusPending)}, ApprovalStatusPending},
+		{"all is approved by everyone", []models.Approval{approval(all, ApprovalStatusApproved), approval(all, ApprovalStatusApproved)}, ApprovalStatusApproved},
+		{"all is rejected by anyone", []models.Approval{approval(all, ApprovalStatusRejected), approval(all, ApprovalStatusPending)}, ApprovalStatusRejected},
+		{"two of three approved", []models.Approval{approval(quorum, ApprovalStatusApproved), approval(quorum, ApprovalStatusRejected), approval(quorum, ApprovalStatusApproved)}, ApprovalStatusApproved},
+		{"two of three can still approve", []models.Approval{approval(quorum, ApprovalStatusRejected), approval(quorum, ApprovalStatusPending), approval(quorum, ApprovalStatusPending)}, ApprovalStatusPending},
+		{"two of three can no longer approve", []models.Approval{approval(quorum, ApprovalStatusRejected), approval(quorum, ApprovalStatusRejected), approval(quorum, ApprovalStatusPending)}, ApprovalStatusRejected},
+		{"a skipped step is passed", []models.Approval{approval(all, ApprovalStatusSkipped)}, ApprovalStatusApproved},
+		{"escalated approvals do not count", []models.Approval{approval(all, ApprovalStatusEscalated), approval(all, ApprovalStatusApproved)}, ApprovalStatusApproved},
+		{"parallel steps all need settling", []models.Approval{approval(all, ApprovalStatusApproved), approval(quorum, ApprovalStatusApproved), approval(quorum, ApprovalStatusPending)}, ApprovalStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stageOutcome(tt.approvals, steps); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+)
+
+// approvalTimerBatch caps how many reminders or escalations one scheduler
+// run handles
+const approvalTimerBatch = 200
+
+func validateApprovalTimers(reminderHours int, slaHours int) error {
+	if reminderHours < 0 || slaHours < 0 {
+		return errors.New("reminder and SLA hours cannot be negative")
+	}
+	if reminderHours > 0 && slaHours > 0 && reminderHours >= slaHours {
+		return errors.New("the reminder must come before the SLA runs out")
+	}
+	return nil
+}
+
+// approvalTimers returns when an approval of the step assigned at now is
+// reminded and escalated, or nil for timers the step does not use
+func approvalTimers(step models.ApprovalStep, now time.Time) (*time.Time, *time.Time) {
+	var remindAt, escalateAt *time.Time
+	if step.ReminderHours > 0 {
+		at := now.Add(time.Duration(step.ReminderHours) * time.Hour)
+		remindAt = &at
+	}
+	if step.SLAHours > 0 {
+		at := now.Add(time.Duration(step.SLAHours) * time.Hour)
+		escalateAt = &at
+	}
+	return remindAt, escalateAt
+}
+
+// stageParticipants returns the requester and everyone who has, or delegated,
+// an approval in the request's current stage. An escalation never goes to
+// any of them.
+func stageParticipants(request *models.ApprovalRequest) map[int]bool {
+	participants := map[int]bool{request.RequesterID: true}
+	for _, approval := range request.Approvals {
+		if approval.StepOrder != request.CurrentStepOrder {
+			continue
+		}
+		participants[approval.ApproverID] = true
+		if approval.DelegatedFromID != nil {
+			participants[*approval.DelegatedFromID] = true
+		}
+	}
+	return participants
+}
+
+// escalationTarget picks who an overdue approval escalates to: the manager of
+// the approver, or of whoever delegated it to them, or else the first of the
+// fallback approvers who is not excluded. It returns false when nobody is
+// left.
+func escalationTarget(excluded map[int]bool, managerID *int, fallback []int) (int, bool) {
+	candidates := []int{}
+	if managerID != nil {
+		candidates = append(candidates, *managerID)
+	}
+	candidates = append(candidates, fallback...)
+	for _, id := range candidates {
+		if !excluded[id] {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func approvalEntityLabel(entityType string) string {
+	return strings.ReplaceAll(entityType, "_", " ")
+}
+
+// ReassignDelegatedApprovals moves pending approvals to the delegates of
+// approvers who delegate today and tells the delegates. It returns how many
+// approvals moved.
+func (ap *ApprovalService) ReassignDelegatedApprovals(ctx context.Context, now time.Time) (int, error) {
+	notices, err := ap.approvalRepo.ReassignDelegatedApprovals(ctx, today(now))
+	if err != nil {
+		return 0, fmt.Errorf("error reassigning delegated approvals: %w", err)
+	}
+
+	for _, notice := range notices {
+		label := approvalEntityLabel(notice.EntityType)
+		subject := "Approval delegated to you: " + label
+		body := fmt.Sprintf(
+			"A %s from %s has been passed to you for approval while its approver is away.\n\nApproval request: %d",
+			label, notice.RequesterName, notice.RequestID,
+		)
+		if err := ap.mailer.Send(ctx, notice.ApproverEmail, subject, body); err != nil {
+			log.Printf("approval %d: error sending delegation notice: %v", notice.ApprovalID, err)
+		}
+	}
+	return len(notices), nil
+}
+
+// SendApprovalReminders reminds approvers of pending approvals whose reminder
+// is due, once per approval, and returns how many reminders it sent. It is
+// safe to run from several instances at once.
+func (ap *ApprovalService) SendApprovalReminders(ctx context.Context, now time.Time) (int, error) {
+	notices, err := ap.approvalRepo.ClaimDueReminders(ctx, now, approvalTimerBatch)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming approval reminders: %w", err)
+	}
+
+	sent := 0
+	for _, notice := range notices {
+		label := approvalEntityLabel(notice.EntityType)
+		subject := "Reminder: " + label + " waiting for your approval"
+		body := fmt.Sprintf("A %s from %s is still waiting for your approval.", label, notice.RequesterName)
+		if notice.EscalateAt != nil {
+			body += fmt.Sprintf(" It will be escalated if it is not decided by %s.", notice.EscalateAt.UTC().Format("2006-01-02 15:04 MST"))
+		}
+		body += fmt.Sprintf("\n\nApproval request: %d", notice.RequestID)
+		if err := ap.mailer.Send(ctx, notice.ApproverEmail, subject, body); err != nil {
+			log.Printf("approval %d: error sending reminder: %v", notice.ApprovalID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// escalate replaces an overdue approval with one for the next level up. It
+// returns false when the approval was decided, moved or escalated by someone
+// else first, or had nowhere to go.
+func (ap *ApprovalService) escalate(ctx context.Context, due repositories.DueEscalation, now time.Time) (bool, error) {
+	request, err := ap.approvalRepo.GetApprovalRequest(ctx, due.TenantID, due.RequestID)
+	if err != nil {
+		return false, fmt.Errorf("error loading approval request: %w", err)
+	}
+	var overdue *models.Approval
+	for i := range request.Approvals {
+		if request.Approvals[i].ID == due.ApprovalID {
+			overdue = &request.Approvals[i]
+		}
+	}
+	if overdue == nil || overdue.Status != ApprovalStatusPending || overdue.StepOrder != request.CurrentStepOrder {
+		return false, nil
+	}
+
+	level := overdue.ApproverID
+	if overdue.DelegatedFromID != nil {
+		level = *overdue.DelegatedFromID
+	}
+	managerID, _, err := ap.approvalRepo.GetReportingLine(ctx, due.TenantID, level)
+	if err != nil {
+		return false, fmt.Errorf("error loading reporting line: %w", err)
+	}
+	fallback, err := ap.approvalRepo.ListRoleHoldersByName(ctx, due.TenantID, approvalFallbackRole)
+	if err != nil {
+		return false, fmt.Errorf("error loading approvers: %w", err)
+	}
+	participants := stageParticipants(request)
+	target, ok := escalationTarget(participants, managerID, fallback)
+	if !ok {
+		log.Printf("approval %d: nobody left to escalate to", overdue.ID)
+		return false, ap.approvalRepo.ClearEscalation(ctx, due.TenantID, overdue.ID)
+	}
+
+	delegates, err := ap.approvalRepo.ListActiveDelegates(ctx, due.TenantID, request.EntityType, []int{target}, today(now))
+	if err != nil {
+		return false, fmt.Errorf("error loading approval delegations: %w", err)
+	}
+	replacement := applyDelegates([]int{target}, delegates, request.RequesterID)[0]
+	if participants[replacement.ApproverID] {
+		replacement = models.Approval{ApproverID: target}
+	}
+
+	step := models.ApprovalStep{}
+	if request.WorkflowID != nil && overdue.ApprovalStepID != nil {
+		workflow, err := ap.approvalRepo.GetWorkflow(ctx, due.TenantID, *request.WorkflowID)
+		if err == nil {
+			step = stepsByID(workflow)[*overdue.ApprovalStepID]
+		}
+	}
+	escalatedFrom := overdue.ID
+	replacement.ApprovalStepID = overdue.ApprovalStepID
+	replacement.StepOrder = overdue.StepOrder
+	replacement.Status = ApprovalStatusPending
+	replacement.EscalatedFromID = &escalatedFrom
+	replacement.RemindAt, replacement.EscalateAt = approvalTimers(step, now)
+
+	err = ap.approvalRepo.EscalateApproval(ctx, due.TenantID, request.ID, request.Version, overdue.ID, replacement)
+	if errors.Is(err, repositories.ErrApprovalConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error escalating approval: %w", err)
+	}
+
+	email, err := ap.approvalRepo.GetEmployeeEmail(ctx, due.TenantID, replacement.ApproverID)
+	if err != nil {
+		log.Printf("approval %d: error loading escalation recipient: %v", overdue.ID, err)
+		return true, nil
+	}
+	label := approvalEntityLabel(request.EntityType)
+	subject := "Escalated: " + label + " waiting for your approval"
+	body := fmt.Sprintf(
+		"A %s from %s was not decided in time by %s and has been escalated to you.\n\nApproval request: %d",
+		label, request.RequesterName, overdue.ApproverName, request.ID,
+	)
+	if err := ap.mailer.Send(ctx, email, subject, body); err != nil {
+		log.Printf("approval %d: error sending escalation notice: %v", overdue.ID, err)
+	}
+	return true, nil
+}
+
+// EscalateOverdueApprovals escalates every pending approval past its SLA and
+// returns how many it escalated. Escalations are checked against the
+// request's version, so an approval decided meanwhile, or escalated by
+// another instance, is left alone.
+func (ap *ApprovalService) EscalateOverdueApprovals(ctx context.Context, now time.Time) (int, error) {
+	due, err := ap.approvalRepo.ListDueEscalations(ctx, now, approvalTimerBatch)
+	if err != nil {
+		return 0, fmt.Errorf("error listing overdue approvals: %w", err)
+	}
+
+	escalated := 0
+	for _, item := range due {
+		ok, err := ap.escalate(ctx, item, now)
+		if err != nil {
+			log.Printf("approval %d: %v", item.ApprovalID, err)
+			continue
+		}
+		if ok {
+			escalated++
+		}
+	}
+	return escalated, nil
+}
+
+// RunScheduler moves delegated approvals, sends reminders and escalates
+// overdue approvals now and then every interval until ctx is cancelled. The
+// timers live on the approvals themselves, so nothing is lost on a restart.
+func (ap *ApprovalService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if moved, err := ap.ReassignDelegatedApprovals(ctx, now); err != nil {
+			log.Printf("approval scheduler: %v", err)
+		} else if moved > 0 {
+			log.Printf("approval scheduler: moved %d approvals to delegates", moved)
+		}
+		if sent, err := ap.SendApprovalReminders(ctx, now); err != nil {
+			log.Printf("approval scheduler: %v", err)
+		} else if sent > 0 {
+			log.Printf("approval scheduler: sent %d approval reminders", sent)
+		}
+		if escalated, err := ap.EscalateOverdueApprovals(ctx, now); err != nil {
+			log.Printf("approval scheduler: %v", err)
+		} else if escalated > 0 {
+			log.Printf("approval scheduler: escalated %d overdue approvals", escalated)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
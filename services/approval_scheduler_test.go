@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+func TestApprovalTimers(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	remindAt, escalateAt := approvalTimers(models.ApprovalStep{ReminderHours: 24, SLAHours: 48}, now)
+	if remindAt == nil || !remindAt.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("got reminder at %v, want a day later", remindAt)
+	}
+	if escalateAt == nil || !escalateAt.Equal(now.Add(48*time.Hour)) {
+		t.Errorf("got escalation at %v, want two days later", escalateAt)
+	}
+
+	remindAt, escalateAt = approvalTimers(models.ApprovalStep{}, now)
+	if remindAt != nil || escalateAt != nil {
+		t.Errorf("got %v and %v, want no timers for a step without them", remindAt, escalateAt)
+	}
+}
+
+func TestEscalationTarget(t *testing.T) {
+	delegator := 6
+	request := &models.ApprovalRequest{
+		RequesterID:      5,
+		CurrentStepOrder: 2,
+		Approvals: []models.Approval{
+			{StepOrder: 1, ApproverID: 3, Status: ApprovalStatusApproved},
+			{StepOrder: 2, ApproverID: 4, Status: ApprovalStatusPending},
+			{StepOrder: 2, ApproverID: 8, DelegatedFromID: &delegator, Status: ApprovalStatusPending},
+		},
+	}
+	participants := stageParticipants(request)
+	manager := func(id int) *int { return &id }
+
+	tests := []struct {
+		name      string
+		managerID *int
+		fallback  []int
+		want      int
+		ok        bool
+	}{
+		{"goes to the approver's manager", manager(10), []int{9}, 10, true},
+		{"goes to an earlier stage's approver", manager(3), []int{9}, 3, true},
+		{"falls back when there is no manager", nil, []int{9}, 9, true},
+		{"skips the requester", manager(5), []int{9}, 9, true},
+		{"skips approvers of the stage and their delegators", manager(6), []int{4, 8, 9}, 9, true},
+		{"gives up when nobody is left", manager(4), []int{5, 6, 8}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := escalationTarget(participants, tt.managerID, tt.fallback)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("got %d %v, want %d %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
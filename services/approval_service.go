@@ -0,0 +1,705 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Approval request and approval statuses. An approval is superseded when its
+// step was settled without it, skipped when the requester was its approver on
+// a step that lets them pass, and escalated when it passed its SLA and was
+// replaced by an approval for the next level up.
+const (
+	ApprovalStatusPending    = "pending"
+	ApprovalStatusApproved   = "approved"
+	ApprovalStatusRejected   = "rejected"
+	ApprovalStatusSuperseded = "superseded"
+	ApprovalStatusSkipped    = "skipped"
+	ApprovalStatusEscalated  = "escalated"
+	ApprovalStatusCancelled  = "cancelled"
+)
+
+// Entity types approval workflows can be set up for
+const (
+	ApprovalEntityLeaveRequest = "leave_request"
+	ApprovalEntityMemo         = "memo"
+)
+
+// How a workflow step finds its approvers
+const (
+	ApproverTypeRole           = "role"
+	ApproverTypeManager        = "manager"
+	ApproverTypeDepartmentHead = "department_head"
+)
+
+// approvalFallbackRole is the role that approves a step when nobody else can
+const approvalFallbackRole = "HR"
+
+var (
+	validApprovalEntityTypes = []string{ApprovalEntityLeaveRequest, ApprovalEntityMemo}
+	validApproverTypes       = []string{ApproverTypeRole, ApproverTypeManager, ApproverTypeDepartmentHead}
+	validWorkflowStatuses    = []string{"active", "inactive"}
+)
+
+// ApprovalCallback is implemented by the modules that own approved records.
+// ApprovalAttributes returns the fields of a record that step conditions test.
+// OnApprovalComplete runs when an approval request is approved or rejected,
+// in the transaction saving the decision: the module makes its changes in tx
+// so they commit with the decision, and returning an error undoes both.
+type ApprovalCallback interface {
+	ApprovalAttributes(ctx context.Context, tenantID int, entityID int) (map[string]interface{}, error)
+	OnApprovalComplete(ctx context.Context, tx pgx.Tx, tenantID int, entityID int, status string, decidedBy int, comment string) error
+}
+
+type IApprovalService interface {
+	ListWorkflows(ctx context.Context, tenantID int) ([]*dto.ApprovalWorkflowResponse, error)
+	GetWorkflow(ctx context.Context, tenantID int, id int) (*dto.ApprovalWorkflowResponse, error)
+	CreateWorkflow(ctx context.Context, tenantID int, req *dto.ApprovalWorkflowRequest) (*dto.ApprovalWorkflowResponse, error)
+	UpdateWorkflow(ctx context.Context, tenantID int, id int, req *dto.ApprovalWorkflowRequest) (*dto.ApprovalWorkflowResponse, error)
+	DeleteWorkflow(ctx context.Context, tenantID int, id int) error
+	GetApprovalRequest(ctx context.Context, actor Actor, id int) (*dto.ApprovalRequestResponse, error)
+	ListEntityApprovalRequests(ctx context.Context, actor Actor, entityType string, entityID int) ([]*dto.ApprovalRequestResponse, error)
+	ApproveRequest(ctx context.Context, actor Actor, id int, req *dto.ApprovalDecisionRequest) (*dto.ApprovalRequestResponse, error)
+	RejectRequest(ctx context.Context, actor Actor, id int, req *dto.ApprovalDecisionRequest) (*dto.ApprovalRequestResponse, error)
+	ListDelegations(ctx context.Context, tenantID int) ([]*dto.ApprovalDelegationResponse, error)
+	ListMyDelegations(ctx context.Context, actor Actor) ([]*dto.ApprovalDelegationResponse, error)
+	CreateDelegation(ctx context.Context, actor Actor, req *dto.ApprovalDelegationRequest) (*dto.ApprovalDelegationResponse, error)
+	RevokeDelegation(ctx context.Context, actor Actor, id int) (*dto.ApprovalDelegationResponse, error)
+	ListMyApprovals(ctx context.Context, actor Actor, filter *dto.ApprovalInboxFilter) (*dto.ApprovalInboxResponse, error)
+	BulkDecide(ctx context.Context, actor Actor, req *dto.ApprovalBulkDecisionRequest) (*dto.ApprovalBulkDecisionResponse, error)
+}
+
+type ApprovalService struct {
+	approvalRepo *repositories.ApprovalRepository
+	mailer       Mailer
+	callbacks    map[string]ApprovalCallback
+}
+
+func NewApprovalService(approvalRepo *repositories.ApprovalRepository, mailer Mailer) *ApprovalService {
+	return &ApprovalService{
+		approvalRepo: approvalRepo,
+		mailer:       mailer,
+		callbacks:    map[string]ApprovalCallback{},
+	}
+}
+
+// RegisterCallback sets the module told about finished approvals of an
+// entity type. It is meant to be called while wiring up the server.
+func (ap *ApprovalService) RegisterCallback(entityType string, callback ApprovalCallback) {
+	ap.callbacks[entityType] = callback
+}
+
+func approvalWorkflowResponses(workflows []models.ApprovalWorkflow) []*dto.ApprovalWorkflowResponse {
+	responses := make([]*dto.ApprovalWorkflowResponse, len(workflows))
+	for i := range workflows {
+		responses[i] = workflows[i].ToResponse()
+	}
+	return responses
+}
+
+func (ap *ApprovalService) ListWorkflows(ctx context.Context, tenantID int) ([]*dto.ApprovalWorkflowResponse, error) {
+	workflows, err := ap.approvalRepo.ListWorkflows(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing approval workflows: %w", err)
+	}
+	return approvalWorkflowResponses(workflows), nil
+}
+
+func (ap *ApprovalService) GetWorkflow(ctx context.Context, tenantID int, id int) (*dto.ApprovalWorkflowResponse, error) {
+	workflow, err := ap.approvalRepo.GetWorkflow(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "approval workflow")
+	}
+	return workflow.ToResponse(), nil
+}
+
+// validateWorkflow checks everything about a workflow request that does not
+// need the database and numbers its steps from 1
+func validateWorkflow(req *dto.ApprovalWorkflowRequest) (*models.ApprovalWorkflow, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name is required"}
+	}
+	if !containsString(validApprovalEntityTypes, req.EntityType) {
+		return nil, &utils.ValidationError{Field: "entity_type", Message: "Entity type must be one of " + strings.Join(validApprovalEntityTypes, ", ")}
+	}
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+	if !containsString(validWorkflowStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validWorkflowStatuses, ", ")}
+	}
+	if len(req.Steps) == 0 {
+		return nil, &utils.ValidationError{Field: "steps", Message: "At least one step is required"}
+	}
+
+	steps := make([]models.ApprovalStep, len(req.Steps))
+	order := 0
+	for i, step := range req.Steps {
+		if !containsString(validApproverTypes, step.ApproverType) {
+			return nil, &utils.ValidationError{Field: "steps", Message: fmt.Sprintf("Step %d: approver type must be one of %s", i+1, strings.Join(validApproverTypes, ", "))}
+		}
+		roleID := step.ApproverRoleID
+		if step.ApproverType == ApproverTypeRole && roleID == nil {
+			return nil, &utils.ValidationError{Field: "steps", Message: fmt.Sprintf("Step %d: a role step needs an approver role", i+1)}
+		}
+		if step.ApproverType != ApproverTypeRole {
+			roleID = nil
+		}
+		if step.Parallel && i == 0 {
+			return nil, &utils.ValidationError{Field: "steps", Message: "The first step cannot run in parallel with an earlier one"}
+		}
+		if !step.Parallel {
+			order++
+		}
+		mode, required, err := validateApprovalMode(step.ApprovalMode, step.RequiredApprovals)
+		if err != nil {
+			return nil, &utils.ValidationError{Field: "steps", Message: fmt.Sprintf("Step %d: %s", i+1, err.Error())}
+		}
+		conditions, err := validateConditions(req.EntityType, step.Conditions)
+		if err != nil {
+			return nil, &utils.ValidationError{Field: "steps", Message: fmt.Sprintf("Step %d: %s", i+1, err.Error())}
+		}
+		if err := validateApprovalTimers(step.ReminderHours, step.SLAHours); err != nil {
+			return nil, &utils.ValidationError{Field: "steps", Message: fmt.Sprintf("Step %d: %s", i+1, err.Error())}
+		}
+		steps[i] = models.ApprovalStep{
+			StepOrder:         order,
+			ApproverType:      step.ApproverType,
+			ApproverRoleID:    roleID,
+			Description:       strings.TrimSpace(step.Description),
+			ApprovalMode:      mode,
+			RequiredApprovals: required,
+			SkipIfRequester:   step.SkipIfRequester,
+			Conditions:        conditions,
+			ReminderHours:     step.ReminderHours,
+			SLAHours:          step.SLAHours,
+		}
+	}
+
+	return &models.ApprovalWorkflow{
+		Name:       name,
+		EntityType: req.EntityType,
+		Status:     status,
+		Steps:      steps,
+	}, nil
+}
+
+func (ap *ApprovalService) buildWorkflow(ctx context.Context, tenantID int, req *dto.ApprovalWorkflowRequest) (*models.ApprovalWorkflow, error) {
+	workflow, err := validateWorkflow(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range workflow.Steps {
+		if step.ApproverRoleID == nil {
+			continue
+		}
+		exists, err := ap.approvalRepo.RoleExists(ctx, tenantID, *step.ApproverRoleID)
+		if err != nil {
+			return nil, fmt.Errorf("error checking role: %w", err)
+		}
+		if !exists {
+			return nil, &utils.ValidationError{Field: "steps", Message: fmt.Sprintf("Step %d: role not found", step.StepOrder)}
+		}
+	}
+	workflow.TenantID = tenantID
+	return workflow, nil
+}
+
+func (ap *ApprovalService) CreateWorkflow(ctx context.Context, tenantID int, req *dto.ApprovalWorkflowRequest) (*dto.ApprovalWorkflowResponse, error) {
+	workflow, err := ap.buildWorkflow(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := ap.approvalRepo.CreateWorkflow(ctx, workflow)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "entity_type", Message: "An approval workflow already exists for this entity type"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating approval workflow: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+// checkNoPendingRequests stops a workflow from changing under the approvals
+// running on it
+func (ap *ApprovalService) checkNoPendingRequests(ctx context.Context, workflowID int) error {
+	pending, err := ap.approvalRepo.CountPendingRequests(ctx, workflowID)
+	if err != nil {
+		return fmt.Errorf("error counting pending approvals: %w", err)
+	}
+	if pending > 0 {
+		return &utils.ValidationError{Field: "id", Message: fmt.Sprintf("%d approvals are still running on this workflow", pending)}
+	}
+	return nil
+}
+
+// UpdateWorkflow replaces a workflow's name, status and steps. Its entity type
+// cannot change.
+func (ap *ApprovalService) UpdateWorkflow(ctx context.Context, tenantID int, id int, req *dto.ApprovalWorkflowRequest) (*dto.ApprovalWorkflowResponse, error) {
+	existing, err := ap.approvalRepo.GetWorkflow(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "approval workflow")
+	}
+	if req.EntityType == "" {
+		req.EntityType = existing.EntityType
+	}
+	if req.EntityType != existing.EntityType {
+		return nil, &utils.ValidationError{Field: "entity_type", Message: "The entity type of a workflow cannot be changed"}
+	}
+
+	workflow, err := ap.buildWorkflow(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := ap.checkNoPendingRequests(ctx, id); err != nil {
+		return nil, err
+	}
+
+	workflow.ID = id
+	updated, err := ap.approvalRepo.UpdateWorkflow(ctx, workflow)
+	if err != nil {
+		return nil, notFoundOr(err, "approval workflow")
+	}
+	return updated.ToResponse(), nil
+}
+
+func (ap *ApprovalService) DeleteWorkflow(ctx context.Context, tenantID int, id int) error {
+	if _, err := ap.approvalRepo.GetWorkflow(ctx, tenantID, id); err != nil {
+		return notFoundOr(err, "approval workflow")
+	}
+	if err := ap.checkNoPendingRequests(ctx, id); err != nil {
+		return err
+	}
+	if err := ap.approvalRepo.DeleteWorkflow(ctx, tenantID, id); err != nil {
+		return notFoundOr(err, "approval workflow")
+	}
+	return nil
+}
+
+// pickApprovers drops the requester, who never approves their own record,
+// from the candidates and falls back to the fallback approvers when nobody is
+// left
+func pickApprovers(candidates []int, requesterID int, fallback []int) []int {
+	approvers := []int{}
+	seen := map[int]bool{requesterID: true}
+	for _, id := range candidates {
+		if !seen[id] {
+			seen[id] = true
+			approvers = append(approvers, id)
+		}
+	}
+	if len(approvers) > 0 {
+		return approvers
+	}
+	for _, id := range fallback {
+		if !seen[id] {
+			seen[id] = true
+			approvers = append(approvers, id)
+		}
+	}
+	return approvers
+}
+
+// findCandidates returns who the step names as approvers, before the
+// requester is taken out
+func (ap *ApprovalService) findCandidates(ctx context.Context, tenantID int, step models.ApprovalStep, requesterID int) ([]int, error) {
+	switch step.ApproverType {
+	case ApproverTypeManager, ApproverTypeDepartmentHead:
+		managerID, hodID, err := ap.approvalRepo.GetReportingLine(ctx, tenantID, requesterID)
+		if err != nil {
+			return nil, notFoundOr(err, "employee")
+		}
+		if step.ApproverType == ApproverTypeManager && managerID != nil {
+			return []int{*managerID}, nil
+		}
+		if step.ApproverType == ApproverTypeDepartmentHead && hodID != nil {
+			return []int{*hodID}, nil
+		}
+		return []int{}, nil
+	default:
+		if step.ApproverRoleID == nil {
+			return []int{}, nil
+		}
+		holders, err := ap.approvalRepo.ListRoleHolders(ctx, tenantID, *step.ApproverRoleID)
+		if err != nil {
+			return nil, fmt.Errorf("error loading approvers: %w", err)
+		}
+		return holders, nil
+	}
+}
+
+// stepApprovals creates the approvals of a step. A step that lets the
+// requester pass gets a single skipped approval when they are among its
+// approvers. Approvers who have delegated their approvals are replaced by
+// their delegates, and each approval gets the step's reminder and SLA timers.
+func (ap *ApprovalService) stepApprovals(ctx context.Context, tenantID int, entityType string, step models.ApprovalStep, requesterID int) ([]models.Approval, error) {
+	candidates, err := ap.findCandidates(ctx, tenantID, step, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	stepID := step.ID
+	if step.SkipIfRequester && containsInt(candidates, requesterID) {
+		return []models.Approval{{
+			ApprovalStepID: &stepID,
+			StepOrder:      step.StepOrder,
+			ApproverID:     requesterID,
+			Status:         ApprovalStatusSkipped,
+			Comments:       "Skipped because the requester is an approver of this step",
+		}}, nil
+	}
+
+	fallback, err := ap.approvalRepo.ListRoleHoldersByName(ctx, tenantID, approvalFallbackRole)
+	if err != nil {
+		return nil, fmt.Errorf("error loading approvers: %w", err)
+	}
+	approvers := pickApprovers(candidates, requesterID, fallback)
+	if len(approvers) == 0 {
+		return nil, &utils.ValidationError{Field: "approval", Message: fmt.Sprintf("Nobody can approve step %d of the approval workflow", step.StepOrder)}
+	}
+
+	now := time.Now()
+	delegates, err := ap.approvalRepo.ListActiveDelegates(ctx, tenantID, entityType, approvers, today(now))
+	if err != nil {
+		return nil, fmt.Errorf("error loading approval delegations: %w", err)
+	}
+	approvals := applyDelegates(approvers, delegates, requesterID)
+	if step.ApprovalMode == ApprovalModeQuorum && len(approvals) < step.RequiredApprovals {
+		return nil, &utils.ValidationError{Field: "approval", Message: fmt.Sprintf("Step %d needs %d approvers but only %d can approve it", step.StepOrder, step.RequiredApprovals, len(approvals))}
+	}
+
+	remindAt, escalateAt := approvalTimers(step, now)
+	for i := range approvals {
+		approvals[i].ApprovalStepID = &stepID
+		approvals[i].StepOrder = step.StepOrder
+		approvals[i].Status = ApprovalStatusPending
+		approvals[i].RemindAt = remindAt
+		approvals[i].EscalateAt = escalateAt
+	}
+	return approvals, nil
+}
+
+// advance finds the next stage after the given step order that applies to
+// the record and creates its approvals. Stages the requester is allowed to
+// pass are recorded as skipped and advance goes on past them. It returns the
+// stage's step order, or 0 when no stage is left.
+func (ap *ApprovalService) advance(ctx context.Context, tenantID int, workflow *models.ApprovalWorkflow, after int, requesterID int, attributes map[string]interface{}) ([]models.Approval, int, error) {
+	approvals := []models.Approval{}
+	for {
+		stage := nextStage(workflow, after, attributes)
+		if len(stage) == 0 {
+			return approvals, 0, nil
+		}
+		after = stage[0].StepOrder
+
+		stageApprovals := []models.Approval{}
+		for _, step := range stage {
+			created, err := ap.stepApprovals(ctx, tenantID, workflow.EntityType, step, requesterID)
+			if err != nil {
+				return nil, 0, err
+			}
+			stageApprovals = append(stageApprovals, created...)
+		}
+		approvals = append(approvals, stageApprovals...)
+
+		if stageOutcome(stageApprovals, stepsByID(workflow)) == ApprovalStatusPending {
+			return approvals, after, nil
+		}
+	}
+}
+
+// attributes asks the owning module for the fields of a record that step
+// conditions test
+func (ap *ApprovalService) attributes(ctx context.Context, tenantID int, entityType string, entityID int) (map[string]interface{}, error) {
+	callback, ok := ap.callbacks[entityType]
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	attributes, err := callback.ApprovalAttributes(ctx, tenantID, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading approval attributes: %w", err)
+	}
+	return attributes, nil
+}
+
+// completion returns the hook that tells the owning module how an approval
+// request ended, or nil when no module listens for the entity type
+func (ap *ApprovalService) completion(request *models.ApprovalRequest, status *string, decidedBy int, comment string) func(ctx context.Context, tx pgx.Tx) error {
+	callback, ok := ap.callbacks[request.EntityType]
+	if !ok {
+		return nil
+	}
+	return func(ctx context.Context, tx pgx.Tx) error {
+		return callback.OnApprovalComplete(ctx, tx, request.TenantID, request.EntityID, *status, decidedBy, comment)
+	}
+}
+
+// StartApproval runs the tenant's active workflow for the entity type on a
+// record. It returns nil when the tenant has no workflow for the entity type,
+// in which case the owning module decides the record itself. When none of the
+// workflow's steps apply to the record it is approved straight away.
+func (ap *ApprovalService) StartApproval(ctx context.Context, tenantID int, entityType string, entityID int, requesterID int) (*models.ApprovalRequest, error) {
+	workflow, err := ap.approvalRepo.GetActiveWorkflow(ctx, tenantID, entityType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading approval workflow: %w", err)
+	}
+	if len(workflow.Steps) == 0 {
+		return nil, nil
+	}
+
+	attributes, err := ap.attributes(ctx, tenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	approvals, stepOrder, err := ap.advance(ctx, tenantID, workflow, 0, requesterID, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowID := workflow.ID
+	request := &models.ApprovalRequest{
+		TenantID:         tenantID,
+		WorkflowID:       &workflowID,
+		EntityType:       entityType,
+		EntityID:         entityID,
+		RequesterID:      requesterID,
+		Status:           ApprovalStatusPending,
+		CurrentStepOrder: stepOrder,
+	}
+	if stepOrder == 0 {
+		request.Status = ApprovalStatusApproved
+	}
+
+	created, err := ap.approvalRepo.CreateApprovalRequest(ctx, request, approvals, ap.completion(request, &request.Status, requesterID, "No approval step applied"))
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error starting approval: %w", err)
+	}
+	return created, nil
+}
+
+// CancelApproval stops the approval running for a record, if there is one
+func (ap *ApprovalService) CancelApproval(ctx context.Context, tenantID int, entityType string, entityID int) error {
+	err := ap.approvalRepo.CancelApprovalRequest(ctx, tenantID, entityType, entityID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error cancelling approval: %w", err)
+	}
+	return nil
+}
+
+// canSeeApprovalRequest lets HR, the requester, anyone asked to approve and
+// anyone who delegated an approval see an approval request
+func canSeeApprovalRequest(actor Actor, request *models.ApprovalRequest) bool {
+	if actor.IsHR() || request.RequesterID == actor.EmployeeID {
+		return true
+	}
+	for _, approval := range request.Approvals {
+		if approval.ApproverID == actor.EmployeeID {
+			return true
+		}
+		if approval.DelegatedFromID != nil && *approval.DelegatedFromID == actor.EmployeeID {
+			return true
+		}
+	}
+	return false
+}
+
+func (ap *ApprovalService) GetApprovalRequest(ctx context.Context, actor Actor, id int) (*dto.ApprovalRequestResponse, error) {
+	request, err := ap.approvalRepo.GetApprovalRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "approval request")
+	}
+	if !canSeeApprovalRequest(actor, request) {
+		return nil, ErrForbidden
+	}
+	return request.ToResponse(), nil
+}
+
+// ListEntityApprovalRequests returns the approval history of a record that
+// the actor can see
+func (ap *ApprovalService) ListEntityApprovalRequests(ctx context.Context, actor Actor, entityType string, entityID int) ([]*dto.ApprovalRequestResponse, error) {
+	if !containsString(validApprovalEntityTypes, entityType) {
+		return nil, &utils.ValidationError{Field: "entity_type", Message: "Entity type must be one of " + strings.Join(validApprovalEntityTypes, ", ")}
+	}
+	if entityID <= 0 {
+		return nil, &utils.ValidationError{Field: "entity_id", Message: "Entity ID is required"}
+	}
+
+	requests, err := ap.approvalRepo.ListEntityApprovalRequests(ctx, actor.TenantID, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing approval requests: %w", err)
+	}
+
+	responses := []*dto.ApprovalRequestResponse{}
+	for i := range requests {
+		if canSeeApprovalRequest(actor, &requests[i]) {
+			responses = append(responses, requests[i].ToResponse())
+		}
+	}
+	return responses, nil
+}
+
+// decideStep records the approver's decision on the current stage of a
+// pending request. The decision goes on every pending approval the approver
+// has in the stage. It returns the approvals to update and what the stage
+// comes to: still pending, approved, or rejected. A settled stage supersedes
+// its remaining pending approvals.
+func decideStep(request *models.ApprovalRequest, steps map[int]models.ApprovalStep, approverID int, status string, comment string) ([]models.Approval, string, error) {
+	if request.Status != ApprovalStatusPending {
+		return nil, "", &utils.ValidationError{Field: "status", Message: "Only pending approval requests can be " + status}
+	}
+
+	stage := []models.Approval{}
+	mine := []bool{}
+	found := false
+	for _, approval := range request.Approvals {
+		if approval.StepOrder != request.CurrentStepOrder {
+			continue
+		}
+		decided := approval.ApproverID == approverID && approval.Status == ApprovalStatusPending
+		if decided {
+			found = true
+			approval.Status = status
+			approval.Comments = comment
+		}
+		stage = append(stage, approval)
+		mine = append(mine, decided)
+	}
+	if !found {
+		return nil, "", ErrForbidden
+	}
+
+	outcome := stageOutcome(stage, steps)
+	decisions := []models.Approval{}
+	for i, approval := range stage {
+		switch {
+		case mine[i]:
+			decisions = append(decisions, approval)
+		case approval.Status == ApprovalStatusPending && outcome != ApprovalStatusPending:
+			approval.Status = ApprovalStatusSuperseded
+			decisions = append(decisions, approval)
+		}
+	}
+	return decisions, outcome, nil
+}
+
+// decide applies the actor's decision to a pending request. A rejected stage
+// rejects the request, and an approved stage moves it on to the next stage
+// that applies or, after the last one, approves it. The owning module is told
+// when the request ends.
+func (ap *ApprovalService) decide(ctx context.Context, actor Actor, request *models.ApprovalRequest, status string, comment string) (*models.ApprovalRequest, error) {
+	comment = strings.TrimSpace(comment)
+
+	var workflow *models.ApprovalWorkflow
+	if request.WorkflowID != nil {
+		loaded, err := ap.approvalRepo.GetWorkflow(ctx, request.TenantID, *request.WorkflowID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("error loading approval workflow: %w", err)
+		}
+		workflow = loaded
+	}
+	if workflow == nil {
+		workflow = &models.ApprovalWorkflow{}
+	}
+
+	decisions, outcome, err := decideStep(request, stepsByID(workflow), actor.EmployeeID, status, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	transition := &repositories.ApprovalTransition{
+		TenantID:  request.TenantID,
+		RequestID: request.ID,
+		Version:   request.Version,
+		Decisions: decisions,
+		Status:    outcome,
+		StepOrder: request.CurrentStepOrder,
+	}
+	if outcome == ApprovalStatusApproved {
+		attributes, err := ap.attributes(ctx, request.TenantID, request.EntityType, request.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		approvals, stepOrder, err := ap.advance(ctx, request.TenantID, workflow, request.CurrentStepOrder, request.RequesterID, attributes)
+		if err != nil {
+			return nil, err
+		}
+		transition.NewApprovals = approvals
+		if stepOrder != 0 {
+			transition.Status = ApprovalStatusPending
+			transition.StepOrder = stepOrder
+		}
+	}
+
+	updated, err := ap.approvalRepo.ApplyApprovalTransition(ctx, transition, ap.completion(request, &transition.Status, actor.EmployeeID, comment))
+	if errors.Is(err, repositories.ErrApprovalConflict) {
+		return nil, ErrConflict
+	}
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error recording approval decision: %w", err)
+	}
+	return updated, nil
+}
+
+// decideRequest applies the actor's decision to an approval request. A
+// version other than 0 must match the request's, so a decision made on a
+// stale view is refused as a conflict.
+func (ap *ApprovalService) decideRequest(ctx context.Context, actor Actor, id int, version int, status string, comment string) (*dto.ApprovalRequestResponse, error) {
+	request, err := ap.approvalRepo.GetApprovalRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "approval request")
+	}
+	if version != 0 && version != request.Version {
+		return nil, ErrConflict
+	}
+	updated, err := ap.decide(ctx, actor, request, status, comment)
+	if err != nil {
+		return nil, err
+	}
+	return updated.ToResponse(), nil
+}
+
+func (ap *ApprovalService) ApproveRequest(ctx context.Context, actor Actor, id int, req *dto.ApprovalDecisionRequest) (*dto.ApprovalRequestResponse, error) {
+	return ap.decideRequest(ctx, actor, id, req.Version, ApprovalStatusApproved, req.Comment)
+}
+
+func (ap *ApprovalService) RejectRequest(ctx context.Context, actor Actor, id int, req *dto.ApprovalDecisionRequest) (*dto.ApprovalRequestResponse, error) {
+	return ap.decideRequest(ctx, actor, id, req.Version, ApprovalStatusRejected, req.Comment)
+}
+
+// decideEntity applies the actor's decision to the approval running for a
+// record, for modules that keep their own approve and reject endpoints
+func (ap *ApprovalService) decideEntity(ctx context.Context, actor Actor, entityType string, entityID int, status string, comment string) error {
+	request, err := ap.approvalRepo.GetPendingApprovalRequest(ctx, actor.TenantID, entityType, entityID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &utils.ValidationError{Field: "status", Message: "Only pending requests can be " + status}
+	}
+	if err != nil {
+		return fmt.Errorf("error loading approval request: %w", err)
+	}
+	_, err = ap.decide(ctx, actor, request, status, comment)
+	return err
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestValidateWorkflow(t *testing.T) {
+	roleID := 4
+
+	t.Run("gives parallel steps the order of the step before them", func(t *testing.T) {
+		workflow, err := validateWorkflow(&dto.ApprovalWorkflowRequest{
+			Name:       "Memo approval",
+			EntityType: ApprovalEntityMemo,
+			Steps: []dto.ApprovalStepRequest{
+				{ApproverType: ApproverTypeManager},
+				{ApproverType: ApproverTypeDepartmentHead, Parallel: true},
+				{ApproverType: ApproverTypeRole, ApproverRoleID: &roleID, ApprovalMode: ApprovalModeQuorum, RequiredApprovals: 2},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := []int{workflow.Steps[0].StepOrder, workflow.Steps[1].StepOrder, workflow.Steps[2].StepOrder}
+		if !reflect.DeepEqual(got, []int{1, 1, 2}) {
+			t.Errorf("got step orders %v, want [1 1 2]", got)
+		}
+		if workflow.Steps[0].ApprovalMode != ApprovalModeAny || workflow.Steps[2].RequiredApprovals != 2 {
+			t.Errorf("got modes %+v, want any by default and the quorum kept", workflow.Steps)
+		}
+	})
+
+	t.Run("numbers the steps and drops roles from non-role steps", func(t *testing.T) {
+		workflow, err := validateWorkflow(&dto.ApprovalWorkflowRequest{
+			Name:       " Leave approval ",
+			EntityType: ApprovalEntityLeaveRequest,
+			Steps: []dto.ApprovalStepRequest{
+				{ApproverType: ApproverTypeManager, ApproverRoleID: &roleID},
+				{ApproverType: ApproverTypeRole, ApproverRoleID: &roleID},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if workflow.Name != "Leave approval" || workflow.Status != "active" {
+			t.Errorf("got name %q and status %q, want the trimmed name and active", workflow.Name, workflow.Status)
+		}
+		if workflow.Steps[0].StepOrder != 1 || workflow.Steps[1].StepOrder != 2 {
+			t.Errorf("got step orders %d and %d, want 1 and 2", workflow.Steps[0].StepOrder, workflow.Steps[1].StepOrder)
+		}
+		if workflow.Steps[0].ApproverRoleID != nil || workflow.Steps[1].ApproverRoleID == nil {
+			t.Errorf("got roles %v and %v, want the role on the role step only", workflow.Steps[0].ApproverRoleID, workflow.Steps[1].ApproverRoleID)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		req   dto.ApprovalWorkflowRequest
+		field string
+	}{
+		{"missing name", dto.ApprovalWorkflowRequest{EntityType: ApprovalEntityMemo, Steps: []dto.ApprovalStepRequest{{ApproverType: ApproverTypeManager}}}, "name"},
+		{"unknown entity type", dto.ApprovalWorkflowRequest{Name: "x", EntityType: "invoice", Steps: []dto.ApprovalStepRequest{{ApproverType: ApproverTypeManager}}}, "entity_type"},
+		{"no steps", dto.ApprovalWorkflowRequest{Name: "x", EntityType: ApprovalEntityMemo}, "steps"},
+		{"role step without a role", dto.ApprovalWorkflowRequest{Name: "x", EntityType: ApprovalEntityMemo, Steps: []dto.ApprovalStepRequest{{ApproverType: ApproverTypeRole}}}, "steps"},
+		{"unknown approver type", dto.ApprovalWorkflowRequest{Name: "x", EntityType: ApprovalEntityMemo, Steps: []dto.ApprovalStepRequest{{ApproverType: "ceo"}}}, "steps"},
+		{"parallel first step", dto.ApprovalWorkflowRequest{Name: "x", EntityType: ApprovalEntityMemo, Steps: []dto.ApprovalStepRequest{{ApproverType: ApproverTypeManager, Parallel: true}}}, "steps"},
+		{"quorum without a count", dto.ApprovalWorkflowRequest{Name: "x", EntityType: ApprovalEntityMemo, Steps: []dto.ApprovalStepRequest{{ApproverType: ApproverTypeManager, ApprovalMode: ApprovalModeQuorum}}}, "steps"},
+		{"reminder after the SLA", dto.ApprovalWorkflowRequest{Name: "x", EntityType: ApprovalEntityMemo, Steps: []dto.ApprovalStepRequest{{ApproverType: ApproverTypeManager, ReminderHours: 48, SLAHours: 24}}}, "steps"},
+		{"negative SLA", dto.ApprovalWorkflowRequest{Name: "x", EntityType: ApprovalEntityMemo, Steps: []dto.ApprovalStepRequest{{ApproverType: ApproverTypeManager, SLAHours: -1}}}, "steps"},
+		{"condition on an unknown field", dto.ApprovalWorkflowRequest{Name: "x", EntityType: ApprovalEntityMemo, Steps: []dto.ApprovalStepRequest{{ApproverType: ApproverTypeManager, Conditions: []dto.ApprovalCondition{{Field: "days_requested", Operator: "gt", Value: 10.0}}}}}, "steps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateWorkflow(&tt.req)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestPickApprovers(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []int
+		fallback   []int
+		want       []int
+	}{
+		{"keeps the candidates", []int{3, 4}, []int{9}, []int{3, 4}},
+		{"drops the requester and duplicates", []int{5, 3, 3}, []int{9}, []int{3}},
+		{"falls back when only the requester is left", []int{5}, []int{9, 5}, []int{9}},
+		{"falls back when nobody was found", nil, []int{9}, []int{9}},
+		{"returns nobody when the fallback is empty too", nil, []int{5}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickApprovers(tt.candidates, 5, tt.fallback); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecideStep(t *testing.T) {
+	stepA, stepB := 10, 11
+	steps := map[int]models.ApprovalStep{
+		stepA: {ID: stepA, StepOrder: 2, ApprovalMode: ApprovalModeAny},
+		stepB: {ID: stepB, StepOrder: 2, ApprovalMode: ApprovalModeQuorum, RequiredApprovals: 2},
+	}
+	request := func() *models.ApprovalRequest {
+		return &models.ApprovalRequest{
+			Status:           ApprovalStatusPending,
+			CurrentStepOrder: 2,
+			Approvals: []models.Approval{
+				{ID: 1, StepOrder: 1, ApproverID: 3, Status: ApprovalStatusApproved},
+				{ID: 2, ApprovalStepID: &stepA, StepOrder: 2, ApproverID: 7, Status: ApprovalStatusPending},
+				{ID: 3, ApprovalStepID: &stepA, StepOrder: 2, ApproverID: 8, Status: ApprovalStatusPending},
+				{ID: 4, ApprovalStepID: &stepB, StepOrder: 2, ApproverID: 20, Status: ApprovalStatusPending},
+				{ID: 5, ApprovalStepID: &stepB, StepOrder: 2, ApproverID: 21, Status: ApprovalStatusPending},
+				{ID: 6, ApprovalStepID: &stepB, StepOrder: 2, ApproverID: 22, Status: ApprovalStatusPending},
+			},
+		}
+	}
+
+	t.Run("keeps the stage open until every parallel step is settled", func(t *testing.T) {
+		decisions, outcome, err := decideStep(request(), steps, 7, ApprovalStatusApproved, "Fine")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outcome != ApprovalStatusPending {
+			t.Errorf("got outcome %s, want pending", outcome)
+		}
+		if len(decisions) != 1 || decisions[0].ID != 2 || decisions[0].Status != ApprovalStatusApproved || decisions[0].Comments != "Fine" {
+			t.Errorf("got %+v, want only approval 2 approved with the comment", decisions)
+		}
+	})
+
+	t.Run("a quorum step survives one rejection", func(t *testing.T) {
+		_, outcome, err := decideStep(request(), steps, 20, ApprovalStatusRejected, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outcome != ApprovalStatusPending {
+			t.Errorf("got outcome %s, want pending", outcome)
+		}
+	})
+
+	t.Run("a rejection in an any step rejects the stage and supersedes the rest", func(t *testing.T) {
+		decisions, outcome, err := decideStep(request(), steps, 8, ApprovalStatusRejected, "Not this month")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outcome != ApprovalStatusRejected {
+			t.Errorf("got outcome %s, want rejected", outcome)
+		}
+		if len(decisions) != 5 {
+			t.Fatalf("got %d decisions, want 5", len(decisions))
+		}
+		for _, decision := range decisions {
+			want := ApprovalStatusSuperseded
+			if decision.ID == 3 {
+				want = ApprovalStatusRejected
+			}
+			if decision.Status != want {
+				t.Errorf("approval %d: got %s, want %s", decision.ID, decision.Status, want)
+			}
+		}
+	})
+
+	t.Run("forbids approvers of other stages", func(t *testing.T) {
+		if _, _, err := decideStep(request(), steps, 3, ApprovalStatusApproved, ""); !errors.Is(err, ErrForbidden) {
+			t.Errorf("got error %v, want ErrForbidden", err)
+		}
+	})
+
+	t.Run("rejects decisions on finished requests", func(t *testing.T) {
+		finished := request()
+		finished.Status = ApprovalStatusApproved
+		var validationErr *utils.ValidationError
+		if _, _, err := decideStep(finished, steps, 7, ApprovalStatusApproved, ""); !errors.As(err, &validationErr) {
+			t.Errorf("got error %v, want a validation error", err)
+		}
+	})
+}
+
+func TestCanSeeApprovalRequest(t *testing.T) {
+	request := &models.ApprovalRequest{RequesterID: 5, Approvals: []models.Approval{{ApproverID: 7}}}
+
+	for _, tt := range []struct {
+		actor Actor
+		want  bool
+	}{
+		{Actor{EmployeeID: 5, Role: "Employee"}, true},
+		{Actor{EmployeeID: 7, Role: "Employee"}, true},
+		{Actor{EmployeeID: 1, Role: "HR"}, true},
+		{Actor{EmployeeID: 9, Role: "Employee"}, false},
+	} {
+		if got := canSeeApprovalRequest(tt.actor, request); got != tt.want {
+			t.Errorf("employee %d: got %v, want %v", tt.actor.EmployeeID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// errAuditChainBroken stops the walk of a chain at its first broken link
+var errAuditChainBroken = errors.New("audit chain is broken")
+
+// ParseAuditSigningKey reads the checkpoint signing key: a base64 Ed25519
+// seed of 32 bytes, such as the output of `openssl rand -base64 32`, or a
+// full 64-byte private key. An empty value means no key.
+func ParseAuditSigningKey(value string) (ed25519.PrivateKey, error) {
+	if value == "" {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("signing key is not base64: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("signing key must be %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+	}
+}
+
+// auditCheckpointMessage is what a checkpoint's signature covers: the lines
+// "peopleos-audit-checkpoint", tenant ID, last log ID, last hash and the
+// creation time in RFC 3339 with nanoseconds, UTC. Anyone holding the public
+// key can check an exported checkpoint against it.
+func auditCheckpointMessage(c *models.AuditCheckpoint) []byte {
+	return []byte(fmt.Sprintf("peopleos-audit-checkpoint\n%d\n%d\n%s\n%s",
+		c.TenantID, c.LastLogID, c.LastHash, c.CreatedAt.UTC().Format(time.RFC3339Nano)))
+}
+
+func signAuditCheckpoint(key ed25519.PrivateKey, c *models.AuditCheckpoint) {
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, auditCheckpointMessage(c)))
+	c.PublicKey = base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+func verifyAuditCheckpoint(publicKey ed25519.PublicKey, c *models.AuditCheckpoint) bool {
+	signature, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(publicKey, auditCheckpointMessage(c), signature)
+}
+
+// auditChainVerifier walks a tenant's audit rows in chain order. Rows from
+// before the chain existed have no hash and are counted but not verified;
+// once a hashed row is seen every later row must link to the one before it.
+type auditChainVerifier struct {
+	result   *dto.AuditChainVerification
+	prevHash string
+	started  bool
+	// hashes holds the hash of every checkpointed row that has been walked
+	hashes map[int]string
+}
+
+func newAuditChainVerifier(tenantID int, checkpoints []models.AuditCheckpoint) *auditChainVerifier {
+	v := &auditChainVerifier{
+		result: &dto.AuditChainVerification{TenantID: tenantID, Valid: true},
+		hashes: map[int]string{},
+	}
+	for _, c := range checkpoints {
+		v.hashes[c.LastLogID] = ""
+	}
+	return v
+}
+
+func (v *auditChainVerifier) fail(broken *dto.AuditChainBreak) bool {
+	v.result.Valid = false
+	v.result.BrokenLink = broken
+	return false
+}
+
+// add checks the next row of the chain and reports whether the chain still
+// holds
+func (v *auditChainVerifier) add(row *models.AuditLog) bool {
+	if row.Hash == "" {
+		if v.started {
+			return v.fail(&dto.AuditChainBreak{LogID: row.ID, Reason: "Row has no hash"})
+		}
+		v.result.UnchainedRows++
+		return true
+	}
+	v.started = true
+
+	if row.PrevHash != v.prevHash {
+		return v.fail(&dto.AuditChainBreak{
+			LogID:        row.ID,
+			Reason:       "Previous hash does not match the preceding row",
+			ExpectedHash: v.prevHash,
+			ActualHash:   row.PrevHash,
+		})
+	}
+	hash, err := row.ComputeHash()
+	if err != nil || hash != row.Hash {
+		return v.fail(&dto.AuditChainBreak{
+			LogID:        row.ID,
+			Reason:       "Row content does not match its hash",
+			ExpectedHash: hash,
+			ActualHash:   row.Hash,
+		})
+	}
+
+	v.prevHash = row.Hash
+	if _, ok := v.hashes[row.ID]; ok {
+		v.hashes[row.ID] = row.Hash
+	}
+	v.result.CheckedRows++
+	v.result.LastLogID = row.ID
+	v.result.LastHash = row.Hash
+	return true
+}
+
+// checkCheckpoints compares the walked chain with its checkpoints, checking
+// their signatures when publicKey is set
+func (v *auditChainVerifier) checkCheckpoints(checkpoints []models.AuditCheckpoint, publicKey ed25519.PublicKey) {
+	v.result.SignaturesVerified = publicKey != nil
+	for i := range checkpoints {
+		c := &checkpoints[i]
+		if publicKey != nil && !verifyAuditCheckpoint(publicKey, c) {
+			v.fail(&dto.AuditChainBreak{CheckpointID: c.ID, Reason: "Checkpoint signature is invalid"})
+			return
+		}
+		if hash := v.hashes[c.LastLogID]; hash != c.LastHash {
+			v.fail(&dto.AuditChainBreak{
+				CheckpointID: c.ID,
+				LogID:        c.LastLogID,
+				Reason:       "Chain no longer matches the checkpoint",
+				ExpectedHash: c.LastHash,
+				ActualHash:   hash,
+			})
+			return
+		}
+		v.result.CheckedCheckpoints++
+	}
+}
+
+func (au *AuditService) publicKey() ed25519.PublicKey {
+	if au.signingKey == nil {
+		return nil
+	}
+	return au.signingKey.Public().(ed25519.PublicKey)
+}
+
+// ListAuditChains returns the tenants that have an audit chain, zero
+// standing for the rows without a tenant
+func (au *AuditService) ListAuditChains(ctx context.Context) ([]int, error) {
+	tenantIDs, err := au.auditRepo.ListAuditChains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit chains: %w", err)
+	}
+	return tenantIDs, nil
+}
+
+// VerifyTenantChain walks the tenant's audit chain and then its checkpoints,
+// stopping at the first broken link
+func (au *AuditService) VerifyTenantChain(ctx context.Context, tenantID int) (*dto.AuditChainVerification, error) {
+	checkpoints, err := au.auditRepo.ListCheckpoints(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit checkpoints: %w", err)
+	}
+
+	v := newAuditChainVerifier(tenantID, checkpoints)
+	err = au.auditRepo.StreamAuditChain(ctx, tenantID, func(row *models.AuditLog) error {
+		if !v.add(row) {
+			return errAuditChainBroken
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAuditChainBroken) {
+		return nil, fmt.Errorf("error reading audit logs: %w", err)
+	}
+
+	if v.result.Valid {
+		v.checkCheckpoints(checkpoints, au.publicKey())
+	}
+	return v.result, nil
+}
+
+// VerifyAuditChain verifies the audit chain of the actor's tenant
+func (au *AuditService) VerifyAuditChain(ctx context.Context, actor Actor) (*dto.AuditChainVerification, error) {
+	if err := au.authorize(ctx, actor, PermissionRead); err != nil {
+		return nil, err
+	}
+	return au.VerifyTenantChain(ctx, actor.TenantID)
+}
+
+// CreateCheckpoints signs a checkpoint of every chain that has grown since
+// its last one and returns how many were made
+func (au *AuditService) CreateCheckpoints(ctx context.Context, now time.Time) (int, error) {
+	if au.signingKey == nil {
+		return 0, nil
+	}
+
+	heads, err := au.auditRepo.ListUncheckpointedHeads(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error listing audit chains: %w", err)
+	}
+
+	created := 0
+	for i := range heads {
+		c := &heads[i]
+		// Postgres keeps microseconds, and the signature covers the time
+		c.CreatedAt = now.UTC().Truncate(time.Microsecond)
+		signAuditCheckpoint(au.signingKey, c)
+		if _, err := au.auditRepo.CreateCheckpoint(ctx, c); err != nil {
+			return created, fmt.Errorf("error creating audit checkpoint: %w", err)
+		}
+		created++
+	}
+	return created, nil
+}
+
+var auditCheckpointExportHeader = []string{"id", "tenant_id", "last_log_id", "last_hash", "created_at", "signature", "public_key"}
+
+// ExportCheckpoints writes the checkpoints of the actor's tenant into w,
+// oldest first, for safekeeping outside the database
+func (au *AuditService) ExportCheckpoints(ctx context.Context, actor Actor, format string, w io.Writer) error {
+	if err := au.authorize(ctx, actor, PermissionExport); err != nil {
+		return err
+	}
+	if format != utils.FormatCSV && format != utils.FormatJSONL {
+		return &utils.ValidationError{Field: "format", Message: "Format must be one of csv or jsonl"}
+	}
+
+	checkpoints, err := au.auditRepo.ListCheckpoints(ctx, actor.TenantID)
+	if err != nil {
+		return fmt.Errorf("error listing audit checkpoints: %w", err)
+	}
+
+	writer, err := utils.NewTabularWriter(format, w)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader(auditCheckpointExportHeader); err != nil {
+		return err
+	}
+	for _, c := range checkpoints {
+		err := writer.WriteRow([]string{
+			strconv.Itoa(c.ID),
+			strconv.Itoa(c.TenantID),
+			strconv.Itoa(c.LastLogID),
+			c.LastHash,
+			c.CreatedAt.UTC().Format(time.RFC3339Nano),
+			c.Signature,
+			c.PublicKey,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// RunScheduler checkpoints the audit chains now and then every interval
+// until ctx is cancelled. Without a signing key it returns at once.
+func (au *AuditService) RunScheduler(ctx context.Context, interval time.Duration) {
+	if au.signingKey == nil {
+		log.Printf("audit checkpoint scheduler: no signing key configured, checkpoints are disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if created, err := au.CreateCheckpoints(ctx, time.Now()); err != nil {
+			log.Printf("audit checkpoint scheduler: %v", err)
+		} else if created > 0 {
+			log.Printf("audit checkpoint scheduler: signed %d checkpoints", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
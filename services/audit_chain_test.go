@@ -0,0 +1,159 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+// auditChain builds n chained audit rows of tenant 1
+func auditChain(t *testing.T, n int) []*models.AuditLog {
+	t.Helper()
+	tenantID := 1
+	logs := []*models.AuditLog{}
+	prevHash := ""
+	for i := 1; i <= n; i++ {
+		log := &models.AuditLog{
+			ID:         i,
+			TenantID:   &tenantID,
+			EntityType: "department",
+			EntityID:   3,
+			Action:     "update",
+			NewData:    map[string]interface{}{"name": "Ops", "level": float64(i)},
+			PrevHash:   prevHash,
+			CreatedAt:  time.Date(2026, 1, 1, 8, i, 0, 0, time.UTC),
+		}
+		hash, err := log.ComputeHash()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		log.Hash = hash
+		prevHash = hash
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+func walkAuditChain(v *auditChainVerifier, logs []*models.AuditLog) {
+	for _, log := range logs {
+		if !v.add(log) {
+			return
+		}
+	}
+}
+
+func TestAuditChainVerifier(t *testing.T) {
+	t.Run("accepts an intact chain after unchained rows", func(t *testing.T) {
+		logs := append([]*models.AuditLog{{ID: 0}}, auditChain(t, 3)...)
+		v := newAuditChainVerifier(1, nil)
+		walkAuditChain(v, logs)
+
+		if !v.result.Valid || v.result.CheckedRows != 3 || v.result.UnchainedRows != 1 || v.result.LastLogID != 3 {
+			t.Errorf("got %+v, want 3 rows checked after 1 unchained", v.result)
+		}
+	})
+
+	t.Run("finds an edited row", func(t *testing.T) {
+		logs := auditChain(t, 3)
+		logs[1].NewData["name"] = "Operations"
+		v := newAuditChainVerifier(1, nil)
+		walkAuditChain(v, logs)
+
+		if v.result.Valid || v.result.BrokenLink.LogID != 2 || v.result.BrokenLink.Reason != "Row content does not match its hash" {
+			t.Errorf("got %+v, want row 2 reported as edited", v.result.BrokenLink)
+		}
+	})
+
+	t.Run("finds a deleted row", func(t *testing.T) {
+		logs := auditChain(t, 3)
+		v := newAuditChainVerifier(1, nil)
+		walkAuditChain(v, []*models.AuditLog{logs[0], logs[2]})
+
+		if v.result.Valid || v.result.BrokenLink.LogID != 3 || v.result.BrokenLink.ExpectedHash != logs[0].Hash {
+			t.Errorf("got %+v, want row 3 no longer linked to row 1", v.result.BrokenLink)
+		}
+	})
+
+	t.Run("finds a hash removed after the chain started", func(t *testing.T) {
+		logs := auditChain(t, 3)
+		logs[2].Hash = ""
+		v := newAuditChainVerifier(1, nil)
+		walkAuditChain(v, logs)
+
+		if v.result.Valid || v.result.BrokenLink.LogID != 3 {
+			t.Errorf("got %+v, want row 3 reported without a hash", v.result.BrokenLink)
+		}
+	})
+}
+
+func TestAuditCheckpoints(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	publicKey := key.Public().(ed25519.PublicKey)
+	logs := auditChain(t, 3)
+
+	checkpoint := func() models.AuditCheckpoint {
+		c := models.AuditCheckpoint{ID: 1, TenantID: 1, LastLogID: 2, LastHash: logs[1].Hash, CreatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)}
+		signAuditCheckpoint(key, &c)
+		return c
+	}
+
+	t.Run("accepts a matching signed checkpoint", func(t *testing.T) {
+		checkpoints := []models.AuditCheckpoint{checkpoint()}
+		v := newAuditChainVerifier(1, checkpoints)
+		walkAuditChain(v, logs)
+		v.checkCheckpoints(checkpoints, publicKey)
+
+		if !v.result.Valid || v.result.CheckedCheckpoints != 1 || !v.result.SignaturesVerified {
+			t.Errorf("got %+v, want the checkpoint verified", v.result)
+		}
+	})
+
+	t.Run("finds a rewritten chain", func(t *testing.T) {
+		checkpoints := []models.AuditCheckpoint{checkpoint()}
+		// Someone with database access edits a row and rehashes the chain
+		rewritten := auditChain(t, 3)
+		prevHash := ""
+		for _, log := range rewritten {
+			log.NewData["name"] = "Operations"
+			log.PrevHash = prevHash
+			log.Hash, _ = log.ComputeHash()
+			prevHash = log.Hash
+		}
+		v := newAuditChainVerifier(1, checkpoints)
+		walkAuditChain(v, rewritten)
+		v.checkCheckpoints(checkpoints, publicKey)
+
+		if v.result.Valid || v.result.BrokenLink.CheckpointID != 1 || v.result.BrokenLink.ActualHash != rewritten[1].Hash {
+			t.Errorf("got %+v, want checkpoint 1 no longer matching", v.result.BrokenLink)
+		}
+	})
+
+	t.Run("finds a forged checkpoint", func(t *testing.T) {
+		c := checkpoint()
+		c.LastLogID = 3
+		c.LastHash = logs[2].Hash
+		checkpoints := []models.AuditCheckpoint{c}
+		v := newAuditChainVerifier(1, checkpoints)
+		walkAuditChain(v, logs)
+		v.checkCheckpoints(checkpoints, publicKey)
+
+		if v.result.Valid || v.result.BrokenLink.Reason != "Checkpoint signature is invalid" {
+			t.Errorf("got %+v, want the signature rejected", v.result.BrokenLink)
+		}
+	})
+}
+
+func TestParseAuditSigningKey(t *testing.T) {
+	key, err := ParseAuditSigningKey("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		t.Errorf("got %d byte key and error %v, want a key from the seed", len(key), err)
+	}
+	if key, err := ParseAuditSigningKey(""); key != nil || err != nil {
+		t.Errorf("got %v and %v, want no key", key, err)
+	}
+	if _, err := ParseAuditSigningKey("c2hvcnQ="); err == nil {
+		t.Error("got no error, want one for a short key")
+	}
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Permissions held through roles
+const (
+	PermissionResourceAuditLogs = "audit_logs"
+
+	PermissionRead   = "read"
+	PermissionExport = "export"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+// auditIgnoredFields change on every write and would clutter every diff
+var auditIgnoredFields = []string{"updated_at"}
+
+type IAuditService interface {
+	ListAuditLogs(ctx context.Context, actor Actor, filter *dto.AuditLogFilter) (*dto.AuditLogPage, error)
+	GetEntityTimeline(ctx context.Context, actor Actor, entityType string, entityID int) (*dto.AuditTimelineResponse, error)
+	ExportAuditLogs(ctx context.Context, actor Actor, filter *dto.AuditLogFilter, format string, w io.Writer) error
+	VerifyAuditChain(ctx context.Context, actor Actor) (*dto.AuditChainVerification, error)
+	ExportCheckpoints(ctx context.Context, actor Actor, format string, w io.Writer) error
+}
+
+// AuditService reads the audit log. signingKey signs the chain checkpoints;
+// without one no checkpoints are made and their signatures go unchecked.
+type AuditService struct {
+	auditRepo  *repositories.AuditRepository
+	roleRepo   *repositories.RoleRepository
+	signingKey ed25519.PrivateKey
+}
+
+func NewAuditService(auditRepo *repositories.AuditRepository, roleRepo *repositories.RoleRepository, signingKey ed25519.PrivateKey) *AuditService {
+	return &AuditService{
+		auditRepo:  auditRepo,
+		roleRepo:   roleRepo,
+		signingKey: signingKey,
+	}
+}
+
+// authorize checks the actor may perform action on the audit log. HR may
+// read it; anyone else, such as an auditor, and every export needs the
+// permission through their role.
+func (au *AuditService) authorize(ctx context.Context, actor Actor, action string) error {
+	if action == PermissionRead && actor.IsHR() {
+		return nil
+	}
+	allowed, err := au.roleRepo.HasPermission(ctx, actor.TenantID, actor.EmployeeID, PermissionResourceAuditLogs, action)
+	if err != nil {
+		return fmt.Errorf("error checking permissions: %w", err)
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// parseAuditTime reads an RFC 3339 time or a YYYY-MM-DD date. A date as the
+// end of a range includes the whole day.
+func parseAuditTime(value string, field string, endOfRange bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	d, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: field, Message: strings.ToUpper(field[:1]) + field[1:] + " must be an RFC 3339 time or a YYYY-MM-DD date"}
+	}
+	if endOfRange {
+		d = d.AddDate(0, 0, 1)
+	}
+	return &d, nil
+}
+
+func encodeAuditCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+func decodeAuditCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if id, err := strconv.Atoi(string(raw)); err == nil && id > 0 {
+			return id, nil
+		}
+	}
+	return 0, &utils.ValidationError{Field: "cursor", Message: "Invalid cursor"}
+}
+
+// buildAuditLogQuery validates the filter and turns it into a query
+func buildAuditLogQuery(filter *dto.AuditLogFilter) (*repositories.AuditLogQuery, error) {
+	from, err := parseAuditTime(filter.From, "from", false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseAuditTime(filter.To, "to", true)
+	if err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil && !to.After(*from) {
+		return nil, &utils.ValidationError{Field: "to", Message: "To must be after from"}
+	}
+	if filter.EntityID != 0 && filter.EntityType == "" {
+		return nil, &utils.ValidationError{Field: "entity_type", Message: "Entity type is required with an entity ID"}
+	}
+
+	q := &repositories.AuditLogQuery{
+		EntityType: filter.EntityType,
+		EntityID:   filter.EntityID,
+		ActorID:    filter.ActorID,
+		Action:     filter.Action,
+		From:       from,
+		To:         to,
+	}
+	if filter.Cursor != "" {
+		if q.BeforeID, err = decodeAuditCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// ListAuditLogs returns one page of the tenant's audit log, newest first
+func (au *AuditService) ListAuditLogs(ctx context.Context, actor Actor, filter *dto.AuditLogFilter) (*dto.AuditLogPage, error) {
+	if err := au.authorize(ctx, actor, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	q, err := buildAuditLogQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultAuditPageSize
+	}
+	if limit < 1 || limit > maxAuditPageSize {
+		return nil, &utils.ValidationError{Field: "limit", Message: fmt.Sprintf("Limit must be between 1 and %d", maxAuditPageSize)}
+	}
+
+	// One extra row tells whether there is another page
+	logs, err := au.auditRepo.ListAuditLogs(ctx, actor.TenantID, q, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit logs: %w", err)
+	}
+
+	page := &dto.AuditLogPage{Items: []*dto.AuditLogResponse{}}
+	if len(logs) > limit {
+		logs = logs[:limit]
+		page.NextCursor = encodeAuditCursor(logs[limit-1].ID)
+	}
+	for i := range logs {
+		page.Items = append(page.Items, logs[i].ToResponse())
+	}
+	return page, nil
+}
+
+// diffAuditData lists the fields that differ between the old and new data of
+// an audit row, by field name
+func diffAuditData(oldData map[string]interface{}, newData map[string]interface{}) []dto.AuditFieldChange {
+	fields := map[string]bool{}
+	for field := range oldData {
+		fields[field] = true
+	}
+	for field := range newData {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		if !containsString(auditIgnoredFields, field) {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []dto.AuditFieldChange{}
+	for _, field := range names {
+		oldValue, hadOld := oldData[field]
+		newValue, hasNew := newData[field]
+		if hadOld && hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, dto.AuditFieldChange{Field: field, Old: oldValue, New: newValue})
+	}
+	return changes
+}
+
+// GetEntityTimeline returns the history of one entity with what changed in
+// each step
+func (au *AuditService) GetEntityTimeline(ctx context.Context, actor Actor, entityType string, entityID int) (*dto.AuditTimelineResponse, error) {
+	if err := au.authorize(ctx, actor, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	logs, err := au.auditRepo.ListEntityAuditLogs(ctx, actor.TenantID, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit logs: %w", err)
+	}
+
+	timeline := &dto.AuditTimelineResponse{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Entries:    make([]*dto.AuditTimelineEntry, len(logs)),
+	}
+	for i := range logs {
+		timeline.Entries[i] = &dto.AuditTimelineEntry{
+			ID:        logs[i].ID,
+			ActorID:   logs[i].ActorID,
+			ActorName: logs[i].ActorName,
+			Action:    logs[i].Action,
+			Changes:   diffAuditData(logs[i].OldData, logs[i].NewData),
+			RequestID: logs[i].RequestID,
+			CreatedAt: logs[i].CreatedAt,
+		}
+	}
+	return timeline, nil
+}
+
+var auditExportHeader = []string{"id", "created_at", "actor_id", "actor_name", "entity_type", "entity_id", "action", "request_id", "ip_address", "old_data", "new_data"}
+
+func auditExportJSON(data map[string]interface{}) (string, error) {
+	if data == nil {
+		return "", nil
+	}
+	encoded, err := json.Marshal(data)
+	return string(encoded), err
+}
+
+func auditExportRow(log *models.AuditLog) ([]string, error) {
+	oldData, err := auditExportJSON(log.OldData)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := auditExportJSON(log.NewData)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		strconv.Itoa(log.ID),
+		log.CreatedAt.Format(time.RFC3339),
+		formatOptionalInt(log.ActorID),
+		log.ActorName,
+		log.EntityType,
+		strconv.Itoa(log.EntityID),
+		log.Action,
+		log.RequestID,
+		log.IPAddress,
+		oldData,
+		newData,
+	}, nil
+}
+
+// ExportAuditLogs streams every matching audit row into w, oldest first
+func (au *AuditService) ExportAuditLogs(ctx context.Context, actor Actor, filter *dto.AuditLogFilter, format string, w io.Writer) error {
+	if err := au.authorize(ctx, actor, PermissionExport); err != nil {
+		return err
+	}
+	if format != utils.FormatCSV && format != utils.FormatJSONL {
+		return &utils.ValidationError{Field: "format", Message: "Format must be one of csv or jsonl"}
+	}
+
+	q, err := buildAuditLogQuery(filter)
+	if err != nil {
+		return err
+	}
+
+	writer, err := utils.NewTabularWriter(format, w)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader(auditExportHeader); err != nil {
+		return err
+	}
+
+	err = au.auditRepo.StreamAuditLogs(ctx, actor.TenantID, q, func(log *models.AuditLog) error {
+		row, err := auditExportRow(log)
+		if err != nil {
+			return err
+		}
+		return writer.WriteRow(row)
+	})
+	if err != nil {
+		return fmt.Errorf("error reading audit logs: %w", err)
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestDiffAuditData(t *testing.T) {
+	t.Run("lists changed, added and removed fields by name", func(t *testing.T) {
+		changes := diffAuditData(
+			map[string]interface{}{"name": "Ops", "status": "active", "hod_id": float64(4), "updated_at": "2026-01-01"},
+			map[string]interface{}{"name": "Operations", "status": "active", "level": float64(2), "updated_at": "2026-02-01"},
+		)
+		want := []dto.AuditFieldChange{
+			{Field: "hod_id", Old: float64(4)},
+			{Field: "level", New: float64(2)},
+			{Field: "name", Old: "Ops", New: "Operations"},
+		}
+		if !reflect.DeepEqual(changes, want) {
+			t.Errorf("got %+v, want %+v", changes, want)
+		}
+	})
+
+	t.Run("compares nested values", func(t *testing.T) {
+		changes := diffAuditData(
+			map[string]interface{}{"custom_fields": map[string]interface{}{"shirt": "M"}},
+			map[string]interface{}{"custom_fields": map[string]interface{}{"shirt": "M"}},
+		)
+		if len(changes) != 0 {
+			t.Errorf("got %+v, want no changes", changes)
+		}
+	})
+
+	t.Run("shows every field of a created entity", func(t *testing.T) {
+		changes := diffAuditData(nil, map[string]interface{}{"id": float64(1), "name": "HR"})
+		if len(changes) != 2 || changes[0].Field != "id" || changes[1].New != "HR" {
+			t.Errorf("got %+v, want id and name", changes)
+		}
+	})
+}
+
+func TestBuildAuditLogQuery(t *testing.T) {
+	t.Run("parses the range and cursor", func(t *testing.T) {
+		q, err := buildAuditLogQuery(&dto.AuditLogFilter{
+			EntityType: "employee",
+			EntityID:   7,
+			From:       "2026-01-01T08:00:00Z",
+			To:         "2026-01-31",
+			Cursor:     encodeAuditCursor(42),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !q.From.Equal(time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)) || !q.To.Equal(date("2026-02-01")) {
+			t.Errorf("got range %v to %v, want 1 January 08:00 up to the end of 31 January", q.From, q.To)
+		}
+		if q.BeforeID != 42 || q.EntityType != "employee" || q.EntityID != 7 {
+			t.Errorf("got %+v, want employee 7 before row 42", q)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		filter dto.AuditLogFilter
+		field  string
+	}{
+		{"bad from", dto.AuditLogFilter{From: "yesterday"}, "from"},
+		{"to before from", dto.AuditLogFilter{From: "2026-02-01", To: "2026-01-01"}, "to"},
+		{"entity id without type", dto.AuditLogFilter{EntityID: 7}, "entity_type"},
+		{"bad cursor", dto.AuditLogFilter{Cursor: "not a cursor"}, "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildAuditLogQuery(&tt.filter)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/falasefemi2/peopleos/dto"
 	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
 )
 
 type IAuthService interface {
@@ -27,9 +28,10 @@ func NewAuthService(employeeRepo *repositories.EmployeeRepository) *AuthService
 }
 
 type Claims struct {
-	ID    int    `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID       int    `json:"id"`
+	TenantID int    `json:"tenant_id"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
 	jwt.StandardClaims
 }
 
@@ -46,15 +48,27 @@ func (as *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (string
 		return "", fmt.Errorf("invalid email or password")
 	}
 
+	if employee.Status == "terminated" {
+		return "", fmt.Errorf("invalid email or password")
+	}
+
+	if employee.MFAEnabled {
+		if employee.MFASecret == nil || !utils.ValidateTOTP(*employee.MFASecret, req.OTPCode, time.Now()) {
+			return "", fmt.Errorf("invalid one-time code")
+		}
+	}
+
 	if roleName == "" {
 		roleName = "Employee"
 	}
 
 	claims := &Claims{
-		ID:    employee.ID,
-		Email: employee.Email,
-		Role:  roleName,
+		ID:       employee.ID,
+		TenantID: employee.TenantID,
+		Email:    employee.Email,
+		Role:     roleName,
 		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  time.Now().Unix(),
 			ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
 		},
 	}
@@ -67,3 +81,20 @@ func (as *AuthService) Login(ctx context.Context, req *dto.LoginRequest) (string
 
 	return tokenString, nil
 }
+
+// sessionRevoked reports whether a token issued at issuedAt predates the
+// revocation of the employee's access
+func sessionRevoked(revokedAt *time.Time, issuedAt time.Time) bool {
+	return revokedAt != nil && !issuedAt.After(*revokedAt)
+}
+
+// IsSessionRevoked reports whether the employee's access was revoked after
+// their token was issued. Every token issued before offboarding completed is
+// rejected this way, whatever its expiry.
+func (as *AuthService) IsSessionRevoked(ctx context.Context, tenantID int, employeeID int, issuedAt time.Time) (bool, error) {
+	revokedAt, err := as.employeeRepo.GetAccessRevokedAt(ctx, tenantID, employeeID)
+	if err != nil {
+		return false, err
+	}
+	return sessionRevoked(revokedAt, issuedAt), nil
+}
@@ -1 +1,20 @@
 package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionRevoked(t *testing.T) {
+	revokedAt := time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)
+
+	if sessionRevoked(nil, revokedAt) {
+		t.Errorf("got revoked, want active while access stands")
+	}
+	if !sessionRevoked(&revokedAt, revokedAt.Add(-time.Hour)) {
+		t.Errorf("got active, want tokens issued before revocation rejected")
+	}
+	if sessionRevoked(&revokedAt, revokedAt.Add(time.Minute)) {
+		t.Errorf("got revoked, want tokens issued after revocation accepted")
+	}
+}
@@ -79,11 +79,17 @@ func (cs *CompanyService) CreateCompany(ctx context.Context, req *dto.CreateComp
 		Description: "Company owner with full access",
 	}
 
-	_, err = cs.roleRepo.CreateRole(ctx, superAdminRole)
+	createdRole, err := cs.roleRepo.CreateRole(ctx, superAdminRole)
 	if err != nil {
 		return nil, fmt.Errorf("error creating super admin role: %w", err)
 	}
 
+	for _, action := range []string{PermissionRead, PermissionExport} {
+		if err := cs.roleRepo.GrantPermission(ctx, createdTenant.ID, createdRole.ID, PermissionResourceAuditLogs, action); err != nil {
+			return nil, fmt.Errorf("error granting audit permissions: %w", err)
+		}
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.AdminPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("error hashing password: %w", err)
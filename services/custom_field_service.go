@@ -0,0 +1,453 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+const (
+	CustomFieldText    = "text"
+	CustomFieldNumber  = "number"
+	CustomFieldDate    = "date"
+	CustomFieldEnum    = "enum"
+	CustomFieldBoolean = "boolean"
+)
+
+// Custom field visibility levels, from widest to narrowest. HR always sees
+// every field.
+const (
+	CustomFieldVisibleEveryone = "everyone"
+	CustomFieldVisibleManager  = "hr_self_manager"
+	CustomFieldVisibleSelf     = "hr_self"
+	CustomFieldVisibleHR       = "hr_only"
+)
+
+const employeeEntityType = "employee"
+
+var (
+	validCustomFieldTypes      = []string{CustomFieldText, CustomFieldNumber, CustomFieldDate, CustomFieldEnum, CustomFieldBoolean}
+	validCustomFieldVisibility = []string{CustomFieldVisibleEveryone, CustomFieldVisibleManager, CustomFieldVisibleSelf, CustomFieldVisibleHR}
+	customFieldKeyPattern      = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+)
+
+type ICustomFieldService interface {
+	ListCustomFields(ctx context.Context, tenantID int) ([]*dto.CustomFieldResponse, error)
+	CreateCustomField(ctx context.Context, tenantID int, req *dto.CustomFieldRequest) (*dto.CustomFieldResponse, error)
+	UpdateCustomField(ctx context.Context, tenantID int, id int, req *dto.CustomFieldRequest) (*dto.CustomFieldResponse, error)
+	DeleteCustomField(ctx context.Context, tenantID int, id int) error
+}
+
+type CustomFieldService struct {
+	customFieldRepo *repositories.CustomFieldRepository
+}
+
+func NewCustomFieldService(customFieldRepo *repositories.CustomFieldRepository) *CustomFieldService {
+	return &CustomFieldService{
+		customFieldRepo: customFieldRepo,
+	}
+}
+
+func (cs *CustomFieldService) ListCustomFields(ctx context.Context, tenantID int) ([]*dto.CustomFieldResponse, error) {
+	fields, err := cs.customFieldRepo.ListCustomFields(ctx, tenantID, employeeEntityType)
+	if err != nil {
+		return nil, fmt.Errorf("error listing custom fields: %w", err)
+	}
+
+	responses := make([]*dto.CustomFieldResponse, len(fields))
+	for i := range fields {
+		responses[i] = fields[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (cs *CustomFieldService) CreateCustomField(ctx context.Context, tenantID int, req *dto.CustomFieldRequest) (*dto.CustomFieldResponse, error) {
+	req.Key = strings.TrimSpace(req.Key)
+	if !customFieldKeyPattern.MatchString(req.Key) {
+		return nil, &utils.ValidationError{Field: "key", Message: "Key must start with a lowercase letter and contain only lowercase letters, digits and underscores"}
+	}
+	if !containsString(validCustomFieldTypes, req.FieldType) {
+		return nil, &utils.ValidationError{Field: "field_type", Message: "Field type must be one of " + strings.Join(validCustomFieldTypes, ", ")}
+	}
+	if err := validateCustomFieldDefinition(req); err != nil {
+		return nil, err
+	}
+
+	fields, err := cs.customFieldRepo.ListCustomFields(ctx, tenantID, employeeEntityType)
+	if err != nil {
+		return nil, fmt.Errorf("error listing custom fields: %w", err)
+	}
+	for _, field := range fields {
+		if field.Key == req.Key {
+			return nil, &utils.ValidationError{Field: "key", Message: "A custom field with this key already exists"}
+		}
+	}
+
+	field := &models.CustomFieldDefinition{
+		TenantID:   tenantID,
+		EntityType: employeeEntityType,
+		Key:        req.Key,
+		FieldType:  req.FieldType,
+	}
+	applyCustomFieldRequest(field, req)
+
+	created, err := cs.customFieldRepo.CreateCustomField(ctx, field)
+	if err != nil {
+		return nil, fmt.Errorf("error creating custom field: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+func (cs *CustomFieldService) UpdateCustomField(ctx context.Context, tenantID int, id int, req *dto.CustomFieldRequest) (*dto.CustomFieldResponse, error) {
+	field, err := cs.customFieldRepo.GetCustomFieldByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "custom field")
+	}
+
+	// The key and type identify stored values and cannot change
+	if req.Key != "" && req.Key != field.Key {
+		return nil, &utils.ValidationError{Field: "key", Message: "Key cannot be changed"}
+	}
+	if req.FieldType != "" && req.FieldType != field.FieldType {
+		return nil, &utils.ValidationError{Field: "field_type", Message: "Field type cannot be changed"}
+	}
+	req.FieldType = field.FieldType
+	if err := validateCustomFieldDefinition(req); err != nil {
+		return nil, err
+	}
+
+	applyCustomFieldRequest(field, req)
+
+	updated, err := cs.customFieldRepo.UpdateCustomField(ctx, field)
+	if err != nil {
+		return nil, fmt.Errorf("error updating custom field: %w", err)
+	}
+	return updated.ToResponse(), nil
+}
+
+func (cs *CustomFieldService) DeleteCustomField(ctx context.Context, tenantID int, id int) error {
+	if err := cs.customFieldRepo.DeleteCustomField(ctx, tenantID, id); err != nil {
+		return notFoundOr(err, "custom field")
+	}
+	return nil
+}
+
+// employeeFields returns the tenant's employee custom field definitions
+func (cs *CustomFieldService) employeeFields(ctx context.Context, tenantID int) ([]models.CustomFieldDefinition, error) {
+	fields, err := cs.customFieldRepo.ListCustomFields(ctx, tenantID, employeeEntityType)
+	if err != nil {
+		return nil, fmt.Errorf("error loading custom fields: %w", err)
+	}
+	return fields, nil
+}
+
+func applyCustomFieldRequest(field *models.CustomFieldDefinition, req *dto.CustomFieldRequest) {
+	field.Label = strings.TrimSpace(req.Label)
+	field.Required = req.Required
+	field.Rules = req.Rules
+	field.Visibility = req.Visibility
+	field.SortOrder = req.SortOrder
+	field.Options = nil
+	if req.FieldType == CustomFieldEnum {
+		field.Options = req.Options
+	}
+	if field.Visibility == "" {
+		field.Visibility = CustomFieldVisibleEveryone
+	}
+}
+
+// validateCustomFieldDefinition checks the label, visibility, options and
+// rules of a definition against its field type.
+func validateCustomFieldDefinition(req *dto.CustomFieldRequest) error {
+	if strings.TrimSpace(req.Label) == "" {
+		return &utils.ValidationError{Field: "label", Message: "Label is required"}
+	}
+	if req.Visibility != "" && !containsString(validCustomFieldVisibility, req.Visibility) {
+		return &utils.ValidationError{Field: "visibility", Message: "Visibility must be one of " + strings.Join(validCustomFieldVisibility, ", ")}
+	}
+
+	rules := req.Rules
+	switch req.FieldType {
+	case CustomFieldText:
+		if (rules.MinLength != nil && *rules.MinLength < 0) || (rules.MaxLength != nil && *rules.MaxLength < 1) {
+			return &utils.ValidationError{Field: "rules", Message: "Length limits must be positive"}
+		}
+		if rules.MinLength != nil && rules.MaxLength != nil && *rules.MinLength > *rules.MaxLength {
+			return &utils.ValidationError{Field: "rules", Message: "Minimum length cannot exceed maximum length"}
+		}
+		if rules.Pattern != "" {
+			if _, err := regexp.Compile(rules.Pattern); err != nil {
+				return &utils.ValidationError{Field: "rules", Message: "Pattern is not a valid regular expression"}
+			}
+		}
+	case CustomFieldNumber:
+		if rules.Min != nil && rules.Max != nil && *rules.Min > *rules.Max {
+			return &utils.ValidationError{Field: "rules", Message: "Minimum cannot exceed maximum"}
+		}
+	case CustomFieldDate:
+		minDate, err := parseOptionalDate(rules.MinDate)
+		if err != nil {
+			return &utils.ValidationError{Field: "rules", Message: "Minimum date must be in YYYY-MM-DD format"}
+		}
+		maxDate, err := parseOptionalDate(rules.MaxDate)
+		if err != nil {
+			return &utils.ValidationError{Field: "rules", Message: "Maximum date must be in YYYY-MM-DD format"}
+		}
+		if minDate != nil && maxDate != nil && minDate.After(*maxDate) {
+			return &utils.ValidationError{Field: "rules", Message: "Minimum date cannot be after maximum date"}
+		}
+	case CustomFieldEnum:
+		if len(req.Options) == 0 {
+			return &utils.ValidationError{Field: "options", Message: "Enum fields need at least one option"}
+		}
+		seen := map[string]bool{}
+		for _, option := range req.Options {
+			if strings.TrimSpace(option) == "" || seen[option] {
+				return &utils.ValidationError{Field: "options", Message: "Options must be non-empty and unique"}
+			}
+			seen[option] = true
+		}
+	}
+
+	return nil
+}
+
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// validateCustomFieldValue checks a decoded JSON value against the field's
+// type and rules and returns the value to store.
+func validateCustomFieldValue(field *models.CustomFieldDefinition, value interface{}) (interface{}, error) {
+	name := "custom_fields." + field.Key
+	invalid := func(message string) error {
+		return &utils.ValidationError{Field: name, Message: field.Label + " " + message}
+	}
+
+	rules := field.Rules
+	switch field.FieldType {
+	case CustomFieldText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be text")
+		}
+		text = strings.TrimSpace(text)
+		length := utf8.RuneCountInString(text)
+		if rules.MinLength != nil && length < *rules.MinLength {
+			return nil, invalid(fmt.Sprintf("must be at least %d characters", *rules.MinLength))
+		}
+		if rules.MaxLength != nil && length > *rules.MaxLength {
+			return nil, invalid(fmt.Sprintf("must be at most %d characters", *rules.MaxLength))
+		}
+		if rules.Pattern != "" {
+			pattern, err := regexp.Compile(rules.Pattern)
+			if err == nil && !pattern.MatchString(text) {
+				return nil, invalid("is not in the expected format")
+			}
+		}
+		return text, nil
+
+	case CustomFieldNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, invalid("must be a number")
+		}
+		if rules.Min != nil && number < *rules.Min {
+			return nil, invalid(fmt.Sprintf("must be at least %s", formatCustomFieldNumber(*rules.Min)))
+		}
+		if rules.Max != nil && number > *rules.Max {
+			return nil, invalid(fmt.Sprintf("must be at most %s", formatCustomFieldNumber(*rules.Max)))
+		}
+		return number, nil
+
+	case CustomFieldDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be a date in YYYY-MM-DD format")
+		}
+		date, err := time.Parse("2006-01-02", text)
+		if err != nil {
+			return nil, invalid("must be a date in YYYY-MM-DD format")
+		}
+		if minDate, _ := parseOptionalDate(rules.MinDate); minDate != nil && date.Before(*minDate) {
+			return nil, invalid("must be on or after " + rules.MinDate)
+		}
+		if maxDate, _ := parseOptionalDate(rules.MaxDate); maxDate != nil && date.After(*maxDate) {
+			return nil, invalid("must be on or before " + rules.MaxDate)
+		}
+		return text, nil
+
+	case CustomFieldEnum:
+		text, ok := value.(string)
+		if !ok || !containsString(field.Options, text) {
+			return nil, invalid("must be one of " + strings.Join(field.Options, ", "))
+		}
+		return text, nil
+
+	case CustomFieldBoolean:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, invalid("must be true or false")
+		}
+		return flag, nil
+	}
+
+	return nil, invalid("has an unsupported type")
+}
+
+func formatCustomFieldNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatCustomFieldValue renders a stored value as text for exports
+func formatCustomFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return formatCustomFieldNumber(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// mergeCustomFieldValues applies input on top of existing values. A nil
+// value clears the field. When creating, every required field must be set.
+func mergeCustomFieldValues(fields []models.CustomFieldDefinition, existing, input map[string]interface{}, creating bool) (map[string]interface{}, error) {
+	byKey := make(map[string]*models.CustomFieldDefinition, len(fields))
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	merged := make(map[string]interface{}, len(existing)+len(input))
+	for key, value := range existing {
+		merged[key] = value
+	}
+
+	for key, value := range input {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, &utils.ValidationError{Field: "custom_fields." + key, Message: "Unknown custom field " + key}
+		}
+		if value == nil {
+			if field.Required {
+				return nil, &utils.ValidationError{Field: "custom_fields." + key, Message: field.Label + " is required"}
+			}
+			delete(merged, key)
+			continue
+		}
+		stored, err := validateCustomFieldValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = stored
+	}
+
+	if creating {
+		for i := range fields {
+			if _, ok := merged[fields[i].Key]; fields[i].Required && !ok {
+				return nil, &utils.ValidationError{Field: "custom_fields." + fields[i].Key, Message: fields[i].Label + " is required"}
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// customFieldVisible reports whether actor may see field's value on employee
+func customFieldVisible(field *models.CustomFieldDefinition, actor Actor, employee *models.Employee) bool {
+	if actor.IsHR() {
+		return true
+	}
+	isSelf := actor.EmployeeID == employee.ID
+	isManager := employee.ManagerID != nil && *employee.ManagerID == actor.EmployeeID
+
+	switch field.Visibility {
+	case CustomFieldVisibleEveryone, "":
+		return true
+	case CustomFieldVisibleManager:
+		return isSelf || isManager
+	case CustomFieldVisibleSelf:
+		return isSelf
+	default:
+		return false
+	}
+}
+
+// visibleCustomFields returns the employee's values for the defined fields the
+// actor may see, dropping values left over from fields that no longer exist.
+func visibleCustomFields(fields []models.CustomFieldDefinition, actor Actor, employee *models.Employee) map[string]interface{} {
+	visible := map[string]interface{}{}
+	for i := range fields {
+		value, ok := employee.CustomFields[fields[i].Key]
+		if ok && customFieldVisible(&fields[i], actor, employee) {
+			visible[fields[i].Key] = value
+		}
+	}
+	return visible
+}
+
+// normalizeCustomFieldFilter validates listing filters on custom fields and
+// rewrites each value into the text form stored in JSONB, so that the
+// repository can compare with ->>. Unless allowRestricted is set, only fields
+// visible to everyone may be filtered on.
+func normalizeCustomFieldFilter(fields []models.CustomFieldDefinition, filter map[string]string, allowRestricted bool) (map[string]string, error) {
+	if len(filter) == 0 {
+		return nil, nil
+	}
+
+	byKey := make(map[string]*models.CustomFieldDefinition, len(fields))
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	normalized := make(map[string]string, len(filter))
+	for key, value := range filter {
+		field, ok := byKey[key]
+		if !ok || (!allowRestricted && field.Visibility != CustomFieldVisibleEveryone) {
+			return nil, &utils.ValidationError{Field: "cf." + key, Message: "Unknown custom field " + key}
+		}
+
+		switch field.FieldType {
+		case CustomFieldNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, &utils.ValidationError{Field: "cf." + key, Message: field.Label + " filter must be a number"}
+			}
+			value = formatCustomFieldNumber(number)
+		case CustomFieldBoolean:
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, &utils.ValidationError{Field: "cf." + key, Message: field.Label + " filter must be true or false"}
+			}
+			value = strconv.FormatBool(flag)
+		case CustomFieldDate:
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				return nil, &utils.ValidationError{Field: "cf." + key, Message: field.Label + " filter must be a date in YYYY-MM-DD format"}
+			}
+		}
+		normalized[key] = value
+	}
+
+	return normalized, nil
+}
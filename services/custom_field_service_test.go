@@ -0,0 +1,181 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+func customFieldFixtures() []models.CustomFieldDefinition {
+	maxLength := 3
+	minSize, maxSize := 1.0, 10.0
+	return []models.CustomFieldDefinition{
+		{Key: "shirt_size", Label: "T-shirt size", FieldType: CustomFieldEnum, Options: []string{"S", "M", "L"}, Visibility: CustomFieldVisibleEveryone},
+		{Key: "cost_centre", Label: "Cost centre", FieldType: CustomFieldText, Required: true, Rules: dto.CustomFieldRules{MaxLength: &maxLength}, Visibility: CustomFieldVisibleManager},
+		{Key: "union_member", Label: "Union member", FieldType: CustomFieldBoolean, Visibility: CustomFieldVisibleSelf},
+		{Key: "grade", Label: "Grade", FieldType: CustomFieldNumber, Rules: dto.CustomFieldRules{Min: &minSize, Max: &maxSize}, Visibility: CustomFieldVisibleHR},
+		{Key: "review_date", Label: "Review date", FieldType: CustomFieldDate, Rules: dto.CustomFieldRules{MinDate: "2020-01-01"}, Visibility: CustomFieldVisibleEveryone},
+	}
+}
+
+func TestValidateCustomFieldValue(t *testing.T) {
+	fields := customFieldFixtures()
+
+	cases := []struct {
+		name    string
+		field   *models.CustomFieldDefinition
+		value   interface{}
+		wantErr bool
+	}{
+		{"enum option", &fields[0], "M", false},
+		{"enum outside options", &fields[0], "XXL", true},
+		{"text within max length", &fields[1], "C01", false},
+		{"text too long", &fields[1], "C0001", true},
+		{"text given a number", &fields[1], 12.0, true},
+		{"boolean", &fields[2], true, false},
+		{"boolean given text", &fields[2], "yes", true},
+		{"number in range", &fields[3], 4.0, false},
+		{"number above max", &fields[3], 11.0, true},
+		{"valid date", &fields[4], "2024-06-30", false},
+		{"date before min", &fields[4], "2019-12-31", true},
+		{"malformed date", &fields[4], "30/06/2024", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := validateCustomFieldValue(tc.field, tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeCustomFieldValues(t *testing.T) {
+	fields := customFieldFixtures()
+
+	t.Run("requires required fields on create", func(t *testing.T) {
+		if _, err := mergeCustomFieldValues(fields, nil, map[string]interface{}{"shirt_size": "S"}, true); err == nil {
+			t.Errorf("got nil error, want cost_centre required")
+		}
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		if _, err := mergeCustomFieldValues(fields, nil, map[string]interface{}{"unknown": "x"}, false); err == nil {
+			t.Errorf("got nil error, want unknown field error")
+		}
+	})
+
+	t.Run("null clears optional fields and keeps the rest", func(t *testing.T) {
+		existing := map[string]interface{}{"shirt_size": "S", "cost_centre": "C01"}
+		merged, err := mergeCustomFieldValues(fields, existing, map[string]interface{}{"shirt_size": nil, "union_member": true}, false)
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+		if _, ok := merged["shirt_size"]; ok {
+			t.Errorf("got shirt_size %v, want cleared", merged["shirt_size"])
+		}
+		if merged["cost_centre"] != "C01" || merged["union_member"] != true {
+			t.Errorf("got %v, want cost_centre kept and union_member set", merged)
+		}
+	})
+
+	t.Run("cannot clear a required field", func(t *testing.T) {
+		existing := map[string]interface{}{"cost_centre": "C01"}
+		if _, err := mergeCustomFieldValues(fields, existing, map[string]interface{}{"cost_centre": nil}, false); err == nil {
+			t.Errorf("got nil error, want cost_centre required")
+		}
+	})
+}
+
+func TestVisibleCustomFields(t *testing.T) {
+	fields := customFieldFixtures()
+	managerID := 10
+	employee := &models.Employee{
+		ID:        5,
+		ManagerID: &managerID,
+		CustomFields: map[string]interface{}{
+			"shirt_size":   "M",
+			"cost_centre":  "C01",
+			"union_member": true,
+			"grade":        4.0,
+			"retired_key":  "left over",
+		},
+	}
+
+	cases := []struct {
+		name  string
+		actor Actor
+		want  []string
+	}{
+		{"HR sees every defined field", Actor{EmployeeID: 1, Role: "HR"}, []string{"shirt_size", "cost_centre", "union_member", "grade"}},
+		{"employee sees their own fields", Actor{EmployeeID: 5, Role: "Employee"}, []string{"shirt_size", "cost_centre", "union_member"}},
+		{"manager sees manager fields", Actor{EmployeeID: 10, Role: "Employee"}, []string{"shirt_size", "cost_centre"}},
+		{"colleague sees public fields", Actor{EmployeeID: 11, Role: "Employee"}, []string{"shirt_size"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			visible := visibleCustomFields(fields, tc.actor, employee)
+			if len(visible) != len(tc.want) {
+				t.Fatalf("got %v, want keys %v", visible, tc.want)
+			}
+			for _, key := range tc.want {
+				if _, ok := visible[key]; !ok {
+					t.Errorf("got %v, want key %s", visible, key)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeCustomFieldFilter(t *testing.T) {
+	fields := customFieldFixtures()
+
+	t.Run("normalizes numbers and booleans", func(t *testing.T) {
+		filter, err := normalizeCustomFieldFilter(fields, map[string]string{"grade": "4.0", "union_member": "TRUE"}, true)
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+		if filter["grade"] != "4" || filter["union_member"] != "true" {
+			t.Errorf("got %v, want grade 4 and union_member true", filter)
+		}
+	})
+
+	t.Run("rejects restricted fields without permission", func(t *testing.T) {
+		if _, err := normalizeCustomFieldFilter(fields, map[string]string{"grade": "4"}, false); err == nil {
+			t.Errorf("got nil error, want restricted field rejected")
+		}
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		if _, err := normalizeCustomFieldFilter(fields, map[string]string{"grade": "high"}, true); err == nil {
+			t.Errorf("got nil error, want invalid number rejected")
+		}
+	})
+}
+
+func TestValidateCustomFieldDefinition(t *testing.T) {
+	cases := []struct {
+		name    string
+		req     dto.CustomFieldRequest
+		wantErr bool
+	}{
+		{"enum with options", dto.CustomFieldRequest{Label: "Size", FieldType: CustomFieldEnum, Options: []string{"S", "M"}}, false},
+		{"enum without options", dto.CustomFieldRequest{Label: "Size", FieldType: CustomFieldEnum}, true},
+		{"duplicate options", dto.CustomFieldRequest{Label: "Size", FieldType: CustomFieldEnum, Options: []string{"S", "S"}}, true},
+		{"invalid pattern", dto.CustomFieldRequest{Label: "Code", FieldType: CustomFieldText, Rules: dto.CustomFieldRules{Pattern: "("}}, true},
+		{"unknown visibility", dto.CustomFieldRequest{Label: "Code", FieldType: CustomFieldText, Visibility: "public"}, true},
+		{"missing label", dto.CustomFieldRequest{FieldType: CustomFieldBoolean}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCustomFieldDefinition(&tc.req)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Disciplinary case stages, in the order a case moves through them
+const (
+	CaseStageQueryIssued      = "query_issued"
+	CaseStageResponseReceived = "response_received"
+	CaseStageHearingScheduled = "hearing_scheduled"
+	CaseStageOutcome          = "outcome"
+)
+
+// Disciplinary case statuses
+const (
+	CaseStatusOpen   = "open"
+	CaseStatusClosed = "closed"
+)
+
+var (
+	caseStages        = []string{CaseStageQueryIssued, CaseStageResponseReceived, CaseStageHearingScheduled, CaseStageOutcome}
+	validCaseStatuses = []string{CaseStatusOpen, CaseStatusClosed}
+	validCaseOutcomes = []string{"no_action", "warning", "final_warning", "suspension", "termination"}
+)
+
+type IDisciplinaryService interface {
+	ListCases(ctx context.Context, tenantID int, filter *dto.DisciplinaryCaseFilter) ([]*dto.DisciplinaryCaseResponse, error)
+	GetCase(ctx context.Context, tenantID int, id int) (*dto.DisciplinaryCaseResponse, error)
+	OpenCase(ctx context.Context, actor Actor, req *dto.OpenDisciplinaryCaseRequest) (*dto.DisciplinaryCaseResponse, error)
+	RecordStage(ctx context.Context, actor Actor, id int, req *dto.DisciplinaryStageRequest) (*dto.DisciplinaryCaseResponse, error)
+	LinkMemo(ctx context.Context, tenantID int, id int, req *dto.LinkCaseMemoRequest) (*dto.DisciplinaryCaseResponse, error)
+	GetDisciplinarySummary(ctx context.Context, tenantID int, employeeID int) (*dto.DisciplinarySummaryResponse, error)
+}
+
+type DisciplinaryService struct {
+	disciplinaryRepo *repositories.DisciplinaryRepository
+	memoRepo         *repositories.MemoRepository
+	employeeRepo     *repositories.EmployeeRepository
+}
+
+func NewDisciplinaryService(disciplinaryRepo *repositories.DisciplinaryRepository, memoRepo *repositories.MemoRepository, employeeRepo *repositories.EmployeeRepository) *DisciplinaryService {
+	return &DisciplinaryService{
+		disciplinaryRepo: disciplinaryRepo,
+		memoRepo:         memoRepo,
+		employeeRepo:     employeeRepo,
+	}
+}
+
+func caseStageIndex(stage string) int {
+	for i, s := range caseStages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyCaseStage moves a case on to the requested stage. Cases only move
+// forward, though stages may be skipped, and a hearing may be rescheduled
+// while the case waits for it. Recording the outcome closes the case.
+func applyCaseStage(c *models.DisciplinaryCase, req *dto.DisciplinaryStageRequest, now time.Time) error {
+	if c.Status == CaseStatusClosed {
+		return &utils.ValidationError{Field: "stage", Message: "Case is closed"}
+	}
+
+	next := caseStageIndex(req.Stage)
+	if next < 0 {
+		return &utils.ValidationError{Field: "stage", Message: "Stage must be one of " + strings.Join(caseStages, ", ")}
+	}
+	current := caseStageIndex(c.Stage)
+	if next < current || (next == current && req.Stage != CaseStageHearingScheduled) {
+		return &utils.ValidationError{Field: "stage", Message: "Case is already at " + c.Stage}
+	}
+
+	switch req.Stage {
+	case CaseStageHearingScheduled:
+		if req.HearingAt == "" {
+			return &utils.ValidationError{Field: "hearing_at", Message: "Hearing time is required"}
+		}
+		hearingAt, err := time.Parse(time.RFC3339, req.HearingAt)
+		if err != nil {
+			return &utils.ValidationError{Field: "hearing_at", Message: "Hearing time must be in RFC 3339 format"}
+		}
+		if !hearingAt.After(now) {
+			return &utils.ValidationError{Field: "hearing_at", Message: "Hearing time must be in the future"}
+		}
+		hearingAt = hearingAt.UTC()
+		c.HearingAt = &hearingAt
+		c.HearingLocation = strings.TrimSpace(req.HearingLocation)
+	case CaseStageOutcome:
+		if !containsString(validCaseOutcomes, req.Outcome) {
+			return &utils.ValidationError{Field: "outcome", Message: "Outcome must be one of " + strings.Join(validCaseOutcomes, ", ")}
+		}
+		c.Outcome = req.Outcome
+		c.OutcomeNotes = strings.TrimSpace(req.OutcomeNotes)
+		c.Status = CaseStatusClosed
+	}
+
+	c.Stage = req.Stage
+	return nil
+}
+
+// activeWarnings splits issued warnings into those still in force and a
+// count of those that have lapsed
+func activeWarnings(memos []models.Memo, now time.Time) ([]models.Memo, int) {
+	active := []models.Memo{}
+	lapsed := 0
+	for i := range memos {
+		if memos[i].ActiveWarning(now) {
+			active = append(active, memos[i])
+		} else if memos[i].Category == MemoCategoryWarning && memos[i].Status == MemoStatusApproved {
+			lapsed++
+		}
+	}
+	return active, lapsed
+}
+
+func caseResponses(cases []models.DisciplinaryCase) []*dto.DisciplinaryCaseResponse {
+	responses := make([]*dto.DisciplinaryCaseResponse, len(cases))
+	for i := range cases {
+		responses[i] = cases[i].ToResponse()
+	}
+	return responses
+}
+
+func (ds *DisciplinaryService) ListCases(ctx context.Context, tenantID int, filter *dto.DisciplinaryCaseFilter) ([]*dto.DisciplinaryCaseResponse, error) {
+	if filter.Status != "" && !containsString(validCaseStatuses, filter.Status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validCaseStatuses, ", ")}
+	}
+	if filter.Stage != "" && caseStageIndex(filter.Stage) < 0 {
+		return nil, &utils.ValidationError{Field: "stage", Message: "Stage must be one of " + strings.Join(caseStages, ", ")}
+	}
+
+	cases, err := ds.disciplinaryRepo.ListCases(ctx, tenantID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error listing disciplinary cases: %w", err)
+	}
+	return caseResponses(cases), nil
+}
+
+// caseResponse returns the case with its events and linked memos
+func (ds *DisciplinaryService) caseResponse(ctx context.Context, c *models.DisciplinaryCase) (*dto.DisciplinaryCaseResponse, error) {
+	memos, err := ds.memoRepo.ListMemos(ctx, c.TenantID, 0, &dto.MemoFilter{CaseID: c.ID})
+	if err != nil {
+		return nil, fmt.Errorf("error listing case memos: %w", err)
+	}
+	response := c.ToResponse()
+	response.Memos = memoResponses(memos)
+	return response, nil
+}
+
+func (ds *DisciplinaryService) GetCase(ctx context.Context, tenantID int, id int) (*dto.DisciplinaryCaseResponse, error) {
+	c, err := ds.disciplinaryRepo.GetCase(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "disciplinary case")
+	}
+	return ds.caseResponse(ctx, c)
+}
+
+// OpenCase opens a case at the query stage and links the memos already
+// written about it, such as the query itself
+func (ds *DisciplinaryService) OpenCase(ctx context.Context, actor Actor, req *dto.OpenDisciplinaryCaseRequest) (*dto.DisciplinaryCaseResponse, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, &utils.ValidationError{Field: "title", Message: "Title is required"}
+	}
+	if len(title) > 255 {
+		return nil, &utils.ValidationError{Field: "title", Message: "Title must be at most 255 characters"}
+	}
+
+	employee, err := ds.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, req.EmployeeID)
+	if err != nil || employee.Status == "terminated" {
+		return nil, &utils.ValidationError{Field: "employee_id", Message: "Employee not found"}
+	}
+
+	openedBy := actor.EmployeeID
+	created, err := ds.disciplinaryRepo.CreateCase(ctx, &models.DisciplinaryCase{
+		TenantID:    actor.TenantID,
+		EmployeeID:  employee.ID,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+		Stage:       CaseStageQueryIssued,
+		Status:      CaseStatusOpen,
+		OpenedBy:    &openedBy,
+	}, req.MemoIDs, "")
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "memo_ids", Message: "Memos must be about the employee and not part of another case"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening disciplinary case: %w", err)
+	}
+	return ds.caseResponse(ctx, created)
+}
+
+func (ds *DisciplinaryService) RecordStage(ctx context.Context, actor Actor, id int, req *dto.DisciplinaryStageRequest) (*dto.DisciplinaryCaseResponse, error) {
+	c, err := ds.disciplinaryRepo.GetCase(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "disciplinary case")
+	}
+
+	fromStage := c.Stage
+	if err := applyCaseStage(c, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	updated, err := ds.disciplinaryRepo.RecordStage(ctx, c, fromStage, strings.TrimSpace(req.Note), actor.EmployeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error recording case stage: %w", err)
+	}
+	return ds.caseResponse(ctx, updated)
+}
+
+func (ds *DisciplinaryService) LinkMemo(ctx context.Context, tenantID int, id int, req *dto.LinkCaseMemoRequest) (*dto.DisciplinaryCaseResponse, error) {
+	c, err := ds.disciplinaryRepo.GetCase(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "disciplinary case")
+	}
+
+	err = ds.disciplinaryRepo.LinkMemo(ctx, tenantID, c.ID, req.MemoID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "memo_id", Message: "Memo must be about the employee and not part of another case"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error linking memo: %w", err)
+	}
+	return ds.caseResponse(ctx, c)
+}
+
+// GetDisciplinarySummary returns what HR needs before deciding on a
+// termination: the employee's warnings still in force, how many have lapsed,
+// and their open cases
+func (ds *DisciplinaryService) GetDisciplinarySummary(ctx context.Context, tenantID int, employeeID int) (*dto.DisciplinarySummaryResponse, error) {
+	employee, err := ds.employeeRepo.GetEmployeeByID(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+
+	warnings, err := ds.memoRepo.ListMemos(ctx, tenantID, 0, &dto.MemoFilter{
+		EmployeeID: employee.ID,
+		Category:   MemoCategoryWarning,
+		Status:     MemoStatusApproved,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing warnings: %w", err)
+	}
+	active, lapsed := activeWarnings(warnings, time.Now())
+
+	openCases, err := ds.disciplinaryRepo.ListCases(ctx, tenantID, &dto.DisciplinaryCaseFilter{
+		EmployeeID: employee.ID,
+		Status:     CaseStatusOpen,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing disciplinary cases: %w", err)
+	}
+
+	return &dto.DisciplinarySummaryResponse{
+		EmployeeID:     employee.ID,
+		EmployeeName:   employee.FirstName + " " + employee.LastName,
+		ActiveWarnings: memoResponses(active),
+		LapsedWarnings: lapsed,
+		OpenCases:      caseResponses(openCases),
+	}, nil
+}
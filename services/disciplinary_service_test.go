@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestApplyCaseStage(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("skips ahead to a hearing", func(t *testing.T) {
+		c := &models.DisciplinaryCase{Stage: CaseStageQueryIssued, Status: CaseStatusOpen}
+		err := applyCaseStage(c, &dto.DisciplinaryStageRequest{
+			Stage:           CaseStageHearingScheduled,
+			HearingAt:       "2026-03-05T10:00:00+01:00",
+			HearingLocation: " Room 2 ",
+		}, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Stage != CaseStageHearingScheduled || c.HearingAt == nil || !c.HearingAt.Equal(time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)) || c.HearingLocation != "Room 2" {
+			t.Errorf("got %+v, want a hearing on 5 March at 09:00 UTC in Room 2", c)
+		}
+	})
+
+	t.Run("reschedules a hearing", func(t *testing.T) {
+		c := &models.DisciplinaryCase{Stage: CaseStageHearingScheduled, Status: CaseStatusOpen}
+		if err := applyCaseStage(c, &dto.DisciplinaryStageRequest{Stage: CaseStageHearingScheduled, HearingAt: "2026-03-09T10:00:00Z"}, now); err != nil {
+			t.Errorf("got %v, want the hearing rescheduled", err)
+		}
+	})
+
+	t.Run("closes the case with the outcome", func(t *testing.T) {
+		c := &models.DisciplinaryCase{Stage: CaseStageHearingScheduled, Status: CaseStatusOpen}
+		if err := applyCaseStage(c, &dto.DisciplinaryStageRequest{Stage: CaseStageOutcome, Outcome: "final_warning"}, now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Status != CaseStatusClosed || c.Outcome != "final_warning" {
+			t.Errorf("got %+v, want a closed case with a final warning", c)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		c     models.DisciplinaryCase
+		req   dto.DisciplinaryStageRequest
+		field string
+	}{
+		{"closed case", models.DisciplinaryCase{Stage: CaseStageOutcome, Status: CaseStatusClosed}, dto.DisciplinaryStageRequest{Stage: CaseStageOutcome, Outcome: "no_action"}, "stage"},
+		{"unknown stage", models.DisciplinaryCase{Stage: CaseStageQueryIssued, Status: CaseStatusOpen}, dto.DisciplinaryStageRequest{Stage: "appeal"}, "stage"},
+		{"moving back", models.DisciplinaryCase{Stage: CaseStageHearingScheduled, Status: CaseStatusOpen}, dto.DisciplinaryStageRequest{Stage: CaseStageResponseReceived}, "stage"},
+		{"repeating a stage", models.DisciplinaryCase{Stage: CaseStageResponseReceived, Status: CaseStatusOpen}, dto.DisciplinaryStageRequest{Stage: CaseStageResponseReceived}, "stage"},
+		{"hearing without a time", models.DisciplinaryCase{Stage: CaseStageQueryIssued, Status: CaseStatusOpen}, dto.DisciplinaryStageRequest{Stage: CaseStageHearingScheduled}, "hearing_at"},
+		{"hearing in the past", models.DisciplinaryCase{Stage: CaseStageQueryIssued, Status: CaseStatusOpen}, dto.DisciplinaryStageRequest{Stage: CaseStageHearingScheduled, HearingAt: "2026-02-01T10:00:00Z"}, "hearing_at"},
+		{"unknown outcome", models.DisciplinaryCase{Stage: CaseStageQueryIssued, Status: CaseStatusOpen}, dto.DisciplinaryStageRequest{Stage: CaseStageOutcome, Outcome: "fine"}, "outcome"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyCaseStage(&tt.c, &tt.req, now)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestActiveWarnings(t *testing.T) {
+	now := date("2026-06-01")
+	lapsed := date("2026-05-01")
+	later := date("2026-12-01")
+
+	memos := []models.Memo{
+		{ID: 1, Category: MemoCategoryWarning, Status: MemoStatusApproved, ExpiresAt: &lapsed},
+		{ID: 2, Category: MemoCategoryWarning, Status: MemoStatusApproved, ExpiresAt: &later},
+		{ID: 3, Category: MemoCategoryWarning, Status: MemoStatusApproved},
+		{ID: 4, Category: MemoCategoryWarning, Status: MemoStatusCancelled},
+		{ID: 5, Category: MemoCategoryQuery, Status: MemoStatusApproved},
+	}
+
+	active, lapsedCount := activeWarnings(memos, now)
+	if len(active) != 2 || active[0].ID != 2 || active[1].ID != 3 {
+		t.Errorf("got active %+v, want the warning lapsing in December and the one that never lapses", active)
+	}
+	if lapsedCount != 1 {
+		t.Errorf("got %d lapsed, want 1", lapsedCount)
+	}
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// maxDocumentExpiryWindow caps the window of the expiring documents report
+const maxDocumentExpiryWindow = 3650
+
+// ListExpiringDocuments returns the tenant's documents that have expired or
+// expire within withinDays, or within the reminder window of their type when
+// withinDays is zero
+func (ds *DocumentService) ListExpiringDocuments(ctx context.Context, actor Actor, withinDays int) ([]*dto.DocumentResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+	if withinDays < 0 || withinDays > maxDocumentExpiryWindow {
+		return nil, &utils.ValidationError{Field: "within_days", Message: fmt.Sprintf("Within days must be between 0 and %d", maxDocumentExpiryWindow)}
+	}
+
+	date := today(time.Now())
+	documents, err := ds.documentRepo.ListExpiringDocuments(ctx, actor.TenantID, date, withinDays)
+	if err != nil {
+		return nil, fmt.Errorf("error listing expiring documents: %w", err)
+	}
+	return documentResponses(documents, date), nil
+}
+
+// expiryReminderLine describes a document in a reminder
+func expiryReminderLine(document *models.Document, date time.Time) string {
+	when := "expires on"
+	if document.ExpiryDate.Before(date) {
+		when = "expired on"
+	}
+	return fmt.Sprintf("%s (%s) %s %s", document.DocumentType, document.FileName, when, document.ExpiryDate.Format("2006-01-02"))
+}
+
+// SendExpiryReminders emails the employee of every document entering its
+// expiry reminder window, once per version, and sends the tenant's HR a
+// digest of them. It returns how many documents were reminded and is safe to
+// run from several instances at once.
+func (ds *DocumentService) SendExpiryReminders(ctx context.Context, now time.Time) (int, error) {
+	date := today(now)
+	reminders, err := ds.documentRepo.ClaimExpiryReminders(ctx, date, documentReminderBatch)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming expiring documents: %w", err)
+	}
+
+	sent := 0
+	digests := map[int][]string{}
+	tenants := []int{}
+	for _, reminder := range reminders {
+		document := &reminder.Document
+		line := expiryReminderLine(document, date)
+		if _, ok := digests[document.TenantID]; !ok {
+			tenants = append(tenants, document.TenantID)
+		}
+		digests[document.TenantID] = append(digests[document.TenantID], document.EmployeeName+": "+line)
+
+		subject := "Document expiring: " + document.DocumentType
+		body := fmt.Sprintf("Your document %s.\n\nPlease upload a renewed copy before it lapses.", line)
+		if err := ds.mailer.Send(ctx, reminder.EmployeeEmail, subject, body); err != nil {
+			log.Printf("document %d: error sending expiry reminder: %v", document.ID, err)
+			continue
+		}
+		sent++
+	}
+
+	for _, tenantID := range tenants {
+		emails, err := ds.documentRepo.ListRoleHolderEmails(ctx, tenantID, documentReminderRole)
+		if err != nil {
+			log.Printf("tenant %d: error listing document reminder recipients: %v", tenantID, err)
+			continue
+		}
+		lines := digests[tenantID]
+		subject := fmt.Sprintf("%d employee documents expiring", len(lines))
+		body := "These employee documents have expired or are about to expire:\n\n" + strings.Join(lines, "\n")
+		for _, email := range emails {
+			if err := ds.mailer.Send(ctx, email, subject, body); err != nil {
+				log.Printf("tenant %d: error sending document expiry digest: %v", tenantID, err)
+			}
+		}
+	}
+	return sent, nil
+}
+
+// RunScheduler sends document expiry reminders now and then every interval
+// until ctx is cancelled.
+func (ds *DocumentService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := ds.SendExpiryReminders(ctx, time.Now()); err != nil {
+			log.Printf("document scheduler: %v", err)
+		} else if sent > 0 {
+			log.Printf("document scheduler: sent %d expiry reminders", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (ds *DocumentService) ListDocumentRequirements(ctx context.Context, actor Actor) ([]*dto.DocumentRequirementResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	requirements, err := ds.documentRepo.ListDocumentRequirements(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing document requirements: %w", err)
+	}
+
+	responses := make([]*dto.DocumentRequirementResponse, len(requirements))
+	for i := range requirements {
+		responses[i] = requirements[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// CreateDocumentRequirement makes a document type mandatory for the
+// employees matching a designation and country
+func (ds *DocumentService) CreateDocumentRequirement(ctx context.Context, actor Actor, req *dto.DocumentRequirementRequest) (*dto.DocumentRequirementResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	if _, err := ds.documentRepo.GetDocumentType(ctx, actor.TenantID, req.DocumentTypeID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &utils.ValidationError{Field: "document_type_id", Message: "Document type not found"}
+		}
+		return nil, fmt.Errorf("error loading document type: %w", err)
+	}
+
+	country := ""
+	if strings.TrimSpace(req.Country) != "" {
+		country = utils.NormalizeCountryCode(strings.TrimSpace(req.Country))
+		if country == "" {
+			return nil, &utils.ValidationError{Field: "country", Message: "Country must be an ISO 3166-1 alpha-2 code"}
+		}
+	}
+
+	existing, err := ds.documentRepo.ListDocumentRequirements(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing document requirements: %w", err)
+	}
+	for _, other := range existing {
+		if other.DocumentTypeID == req.DocumentTypeID && other.Country == country && sameOptionalInt(other.DesignationID, req.DesignationID) {
+			return nil, &utils.ValidationError{Field: "document_type_id", Message: "This requirement already exists"}
+		}
+	}
+
+	requirement, err := ds.documentRepo.CreateDocumentRequirement(ctx, &models.DocumentRequirement{
+		TenantID:       actor.TenantID,
+		DocumentTypeID: req.DocumentTypeID,
+		DesignationID:  req.DesignationID,
+		Country:        country,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &utils.ValidationError{Field: "designation_id", Message: "Designation not found"}
+		}
+		return nil, fmt.Errorf("error creating document requirement: %w", err)
+	}
+	return requirement.ToResponse(), nil
+}
+
+func sameOptionalInt(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func (ds *DocumentService) DeleteDocumentRequirement(ctx context.Context, actor Actor, id int) error {
+	if !actor.IsHR() {
+		return ErrForbidden
+	}
+
+	if err := ds.documentRepo.DeleteDocumentRequirement(ctx, actor.TenantID, id); err != nil {
+		return notFoundOr(err, "document requirement")
+	}
+	return nil
+}
+
+// missingDocuments works out which mandatory document types each employee
+// lacks. A requirement applies when its designation and country, if set,
+// match the employee; employees without a home address count as being in
+// companyCountry. A type is held when the employee has a copy that has not
+// expired by today. Employees missing nothing are left out.
+func missingDocuments(subjects []repositories.RequirementSubject, requirements []models.DocumentRequirement, held []repositories.HeldDocument, companyCountry string, today time.Time) []*dto.MissingDocumentsResponse {
+	type holding struct {
+		valid   bool
+		expired bool
+	}
+	holdings := map[[2]int]*holding{}
+	for _, document := range held {
+		key := [2]int{document.EmployeeID, document.DocumentTypeID}
+		h, ok := holdings[key]
+		if !ok {
+			h = &holding{}
+			holdings[key] = h
+		}
+		if document.ExpiryDate == nil || !document.ExpiryDate.Before(today) {
+			h.valid = true
+		} else {
+			h.expired = true
+		}
+	}
+
+	reports := []*dto.MissingDocumentsResponse{}
+	for _, subject := range subjects {
+		country := utils.NormalizeCountryCode(subject.Country)
+		if country == "" {
+			country = companyCountry
+		}
+
+		report := &dto.MissingDocumentsResponse{
+			EmployeeID:    subject.EmployeeID,
+			EmployeeName:  subject.FirstName + " " + subject.LastName,
+			DesignationID: subject.DesignationID,
+			Country:       country,
+			Missing:       []dto.MissingDocument{},
+		}
+		seen := map[int]bool{}
+		for _, requirement := range requirements {
+			if requirement.DesignationID != nil && *requirement.DesignationID != subject.DesignationID {
+				continue
+			}
+			if requirement.Country != "" && requirement.Country != country {
+				continue
+			}
+			if seen[requirement.DocumentTypeID] {
+				continue
+			}
+			seen[requirement.DocumentTypeID] = true
+
+			h := holdings[[2]int{subject.EmployeeID, requirement.DocumentTypeID}]
+			if h != nil && h.valid {
+				continue
+			}
+			reason := "missing"
+			if h != nil && h.expired {
+				reason = DocumentExpired
+			}
+			report.Missing = append(report.Missing, dto.MissingDocument{
+				DocumentTypeID: requirement.DocumentTypeID,
+				DocumentType:   requirement.DocumentType,
+				Reason:         reason,
+			})
+		}
+		if len(report.Missing) > 0 {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// MissingDocumentsReport lists the employees missing a mandatory document
+// type, or holding only expired copies of one
+func (ds *DocumentService) MissingDocumentsReport(ctx context.Context, actor Actor) ([]*dto.MissingDocumentsResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	requirements, err := ds.documentRepo.ListDocumentRequirements(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing document requirements: %w", err)
+	}
+	if len(requirements) == 0 {
+		return []*dto.MissingDocumentsResponse{}, nil
+	}
+	subjects, err := ds.documentRepo.ListRequirementSubjects(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing employees: %w", err)
+	}
+	held, err := ds.documentRepo.ListHeldDocuments(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing documents: %w", err)
+	}
+
+	companyCountry := ""
+	if company, err := ds.companyRepo.GetCompanyByTenantID(ctx, actor.TenantID); err == nil {
+		companyCountry = utils.NormalizeCountryCode(company.Country)
+	}
+	return missingDocuments(subjects, requirements, held, companyCountry, today(time.Now())), nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestDocumentExpiryStatus(t *testing.T) {
+	today := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	date := func(y int, m time.Month, d int) *time.Time {
+		value := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &value
+	}
+
+	tests := []struct {
+		name         string
+		expiryDate   *time.Time
+		reminderDays int
+		want         string
+	}{
+		{"no expiry", nil, 30, ""},
+		{"expired yesterday", date(2026, 5, 9), 30, DocumentExpired},
+		{"expires today", date(2026, 5, 10), 30, DocumentExpiring},
+		{"last day of the window", date(2026, 6, 9), 30, DocumentExpiring},
+		{"after the window", date(2026, 6, 10), 30, DocumentValid},
+		{"reminders off", date(2026, 5, 11), 0, DocumentValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := documentExpiryStatus(tt.expiryDate, tt.reminderDays, today); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDocumentDates(t *testing.T) {
+	t.Run("accepts optional dates", func(t *testing.T) {
+		issueDate, expiryDate, err := parseDocumentDates(&dto.DocumentUpload{ExpiryDate: "2027-03-31"})
+		if err != nil || issueDate != nil || expiryDate == nil || expiryDate.Format("2006-01-02") != "2027-03-31" {
+			t.Errorf("got %v, %v and %v, want only the expiry date", issueDate, expiryDate, err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		upload dto.DocumentUpload
+		field  string
+	}{
+		{"bad issue date", dto.DocumentUpload{IssueDate: "31/03/2026"}, "issue_date"},
+		{"bad expiry date", dto.DocumentUpload{ExpiryDate: "soon"}, "expiry_date"},
+		{"expiry before issue", dto.DocumentUpload{IssueDate: "2026-03-31", ExpiryDate: "2026-03-31"}, "expiry_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseDocumentDates(&tt.upload)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestMissingDocuments(t *testing.T) {
+	today := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	expired := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	engineer := 2
+
+	subjects := []repositories.RequirementSubject{
+		{EmployeeID: 1, FirstName: "Ada", LastName: "Obi", DesignationID: 2, Country: "NG"},
+		{EmployeeID: 2, FirstName: "Ben", LastName: "Cole", DesignationID: 3, Country: "United Kingdom"},
+		{EmployeeID: 3, FirstName: "Chi", LastName: "Eze", DesignationID: 2},
+	}
+	requirements := []models.DocumentRequirement{
+		{DocumentTypeID: 10, DocumentType: "Contract"},
+		{DocumentTypeID: 11, DocumentType: "Work permit", Country: "GB"},
+		{DocumentTypeID: 12, DocumentType: "Certification", DesignationID: &engineer},
+		{DocumentTypeID: 10, DocumentType: "Contract", DesignationID: &engineer},
+	}
+	held := []repositories.HeldDocument{
+		{EmployeeID: 1, DocumentTypeID: 10},
+		{EmployeeID: 1, DocumentTypeID: 12, ExpiryDate: &expired},
+		{EmployeeID: 2, DocumentTypeID: 10},
+		{EmployeeID: 2, DocumentTypeID: 11},
+		{EmployeeID: 3, DocumentTypeID: 12, ExpiryDate: &expired},
+		{EmployeeID: 3, DocumentTypeID: 12},
+	}
+
+	reports := missingDocuments(subjects, requirements, held, "GB", today)
+
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want Ada and Chi", len(reports))
+	}
+	ada := reports[0]
+	if ada.EmployeeID != 1 || len(ada.Missing) != 1 || ada.Missing[0].DocumentTypeID != 12 || ada.Missing[0].Reason != DocumentExpired {
+		t.Errorf("got %+v, want Ada's expired certification", ada)
+	}
+	// Chi has no home address, so the company's country makes the permit
+	// mandatory; a renewed certification makes up for the expired one
+	chi := reports[1]
+	if chi.EmployeeID != 3 || chi.Country != "GB" || len(chi.Missing) != 2 ||
+		chi.Missing[0].DocumentTypeID != 10 || chi.Missing[1].DocumentTypeID != 11 || chi.Missing[1].Reason != "missing" {
+		t.Errorf("got %+v, want Chi's contract and work permit", chi)
+	}
+}
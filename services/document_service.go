@@ -0,0 +1,556 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+const (
+	// MaxDocumentBytes is the largest file any document type may accept
+	MaxDocumentBytes = 25 << 20
+
+	defaultDocumentMaxBytes = 10 << 20
+
+	defaultDocumentExpiryReminderDays = 30
+	maxDocumentExpiryReminderDays     = 365
+
+	// documentReminderBatch caps how many expiry reminders one scheduler run
+	// sends
+	documentReminderBatch = 200
+	// documentReminderRole is the role whose holders get the daily digest of
+	// expiring documents
+	documentReminderRole = "HR"
+)
+
+const (
+	DocumentValid    = "valid"
+	DocumentExpiring = "expiring"
+	DocumentExpired  = "expired"
+)
+
+var validDocumentTypeStatuses = []string{"active", "inactive"}
+
+type IDocumentService interface {
+	ListDocumentTypes(ctx context.Context, tenantID int, status string) ([]*dto.DocumentTypeResponse, error)
+	GetDocumentType(ctx context.Context, tenantID int, id int) (*dto.DocumentTypeResponse, error)
+	CreateDocumentType(ctx context.Context, tenantID int, req *dto.DocumentTypeRequest) (*dto.DocumentTypeResponse, error)
+	UpdateDocumentType(ctx context.Context, tenantID int, id int, req *dto.DocumentTypeRequest) (*dto.DocumentTypeResponse, error)
+	ListEmployeeDocuments(ctx context.Context, actor Actor, employeeID int) ([]*dto.DocumentResponse, error)
+	UploadDocument(ctx context.Context, actor Actor, employeeID int, upload *dto.DocumentUpload, file io.Reader) (*dto.DocumentResponse, error)
+	OpenDocument(ctx context.Context, actor Actor, id int) (io.ReadCloser, *models.Document, error)
+	DeleteDocument(ctx context.Context, actor Actor, id int) error
+	UploadDocumentVersion(ctx context.Context, actor Actor, id int, upload *dto.DocumentUpload, file io.Reader) (*dto.DocumentResponse, error)
+	ListDocumentVersions(ctx context.Context, actor Actor, id int) ([]*dto.DocumentVersionResponse, error)
+	OpenDocumentVersion(ctx context.Context, actor Actor, id int, version int) (io.ReadCloser, *models.DocumentVersion, error)
+	ListExpiringDocuments(ctx context.Context, actor Actor, withinDays int) ([]*dto.DocumentResponse, error)
+	ListDocumentRequirements(ctx context.Context, actor Actor) ([]*dto.DocumentRequirementResponse, error)
+	CreateDocumentRequirement(ctx context.Context, actor Actor, req *dto.DocumentRequirementRequest) (*dto.DocumentRequirementResponse, error)
+	DeleteDocumentRequirement(ctx context.Context, actor Actor, id int) error
+	MissingDocumentsReport(ctx context.Context, actor Actor) ([]*dto.MissingDocumentsResponse, error)
+}
+
+type DocumentService struct {
+	documentRepo *repositories.DocumentRepository
+	employeeRepo *repositories.EmployeeRepository
+	companyRepo  *repositories.CompanyRepository
+	storage      DocumentStorage
+	mailer       Mailer
+}
+
+func NewDocumentService(documentRepo *repositories.DocumentRepository, employeeRepo *repositories.EmployeeRepository, companyRepo *repositories.CompanyRepository, storage DocumentStorage, mailer Mailer) *DocumentService {
+	return &DocumentService{
+		documentRepo: documentRepo,
+		employeeRepo: employeeRepo,
+		companyRepo:  companyRepo,
+		storage:      storage,
+		mailer:       mailer,
+	}
+}
+
+func (ds *DocumentService) ListDocumentTypes(ctx context.Context, tenantID int, status string) ([]*dto.DocumentTypeResponse, error) {
+	if status != "" && !containsString(validDocumentTypeStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validDocumentTypeStatuses, ", ")}
+	}
+
+	documentTypes, err := ds.documentRepo.ListDocumentTypes(ctx, tenantID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing document types: %w", err)
+	}
+
+	responses := make([]*dto.DocumentTypeResponse, len(documentTypes))
+	for i := range documentTypes {
+		responses[i] = documentTypes[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (ds *DocumentService) GetDocumentType(ctx context.Context, tenantID int, id int) (*dto.DocumentTypeResponse, error) {
+	documentType, err := ds.documentRepo.GetDocumentType(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "document type")
+	}
+	return documentType.ToResponse(), nil
+}
+
+// validateDocumentType checks a document type request and turns it into a
+// document type. Content types are kept as lower-case media types without
+// parameters.
+func validateDocumentType(req *dto.DocumentTypeRequest) (*models.DocumentType, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name is required"}
+	}
+	if len(name) > 100 {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name must be at most 100 characters"}
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+	if !containsString(validDocumentTypeStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validDocumentTypeStatuses, ", ")}
+	}
+
+	maxSize := req.MaxSizeBytes
+	if maxSize == 0 {
+		maxSize = defaultDocumentMaxBytes
+	}
+	if maxSize < 1 || maxSize > MaxDocumentBytes {
+		return nil, &utils.ValidationError{Field: "max_size_bytes", Message: fmt.Sprintf("Maximum size must be between 1 and %d bytes", MaxDocumentBytes)}
+	}
+
+	reminderDays := defaultDocumentExpiryReminderDays
+	if req.ExpiryReminderDays != nil {
+		reminderDays = *req.ExpiryReminderDays
+	}
+	if reminderDays < 0 || reminderDays > maxDocumentExpiryReminderDays {
+		return nil, &utils.ValidationError{Field: "expiry_reminder_days", Message: fmt.Sprintf("Expiry reminder days must be between 0 and %d", maxDocumentExpiryReminderDays)}
+	}
+
+	contentTypes := []string{}
+	for _, contentType := range req.AllowedContentTypes {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil || len(params) > 0 || !strings.Contains(mediaType, "/") {
+			return nil, &utils.ValidationError{Field: "allowed_content_types", Message: fmt.Sprintf("%q is not a media type such as application/pdf", contentType)}
+		}
+		if !containsString(contentTypes, mediaType) {
+			contentTypes = append(contentTypes, mediaType)
+		}
+	}
+
+	return &models.DocumentType{
+		Name:                name,
+		Description:         strings.TrimSpace(req.Description),
+		AllowedContentTypes: contentTypes,
+		MaxSizeBytes:        maxSize,
+		ExpiryReminderDays:  reminderDays,
+		Status:              status,
+	}, nil
+}
+
+// buildDocumentType validates the request and turns it into a document type.
+// id is zero for a new document type; another document type of the tenant
+// may not share its name.
+func (ds *DocumentService) buildDocumentType(ctx context.Context, tenantID int, id int, req *dto.DocumentTypeRequest) (*models.DocumentType, error) {
+	documentType, err := validateDocumentType(req)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := ds.documentRepo.ListDocumentTypes(ctx, tenantID, "")
+	if err != nil {
+		return nil, fmt.Errorf("error listing document types: %w", err)
+	}
+	for _, other := range existing {
+		if other.ID != id && strings.EqualFold(other.Name, documentType.Name) {
+			return nil, &utils.ValidationError{Field: "name", Message: "A document type with this name already exists"}
+		}
+	}
+
+	documentType.ID = id
+	documentType.TenantID = tenantID
+	return documentType, nil
+}
+
+func (ds *DocumentService) CreateDocumentType(ctx context.Context, tenantID int, req *dto.DocumentTypeRequest) (*dto.DocumentTypeResponse, error) {
+	documentType, err := ds.buildDocumentType(ctx, tenantID, 0, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := ds.documentRepo.CreateDocumentType(ctx, documentType)
+	if err != nil {
+		return nil, fmt.Errorf("error creating document type: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+func (ds *DocumentService) UpdateDocumentType(ctx context.Context, tenantID int, id int, req *dto.DocumentTypeRequest) (*dto.DocumentTypeResponse, error) {
+	documentType, err := ds.buildDocumentType(ctx, tenantID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := ds.documentRepo.UpdateDocumentType(ctx, documentType)
+	if err != nil {
+		return nil, notFoundOr(err, "document type")
+	}
+	return updated.ToResponse(), nil
+}
+
+// authorizeEmployee returns the actor's access to an employee's documents,
+// which follows the employee's profile: HR and the employee see and upload
+// everything, while a manager sees only non-sensitive details and so no
+// documents.
+func (ds *DocumentService) authorizeEmployee(ctx context.Context, actor Actor, employeeID int) (profileAccess, error) {
+	employee, err := ds.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return profileAccess{}, fmt.Errorf("employee %w", ErrNotFound)
+	}
+	access, err := profileAccessFor(actor, employee)
+	if err != nil {
+		return profileAccess{}, err
+	}
+	if !access.viewSensitive {
+		return profileAccess{}, ErrForbidden
+	}
+	return access, nil
+}
+
+func (ds *DocumentService) ListEmployeeDocuments(ctx context.Context, actor Actor, employeeID int) ([]*dto.DocumentResponse, error) {
+	if _, err := ds.authorizeEmployee(ctx, actor, employeeID); err != nil {
+		return nil, err
+	}
+
+	documents, err := ds.documentRepo.ListEmployeeDocuments(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing documents: %w", err)
+	}
+
+	return documentResponses(documents, today(time.Now())), nil
+}
+
+// documentExpiryStatus tells whether a document expiring on expiryDate has
+// expired by today or enters its reminder window of reminderDays. It is empty
+// for documents that do not expire.
+func documentExpiryStatus(expiryDate *time.Time, reminderDays int, today time.Time) string {
+	switch {
+	case expiryDate == nil:
+		return ""
+	case expiryDate.Before(today):
+		return DocumentExpired
+	case reminderDays > 0 && !expiryDate.After(today.AddDate(0, 0, reminderDays)):
+		return DocumentExpiring
+	default:
+		return DocumentValid
+	}
+}
+
+func documentResponse(document *models.Document, today time.Time) *dto.DocumentResponse {
+	response := document.ToResponse()
+	response.ExpiryStatus = documentExpiryStatus(document.ExpiryDate, document.ExpiryReminderDays, today)
+	return response
+}
+
+func documentResponses(documents []models.Document, today time.Time) []*dto.DocumentResponse {
+	responses := make([]*dto.DocumentResponse, len(documents))
+	for i := range documents {
+		responses[i] = documentResponse(&documents[i], today)
+	}
+	return responses
+}
+
+// parseDocumentDates reads the optional issue and expiry dates of an upload
+func parseDocumentDates(upload *dto.DocumentUpload) (*time.Time, *time.Time, error) {
+	issueDate, err := parseOptionalDate(strings.TrimSpace(upload.IssueDate))
+	if err != nil {
+		return nil, nil, &utils.ValidationError{Field: "issue_date", Message: "Issue date must be a date in YYYY-MM-DD format"}
+	}
+	expiryDate, err := parseOptionalDate(strings.TrimSpace(upload.ExpiryDate))
+	if err != nil {
+		return nil, nil, &utils.ValidationError{Field: "expiry_date", Message: "Expiry date must be a date in YYYY-MM-DD format"}
+	}
+	if issueDate != nil && expiryDate != nil && !expiryDate.After(*issueDate) {
+		return nil, nil, &utils.ValidationError{Field: "expiry_date", Message: "Expiry date must be after the issue date"}
+	}
+	return issueDate, expiryDate, nil
+}
+
+// cleanDocumentFileName keeps the base name of an uploaded file without
+// control characters, so it is safe to send back in a header
+func cleanDocumentFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "document"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+// validateDocumentUpload checks an upload against its document type and
+// returns its media type
+func validateDocumentUpload(documentType *models.DocumentType, upload *dto.DocumentUpload) (string, error) {
+	if documentType.Status != "active" {
+		return "", &utils.ValidationError{Field: "document_type_id", Message: "Document type is inactive"}
+	}
+	if upload.Size <= 0 {
+		return "", &utils.ValidationError{Field: "file", Message: "File is empty"}
+	}
+	if upload.Size > documentType.MaxSizeBytes {
+		return "", &utils.ValidationError{Field: "file", Message: fmt.Sprintf("%s documents cannot be larger than %d bytes", documentType.Name, documentType.MaxSizeBytes)}
+	}
+
+	contentType := "application/octet-stream"
+	if upload.ContentType != "" {
+		mediaType, _, err := mime.ParseMediaType(upload.ContentType)
+		if err != nil {
+			return "", &utils.ValidationError{Field: "file", Message: "File has an invalid content type"}
+		}
+		contentType = mediaType
+	}
+	if len(documentType.AllowedContentTypes) > 0 && !containsString(documentType.AllowedContentTypes, contentType) {
+		return "", &utils.ValidationError{Field: "file", Message: fmt.Sprintf("%s documents must be one of %s", documentType.Name, strings.Join(documentType.AllowedContentTypes, ", "))}
+	}
+	return contentType, nil
+}
+
+// newDocumentStorageKey returns a fresh key for an employee's document. The
+// key never contains the file name, which is kept in the database.
+func newDocumentStorageKey(tenantID int, employeeID int) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%d/%s", tenantID, employeeID, hex.EncodeToString(random)), nil
+}
+
+// storeDocumentFile checks an upload against its document type and streams
+// it into storage under a fresh key, recording the SHA-256 of its content. The
+// returned document carries the file and dates of the upload.
+func (ds *DocumentService) storeDocumentFile(ctx context.Context, actor Actor, employeeID int, documentType *models.DocumentType, upload *dto.DocumentUpload, file io.Reader) (*models.Document, error) {
+	contentType, err := validateDocumentUpload(documentType, upload)
+	if err != nil {
+		return nil, err
+	}
+	issueDate, expiryDate, err := parseDocumentDates(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newDocumentStorageKey(actor.TenantID, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error creating document key: %w", err)
+	}
+	hash := sha256.New()
+	if err := ds.storage.Put(ctx, key, io.TeeReader(file, hash), upload.Size, contentType); err != nil {
+		return nil, err
+	}
+
+	return &models.Document{
+		TenantID:    actor.TenantID,
+		StorageKey:  key,
+		FileName:    cleanDocumentFileName(upload.FileName),
+		ContentType: contentType,
+		SizeBytes:   upload.Size,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		IssueDate:   issueDate,
+		ExpiryDate:  expiryDate,
+		UploadedBy:  &actor.EmployeeID,
+	}, nil
+}
+
+// removeOrphanedFile deletes a stored file whose record could not be saved
+func (ds *DocumentService) removeOrphanedFile(ctx context.Context, key string) {
+	if err := ds.storage.Delete(ctx, key); err != nil {
+		log.Printf("document upload: error removing orphaned file %s: %v", key, err)
+	}
+}
+
+// UploadDocument stores an uploaded file for an employee as the first version
+// of a new document
+func (ds *DocumentService) UploadDocument(ctx context.Context, actor Actor, employeeID int, upload *dto.DocumentUpload, file io.Reader) (*dto.DocumentResponse, error) {
+	access, err := ds.authorizeEmployee(ctx, actor, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.edit {
+		return nil, ErrForbidden
+	}
+
+	documentType, err := ds.documentRepo.GetDocumentType(ctx, actor.TenantID, upload.DocumentTypeID)
+	if err != nil {
+		return nil, notFoundOr(err, "document type")
+	}
+	stored, err := ds.storeDocumentFile(ctx, actor, employeeID, documentType, upload, file)
+	if err != nil {
+		return nil, err
+	}
+
+	stored.EmployeeID = &employeeID
+	stored.DocumentTypeID = &documentType.ID
+	stored.DocumentType = documentType.Name
+	document, err := ds.documentRepo.CreateDocument(ctx, stored)
+	if err != nil {
+		ds.removeOrphanedFile(ctx, stored.StorageKey)
+		return nil, fmt.Errorf("error creating document: %w", err)
+	}
+	return documentResponse(document, today(time.Now())), nil
+}
+
+// authorizeDocument loads a document the actor may see and returns the
+// actor's access to it. Company documents, which belong to no employee, are
+// only for HR.
+func (ds *DocumentService) authorizeDocument(ctx context.Context, actor Actor, id int) (*models.Document, profileAccess, error) {
+	document, err := ds.documentRepo.GetDocument(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, profileAccess{}, notFoundOr(err, "document")
+	}
+	if document.EmployeeID == nil {
+		if !actor.IsHR() {
+			return nil, profileAccess{}, ErrForbidden
+		}
+		return document, profileAccess{viewSensitive: true, viewBank: true, edit: true}, nil
+	}
+	access, err := ds.authorizeEmployee(ctx, actor, *document.EmployeeID)
+	if err != nil {
+		return nil, profileAccess{}, err
+	}
+	return document, access, nil
+}
+
+// OpenDocument returns the content of the current version of a document the
+// actor may see
+func (ds *DocumentService) OpenDocument(ctx context.Context, actor Actor, id int) (io.ReadCloser, *models.Document, error) {
+	document, _, err := ds.authorizeDocument(ctx, actor, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if document.StorageKey == "" {
+		return nil, nil, fmt.Errorf("document file %w", ErrNotFound)
+	}
+
+	content, err := ds.storage.Get(ctx, document.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, document, nil
+}
+
+// DeleteDocument removes a document and the files of all its versions. Only
+// HR may delete documents.
+func (ds *DocumentService) DeleteDocument(ctx context.Context, actor Actor, id int) error {
+	if !actor.IsHR() {
+		return ErrForbidden
+	}
+
+	keys, err := ds.documentRepo.DeleteDocument(ctx, actor.TenantID, id)
+	if err != nil {
+		return notFoundOr(err, "document")
+	}
+	for _, key := range keys {
+		if err := ds.storage.Delete(ctx, key); err != nil {
+			// The record is gone, so the file is only wasted space
+			log.Printf("document %d: error deleting file %s: %v", id, key, err)
+		}
+	}
+	return nil
+}
+
+// UploadDocumentVersion stores an uploaded file as the new current version of
+// a document, such as a renewed permit. Earlier versions are kept.
+func (ds *DocumentService) UploadDocumentVersion(ctx context.Context, actor Actor, id int, upload *dto.DocumentUpload, file io.Reader) (*dto.DocumentResponse, error) {
+	document, access, err := ds.authorizeDocument(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if !access.edit {
+		return nil, ErrForbidden
+	}
+
+	// Documents uploaded before document types existed take any file
+	documentType := &models.DocumentType{Name: document.DocumentType, MaxSizeBytes: MaxDocumentBytes, Status: "active"}
+	if document.DocumentTypeID != nil {
+		documentType, err = ds.documentRepo.GetDocumentType(ctx, actor.TenantID, *document.DocumentTypeID)
+		if err != nil {
+			return nil, notFoundOr(err, "document type")
+		}
+	}
+
+	employeeID := 0
+	if document.EmployeeID != nil {
+		employeeID = *document.EmployeeID
+	}
+	stored, err := ds.storeDocumentFile(ctx, actor, employeeID, documentType, upload, file)
+	if err != nil {
+		return nil, err
+	}
+
+	stored.ID = document.ID
+	updated, err := ds.documentRepo.AddDocumentVersion(ctx, stored)
+	if err != nil {
+		ds.removeOrphanedFile(ctx, stored.StorageKey)
+		return nil, notFoundOr(err, "document")
+	}
+	return documentResponse(updated, today(time.Now())), nil
+}
+
+func (ds *DocumentService) ListDocumentVersions(ctx context.Context, actor Actor, id int) ([]*dto.DocumentVersionResponse, error) {
+	if _, _, err := ds.authorizeDocument(ctx, actor, id); err != nil {
+		return nil, err
+	}
+
+	versions, err := ds.documentRepo.ListDocumentVersions(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("error listing document versions: %w", err)
+	}
+
+	responses := make([]*dto.DocumentVersionResponse, len(versions))
+	for i := range versions {
+		responses[i] = versions[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// OpenDocumentVersion returns the content of one version of a document the
+// actor may see
+func (ds *DocumentService) OpenDocumentVersion(ctx context.Context, actor Actor, id int, version int) (io.ReadCloser, *models.DocumentVersion, error) {
+	if _, _, err := ds.authorizeDocument(ctx, actor, id); err != nil {
+		return nil, nil, err
+	}
+
+	documentVersion, err := ds.documentRepo.GetDocumentVersion(ctx, actor.TenantID, id, version)
+	if err != nil {
+		return nil, nil, notFoundOr(err, "document version")
+	}
+
+	content, err := ds.storage.Get(ctx, documentVersion.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, documentVersion, nil
+}
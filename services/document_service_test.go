@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestValidateDocumentType(t *testing.T) {
+	t.Run("normalises the content types and defaults the size", func(t *testing.T) {
+		documentType, err := validateDocumentType(&dto.DocumentTypeRequest{
+			Name:                " Contract ",
+			AllowedContentTypes: []string{"Application/PDF", "application/pdf", "image/png"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if documentType.Name != "Contract" || documentType.MaxSizeBytes != defaultDocumentMaxBytes || documentType.Status != "active" {
+			t.Errorf("got %+v, want an active contract type of the default size", documentType)
+		}
+		if len(documentType.AllowedContentTypes) != 2 || documentType.AllowedContentTypes[0] != "application/pdf" {
+			t.Errorf("got %v, want application/pdf and image/png", documentType.AllowedContentTypes)
+		}
+	})
+
+	t.Run("defaults the expiry reminder and keeps zero", func(t *testing.T) {
+		documentType, err := validateDocumentType(&dto.DocumentTypeRequest{Name: "Permit"})
+		if err != nil || documentType.ExpiryReminderDays != defaultDocumentExpiryReminderDays {
+			t.Errorf("got %+v and %v, want the default reminder window", documentType, err)
+		}
+		off := 0
+		documentType, err = validateDocumentType(&dto.DocumentTypeRequest{Name: "Permit", ExpiryReminderDays: &off})
+		if err != nil || documentType.ExpiryReminderDays != 0 {
+			t.Errorf("got %+v and %v, want reminders off", documentType, err)
+		}
+	})
+
+	negativeDays := -1
+	tests := []struct {
+		name  string
+		req   dto.DocumentTypeRequest
+		field string
+	}{
+		{"no name", dto.DocumentTypeRequest{}, "name"},
+		{"too large", dto.DocumentTypeRequest{Name: "Scan", MaxSizeBytes: MaxDocumentBytes + 1}, "max_size_bytes"},
+		{"bad content type", dto.DocumentTypeRequest{Name: "Scan", AllowedContentTypes: []string{"pdf"}}, "allowed_content_types"},
+		{"bad status", dto.DocumentTypeRequest{Name: "Scan", Status: "archived"}, "status"},
+		{"negative reminder", dto.DocumentTypeRequest{Name: "Scan", ExpiryReminderDays: &negativeDays}, "expiry_reminder_days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateDocumentType(&tt.req)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidateDocumentUpload(t *testing.T) {
+	contract := &models.DocumentType{Name: "Contract", AllowedContentTypes: []string{"application/pdf"}, MaxSizeBytes: 1024, Status: "active"}
+
+	t.Run("accepts an allowed file", func(t *testing.T) {
+		contentType, err := validateDocumentUpload(contract, &dto.DocumentUpload{ContentType: "application/pdf; name=x.pdf", Size: 100})
+		if err != nil || contentType != "application/pdf" {
+			t.Errorf("got %q and %v, want application/pdf", contentType, err)
+		}
+	})
+
+	tests := []struct {
+		name         string
+		documentType *models.DocumentType
+		upload       dto.DocumentUpload
+		field        string
+	}{
+		{"empty file", contract, dto.DocumentUpload{ContentType: "application/pdf"}, "file"},
+		{"too large", contract, dto.DocumentUpload{ContentType: "application/pdf", Size: 1025}, "file"},
+		{"wrong type", contract, dto.DocumentUpload{ContentType: "image/png", Size: 10}, "file"},
+		{"inactive type", &models.DocumentType{MaxSizeBytes: 1024, Status: "inactive"}, dto.DocumentUpload{Size: 10}, "document_type_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateDocumentUpload(tt.documentType, &tt.upload)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestCleanDocumentFileName(t *testing.T) {
+	tests := map[string]string{
+		"offer.pdf":              "offer.pdf",
+		`C:\Users\ada\offer.pdf`: "offer.pdf",
+		"../../etc/passwd":       "passwd",
+		"bad\r\nname.pdf":        "badname.pdf",
+		"":                       "document",
+	}
+	for name, want := range tests {
+		if got := cleanDocumentFileName(name); got != want {
+			t.Errorf("cleanDocumentFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
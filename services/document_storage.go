@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DocumentStorage keeps the files of uploaded documents under keys chosen by
+// the document service
+type DocumentStorage interface {
+	// Put stores size bytes read from body under key
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get opens the file stored under key. It returns an error wrapping
+	// ErrNotFound when there is none.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the file stored under key, if any
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalDocumentStorage keeps documents as files under a directory. It is
+// used when no S3 bucket is configured.
+type LocalDocumentStorage struct {
+	dir string
+}
+
+func NewLocalDocumentStorage(dir string) *LocalDocumentStorage {
+	return &LocalDocumentStorage{
+		dir: dir,
+	}
+}
+
+// path turns key into a path inside the storage directory
+func (ls *LocalDocumentStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(ls.dir, clean), nil
+}
+
+func (ls *LocalDocumentStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	filePath, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+		return fmt.Errorf("error creating document directory: %w", err)
+	}
+
+	// Written under a temporary name so a failed upload never leaves a
+	// partial file behind the key
+	tmpPath := filePath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("error creating document file: %w", err)
+	}
+	_, err = io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing document file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error saving document file: %w", err)
+	}
+	return nil
+}
+
+func (ls *LocalDocumentStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filePath, err := ls.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("document file %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening document file: %w", err)
+	}
+	return file, nil
+}
+
+func (ls *LocalDocumentStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting document file: %w", err)
+	}
+	return nil
+}
+
+// S3DocumentStorage keeps documents in a bucket of an S3-compatible object
+// store, such as AWS S3 or MinIO, addressed path-style at endpoint
+type S3DocumentStorage struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+func NewS3DocumentStorage(endpoint string, region string, bucket string, accessKey string, secretKey string) *S3DocumentStorage {
+	return &S3DocumentStorage{
+		endpoint:  strings.TrimRight(endpoint, "/"),
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// s3UnsignedPayload signs a request without hashing its body, which would
+// mean reading a streamed upload twice
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+func (ss *S3DocumentStorage) do(ctx context.Context, method string, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	url := ss.endpoint + "/" + awsURIEncode(ss.bucket, true) + "/" + awsURIEncode(key, false)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	signS3Request(req, s3UnsignedPayload, ss.accessKey, ss.secretKey, ss.region, time.Now())
+	return ss.client.Do(req)
+}
+
+// s3Error describes a failed response, including the start of the error
+// document the store sent
+func s3Error(action string, resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("error %s document: object store returned %s: %s", action, resp.Status, strings.TrimSpace(string(detail)))
+}
+
+func (ss *S3DocumentStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	resp, err := ss.do(ctx, http.MethodPut, key, body, size, contentType)
+	if err != nil {
+		return fmt.Errorf("error storing document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return s3Error("storing", resp)
+	}
+	return nil
+}
+
+func (ss *S3DocumentStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := ss.do(ctx, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, fmt.Errorf("error reading document: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("document file %w", ErrNotFound)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, s3Error("reading", resp)
+	}
+	return resp.Body, nil
+}
+
+func (ss *S3DocumentStorage) Delete(ctx context.Context, key string) error {
+	resp, err := ss.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return fmt.Errorf("error deleting document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return s3Error("deleting", resp)
+	}
+	return nil
+}
+
+// awsURIEncode percent-encodes everything but the unreserved characters, and
+// slashes unless encodeSlash is set, as AWS Signature Version 4 requires
+func awsURIEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// signS3Request signs req with AWS Signature Version 4, covering the host and
+// every header already set. Requests to the object store carry no query
+// string.
+func signS3Request(req *http.Request, payloadHash string, accessKey string, secretKey string, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(req.URL.Path, false),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
@@ -0,0 +1,441 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type IEmployeeProfileService interface {
+	GetPersonalDetails(ctx context.Context, actor Actor, employeeID int) (*dto.PersonalDetailsResponse, error)
+	UpdatePersonalDetails(ctx context.Context, actor Actor, employeeID int, req *dto.PersonalDetailsRequest) (*dto.PersonalDetailsResponse, error)
+	ListAddresses(ctx context.Context, actor Actor, employeeID int, includeHistory bool) ([]*dto.AddressResponse, error)
+	AddAddress(ctx context.Context, actor Actor, employeeID int, req *dto.AddressRequest) (*dto.AddressResponse, error)
+	ListEmergencyContacts(ctx context.Context, actor Actor, employeeID int) ([]*dto.EmergencyContactResponse, error)
+	SaveEmergencyContact(ctx context.Context, actor Actor, employeeID int, contactID int, req *dto.EmergencyContactRequest) (*dto.EmergencyContactResponse, error)
+	DeleteEmergencyContact(ctx context.Context, actor Actor, employeeID int, contactID int) error
+	ListBankAccounts(ctx context.Context, actor Actor, employeeID int) ([]*dto.BankAccountResponse, error)
+	SaveBankAccount(ctx context.Context, actor Actor, employeeID int, accountID int, req *dto.BankAccountRequest) (*dto.BankAccountResponse, error)
+	DeleteBankAccount(ctx context.Context, actor Actor, employeeID int, accountID int) error
+}
+
+type EmployeeProfileService struct {
+	employeeRepo *repositories.EmployeeRepository
+	profileRepo  *repositories.EmployeeProfileRepository
+	companyRepo  *repositories.CompanyRepository
+}
+
+func NewEmployeeProfileService(
+	employeeRepo *repositories.EmployeeRepository,
+	profileRepo *repositories.EmployeeProfileRepository,
+	companyRepo *repositories.CompanyRepository,
+) *EmployeeProfileService {
+	return &EmployeeProfileService{
+		employeeRepo: employeeRepo,
+		profileRepo:  profileRepo,
+		companyRepo:  companyRepo,
+	}
+}
+
+// profileAccess describes which parts of an employee's profile an actor may
+// see or change. HR and the employee themselves get everything; the direct
+// manager sees only non-sensitive details and emergency contacts.
+type profileAccess struct {
+	viewSensitive bool
+	viewBank      bool
+	edit          bool
+}
+
+func profileAccessFor(actor Actor, employee *models.Employee) (profileAccess, error) {
+	if actor.IsHR() || actor.EmployeeID == employee.ID {
+		return profileAccess{viewSensitive: true, viewBank: true, edit: true}, nil
+	}
+	if employee.ManagerID != nil && *employee.ManagerID == actor.EmployeeID {
+		return profileAccess{}, nil
+	}
+	return profileAccess{}, ErrForbidden
+}
+
+func (ps *EmployeeProfileService) authorize(ctx context.Context, actor Actor, employeeID int) (profileAccess, error) {
+	employee, err := ps.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return profileAccess{}, fmt.Errorf("employee %w", ErrNotFound)
+	}
+	return profileAccessFor(actor, employee)
+}
+
+func (ps *EmployeeProfileService) countryRules(ctx context.Context, tenantID int) (utils.CountryRules, bool) {
+	company, err := ps.companyRepo.GetCompanyByTenantID(ctx, tenantID)
+	if err != nil {
+		return utils.CountryRules{}, false
+	}
+	return utils.GetCountryRules(company.Country)
+}
+
+func notFoundOr(err error, what string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s %w", what, ErrNotFound)
+	}
+	return err
+}
+
+var (
+	validGenders       = []string{"male", "female", "non_binary", "other", "prefer_not_to_say"}
+	validAddressTypes  = []string{"home", "mailing", "temporary"}
+	phonePattern       = regexp.MustCompile(`^\+?[0-9 ()-]{7,20}$`)
+	currencyCodeRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePersonalDetails(req *dto.PersonalDetailsRequest, rules utils.CountryRules, now time.Time) (*time.Time, error) {
+	var dateOfBirth *time.Time
+	if req.DateOfBirth != nil && *req.DateOfBirth != "" {
+		dob, err := time.Parse("2006-01-02", *req.DateOfBirth)
+		if err != nil {
+			return nil, &utils.ValidationError{Field: "date_of_birth", Message: "Date of birth must be in YYYY-MM-DD format"}
+		}
+		if !dob.Before(now) {
+			return nil, &utils.ValidationError{Field: "date_of_birth", Message: "Date of birth must be in the past"}
+		}
+		dateOfBirth = &dob
+	}
+
+	if req.Gender != "" && !containsString(validGenders, req.Gender) {
+		return nil, &utils.ValidationError{Field: "gender", Message: "Gender must be one of " + strings.Join(validGenders, ", ")}
+	}
+
+	if req.NationalID != "" && rules.NationalIDPattern != nil && !rules.NationalIDPattern.MatchString(strings.ToUpper(req.NationalID)) {
+		return nil, &utils.ValidationError{Field: "national_id", Message: fmt.Sprintf("National ID is not a valid %s for %s", rules.NationalIDLabel, rules.Code)}
+	}
+
+	if req.TaxNumber != "" && rules.TaxNumberPattern != nil && !rules.TaxNumberPattern.MatchString(strings.ToUpper(req.TaxNumber)) {
+		return nil, &utils.ValidationError{Field: "tax_number", Message: fmt.Sprintf("Tax number is not valid for %s", rules.Code)}
+	}
+
+	return dateOfBirth, nil
+}
+
+func validateAddress(req *dto.AddressRequest) error {
+	if strings.TrimSpace(req.Line1) == "" {
+		return &utils.ValidationError{Field: "line1", Message: "Address line 1 is required"}
+	}
+	if strings.TrimSpace(req.City) == "" {
+		return &utils.ValidationError{Field: "city", Message: "City is required"}
+	}
+	if !containsString(validAddressTypes, req.AddressType) {
+		return &utils.ValidationError{Field: "address_type", Message: "Address type must be one of " + strings.Join(validAddressTypes, ", ")}
+	}
+	if req.Country == "" {
+		return &utils.ValidationError{Field: "country", Message: "Country must be a two-letter ISO code"}
+	}
+
+	rules, ok := utils.GetCountryRules(req.Country)
+	if ok && req.PostalCode != "" && rules.PostalCodePattern != nil && !rules.PostalCodePattern.MatchString(strings.ToUpper(req.PostalCode)) {
+		return &utils.ValidationError{Field: "postal_code", Message: fmt.Sprintf("Postal code is not valid for %s", rules.Code)}
+	}
+	return nil
+}
+
+func validateEmergencyContact(req *dto.EmergencyContactRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return &utils.ValidationError{Field: "name", Message: "Name is required"}
+	}
+	if strings.TrimSpace(req.Relationship) == "" {
+		return &utils.ValidationError{Field: "relationship", Message: "Relationship is required"}
+	}
+	if !phonePattern.MatchString(req.Phone) {
+		return &utils.ValidationError{Field: "phone", Message: "Phone number is invalid"}
+	}
+	if req.Email != "" && !utils.IsValidEmail(req.Email) {
+		return &utils.ValidationError{Field: "email", Message: "Email is invalid"}
+	}
+	return nil
+}
+
+func validateBankAccount(req *dto.BankAccountRequest, rules utils.CountryRules) error {
+	if strings.TrimSpace(req.BankName) == "" {
+		return &utils.ValidationError{Field: "bank_name", Message: "Bank name is required"}
+	}
+	if strings.TrimSpace(req.AccountName) == "" {
+		return &utils.ValidationError{Field: "account_name", Message: "Account name is required"}
+	}
+	if req.AccountNumber == "" && req.IBAN == "" {
+		return &utils.ValidationError{Field: "account_number", Message: "Account number or IBAN is required"}
+	}
+	if req.IBAN != "" && !utils.IsValidIBAN(req.IBAN) {
+		return &utils.ValidationError{Field: "iban", Message: "IBAN is invalid"}
+	}
+	if req.AccountNumber != "" && rules.BankAccountPattern != nil && !rules.BankAccountPattern.MatchString(req.AccountNumber) {
+		return &utils.ValidationError{Field: "account_number", Message: fmt.Sprintf("Account number is not valid for %s", rules.Code)}
+	}
+	if req.BankCode != "" && rules.BankCodePattern != nil && !rules.BankCodePattern.MatchString(strings.ToUpper(req.BankCode)) {
+		return &utils.ValidationError{Field: "bank_code", Message: fmt.Sprintf("Bank code is not a valid %s for %s", rules.BankCodeLabel, rules.Code)}
+	}
+	if req.Currency != "" && !currencyCodeRegexp.MatchString(req.Currency) {
+		return &utils.ValidationError{Field: "currency", Message: "Currency must be a three-letter ISO code"}
+	}
+	return nil
+}
+
+func (ps *EmployeeProfileService) GetPersonalDetails(ctx context.Context, actor Actor, employeeID int) (*dto.PersonalDetailsResponse, error) {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := ps.profileRepo.GetPersonalDetails(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "personal details")
+	}
+
+	response := details.ToResponse()
+	if !access.viewSensitive {
+		response.DateOfBirth = nil
+		response.MaritalStatus = ""
+		response.NationalID = ""
+		response.TaxNumber = ""
+	}
+	return response, nil
+}
+
+func (ps *EmployeeProfileService) UpdatePersonalDetails(ctx context.Context, actor Actor, employeeID int, req *dto.PersonalDetailsRequest) (*dto.PersonalDetailsResponse, error) {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.edit {
+		return nil, ErrForbidden
+	}
+
+	rules, _ := ps.countryRules(ctx, actor.TenantID)
+	dateOfBirth, err := validatePersonalDetails(req, rules, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := ps.profileRepo.UpsertPersonalDetails(ctx, &models.EmployeePersonalDetails{
+		TenantID:      actor.TenantID,
+		EmployeeID:    employeeID,
+		DateOfBirth:   dateOfBirth,
+		Gender:        req.Gender,
+		Nationality:   req.Nationality,
+		MaritalStatus: req.MaritalStatus,
+		NationalID:    strings.ToUpper(req.NationalID),
+		TaxNumber:     strings.ToUpper(req.TaxNumber),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error saving personal details: %w", err)
+	}
+	return details.ToResponse(), nil
+}
+
+func (ps *EmployeeProfileService) ListAddresses(ctx context.Context, actor Actor, employeeID int, includeHistory bool) ([]*dto.AddressResponse, error) {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.viewSensitive {
+		return nil, ErrForbidden
+	}
+
+	addresses, err := ps.profileRepo.ListAddresses(ctx, actor.TenantID, employeeID, includeHistory)
+	if err != nil {
+		return nil, fmt.Errorf("error listing addresses: %w", err)
+	}
+
+	responses := make([]*dto.AddressResponse, len(addresses))
+	for i := range addresses {
+		responses[i] = addresses[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (ps *EmployeeProfileService) AddAddress(ctx context.Context, actor Actor, employeeID int, req *dto.AddressRequest) (*dto.AddressResponse, error) {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.edit {
+		return nil, ErrForbidden
+	}
+
+	if req.AddressType == "" {
+		req.AddressType = "home"
+	}
+	if req.Country == "" {
+		if company, err := ps.companyRepo.GetCompanyByTenantID(ctx, actor.TenantID); err == nil {
+			req.Country = utils.NormalizeCountryCode(company.Country)
+		}
+	}
+	req.Country = utils.NormalizeCountryCode(req.Country)
+
+	if err := validateAddress(req); err != nil {
+		return nil, err
+	}
+
+	validFrom := time.Now().Truncate(24 * time.Hour)
+	if req.ValidFrom != "" {
+		validFrom, err = time.Parse("2006-01-02", req.ValidFrom)
+		if err != nil {
+			return nil, &utils.ValidationError{Field: "valid_from", Message: "Valid from must be in YYYY-MM-DD format"}
+		}
+	}
+
+	address, err := ps.profileRepo.AddAddress(ctx, &models.EmployeeAddress{
+		TenantID:    actor.TenantID,
+		EmployeeID:  employeeID,
+		AddressType: req.AddressType,
+		Line1:       req.Line1,
+		Line2:       req.Line2,
+		City:        req.City,
+		State:       req.State,
+		PostalCode:  strings.ToUpper(req.PostalCode),
+		Country:     req.Country,
+		ValidFrom:   validFrom,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error saving address: %w", err)
+	}
+	return address.ToResponse(), nil
+}
+
+func (ps *EmployeeProfileService) ListEmergencyContacts(ctx context.Context, actor Actor, employeeID int) ([]*dto.EmergencyContactResponse, error) {
+	if _, err := ps.authorize(ctx, actor, employeeID); err != nil {
+		return nil, err
+	}
+
+	contacts, err := ps.profileRepo.ListEmergencyContacts(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing emergency contacts: %w", err)
+	}
+
+	responses := make([]*dto.EmergencyContactResponse, len(contacts))
+	for i := range contacts {
+		responses[i] = contacts[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (ps *EmployeeProfileService) SaveEmergencyContact(ctx context.Context, actor Actor, employeeID int, contactID int, req *dto.EmergencyContactRequest) (*dto.EmergencyContactResponse, error) {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.edit {
+		return nil, ErrForbidden
+	}
+
+	if err := validateEmergencyContact(req); err != nil {
+		return nil, err
+	}
+
+	contact, err := ps.profileRepo.SaveEmergencyContact(ctx, &models.EmployeeEmergencyContact{
+		ID:           contactID,
+		TenantID:     actor.TenantID,
+		EmployeeID:   employeeID,
+		Name:         req.Name,
+		Relationship: req.Relationship,
+		Phone:        req.Phone,
+		Email:        req.Email,
+		IsPrimary:    req.IsPrimary,
+	})
+	if err != nil {
+		return nil, notFoundOr(err, "emergency contact")
+	}
+	return contact.ToResponse(), nil
+}
+
+func (ps *EmployeeProfileService) DeleteEmergencyContact(ctx context.Context, actor Actor, employeeID int, contactID int) error {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return err
+	}
+	if !access.edit {
+		return ErrForbidden
+	}
+
+	return notFoundOr(ps.profileRepo.DeleteEmergencyContact(ctx, actor.TenantID, employeeID, contactID), "emergency contact")
+}
+
+func (ps *EmployeeProfileService) ListBankAccounts(ctx context.Context, actor Actor, employeeID int) ([]*dto.BankAccountResponse, error) {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.viewBank {
+		return nil, ErrForbidden
+	}
+
+	accounts, err := ps.profileRepo.ListBankAccounts(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing bank accounts: %w", err)
+	}
+
+	responses := make([]*dto.BankAccountResponse, len(accounts))
+	for i := range accounts {
+		responses[i] = accounts[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (ps *EmployeeProfileService) SaveBankAccount(ctx context.Context, actor Actor, employeeID int, accountID int, req *dto.BankAccountRequest) (*dto.BankAccountResponse, error) {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.edit || !access.viewBank {
+		return nil, ErrForbidden
+	}
+
+	rules, _ := ps.countryRules(ctx, actor.TenantID)
+	if err := validateBankAccount(req, rules); err != nil {
+		return nil, err
+	}
+
+	account, err := ps.profileRepo.SaveBankAccount(ctx, &models.EmployeeBankAccount{
+		ID:            accountID,
+		TenantID:      actor.TenantID,
+		EmployeeID:    employeeID,
+		BankName:      req.BankName,
+		AccountName:   req.AccountName,
+		AccountNumber: req.AccountNumber,
+		BankCode:      strings.ToUpper(req.BankCode),
+		IBAN:          strings.ToUpper(strings.ReplaceAll(req.IBAN, " ", "")),
+		Currency:      req.Currency,
+		IsPrimary:     req.IsPrimary,
+	})
+	if err != nil {
+		return nil, notFoundOr(err, "bank account")
+	}
+	return account.ToResponse(), nil
+}
+
+func (ps *EmployeeProfileService) DeleteBankAccount(ctx context.Context, actor Actor, employeeID int, accountID int) error {
+	access, err := ps.authorize(ctx, actor, employeeID)
+	if err != nil {
+		return err
+	}
+	if !access.edit || !access.viewBank {
+		return ErrForbidden
+	}
+
+	return notFoundOr(ps.profileRepo.DeleteBankAccount(ctx, actor.TenantID, employeeID, accountID), "bank account")
+}
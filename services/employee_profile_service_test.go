@@ -0,0 +1,98 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestProfileAccessFor(t *testing.T) {
+	managerID := 10
+	employee := &models.Employee{ID: 5, ManagerID: &managerID}
+
+	t.Run("HR sees everything", func(t *testing.T) {
+		access, err := profileAccessFor(Actor{EmployeeID: 1, Role: "HR"}, employee)
+		if err != nil || !access.viewBank || !access.viewSensitive || !access.edit {
+			t.Errorf("got %+v, %v; want full access", access, err)
+		}
+	})
+
+	t.Run("employee sees their own bank details", func(t *testing.T) {
+		access, err := profileAccessFor(Actor{EmployeeID: 5, Role: "Employee"}, employee)
+		if err != nil || !access.viewBank {
+			t.Errorf("got %+v, %v; want bank access", access, err)
+		}
+	})
+
+	t.Run("manager cannot see bank or sensitive details", func(t *testing.T) {
+		access, err := profileAccessFor(Actor{EmployeeID: 10, Role: "Employee"}, employee)
+		if err != nil || access.viewBank || access.viewSensitive || access.edit {
+			t.Errorf("got %+v, %v; want read-only non-sensitive access", access, err)
+		}
+	})
+
+	t.Run("colleague is forbidden", func(t *testing.T) {
+		if _, err := profileAccessFor(Actor{EmployeeID: 11, Role: "Employee"}, employee); err != ErrForbidden {
+			t.Errorf("got %v, want ErrForbidden", err)
+		}
+	})
+}
+
+func TestValidateBankAccount(t *testing.T) {
+	nigeria, _ := utils.GetCountryRules("Nigeria")
+	uk, _ := utils.GetCountryRules("GB")
+
+	cases := []struct {
+		name    string
+		req     dto.BankAccountRequest
+		rules   utils.CountryRules
+		wantErr bool
+	}{
+		{"valid NUBAN", dto.BankAccountRequest{BankName: "B", AccountName: "A", AccountNumber: "0123456789"}, nigeria, false},
+		{"short NUBAN", dto.BankAccountRequest{BankName: "B", AccountName: "A", AccountNumber: "12345"}, nigeria, true},
+		{"valid UK sort code", dto.BankAccountRequest{BankName: "B", AccountName: "A", AccountNumber: "12345678", BankCode: "12-34-56"}, uk, false},
+		{"valid IBAN", dto.BankAccountRequest{BankName: "B", AccountName: "A", IBAN: "GB82 WEST 1234 5698 7654 32"}, uk, false},
+		{"bad IBAN checksum", dto.BankAccountRequest{BankName: "B", AccountName: "A", IBAN: "GB83WEST12345698765432"}, uk, true},
+		{"missing account", dto.BankAccountRequest{BankName: "B", AccountName: "A"}, nigeria, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateBankAccount(&tc.req, tc.rules)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePersonalDetails(t *testing.T) {
+	nigeria, _ := utils.GetCountryRules("NG")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("rejects a national ID in the wrong format", func(t *testing.T) {
+		_, err := validatePersonalDetails(&dto.PersonalDetailsRequest{NationalID: "ABC"}, nigeria, now)
+		if err == nil {
+			t.Errorf("got nil error, want validation error")
+		}
+	})
+
+	t.Run("rejects a future date of birth", func(t *testing.T) {
+		dob := "2030-01-01"
+		_, err := validatePersonalDetails(&dto.PersonalDetailsRequest{DateOfBirth: &dob}, nigeria, now)
+		if err == nil {
+			t.Errorf("got nil error, want validation error")
+		}
+	})
+
+	t.Run("accepts valid details", func(t *testing.T) {
+		dob := "1990-05-17"
+		_, err := validatePersonalDetails(&dto.PersonalDetailsRequest{DateOfBirth: &dob, Gender: "female", NationalID: "12345678901"}, nigeria, now)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	})
+}
@@ -2,50 +2,98 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/falasefemi2/peopleos/dto"
 	"github.com/falasefemi2/peopleos/models"
 	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
 )
 
 type IEmployeeService interface {
-	CreateEmployee(ctx context.Context, req *dto.CreateEmployeeRequest) (*dto.EmployeeResponse, error)
+	CreateEmployee(ctx context.Context, tenantID int, actorID int, req *dto.CreateEmployeeRequest) (*dto.EmployeeResponse, error)
+	UpdateEmployee(ctx context.Context, actor Actor, employeeID int, req *dto.UpdateEmployeeRequest) (*dto.EmployeeSummaryResponse, error)
+	ListEmployees(ctx context.Context, actor Actor, filter *dto.EmployeeFilter) ([]*dto.EmployeeSummaryResponse, error)
 }
 
 type EmployeeService struct {
-	employeeRepo *repositories.EmployeeRepository
-	roleRepo     *repositories.RoleRepository
+	employeeRepo       *repositories.EmployeeRepository
+	roleRepo           *repositories.RoleRepository
+	invitationService  *InvitationService
+	customFieldService *CustomFieldService
+	employmentService  *EmploymentHistoryService
+	onboardingService  *OnboardingService
 }
 
-func NewEmployeeService(employeeRepo *repositories.EmployeeRepository, roleRepo *repositories.RoleRepository) *EmployeeService {
+func NewEmployeeService(
+	employeeRepo *repositories.EmployeeRepository,
+	roleRepo *repositories.RoleRepository,
+	invitationService *InvitationService,
+	customFieldService *CustomFieldService,
+	employmentService *EmploymentHistoryService,
+	onboardingService *OnboardingService,
+) *EmployeeService {
 	return &EmployeeService{
-		employeeRepo: employeeRepo,
-		roleRepo:     roleRepo,
+		employeeRepo:       employeeRepo,
+		roleRepo:           roleRepo,
+		invitationService:  invitationService,
+		customFieldService: customFieldService,
+		employmentService:  employmentService,
+		onboardingService:  onboardingService,
 	}
 }
 
-func (es *EmployeeService) CreateEmployee(ctx context.Context, req *dto.CreateEmployeeRequest) (*dto.EmployeeResponse, error) {
+func (es *EmployeeService) CreateEmployee(ctx context.Context, tenantID int, actorID int, req *dto.CreateEmployeeRequest) (*dto.EmployeeResponse, error) {
 	existingEmployee, _ := es.employeeRepo.GetEmployeeByEmail(ctx, req.Email)
 	if existingEmployee != nil {
 		return nil, fmt.Errorf("employee with this email already exists")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	fields, err := es.customFieldService.employeeFields(ctx, tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("error hashing password: %w", err)
+		return nil, err
+	}
+	customFields, err := mergeCustomFieldValues(fields, nil, req.CustomFields, true)
+	if err != nil {
+		return nil, err
+	}
+
+	employmentType := req.EmploymentType
+	if employmentType == "" {
+		employmentType = "full_time"
+	}
+	if !containsString(validEmploymentTypes, employmentType) {
+		return nil, &utils.ValidationError{Field: "employment_type", Message: "Employment type must be one of " + strings.Join(validEmploymentTypes, ", ")}
 	}
 
 	employee := &models.Employee{
-		FirstName:     req.FirstName,
-		LastName:      req.LastName,
-		Email:         req.Email,
-		PasswordHash:  string(hashedPassword),
-		DepartmentID:  req.DepartmentID,
-		DesignationID: req.DesignationID,
-		Status:        "active",
+		TenantID:       tenantID,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Email:          req.Email,
+		DepartmentID:   req.DepartmentID,
+		DesignationID:  req.DesignationID,
+		Status:         "active",
+		EmploymentType: employmentType,
+		CustomFields:   customFields,
+	}
+
+	// Invited employees stay in draft with no password until they accept
+	if req.SendInvitation {
+		employee.Status = "draft"
+	} else {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("error hashing password: %w", err)
+		}
+		employee.PasswordHash = string(hashedPassword)
 	}
 
 	createdEmployee, err := es.employeeRepo.CreateEmployee(ctx, employee)
@@ -53,15 +101,120 @@ func (es *EmployeeService) CreateEmployee(ctx context.Context, req *dto.CreateEm
 		return nil, fmt.Errorf("error creating employee: %w", err)
 	}
 
-	err = es.employeeRepo.AssignRoleToEmployee(ctx, createdEmployee.ID, req.RoleID)
+	invitationID, err := es.setUpNewEmployee(ctx, createdEmployee, actorID, req)
+	if err != nil {
+		// Remove the half set up employee so that the request can be retried
+		// with the same email
+		if deleteErr := es.employeeRepo.DeleteEmployee(context.WithoutCancel(ctx), tenantID, createdEmployee.ID); deleteErr != nil {
+			log.Printf("error removing employee %d after failed creation: %v", createdEmployee.ID, deleteErr)
+		}
+		return nil, err
+	}
+
+	response := &dto.EmployeeResponse{
+		ID:           createdEmployee.ID,
+		Email:        createdEmployee.Email,
+		Name:         createdEmployee.FirstName + " " + createdEmployee.LastName,
+		Role:         "Assigned",
+		Status:       createdEmployee.Status,
+		InvitationID: invitationID,
+	}
+	response.CustomFields = createdEmployee.CustomFields
+
+	return response, nil
+}
+
+// setUpNewEmployee assigns a new employee's role, records their hire, starts
+// their onboarding and, when asked, invites them. It returns the ID of the
+// invitation, or zero when none was sent.
+func (es *EmployeeService) setUpNewEmployee(ctx context.Context, employee *models.Employee, actorID int, req *dto.CreateEmployeeRequest) (int, error) {
+	if err := es.employeeRepo.AssignRoleToEmployee(ctx, employee.ID, req.RoleID); err != nil {
+		return 0, fmt.Errorf("error assigning role: %w", err)
+	}
+
+	if err := es.employmentService.recordHire(ctx, employee, actorID, time.Now()); err != nil {
+		return 0, fmt.Errorf("error recording employment history: %w", err)
+	}
+
+	if err := es.onboardingService.startOnboarding(ctx, employee, actorID, time.Now()); err != nil {
+		return 0, fmt.Errorf("error starting onboarding: %w", err)
+	}
+
+	if !req.SendInvitation {
+		return 0, nil
+	}
+	invitation, err := es.invitationService.InviteEmployee(ctx, employee, actorID)
 	if err != nil {
-		return nil, fmt.Errorf("error assigning role: %w", err)
+		return 0, fmt.Errorf("error inviting employee: %w", err)
+	}
+	return invitation.ID, nil
+}
+
+// UpdateEmployee changes an employee's name, phone and custom field values.
+// Only HR may update employees.
+func (es *EmployeeService) UpdateEmployee(ctx context.Context, actor Actor, employeeID int, req *dto.UpdateEmployeeRequest) (*dto.EmployeeSummaryResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
 	}
 
-	return &dto.EmployeeResponse{
-		ID:    createdEmployee.ID,
-		Email: createdEmployee.Email,
-		Name:  createdEmployee.FirstName + " " + createdEmployee.LastName,
-		Role:  "Assigned",
-	}, nil
+	employee, err := es.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+
+	if req.FirstName != nil {
+		if strings.TrimSpace(*req.FirstName) == "" {
+			return nil, &utils.ValidationError{Field: "first_name", Message: "First name is required"}
+		}
+		employee.FirstName = strings.TrimSpace(*req.FirstName)
+	}
+	if req.LastName != nil {
+		employee.LastName = strings.TrimSpace(*req.LastName)
+	}
+	if req.Phone != nil {
+		employee.Phone = strings.TrimSpace(*req.Phone)
+	}
+
+	fields, err := es.customFieldService.employeeFields(ctx, actor.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if employee.CustomFields, err = mergeCustomFieldValues(fields, employee.CustomFields, req.CustomFields, false); err != nil {
+		return nil, err
+	}
+
+	if err := es.employeeRepo.UpdateEmployee(ctx, employee); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notFoundOr(err, "employee")
+		}
+		return nil, fmt.Errorf("error updating employee: %w", err)
+	}
+
+	response := employee.ToSummaryResponse()
+	response.CustomFields = visibleCustomFields(fields, actor, employee)
+	return response, nil
+}
+
+func (es *EmployeeService) ListEmployees(ctx context.Context, actor Actor, filter *dto.EmployeeFilter) ([]*dto.EmployeeSummaryResponse, error) {
+	fields, err := es.customFieldService.employeeFields(ctx, actor.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		if filter.CustomFields, err = normalizeCustomFieldFilter(fields, filter.CustomFields, actor.IsHR()); err != nil {
+			return nil, err
+		}
+	}
+
+	employees, err := es.employeeRepo.ListEmployees(ctx, actor.TenantID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error listing employees: %w", err)
+	}
+
+	responses := make([]*dto.EmployeeSummaryResponse, len(employees))
+	for i := range employees {
+		responses[i] = employees[i].ToSummaryResponse()
+		responses[i].CustomFields = visibleCustomFields(fields, actor, &employees[i])
+	}
+	return responses, nil
 }
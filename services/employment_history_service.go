@@ -0,0 +1,478 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Fields an employment history record can change
+const (
+	historyFieldDepartment     = "department_id"
+	historyFieldDesignation    = "designation_id"
+	historyFieldManager        = "manager_id"
+	historyFieldEmploymentType = "employment_type"
+	historyFieldSalary         = "salary"
+)
+
+var (
+	validChangeTypes     = []string{"transfer", "promotion", "demotion", "manager_change", "employment_type_change", "compensation_change", "correction"}
+	validEmploymentTypes = []string{"full_time", "part_time", "contract", "intern", "temporary"}
+)
+
+// maxManagerChainDepth bounds the walk up the reporting line when checking
+// for cycles, so bad data can never loop forever.
+const maxManagerChainDepth = 100
+
+type IEmploymentHistoryService interface {
+	ListHistory(ctx context.Context, actor Actor, employeeID int) ([]*dto.EmploymentHistoryResponse, error)
+	ScheduleChange(ctx context.Context, actor Actor, employeeID int, req *dto.EmploymentChangeRequest) (*dto.EmploymentHistoryResponse, error)
+	CancelChange(ctx context.Context, actor Actor, employeeID int, changeID int) (*dto.EmploymentHistoryResponse, error)
+	GetEmploymentAsOf(ctx context.Context, actor Actor, employeeID int, date string) (*dto.EmploymentStateResponse, error)
+	GetOrgAsOf(ctx context.Context, actor Actor, date string) ([]*dto.EmploymentStateResponse, error)
+}
+
+type EmploymentHistoryService struct {
+	historyRepo     *repositories.EmploymentHistoryRepository
+	employeeRepo    *repositories.EmployeeRepository
+	departmentRepo  *repositories.DepartmentRepository
+	designationRepo *repositories.DesignationRepository
+}
+
+func NewEmploymentHistoryService(
+	historyRepo *repositories.EmploymentHistoryRepository,
+	employeeRepo *repositories.EmployeeRepository,
+	departmentRepo *repositories.DepartmentRepository,
+	designationRepo *repositories.DesignationRepository,
+) *EmploymentHistoryService {
+	return &EmploymentHistoryService{
+		historyRepo:     historyRepo,
+		employeeRepo:    employeeRepo,
+		departmentRepo:  departmentRepo,
+		designationRepo: designationRepo,
+	}
+}
+
+// today returns the current UTC calendar date at midnight
+func today(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func parseAsOfDate(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return today(now), nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, &utils.ValidationError{Field: "as_of", Message: "Date must be in YYYY-MM-DD format"}
+	}
+	return date, nil
+}
+
+// applyEmploymentRecord sets the fields the record changes on employee
+func applyEmploymentRecord(employee *models.Employee, record *models.EmploymentHistory) {
+	for _, field := range record.ChangedFields {
+		switch field {
+		case historyFieldDepartment:
+			if record.DepartmentID != nil {
+				employee.DepartmentID = *record.DepartmentID
+			}
+		case historyFieldDesignation:
+			if record.DesignationID != nil {
+				employee.DesignationID = *record.DesignationID
+			}
+		case historyFieldManager:
+			employee.ManagerID = record.ManagerID
+		case historyFieldEmploymentType:
+			employee.EmploymentType = record.EmploymentType
+		case historyFieldSalary:
+			employee.Salary = record.Salary
+			employee.SalaryCurrency = record.SalaryCurrency
+		}
+	}
+}
+
+// foldEmploymentHistory applies every non-cancelled record effective on or
+// before date, in order, on top of employee. Salary is only ever set through
+// history, so it starts empty. It reports whether any record applied, i.e.
+// whether the employee had been hired by date.
+func foldEmploymentHistory(employee *models.Employee, history []models.EmploymentHistory, date time.Time) bool {
+	employee.Salary = nil
+	employee.SalaryCurrency = ""
+
+	found := false
+	for i := range history {
+		if history[i].Status == "cancelled" || history[i].EffectiveDate.After(date) {
+			continue
+		}
+		applyEmploymentRecord(employee, &history[i])
+		found = true
+	}
+	return found
+}
+
+func employmentStateResponse(employee *models.Employee, date time.Time, includeSalary bool) *dto.EmploymentStateResponse {
+	response := &dto.EmploymentStateResponse{
+		EmployeeID:     employee.ID,
+		FirstName:      employee.FirstName,
+		LastName:       employee.LastName,
+		AsOf:           date.Format("2006-01-02"),
+		DepartmentID:   employee.DepartmentID,
+		DesignationID:  employee.DesignationID,
+		ManagerID:      employee.ManagerID,
+		EmploymentType: employee.EmploymentType,
+	}
+	if includeSalary {
+		response.Salary = employee.Salary
+		response.SalaryCurrency = employee.SalaryCurrency
+	}
+	return response
+}
+
+func historyResponse(record *models.EmploymentHistory, includeSalary bool) *dto.EmploymentHistoryResponse {
+	response := record.ToResponse()
+	if !includeSalary {
+		response.Salary = nil
+		response.SalaryCurrency = ""
+		if containsString(record.ChangedFields, historyFieldSalary) {
+			fields := make([]string, 0, len(record.ChangedFields))
+			for _, field := range record.ChangedFields {
+				if field != historyFieldSalary {
+					fields = append(fields, field)
+				}
+			}
+			response.ChangedFields = fields
+		}
+	}
+	return response
+}
+
+func (hs *EmploymentHistoryService) ListHistory(ctx context.Context, actor Actor, employeeID int) ([]*dto.EmploymentHistoryResponse, error) {
+	employee, err := hs.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	access, err := profileAccessFor(actor, employee)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := hs.historyRepo.ListEmploymentHistory(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing employment history: %w", err)
+	}
+
+	responses := make([]*dto.EmploymentHistoryResponse, 0, len(history))
+	for i := range history {
+		response := historyResponse(&history[i], access.viewSensitive)
+		// Salary-only changes would be empty without salary access
+		if len(response.ChangedFields) == 0 {
+			continue
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// validateEmploymentChange checks the request and builds the history record
+// it describes, without touching the database.
+func validateEmploymentChange(req *dto.EmploymentChangeRequest) (*models.EmploymentHistory, error) {
+	if !containsString(validChangeTypes, req.ChangeType) {
+		return nil, &utils.ValidationError{Field: "change_type", Message: "Change type must be one of " + strings.Join(validChangeTypes, ", ")}
+	}
+
+	effectiveDate, err := time.Parse("2006-01-02", req.EffectiveDate)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "effective_date", Message: "Effective date must be in YYYY-MM-DD format"}
+	}
+
+	record := &models.EmploymentHistory{
+		ChangeType:    req.ChangeType,
+		EffectiveDate: effectiveDate,
+		Reason:        strings.TrimSpace(req.Reason),
+	}
+
+	if req.DepartmentID != nil {
+		record.DepartmentID = req.DepartmentID
+		record.ChangedFields = append(record.ChangedFields, historyFieldDepartment)
+	}
+	if req.DesignationID != nil {
+		record.DesignationID = req.DesignationID
+		record.ChangedFields = append(record.ChangedFields, historyFieldDesignation)
+	}
+	if req.ManagerID != nil && req.ClearManager {
+		return nil, &utils.ValidationError{Field: "manager_id", Message: "Set either manager_id or clear_manager, not both"}
+	}
+	if req.ManagerID != nil || req.ClearManager {
+		record.ManagerID = req.ManagerID
+		record.ChangedFields = append(record.ChangedFields, historyFieldManager)
+	}
+	if req.EmploymentType != nil {
+		if !containsString(validEmploymentTypes, *req.EmploymentType) {
+			return nil, &utils.ValidationError{Field: "employment_type", Message: "Employment type must be one of " + strings.Join(validEmploymentTypes, ", ")}
+		}
+		record.EmploymentType = *req.EmploymentType
+		record.ChangedFields = append(record.ChangedFields, historyFieldEmploymentType)
+	}
+	if req.Salary != nil {
+		if *req.Salary < 0 {
+			return nil, &utils.ValidationError{Field: "salary", Message: "Salary cannot be negative"}
+		}
+		if req.SalaryCurrency == nil || !currencyCodeRegexp.MatchString(strings.ToUpper(*req.SalaryCurrency)) {
+			return nil, &utils.ValidationError{Field: "salary_currency", Message: "Salary currency must be a three-letter ISO code"}
+		}
+		record.Salary = req.Salary
+		record.SalaryCurrency = strings.ToUpper(*req.SalaryCurrency)
+		record.ChangedFields = append(record.ChangedFields, historyFieldSalary)
+	}
+
+	if len(record.ChangedFields) == 0 {
+		return nil, &utils.ValidationError{Field: "change", Message: "At least one of department, designation, manager, employment type or salary must change"}
+	}
+
+	return record, nil
+}
+
+// checkReferences verifies that the department, designation and manager named
+// by record belong to the tenant and that the manager change would not create
+// a reporting cycle.
+func (hs *EmploymentHistoryService) checkReferences(ctx context.Context, tenantID int, employeeID int, record *models.EmploymentHistory) error {
+	if record.DepartmentID != nil {
+		department, err := hs.departmentRepo.GetDepartmentByID(ctx, *record.DepartmentID)
+		if err != nil || department.TenantID != tenantID {
+			return &utils.ValidationError{Field: "department_id", Message: "Department not found"}
+		}
+	}
+	if record.DesignationID != nil {
+		designation, err := hs.designationRepo.GetDesignationByID(ctx, *record.DesignationID)
+		if err != nil || designation.TenantID != tenantID {
+			return &utils.ValidationError{Field: "designation_id", Message: "Designation not found"}
+		}
+	}
+	if record.ManagerID != nil {
+		managerID := *record.ManagerID
+		for depth := 0; depth < maxManagerChainDepth; depth++ {
+			if managerID == employeeID {
+				return &utils.ValidationError{Field: "manager_id", Message: "An employee cannot report to themselves or to someone who reports to them"}
+			}
+			manager, err := hs.employeeRepo.GetEmployeeByID(ctx, tenantID, managerID)
+			if err != nil {
+				if depth == 0 {
+					return &utils.ValidationError{Field: "manager_id", Message: "Manager not found"}
+				}
+				break
+			}
+			if manager.ManagerID == nil {
+				break
+			}
+			managerID = *manager.ManagerID
+		}
+	}
+	return nil
+}
+
+func (hs *EmploymentHistoryService) ScheduleChange(ctx context.Context, actor Actor, employeeID int, req *dto.EmploymentChangeRequest) (*dto.EmploymentHistoryResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	if _, err := hs.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID); err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+
+	record, err := validateEmploymentChange(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := hs.checkReferences(ctx, actor.TenantID, employeeID, record); err != nil {
+		return nil, err
+	}
+
+	record.TenantID = actor.TenantID
+	record.EmployeeID = employeeID
+	record.Status = "scheduled"
+	record.CreatedBy = &actor.EmployeeID
+
+	created, err := hs.historyRepo.CreateEmploymentChange(ctx, record)
+	if err != nil {
+		return nil, fmt.Errorf("error scheduling employment change: %w", err)
+	}
+
+	// Changes effective today or earlier take effect straight away
+	now := time.Now()
+	if !created.EffectiveDate.After(today(now)) {
+		if _, err := hs.applyEmployee(ctx, actor.TenantID, employeeID, now); err != nil {
+			return nil, fmt.Errorf("error applying employment change: %w", err)
+		}
+		created.Status = "applied"
+		created.AppliedAt = &now
+	}
+
+	return created.ToResponse(), nil
+}
+
+func (hs *EmploymentHistoryService) CancelChange(ctx context.Context, actor Actor, employeeID int, changeID int) (*dto.EmploymentHistoryResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	cancelled, err := hs.historyRepo.CancelEmploymentChange(ctx, actor.TenantID, employeeID, changeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("scheduled employment change %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling employment change: %w", err)
+	}
+	return cancelled.ToResponse(), nil
+}
+
+func (hs *EmploymentHistoryService) GetEmploymentAsOf(ctx context.Context, actor Actor, employeeID int, date string) (*dto.EmploymentStateResponse, error) {
+	asOf, err := parseAsOfDate(date, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := hs.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	access, err := profileAccessFor(actor, employee)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := hs.historyRepo.ListEmploymentHistory(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing employment history: %w", err)
+	}
+	if !foldEmploymentHistory(employee, history, asOf) {
+		return nil, fmt.Errorf("employment on %s %w", asOf.Format("2006-01-02"), ErrNotFound)
+	}
+
+	return employmentStateResponse(employee, asOf, access.viewSensitive), nil
+}
+
+// GetOrgAsOf returns every employee's job as it stood on date. Employees not
+// yet hired by date are left out.
+func (hs *EmploymentHistoryService) GetOrgAsOf(ctx context.Context, actor Actor, date string) ([]*dto.EmploymentStateResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	asOf, err := parseAsOfDate(date, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	employees, err := hs.employeeRepo.ListEmployees(ctx, actor.TenantID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error listing employees: %w", err)
+	}
+
+	history, err := hs.historyRepo.ListTenantHistoryUntil(ctx, actor.TenantID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("error listing employment history: %w", err)
+	}
+
+	byEmployee := map[int][]models.EmploymentHistory{}
+	for _, record := range history {
+		byEmployee[record.EmployeeID] = append(byEmployee[record.EmployeeID], record)
+	}
+
+	states := []*dto.EmploymentStateResponse{}
+	for i := range employees {
+		employee := employees[i]
+		if !foldEmploymentHistory(&employee, byEmployee[employee.ID], asOf) {
+			continue
+		}
+		states = append(states, employmentStateResponse(&employee, asOf, true))
+	}
+	return states, nil
+}
+
+// applyEmployee brings the employee's current job in line with their history
+func (hs *EmploymentHistoryService) applyEmployee(ctx context.Context, tenantID int, employeeID int, now time.Time) (bool, error) {
+	date := today(now)
+	return hs.historyRepo.ApplyEmploymentChanges(ctx, tenantID, employeeID, date, func(employee *models.Employee, history []models.EmploymentHistory) {
+		foldEmploymentHistory(employee, history, date)
+	})
+}
+
+// ApplyDueChanges applies every scheduled change whose effective date has
+// arrived and returns the number of employees updated. It is safe to run
+// repeatedly and from several instances at once.
+func (hs *EmploymentHistoryService) ApplyDueChanges(ctx context.Context, now time.Time) (int, error) {
+	due, err := hs.historyRepo.ListDueEmployees(ctx, today(now))
+	if err != nil {
+		return 0, fmt.Errorf("error listing due employment changes: %w", err)
+	}
+
+	applied := 0
+	for _, d := range due {
+		ok, err := hs.applyEmployee(ctx, d.TenantID, d.EmployeeID, now)
+		if err != nil {
+			log.Printf("employment changes for employee %d: %v", d.EmployeeID, err)
+			continue
+		}
+		if ok {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// RunScheduler applies due employment changes now and then every interval
+// until ctx is cancelled.
+func (hs *EmploymentHistoryService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if applied, err := hs.ApplyDueChanges(ctx, time.Now()); err != nil {
+			log.Printf("employment change scheduler: %v", err)
+		} else if applied > 0 {
+			log.Printf("employment change scheduler: updated %d employees", applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordHire starts a new employee's history with their initial job
+func (hs *EmploymentHistoryService) recordHire(ctx context.Context, employee *models.Employee, createdBy int, now time.Time) error {
+	effectiveDate := today(now)
+	if employee.HireDate != nil {
+		effectiveDate = *employee.HireDate
+	}
+
+	_, err := hs.historyRepo.CreateEmploymentChange(ctx, &models.EmploymentHistory{
+		TenantID:       employee.TenantID,
+		EmployeeID:     employee.ID,
+		ChangeType:     "hire",
+		EffectiveDate:  effectiveDate,
+		ChangedFields:  []string{historyFieldDepartment, historyFieldDesignation, historyFieldManager, historyFieldEmploymentType},
+		DepartmentID:   &employee.DepartmentID,
+		DesignationID:  &employee.DesignationID,
+		ManagerID:      employee.ManagerID,
+		EmploymentType: employee.EmploymentType,
+		Status:         "applied",
+		CreatedBy:      &createdBy,
+		AppliedAt:      &now,
+	})
+	return err
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+func date(value string) time.Time {
+	d, _ := time.Parse("2006-01-02", value)
+	return d
+}
+
+func employmentHistoryFixture() []models.EmploymentHistory {
+	sales, engineering := 1, 2
+	associate, lead := 10, 11
+	manager := 7
+	salary := 50000.0
+	return []models.EmploymentHistory{
+		{ID: 1, ChangeType: "hire", EffectiveDate: date("2023-01-09"), Status: "applied", ChangedFields: []string{"department_id", "designation_id", "manager_id", "employment_type"}, DepartmentID: &sales, DesignationID: &associate, ManagerID: &manager, EmploymentType: "full_time"},
+		{ID: 2, ChangeType: "compensation_change", EffectiveDate: date("2023-07-01"), Status: "applied", ChangedFields: []string{"salary"}, Salary: &salary, SalaryCurrency: "NGN"},
+		{ID: 3, ChangeType: "transfer", EffectiveDate: date("2024-03-01"), Status: "applied", ChangedFields: []string{"department_id", "manager_id"}, DepartmentID: &engineering},
+		{ID: 4, ChangeType: "promotion", EffectiveDate: date("2025-01-01"), Status: "cancelled", ChangedFields: []string{"designation_id"}, DesignationID: &lead},
+		{ID: 5, ChangeType: "promotion", EffectiveDate: date("2026-01-01"), Status: "scheduled", ChangedFields: []string{"designation_id"}, DesignationID: &lead},
+	}
+}
+
+func TestFoldEmploymentHistory(t *testing.T) {
+	history := employmentHistoryFixture()
+
+	t.Run("not hired before the first record", func(t *testing.T) {
+		employee := &models.Employee{ID: 3}
+		if foldEmploymentHistory(employee, history, date("2022-12-31")) {
+			t.Errorf("got hired, want no employment before 2023-01-09")
+		}
+	})
+
+	t.Run("state after hire has no salary yet", func(t *testing.T) {
+		employee := &models.Employee{ID: 3}
+		foldEmploymentHistory(employee, history, date("2023-03-01"))
+		if employee.DepartmentID != 1 || employee.DesignationID != 10 || employee.ManagerID == nil || *employee.ManagerID != 7 || employee.Salary != nil {
+			t.Errorf("got %+v, want sales associate reporting to 7 with no salary", employee)
+		}
+	})
+
+	t.Run("transfer clears the manager and keeps the salary", func(t *testing.T) {
+		employee := &models.Employee{ID: 3}
+		foldEmploymentHistory(employee, history, date("2024-06-01"))
+		if employee.DepartmentID != 2 || employee.ManagerID != nil || employee.Salary == nil || *employee.Salary != 50000 {
+			t.Errorf("got %+v, want engineering with no manager and salary 50000", employee)
+		}
+	})
+
+	t.Run("cancelled records are ignored and scheduled ones apply on their date", func(t *testing.T) {
+		employee := &models.Employee{ID: 3}
+		foldEmploymentHistory(employee, history, date("2025-06-01"))
+		if employee.DesignationID != 10 {
+			t.Errorf("got designation %d, want 10 before the scheduled promotion", employee.DesignationID)
+		}
+
+		foldEmploymentHistory(employee, history, date("2026-01-01"))
+		if employee.DesignationID != 11 {
+			t.Errorf("got designation %d, want 11 from the scheduled promotion", employee.DesignationID)
+		}
+	})
+}
+
+func TestValidateEmploymentChange(t *testing.T) {
+	department := 4
+	manager := 9
+	contract := "contract"
+	unknownType := "freelance"
+	salary := 1200.0
+	currency := "usd"
+
+	cases := []struct {
+		name       string
+		req        dto.EmploymentChangeRequest
+		wantFields []string
+		wantErr    bool
+	}{
+		{"transfer", dto.EmploymentChangeRequest{ChangeType: "transfer", EffectiveDate: "2026-01-01", DepartmentID: &department, ManagerID: &manager}, []string{"department_id", "manager_id"}, false},
+		{"clear manager", dto.EmploymentChangeRequest{ChangeType: "manager_change", EffectiveDate: "2026-01-01", ClearManager: true}, []string{"manager_id"}, false},
+		{"salary with currency", dto.EmploymentChangeRequest{ChangeType: "compensation_change", EffectiveDate: "2026-01-01", Salary: &salary, SalaryCurrency: &currency}, []string{"salary"}, false},
+		{"employment type", dto.EmploymentChangeRequest{ChangeType: "employment_type_change", EffectiveDate: "2026-01-01", EmploymentType: &contract}, []string{"employment_type"}, false},
+		{"salary without currency", dto.EmploymentChangeRequest{ChangeType: "compensation_change", EffectiveDate: "2026-01-01", Salary: &salary}, nil, true},
+		{"unknown employment type", dto.EmploymentChangeRequest{ChangeType: "employment_type_change", EffectiveDate: "2026-01-01", EmploymentType: &unknownType}, nil, true},
+		{"manager set and cleared", dto.EmploymentChangeRequest{ChangeType: "manager_change", EffectiveDate: "2026-01-01", ManagerID: &manager, ClearManager: true}, nil, true},
+		{"nothing changes", dto.EmploymentChangeRequest{ChangeType: "transfer", EffectiveDate: "2026-01-01"}, nil, true},
+		{"hire is not schedulable", dto.EmploymentChangeRequest{ChangeType: "hire", EffectiveDate: "2026-01-01", DepartmentID: &department}, nil, true},
+		{"bad date", dto.EmploymentChangeRequest{ChangeType: "transfer", EffectiveDate: "01/01/2026", DepartmentID: &department}, nil, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			record, err := validateEmploymentChange(&tc.req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(record.ChangedFields) != len(tc.wantFields) {
+				t.Fatalf("got fields %v, want %v", record.ChangedFields, tc.wantFields)
+			}
+			for i, field := range tc.wantFields {
+				if record.ChangedFields[i] != field {
+					t.Errorf("got fields %v, want %v", record.ChangedFields, tc.wantFields)
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryResponseHidesSalary(t *testing.T) {
+	salary := 50000.0
+	record := &models.EmploymentHistory{ChangedFields: []string{"designation_id", "salary"}, Salary: &salary, SalaryCurrency: "NGN"}
+
+	response := historyResponse(record, false)
+	if response.Salary != nil || response.SalaryCurrency != "" {
+		t.Errorf("got salary %v %s, want hidden", response.Salary, response.SalaryCurrency)
+	}
+	if len(response.ChangedFields) != 1 || response.ChangedFields[0] != "designation_id" {
+		t.Errorf("got fields %v, want only designation_id", response.ChangedFields)
+	}
+
+	if full := historyResponse(record, true); full.Salary == nil {
+		t.Errorf("got no salary, want salary with access")
+	}
+}
@@ -0,0 +1,12 @@
+package services
+
+import "errors"
+
+var (
+	// ErrForbidden is returned when the actor may not see or change a resource
+	ErrForbidden = errors.New("you do not have permission to access this resource")
+	// ErrNotFound is returned when a tenant-scoped resource does not exist
+	ErrNotFound = errors.New("resource not found")
+	// ErrConflict is returned when a resource changed since the actor read it
+	ErrConflict = errors.New("the resource was changed by someone else; reload it and try again")
+)
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type IExportService interface {
+	ExportEmployees(ctx context.Context, tenantID int, req *dto.EmployeeExportRequest, w io.Writer) error
+	CreateEmployeeExportJob(ctx context.Context, tenantID int, requestedBy int, req *dto.EmployeeExportRequest) (*dto.ExportJobResponse, error)
+	GetExportJob(ctx context.Context, tenantID int, jobID int) (*dto.ExportJobResponse, error)
+	OpenExportJobFile(ctx context.Context, tenantID int, jobID int) (io.ReadCloser, *models.ExportJob, error)
+}
+
+type ExportService struct {
+	employeeRepo       *repositories.EmployeeRepository
+	exportJobRepo      *repositories.ExportJobRepository
+	customFieldService *CustomFieldService
+	exportDir          string
+	wake               chan struct{}
+}
+
+func NewExportService(employeeRepo *repositories.EmployeeRepository, exportJobRepo *repositories.ExportJobRepository, customFieldService *CustomFieldService, exportDir string) *ExportService {
+	return &ExportService{
+		employeeRepo:       employeeRepo,
+		exportJobRepo:      exportJobRepo,
+		customFieldService: customFieldService,
+		exportDir:          exportDir,
+		wake:               make(chan struct{}, 1),
+	}
+}
+
+type employeeExportColumn struct {
+	name  string
+	value func(row *models.EmployeeExportRow) string
+}
+
+func formatOptionalInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// employeeExportColumns lists every exportable column in default output order
+var employeeExportColumns = []employeeExportColumn{
+	{"id", func(r *models.EmployeeExportRow) string { return strconv.Itoa(r.ID) }},
+	{"first_name", func(r *models.EmployeeExportRow) string { return r.FirstName }},
+	{"last_name", func(r *models.EmployeeExportRow) string { return r.LastName }},
+	{"email", func(r *models.EmployeeExportRow) string { return r.Email }},
+	{"phone", func(r *models.EmployeeExportRow) string { return r.Phone }},
+	{"status", func(r *models.EmployeeExportRow) string { return r.Status }},
+	{"hire_date", func(r *models.EmployeeExportRow) string {
+		if r.HireDate == nil {
+			return ""
+		}
+		return r.HireDate.Format("2006-01-02")
+	}},
+	{"department_id", func(r *models.EmployeeExportRow) string { return strconv.Itoa(r.DepartmentID) }},
+	{"department_name", func(r *models.EmployeeExportRow) string { return r.DepartmentName }},
+	{"designation_id", func(r *models.EmployeeExportRow) string { return strconv.Itoa(r.DesignationID) }},
+	{"designation_name", func(r *models.EmployeeExportRow) string { return r.DesignationName }},
+	{"manager_id", func(r *models.EmployeeExportRow) string { return formatOptionalInt(r.ManagerID) }},
+	{"manager_name", func(r *models.EmployeeExportRow) string { return r.ManagerName }},
+	{"role_name", func(r *models.EmployeeExportRow) string { return r.RoleName }},
+	{"created_at", func(r *models.EmployeeExportRow) string { return r.CreatedAt.Format("2006-01-02T15:04:05Z07:00") }},
+}
+
+// customFieldExportColumns lists one "cf.<key>" column per custom field
+func customFieldExportColumns(fields []models.CustomFieldDefinition) []employeeExportColumn {
+	columns := make([]employeeExportColumn, len(fields))
+	for i := range fields {
+		key := fields[i].Key
+		columns[i] = employeeExportColumn{"cf." + key, func(r *models.EmployeeExportRow) string {
+			return formatCustomFieldValue(r.CustomFields[key])
+		}}
+	}
+	return columns
+}
+
+// resolveEmployeeExportColumns validates the requested column names, falling
+// back to every column when none are given.
+func resolveEmployeeExportColumns(names []string, fields []models.CustomFieldDefinition) ([]employeeExportColumn, error) {
+	available := append(append([]employeeExportColumn{}, employeeExportColumns...), customFieldExportColumns(fields)...)
+	if len(names) == 0 {
+		return available, nil
+	}
+
+	columns := make([]employeeExportColumn, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, column := range available {
+			if column.name == name {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, &utils.ValidationError{Field: "columns", Message: fmt.Sprintf("Unknown export column: %s", name)}
+		}
+	}
+	return columns, nil
+}
+
+// validateEmployeeExportRequest checks the format, normalizes custom field
+// filters and resolves the output columns. Exports are HR-only, so every
+// custom field may be filtered on and exported.
+func (xs *ExportService) validateEmployeeExportRequest(ctx context.Context, tenantID int, req *dto.EmployeeExportRequest) ([]employeeExportColumn, error) {
+	if !utils.IsValidExportFormat(req.Format) {
+		return nil, &utils.ValidationError{Field: "format", Message: "Format must be one of csv, xlsx or jsonl"}
+	}
+
+	fields, err := xs.customFieldService.employeeFields(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Filter.CustomFields, err = normalizeCustomFieldFilter(fields, req.Filter.CustomFields, true); err != nil {
+		return nil, err
+	}
+
+	return resolveEmployeeExportColumns(req.Columns, fields)
+}
+
+// writeEmployeeExport streams every matching employee into w and returns the
+// number of data rows written.
+func (xs *ExportService) writeEmployeeExport(ctx context.Context, tenantID int, req *dto.EmployeeExportRequest, columns []employeeExportColumn, w io.Writer) (int, error) {
+	writer, err := utils.NewTabularWriter(req.Format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := writer.WriteHeader(header); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = xs.employeeRepo.StreamEmployeeExportRows(ctx, tenantID, &req.Filter, func(row *models.EmployeeExportRow) error {
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = column.value(row)
+		}
+		count++
+		return writer.WriteRow(values)
+	})
+	if err != nil {
+		return count, fmt.Errorf("error reading employees: %w", err)
+	}
+
+	return count, writer.Close()
+}
+
+func (xs *ExportService) ExportEmployees(ctx context.Context, tenantID int, req *dto.EmployeeExportRequest, w io.Writer) error {
+	columns, err := xs.validateEmployeeExportRequest(ctx, tenantID, req)
+	if err != nil {
+		return err
+	}
+
+	_, err = xs.writeEmployeeExport(ctx, tenantID, req, columns, w)
+	return err
+}
+
+func (xs *ExportService) CreateEmployeeExportJob(ctx context.Context, tenantID int, requestedBy int, req *dto.EmployeeExportRequest) (*dto.ExportJobResponse, error) {
+	columns, err := xs.validateEmployeeExportRequest(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+	}
+
+	filters, err := json.Marshal(req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding filters: %w", err)
+	}
+
+	job, err := xs.exportJobRepo.CreateExportJob(ctx, &models.ExportJob{
+		TenantID:    tenantID,
+		RequestedBy: &requestedBy,
+		EntityType:  "employees",
+		Format:      req.Format,
+		Columns:     strings.Join(names, ","),
+		Filters:     filters,
+		Status:      "pending",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating export job: %w", err)
+	}
+
+	// The worker picks the job up from the table; waking it saves waiting for
+	// the next tick
+	select {
+	case xs.wake <- struct{}{}:
+	default:
+	}
+
+	return job.ToResponse(), nil
+}
+
+// exportJobStaleAfter is how long a job may stay running before it is taken
+// to have been abandoned by a process that stopped mid-export
+const exportJobStaleAfter = time.Hour
+
+// RunScheduler runs pending export jobs until ctx is cancelled, checking the
+// table every interval and whenever a job is created. Jobs left running by a
+// stopped process are marked failed.
+func (xs *ExportService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if failed, err := xs.exportJobRepo.FailStaleExportJobs(ctx, time.Now().Add(-exportJobStaleAfter), "Export was interrupted"); err != nil {
+			log.Printf("export scheduler: %v", err)
+		} else if failed > 0 {
+			log.Printf("export scheduler: marked %d interrupted export jobs failed", failed)
+		}
+
+		if err := xs.runPendingExportJobs(ctx); err != nil {
+			log.Printf("export scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-xs.wake:
+		}
+	}
+}
+
+// runPendingExportJobs claims and runs pending jobs one at a time until none
+// is left or ctx is cancelled
+func (xs *ExportService) runPendingExportJobs(ctx context.Context) error {
+	for ctx.Err() == nil {
+		job, err := xs.exportJobRepo.ClaimPendingExportJob(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error claiming export job: %w", err)
+		}
+		xs.runEmployeeExportJob(ctx, job)
+	}
+	return nil
+}
+
+// employeeExportJobRequest rebuilds the request an export job was created from
+func employeeExportJobRequest(job *models.ExportJob) (*dto.EmployeeExportRequest, error) {
+	req := &dto.EmployeeExportRequest{Format: job.Format}
+	if job.Columns != "" {
+		req.Columns = strings.Split(job.Columns, ",")
+	}
+	if len(job.Filters) > 0 {
+		if err := json.Unmarshal(job.Filters, &req.Filter); err != nil {
+			return nil, fmt.Errorf("error decoding filters: %w", err)
+		}
+	}
+	return req, nil
+}
+
+func (xs *ExportService) runEmployeeExportJob(ctx context.Context, job *models.ExportJob) {
+	jobID, tenantID := job.ID, job.TenantID
+
+	// A job cut short by shutdown goes back to the queue; its status is
+	// updated even though ctx is already cancelled
+	fail := func(err error) {
+		if ctx.Err() != nil {
+			log.Printf("export job %d interrupted: %v", jobID, err)
+			if requeueErr := xs.exportJobRepo.RequeueExportJob(context.WithoutCancel(ctx), jobID); requeueErr != nil {
+				log.Printf("export job %d: error requeueing: %v", jobID, requeueErr)
+			}
+			return
+		}
+		log.Printf("export job %d failed: %v", jobID, err)
+		if markErr := xs.exportJobRepo.MarkExportJobFailed(ctx, jobID, err.Error()); markErr != nil {
+			log.Printf("export job %d: error marking failed: %v", jobID, markErr)
+		}
+	}
+
+	req, err := employeeExportJobRequest(job)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	// Custom fields may have changed since the job was created
+	columns, err := xs.validateEmployeeExportRequest(ctx, tenantID, req)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	fileName := fmt.Sprintf("employees_%d.%s", jobID, req.Format)
+	dir := filepath.Join(xs.exportDir, strconv.Itoa(tenantID))
+	filePath := filepath.Join(dir, fileName)
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		fail(fmt.Errorf("error creating export directory: %w", err))
+		return
+	}
+
+	tmpPath := filePath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		fail(fmt.Errorf("error creating export file: %w", err))
+		return
+	}
+
+	count, err := xs.writeEmployeeExport(ctx, tenantID, req, columns, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		fail(err)
+		return
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		fail(fmt.Errorf("error saving export file: %w", err))
+		return
+	}
+
+	if err := xs.exportJobRepo.MarkExportJobCompleted(context.WithoutCancel(ctx), jobID, filePath, fileName, count); err != nil {
+		log.Printf("export job %d: error marking completed: %v", jobID, err)
+	}
+}
+
+func (xs *ExportService) GetExportJob(ctx context.Context, tenantID int, jobID int) (*dto.ExportJobResponse, error) {
+	job, err := xs.exportJobRepo.GetExportJobByID(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("export job not found: %w", err)
+	}
+	return job.ToResponse(), nil
+}
+
+func (xs *ExportService) OpenExportJobFile(ctx context.Context, tenantID int, jobID int) (io.ReadCloser, *models.ExportJob, error) {
+	job, err := xs.exportJobRepo.GetExportJobByID(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("export job not found: %w", err)
+	}
+
+	if job.Status != "completed" || job.FilePath == nil {
+		return nil, nil, fmt.Errorf("export job is not ready for download")
+	}
+
+	file, err := os.Open(*job.FilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening export file: %w", err)
+	}
+
+	return file, job, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+func TestEmployeeExportJobRequest(t *testing.T) {
+	filter := dto.EmployeeFilter{Status: "active", DepartmentID: 3, CustomFields: map[string]string{"shirt_size": "M"}}
+	filters, _ := json.Marshal(filter)
+
+	req, err := employeeExportJobRequest(&models.ExportJob{Format: "xlsx", Columns: "id,email,cf.shirt_size", Filters: filters})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Format != "xlsx" || len(req.Columns) != 3 || req.Columns[2] != "cf.shirt_size" {
+		t.Errorf("got %+v, want the job's format and columns", req)
+	}
+	if req.Filter.Status != "active" || req.Filter.DepartmentID != 3 || req.Filter.CustomFields["shirt_size"] != "M" {
+		t.Errorf("got filter %+v, want %+v", req.Filter, filter)
+	}
+
+	req, err = employeeExportJobRequest(&models.ExportJob{Format: "csv"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Columns != nil {
+		t.Errorf("got columns %v, want the default columns", req.Columns)
+	}
+
+	if _, err := employeeExportJobRequest(&models.ExportJob{Format: "csv", Filters: []byte("{")}); err == nil {
+		t.Error("got no error, want malformed filters rejected")
+	}
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Where a holiday came from
+const (
+	HolidaySourceBundled = "bundled"
+	HolidaySourceICal    = "ical"
+	HolidaySourceCustom  = "custom"
+)
+
+const (
+	// maxHolidayRangeDays limits how long a range holidays are listed for
+	maxHolidayRangeDays = 731
+	// maxImportedHolidays limits how many holidays one iCalendar file adds
+	maxImportedHolidays = 1000
+	maxLocationLength   = 100
+)
+
+type IHolidayService interface {
+	ListHolidays(ctx context.Context, tenantID int, from string, to string) ([]*dto.HolidayResponse, error)
+	ListEffectiveHolidays(ctx context.Context, tenantID int, location string, from string, to string) ([]*dto.HolidayResponse, error)
+	ListMyHolidays(ctx context.Context, actor Actor, from string, to string) ([]*dto.HolidayResponse, error)
+	CreateHoliday(ctx context.Context, tenantID int, req *dto.HolidayRequest) (*dto.HolidayResponse, error)
+	UpdateHoliday(ctx context.Context, tenantID int, id int, req *dto.HolidayRequest) (*dto.HolidayResponse, error)
+	DeleteHoliday(ctx context.Context, tenantID int, id int) error
+	LoadBundledHolidays(ctx context.Context, tenantID int, req *dto.LoadHolidaysRequest) (*dto.HolidayImportResponse, error)
+	ImportICalendar(ctx context.Context, tenantID int, location string, r io.Reader) (*dto.HolidayImportResponse, error)
+	UpdateWorkLocation(ctx context.Context, tenantID int, employeeID int, req *dto.WorkLocationRequest) (*dto.WorkLocationResponse, error)
+}
+
+type HolidayService struct {
+	holidayRepo *repositories.HolidayRepository
+	companyRepo *repositories.CompanyRepository
+}
+
+func NewHolidayService(holidayRepo *repositories.HolidayRepository, companyRepo *repositories.CompanyRepository) *HolidayService {
+	return &HolidayService{
+		holidayRepo: holidayRepo,
+		companyRepo: companyRepo,
+	}
+}
+
+// parseHolidayRange reads an inclusive date range. From defaults to the start
+// of the current year and to to the end of from's year.
+func parseHolidayRange(from string, to string, now time.Time) (time.Time, time.Time, error) {
+	start := time.Date(now.UTC().Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	if from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return time.Time{}, time.Time{}, &utils.ValidationError{Field: "from", Message: "From must be in YYYY-MM-DD format"}
+		}
+		start = parsed
+	}
+	end := time.Date(start.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return time.Time{}, time.Time{}, &utils.ValidationError{Field: "to", Message: "To must be in YYYY-MM-DD format"}
+		}
+		end = parsed
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, &utils.ValidationError{Field: "to", Message: "To cannot be before from"}
+	}
+	if end.Sub(start) > maxHolidayRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, &utils.ValidationError{Field: "to", Message: fmt.Sprintf("The range cannot be longer than %d days", maxHolidayRangeDays)}
+	}
+	return start, end, nil
+}
+
+func normalizeLocation(location string) (string, error) {
+	location = strings.TrimSpace(location)
+	if len(location) > maxLocationLength {
+		return "", &utils.ValidationError{Field: "location", Message: fmt.Sprintf("Location cannot be longer than %d characters", maxLocationLength)}
+	}
+	return location, nil
+}
+
+// effectiveHolidays resolves the holidays that apply at a location: on each
+// date the location's own holiday replaces the tenant-wide one, and dates the
+// location works on are dropped. Holidays of other locations are ignored.
+func effectiveHolidays(holidays []models.Holiday, location string) []models.Holiday {
+	byDate := map[time.Time]int{}
+	resolved := []models.Holiday{}
+	for _, holiday := range holidays {
+		if holiday.Location != "" && !strings.EqualFold(holiday.Location, location) {
+			continue
+		}
+		i, seen := byDate[holiday.Date]
+		if !seen {
+			byDate[holiday.Date] = len(resolved)
+			resolved = append(resolved, holiday)
+			continue
+		}
+		if holiday.Location != "" {
+			resolved[i] = holiday
+		}
+	}
+
+	daysOff := []models.Holiday{}
+	for _, holiday := range resolved {
+		if holiday.IsDayOff {
+			daysOff = append(daysOff, holiday)
+		}
+	}
+	return daysOff
+}
+
+func holidayResponses(holidays []models.Holiday) []*dto.HolidayResponse {
+	responses := make([]*dto.HolidayResponse, len(holidays))
+	for i := range holidays {
+		responses[i] = holidays[i].ToResponse()
+	}
+	return responses
+}
+
+// ListHolidays returns every holiday of the tenant in the range, including
+// each location's overrides
+func (hs *HolidayService) ListHolidays(ctx context.Context, tenantID int, from string, to string) ([]*dto.HolidayResponse, error) {
+	start, end, err := parseHolidayRange(from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := hs.holidayRepo.ListHolidays(ctx, tenantID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error listing holidays: %w", err)
+	}
+	return holidayResponses(holidays), nil
+}
+
+// ListEffectiveHolidays returns the days off in the range for people working
+// at the location, or for the whole tenant when location is empty
+func (hs *HolidayService) ListEffectiveHolidays(ctx context.Context, tenantID int, location string, from string, to string) ([]*dto.HolidayResponse, error) {
+	start, end, err := parseHolidayRange(from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := hs.effectiveHolidaysAt(ctx, tenantID, strings.TrimSpace(location), start, end)
+	if err != nil {
+		return nil, err
+	}
+	return holidayResponses(holidays), nil
+}
+
+// ListMyHolidays returns the days off in the range at the caller's work
+// location
+func (hs *HolidayService) ListMyHolidays(ctx context.Context, actor Actor, from string, to string) ([]*dto.HolidayResponse, error) {
+	start, end, err := parseHolidayRange(from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := hs.holidayRepo.GetWorkLocation(ctx, actor.TenantID, actor.EmployeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	holidays, err := hs.effectiveHolidaysAt(ctx, actor.TenantID, location, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return holidayResponses(holidays), nil
+}
+
+func (hs *HolidayService) effectiveHolidaysAt(ctx context.Context, tenantID int, location string, from time.Time, to time.Time) ([]models.Holiday, error) {
+	holidays, err := hs.holidayRepo.ListHolidays(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error loading holidays: %w", err)
+	}
+	return effectiveHolidays(holidays, location), nil
+}
+
+// holidayDates returns the days off between from and to at the employee's
+// work location, for counting leave days
+func (hs *HolidayService) holidayDates(ctx context.Context, tenantID int, employeeID int, from time.Time, to time.Time) ([]time.Time, error) {
+	location, err := hs.holidayRepo.GetWorkLocation(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	holidays, err := hs.effectiveHolidaysAt(ctx, tenantID, location, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, len(holidays))
+	for i, holiday := range holidays {
+		dates[i] = holiday.Date
+	}
+	return dates, nil
+}
+
+func buildHoliday(tenantID int, req *dto.HolidayRequest) (*models.Holiday, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "date", Message: "Date must be in YYYY-MM-DD format"}
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name is required"}
+	}
+	location, err := normalizeLocation(req.Location)
+	if err != nil {
+		return nil, err
+	}
+	isDayOff := true
+	if req.IsDayOff != nil {
+		isDayOff = *req.IsDayOff
+	}
+	if !isDayOff && location == "" {
+		return nil, &utils.ValidationError{Field: "is_day_off", Message: "Only a location can work on a holiday; delete the holiday to make it a working day for everyone"}
+	}
+
+	return &models.Holiday{
+		TenantID: tenantID,
+		Date:     date,
+		Name:     name,
+		Location: location,
+		IsDayOff: isDayOff,
+		Source:   HolidaySourceCustom,
+	}, nil
+}
+
+var errDuplicateHoliday = &utils.ValidationError{Field: "date", Message: "There is already a holiday on this date for this location"}
+
+func (hs *HolidayService) CreateHoliday(ctx context.Context, tenantID int, req *dto.HolidayRequest) (*dto.HolidayResponse, error) {
+	holiday, err := buildHoliday(tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := hs.holidayRepo.CreateHoliday(ctx, holiday)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errDuplicateHoliday
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating holiday: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+func (hs *HolidayService) UpdateHoliday(ctx context.Context, tenantID int, id int, req *dto.HolidayRequest) (*dto.HolidayResponse, error) {
+	holiday, err := buildHoliday(tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	holiday.ID = id
+
+	if _, err := hs.holidayRepo.GetHoliday(ctx, tenantID, id); err != nil {
+		return nil, notFoundOr(err, "holiday")
+	}
+	sameDay, err := hs.holidayRepo.ListHolidays(ctx, tenantID, holiday.Date, holiday.Date)
+	if err != nil {
+		return nil, fmt.Errorf("error checking holidays: %w", err)
+	}
+	for _, other := range sameDay {
+		if other.ID != id && other.Location == holiday.Location {
+			return nil, errDuplicateHoliday
+		}
+	}
+
+	updated, err := hs.holidayRepo.UpdateHoliday(ctx, holiday)
+	if err != nil {
+		return nil, notFoundOr(err, "holiday")
+	}
+	return updated.ToResponse(), nil
+}
+
+func (hs *HolidayService) DeleteHoliday(ctx context.Context, tenantID int, id int) error {
+	if err := hs.holidayRepo.DeleteHoliday(ctx, tenantID, id); err != nil {
+		return notFoundOr(err, "holiday")
+	}
+	return nil
+}
+
+// addHolidays saves public holidays as days off, skipping the dates the
+// location already has a holiday on
+func (hs *HolidayService) addHolidays(ctx context.Context, tenantID int, location string, source string, publicHolidays []utils.PublicHoliday) (*dto.HolidayImportResponse, error) {
+	holidays := make([]models.Holiday, len(publicHolidays))
+	for i, publicHoliday := range publicHolidays {
+		name := publicHoliday.Name
+		if name == "" {
+			name = "Holiday"
+		}
+		holidays[i] = models.Holiday{
+			TenantID: tenantID,
+			Date:     publicHoliday.Date,
+			Name:     name,
+			Location: location,
+			IsDayOff: true,
+			Source:   source,
+		}
+	}
+
+	added, err := hs.holidayRepo.AddHolidays(ctx, holidays)
+	if err != nil {
+		return nil, fmt.Errorf("error saving holidays: %w", err)
+	}
+	return &dto.HolidayImportResponse{Added: added, Skipped: len(holidays) - added}, nil
+}
+
+// LoadBundledHolidays adds a country's public holidays for a year. Loading a
+// year again only adds what is missing, so holidays edited since are kept.
+func (hs *HolidayService) LoadBundledHolidays(ctx context.Context, tenantID int, req *dto.LoadHolidaysRequest) (*dto.HolidayImportResponse, error) {
+	location, err := normalizeLocation(req.Location)
+	if err != nil {
+		return nil, err
+	}
+	year := req.Year
+	if year == 0 {
+		year = time.Now().UTC().Year()
+	}
+	if year < 2000 || year > 2100 {
+		return nil, &utils.ValidationError{Field: "year", Message: "Year must be between 2000 and 2100"}
+	}
+
+	country := strings.TrimSpace(req.Country)
+	if country == "" {
+		company, err := hs.companyRepo.GetCompanyByTenantID(ctx, tenantID)
+		if err != nil {
+			return nil, notFoundOr(err, "company")
+		}
+		country = company.Country
+	}
+	if !utils.HasBundledHolidays(country) {
+		return nil, &utils.ValidationError{Field: "country", Message: fmt.Sprintf("No holidays are bundled for %q; import an iCalendar file instead", country)}
+	}
+
+	return hs.addHolidays(ctx, tenantID, location, HolidaySourceBundled, utils.BundledHolidays(country, year))
+}
+
+// ImportICalendar adds the events of an iCalendar file as holidays
+func (hs *HolidayService) ImportICalendar(ctx context.Context, tenantID int, location string, r io.Reader) (*dto.HolidayImportResponse, error) {
+	location, err := normalizeLocation(location)
+	if err != nil {
+		return nil, err
+	}
+
+	publicHolidays, err := utils.ParseICalendar(r)
+	if errors.Is(err, utils.ErrInvalidICalendar) {
+		return nil, &utils.ValidationError{Field: "file", Message: "The file is not a valid iCalendar file"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading calendar: %w", err)
+	}
+	if len(publicHolidays) == 0 {
+		return nil, &utils.ValidationError{Field: "file", Message: "The calendar has no events"}
+	}
+	if len(publicHolidays) > maxImportedHolidays {
+		return nil, &utils.ValidationError{Field: "file", Message: fmt.Sprintf("A calendar can add at most %d holidays", maxImportedHolidays)}
+	}
+
+	return hs.addHolidays(ctx, tenantID, location, HolidaySourceICal, publicHolidays)
+}
+
+// UpdateWorkLocation sets where an employee works, which decides the holidays
+// their leave skips. An empty location clears it.
+func (hs *HolidayService) UpdateWorkLocation(ctx context.Context, tenantID int, employeeID int, req *dto.WorkLocationRequest) (*dto.WorkLocationResponse, error) {
+	location, err := normalizeLocation(req.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := hs.holidayRepo.UpdateWorkLocation(ctx, tenantID, employeeID, location); err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	return &dto.WorkLocationResponse{EmployeeID: employeeID, Location: location}, nil
+}
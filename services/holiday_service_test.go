@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestEffectiveHolidays(t *testing.T) {
+	holidays := []models.Holiday{
+		{ID: 1, Date: date("2026-01-01"), Name: "New Year's Day", IsDayOff: true},
+		{ID: 2, Date: date("2026-03-06"), Name: "Independence Day", IsDayOff: true},
+		{ID: 3, Date: date("2026-03-06"), Name: "Independence Day", Location: "Lagos", IsDayOff: false},
+		{ID: 4, Date: date("2026-04-10"), Name: "Company day off", IsDayOff: true},
+		{ID: 5, Date: date("2026-04-10"), Name: "Founders' Day", Location: "Accra", IsDayOff: true},
+		{ID: 6, Date: date("2026-05-27"), Name: "Lagos Day", Location: "Lagos", IsDayOff: true},
+	}
+
+	tests := []struct {
+		name     string
+		location string
+		want     []int
+	}{
+		{"tenant-wide only", "", []int{1, 2, 4}},
+		{"location works on a holiday and adds its own", "Lagos", []int{1, 4, 6}},
+		{"location renames a holiday", "accra", []int{1, 2, 5}},
+		{"location without overrides", "Abuja", []int{1, 2, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := effectiveHolidays(holidays, tt.location)
+			ids := make([]int, len(got))
+			for i, holiday := range got {
+				ids[i] = holiday.ID
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("got holidays %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("got holidays %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHolidayRange(t *testing.T) {
+	now := time.Date(2026, time.June, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		wantStart string
+		wantEnd   string
+		field     string
+	}{
+		{"defaults to the current year", "", "", "2026-01-01", "2026-12-31", ""},
+		{"to defaults to the end of from's year", "2027-03-01", "", "2027-03-01", "2027-12-31", ""},
+		{"explicit range", "2026-03-01", "2026-03-31", "2026-03-01", "2026-03-31", ""},
+		{"bad from", "March", "", "", "", "from"},
+		{"to before from", "2026-03-01", "2026-02-01", "", "", "to"},
+		{"range too long", "2026-01-01", "2028-06-01", "", "", "to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseHolidayRange(tt.from, tt.to, now)
+			if tt.field != "" {
+				var validationErr *utils.ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+					t.Fatalf("got error %v, want a validation error on %s", err, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !start.Equal(date(tt.wantStart)) || !end.Equal(date(tt.wantEnd)) {
+				t.Errorf("got %s to %s, want %s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"), tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestBuildHoliday(t *testing.T) {
+	working := false
+
+	t.Run("defaults to a day off", func(t *testing.T) {
+		holiday, err := buildHoliday(1, &dto.HolidayRequest{Date: "2026-12-24", Name: " Company day off ", Location: " Lagos "})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !holiday.IsDayOff || holiday.Name != "Company day off" || holiday.Location != "Lagos" || holiday.Source != HolidaySourceCustom {
+			t.Errorf("got %+v, want a custom day off in Lagos", holiday)
+		}
+	})
+
+	t.Run("a location can work on a holiday", func(t *testing.T) {
+		holiday, err := buildHoliday(1, &dto.HolidayRequest{Date: "2026-12-26", Name: "Boxing Day", Location: "Lagos", IsDayOff: &working})
+		if err != nil || holiday.IsDayOff {
+			t.Errorf("got %+v and error %v, want a working day", holiday, err)
+		}
+	})
+
+	t.Run("the whole tenant cannot work on a holiday", func(t *testing.T) {
+		_, err := buildHoliday(1, &dto.HolidayRequest{Date: "2026-12-26", Name: "Boxing Day", IsDayOff: &working})
+		var validationErr *utils.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != "is_day_off" {
+			t.Errorf("got error %v, want a validation error on is_day_off", err)
+		}
+	})
+
+	t.Run("rejects a bad date", func(t *testing.T) {
+		_, err := buildHoliday(1, &dto.HolidayRequest{Date: "24/12/2026", Name: "Christmas Eve"})
+		var validationErr *utils.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != "date" {
+			t.Errorf("got error %v, want a validation error on date", err)
+		}
+	})
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+type IInvitationService interface {
+	GetInvitationDetails(ctx context.Context, token string) (*dto.InvitationDetailsResponse, error)
+	SetUpInvitationMFA(ctx context.Context, token string) (*dto.InvitationMFAResponse, error)
+	AcceptInvitation(ctx context.Context, req *dto.AcceptInvitationRequest) (*dto.AcceptInvitationResponse, error)
+	ResendInvitation(ctx context.Context, tenantID int, invitationID int) (*dto.InvitationResponse, error)
+	RevokeInvitation(ctx context.Context, tenantID int, invitationID int) (*dto.InvitationResponse, error)
+}
+
+type InvitationService struct {
+	invitationRepo *repositories.InvitationRepository
+	employeeRepo   *repositories.EmployeeRepository
+	mailer         Mailer
+	secret         []byte
+	baseURL        string
+	ttl            time.Duration
+}
+
+func NewInvitationService(
+	invitationRepo *repositories.InvitationRepository,
+	employeeRepo *repositories.EmployeeRepository,
+	mailer Mailer,
+	secret string,
+	baseURL string,
+	ttl time.Duration,
+) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		employeeRepo:   employeeRepo,
+		mailer:         mailer,
+		secret:         []byte(secret),
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		ttl:            ttl,
+	}
+}
+
+var errInvalidInvitation = fmt.Errorf("invalid or expired invitation")
+
+// signInvitationToken builds "<id>.<expiry>.<nonce>.<signature>". The nonce
+// makes every resend produce a fresh link, and the signature lets us reject
+// forged or tampered links before touching the database.
+func signInvitationToken(secret []byte, invitationID int, expiresAt time.Time) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	payload := fmt.Sprintf("%d.%d.%s", invitationID, expiresAt.Unix(), base64.RawURLEncoding.EncodeToString(nonce))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// parseInvitationToken verifies the signature and expiry of a token and
+// returns the invitation ID it was issued for.
+func parseInvitationToken(secret []byte, token string, now time.Time) (int, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return 0, errInvalidInvitation
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return 0, errInvalidInvitation
+	}
+
+	invitationID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, errInvalidInvitation
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return 0, errInvalidInvitation
+	}
+
+	return invitationID, nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// InviteEmployee creates a pending invitation for a draft employee and emails
+// them the acceptance link.
+func (is *InvitationService) InviteEmployee(ctx context.Context, employee *models.Employee, invitedBy int) (*models.EmployeeInvitation, error) {
+	invitation, err := is.invitationRepo.CreateInvitation(ctx, &models.EmployeeInvitation{
+		TenantID:   employee.TenantID,
+		EmployeeID: employee.ID,
+		Status:     "pending",
+		ExpiresAt:  time.Now().Add(is.ttl),
+		InvitedBy:  &invitedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating invitation: %w", err)
+	}
+
+	return is.send(ctx, invitation, employee)
+}
+
+func (is *InvitationService) send(ctx context.Context, invitation *models.EmployeeInvitation, employee *models.Employee) (*models.EmployeeInvitation, error) {
+	expiresAt := time.Now().Add(is.ttl)
+	token, err := signInvitationToken(is.secret, invitation.ID, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error generating invitation token: %w", err)
+	}
+
+	updated, err := is.invitationRepo.RotateInvitationToken(ctx, invitation.ID, hashInvitationToken(token), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("error issuing invitation token: %w", err)
+	}
+
+	link := is.baseURL + "/invitations/accept?token=" + token
+	body := fmt.Sprintf(
+		"Hello %s,\n\nYou have been invited to join PeopleOS. Set your password using the link below:\n\n%s\n\nThis link expires on %s.\n",
+		employee.FirstName, link, expiresAt.Format(time.RFC1123),
+	)
+	if err := is.mailer.Send(ctx, employee.Email, "You're invited to PeopleOS", body); err != nil {
+		return nil, fmt.Errorf("error sending invitation: %w", err)
+	}
+
+	return updated, nil
+}
+
+// resolveToken returns the pending invitation a token refers to, rejecting
+// tokens that have been superseded by a resend.
+func (is *InvitationService) resolveToken(ctx context.Context, token string) (*models.EmployeeInvitation, error) {
+	invitationID, err := parseInvitationToken(is.secret, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := is.invitationRepo.GetInvitationByID(ctx, invitationID)
+	if err != nil {
+		return nil, errInvalidInvitation
+	}
+
+	if invitation.Status != "pending" || time.Now().After(invitation.ExpiresAt) {
+		return nil, errInvalidInvitation
+	}
+
+	if !hmac.Equal([]byte(invitation.TokenHash), []byte(hashInvitationToken(token))) {
+		return nil, errInvalidInvitation
+	}
+
+	return invitation, nil
+}
+
+func (is *InvitationService) GetInvitationDetails(ctx context.Context, token string) (*dto.InvitationDetailsResponse, error) {
+	invitation, err := is.resolveToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := is.employeeRepo.GetEmployeeByID(ctx, invitation.TenantID, invitation.EmployeeID)
+	if err != nil {
+		return nil, errInvalidInvitation
+	}
+
+	return &dto.InvitationDetailsResponse{
+		FirstName: employee.FirstName,
+		LastName:  employee.LastName,
+		Email:     employee.Email,
+		ExpiresAt: invitation.ExpiresAt,
+	}, nil
+}
+
+// SetUpInvitationMFA issues the MFA secret an invitee adds to their
+// authenticator app. It is only stored on the employee once they accept the
+// invitation with a code it generates; calling it again replaces the secret.
+func (is *InvitationService) SetUpInvitationMFA(ctx context.Context, token string) (*dto.InvitationMFAResponse, error) {
+	invitation, err := is.resolveToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := is.employeeRepo.GetEmployeeByID(ctx, invitation.TenantID, invitation.EmployeeID)
+	if err != nil {
+		return nil, errInvalidInvitation
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("error generating MFA secret: %w", err)
+	}
+	if err := is.invitationRepo.SetInvitationMFASecret(ctx, invitation.ID, secret); err != nil {
+		return nil, errInvalidInvitation
+	}
+
+	return &dto.InvitationMFAResponse{
+		MFASecret:          secret,
+		MFAProvisioningURI: utils.TOTPProvisioningURI("PeopleOS", employee.Email, secret),
+	}, nil
+}
+
+// invitationMFASecret returns the MFA secret to enrol when accepting an
+// invitation, checking that the invitee's code was generated from it
+func invitationMFASecret(invitation *models.EmployeeInvitation, req *dto.AcceptInvitationRequest, now time.Time) (*string, error) {
+	if !req.EnableMFA {
+		return nil, nil
+	}
+	if invitation.MFASecret == nil {
+		return nil, &utils.ValidationError{Field: "enable_mfa", Message: "Set up MFA before accepting the invitation"}
+	}
+	if !utils.ValidateTOTP(*invitation.MFASecret, strings.TrimSpace(req.MFACode), now) {
+		return nil, &utils.ValidationError{Field: "mfa_code", Message: "Invalid MFA code"}
+	}
+	return invitation.MFASecret, nil
+}
+
+func (is *InvitationService) AcceptInvitation(ctx context.Context, req *dto.AcceptInvitationRequest) (*dto.AcceptInvitationResponse, error) {
+	invitation, err := is.resolveToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := is.employeeRepo.GetEmployeeByID(ctx, invitation.TenantID, invitation.EmployeeID)
+	if err != nil {
+		return nil, errInvalidInvitation
+	}
+
+	mfaSecret, err := invitationMFASecret(invitation, req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	if err := is.invitationRepo.AcceptInvitation(ctx, invitation.ID, string(hashedPassword), mfaSecret); err != nil {
+		return nil, fmt.Errorf("error accepting invitation: %w", err)
+	}
+
+	return &dto.AcceptInvitationResponse{
+		EmployeeID: employee.ID,
+		Email:      employee.Email,
+		MFAEnabled: mfaSecret != nil,
+	}, nil
+}
+
+func (is *InvitationService) ResendInvitation(ctx context.Context, tenantID int, invitationID int) (*dto.InvitationResponse, error) {
+	invitation, err := is.invitationRepo.GetTenantInvitationByID(ctx, tenantID, invitationID)
+	if err != nil {
+		return nil, fmt.Errorf("invitation not found: %w", err)
+	}
+
+	if invitation.Status != "pending" {
+		return nil, fmt.Errorf("only pending invitations can be resent")
+	}
+
+	employee, err := is.employeeRepo.GetEmployeeByID(ctx, tenantID, invitation.EmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("employee not found: %w", err)
+	}
+
+	updated, err := is.send(ctx, invitation, employee)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated.ToResponse(), nil
+}
+
+func (is *InvitationService) RevokeInvitation(ctx context.Context, tenantID int, invitationID int) (*dto.InvitationResponse, error) {
+	invitation, err := is.invitationRepo.RevokeInvitation(ctx, tenantID, invitationID)
+	if err != nil {
+		return nil, fmt.Errorf("error revoking invitation: %w", err)
+	}
+	return invitation.ToResponse(), nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestInvitationToken(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Now()
+
+	t.Run("round-trips the invitation ID", func(t *testing.T) {
+		token, err := signInvitationToken(secret, 42, now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		id, err := parseInvitationToken(secret, token, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if id != 42 {
+			t.Errorf("got id %d, want %d", id, 42)
+		}
+	})
+
+	t.Run("rejects an expired token", func(t *testing.T) {
+		token, _ := signInvitationToken(secret, 42, now.Add(-time.Minute))
+
+		if _, err := parseInvitationToken(secret, token, now); err == nil {
+			t.Errorf("got nil error, want expiry error")
+		}
+	})
+
+	t.Run("rejects a token signed with another secret", func(t *testing.T) {
+		token, _ := signInvitationToken([]byte("other-secret"), 42, now.Add(time.Hour))
+
+		if _, err := parseInvitationToken(secret, token, now); err == nil {
+			t.Errorf("got nil error, want signature error")
+		}
+	})
+
+	t.Run("rejects a token whose ID was tampered with", func(t *testing.T) {
+		token, _ := signInvitationToken(secret, 42, now.Add(time.Hour))
+		tampered := "43" + token[2:]
+
+		if _, err := parseInvitationToken(secret, tampered, now); err == nil {
+			t.Errorf("got nil error, want signature error")
+		}
+	})
+
+	t.Run("issues a different token on every resend", func(t *testing.T) {
+		first, _ := signInvitationToken(secret, 42, now.Add(time.Hour))
+		second, _ := signInvitationToken(secret, 42, now.Add(time.Hour))
+
+		if first == second {
+			t.Errorf("got identical tokens, want distinct ones")
+		}
+	})
+}
+
+func TestInvitationMFASecret(t *testing.T) {
+	now := time.Now()
+	secret, _ := utils.GenerateTOTPSecret()
+	code, _ := utils.TOTPCode(secret, now)
+	set := &models.EmployeeInvitation{MFASecret: &secret}
+
+	t.Run("enrols no secret without MFA", func(t *testing.T) {
+		got, err := invitationMFASecret(set, &dto.AcceptInvitationRequest{}, now)
+		if err != nil || got != nil {
+			t.Errorf("got %v, %v, want no secret", got, err)
+		}
+	})
+
+	t.Run("enrols the secret confirmed by a code", func(t *testing.T) {
+		got, err := invitationMFASecret(set, &dto.AcceptInvitationRequest{EnableMFA: true, MFACode: code}, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got == nil || *got != secret {
+			t.Errorf("got %v, want the invitation's secret", got)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		invitation *models.EmployeeInvitation
+		code       string
+		wantField  string
+	}{
+		{"MFA not set up", &models.EmployeeInvitation{}, code, "enable_mfa"},
+		{"no code", set, "", "mfa_code"},
+		{"wrong code", set, "000000", "mfa_code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code == "000000" && code == "000000" {
+				t.Skip("the current code happens to be 000000")
+			}
+			_, err := invitationMFASecret(tt.invitation, &dto.AcceptInvitationRequest{EnableMFA: true, MFACode: tt.code}, now)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
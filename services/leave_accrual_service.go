@@ -0,0 +1,569 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+var validAccrualFrequencies = []string{"annual", "monthly", "pay_period"}
+
+type ILeaveAccrualService interface {
+	GetAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int) (*dto.LeaveAccrualPolicyResponse, error)
+	SaveAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int, req *dto.LeaveAccrualPolicyRequest) (*dto.LeaveAccrualPolicyResponse, error)
+	DeleteAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int) error
+	GetLeaveYear(ctx context.Context, tenantID int) (*dto.LeaveYearResponse, error)
+	UpdateLeaveYear(ctx context.Context, tenantID int, req *dto.LeaveYearRequest) (*dto.LeaveYearResponse, error)
+	ListLeaveBalances(ctx context.Context, actor Actor, employeeID int, year int) ([]*dto.LeaveBalanceResponse, error)
+	ListLeaveLedger(ctx context.Context, actor Actor, employeeID int, leaveTypeID int, year int) ([]*dto.LeaveLedgerEntryResponse, error)
+	AdjustLeaveBalance(ctx context.Context, actor Actor, req *dto.LeaveAdjustmentRequest) (*dto.LeaveLedgerEntryResponse, error)
+	RunAccruals(ctx context.Context, tenantID int, now time.Time) (*dto.LeaveAccrualRunResponse, error)
+}
+
+type LeaveAccrualService struct {
+	accrualRepo      *repositories.LeaveAccrualRepository
+	leaveRequestRepo *repositories.LeaveRequestRepository
+	leaveTypeRepo    *repositories.LeaveTypeRepository
+	employeeRepo     *repositories.EmployeeRepository
+}
+
+func NewLeaveAccrualService(
+	accrualRepo *repositories.LeaveAccrualRepository,
+	leaveRequestRepo *repositories.LeaveRequestRepository,
+	leaveTypeRepo *repositories.LeaveTypeRepository,
+	employeeRepo *repositories.EmployeeRepository,
+) *LeaveAccrualService {
+	return &LeaveAccrualService{
+		accrualRepo:      accrualRepo,
+		leaveRequestRepo: leaveRequestRepo,
+		leaveTypeRepo:    leaveTypeRepo,
+		employeeRepo:     employeeRepo,
+	}
+}
+
+// leaveYear returns the leave year date falls in. Leave years are named after
+// the calendar year they start in.
+func leaveYear(date time.Time, startMonth time.Month) int {
+	if date.Month() < startMonth {
+		return date.Year() - 1
+	}
+	return date.Year()
+}
+
+func leaveYearStart(year int, startMonth time.Month) time.Time {
+	return time.Date(year, startMonth, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func leaveYearResponse(startMonth time.Month, now time.Time) *dto.LeaveYearResponse {
+	year := leaveYear(today(now), startMonth)
+	start := leaveYearStart(year, startMonth)
+	return &dto.LeaveYearResponse{
+		StartMonth:  int(startMonth),
+		CurrentYear: year,
+		StartsOn:    start.Format("2006-01-02"),
+		EndsOn:      start.AddDate(1, 0, -1).Format("2006-01-02"),
+	}
+}
+
+func (la *LeaveAccrualService) GetLeaveYear(ctx context.Context, tenantID int) (*dto.LeaveYearResponse, error) {
+	startMonth, err := la.leaveRequestRepo.GetLeaveYearStartMonth(ctx, tenantID)
+	if err != nil {
+		return nil, notFoundOr(err, "company")
+	}
+	return leaveYearResponse(startMonth, time.Now()), nil
+}
+
+// UpdateLeaveYear changes the month the leave year starts in. Balances keep
+// the leave year they were recorded under, so this is meant to be set before
+// leave is first accrued.
+func (la *LeaveAccrualService) UpdateLeaveYear(ctx context.Context, tenantID int, req *dto.LeaveYearRequest) (*dto.LeaveYearResponse, error) {
+	if req.StartMonth < 1 || req.StartMonth > 12 {
+		return nil, &utils.ValidationError{Field: "start_month", Message: "Start month must be between 1 and 12"}
+	}
+	startMonth := time.Month(req.StartMonth)
+	if err := la.leaveRequestRepo.UpdateLeaveYearStartMonth(ctx, tenantID, startMonth); err != nil {
+		return nil, notFoundOr(err, "company")
+	}
+	return leaveYearResponse(startMonth, time.Now()), nil
+}
+
+func (la *LeaveAccrualService) GetAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int) (*dto.LeaveAccrualPolicyResponse, error) {
+	policy, err := la.accrualRepo.GetAccrualPolicy(ctx, tenantID, leaveTypeID)
+	if err != nil {
+		return nil, notFoundOr(err, "accrual policy")
+	}
+	return policy.ToResponse(), nil
+}
+
+// buildAccrualPolicy validates the request and turns it into a policy with
+// its tiers ordered by tenure
+func buildAccrualPolicy(tenantID int, leaveTypeID int, req *dto.LeaveAccrualPolicyRequest) (*models.LeaveAccrualPolicy, error) {
+	if !containsString(validAccrualFrequencies, req.Frequency) {
+		return nil, &utils.ValidationError{Field: "frequency", Message: "Frequency must be one of " + strings.Join(validAccrualFrequencies, ", ")}
+	}
+	payPeriods := 0
+	if req.Frequency == "pay_period" {
+		if req.PayPeriodsPerYear < 1 || req.PayPeriodsPerYear > 52 {
+			return nil, &utils.ValidationError{Field: "pay_periods_per_year", Message: "Pay periods per year must be between 1 and 52"}
+		}
+		payPeriods = req.PayPeriodsPerYear
+	}
+	if req.CarryOverMaxDays != nil && *req.CarryOverMaxDays < 0 {
+		return nil, &utils.ValidationError{Field: "carry_over_max_days", Message: "Maximum carry-over cannot be negative"}
+	}
+	if req.CarryOverExpiryMonths != nil && (*req.CarryOverExpiryMonths < 1 || *req.CarryOverExpiryMonths > 12) {
+		return nil, &utils.ValidationError{Field: "carry_over_expiry_months", Message: "Carry-over expiry must be between 1 and 12 months"}
+	}
+
+	tiers := make([]models.LeaveAccrualTier, 0, len(req.Tiers))
+	seen := map[int]bool{}
+	for _, tier := range req.Tiers {
+		if tier.MinTenureMonths < 0 {
+			return nil, &utils.ValidationError{Field: "tiers", Message: "Tier tenure cannot be negative"}
+		}
+		if tier.DaysPerYear < 0 || tier.DaysPerYear > 366 {
+			return nil, &utils.ValidationError{Field: "tiers", Message: "Tier days per year must be between 0 and 366"}
+		}
+		if seen[tier.MinTenureMonths] {
+			return nil, &utils.ValidationError{Field: "tiers", Message: fmt.Sprintf("More than one tier starts at %d months", tier.MinTenureMonths)}
+		}
+		seen[tier.MinTenureMonths] = true
+		tiers = append(tiers, models.LeaveAccrualTier{MinTenureMonths: tier.MinTenureMonths, DaysPerYear: tier.DaysPerYear})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinTenureMonths < tiers[j].MinTenureMonths })
+
+	prorate := true
+	if req.ProrateFirstYear != nil {
+		prorate = *req.ProrateFirstYear
+	}
+
+	return &models.LeaveAccrualPolicy{
+		TenantID:              tenantID,
+		LeaveTypeID:           leaveTypeID,
+		Frequency:             req.Frequency,
+		PayPeriodsPerYear:     payPeriods,
+		ProrateFirstYear:      prorate,
+		CarryOverMaxDays:      req.CarryOverMaxDays,
+		CarryOverExpiryMonths: req.CarryOverExpiryMonths,
+		Tiers:                 tiers,
+	}, nil
+}
+
+// SaveAccrualPolicy creates or replaces the leave type's accrual policy.
+// Periods already accrued are not recalculated.
+func (la *LeaveAccrualService) SaveAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int, req *dto.LeaveAccrualPolicyRequest) (*dto.LeaveAccrualPolicyResponse, error) {
+	if _, err := la.leaveTypeRepo.GetLeaveTypeByID(ctx, tenantID, leaveTypeID); err != nil {
+		return nil, notFoundOr(err, "leave type")
+	}
+
+	policy, err := buildAccrualPolicy(tenantID, leaveTypeID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := la.accrualRepo.SaveAccrualPolicy(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("error saving accrual policy: %w", err)
+	}
+	return saved.ToResponse(), nil
+}
+
+func (la *LeaveAccrualService) DeleteAccrualPolicy(ctx context.Context, tenantID int, leaveTypeID int) error {
+	if err := la.accrualRepo.DeleteAccrualPolicy(ctx, tenantID, leaveTypeID); err != nil {
+		return notFoundOr(err, "accrual policy")
+	}
+	return nil
+}
+
+// currentLeaveYear returns year when it is set, or else the tenant's leave
+// year today
+func (la *LeaveAccrualService) currentLeaveYear(ctx context.Context, tenantID int, year int) (int, error) {
+	if year != 0 {
+		return year, nil
+	}
+	startMonth, err := la.leaveRequestRepo.GetLeaveYearStartMonth(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("error loading leave year: %w", err)
+	}
+	return leaveYear(today(time.Now()), startMonth), nil
+}
+
+func (la *LeaveAccrualService) authorizeEmployee(ctx context.Context, actor Actor, employeeID int) error {
+	employee, err := la.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return notFoundOr(err, "employee")
+	}
+	_, err = profileAccessFor(actor, employee)
+	return err
+}
+
+// ListLeaveBalances returns the employee's balances for a leave year, the
+// current one when year is zero. HR, the employee and their manager may see
+// them.
+func (la *LeaveAccrualService) ListLeaveBalances(ctx context.Context, actor Actor, employeeID int, year int) ([]*dto.LeaveBalanceResponse, error) {
+	if err := la.authorizeEmployee(ctx, actor, employeeID); err != nil {
+		return nil, err
+	}
+	year, err := la.currentLeaveYear(ctx, actor.TenantID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := la.accrualRepo.ListLeaveBalances(ctx, actor.TenantID, employeeID, year)
+	if err != nil {
+		return nil, fmt.Errorf("error listing leave balances: %w", err)
+	}
+
+	responses := make([]*dto.LeaveBalanceResponse, len(balances))
+	for i := range balances {
+		responses[i] = balances[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// ListLeaveLedger returns every change to the employee's balances for a leave
+// year, optionally for one leave type
+func (la *LeaveAccrualService) ListLeaveLedger(ctx context.Context, actor Actor, employeeID int, leaveTypeID int, year int) ([]*dto.LeaveLedgerEntryResponse, error) {
+	if err := la.authorizeEmployee(ctx, actor, employeeID); err != nil {
+		return nil, err
+	}
+	year, err := la.currentLeaveYear(ctx, actor.TenantID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := la.accrualRepo.ListLedgerEntries(ctx, actor.TenantID, employeeID, leaveTypeID, year)
+	if err != nil {
+		return nil, fmt.Errorf("error listing leave ledger: %w", err)
+	}
+
+	responses := make([]*dto.LeaveLedgerEntryResponse, len(entries))
+	for i := range entries {
+		responses[i] = entries[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// AdjustLeaveBalance records a manual correction by HR. The balance may not
+// go below zero.
+func (la *LeaveAccrualService) AdjustLeaveBalance(ctx context.Context, actor Actor, req *dto.LeaveAdjustmentRequest) (*dto.LeaveLedgerEntryResponse, error) {
+	if req.Days == 0 {
+		return nil, &utils.ValidationError{Field: "days", Message: "Days must not be zero"}
+	}
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, &utils.ValidationError{Field: "note", Message: "A note explaining the adjustment is required"}
+	}
+	if _, err := la.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, req.EmployeeID); err != nil {
+		return nil, &utils.ValidationError{Field: "employee_id", Message: "Employee not found"}
+	}
+	if _, err := la.leaveTypeRepo.GetLeaveTypeByID(ctx, actor.TenantID, req.LeaveTypeID); err != nil {
+		return nil, &utils.ValidationError{Field: "leave_type_id", Message: "Leave type not found"}
+	}
+	year, err := la.currentLeaveYear(ctx, actor.TenantID, req.Year)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := la.accrualRepo.AdjustBalance(ctx, &models.LeaveLedgerEntry{
+		TenantID:    actor.TenantID,
+		EmployeeID:  req.EmployeeID,
+		LeaveTypeID: req.LeaveTypeID,
+		Year:        year,
+		EntryType:   repositories.LedgerAdjustment,
+		Days:        roundDays(req.Days),
+		Note:        note,
+		CreatedBy:   &actor.EmployeeID,
+	})
+	if errors.Is(err, repositories.ErrInsufficientBalance) {
+		return nil, &utils.ValidationError{Field: "days", Message: "The adjustment would take the balance below zero"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error adjusting leave balance: %w", err)
+	}
+	return entry.ToResponse(), nil
+}
+
+// accrualPeriod is a span of a leave year over which leave is earned. End is
+// the first day of the next period.
+type accrualPeriod struct {
+	Key   string
+	Start time.Time
+	End   time.Time
+}
+
+// accrualPeriods splits the leave year starting at yearStart into the
+// policy's accrual periods. Each period is keyed by its first day.
+func accrualPeriods(policy *models.LeaveAccrualPolicy, yearStart time.Time) []accrualPeriod {
+	yearEnd := yearStart.AddDate(1, 0, 0)
+	starts := []time.Time{yearStart}
+	switch policy.Frequency {
+	case "monthly":
+		for i := 1; i < 12; i++ {
+			starts = append(starts, yearStart.AddDate(0, i, 0))
+		}
+	case "pay_period":
+		days := int(yearEnd.Sub(yearStart).Hours() / 24)
+		for i := 1; i < policy.PayPeriodsPerYear; i++ {
+			starts = append(starts, yearStart.AddDate(0, 0, i*days/policy.PayPeriodsPerYear))
+		}
+	}
+
+	periods := make([]accrualPeriod, len(starts))
+	for i, start := range starts {
+		end := yearEnd
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		periods[i] = accrualPeriod{Key: start.Format("2006-01-02"), Start: start, End: end}
+	}
+	return periods
+}
+
+// accrualEntitlement returns the yearly days earned on the given date: those
+// of the longest-service tier the employee has reached, or defaultDays when
+// they have reached none. Tiers must be ordered by tenure.
+func accrualEntitlement(tiers []models.LeaveAccrualTier, defaultDays float64, hireDate *time.Time, on time.Time) float64 {
+	days := defaultDays
+	for _, tier := range tiers {
+		if tier.MinTenureMonths == 0 || (hireDate != nil && !hireDate.AddDate(0, tier.MinTenureMonths, 0).After(on)) {
+			days = tier.DaysPerYear
+		}
+	}
+	return days
+}
+
+// accrual is leave earned for one period
+type accrual struct {
+	PeriodKey string
+	Days      float64
+}
+
+// dueAccruals returns the leave earned for every period of the leave year
+// starting at yearStart that has begun by now. Leave is earned at the start of
+// a period, or on the hire date for the period the employee joined in; that
+// period is pro-rated by the days employed when the policy says so.
+func dueAccruals(policy *models.LeaveAccrualPolicy, defaultDays float64, hireDate *time.Time, yearStart time.Time, now time.Time) []accrual {
+	var hired *time.Time
+	if hireDate != nil {
+		h := today(*hireDate)
+		hired = &h
+	}
+
+	periods := accrualPeriods(policy, yearStart)
+	accruals := []accrual{}
+	for _, period := range periods {
+		from := period.Start
+		if hired != nil && hired.After(from) {
+			if !hired.Before(period.End) {
+				continue
+			}
+			from = *hired
+		}
+		if from.After(today(now)) {
+			break
+		}
+
+		days := accrualEntitlement(policy.Tiers, defaultDays, hired, from) / float64(len(periods))
+		if policy.ProrateFirstYear && from.After(period.Start) {
+			days *= period.End.Sub(from).Hours() / period.End.Sub(period.Start).Hours()
+		}
+		if days = roundDays(days); days > 0 {
+			accruals = append(accruals, accrual{PeriodKey: period.Key, Days: days})
+		}
+	}
+	return accruals
+}
+
+// RunAccruals brings the tenant's leave balances up to date
+func (la *LeaveAccrualService) RunAccruals(ctx context.Context, tenantID int, now time.Time) (*dto.LeaveAccrualRunResponse, error) {
+	policies, err := la.accrualRepo.ListAccrualPolicies(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing accrual policies: %w", err)
+	}
+	return la.runAccruals(ctx, policies, now)
+}
+
+// RunScheduledAccruals brings every tenant's leave balances up to date. Every
+// entry it writes is keyed by its period, so running it again, or from
+// several instances at once, never accrues or rolls over anything twice.
+func (la *LeaveAccrualService) RunScheduledAccruals(ctx context.Context, now time.Time) (*dto.LeaveAccrualRunResponse, error) {
+	policies, err := la.accrualRepo.ListAccrualPolicies(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error listing accrual policies: %w", err)
+	}
+	return la.runAccruals(ctx, policies, now)
+}
+
+// runAccruals applies each policy for the current leave year: unused days of
+// the previous year are carried over or expired, carried days past their
+// expiry are removed and every period begun so far is accrued.
+func (la *LeaveAccrualService) runAccruals(ctx context.Context, policies []repositories.AccrualPolicyRow, now time.Time) (*dto.LeaveAccrualRunResponse, error) {
+	result := &dto.LeaveAccrualRunResponse{}
+	employeesByTenant := map[int][]repositories.AccrualEmployee{}
+
+	for _, row := range policies {
+		policy := &row.Policy
+		employees, ok := employeesByTenant[policy.TenantID]
+		if !ok {
+			var err error
+			employees, err = la.accrualRepo.ListAccrualEmployees(ctx, policy.TenantID)
+			if err != nil {
+				return nil, fmt.Errorf("error listing employees: %w", err)
+			}
+			employeesByTenant[policy.TenantID] = employees
+		}
+
+		year := leaveYear(today(now), row.LeaveYearStartMonth)
+		if err := la.rolloverPreviousYear(ctx, row, employees, year, now, result); err != nil {
+			return nil, err
+		}
+		if err := la.expireCarryOver(ctx, row, year, now, result); err != nil {
+			return nil, err
+		}
+		if err := la.accrueYear(ctx, row, employees, nil, year, now, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// accrueYear records the accruals due for the leave year that are not yet in
+// the ledger. When only is set, just those employees are accrued.
+func (la *LeaveAccrualService) accrueYear(ctx context.Context, row repositories.AccrualPolicyRow, employees []repositories.AccrualEmployee, only map[int]bool, year int, now time.Time, result *dto.LeaveAccrualRunResponse) error {
+	policy := &row.Policy
+	accrued, err := la.accrualRepo.ListAccruedPeriods(ctx, policy.LeaveTypeID, year)
+	if err != nil {
+		return fmt.Errorf("error listing accrued periods: %w", err)
+	}
+
+	yearStart := leaveYearStart(year, row.LeaveYearStartMonth)
+	for _, employee := range employees {
+		if only != nil && !only[employee.ID] {
+			continue
+		}
+		if row.EligibleGender != "" && row.EligibleGender != employee.Gender {
+			continue
+		}
+
+		for _, due := range dueAccruals(policy, float64(row.DaysPerYear), employee.HireDate, yearStart, now) {
+			if accrued[employee.ID][due.PeriodKey] {
+				continue
+			}
+			_, err := la.accrualRepo.AddLedgerEntry(ctx, &models.LeaveLedgerEntry{
+				TenantID:    policy.TenantID,
+				EmployeeID:  employee.ID,
+				LeaveTypeID: policy.LeaveTypeID,
+				Year:        year,
+				EntryType:   repositories.LedgerAccrual,
+				Days:        due.Days,
+				PeriodKey:   due.PeriodKey,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("error accruing leave for employee %d: %w", employee.ID, err)
+			}
+			result.Accruals++
+		}
+	}
+	return nil
+}
+
+// rolloverPreviousYear closes the balances of the leave year before year that
+// are still open. Any period of that year missed while the job was not running
+// is accrued first.
+func (la *LeaveAccrualService) rolloverPreviousYear(ctx context.Context, row repositories.AccrualPolicyRow, employees []repositories.AccrualEmployee, year int, now time.Time, result *dto.LeaveAccrualRunResponse) error {
+	policy := &row.Policy
+	candidates, err := la.accrualRepo.ListRolloverCandidates(ctx, policy.LeaveTypeID, year-1)
+	if err != nil {
+		return fmt.Errorf("error listing balances to roll over: %w", err)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	only := map[int]bool{}
+	for _, id := range candidates {
+		only[id] = true
+	}
+	if err := la.accrueYear(ctx, row, employees, only, year-1, now, result); err != nil {
+		return err
+	}
+
+	for _, employeeID := range candidates {
+		_, _, err := la.accrualRepo.RolloverBalance(ctx, policy.TenantID, employeeID, policy.LeaveTypeID, year-1, policy.CarryOverMaxDays)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error rolling over leave for employee %d: %w", employeeID, err)
+		}
+		result.CarryOvers++
+	}
+	return nil
+}
+
+// expireCarryOver removes unused carried-over days once the policy's expiry
+// has passed
+func (la *LeaveAccrualService) expireCarryOver(ctx context.Context, row repositories.AccrualPolicyRow, year int, now time.Time, result *dto.LeaveAccrualRunResponse) error {
+	policy := &row.Policy
+	if policy.CarryOverExpiryMonths == nil {
+		return nil
+	}
+	expiresOn := leaveYearStart(year, row.LeaveYearStartMonth).AddDate(0, *policy.CarryOverExpiryMonths, 0)
+	if today(now).Before(expiresOn) {
+		return nil
+	}
+
+	candidates, err := la.accrualRepo.ListCarryOverExpiryCandidates(ctx, policy.LeaveTypeID, year)
+	if err != nil {
+		return fmt.Errorf("error listing carried-over leave: %w", err)
+	}
+	for _, employeeID := range candidates {
+		expired, err := la.accrualRepo.ExpireCarryOver(ctx, policy.TenantID, employeeID, policy.LeaveTypeID, year)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error expiring carried-over leave for employee %d: %w", employeeID, err)
+		}
+		if expired > 0 {
+			result.Expiries++
+		}
+	}
+	return nil
+}
+
+// RunScheduler runs accruals now and then every interval until ctx is
+// cancelled
+func (la *LeaveAccrualService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if result, err := la.RunScheduledAccruals(ctx, time.Now()); err != nil {
+			log.Printf("leave accrual scheduler: %v", err)
+		} else if result.Accruals+result.CarryOvers+result.Expiries > 0 {
+			log.Printf("leave accrual scheduler: %d accruals, %d carry-overs, %d expiries", result.Accruals, result.CarryOvers, result.Expiries)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+)
+
+func TestLeaveYear(t *testing.T) {
+	tests := []struct {
+		date       string
+		startMonth time.Month
+		want       int
+	}{
+		{"2026-01-01", time.January, 2026},
+		{"2026-12-31", time.January, 2026},
+		{"2026-03-31", time.April, 2025},
+		{"2026-04-01", time.April, 2026},
+	}
+
+	for _, tt := range tests {
+		if got := leaveYear(date(tt.date), tt.startMonth); got != tt.want {
+			t.Errorf("leaveYear(%s, %s) = %d, want %d", tt.date, tt.startMonth, got, tt.want)
+		}
+	}
+}
+
+func TestAccrualPeriods(t *testing.T) {
+	yearStart := date("2026-04-01")
+
+	t.Run("monthly periods start on the first of each month", func(t *testing.T) {
+		periods := accrualPeriods(&models.LeaveAccrualPolicy{Frequency: "monthly"}, yearStart)
+		if len(periods) != 12 {
+			t.Fatalf("got %d periods, want 12", len(periods))
+		}
+		if periods[11].Key != "2027-03-01" || !periods[11].End.Equal(date("2027-04-01")) {
+			t.Errorf("got last period %+v, want March 2027", periods[11])
+		}
+	})
+
+	t.Run("pay periods split the year evenly", func(t *testing.T) {
+		periods := accrualPeriods(&models.LeaveAccrualPolicy{Frequency: "pay_period", PayPeriodsPerYear: 26}, yearStart)
+		if len(periods) != 26 {
+			t.Fatalf("got %d periods, want 26", len(periods))
+		}
+		if periods[1].Key != "2026-04-15" {
+			t.Errorf("got second period %s, want 2026-04-15", periods[1].Key)
+		}
+	})
+
+	t.Run("annual is one period", func(t *testing.T) {
+		periods := accrualPeriods(&models.LeaveAccrualPolicy{Frequency: "annual"}, yearStart)
+		if len(periods) != 1 || periods[0].Key != "2026-04-01" {
+			t.Errorf("got %+v, want one period from 2026-04-01", periods)
+		}
+	})
+}
+
+func TestAccrualEntitlement(t *testing.T) {
+	hired := date("2020-06-15")
+	tiers := []models.LeaveAccrualTier{{MinTenureMonths: 24, DaysPerYear: 22}, {MinTenureMonths: 60, DaysPerYear: 25}}
+
+	tests := []struct {
+		name     string
+		hireDate *time.Time
+		on       string
+		want     float64
+	}{
+		{"before any tier", &hired, "2022-06-14", 20},
+		{"first tier", &hired, "2022-06-15", 22},
+		{"last tier", &hired, "2025-06-15", 25},
+		{"no hire date", nil, "2026-01-01", 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accrualEntitlement(tiers, 20, tt.hireDate, date(tt.on)); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDueAccruals(t *testing.T) {
+	yearStart := date("2026-01-01")
+	annual := &models.LeaveAccrualPolicy{Frequency: "annual", ProrateFirstYear: true}
+	monthly := &models.LeaveAccrualPolicy{Frequency: "monthly", ProrateFirstYear: true}
+
+	total := func(accruals []accrual) float64 {
+		sum := 0.0
+		for _, a := range accruals {
+			sum += a.Days
+		}
+		return roundDays(sum)
+	}
+
+	t.Run("annual lump sum on the first day", func(t *testing.T) {
+		accruals := dueAccruals(annual, 20, nil, yearStart, date("2026-01-01"))
+		if len(accruals) != 1 || accruals[0].Days != 20 || accruals[0].PeriodKey != "2026-01-01" {
+			t.Errorf("got %+v, want 20 days for 2026-01-01", accruals)
+		}
+	})
+
+	t.Run("annual lump sum pro-rated from the hire date", func(t *testing.T) {
+		hired := date("2026-07-02")
+		accruals := dueAccruals(annual, 20, &hired, yearStart, date("2026-08-01"))
+		if total(accruals) != 10.03 {
+			t.Errorf("got %+v, want 10.03 days", accruals)
+		}
+	})
+
+	t.Run("nothing before the hire date", func(t *testing.T) {
+		hired := date("2026-07-02")
+		if accruals := dueAccruals(annual, 20, &hired, yearStart, date("2026-07-01")); len(accruals) != 0 {
+			t.Errorf("got %+v, want none", accruals)
+		}
+	})
+
+	t.Run("monthly only for months begun", func(t *testing.T) {
+		accruals := dueAccruals(monthly, 24, nil, yearStart, date("2026-03-15"))
+		if len(accruals) != 3 || total(accruals) != 6 {
+			t.Errorf("got %+v, want three months of 2 days", accruals)
+		}
+	})
+
+	t.Run("monthly with a mid-month hire", func(t *testing.T) {
+		hired := date("2026-04-16")
+		accruals := dueAccruals(monthly, 24, &hired, yearStart, date("2026-05-01"))
+		if len(accruals) != 2 || accruals[0].PeriodKey != "2026-04-01" || accruals[0].Days != 1 || accruals[1].Days != 2 {
+			t.Errorf("got %+v, want half of April and all of May", accruals)
+		}
+	})
+
+	t.Run("no pro-rating when the policy says so", func(t *testing.T) {
+		hired := date("2026-04-16")
+		policy := &models.LeaveAccrualPolicy{Frequency: "monthly"}
+		accruals := dueAccruals(policy, 24, &hired, yearStart, date("2026-04-16"))
+		if len(accruals) != 1 || accruals[0].Days != 2 {
+			t.Errorf("got %+v, want a full month", accruals)
+		}
+	})
+
+	t.Run("tier reached during the year", func(t *testing.T) {
+		hired := date("2024-03-01")
+		policy := &models.LeaveAccrualPolicy{Frequency: "monthly", Tiers: []models.LeaveAccrualTier{{MinTenureMonths: 24, DaysPerYear: 36}}}
+		accruals := dueAccruals(policy, 24, &hired, yearStart, date("2026-03-01"))
+		if len(accruals) != 3 || accruals[1].Days != 2 || accruals[2].Days != 3 {
+			t.Errorf("got %+v, want the higher rate from March", accruals)
+		}
+	})
+}
+
+func TestBuildAccrualPolicy(t *testing.T) {
+	negative := -1.0
+	tests := []struct {
+		name  string
+		req   dto.LeaveAccrualPolicyRequest
+		valid bool
+	}{
+		{"monthly", dto.LeaveAccrualPolicyRequest{Frequency: "monthly"}, true},
+		{"unknown frequency", dto.LeaveAccrualPolicyRequest{Frequency: "weekly"}, false},
+		{"pay periods missing", dto.LeaveAccrualPolicyRequest{Frequency: "pay_period"}, false},
+		{"negative carry-over", dto.LeaveAccrualPolicyRequest{Frequency: "annual", CarryOverMaxDays: &negative}, false},
+		{"duplicate tiers", dto.LeaveAccrualPolicyRequest{Frequency: "annual", Tiers: []dto.LeaveAccrualTier{{MinTenureMonths: 12, DaysPerYear: 20}, {MinTenureMonths: 12, DaysPerYear: 22}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildAccrualPolicy(1, 2, &tt.req)
+			if tt.valid != (err == nil) {
+				t.Errorf("got %v, want valid=%v", err, tt.valid)
+			}
+		})
+	}
+
+	t.Run("orders tiers and defaults to pro-rating", func(t *testing.T) {
+		policy, err := buildAccrualPolicy(1, 2, &dto.LeaveAccrualPolicyRequest{
+			Frequency: "annual",
+			Tiers:     []dto.LeaveAccrualTier{{MinTenureMonths: 60, DaysPerYear: 25}, {MinTenureMonths: 24, DaysPerYear: 22}},
+		})
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+		if !policy.ProrateFirstYear || policy.Tiers[0].MinTenureMonths != 24 {
+			t.Errorf("got %+v, want pro-rating and tiers ordered by tenure", policy)
+		}
+	})
+}
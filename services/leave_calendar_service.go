@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+const (
+	// maxCalendarRangeDays limits how long a range the leave calendar covers
+	maxCalendarRangeDays = 366
+	// The feed covers leave from feedPastDays ago to feedFutureDays ahead
+	feedPastDays   = 90
+	feedFutureDays = 365
+)
+
+// ErrInvalidCalendarFeed is returned for unknown or revoked feed tokens
+var ErrInvalidCalendarFeed = errors.New("invalid calendar feed")
+
+type ILeaveCalendarService interface {
+	ListLeaveCalendar(ctx context.Context, actor Actor, filter *dto.LeaveCalendarFilter) ([]*dto.LeaveCalendarEntryResponse, error)
+	CreateCalendarFeed(ctx context.Context, actor Actor) (*dto.CalendarFeedResponse, error)
+	RevokeCalendarFeed(ctx context.Context, actor Actor) error
+	RenderCalendarFeed(ctx context.Context, token string) ([]byte, error)
+}
+
+type LeaveCalendarService struct {
+	calendarRepo *repositories.LeaveCalendarRepository
+	baseURL      string
+}
+
+func NewLeaveCalendarService(calendarRepo *repositories.LeaveCalendarRepository, baseURL string) *LeaveCalendarService {
+	return &LeaveCalendarService{
+		calendarRepo: calendarRepo,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// parseCalendarRange reads an inclusive date range. From defaults to today
+// and to to four weeks after from.
+func parseCalendarRange(from string, to string, now time.Time) (time.Time, time.Time, error) {
+	start := today(now)
+	if from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return time.Time{}, time.Time{}, &utils.ValidationError{Field: "from", Message: "From must be in YYYY-MM-DD format"}
+		}
+		start = parsed
+	}
+	end := start.AddDate(0, 0, 27)
+	if to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return time.Time{}, time.Time{}, &utils.ValidationError{Field: "to", Message: "To must be in YYYY-MM-DD format"}
+		}
+		end = parsed
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, &utils.ValidationError{Field: "to", Message: "To cannot be before from"}
+	}
+	if end.Sub(start) > maxCalendarRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, &utils.ValidationError{Field: "to", Message: fmt.Sprintf("The range cannot be longer than %d days", maxCalendarRangeDays)}
+	}
+	return start, end, nil
+}
+
+// calendarStatuses returns the leave statuses the calendar shows; cancelled
+// and rejected leave never appears
+func calendarStatuses(status string) ([]string, error) {
+	switch status {
+	case "":
+		return []string{LeaveStatusApproved, LeaveStatusPending}, nil
+	case LeaveStatusApproved, LeaveStatusPending:
+		return []string{status}, nil
+	default:
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be approved or pending"}
+	}
+}
+
+// calendarRoot works out whose reporting subtree the actor may see. HR sees
+// the whole tenant, or the subtree of any manager they ask for; everyone else
+// sees their own subtree, or the subtree of someone within it.
+func (lc *LeaveCalendarService) calendarRoot(ctx context.Context, actor Actor, managerID int) (int, error) {
+	if actor.IsHR() || managerID == actor.EmployeeID {
+		return managerID, nil
+	}
+	if managerID == 0 {
+		return actor.EmployeeID, nil
+	}
+
+	visible, err := lc.calendarRepo.IsInSubtree(ctx, actor.TenantID, actor.EmployeeID, managerID)
+	if err != nil {
+		return 0, fmt.Errorf("error checking reporting line: %w", err)
+	}
+	if !visible {
+		return 0, ErrForbidden
+	}
+	return managerID, nil
+}
+
+func (lc *LeaveCalendarService) listLeave(ctx context.Context, actor Actor, filter *dto.LeaveCalendarFilter, from time.Time, to time.Time) ([]models.LeaveCalendarEntry, error) {
+	statuses, err := calendarStatuses(filter.Status)
+	if err != nil {
+		return nil, err
+	}
+	rootID, err := lc.calendarRoot(ctx, actor, filter.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := lc.calendarRepo.ListCalendarLeave(ctx, actor.TenantID, rootID, filter.DepartmentID, statuses, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error listing leave: %w", err)
+	}
+	return entries, nil
+}
+
+// ListLeaveCalendar returns the approved and pending leave the actor can see
+// that overlaps the range
+func (lc *LeaveCalendarService) ListLeaveCalendar(ctx context.Context, actor Actor, filter *dto.LeaveCalendarFilter) ([]*dto.LeaveCalendarEntryResponse, error) {
+	from, to, err := parseCalendarRange(filter.From, filter.To, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := lc.listLeave(ctx, actor, filter, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.LeaveCalendarEntryResponse, len(entries))
+	for i := range entries {
+		responses[i] = entries[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateCalendarFeed issues the actor a secret subscription link to their
+// leave calendar, revoking any earlier link
+func (lc *LeaveCalendarService) CreateCalendarFeed(ctx context.Context, actor Actor) (*dto.CalendarFeedResponse, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("error generating feed token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	createdAt, err := lc.calendarRepo.SaveCalendarFeed(ctx, actor.TenantID, actor.EmployeeID, hashCalendarToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("error saving calendar feed: %w", err)
+	}
+
+	return &dto.CalendarFeedResponse{
+		URL:       lc.baseURL + "/calendar/leave.ics?token=" + token,
+		Token:     token,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func (lc *LeaveCalendarService) RevokeCalendarFeed(ctx context.Context, actor Actor) error {
+	if err := lc.calendarRepo.DeleteCalendarFeed(ctx, actor.TenantID, actor.EmployeeID); err != nil {
+		return notFoundOr(err, "calendar feed")
+	}
+	return nil
+}
+
+// calendarEvents turns leave into all-day events, marking pending leave as
+// tentative
+func calendarEvents(entries []models.LeaveCalendarEntry) []utils.ICalEvent {
+	events := make([]utils.ICalEvent, len(entries))
+	for i, entry := range entries {
+		summary := entry.EmployeeName + " - " + entry.LeaveTypeName
+		if entry.Status == LeaveStatusPending {
+			summary += " (pending)"
+		}
+		description := ""
+		if entry.HalfDay {
+			description = "Half day"
+		}
+		events[i] = utils.ICalEvent{
+			UID:         fmt.Sprintf("leave-%d@peopleos", entry.LeaveRequestID),
+			Start:       entry.StartDate,
+			End:         entry.EndDate,
+			Summary:     summary,
+			Description: description,
+			Tentative:   entry.Status == LeaveStatusPending,
+		}
+	}
+	return events
+}
+
+// RenderCalendarFeed returns the iCalendar file behind a feed token. The feed
+// shows what its owner would see on the leave calendar today, so it follows
+// changes to their role and reporting line.
+func (lc *LeaveCalendarService) RenderCalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	if token == "" {
+		return nil, ErrInvalidCalendarFeed
+	}
+	owner, err := lc.calendarRepo.UseCalendarFeed(ctx, hashCalendarToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvalidCalendarFeed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading calendar feed: %w", err)
+	}
+	if owner.Status == "terminated" {
+		return nil, ErrInvalidCalendarFeed
+	}
+
+	now := time.Now()
+	actor := Actor{EmployeeID: owner.EmployeeID, TenantID: owner.TenantID, Role: owner.Role}
+	entries, err := lc.listLeave(ctx, actor, &dto.LeaveCalendarFilter{}, today(now).AddDate(0, 0, -feedPastDays), today(now).AddDate(0, 0, feedFutureDays))
+	if err != nil {
+		return nil, err
+	}
+	return utils.FormatICalendar("Team leave", calendarEvents(entries), now), nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestParseCalendarRange(t *testing.T) {
+	now := time.Date(2026, time.March, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		wantStart string
+		wantEnd   string
+		field     string
+	}{
+		{"defaults to four weeks from today", "", "", "2026-03-02", "2026-03-29", ""},
+		{"to defaults to four weeks after from", "2026-04-01", "", "2026-04-01", "2026-04-28", ""},
+		{"explicit range", "2026-03-01", "2026-03-31", "2026-03-01", "2026-03-31", ""},
+		{"bad to", "", "soon", "", "", "to"},
+		{"to before from", "2026-03-10", "2026-03-01", "", "", "to"},
+		{"range too long", "2026-01-01", "2027-06-01", "", "", "to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseCalendarRange(tt.from, tt.to, now)
+			if tt.field != "" {
+				var validationErr *utils.ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+					t.Fatalf("got error %v, want a validation error on %s", err, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !start.Equal(date(tt.wantStart)) || !end.Equal(date(tt.wantEnd)) {
+				t.Errorf("got %s to %s, want %s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"), tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestCalendarStatuses(t *testing.T) {
+	if statuses, err := calendarStatuses(""); err != nil || len(statuses) != 2 {
+		t.Errorf("got %v and error %v, want approved and pending", statuses, err)
+	}
+	if statuses, err := calendarStatuses(LeaveStatusPending); err != nil || len(statuses) != 1 || statuses[0] != LeaveStatusPending {
+		t.Errorf("got %v and error %v, want pending only", statuses, err)
+	}
+	var validationErr *utils.ValidationError
+	if _, err := calendarStatuses(LeaveStatusCancelled); !errors.As(err, &validationErr) {
+		t.Errorf("got error %v, want a validation error for cancelled", err)
+	}
+}
+
+func TestCalendarEvents(t *testing.T) {
+	entries := []models.LeaveCalendarEntry{
+		{LeaveRequestID: 7, EmployeeName: "Ada Obi", LeaveTypeName: "Annual", StartDate: date("2026-03-09"), EndDate: date("2026-03-13"), Status: LeaveStatusApproved},
+		{LeaveRequestID: 8, EmployeeName: "Kofi Mensah", LeaveTypeName: "Sick", StartDate: date("2026-03-10"), EndDate: date("2026-03-10"), HalfDay: true, Status: LeaveStatusPending},
+	}
+
+	events := calendarEvents(entries)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].UID != "leave-7@peopleos" || events[0].Summary != "Ada Obi - Annual" || events[0].Tentative {
+		t.Errorf("got %+v, want a confirmed event for request 7", events[0])
+	}
+	if events[1].Summary != "Kofi Mensah - Sick (pending)" || !events[1].Tentative || events[1].Description != "Half day" {
+		t.Errorf("got %+v, want a tentative half-day event", events[1])
+	}
+
+	calendar := string(utils.FormatICalendar("Team leave", events, date("2026-03-01")))
+	for _, line := range []string{"DTSTART;VALUE=DATE:20260309\r\n", "DTEND;VALUE=DATE:20260314\r\n", "STATUS:TENTATIVE\r\n"} {
+		if !strings.Contains(calendar, line) {
+			t.Errorf("calendar is missing %q", line)
+		}
+	}
+}
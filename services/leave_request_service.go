@@ -0,0 +1,484 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Leave request statuses
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved"
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
+var validLeaveRequestStatuses = []string{LeaveStatusPending, LeaveStatusApproved, LeaveStatusRejected, LeaveStatusCancelled}
+
+type ILeaveRequestService interface {
+	SubmitLeaveRequest(ctx context.Context, actor Actor, req *dto.CreateLeaveRequestRequest) (*dto.LeaveRequestResponse, error)
+	ListMyLeaveRequests(ctx context.Context, actor Actor, status string) ([]*dto.LeaveRequestResponse, error)
+	ListLeaveRequests(ctx context.Context, actor Actor, filter *dto.LeaveRequestFilter) ([]*dto.LeaveRequestResponse, error)
+	GetLeaveRequest(ctx context.Context, actor Actor, id int) (*dto.LeaveRequestResponse, error)
+	ApproveLeaveRequest(ctx context.Context, actor Actor, id int, req *dto.LeaveDecisionRequest) (*dto.LeaveRequestResponse, error)
+	RejectLeaveRequest(ctx context.Context, actor Actor, id int, req *dto.LeaveDecisionRequest) (*dto.LeaveRequestResponse, error)
+	CancelLeaveRequest(ctx context.Context, actor Actor, id int, req *dto.CancelLeaveRequestRequest) (*dto.LeaveRequestResponse, error)
+	GetWorkWeek(ctx context.Context, tenantID int) (*dto.WorkWeekResponse, error)
+	UpdateWorkWeek(ctx context.Context, tenantID int, req *dto.WorkWeekRequest) (*dto.WorkWeekResponse, error)
+}
+
+type LeaveRequestService struct {
+	leaveRequestRepo *repositories.LeaveRequestRepository
+	leaveTypeRepo    *repositories.LeaveTypeRepository
+	employeeRepo     *repositories.EmployeeRepository
+	leaveTypeService *LeaveTypeService
+	holidayService   *HolidayService
+	approvalService  *ApprovalService
+}
+
+func NewLeaveRequestService(
+	leaveRequestRepo *repositories.LeaveRequestRepository,
+	leaveTypeRepo *repositories.LeaveTypeRepository,
+	employeeRepo *repositories.EmployeeRepository,
+	leaveTypeService *LeaveTypeService,
+	holidayService *HolidayService,
+	approvalService *ApprovalService,
+) *LeaveRequestService {
+	return &LeaveRequestService{
+		leaveRequestRepo: leaveRequestRepo,
+		leaveTypeRepo:    leaveTypeRepo,
+		employeeRepo:     employeeRepo,
+		leaveTypeService: leaveTypeService,
+		holidayService:   holidayService,
+		approvalService:  approvalService,
+	}
+}
+
+// parseWorkDays converts weekday names such as "monday" into weekdays,
+// ignoring duplicates.
+func parseWorkDays(names []string) ([]time.Weekday, error) {
+	if len(names) == 0 {
+		return nil, &utils.ValidationError{Field: "work_days", Message: "At least one work day is required"}
+	}
+
+	seen := map[time.Weekday]bool{}
+	workDays := []time.Weekday{}
+	for _, name := range names {
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(strings.TrimSpace(name), day.String()) {
+				if !seen[day] {
+					seen[day] = true
+					workDays = append(workDays, day)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, &utils.ValidationError{Field: "work_days", Message: fmt.Sprintf("%q is not a day of the week", name)}
+		}
+	}
+	return workDays, nil
+}
+
+func workWeekResponse(workDays []time.Weekday) *dto.WorkWeekResponse {
+	names := make([]string, len(workDays))
+	for i, day := range workDays {
+		names[i] = strings.ToLower(day.String())
+	}
+	return &dto.WorkWeekResponse{WorkDays: names}
+}
+
+func (lr *LeaveRequestService) GetWorkWeek(ctx context.Context, tenantID int) (*dto.WorkWeekResponse, error) {
+	workDays, err := lr.leaveRequestRepo.GetWorkDays(ctx, tenantID)
+	if err != nil {
+		return nil, notFoundOr(err, "company")
+	}
+	return workWeekResponse(workDays), nil
+}
+
+func (lr *LeaveRequestService) UpdateWorkWeek(ctx context.Context, tenantID int, req *dto.WorkWeekRequest) (*dto.WorkWeekResponse, error) {
+	workDays, err := parseWorkDays(req.WorkDays)
+	if err != nil {
+		return nil, err
+	}
+	if err := lr.leaveRequestRepo.UpdateWorkDays(ctx, tenantID, workDays); err != nil {
+		return nil, notFoundOr(err, "company")
+	}
+	return workWeekResponse(workDays), nil
+}
+
+// countLeaveDays counts the working days between start and end inclusive,
+// skipping days outside the work week and holidays. A half-day request counts
+// its single day as half.
+func countLeaveDays(start time.Time, end time.Time, workDays []time.Weekday, holidays []time.Time, halfDay bool) float64 {
+	working := map[time.Weekday]bool{}
+	for _, day := range workDays {
+		working[day] = true
+	}
+	off := map[string]bool{}
+	for _, holiday := range holidays {
+		off[holiday.Format("2006-01-02")] = true
+	}
+
+	days := 0.0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if working[day.Weekday()] && !off[day.Format("2006-01-02")] {
+			days++
+		}
+	}
+	if halfDay && days > 0 {
+		return 0.5
+	}
+	return days
+}
+
+// checkLeaveRules applies the leave type's notice, duration, half-day and
+// attachment rules to a request for days of leave between start and end.
+func checkLeaveRules(leaveType *models.LeaveType, start time.Time, end time.Time, days float64, halfDay bool, attachmentURL string, now time.Time) error {
+	if halfDay {
+		if !leaveType.AllowHalfDay {
+			return &utils.ValidationError{Field: "half_day", Message: "This leave type cannot be taken as a half day"}
+		}
+		if !start.Equal(end) {
+			return &utils.ValidationError{Field: "half_day", Message: "A half day must start and end on the same date"}
+		}
+	}
+	if leaveType.MinNoticeDays > 0 && start.Before(today(now).AddDate(0, 0, leaveType.MinNoticeDays)) {
+		return &utils.ValidationError{Field: "start_date", Message: fmt.Sprintf("This leave type requires %d days notice", leaveType.MinNoticeDays)}
+	}
+	if leaveType.MaxConsecutiveDays != nil && days > float64(*leaveType.MaxConsecutiveDays) {
+		return &utils.ValidationError{Field: "end_date", Message: fmt.Sprintf("This leave type allows at most %d consecutive days", *leaveType.MaxConsecutiveDays)}
+	}
+	if leaveType.RequiresAttachment && strings.TrimSpace(attachmentURL) == "" {
+		return &utils.ValidationError{Field: "attachment_url", Message: "This leave type requires an attachment"}
+	}
+	return nil
+}
+
+// SubmitLeaveRequest files a leave request for the caller. Paid leave must be
+// covered by the balance left after the caller's other pending requests. When
+// the tenant has a leave approval workflow, the request goes through it.
+func (lr *LeaveRequestService) SubmitLeaveRequest(ctx context.Context, actor Actor, req *dto.CreateLeaveRequestRequest) (*dto.LeaveRequestResponse, error) {
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "start_date", Message: "Start date must be in YYYY-MM-DD format"}
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "end_date", Message: "End date must be in YYYY-MM-DD format"}
+	}
+	if end.Before(start) {
+		return nil, &utils.ValidationError{Field: "end_date", Message: "End date cannot be before the start date"}
+	}
+	startMonth, err := lr.leaveRequestRepo.GetLeaveYearStartMonth(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading leave year: %w", err)
+	}
+	year := leaveYear(start, startMonth)
+	if leaveYear(end, startMonth) != year {
+		return nil, &utils.ValidationError{Field: "end_date", Message: "Leave cannot span two leave years; submit one request per leave year"}
+	}
+
+	leaveType, err := lr.leaveTypeRepo.GetLeaveTypeByID(ctx, actor.TenantID, req.LeaveTypeID)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "leave_type_id", Message: "Leave type not found"}
+	}
+	employee, err := lr.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, actor.EmployeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	gender, err := lr.leaveTypeService.employeeGender(ctx, actor.TenantID, actor.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := checkLeaveEligibility(leaveType, gender, employee.HireDate, today(now)); err != nil {
+		return nil, err
+	}
+
+	workDays, err := lr.leaveRequestRepo.GetWorkDays(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading work week: %w", err)
+	}
+	holidays, err := lr.holidayService.holidayDates(ctx, actor.TenantID, actor.EmployeeID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error loading holidays: %w", err)
+	}
+	days := countLeaveDays(start, end, workDays, holidays, req.HalfDay)
+	if days == 0 {
+		return nil, &utils.ValidationError{Field: "start_date", Message: "The requested dates contain no working days"}
+	}
+	if err := checkLeaveRules(leaveType, start, end, days, req.HalfDay, req.AttachmentURL, now); err != nil {
+		return nil, err
+	}
+
+	created, err := lr.leaveRequestRepo.CreateLeaveRequest(ctx, &models.LeaveRequest{
+		TenantID:      actor.TenantID,
+		EmployeeID:    actor.EmployeeID,
+		LeaveTypeID:   leaveType.ID,
+		StartDate:     start,
+		EndDate:       end,
+		LeaveYear:     year,
+		DaysRequested: days,
+		HalfDay:       req.HalfDay,
+		Reason:        strings.TrimSpace(req.Reason),
+		AttachmentURL: strings.TrimSpace(req.AttachmentURL),
+	}, leaveType.IsPaid)
+	var shortfall *repositories.InsufficientBalanceError
+	if errors.Is(err, repositories.ErrLeaveOverlap) {
+		return nil, &utils.ValidationError{Field: "start_date", Message: "You already have leave requested for some of these dates"}
+	}
+	if errors.As(err, &shortfall) {
+		return nil, &utils.ValidationError{Field: "leave_type_id", Message: fmt.Sprintf("Insufficient balance: %.2f days available, %.2f requested", shortfall.Available, days)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating leave request: %w", err)
+	}
+
+	approval, err := lr.approvalService.StartApproval(ctx, actor.TenantID, ApprovalEntityLeaveRequest, created.ID, actor.EmployeeID)
+	if err == nil && approval != nil {
+		err = lr.leaveRequestRepo.SetApprovalWorkflow(ctx, actor.TenantID, created.ID, *approval.WorkflowID)
+	}
+	if err != nil {
+		// Without its approval the request could never be decided
+		if cancelErr := lr.approvalService.CancelApproval(ctx, actor.TenantID, ApprovalEntityLeaveRequest, created.ID); cancelErr != nil {
+			return nil, cancelErr
+		}
+		if deleteErr := lr.leaveRequestRepo.DeleteLeaveRequest(ctx, actor.TenantID, created.ID); deleteErr != nil {
+			return nil, fmt.Errorf("error removing leave request: %w", deleteErr)
+		}
+		return nil, err
+	}
+	if approval != nil {
+		// The approval may already have decided the request
+		submitted, err := lr.leaveRequestRepo.GetLeaveRequest(ctx, actor.TenantID, created.ID)
+		if err != nil {
+			return nil, notFoundOr(err, "leave request")
+		}
+		return submitted.ToResponse(), nil
+	}
+	return created.ToResponse(), nil
+}
+
+func leaveRequestResponses(requests []models.LeaveRequest) []*dto.LeaveRequestResponse {
+	responses := make([]*dto.LeaveRequestResponse, len(requests))
+	for i := range requests {
+		responses[i] = requests[i].ToResponse()
+	}
+	return responses
+}
+
+func validateLeaveStatusFilter(status string) error {
+	if status != "" && !containsString(validLeaveRequestStatuses, status) {
+		return &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validLeaveRequestStatuses, ", ")}
+	}
+	return nil
+}
+
+func (lr *LeaveRequestService) ListMyLeaveRequests(ctx context.Context, actor Actor, status string) ([]*dto.LeaveRequestResponse, error) {
+	if err := validateLeaveStatusFilter(status); err != nil {
+		return nil, err
+	}
+
+	requests, err := lr.leaveRequestRepo.ListLeaveRequests(ctx, actor.TenantID, 0, &dto.LeaveRequestFilter{EmployeeID: actor.EmployeeID, Status: status})
+	if err != nil {
+		return nil, fmt.Errorf("error listing leave requests: %w", err)
+	}
+	return leaveRequestResponses(requests), nil
+}
+
+// ListLeaveRequests returns every request in the tenant to HR and the
+// requests of their direct reports to everyone else.
+func (lr *LeaveRequestService) ListLeaveRequests(ctx context.Context, actor Actor, filter *dto.LeaveRequestFilter) ([]*dto.LeaveRequestResponse, error) {
+	if err := validateLeaveStatusFilter(filter.Status); err != nil {
+		return nil, err
+	}
+
+	managerID := 0
+	if !actor.IsHR() {
+		managerID = actor.EmployeeID
+	}
+
+	requests, err := lr.leaveRequestRepo.ListLeaveRequests(ctx, actor.TenantID, managerID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error listing leave requests: %w", err)
+	}
+	return leaveRequestResponses(requests), nil
+}
+
+// loadLeaveRequest returns the request together with the employee it belongs
+// to.
+func (lr *LeaveRequestService) loadLeaveRequest(ctx context.Context, tenantID int, id int) (*models.LeaveRequest, *models.Employee, error) {
+	request, err := lr.leaveRequestRepo.GetLeaveRequest(ctx, tenantID, id)
+	if err != nil {
+		return nil, nil, notFoundOr(err, "leave request")
+	}
+	employee, err := lr.employeeRepo.GetEmployeeByID(ctx, tenantID, request.EmployeeID)
+	if err != nil {
+		return nil, nil, notFoundOr(err, "employee")
+	}
+	return request, employee, nil
+}
+
+func isManagerOf(actor Actor, employee *models.Employee) bool {
+	return employee.ManagerID != nil && *employee.ManagerID == actor.EmployeeID
+}
+
+// GetLeaveRequest returns a request to its owner, their manager and HR
+func (lr *LeaveRequestService) GetLeaveRequest(ctx context.Context, actor Actor, id int) (*dto.LeaveRequestResponse, error) {
+	request, employee, err := lr.loadLeaveRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := profileAccessFor(actor, employee); err != nil {
+		return nil, err
+	}
+	return request.ToResponse(), nil
+}
+
+// decide approves or rejects a pending request. A request going through an
+// approval workflow is decided by its approvers, one step at a time.
+// Otherwise the employee's manager and HR may decide, but never on their own
+// leave.
+func (lr *LeaveRequestService) decide(ctx context.Context, actor Actor, id int, status string, comment string) (*dto.LeaveRequestResponse, error) {
+	request, employee, err := lr.loadLeaveRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.ApprovalWorkflowID != nil {
+		if err := lr.approvalService.decideEntity(ctx, actor, ApprovalEntityLeaveRequest, id, status, comment); err != nil {
+			return nil, err
+		}
+		decided, err := lr.leaveRequestRepo.GetLeaveRequest(ctx, actor.TenantID, id)
+		if err != nil {
+			return nil, notFoundOr(err, "leave request")
+		}
+		return decided.ToResponse(), nil
+	}
+
+	if request.EmployeeID == actor.EmployeeID || (!actor.IsHR() && !isManagerOf(actor, employee)) {
+		return nil, ErrForbidden
+	}
+	if request.Status != LeaveStatusPending {
+		return nil, &utils.ValidationError{Field: "status", Message: "Only pending requests can be " + status}
+	}
+
+	decided, err := lr.finalize(ctx, nil, actor.TenantID, request, status, actor.EmployeeID, comment)
+	if err != nil {
+		return nil, err
+	}
+	return decided.ToResponse(), nil
+}
+
+// finalize records the final decision on a pending request, deducting
+// approved paid leave from the balance. The decision is made in tx when it is
+// set and in a transaction of its own otherwise.
+func (lr *LeaveRequestService) finalize(ctx context.Context, tx pgx.Tx, tenantID int, request *models.LeaveRequest, status string, decidedBy int, comment string) (*models.LeaveRequest, error) {
+	deduct := 0.0
+	if status == LeaveStatusApproved {
+		leaveType, err := lr.leaveTypeRepo.GetLeaveTypeByID(ctx, tenantID, request.LeaveTypeID)
+		if err != nil {
+			return nil, notFoundOr(err, "leave type")
+		}
+		if leaveType.IsPaid {
+			deduct = request.DaysRequested
+		}
+	}
+
+	decided, err := lr.leaveRequestRepo.DecideLeaveRequest(ctx, tx, tenantID, request.ID, status, decidedBy, strings.TrimSpace(comment), deduct)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Only pending requests can be " + status}
+	}
+	if errors.Is(err, repositories.ErrInsufficientBalance) {
+		return nil, &utils.ValidationError{Field: "status", Message: "The employee no longer has enough leave balance"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating leave request: %w", err)
+	}
+	return decided, nil
+}
+
+// ApprovalAttributes returns the fields of a leave request that approval
+// workflow conditions can test
+func (lr *LeaveRequestService) ApprovalAttributes(ctx context.Context, tenantID int, entityID int) (map[string]interface{}, error) {
+	request, employee, err := lr.loadLeaveRequest(ctx, tenantID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"days_requested":  request.DaysRequested,
+		"leave_type_id":   request.LeaveTypeID,
+		"leave_type":      request.LeaveTypeName,
+		"half_day":        request.HalfDay,
+		"department_id":   employee.DepartmentID,
+		"designation_id":  employee.DesignationID,
+		"employment_type": employee.EmploymentType,
+	}, nil
+}
+
+// OnApprovalComplete records the outcome of a leave request's approval
+// workflow in the approval's transaction
+func (lr *LeaveRequestService) OnApprovalComplete(ctx context.Context, tx pgx.Tx, tenantID int, entityID int, status string, decidedBy int, comment string) error {
+	request, err := lr.leaveRequestRepo.GetLeaveRequest(ctx, tenantID, entityID)
+	if err != nil {
+		return notFoundOr(err, "leave request")
+	}
+	_, err = lr.finalize(ctx, tx, tenantID, request, status, decidedBy, comment)
+	return err
+}
+
+func (lr *LeaveRequestService) ApproveLeaveRequest(ctx context.Context, actor Actor, id int, req *dto.LeaveDecisionRequest) (*dto.LeaveRequestResponse, error) {
+	return lr.decide(ctx, actor, id, LeaveStatusApproved, req.Comment)
+}
+
+func (lr *LeaveRequestService) RejectLeaveRequest(ctx context.Context, actor Actor, id int, req *dto.LeaveDecisionRequest) (*dto.LeaveRequestResponse, error) {
+	return lr.decide(ctx, actor, id, LeaveStatusRejected, req.Comment)
+}
+
+// CancelLeaveRequest withdraws a pending or approved request and restores any
+// balance it used. Employees may cancel their own leave until it starts; HR
+// may cancel any request.
+func (lr *LeaveRequestService) CancelLeaveRequest(ctx context.Context, actor Actor, id int, req *dto.CancelLeaveRequestRequest) (*dto.LeaveRequestResponse, error) {
+	request, err := lr.leaveRequestRepo.GetLeaveRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "leave request")
+	}
+	if !actor.IsHR() {
+		if request.EmployeeID != actor.EmployeeID {
+			return nil, ErrForbidden
+		}
+		if request.Status == LeaveStatusApproved && !request.StartDate.After(today(time.Now())) {
+			return nil, &utils.ValidationError{Field: "status", Message: "Leave that has already started can only be cancelled by HR"}
+		}
+	}
+	if request.Status != LeaveStatusPending && request.Status != LeaveStatusApproved {
+		return nil, &utils.ValidationError{Field: "status", Message: "Only pending or approved requests can be cancelled"}
+	}
+
+	cancelled, err := lr.leaveRequestRepo.CancelLeaveRequest(ctx, actor.TenantID, id, actor.EmployeeID, strings.TrimSpace(req.Reason))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Only pending or approved requests can be cancelled"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling leave request: %w", err)
+	}
+	if request.Status == LeaveStatusPending && request.ApprovalWorkflowID != nil {
+		if err := lr.approvalService.CancelApproval(ctx, actor.TenantID, ApprovalEntityLeaveRequest, id); err != nil {
+			return nil, err
+		}
+	}
+	return cancelled.ToResponse(), nil
+}
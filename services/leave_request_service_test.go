@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestCountLeaveDays(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+	tests := []struct {
+		name     string
+		start    string
+		end      string
+		holidays []time.Time
+		halfDay  bool
+		want     float64
+	}{
+		{"single weekday", "2026-03-02", "2026-03-02", nil, false, 1},
+		{"skips the weekend", "2026-03-05", "2026-03-10", nil, false, 4},
+		{"skips holidays", "2026-03-02", "2026-03-06", []time.Time{date("2026-03-04")}, false, 4},
+		{"weekend only", "2026-03-07", "2026-03-08", nil, false, 0},
+		{"half day", "2026-03-02", "2026-03-02", nil, true, 0.5},
+		{"half day on a holiday", "2026-03-04", "2026-03-04", []time.Time{date("2026-03-04")}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := countLeaveDays(date(tt.start), date(tt.end), weekdays, tt.holidays, tt.halfDay)
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLeaveRules(t *testing.T) {
+	now := date("2026-03-02")
+	five := 5
+	annual := &models.LeaveType{Name: "Annual", MinNoticeDays: 7, MaxConsecutiveDays: &five}
+	sick := &models.LeaveType{Name: "Sick", RequiresAttachment: true, AllowHalfDay: true}
+
+	tests := []struct {
+		name       string
+		leaveType  *models.LeaveType
+		start      string
+		end        string
+		days       float64
+		halfDay    bool
+		attachment string
+		field      string
+	}{
+		{"meets every rule", annual, "2026-03-09", "2026-03-13", 5, false, "", ""},
+		{"short notice", annual, "2026-03-08", "2026-03-08", 1, false, "", "start_date"},
+		{"too many days", annual, "2026-03-09", "2026-03-16", 6, false, "", "end_date"},
+		{"half day not allowed", annual, "2026-03-09", "2026-03-09", 0.5, true, "", "half_day"},
+		{"half day over two dates", sick, "2026-03-02", "2026-03-03", 0.5, true, "https://files/note.pdf", "half_day"},
+		{"missing attachment", sick, "2026-03-02", "2026-03-02", 1, false, " ", "attachment_url"},
+		{"half day with attachment", sick, "2026-03-02", "2026-03-02", 0.5, true, "https://files/note.pdf", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkLeaveRules(tt.leaveType, date(tt.start), date(tt.end), tt.days, tt.halfDay, tt.attachment, now)
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("got %v, want no error", err)
+				}
+				return
+			}
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got %v, want a %s validation error", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestParseWorkDays(t *testing.T) {
+	t.Run("accepts any case and drops duplicates", func(t *testing.T) {
+		got, err := parseWorkDays([]string{"Sunday", "monday", " MONDAY "})
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+		if len(got) != 2 || got[0] != time.Sunday || got[1] != time.Monday {
+			t.Errorf("got %v, want [Sunday Monday]", got)
+		}
+	})
+
+	t.Run("rejects unknown names", func(t *testing.T) {
+		if _, err := parseWorkDays([]string{"funday"}); err == nil {
+			t.Error("got no error, want a validation error")
+		}
+	})
+
+	t.Run("requires at least one day", func(t *testing.T) {
+		if _, err := parseWorkDays(nil); err == nil {
+			t.Error("got no error, want a validation error")
+		}
+	})
+}
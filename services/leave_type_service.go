@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+var validLeaveTypeStatuses = []string{"active", "inactive"}
+
+type ILeaveTypeService interface {
+	ListLeaveTypes(ctx context.Context, tenantID int, status string) ([]*dto.LeaveTypeResponse, error)
+	GetLeaveType(ctx context.Context, tenantID int, id int) (*dto.LeaveTypeResponse, error)
+	CreateLeaveType(ctx context.Context, tenantID int, req *dto.LeaveTypeRequest) (*dto.LeaveTypeResponse, error)
+	UpdateLeaveType(ctx context.Context, tenantID int, id int, req *dto.LeaveTypeRequest) (*dto.LeaveTypeResponse, error)
+	DeleteLeaveType(ctx context.Context, tenantID int, id int) error
+	ListEligibleLeaveTypes(ctx context.Context, actor Actor) ([]*dto.LeaveTypeResponse, error)
+}
+
+type LeaveTypeService struct {
+	leaveTypeRepo *repositories.LeaveTypeRepository
+	employeeRepo  *repositories.EmployeeRepository
+	profileRepo   *repositories.EmployeeProfileRepository
+}
+
+func NewLeaveTypeService(leaveTypeRepo *repositories.LeaveTypeRepository, employeeRepo *repositories.EmployeeRepository, profileRepo *repositories.EmployeeProfileRepository) *LeaveTypeService {
+	return &LeaveTypeService{
+		leaveTypeRepo: leaveTypeRepo,
+		employeeRepo:  employeeRepo,
+		profileRepo:   profileRepo,
+	}
+}
+
+func (ls *LeaveTypeService) ListLeaveTypes(ctx context.Context, tenantID int, status string) ([]*dto.LeaveTypeResponse, error) {
+	if status != "" && !containsString(validLeaveTypeStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validLeaveTypeStatuses, ", ")}
+	}
+
+	leaveTypes, err := ls.leaveTypeRepo.ListLeaveTypes(ctx, tenantID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing leave types: %w", err)
+	}
+
+	responses := make([]*dto.LeaveTypeResponse, len(leaveTypes))
+	for i := range leaveTypes {
+		responses[i] = leaveTypes[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (ls *LeaveTypeService) GetLeaveType(ctx context.Context, tenantID int, id int) (*dto.LeaveTypeResponse, error) {
+	leaveType, err := ls.leaveTypeRepo.GetLeaveTypeByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "leave type")
+	}
+	return leaveType.ToResponse(), nil
+}
+
+// buildLeaveType validates the request and turns it into a leave type. id is
+// zero for a new leave type; another leave type of the tenant may not share
+// its name.
+func (ls *LeaveTypeService) buildLeaveType(ctx context.Context, tenantID int, id int, req *dto.LeaveTypeRequest) (*models.LeaveType, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name is required"}
+	}
+	if req.DaysPerYear < 0 || req.DaysPerYear > 366 {
+		return nil, &utils.ValidationError{Field: "days_per_year", Message: "Days per year must be between 0 and 366"}
+	}
+	if req.MinNoticeDays < 0 {
+		return nil, &utils.ValidationError{Field: "min_notice_days", Message: "Minimum notice cannot be negative"}
+	}
+	if req.MaxConsecutiveDays != nil && *req.MaxConsecutiveDays < 1 {
+		return nil, &utils.ValidationError{Field: "max_consecutive_days", Message: "Maximum consecutive days must be at least 1"}
+	}
+	if req.EligibleGender != "" && !containsString(validGenders, req.EligibleGender) {
+		return nil, &utils.ValidationError{Field: "eligible_gender", Message: "Eligible gender must be one of " + strings.Join(validGenders, ", ")}
+	}
+	if req.MinTenureMonths < 0 {
+		return nil, &utils.ValidationError{Field: "min_tenure_months", Message: "Minimum tenure cannot be negative"}
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+	if !containsString(validLeaveTypeStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validLeaveTypeStatuses, ", ")}
+	}
+
+	existing, err := ls.leaveTypeRepo.ListLeaveTypes(ctx, tenantID, "")
+	if err != nil {
+		return nil, fmt.Errorf("error listing leave types: %w", err)
+	}
+	for _, leaveType := range existing {
+		if leaveType.ID != id && strings.EqualFold(leaveType.Name, name) {
+			return nil, &utils.ValidationError{Field: "name", Message: "A leave type with this name already exists"}
+		}
+	}
+
+	isPaid := true
+	if req.IsPaid != nil {
+		isPaid = *req.IsPaid
+	}
+
+	return &models.LeaveType{
+		ID:                 id,
+		TenantID:           tenantID,
+		Name:               name,
+		Description:        strings.TrimSpace(req.Description),
+		DaysPerYear:        req.DaysPerYear,
+		IsPaid:             isPaid,
+		RequiresAttachment: req.RequiresAttachment,
+		MinNoticeDays:      req.MinNoticeDays,
+		MaxConsecutiveDays: req.MaxConsecutiveDays,
+		EligibleGender:     req.EligibleGender,
+		MinTenureMonths:    req.MinTenureMonths,
+		AllowHalfDay:       req.AllowHalfDay,
+		Status:             status,
+	}, nil
+}
+
+func (ls *LeaveTypeService) CreateLeaveType(ctx context.Context, tenantID int, req *dto.LeaveTypeRequest) (*dto.LeaveTypeResponse, error) {
+	leaveType, err := ls.buildLeaveType(ctx, tenantID, 0, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := ls.leaveTypeRepo.CreateLeaveType(ctx, leaveType)
+	if err != nil {
+		return nil, fmt.Errorf("error creating leave type: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+func (ls *LeaveTypeService) UpdateLeaveType(ctx context.Context, tenantID int, id int, req *dto.LeaveTypeRequest) (*dto.LeaveTypeResponse, error) {
+	leaveType, err := ls.buildLeaveType(ctx, tenantID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := ls.leaveTypeRepo.UpdateLeaveType(ctx, leaveType)
+	if err != nil {
+		return nil, notFoundOr(err, "leave type")
+	}
+	return updated.ToResponse(), nil
+}
+
+// DeleteLeaveType removes a leave type that no request refers to. Leave types
+// with history are deactivated instead.
+func (ls *LeaveTypeService) DeleteLeaveType(ctx context.Context, tenantID int, id int) error {
+	inUse, err := ls.leaveTypeRepo.LeaveTypeInUse(ctx, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("error checking leave type usage: %w", err)
+	}
+	if inUse {
+		return &utils.ValidationError{Field: "id", Message: "Leave type has leave requests; set its status to inactive instead"}
+	}
+
+	if err := ls.leaveTypeRepo.DeleteLeaveType(ctx, tenantID, id); err != nil {
+		return notFoundOr(err, "leave type")
+	}
+	return nil
+}
+
+// checkLeaveEligibility reports why an employee may not take a leave type on
+// the given date, or nil when they may. An employee without a hire date does
+// not meet any tenure requirement.
+func checkLeaveEligibility(leaveType *models.LeaveType, gender string, hireDate *time.Time, on time.Time) error {
+	if leaveType.Status != "active" {
+		return &utils.ValidationError{Field: "leave_type_id", Message: "Leave type is not active"}
+	}
+	if leaveType.EligibleGender != "" && leaveType.EligibleGender != gender {
+		return &utils.ValidationError{Field: "leave_type_id", Message: "Employee is not eligible for this leave type"}
+	}
+	if leaveType.MinTenureMonths > 0 {
+		if hireDate == nil || hireDate.AddDate(0, leaveType.MinTenureMonths, 0).After(on) {
+			return &utils.ValidationError{Field: "leave_type_id", Message: fmt.Sprintf("This leave type requires %d months of service", leaveType.MinTenureMonths)}
+		}
+	}
+	return nil
+}
+
+// employeeGender returns the gender recorded in the employee's personal
+// details, or "" when none is recorded.
+func (ls *LeaveTypeService) employeeGender(ctx context.Context, tenantID int, employeeID int) (string, error) {
+	details, err := ls.profileRepo.GetPersonalDetails(ctx, tenantID, employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error loading personal details: %w", err)
+	}
+	return details.Gender, nil
+}
+
+// ListEligibleLeaveTypes returns the active leave types the caller may take
+// today.
+func (ls *LeaveTypeService) ListEligibleLeaveTypes(ctx context.Context, actor Actor) ([]*dto.LeaveTypeResponse, error) {
+	employee, err := ls.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, actor.EmployeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	gender, err := ls.employeeGender(ctx, actor.TenantID, actor.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveTypes, err := ls.leaveTypeRepo.ListLeaveTypes(ctx, actor.TenantID, "active")
+	if err != nil {
+		return nil, fmt.Errorf("error listing leave types: %w", err)
+	}
+
+	now := today(time.Now())
+	responses := []*dto.LeaveTypeResponse{}
+	for i := range leaveTypes {
+		if checkLeaveEligibility(&leaveTypes[i], gender, employee.HireDate, now) == nil {
+			responses = append(responses, leaveTypes[i].ToResponse())
+		}
+	}
+	return responses, nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestCheckLeaveEligibility(t *testing.T) {
+	hired := date("2025-06-15")
+	maternity := &models.LeaveType{Name: "Maternity", Status: "active", EligibleGender: "female", MinTenureMonths: 6}
+
+	tests := []struct {
+		name      string
+		leaveType *models.LeaveType
+		gender    string
+		hireDate  *time.Time
+		on        string
+		eligible  bool
+	}{
+		{"meets every rule", maternity, "female", &hired, "2025-12-15", true},
+		{"wrong gender", maternity, "male", &hired, "2026-06-01", false},
+		{"gender not recorded", maternity, "", &hired, "2026-06-01", false},
+		{"one day short of the tenure", maternity, "female", &hired, "2025-12-14", false},
+		{"no hire date", maternity, "female", nil, "2026-06-01", false},
+		{"inactive leave type", &models.LeaveType{Status: "inactive"}, "male", &hired, "2026-06-01", false},
+		{"no rules", &models.LeaveType{Status: "active"}, "", nil, "2026-06-01", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkLeaveEligibility(tt.leaveType, tt.gender, tt.hireDate, date(tt.on))
+			if tt.eligible && err != nil {
+				t.Errorf("got %v, want eligible", err)
+			}
+			if !tt.eligible {
+				var validationErr *utils.ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != "leave_type_id" {
+					t.Errorf("got %v, want a leave_type_id validation error", err)
+				}
+			}
+		})
+	}
+}
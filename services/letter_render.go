@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+const (
+	LetterFormatPDF  = "pdf"
+	LetterFormatHTML = "html"
+)
+
+// letterCustomFieldPrefix starts the placeholders of employee custom fields,
+// such as {{employee.custom.cost_centre}}
+const letterCustomFieldPrefix = "employee.custom."
+
+var letterPlaceholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// letterPlaceholders describes the fields letters may use, besides employee
+// custom fields
+var letterPlaceholders = []dto.LetterPlaceholder{
+	{Key: "employee.id", Description: "Employee ID"},
+	{Key: "employee.first_name", Description: "First name"},
+	{Key: "employee.last_name", Description: "Last name"},
+	{Key: "employee.full_name", Description: "First and last name"},
+	{Key: "employee.email", Description: "Work email address"},
+	{Key: "employee.phone", Description: "Phone number"},
+	{Key: "employee.hire_date", Description: "Hire date, such as 2 January 2026"},
+	{Key: "employee.employment_type", Description: "Employment type, such as full time"},
+	{Key: "employee.status", Description: "Employment status"},
+	{Key: "employee.salary", Description: "Salary, such as 55,000.00"},
+	{Key: "employee.salary_currency", Description: "Salary currency code"},
+	{Key: "employee.manager_name", Description: "Manager's name"},
+	{Key: "department.name", Description: "Department name"},
+	{Key: "designation.name", Description: "Designation name"},
+	{Key: "company.name", Description: "Company name"},
+	{Key: "company.industry", Description: "Company industry"},
+	{Key: "company.country", Description: "Company country"},
+	{Key: "today", Description: "Date the letter is generated"},
+}
+
+// LetterPlaceholders lists the fields letter templates may use
+func LetterPlaceholders() []dto.LetterPlaceholder {
+	placeholders := append([]dto.LetterPlaceholder{}, letterPlaceholders...)
+	return append(placeholders, dto.LetterPlaceholder{Key: letterCustomFieldPrefix + "<key>", Description: "Value of an employee custom field"})
+}
+
+func isLetterPlaceholder(key string) bool {
+	if strings.HasPrefix(key, letterCustomFieldPrefix) {
+		return len(key) > len(letterCustomFieldPrefix)
+	}
+	for _, placeholder := range letterPlaceholders {
+		if placeholder.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownLetterPlaceholders returns the placeholders of text letters cannot
+// fill, sorted and without repeats
+func unknownLetterPlaceholders(text string) []string {
+	unknown := []string{}
+	for _, match := range letterPlaceholderPattern.FindAllStringSubmatch(text, -1) {
+		if !isLetterPlaceholder(match[1]) && !containsString(unknown, match[1]) {
+			unknown = append(unknown, match[1])
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func formatLetterDate(date time.Time) string {
+	return date.Format("2 January 2006")
+}
+
+// formatLetterAmount writes an amount with two decimals and thousands
+// separators
+func formatLetterAmount(amount float64) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction := s[:len(s)-3], s[len(s)-3:]
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return sign + whole + fraction
+}
+
+// letterValues returns the value of every placeholder for an employee
+func letterValues(employee *models.EmployeeExportRow, company *models.Company, now time.Time) map[string]string {
+	values := map[string]string{
+		"employee.id":              strconv.Itoa(employee.ID),
+		"employee.first_name":      employee.FirstName,
+		"employee.last_name":       employee.LastName,
+		"employee.full_name":       strings.TrimSpace(employee.FirstName + " " + employee.LastName),
+		"employee.email":           employee.Email,
+		"employee.phone":           employee.Phone,
+		"employee.employment_type": strings.ReplaceAll(employee.EmploymentType, "_", " "),
+		"employee.status":          strings.ReplaceAll(employee.Status, "_", " "),
+		"employee.salary_currency": employee.SalaryCurrency,
+		"employee.manager_name":    employee.ManagerName,
+		"department.name":          employee.DepartmentName,
+		"designation.name":         employee.DesignationName,
+		"today":                    formatLetterDate(now),
+	}
+	if employee.HireDate != nil {
+		values["employee.hire_date"] = formatLetterDate(*employee.HireDate)
+	}
+	if employee.Salary != nil {
+		values["employee.salary"] = formatLetterAmount(*employee.Salary)
+	}
+	if company != nil {
+		values["company.name"] = company.Name
+		values["company.industry"] = company.Industry
+		values["company.country"] = company.Country
+		if code := utils.NormalizeCountryCode(company.Country); code != "" {
+			values["company.country"] = code
+		}
+	}
+	for key, value := range employee.CustomFields {
+		if value != nil {
+			values[letterCustomFieldPrefix+key] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
+
+// fillLetter replaces the placeholders of text with their values. Fields
+// without a value, such as an unset hire date, are left blank.
+func fillLetter(text string, values map[string]string) string {
+	return letterPlaceholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		key := letterPlaceholderPattern.FindStringSubmatch(match)[1]
+		return values[key]
+	})
+}
+
+// letterParagraphs splits a letter body into paragraphs at blank lines. The
+// lines of a paragraph are kept apart, as in an address block.
+func letterParagraphs(body string) [][]string {
+	paragraphs := [][]string{}
+	current := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = []string{}
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+	return paragraphs
+}
+
+// renderLetterHTML writes a filled letter as a standalone HTML page. All text
+// is escaped, so templates cannot inject markup.
+func renderLetterHTML(title string, body string) []byte {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>")
+	b.WriteString(html.EscapeString(title))
+	b.WriteString("</title>\n<style>body{font-family:Helvetica,Arial,sans-serif;font-size:11pt;line-height:1.4;max-width:40em;margin:2em auto;}</style>\n</head>\n<body>\n<h1>")
+	b.WriteString(html.EscapeString(title))
+	b.WriteString("</h1>\n")
+	for _, paragraph := range letterParagraphs(body) {
+		escaped := make([]string, len(paragraph))
+		for i, line := range paragraph {
+			escaped[i] = html.EscapeString(line)
+		}
+		b.WriteString("<p>" + strings.Join(escaped, "<br>\n") + "</p>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return []byte(b.String())
+}
+
+// renderLetterPDF writes a filled letter as an A4 PDF
+func renderLetterPDF(title string, body string) ([]byte, error) {
+	document := newPDFDocument(title)
+	flow := newPDFTextFlow(document)
+	flow.paragraph(title, pdfFontBold, 16, 20)
+	flow.space(10)
+	for _, paragraph := range letterParagraphs(body) {
+		for _, line := range paragraph {
+			flow.paragraph(line, pdfFontRegular, 11, 15)
+		}
+		flow.space(8)
+	}
+	return document.bytes()
+}
+
+// renderLetter fills a template for an employee and renders it in format. It
+// returns the content, its media type and the letter's title.
+func renderLetter(template *models.LetterTemplate, values map[string]string, format string) ([]byte, string, string, error) {
+	title := strings.TrimSpace(fillLetter(template.Title, values))
+	body := fillLetter(template.Body, values)
+	switch format {
+	case LetterFormatHTML:
+		return renderLetterHTML(title, body), "text/html", title, nil
+	default:
+		content, err := renderLetterPDF(title, body)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("error rendering letter: %w", err)
+		}
+		return content, "application/pdf", title, nil
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestLetterValues(t *testing.T) {
+	hireDate := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	salary := 1234567.5
+	employee := &models.EmployeeExportRow{
+		Employee: models.Employee{
+			ID: 7, FirstName: "Ada", LastName: "Obi", HireDate: &hireDate, EmploymentType: "full_time",
+			Salary: &salary, SalaryCurrency: "NGN", CustomFields: map[string]interface{}{"grade": "L4"},
+		},
+		DepartmentName:  "Engineering",
+		DesignationName: "Engineer",
+	}
+	company := &models.Company{Name: "Acme", Country: "Nigeria"}
+
+	values := letterValues(employee, company, time.Date(2026, 5, 10, 9, 0, 0, 0, time.UTC))
+	body := fillLetter("Dear {{ employee.first_name }},\n{{employee.full_name}} joined {{company.name}} ({{company.country}}) on {{employee.hire_date}} "+
+		"as a {{employee.employment_type}} {{designation.name}} in {{department.name}} at {{employee.salary_currency}} {{employee.salary}}, grade {{employee.custom.grade}}{{employee.custom.missing}}. {{today}}", values)
+
+	want := "Dear Ada,\nAda Obi joined Acme (NG) on 3 March 2025 as a full time Engineer in Engineering at NGN 1,234,567.50, grade L4. 10 May 2026"
+	if body != want {
+		t.Errorf("got %q, want %q", body, want)
+	}
+}
+
+func TestUnknownLetterPlaceholders(t *testing.T) {
+	unknown := unknownLetterPlaceholders("{{employee.first_name}} {{employee.password_hash}} {{ employee.custom.grade }} {{bonus}} {{bonus}}")
+	if strings.Join(unknown, ",") != "bonus,employee.password_hash" {
+		t.Errorf("got %v, want bonus and employee.password_hash", unknown)
+	}
+}
+
+func TestValidateLetterTemplate(t *testing.T) {
+	t.Run("defaults the category and status", func(t *testing.T) {
+		template, err := validateLetterTemplate(&dto.LetterTemplateRequest{Name: " Offer ", Title: "Offer for {{employee.full_name}}", Body: "Dear {{employee.first_name}}"})
+		if err != nil || template.Name != "Offer" || template.Category != "other" || template.Status != "active" {
+			t.Errorf("got %+v and %v, want an active template", template, err)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		req   dto.LetterTemplateRequest
+		field string
+	}{
+		{"no name", dto.LetterTemplateRequest{Title: "T", Body: "B"}, "name"},
+		{"bad category", dto.LetterTemplateRequest{Name: "N", Category: "memo", Title: "T", Body: "B"}, "category"},
+		{"no body", dto.LetterTemplateRequest{Name: "N", Title: "T", Body: " "}, "body"},
+		{"unknown placeholder", dto.LetterTemplateRequest{Name: "N", Title: "T", Body: "{{employee.salary_band}}"}, "body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateLetterTemplate(&tt.req)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestRenderLetterHTML(t *testing.T) {
+	page := string(renderLetterHTML("Offer <b>", "Dear Ada,\n\n14 Main St\nLagos\n\n<script>alert(1)</script>"))
+
+	if strings.Contains(page, "<script>") || strings.Contains(page, "<b>") {
+		t.Errorf("got %q, want the text escaped", page)
+	}
+	if !strings.Contains(page, "<p>14 Main St<br>\nLagos</p>") || strings.Count(page, "<p>") != 3 {
+		t.Errorf("got %q, want three paragraphs with the address lines kept", page)
+	}
+}
+
+func TestRenderLetterPDF(t *testing.T) {
+	body := strings.Repeat("This paragraph is long enough to wrap across several lines of the page. ", 40) + "\n\nCafé (Lagos) \\ Ltd"
+	content, err := renderLetterPDF("Offer letter", strings.Repeat(body+"\n\n", 5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.HasPrefix(content, []byte("%PDF-1.4")) || !bytes.HasSuffix(content, []byte("%%EOF\n")) {
+		t.Fatalf("got a file starting %q, want a PDF", content[:8])
+	}
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(content)
+	if startxref == nil {
+		t.Fatal("got no startxref")
+	}
+	offset, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(content[offset:], []byte("xref\n")) {
+		t.Errorf("got startxref %d, want the offset of the xref table", offset)
+	}
+	// Every object the xref table lists starts where it says
+	for i, match := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(content, -1) {
+		objectOffset, _ := strconv.Atoi(string(match[1]))
+		if !bytes.HasPrefix(content[objectOffset:], []byte(strconv.Itoa(i+1)+" 0 obj")) {
+			t.Errorf("got xref entry %d at %d, want object %d there", i, objectOffset, i+1)
+		}
+	}
+	if pages := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(content); pages == nil || string(pages[1]) == "1" {
+		t.Errorf("got page count %q, want the letter to span several pages", pages)
+	}
+
+	stream := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindSubmatch(content)
+	reader, err := zlib.NewReader(bytes.NewReader(stream[1]))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, _ := io.ReadAll(reader)
+	if !bytes.Contains(text, []byte("(Offer letter) Tj")) || !bytes.Contains(text, []byte("Caf\xe9 \\(Lagos\\) \\\\ Ltd")) {
+		t.Errorf("got content %q, want the escaped WinAnsi text", text)
+	}
+}
+
+func TestPDFWrap(t *testing.T) {
+	lines := pdfWrap("one two three "+strings.Repeat("x", 60), pdfFontRegular, 10, 100)
+
+	for _, line := range lines {
+		if pdfTextWidth(line, pdfFontRegular, 10) > 100 {
+			t.Errorf("got line %q wider than 100 points", line)
+		}
+	}
+	if lines[0] != "one two three" || strings.Join(lines[1:], "") != strings.Repeat("x", 60) {
+		t.Errorf("got %q, want the words kept together and the long word split", lines)
+	}
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// maxBulkLetters caps how many letters one bulk generation produces
+const maxBulkLetters = 500
+
+const maxLetterBodyLength = 100000
+
+var (
+	validLetterCategories = []string{"offer", "confirmation", "employment_verification", "other"}
+	validLetterStatuses   = []string{"active", "inactive"}
+)
+
+// RenderedLetter is a letter rendered for an employee but not saved
+type RenderedLetter struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+type ILetterService interface {
+	ListLetterTemplates(ctx context.Context, tenantID int, status string) ([]*dto.LetterTemplateResponse, error)
+	GetLetterTemplate(ctx context.Context, tenantID int, id int) (*dto.LetterTemplateResponse, error)
+	CreateLetterTemplate(ctx context.Context, actor Actor, req *dto.LetterTemplateRequest) (*dto.LetterTemplateResponse, error)
+	UpdateLetterTemplate(ctx context.Context, tenantID int, id int, req *dto.LetterTemplateRequest) (*dto.LetterTemplateResponse, error)
+	PreviewLetter(ctx context.Context, actor Actor, id int, req *dto.GenerateLetterRequest) (*RenderedLetter, error)
+	GenerateLetter(ctx context.Context, actor Actor, id int, req *dto.GenerateLetterRequest) (*dto.DocumentResponse, error)
+	BulkGenerateLetters(ctx context.Context, actor Actor, id int, req *dto.BulkGenerateLettersRequest) (*dto.BulkGenerateLettersResponse, error)
+}
+
+type LetterService struct {
+	letterRepo   *repositories.LetterRepository
+	documentRepo *repositories.DocumentRepository
+	companyRepo  *repositories.CompanyRepository
+	storage      DocumentStorage
+}
+
+func NewLetterService(letterRepo *repositories.LetterRepository, documentRepo *repositories.DocumentRepository, companyRepo *repositories.CompanyRepository, storage DocumentStorage) *LetterService {
+	return &LetterService{
+		letterRepo:   letterRepo,
+		documentRepo: documentRepo,
+		companyRepo:  companyRepo,
+		storage:      storage,
+	}
+}
+
+func (ls *LetterService) ListLetterTemplates(ctx context.Context, tenantID int, status string) ([]*dto.LetterTemplateResponse, error) {
+	if status != "" && !containsString(validLetterStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validLetterStatuses, ", ")}
+	}
+
+	templates, err := ls.letterRepo.ListLetterTemplates(ctx, tenantID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing letter templates: %w", err)
+	}
+
+	responses := make([]*dto.LetterTemplateResponse, len(templates))
+	for i := range templates {
+		responses[i] = templates[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (ls *LetterService) GetLetterTemplate(ctx context.Context, tenantID int, id int) (*dto.LetterTemplateResponse, error) {
+	template, err := ls.letterRepo.GetLetterTemplate(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "letter template")
+	}
+	return template.ToResponse(), nil
+}
+
+// validateLetterTemplate checks a letter template request and turns it into
+// a template. Every placeholder must be one letters can fill.
+func validateLetterTemplate(req *dto.LetterTemplateRequest) (*models.LetterTemplate, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name is required"}
+	}
+	if len(name) > 100 {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name must be at most 100 characters"}
+	}
+
+	category := req.Category
+	if category == "" {
+		category = "other"
+	}
+	if !containsString(validLetterCategories, category) {
+		return nil, &utils.ValidationError{Field: "category", Message: "Category must be one of " + strings.Join(validLetterCategories, ", ")}
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+	if !containsString(validLetterStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validLetterStatuses, ", ")}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, &utils.ValidationError{Field: "title", Message: "Title is required"}
+	}
+	if len(title) > 255 {
+		return nil, &utils.ValidationError{Field: "title", Message: "Title must be at most 255 characters"}
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, &utils.ValidationError{Field: "body", Message: "Body is required"}
+	}
+	if len(req.Body) > maxLetterBodyLength {
+		return nil, &utils.ValidationError{Field: "body", Message: fmt.Sprintf("Body must be at most %d characters", maxLetterBodyLength)}
+	}
+
+	if unknown := unknownLetterPlaceholders(title); len(unknown) > 0 {
+		return nil, &utils.ValidationError{Field: "title", Message: "Unknown placeholders: " + strings.Join(unknown, ", ")}
+	}
+	if unknown := unknownLetterPlaceholders(req.Body); len(unknown) > 0 {
+		return nil, &utils.ValidationError{Field: "body", Message: "Unknown placeholders: " + strings.Join(unknown, ", ")}
+	}
+
+	return &models.LetterTemplate{
+		Name:           name,
+		Category:       category,
+		DocumentTypeID: req.DocumentTypeID,
+		Title:          title,
+		Body:           req.Body,
+		Status:         status,
+	}, nil
+}
+
+// buildLetterTemplate validates the request and turns it into a template. id
+// is zero for a new template; another template of the tenant may not share
+// its name.
+func (ls *LetterService) buildLetterTemplate(ctx context.Context, tenantID int, id int, req *dto.LetterTemplateRequest) (*models.LetterTemplate, error) {
+	template, err := validateLetterTemplate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if template.DocumentTypeID != nil {
+		if _, err := ls.documentRepo.GetDocumentType(ctx, tenantID, *template.DocumentTypeID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, &utils.ValidationError{Field: "document_type_id", Message: "Document type not found"}
+			}
+			return nil, fmt.Errorf("error loading document type: %w", err)
+		}
+	}
+
+	existing, err := ls.letterRepo.ListLetterTemplates(ctx, tenantID, "")
+	if err != nil {
+		return nil, fmt.Errorf("error listing letter templates: %w", err)
+	}
+	for _, other := range existing {
+		if other.ID != id && strings.EqualFold(other.Name, template.Name) {
+			return nil, &utils.ValidationError{Field: "name", Message: "A letter template with this name already exists"}
+		}
+	}
+
+	template.ID = id
+	template.TenantID = tenantID
+	return template, nil
+}
+
+func (ls *LetterService) CreateLetterTemplate(ctx context.Context, actor Actor, req *dto.LetterTemplateRequest) (*dto.LetterTemplateResponse, error) {
+	template, err := ls.buildLetterTemplate(ctx, actor.TenantID, 0, req)
+	if err != nil {
+		return nil, err
+	}
+	template.CreatedBy = &actor.EmployeeID
+
+	created, err := ls.letterRepo.CreateLetterTemplate(ctx, template)
+	if err != nil {
+		return nil, fmt.Errorf("error creating letter template: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+func (ls *LetterService) UpdateLetterTemplate(ctx context.Context, tenantID int, id int, req *dto.LetterTemplateRequest) (*dto.LetterTemplateResponse, error) {
+	template, err := ls.buildLetterTemplate(ctx, tenantID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := ls.letterRepo.UpdateLetterTemplate(ctx, template)
+	if err != nil {
+		return nil, notFoundOr(err, "letter template")
+	}
+	return updated.ToResponse(), nil
+}
+
+func letterFormat(format string) (string, error) {
+	switch format {
+	case "", LetterFormatPDF:
+		return LetterFormatPDF, nil
+	case LetterFormatHTML:
+		return LetterFormatHTML, nil
+	default:
+		return "", &utils.ValidationError{Field: "format", Message: "Format must be pdf or html"}
+	}
+}
+
+// letterRun holds what every letter of one generation shares
+type letterRun struct {
+	template     *models.LetterTemplate
+	documentType *models.DocumentType
+	company      *models.Company
+	format       string
+	now          time.Time
+}
+
+// startLetterRun loads an active template and what its letters need
+func (ls *LetterService) startLetterRun(ctx context.Context, actor Actor, id int, format string) (*letterRun, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+	format, err := letterFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := ls.letterRepo.GetLetterTemplate(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "letter template")
+	}
+	if template.Status != "active" {
+		return nil, &utils.ValidationError{Field: "template", Message: "Letter template is inactive"}
+	}
+
+	run := &letterRun{template: template, format: format, now: time.Now()}
+	if template.DocumentTypeID != nil {
+		run.documentType, err = ls.documentRepo.GetDocumentType(ctx, actor.TenantID, *template.DocumentTypeID)
+		if err != nil {
+			return nil, notFoundOr(err, "document type")
+		}
+	}
+	if company, err := ls.companyRepo.GetCompanyByTenantID(ctx, actor.TenantID); err == nil {
+		run.company = company
+	}
+	return run, nil
+}
+
+// render fills the run's template for an employee
+func (lr *letterRun) render(employee *models.EmployeeExportRow) (*RenderedLetter, error) {
+	content, contentType, title, err := renderLetter(lr.template, letterValues(employee, lr.company, lr.now), lr.format)
+	if err != nil {
+		return nil, err
+	}
+	if title == "" {
+		title = lr.template.Name
+	}
+	fileName := cleanDocumentFileName(fmt.Sprintf("%s - %s %s.%s", title, employee.FirstName, employee.LastName, lr.format))
+	return &RenderedLetter{FileName: fileName, ContentType: contentType, Content: content}, nil
+}
+
+// loadLetterSubject returns the employee a single letter is for
+func (ls *LetterService) loadLetterSubject(ctx context.Context, tenantID int, employeeID int) (*models.EmployeeExportRow, error) {
+	if employeeID <= 0 {
+		return nil, &utils.ValidationError{Field: "employee_id", Message: "Employee is required"}
+	}
+	subjects, err := ls.letterRepo.ListLetterSubjects(ctx, tenantID, nil, employeeID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("error loading employee: %w", err)
+	}
+	if len(subjects) == 0 {
+		return nil, fmt.Errorf("employee %w", ErrNotFound)
+	}
+	return &subjects[0], nil
+}
+
+// PreviewLetter renders a template for an employee without saving it
+func (ls *LetterService) PreviewLetter(ctx context.Context, actor Actor, id int, req *dto.GenerateLetterRequest) (*RenderedLetter, error) {
+	run, err := ls.startLetterRun(ctx, actor, id, req.Format)
+	if err != nil {
+		return nil, err
+	}
+	employee, err := ls.loadLetterSubject(ctx, actor.TenantID, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	return run.render(employee)
+}
+
+// saveLetter renders a letter for an employee and saves it as one of their
+// documents
+func (ls *LetterService) saveLetter(ctx context.Context, actor Actor, run *letterRun, employee *models.EmployeeExportRow) (*models.Document, error) {
+	letter, err := run.render(employee)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &models.Document{
+		TenantID:     actor.TenantID,
+		EmployeeID:   &employee.ID,
+		DocumentType: run.template.Name,
+		FileName:     letter.FileName,
+		ContentType:  letter.ContentType,
+		SizeBytes:    int64(len(letter.Content)),
+		UploadedBy:   &actor.EmployeeID,
+	}
+	if run.documentType != nil {
+		upload := &dto.DocumentUpload{ContentType: letter.ContentType, Size: document.SizeBytes}
+		if _, err := validateDocumentUpload(run.documentType, upload); err != nil {
+			return nil, err
+		}
+		document.DocumentTypeID = &run.documentType.ID
+		document.DocumentType = run.documentType.Name
+	}
+
+	key, err := newDocumentStorageKey(actor.TenantID, employee.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error creating document key: %w", err)
+	}
+	if err := ls.storage.Put(ctx, key, bytes.NewReader(letter.Content), document.SizeBytes, letter.ContentType); err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(letter.Content)
+	document.StorageKey = key
+	document.Checksum = hex.EncodeToString(checksum[:])
+
+	created, err := ls.documentRepo.CreateDocument(ctx, document)
+	if err != nil {
+		if deleteErr := ls.storage.Delete(ctx, key); deleteErr != nil {
+			log.Printf("letter generation: error removing orphaned file %s: %v", key, deleteErr)
+		}
+		return nil, fmt.Errorf("error creating document: %w", err)
+	}
+	return created, nil
+}
+
+// GenerateLetter renders a template for an employee and saves it as one of
+// their documents
+func (ls *LetterService) GenerateLetter(ctx context.Context, actor Actor, id int, req *dto.GenerateLetterRequest) (*dto.DocumentResponse, error) {
+	run, err := ls.startLetterRun(ctx, actor, id, req.Format)
+	if err != nil {
+		return nil, err
+	}
+	employee, err := ls.loadLetterSubject(ctx, actor.TenantID, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := ls.saveLetter(ctx, actor, run, employee)
+	if err != nil {
+		return nil, err
+	}
+	return documentResponse(document, today(run.now)), nil
+}
+
+// BulkGenerateLetters generates and saves a letter for every employee
+// matching the filter. A letter that fails is reported without stopping the
+// others.
+func (ls *LetterService) BulkGenerateLetters(ctx context.Context, actor Actor, id int, req *dto.BulkGenerateLettersRequest) (*dto.BulkGenerateLettersResponse, error) {
+	run, err := ls.startLetterRun(ctx, actor, id, req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	employees, err := ls.letterRepo.ListLetterSubjects(ctx, actor.TenantID, &req.Filter, 0, maxBulkLetters+1)
+	if err != nil {
+		return nil, fmt.Errorf("error listing employees: %w", err)
+	}
+	if len(employees) > maxBulkLetters {
+		return nil, &utils.ValidationError{Field: "filter", Message: fmt.Sprintf("The filter matches more than %d employees; narrow it down", maxBulkLetters)}
+	}
+
+	response := &dto.BulkGenerateLettersResponse{
+		Generated: []*dto.DocumentResponse{},
+		Failed:    []dto.LetterFailure{},
+	}
+	for i := range employees {
+		employee := &employees[i]
+		document, err := ls.saveLetter(ctx, actor, run, employee)
+		if err != nil {
+			log.Printf("letter template %d: error generating letter for employee %d: %v", id, employee.ID, err)
+			response.Failed = append(response.Failed, dto.LetterFailure{
+				EmployeeID:   employee.ID,
+				EmployeeName: strings.TrimSpace(employee.FirstName + " " + employee.LastName),
+				Error:        letterFailureMessage(err),
+			})
+			continue
+		}
+		response.Generated = append(response.Generated, documentResponse(document, today(run.now)))
+	}
+	return response, nil
+}
+
+// letterFailureMessage describes why a letter failed without exposing
+// internal errors
+func letterFailureMessage(err error) string {
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Message
+	}
+	return "The letter could not be generated"
+}
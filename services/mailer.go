@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer delivers plain-text email notifications
+type Mailer interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+// LogMailer writes messages to the server log instead of sending them. It is
+// used when no SMTP server is configured.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (lm *LogMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
+
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: host + ":" + port,
+		auth: auth,
+		from: from,
+	}
+}
+
+func (sm *SMTPMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	msg := strings.Join([]string{
+		"From: " + sm.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(sm.addr, sm.auth, sm.from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,523 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Memo statuses
+const (
+	MemoStatusPending   = "pending"
+	MemoStatusApproved  = "approved"
+	MemoStatusRejected  = "rejected"
+	MemoStatusCancelled = "cancelled"
+)
+
+// Memo type categories
+const (
+	MemoCategoryGeneral      = "general"
+	MemoCategoryQuery        = "query"
+	MemoCategoryCommendation = "commendation"
+	MemoCategoryWarning      = "warning"
+)
+
+var (
+	validMemoStatuses     = []string{MemoStatusPending, MemoStatusApproved, MemoStatusRejected, MemoStatusCancelled}
+	validMemoCategories   = []string{MemoCategoryGeneral, MemoCategoryQuery, MemoCategoryCommendation, MemoCategoryWarning}
+	validMemoTypeStatuses = []string{"active", "inactive"}
+)
+
+type IMemoService interface {
+	ListMemoTypes(ctx context.Context, tenantID int, status string) ([]*dto.MemoTypeResponse, error)
+	GetMemoType(ctx context.Context, tenantID int, id int) (*dto.MemoTypeResponse, error)
+	CreateMemoType(ctx context.Context, tenantID int, req *dto.MemoTypeRequest) (*dto.MemoTypeResponse, error)
+	UpdateMemoType(ctx context.Context, tenantID int, id int, req *dto.MemoTypeRequest) (*dto.MemoTypeResponse, error)
+	DeleteMemoType(ctx context.Context, tenantID int, id int) error
+	CreateMemo(ctx context.Context, actor Actor, req *dto.CreateMemoRequest) (*dto.MemoResponse, error)
+	ListMemos(ctx context.Context, actor Actor, filter *dto.MemoFilter) ([]*dto.MemoResponse, error)
+	ListMyMemos(ctx context.Context, actor Actor, status string) ([]*dto.MemoResponse, error)
+	GetMemo(ctx context.Context, actor Actor, id int) (*dto.MemoResponse, error)
+	ApproveMemo(ctx context.Context, actor Actor, id int, req *dto.MemoDecisionRequest) (*dto.MemoResponse, error)
+	RejectMemo(ctx context.Context, actor Actor, id int, req *dto.MemoDecisionRequest) (*dto.MemoResponse, error)
+	CancelMemo(ctx context.Context, actor Actor, id int) (*dto.MemoResponse, error)
+	AcknowledgeMemo(ctx context.Context, actor Actor, id int, req *dto.AcknowledgeMemoRequest) (*dto.MemoResponse, error)
+}
+
+type MemoService struct {
+	memoRepo        *repositories.MemoRepository
+	employeeRepo    *repositories.EmployeeRepository
+	approvalService *ApprovalService
+}
+
+func NewMemoService(memoRepo *repositories.MemoRepository, employeeRepo *repositories.EmployeeRepository, approvalService *ApprovalService) *MemoService {
+	return &MemoService{
+		memoRepo:        memoRepo,
+		employeeRepo:    employeeRepo,
+		approvalService: approvalService,
+	}
+}
+
+func (ms *MemoService) ListMemoTypes(ctx context.Context, tenantID int, status string) ([]*dto.MemoTypeResponse, error) {
+	if status != "" && !containsString(validMemoTypeStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validMemoTypeStatuses, ", ")}
+	}
+
+	memoTypes, err := ms.memoRepo.ListMemoTypes(ctx, tenantID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing memo types: %w", err)
+	}
+
+	responses := make([]*dto.MemoTypeResponse, len(memoTypes))
+	for i := range memoTypes {
+		responses[i] = memoTypes[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (ms *MemoService) GetMemoType(ctx context.Context, tenantID int, id int) (*dto.MemoTypeResponse, error) {
+	memoType, err := ms.memoRepo.GetMemoType(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "memo type")
+	}
+	return memoType.ToResponse(), nil
+}
+
+// validateMemoType checks a memo type request and turns it into a memo type.
+// Queries and warnings are disciplinary unless the request says otherwise.
+func validateMemoType(req *dto.MemoTypeRequest) (*models.MemoType, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name is required"}
+	}
+	if len(name) > 100 {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name must be at most 100 characters"}
+	}
+
+	category := req.Category
+	if category == "" {
+		category = MemoCategoryGeneral
+	}
+	if !containsString(validMemoCategories, category) {
+		return nil, &utils.ValidationError{Field: "category", Message: "Category must be one of " + strings.Join(validMemoCategories, ", ")}
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+	if !containsString(validMemoTypeStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validMemoTypeStatuses, ", ")}
+	}
+
+	if req.ValidityMonths != nil {
+		if category != MemoCategoryWarning {
+			return nil, &utils.ValidationError{Field: "validity_months", Message: "Only warnings can lapse"}
+		}
+		if *req.ValidityMonths < 1 || *req.ValidityMonths > 120 {
+			return nil, &utils.ValidationError{Field: "validity_months", Message: "Validity must be between 1 and 120 months"}
+		}
+	}
+
+	isDisciplinary := category == MemoCategoryQuery || category == MemoCategoryWarning
+	if req.IsDisciplinary != nil {
+		isDisciplinary = *req.IsDisciplinary
+	}
+
+	return &models.MemoType{
+		Name:                    name,
+		Description:             strings.TrimSpace(req.Description),
+		Category:                category,
+		IsDisciplinary:          isDisciplinary,
+		RequiresAcknowledgement: req.RequiresAcknowledgement,
+		ValidityMonths:          req.ValidityMonths,
+		Status:                  status,
+	}, nil
+}
+
+// buildMemoType validates the request and turns it into a memo type. id is
+// zero for a new memo type; another memo type of the tenant may not share its
+// name.
+func (ms *MemoService) buildMemoType(ctx context.Context, tenantID int, id int, req *dto.MemoTypeRequest) (*models.MemoType, error) {
+	memoType, err := validateMemoType(req)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := ms.memoRepo.ListMemoTypes(ctx, tenantID, "")
+	if err != nil {
+		return nil, fmt.Errorf("error listing memo types: %w", err)
+	}
+	for _, other := range existing {
+		if other.ID != id && strings.EqualFold(other.Name, memoType.Name) {
+			return nil, &utils.ValidationError{Field: "name", Message: "A memo type with this name already exists"}
+		}
+	}
+
+	memoType.ID = id
+	memoType.TenantID = tenantID
+	return memoType, nil
+}
+
+func (ms *MemoService) CreateMemoType(ctx context.Context, tenantID int, req *dto.MemoTypeRequest) (*dto.MemoTypeResponse, error) {
+	memoType, err := ms.buildMemoType(ctx, tenantID, 0, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := ms.memoRepo.CreateMemoType(ctx, memoType)
+	if err != nil {
+		return nil, fmt.Errorf("error creating memo type: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+func (ms *MemoService) UpdateMemoType(ctx context.Context, tenantID int, id int, req *dto.MemoTypeRequest) (*dto.MemoTypeResponse, error) {
+	memoType, err := ms.buildMemoType(ctx, tenantID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := ms.memoRepo.UpdateMemoType(ctx, memoType)
+	if err != nil {
+		return nil, notFoundOr(err, "memo type")
+	}
+	return updated.ToResponse(), nil
+}
+
+// DeleteMemoType removes a memo type no memo uses. Memo types with memos are
+// deactivated instead.
+func (ms *MemoService) DeleteMemoType(ctx context.Context, tenantID int, id int) error {
+	inUse, err := ms.memoRepo.MemoTypeInUse(ctx, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("error checking memo type usage: %w", err)
+	}
+	if inUse {
+		return &utils.ValidationError{Field: "id", Message: "Memo type has memos; set its status to inactive instead"}
+	}
+
+	if err := ms.memoRepo.DeleteMemoType(ctx, tenantID, id); err != nil {
+		return notFoundOr(err, "memo type")
+	}
+	return nil
+}
+
+// canWriteMemo reports whether the actor may write a memo of the type about
+// the subject. Anyone may write an ordinary memo, but only HR and the
+// subject's managers may write a disciplinary one, and never about
+// themselves.
+func canWriteMemo(actor Actor, memoType *models.MemoType, subjectID int, managesSubject bool) error {
+	if !memoType.IsDisciplinary {
+		return nil
+	}
+	if subjectID == actor.EmployeeID {
+		return &utils.ValidationError{Field: "employee_id", Message: "You cannot write a disciplinary memo about yourself"}
+	}
+	if !actor.IsHR() && !managesSubject {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// canSeeMemo reports whether the actor may see the memo. HR, the subject and
+// the subject's managers see every memo; the author also sees the memos they
+// wrote unless they are disciplinary.
+func canSeeMemo(actor Actor, memo *models.Memo, managesSubject bool) bool {
+	if actor.IsHR() || memo.EmployeeID == actor.EmployeeID || managesSubject {
+		return true
+	}
+	return !memo.IsDisciplinary && memo.AuthorID != nil && *memo.AuthorID == actor.EmployeeID
+}
+
+// CreateMemo writes a memo about an employee, or the author when no employee
+// is given. When the tenant has a memo approval workflow the memo goes
+// through it; otherwise it is issued straight away.
+func (ms *MemoService) CreateMemo(ctx context.Context, actor Actor, req *dto.CreateMemoRequest) (*dto.MemoResponse, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, &utils.ValidationError{Field: "title", Message: "Title is required"}
+	}
+	if len(title) > 255 {
+		return nil, &utils.ValidationError{Field: "title", Message: "Title must be at most 255 characters"}
+	}
+
+	memoType, err := ms.memoRepo.GetMemoType(ctx, actor.TenantID, req.MemoTypeID)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "memo_type_id", Message: "Memo type not found"}
+	}
+	if memoType.Status != "active" {
+		return nil, &utils.ValidationError{Field: "memo_type_id", Message: "Memo type is not active"}
+	}
+
+	subjectID := actor.EmployeeID
+	if req.EmployeeID != 0 {
+		subjectID = req.EmployeeID
+	}
+	subject, err := ms.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, subjectID)
+	if err != nil || subject.Status == "terminated" {
+		return nil, &utils.ValidationError{Field: "employee_id", Message: "Employee not found"}
+	}
+	managesSubject, err := ms.memoRepo.IsInManagerChain(ctx, actor.TenantID, subjectID, actor.EmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking reporting line: %w", err)
+	}
+	if err := canWriteMemo(actor, memoType, subjectID, managesSubject); err != nil {
+		return nil, err
+	}
+
+	authorID := actor.EmployeeID
+	created, err := ms.memoRepo.CreateMemo(ctx, &models.Memo{
+		TenantID:    actor.TenantID,
+		EmployeeID:  subjectID,
+		AuthorID:    &authorID,
+		MemoTypeID:  memoType.ID,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating memo: %w", err)
+	}
+
+	approval, err := ms.approvalService.StartApproval(ctx, actor.TenantID, ApprovalEntityMemo, created.ID, actor.EmployeeID)
+	if err == nil && approval != nil {
+		err = ms.memoRepo.SetApprovalWorkflow(ctx, actor.TenantID, created.ID, *approval.WorkflowID)
+	}
+	if err != nil {
+		// Without its approval the memo could never be issued
+		if cancelErr := ms.approvalService.CancelApproval(ctx, actor.TenantID, ApprovalEntityMemo, created.ID); cancelErr != nil {
+			return nil, cancelErr
+		}
+		if deleteErr := ms.memoRepo.DeleteMemo(ctx, actor.TenantID, created.ID); deleteErr != nil {
+			return nil, fmt.Errorf("error removing memo: %w", deleteErr)
+		}
+		return nil, err
+	}
+	if approval != nil {
+		// The approval may already have decided the memo
+		submitted, err := ms.memoRepo.GetMemo(ctx, actor.TenantID, created.ID)
+		if err != nil {
+			return nil, notFoundOr(err, "memo")
+		}
+		return submitted.ToResponse(), nil
+	}
+
+	issued, err := ms.finalize(ctx, nil, actor.TenantID, created.ID, MemoStatusApproved, actor.EmployeeID, "")
+	if err != nil {
+		return nil, err
+	}
+	return issued.ToResponse(), nil
+}
+
+func memoResponses(memos []models.Memo) []*dto.MemoResponse {
+	responses := make([]*dto.MemoResponse, len(memos))
+	for i := range memos {
+		responses[i] = memos[i].ToResponse()
+	}
+	return responses
+}
+
+func validateMemoFilter(filter *dto.MemoFilter) error {
+	if filter.Status != "" && !containsString(validMemoStatuses, filter.Status) {
+		return &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validMemoStatuses, ", ")}
+	}
+	if filter.Category != "" && !containsString(validMemoCategories, filter.Category) {
+		return &utils.ValidationError{Field: "category", Message: "Category must be one of " + strings.Join(validMemoCategories, ", ")}
+	}
+	return nil
+}
+
+// ListMemos returns every memo in the tenant to HR. Everyone else gets the
+// memos about themselves and the people reporting to them, and the ordinary
+// memos they wrote.
+func (ms *MemoService) ListMemos(ctx context.Context, actor Actor, filter *dto.MemoFilter) ([]*dto.MemoResponse, error) {
+	if err := validateMemoFilter(filter); err != nil {
+		return nil, err
+	}
+
+	viewerID := 0
+	if !actor.IsHR() {
+		viewerID = actor.EmployeeID
+	}
+
+	memos, err := ms.memoRepo.ListMemos(ctx, actor.TenantID, viewerID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error listing memos: %w", err)
+	}
+	return memoResponses(memos), nil
+}
+
+// ListMyMemos returns the memos about the caller
+func (ms *MemoService) ListMyMemos(ctx context.Context, actor Actor, status string) ([]*dto.MemoResponse, error) {
+	filter := &dto.MemoFilter{EmployeeID: actor.EmployeeID, Status: status}
+	if err := validateMemoFilter(filter); err != nil {
+		return nil, err
+	}
+
+	memos, err := ms.memoRepo.ListMemos(ctx, actor.TenantID, actor.EmployeeID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error listing memos: %w", err)
+	}
+	return memoResponses(memos), nil
+}
+
+// loadMemo returns the memo and whether the actor manages its subject
+func (ms *MemoService) loadMemo(ctx context.Context, actor Actor, id int) (*models.Memo, bool, error) {
+	memo, err := ms.memoRepo.GetMemo(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, false, notFoundOr(err, "memo")
+	}
+	managesSubject, err := ms.memoRepo.IsInManagerChain(ctx, actor.TenantID, memo.EmployeeID, actor.EmployeeID)
+	if err != nil {
+		return nil, false, fmt.Errorf("error checking reporting line: %w", err)
+	}
+	return memo, managesSubject, nil
+}
+
+func (ms *MemoService) GetMemo(ctx context.Context, actor Actor, id int) (*dto.MemoResponse, error) {
+	memo, managesSubject, err := ms.loadMemo(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeeMemo(actor, memo, managesSubject) {
+		return nil, ErrForbidden
+	}
+	return memo.ToResponse(), nil
+}
+
+// decide approves or rejects a pending memo on behalf of one of its
+// approvers. Memos outside an approval workflow are issued when they are
+// written, so there is nothing to decide.
+func (ms *MemoService) decide(ctx context.Context, actor Actor, id int, status string, comment string) (*dto.MemoResponse, error) {
+	memo, err := ms.memoRepo.GetMemo(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "memo")
+	}
+	if memo.ApprovalWorkflowID == nil {
+		return nil, &utils.ValidationError{Field: "status", Message: "Memo is not waiting for approval"}
+	}
+
+	if err := ms.approvalService.decideEntity(ctx, actor, ApprovalEntityMemo, id, status, comment); err != nil {
+		return nil, err
+	}
+	decided, err := ms.memoRepo.GetMemo(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "memo")
+	}
+	return decided.ToResponse(), nil
+}
+
+// finalize records the final decision on a pending memo, in tx when it is set
+func (ms *MemoService) finalize(ctx context.Context, tx pgx.Tx, tenantID int, id int, status string, decidedBy int, comment string) (*models.Memo, error) {
+	decided, err := ms.memoRepo.DecideMemo(ctx, tx, tenantID, id, status, decidedBy, strings.TrimSpace(comment))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Only pending memos can be " + status}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating memo: %w", err)
+	}
+	return decided, nil
+}
+
+// ApprovalAttributes returns the fields of a memo that approval workflow
+// conditions can test
+func (ms *MemoService) ApprovalAttributes(ctx context.Context, tenantID int, entityID int) (map[string]interface{}, error) {
+	memo, err := ms.memoRepo.GetMemo(ctx, tenantID, entityID)
+	if err != nil {
+		return nil, notFoundOr(err, "memo")
+	}
+	employee, err := ms.employeeRepo.GetEmployeeByID(ctx, tenantID, memo.EmployeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	return map[string]interface{}{
+		"memo_type_id":   memo.MemoTypeID,
+		"memo_type":      memo.MemoTypeName,
+		"department_id":  employee.DepartmentID,
+		"designation_id": employee.DesignationID,
+	}, nil
+}
+
+// OnApprovalComplete records the outcome of a memo's approval workflow in the
+// approval's transaction
+func (ms *MemoService) OnApprovalComplete(ctx context.Context, tx pgx.Tx, tenantID int, entityID int, status string, decidedBy int, comment string) error {
+	_, err := ms.finalize(ctx, tx, tenantID, entityID, status, decidedBy, comment)
+	return err
+}
+
+func (ms *MemoService) ApproveMemo(ctx context.Context, actor Actor, id int, req *dto.MemoDecisionRequest) (*dto.MemoResponse, error) {
+	return ms.decide(ctx, actor, id, MemoStatusApproved, req.Comment)
+}
+
+func (ms *MemoService) RejectMemo(ctx context.Context, actor Actor, id int, req *dto.MemoDecisionRequest) (*dto.MemoResponse, error) {
+	return ms.decide(ctx, actor, id, MemoStatusRejected, req.Comment)
+}
+
+// CancelMemo withdraws a memo that is still waiting for approval. Its author
+// and HR may withdraw it.
+func (ms *MemoService) CancelMemo(ctx context.Context, actor Actor, id int) (*dto.MemoResponse, error) {
+	memo, err := ms.memoRepo.GetMemo(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "memo")
+	}
+	if !actor.IsHR() && (memo.AuthorID == nil || *memo.AuthorID != actor.EmployeeID) {
+		return nil, ErrForbidden
+	}
+
+	cancelled, err := ms.memoRepo.CancelMemo(ctx, actor.TenantID, id, actor.EmployeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Only pending memos can be cancelled"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling memo: %w", err)
+	}
+	if memo.ApprovalWorkflowID != nil {
+		if err := ms.approvalService.CancelApproval(ctx, actor.TenantID, ApprovalEntityMemo, id); err != nil {
+			return nil, err
+		}
+	}
+	return cancelled.ToResponse(), nil
+}
+
+// checkAcknowledgement reports why the actor may not acknowledge the memo, or
+// nil when they may. Only the subject acknowledges an issued memo, once.
+func checkAcknowledgement(actor Actor, memo *models.Memo) error {
+	if memo.EmployeeID != actor.EmployeeID {
+		return ErrForbidden
+	}
+	if memo.Status != MemoStatusApproved {
+		return &utils.ValidationError{Field: "status", Message: "Only issued memos can be acknowledged"}
+	}
+	if memo.AcknowledgedAt != nil {
+		return &utils.ValidationError{Field: "status", Message: "Memo is already acknowledged"}
+	}
+	return nil
+}
+
+// AcknowledgeMemo records when the subject of a memo acknowledged it, with
+// an optional comment
+func (ms *MemoService) AcknowledgeMemo(ctx context.Context, actor Actor, id int, req *dto.AcknowledgeMemoRequest) (*dto.MemoResponse, error) {
+	memo, err := ms.memoRepo.GetMemo(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "memo")
+	}
+	if err := checkAcknowledgement(actor, memo); err != nil {
+		return nil, err
+	}
+
+	acknowledged, err := ms.memoRepo.AcknowledgeMemo(ctx, actor.TenantID, id, strings.TrimSpace(req.Comment))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Memo is already acknowledged"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error acknowledging memo: %w", err)
+	}
+	return acknowledged.ToResponse(), nil
+}
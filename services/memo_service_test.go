@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func TestValidateMemoType(t *testing.T) {
+	no := false
+	six, zero := 6, 0
+
+	t.Run("makes queries and warnings disciplinary by default", func(t *testing.T) {
+		for category, want := range map[string]bool{
+			MemoCategoryQuery:        true,
+			MemoCategoryWarning:      true,
+			MemoCategoryCommendation: false,
+			"":                       false,
+		} {
+			memoType, err := validateMemoType(&dto.MemoTypeRequest{Name: "Memo", Category: category})
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %v", category, err)
+			}
+			if memoType.IsDisciplinary != want {
+				t.Errorf("%q: got disciplinary %v, want %v", category, memoType.IsDisciplinary, want)
+			}
+		}
+	})
+
+	t.Run("keeps an explicit choice and fills in defaults", func(t *testing.T) {
+		memoType, err := validateMemoType(&dto.MemoTypeRequest{Name: " Verbal warning ", Category: MemoCategoryWarning, IsDisciplinary: &no})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if memoType.IsDisciplinary || memoType.Name != "Verbal warning" || memoType.Status != "active" {
+			t.Errorf("got %+v, want a trimmed, active, non-disciplinary memo type", memoType)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		req   dto.MemoTypeRequest
+		field string
+	}{
+		{"missing name", dto.MemoTypeRequest{Category: MemoCategoryQuery}, "name"},
+		{"unknown category", dto.MemoTypeRequest{Name: "x", Category: "praise"}, "category"},
+		{"unknown status", dto.MemoTypeRequest{Name: "x", Status: "archived"}, "status"},
+		{"validity on a query", dto.MemoTypeRequest{Name: "x", Category: MemoCategoryQuery, ValidityMonths: &six}, "validity_months"},
+		{"validity out of range", dto.MemoTypeRequest{Name: "x", Category: MemoCategoryWarning, ValidityMonths: &zero}, "validity_months"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateMemoType(&tt.req)
+			var validationErr *utils.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+				t.Errorf("got error %v, want a validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestCanWriteMemo(t *testing.T) {
+	ordinary := &models.MemoType{Category: MemoCategoryCommendation}
+	disciplinary := &models.MemoType{Category: MemoCategoryQuery, IsDisciplinary: true}
+	employee := Actor{EmployeeID: 5, Role: "Employee"}
+	hr := Actor{EmployeeID: 1, Role: "HR"}
+
+	if err := canWriteMemo(employee, ordinary, 7, false); err != nil {
+		t.Errorf("got %v, want anyone to write an ordinary memo", err)
+	}
+	if err := canWriteMemo(employee, disciplinary, 7, true); err != nil {
+		t.Errorf("got %v, want a manager to write a disciplinary memo", err)
+	}
+	if err := canWriteMemo(hr, disciplinary, 7, false); err != nil {
+		t.Errorf("got %v, want HR to write a disciplinary memo", err)
+	}
+	if err := canWriteMemo(employee, disciplinary, 7, false); !errors.Is(err, ErrForbidden) {
+		t.Errorf("got %v, want ErrForbidden for someone outside the manager chain", err)
+	}
+	var validationErr *utils.ValidationError
+	if err := canWriteMemo(hr, disciplinary, 1, false); !errors.As(err, &validationErr) {
+		t.Errorf("got %v, want a validation error for a disciplinary memo about oneself", err)
+	}
+}
+
+func TestCanSeeMemo(t *testing.T) {
+	author := 3
+	memo := func(disciplinary bool) *models.Memo {
+		return &models.Memo{EmployeeID: 7, AuthorID: &author, IsDisciplinary: disciplinary}
+	}
+
+	tests := []struct {
+		name           string
+		actor          Actor
+		disciplinary   bool
+		managesSubject bool
+		want           bool
+	}{
+		{"HR sees disciplinary memos", Actor{EmployeeID: 1, Role: "HR"}, true, false, true},
+		{"the subject sees disciplinary memos", Actor{EmployeeID: 7, Role: "Employee"}, true, false, true},
+		{"the manager chain sees disciplinary memos", Actor{EmployeeID: 2, Role: "Employee"}, true, true, true},
+		{"the author does not see disciplinary memos", Actor{EmployeeID: 3, Role: "Employee"}, true, false, false},
+		{"the author sees ordinary memos", Actor{EmployeeID: 3, Role: "Employee"}, false, false, true},
+		{"others see nothing", Actor{EmployeeID: 9, Role: "Employee"}, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canSeeMemo(tt.actor, memo(tt.disciplinary), tt.managesSubject); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAcknowledgement(t *testing.T) {
+	acknowledgedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	subject := Actor{EmployeeID: 7, Role: "Employee"}
+
+	if err := checkAcknowledgement(subject, &models.Memo{EmployeeID: 7, Status: MemoStatusApproved}); err != nil {
+		t.Errorf("got %v, want the subject to acknowledge an issued memo", err)
+	}
+	if err := checkAcknowledgement(Actor{EmployeeID: 1, Role: "HR"}, &models.Memo{EmployeeID: 7, Status: MemoStatusApproved}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("got %v, want ErrForbidden for anyone but the subject", err)
+	}
+
+	var validationErr *utils.ValidationError
+	if err := checkAcknowledgement(subject, &models.Memo{EmployeeID: 7, Status: MemoStatusPending}); !errors.As(err, &validationErr) {
+		t.Errorf("got %v, want a validation error for a pending memo", err)
+	}
+	if err := checkAcknowledgement(subject, &models.Memo{EmployeeID: 7, Status: MemoStatusApproved, AcknowledgedAt: &acknowledgedAt}); !errors.As(err, &validationErr) {
+		t.Errorf("got %v, want a validation error for a memo acknowledged already", err)
+	}
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Who an offboarding checklist item is assigned to
+const (
+	OffboardingAssigneeHR        = "hr" // the HR user who started the offboarding
+	OffboardingAssigneeManager   = "manager"
+	OffboardingAssigneeEmployee  = "employee"
+	OffboardingAssigneeSuccessor = "successor"
+	OffboardingAssigneeSpecific  = "specific"
+)
+
+var validOffboardingAssignees = []string{OffboardingAssigneeHR, OffboardingAssigneeManager, OffboardingAssigneeEmployee, OffboardingAssigneeSuccessor, OffboardingAssigneeSpecific}
+
+// defaultOffboardingChecklist is used for tenants that have not configured
+// their own checklist.
+var defaultOffboardingChecklist = []models.OffboardingChecklistItem{
+	{Title: "Knowledge transfer", Description: "Hand over open work and documentation", AssigneeType: OffboardingAssigneeSuccessor, DueOffsetDays: -3},
+	{Title: "Exit interview", AssigneeType: OffboardingAssigneeHR, DueOffsetDays: -1},
+	{Title: "Return company assets", Description: "Laptop, badge, keys and any other company equipment", AssigneeType: OffboardingAssigneeEmployee},
+}
+
+// workingDaysPerYear converts an annual salary to the daily rate used to
+// value a leave settlement.
+const workingDaysPerYear = 260
+
+type IOffboardingService interface {
+	ListChecklistItems(ctx context.Context, tenantID int) ([]*dto.OffboardingChecklistItemResponse, error)
+	CreateChecklistItem(ctx context.Context, tenantID int, req *dto.OffboardingChecklistItemRequest) (*dto.OffboardingChecklistItemResponse, error)
+	UpdateChecklistItem(ctx context.Context, tenantID int, id int, req *dto.OffboardingChecklistItemRequest) (*dto.OffboardingChecklistItemResponse, error)
+	DeleteChecklistItem(ctx context.Context, tenantID int, id int) error
+	StartOffboarding(ctx context.Context, actor Actor, employeeID int, req *dto.StartOffboardingRequest) (*dto.OffboardingResponse, error)
+	GetOffboarding(ctx context.Context, actor Actor, employeeID int) (*dto.OffboardingResponse, error)
+	ListOffboardings(ctx context.Context, actor Actor, status string) ([]*dto.OffboardingResponse, error)
+	CancelOffboarding(ctx context.Context, actor Actor, employeeID int) (*dto.OffboardingResponse, error)
+	CompleteTask(ctx context.Context, actor Actor, employeeID int, taskID int, req *dto.CompleteOffboardingTaskRequest) (*dto.OffboardingTaskResponse, error)
+}
+
+type OffboardingService struct {
+	offboardingRepo *repositories.OffboardingRepository
+	employeeRepo    *repositories.EmployeeRepository
+}
+
+func NewOffboardingService(offboardingRepo *repositories.OffboardingRepository, employeeRepo *repositories.EmployeeRepository) *OffboardingService {
+	return &OffboardingService{
+		offboardingRepo: offboardingRepo,
+		employeeRepo:    employeeRepo,
+	}
+}
+
+func (ob *OffboardingService) ListChecklistItems(ctx context.Context, tenantID int) ([]*dto.OffboardingChecklistItemResponse, error) {
+	items, err := ob.offboardingRepo.ListChecklistItems(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing offboarding checklist: %w", err)
+	}
+
+	responses := make([]*dto.OffboardingChecklistItemResponse, len(items))
+	for i := range items {
+		responses[i] = items[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// validateChecklistItem checks the request and, for items assigned to a
+// specific employee, that the employee belongs to the tenant.
+func (ob *OffboardingService) validateChecklistItem(ctx context.Context, tenantID int, req *dto.OffboardingChecklistItemRequest) error {
+	if strings.TrimSpace(req.Title) == "" {
+		return &utils.ValidationError{Field: "title", Message: "Title is required"}
+	}
+	if !containsString(validOffboardingAssignees, req.AssigneeType) {
+		return &utils.ValidationError{Field: "assignee_type", Message: "Assignee type must be one of " + strings.Join(validOffboardingAssignees, ", ")}
+	}
+	if req.AssigneeType == OffboardingAssigneeSpecific {
+		if req.AssigneeEmployeeID == nil {
+			return &utils.ValidationError{Field: "assignee_employee_id", Message: "Assignee employee is required for specific assignments"}
+		}
+		if _, err := ob.employeeRepo.GetEmployeeByID(ctx, tenantID, *req.AssigneeEmployeeID); err != nil {
+			return &utils.ValidationError{Field: "assignee_employee_id", Message: "Assignee employee not found"}
+		}
+	} else {
+		req.AssigneeEmployeeID = nil
+	}
+	return nil
+}
+
+func (ob *OffboardingService) CreateChecklistItem(ctx context.Context, tenantID int, req *dto.OffboardingChecklistItemRequest) (*dto.OffboardingChecklistItemResponse, error) {
+	if err := ob.validateChecklistItem(ctx, tenantID, req); err != nil {
+		return nil, err
+	}
+
+	created, err := ob.offboardingRepo.CreateChecklistItem(ctx, &models.OffboardingChecklistItem{
+		TenantID:           tenantID,
+		Title:              strings.TrimSpace(req.Title),
+		Description:        strings.TrimSpace(req.Description),
+		AssigneeType:       req.AssigneeType,
+		AssigneeEmployeeID: req.AssigneeEmployeeID,
+		DueOffsetDays:      req.DueOffsetDays,
+		SortOrder:          req.SortOrder,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating offboarding checklist item: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+func (ob *OffboardingService) UpdateChecklistItem(ctx context.Context, tenantID int, id int, req *dto.OffboardingChecklistItemRequest) (*dto.OffboardingChecklistItemResponse, error) {
+	if err := ob.validateChecklistItem(ctx, tenantID, req); err != nil {
+		return nil, err
+	}
+
+	updated, err := ob.offboardingRepo.UpdateChecklistItem(ctx, &models.OffboardingChecklistItem{
+		ID:                 id,
+		TenantID:           tenantID,
+		Title:              strings.TrimSpace(req.Title),
+		Description:        strings.TrimSpace(req.Description),
+		AssigneeType:       req.AssigneeType,
+		AssigneeEmployeeID: req.AssigneeEmployeeID,
+		DueOffsetDays:      req.DueOffsetDays,
+		SortOrder:          req.SortOrder,
+	})
+	if err != nil {
+		return nil, notFoundOr(err, "offboarding checklist item")
+	}
+	return updated.ToResponse(), nil
+}
+
+func (ob *OffboardingService) DeleteChecklistItem(ctx context.Context, tenantID int, id int) error {
+	if err := ob.offboardingRepo.DeleteChecklistItem(ctx, tenantID, id); err != nil {
+		return notFoundOr(err, "offboarding checklist item")
+	}
+	return nil
+}
+
+// buildOffboardingTasks turns the checklist into dated, assigned tasks for one
+// leaver. Items whose assignee cannot be resolved, such as the manager of an
+// employee without one, fall back to the HR user who started the offboarding.
+func buildOffboardingTasks(items []models.OffboardingChecklistItem, employee *models.Employee, successorID int, initiatorID int, lastWorkingDay time.Time) []models.OffboardingTask {
+	tasks := make([]models.OffboardingTask, 0, len(items))
+	for _, item := range items {
+		assigneeID := initiatorID
+		switch item.AssigneeType {
+		case OffboardingAssigneeManager:
+			if employee.ManagerID != nil {
+				assigneeID = *employee.ManagerID
+			}
+		case OffboardingAssigneeEmployee:
+			assigneeID = employee.ID
+		case OffboardingAssigneeSuccessor:
+			assigneeID = successorID
+		case OffboardingAssigneeSpecific:
+			if item.AssigneeEmployeeID != nil {
+				assigneeID = *item.AssigneeEmployeeID
+			}
+		}
+
+		dueDate := lastWorkingDay.AddDate(0, 0, item.DueOffsetDays)
+		tasks = append(tasks, models.OffboardingTask{
+			Title:        item.Title,
+			Description:  item.Description,
+			AssigneeType: item.AssigneeType,
+			AssigneeID:   &assigneeID,
+			DueDate:      &dueDate,
+		})
+	}
+	return tasks
+}
+
+func roundDays(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// computeLeaveSettlement works out what is owed for leave on the last working
+// day. Each leave type's annual entitlement is earned evenly across the year,
+// so the settlement is the remaining balance less the entitlement not yet
+// earned; it is valued at the daily rate of salary when one is recorded.
+func computeLeaveSettlement(balances []repositories.LeaveBalanceRow, lastWorkingDay time.Time, salary *float64, currency string) *dto.LeaveSettlementResponse {
+	year := lastWorkingDay.Year()
+	daysInYear := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	earnedFraction := float64(lastWorkingDay.YearDay()) / float64(daysInYear)
+
+	settlement := &dto.LeaveSettlementResponse{
+		Year:  year,
+		Lines: make([]dto.LeaveSettlementLine, 0, len(balances)),
+	}
+	for _, balance := range balances {
+		accrued := roundDays(balance.DaysPerYear * earnedFraction)
+		days := roundDays(balance.BalanceDays - (balance.DaysPerYear - accrued))
+		settlement.Lines = append(settlement.Lines, dto.LeaveSettlementLine{
+			LeaveTypeID:    balance.LeaveTypeID,
+			LeaveType:      balance.LeaveTypeName,
+			Entitlement:    balance.DaysPerYear,
+			Accrued:        accrued,
+			Balance:        balance.BalanceDays,
+			SettlementDays: days,
+		})
+		settlement.Days += days
+	}
+	settlement.Days = roundDays(settlement.Days)
+
+	if salary != nil && currency != "" {
+		amount := roundDays(settlement.Days * *salary / workingDaysPerYear)
+		settlement.Amount = &amount
+		settlement.Currency = currency
+	}
+	return settlement
+}
+
+func (ob *OffboardingService) leaveSettlement(ctx context.Context, employee *models.Employee, lastWorkingDay time.Time) (*dto.LeaveSettlementResponse, error) {
+	balances, err := ob.offboardingRepo.ListLeaveBalances(ctx, employee.TenantID, employee.ID, lastWorkingDay.Year())
+	if err != nil {
+		return nil, fmt.Errorf("error loading leave balances: %w", err)
+	}
+	return computeLeaveSettlement(balances, lastWorkingDay, employee.Salary, employee.SalaryCurrency), nil
+}
+
+func offboardingResponse(offboarding *models.Offboarding, tasks []models.OffboardingTask, settlement *dto.LeaveSettlementResponse, includeAmount bool) *dto.OffboardingResponse {
+	response := offboarding.ToResponse()
+	if settlement != nil {
+		response.Settlement = settlement
+	}
+	if response.Settlement != nil && !includeAmount {
+		withoutAmount := *response.Settlement
+		withoutAmount.Amount = nil
+		withoutAmount.Currency = ""
+		response.Settlement = &withoutAmount
+	}
+	response.Tasks = make([]*dto.OffboardingTaskResponse, len(tasks))
+	for i := range tasks {
+		response.Tasks[i] = tasks[i].ToResponse()
+	}
+	return response
+}
+
+func (ob *OffboardingService) StartOffboarding(ctx context.Context, actor Actor, employeeID int, req *dto.StartOffboardingRequest) (*dto.OffboardingResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	employee, err := ob.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	if employee.Status == "terminated" {
+		return nil, &utils.ValidationError{Field: "employee", Message: "Employee has already left"}
+	}
+
+	lastWorkingDay, err := time.Parse("2006-01-02", req.LastWorkingDay)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "last_working_day", Message: "Last working day must be in YYYY-MM-DD format"}
+	}
+	if employee.HireDate != nil && lastWorkingDay.Before(*employee.HireDate) {
+		return nil, &utils.ValidationError{Field: "last_working_day", Message: "Last working day cannot be before the hire date"}
+	}
+
+	if req.SuccessorID == employeeID {
+		return nil, &utils.ValidationError{Field: "successor_id", Message: "An employee cannot succeed themselves"}
+	}
+	successor, err := ob.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, req.SuccessorID)
+	if err != nil || successor.Status == "terminated" {
+		return nil, &utils.ValidationError{Field: "successor_id", Message: "Successor not found"}
+	}
+
+	if _, err := ob.offboardingRepo.GetOffboardingByEmployee(ctx, actor.TenantID, employeeID); err == nil {
+		return nil, &utils.ValidationError{Field: "employee", Message: "Employee already has an offboarding in progress"}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("error checking existing offboarding: %w", err)
+	}
+	if _, err := ob.offboardingRepo.GetOffboardingByEmployee(ctx, actor.TenantID, req.SuccessorID); err == nil {
+		return nil, &utils.ValidationError{Field: "successor_id", Message: "Successor is also being offboarded"}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("error checking successor offboarding: %w", err)
+	}
+
+	items, err := ob.offboardingRepo.ListChecklistItems(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading offboarding checklist: %w", err)
+	}
+	if len(items) == 0 {
+		items = defaultOffboardingChecklist
+	}
+
+	offboarding, tasks, err := ob.offboardingRepo.CreateOffboarding(ctx, &models.Offboarding{
+		TenantID:       actor.TenantID,
+		EmployeeID:     employeeID,
+		LastWorkingDay: lastWorkingDay,
+		Reason:         strings.TrimSpace(req.Reason),
+		SuccessorID:    req.SuccessorID,
+		InitiatedBy:    &actor.EmployeeID,
+	}, buildOffboardingTasks(items, employee, req.SuccessorID, actor.EmployeeID, lastWorkingDay))
+	if err != nil {
+		return nil, fmt.Errorf("error starting offboarding: %w", err)
+	}
+
+	settlement, err := ob.leaveSettlement(ctx, employee, lastWorkingDay)
+	if err != nil {
+		return nil, err
+	}
+	return offboardingResponse(offboarding, tasks, settlement, true), nil
+}
+
+// GetOffboarding returns the employee's offboarding with its checklist. Until
+// it completes, the leave settlement is a live preview; the amount is only
+// shown to callers who may see compensation.
+func (ob *OffboardingService) GetOffboarding(ctx context.Context, actor Actor, employeeID int) (*dto.OffboardingResponse, error) {
+	employee, err := ob.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	access, err := profileAccessFor(actor, employee)
+	if err != nil {
+		return nil, err
+	}
+
+	offboarding, err := ob.offboardingRepo.GetOffboardingByEmployee(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "offboarding")
+	}
+	tasks, err := ob.offboardingRepo.ListOffboardingTasks(ctx, actor.TenantID, offboarding.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing offboarding tasks: %w", err)
+	}
+
+	var settlement *dto.LeaveSettlementResponse
+	if offboarding.Status == "scheduled" {
+		settlement, err = ob.leaveSettlement(ctx, employee, offboarding.LastWorkingDay)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return offboardingResponse(offboarding, tasks, settlement, access.viewSensitive), nil
+}
+
+func (ob *OffboardingService) ListOffboardings(ctx context.Context, actor Actor, status string) ([]*dto.OffboardingResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	offboardings, err := ob.offboardingRepo.ListOffboardings(ctx, actor.TenantID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing offboardings: %w", err)
+	}
+
+	responses := make([]*dto.OffboardingResponse, len(offboardings))
+	for i := range offboardings {
+		responses[i] = offboardings[i].ToResponse()
+	}
+	return responses, nil
+}
+
+func (ob *OffboardingService) CancelOffboarding(ctx context.Context, actor Actor, employeeID int) (*dto.OffboardingResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	cancelled, err := ob.offboardingRepo.CancelOffboarding(ctx, actor.TenantID, employeeID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("scheduled offboarding %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling offboarding: %w", err)
+	}
+	return cancelled.ToResponse(), nil
+}
+
+// CompleteTask marks a checklist task done. Only its assignee or HR may do so.
+func (ob *OffboardingService) CompleteTask(ctx context.Context, actor Actor, employeeID int, taskID int, req *dto.CompleteOffboardingTaskRequest) (*dto.OffboardingTaskResponse, error) {
+	offboarding, err := ob.offboardingRepo.GetOffboardingByEmployee(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "offboarding")
+	}
+	task, err := ob.offboardingRepo.GetOffboardingTask(ctx, actor.TenantID, taskID)
+	if err != nil {
+		return nil, notFoundOr(err, "offboarding task")
+	}
+	if task.OffboardingID != offboarding.ID {
+		return nil, fmt.Errorf("offboarding task %w", ErrNotFound)
+	}
+	if !actor.IsHR() && (task.AssigneeID == nil || *task.AssigneeID != actor.EmployeeID) {
+		return nil, ErrForbidden
+	}
+	if task.Status != "pending" {
+		return nil, &utils.ValidationError{Field: "status", Message: "Task is already completed"}
+	}
+
+	completed, err := ob.offboardingRepo.CompleteOffboardingTask(ctx, actor.TenantID, taskID, actor.EmployeeID, strings.TrimSpace(req.Notes))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Task is already completed"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error completing offboarding task: %w", err)
+	}
+	return completed.ToResponse(), nil
+}
+
+// CompleteDueOffboardings finishes every offboarding whose last working day
+// has ended and returns how many it completed. It is safe to run repeatedly
+// and from several instances at once.
+func (ob *OffboardingService) CompleteDueOffboardings(ctx context.Context, now time.Time) (int, error) {
+	due, err := ob.offboardingRepo.ListDueOffboardings(ctx, today(now))
+	if err != nil {
+		return 0, fmt.Errorf("error listing due offboardings: %w", err)
+	}
+
+	completed := 0
+	for _, offboarding := range due {
+		ok, err := ob.offboardingRepo.CompleteOffboarding(ctx, offboarding.TenantID, offboarding.ID, func(locked *models.Offboarding) (*dto.LeaveSettlementResponse, error) {
+			employee, err := ob.employeeRepo.GetEmployeeByID(ctx, locked.TenantID, locked.EmployeeID)
+			if err != nil {
+				return nil, fmt.Errorf("error loading employee: %w", err)
+			}
+			return ob.leaveSettlement(ctx, employee, locked.LastWorkingDay)
+		})
+		if err != nil {
+			log.Printf("offboarding %d: %v", offboarding.ID, err)
+			continue
+		}
+		if ok {
+			completed++
+		}
+	}
+	return completed, nil
+}
+
+// RunScheduler completes due offboardings now and then every interval until
+// ctx is cancelled.
+func (ob *OffboardingService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if completed, err := ob.CompleteDueOffboardings(ctx, time.Now()); err != nil {
+			log.Printf("offboarding scheduler: %v", err)
+		} else if completed > 0 {
+			log.Printf("offboarding scheduler: completed %d offboardings", completed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
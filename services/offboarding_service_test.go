@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+)
+
+func TestComputeLeaveSettlement(t *testing.T) {
+	balances := []repositories.LeaveBalanceRow{
+		{LeaveTypeID: 1, LeaveTypeName: "Annual", DaysPerYear: 20, BalanceDays: 15},
+		{LeaveTypeID: 2, LeaveTypeName: "Study", DaysPerYear: 10, BalanceDays: 2},
+	}
+
+	t.Run("settles the earned part of each balance", func(t *testing.T) {
+		// 2026-07-02 is day 183 of 365, almost exactly half the year
+		settlement := computeLeaveSettlement(balances, date("2026-07-02"), nil, "")
+
+		if settlement.Year != 2026 || len(settlement.Lines) != 2 {
+			t.Fatalf("got %+v, want two lines for 2026", settlement)
+		}
+		if annual := settlement.Lines[0]; annual.Accrued != 10.03 || annual.SettlementDays != 5.03 {
+			t.Errorf("got annual %+v, want 10.03 accrued and 5.03 to settle", annual)
+		}
+		if study := settlement.Lines[1]; study.SettlementDays != -2.99 {
+			t.Errorf("got study %+v, want -2.99 owed back", study)
+		}
+		if settlement.Days != 2.04 {
+			t.Errorf("got %v days, want 2.04", settlement.Days)
+		}
+		if settlement.Amount != nil {
+			t.Errorf("got amount %v, want none without a salary", *settlement.Amount)
+		}
+	})
+
+	t.Run("values the days at the daily rate", func(t *testing.T) {
+		salary := 2600000.0
+		settlement := computeLeaveSettlement(balances[:1], date("2026-12-31"), &salary, "NGN")
+
+		if settlement.Days != 15 {
+			t.Fatalf("got %v days, want the full balance of 15 on the last day of the year", settlement.Days)
+		}
+		if settlement.Amount == nil || *settlement.Amount != 150000 || settlement.Currency != "NGN" {
+			t.Errorf("got amount %v %s, want 150000 NGN", settlement.Amount, settlement.Currency)
+		}
+	})
+}
+
+func TestBuildOffboardingTasks(t *testing.T) {
+	manager := 7
+	specific := 12
+	items := []models.OffboardingChecklistItem{
+		{Title: "Exit interview", AssigneeType: OffboardingAssigneeHR, DueOffsetDays: -1},
+		{Title: "Sign off handover", AssigneeType: OffboardingAssigneeManager},
+		{Title: "Return laptop", AssigneeType: OffboardingAssigneeEmployee},
+		{Title: "Take over accounts", AssigneeType: OffboardingAssigneeSuccessor, DueOffsetDays: -3},
+		{Title: "Disable badge", AssigneeType: OffboardingAssigneeSpecific, AssigneeEmployeeID: &specific, DueOffsetDays: 1},
+	}
+
+	t.Run("resolves every assignee and due date", func(t *testing.T) {
+		employee := &models.Employee{ID: 3, ManagerID: &manager}
+		tasks := buildOffboardingTasks(items, employee, 9, 1, date("2026-03-31"))
+
+		wantAssignees := []int{1, 7, 3, 9, 12}
+		wantDue := []string{"2026-03-30", "2026-03-31", "2026-03-31", "2026-03-28", "2026-04-01"}
+		for i, task := range tasks {
+			if *task.AssigneeID != wantAssignees[i] {
+				t.Errorf("task %q: got assignee %d, want %d", task.Title, *task.AssigneeID, wantAssignees[i])
+			}
+			if got := task.DueDate.Format("2006-01-02"); got != wantDue[i] {
+				t.Errorf("task %q: got due %s, want %s", task.Title, got, wantDue[i])
+			}
+		}
+	})
+
+	t.Run("falls back to the initiator without a manager", func(t *testing.T) {
+		employee := &models.Employee{ID: 3}
+		tasks := buildOffboardingTasks(items[1:2], employee, 9, 1, date("2026-03-31"))
+
+		if *tasks[0].AssigneeID != 1 {
+			t.Errorf("got assignee %d, want the initiator 1", *tasks[0].AssigneeID)
+		}
+	})
+}
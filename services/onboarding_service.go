@@ -0,0 +1,447 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+// Who an onboarding template task is assigned to. HR and IT tasks go to the
+// named employee when one is set and otherwise to the user who created the
+// new hire.
+const (
+	OnboardingAssigneeHR      = "hr"
+	OnboardingAssigneeIT      = "it"
+	OnboardingAssigneeManager = "manager"
+	OnboardingAssigneeNewHire = "new_hire"
+)
+
+var (
+	validOnboardingAssignees        = []string{OnboardingAssigneeHR, OnboardingAssigneeIT, OnboardingAssigneeManager, OnboardingAssigneeNewHire}
+	validOnboardingTemplateStatuses = []string{"active", "inactive"}
+)
+
+// onboardingReminderInterval is the minimum time between two reminders for
+// the same overdue task.
+const onboardingReminderInterval = 24 * time.Hour
+
+// onboardingReminderBatch caps how many reminders one scheduler run sends
+const onboardingReminderBatch = 200
+
+type IOnboardingService interface {
+	ListTemplates(ctx context.Context, tenantID int) ([]*dto.OnboardingTemplateResponse, error)
+	CreateTemplate(ctx context.Context, tenantID int, req *dto.OnboardingTemplateRequest) (*dto.OnboardingTemplateResponse, error)
+	UpdateTemplate(ctx context.Context, tenantID int, id int, req *dto.OnboardingTemplateRequest) (*dto.OnboardingTemplateResponse, error)
+	DeleteTemplate(ctx context.Context, tenantID int, id int) error
+	ListMyTasks(ctx context.Context, actor Actor, status string) ([]*dto.OnboardingTaskResponse, error)
+	CompleteTask(ctx context.Context, actor Actor, taskID int, req *dto.CompleteOnboardingTaskRequest) (*dto.OnboardingTaskResponse, error)
+	GetEmployeeOnboarding(ctx context.Context, actor Actor, employeeID int) ([]*dto.OnboardingTaskResponse, error)
+	GetProgress(ctx context.Context, actor Actor) ([]*dto.OnboardingProgressResponse, error)
+}
+
+type OnboardingService struct {
+	onboardingRepo  *repositories.OnboardingRepository
+	employeeRepo    *repositories.EmployeeRepository
+	departmentRepo  *repositories.DepartmentRepository
+	designationRepo *repositories.DesignationRepository
+	mailer          Mailer
+}
+
+func NewOnboardingService(
+	onboardingRepo *repositories.OnboardingRepository,
+	employeeRepo *repositories.EmployeeRepository,
+	departmentRepo *repositories.DepartmentRepository,
+	designationRepo *repositories.DesignationRepository,
+	mailer Mailer,
+) *OnboardingService {
+	return &OnboardingService{
+		onboardingRepo:  onboardingRepo,
+		employeeRepo:    employeeRepo,
+		departmentRepo:  departmentRepo,
+		designationRepo: designationRepo,
+		mailer:          mailer,
+	}
+}
+
+func (on *OnboardingService) ListTemplates(ctx context.Context, tenantID int) ([]*dto.OnboardingTemplateResponse, error) {
+	templates, err := on.onboardingRepo.ListTemplates(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing onboarding templates: %w", err)
+	}
+
+	responses := make([]*dto.OnboardingTemplateResponse, len(templates))
+	for i := range templates {
+		responses[i] = templates[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// buildTemplate validates the request and turns it into a template. id is
+// zero for a new template; another template of the tenant may not share its
+// name.
+func (on *OnboardingService) buildTemplate(ctx context.Context, tenantID int, id int, req *dto.OnboardingTemplateRequest) (*models.OnboardingTemplate, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, &utils.ValidationError{Field: "name", Message: "Name is required"}
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "active"
+	}
+	if !containsString(validOnboardingTemplateStatuses, status) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validOnboardingTemplateStatuses, ", ")}
+	}
+
+	if req.DepartmentID != nil {
+		department, err := on.departmentRepo.GetDepartmentByID(ctx, *req.DepartmentID)
+		if err != nil || department.TenantID != tenantID {
+			return nil, &utils.ValidationError{Field: "department_id", Message: "Department not found"}
+		}
+	}
+	if req.DesignationID != nil {
+		designation, err := on.designationRepo.GetDesignationByID(ctx, *req.DesignationID)
+		if err != nil || designation.TenantID != tenantID {
+			return nil, &utils.ValidationError{Field: "designation_id", Message: "Designation not found"}
+		}
+	}
+
+	existing, err := on.onboardingRepo.ListTemplates(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing onboarding templates: %w", err)
+	}
+	for _, template := range existing {
+		if template.ID != id && strings.EqualFold(template.Name, name) {
+			return nil, &utils.ValidationError{Field: "name", Message: "A template with this name already exists"}
+		}
+	}
+
+	if len(req.Tasks) == 0 {
+		return nil, &utils.ValidationError{Field: "tasks", Message: "At least one task is required"}
+	}
+	tasks := make([]models.OnboardingTemplateTask, 0, len(req.Tasks))
+	for i, task := range req.Tasks {
+		field := fmt.Sprintf("tasks[%d]", i)
+		if strings.TrimSpace(task.Title) == "" {
+			return nil, &utils.ValidationError{Field: field + ".title", Message: "Title is required"}
+		}
+		if !containsString(validOnboardingAssignees, task.AssigneeType) {
+			return nil, &utils.ValidationError{Field: field + ".assignee_type", Message: "Assignee type must be one of " + strings.Join(validOnboardingAssignees, ", ")}
+		}
+		if task.AssigneeEmployeeID != nil {
+			if task.AssigneeType != OnboardingAssigneeHR && task.AssigneeType != OnboardingAssigneeIT {
+				return nil, &utils.ValidationError{Field: field + ".assignee_employee_id", Message: "Only HR and IT tasks can name an assignee"}
+			}
+			if _, err := on.employeeRepo.GetEmployeeByID(ctx, tenantID, *task.AssigneeEmployeeID); err != nil {
+				return nil, &utils.ValidationError{Field: field + ".assignee_employee_id", Message: "Assignee employee not found"}
+			}
+		}
+		tasks = append(tasks, models.OnboardingTemplateTask{
+			Title:              strings.TrimSpace(task.Title),
+			Description:        strings.TrimSpace(task.Description),
+			AssigneeType:       task.AssigneeType,
+			AssigneeEmployeeID: task.AssigneeEmployeeID,
+			DueOffsetDays:      task.DueOffsetDays,
+			SortOrder:          i,
+		})
+	}
+
+	return &models.OnboardingTemplate{
+		ID:            id,
+		TenantID:      tenantID,
+		Name:          name,
+		DepartmentID:  req.DepartmentID,
+		DesignationID: req.DesignationID,
+		Status:        status,
+		Tasks:         tasks,
+	}, nil
+}
+
+func (on *OnboardingService) CreateTemplate(ctx context.Context, tenantID int, req *dto.OnboardingTemplateRequest) (*dto.OnboardingTemplateResponse, error) {
+	template, err := on.buildTemplate(ctx, tenantID, 0, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := on.onboardingRepo.CreateTemplate(ctx, template)
+	if err != nil {
+		return nil, fmt.Errorf("error creating onboarding template: %w", err)
+	}
+	return created.ToResponse(), nil
+}
+
+func (on *OnboardingService) UpdateTemplate(ctx context.Context, tenantID int, id int, req *dto.OnboardingTemplateRequest) (*dto.OnboardingTemplateResponse, error) {
+	template, err := on.buildTemplate(ctx, tenantID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := on.onboardingRepo.UpdateTemplate(ctx, template)
+	if err != nil {
+		return nil, notFoundOr(err, "onboarding template")
+	}
+	return updated.ToResponse(), nil
+}
+
+func (on *OnboardingService) DeleteTemplate(ctx context.Context, tenantID int, id int) error {
+	if err := on.onboardingRepo.DeleteTemplate(ctx, tenantID, id); err != nil {
+		return notFoundOr(err, "onboarding template")
+	}
+	return nil
+}
+
+// matchOnboardingTemplate picks the active template that best fits a new
+// hire. A template for the hire's designation beats one for their department,
+// which beats a tenant-wide template; a template naming a department or
+// designation the hire does not have never matches. Ties go to the oldest
+// template.
+func matchOnboardingTemplate(templates []models.OnboardingTemplate, departmentID int, designationID int) *models.OnboardingTemplate {
+	var best *models.OnboardingTemplate
+	bestScore := -1
+	for i := range templates {
+		template := &templates[i]
+		if template.Status != "active" {
+			continue
+		}
+
+		score := 0
+		if template.DesignationID != nil {
+			if *template.DesignationID != designationID {
+				continue
+			}
+			score += 2
+		}
+		if template.DepartmentID != nil {
+			if *template.DepartmentID != departmentID {
+				continue
+			}
+			score++
+		}
+
+		if score > bestScore || (score == bestScore && template.ID < best.ID) {
+			best = template
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// buildOnboardingTasks turns a template into dated, assigned tasks for one new
+// hire. Tasks whose assignee cannot be resolved, such as the manager of a hire
+// without one, fall back to the user who created the hire.
+func buildOnboardingTasks(template *models.OnboardingTemplate, employee *models.Employee, createdBy int, startDate time.Time) []models.OnboardingTask {
+	tasks := make([]models.OnboardingTask, 0, len(template.Tasks))
+	for _, item := range template.Tasks {
+		assigneeID := createdBy
+		switch item.AssigneeType {
+		case OnboardingAssigneeHR, OnboardingAssigneeIT:
+			if item.AssigneeEmployeeID != nil {
+				assigneeID = *item.AssigneeEmployeeID
+			}
+		case OnboardingAssigneeManager:
+			if employee.ManagerID != nil {
+				assigneeID = *employee.ManagerID
+			}
+		case OnboardingAssigneeNewHire:
+			assigneeID = employee.ID
+		}
+
+		templateID := template.ID
+		tasks = append(tasks, models.OnboardingTask{
+			TenantID:     employee.TenantID,
+			EmployeeID:   employee.ID,
+			TemplateID:   &templateID,
+			Title:        item.Title,
+			Description:  item.Description,
+			AssigneeType: item.AssigneeType,
+			AssigneeID:   &assigneeID,
+			DueDate:      startDate.AddDate(0, 0, item.DueOffsetDays),
+		})
+	}
+	return tasks
+}
+
+// startOnboarding spawns the tasks of the best matching template for a new
+// hire. Tenants without a matching template simply get no tasks.
+func (on *OnboardingService) startOnboarding(ctx context.Context, employee *models.Employee, createdBy int, now time.Time) error {
+	templates, err := on.onboardingRepo.ListTemplates(ctx, employee.TenantID)
+	if err != nil {
+		return fmt.Errorf("error listing onboarding templates: %w", err)
+	}
+	template := matchOnboardingTemplate(templates, employee.DepartmentID, employee.DesignationID)
+	if template == nil || len(template.Tasks) == 0 {
+		return nil
+	}
+
+	startDate := today(now)
+	if employee.HireDate != nil {
+		startDate = *employee.HireDate
+	}
+
+	if _, err := on.onboardingRepo.CreateOnboardingTasks(ctx, buildOnboardingTasks(template, employee, createdBy, startDate)); err != nil {
+		return fmt.Errorf("error creating onboarding tasks: %w", err)
+	}
+	return nil
+}
+
+func onboardingTaskResponse(task *models.OnboardingTask, now time.Time) *dto.OnboardingTaskResponse {
+	response := task.ToResponse()
+	response.Overdue = task.Status == "pending" && task.DueDate.Before(today(now))
+	return response
+}
+
+func onboardingTaskResponses(tasks []models.OnboardingTask, now time.Time) []*dto.OnboardingTaskResponse {
+	responses := make([]*dto.OnboardingTaskResponse, len(tasks))
+	for i := range tasks {
+		responses[i] = onboardingTaskResponse(&tasks[i], now)
+	}
+	return responses
+}
+
+// ListMyTasks returns the onboarding tasks assigned to the caller
+func (on *OnboardingService) ListMyTasks(ctx context.Context, actor Actor, status string) ([]*dto.OnboardingTaskResponse, error) {
+	if status != "" && status != "pending" && status != "done" {
+		return nil, &utils.ValidationError{Field: "status", Message: "Status must be one of pending, done"}
+	}
+
+	tasks, err := on.onboardingRepo.ListTasksForAssignee(ctx, actor.TenantID, actor.EmployeeID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing onboarding tasks: %w", err)
+	}
+	return onboardingTaskResponses(tasks, time.Now()), nil
+}
+
+// CompleteTask marks an onboarding task done. Only its assignee or HR may do
+// so.
+func (on *OnboardingService) CompleteTask(ctx context.Context, actor Actor, taskID int, req *dto.CompleteOnboardingTaskRequest) (*dto.OnboardingTaskResponse, error) {
+	task, err := on.onboardingRepo.GetTask(ctx, actor.TenantID, taskID)
+	if err != nil {
+		return nil, notFoundOr(err, "onboarding task")
+	}
+	if !actor.IsHR() && (task.AssigneeID == nil || *task.AssigneeID != actor.EmployeeID) {
+		return nil, ErrForbidden
+	}
+	if task.Status != "pending" {
+		return nil, &utils.ValidationError{Field: "status", Message: "Task is already completed"}
+	}
+
+	completed, err := on.onboardingRepo.CompleteTask(ctx, actor.TenantID, taskID, actor.EmployeeID, strings.TrimSpace(req.Notes))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "status", Message: "Task is already completed"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error completing onboarding task: %w", err)
+	}
+	return onboardingTaskResponse(completed, time.Now()), nil
+}
+
+// GetEmployeeOnboarding returns a new hire's onboarding tasks to HR, the hire
+// and their manager.
+func (on *OnboardingService) GetEmployeeOnboarding(ctx context.Context, actor Actor, employeeID int) ([]*dto.OnboardingTaskResponse, error) {
+	employee, err := on.employeeRepo.GetEmployeeByID(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, notFoundOr(err, "employee")
+	}
+	if _, err := profileAccessFor(actor, employee); err != nil {
+		return nil, err
+	}
+
+	tasks, err := on.onboardingRepo.ListTasksForEmployee(ctx, actor.TenantID, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing onboarding tasks: %w", err)
+	}
+	return onboardingTaskResponses(tasks, time.Now()), nil
+}
+
+func onboardingProgressResponse(row *repositories.OnboardingProgressRow) *dto.OnboardingProgressResponse {
+	percent := 0
+	if row.TotalTasks > 0 {
+		percent = row.CompletedTasks * 100 / row.TotalTasks
+	}
+	return &dto.OnboardingProgressResponse{
+		EmployeeID:     row.EmployeeID,
+		FirstName:      row.FirstName,
+		LastName:       row.LastName,
+		StartDate:      row.StartDate,
+		TotalTasks:     row.TotalTasks,
+		CompletedTasks: row.CompletedTasks,
+		OverdueTasks:   row.OverdueTasks,
+		PercentDone:    percent,
+		NextDueDate:    row.NextDueDate,
+	}
+}
+
+// GetProgress returns the onboarding progress of every new hire in the tenant
+func (on *OnboardingService) GetProgress(ctx context.Context, actor Actor) ([]*dto.OnboardingProgressResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	rows, err := on.onboardingRepo.ListOnboardingProgress(ctx, actor.TenantID, today(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("error loading onboarding progress: %w", err)
+	}
+
+	responses := make([]*dto.OnboardingProgressResponse, len(rows))
+	for i := range rows {
+		responses[i] = onboardingProgressResponse(&rows[i])
+	}
+	return responses, nil
+}
+
+// SendOverdueReminders emails the assignee of every overdue onboarding task,
+// at most once per reminder interval, and returns how many reminders it sent.
+// It is safe to run from several instances at once.
+func (on *OnboardingService) SendOverdueReminders(ctx context.Context, now time.Time) (int, error) {
+	reminders, err := on.onboardingRepo.ClaimOverdueReminders(ctx, today(now), now.Add(-onboardingReminderInterval), onboardingReminderBatch)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming overdue onboarding tasks: %w", err)
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		newHire := reminder.NewHireFirstName + " " + reminder.NewHireLastName
+		subject := "Overdue onboarding task: " + reminder.Task.Title
+		body := fmt.Sprintf(
+			"The onboarding task %q for %s was due on %s and is still open.\n\nPlease complete it or let HR know if it no longer applies.",
+			reminder.Task.Title, newHire, reminder.Task.DueDate.Format("2006-01-02"),
+		)
+		if err := on.mailer.Send(ctx, reminder.AssigneeEmail, subject, body); err != nil {
+			log.Printf("onboarding task %d: error sending reminder: %v", reminder.Task.ID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// RunScheduler sends overdue task reminders now and then every interval until
+// ctx is cancelled.
+func (on *OnboardingService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := on.SendOverdueReminders(ctx, time.Now()); err != nil {
+			log.Printf("onboarding scheduler: %v", err)
+		} else if sent > 0 {
+			log.Printf("onboarding scheduler: sent %d overdue task reminders", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/falasefemi2/peopleos/models"
+)
+
+func TestMatchOnboardingTemplate(t *testing.T) {
+	engineering := 3
+	sales := 4
+	developer := 10
+	templates := []models.OnboardingTemplate{
+		{ID: 1, Name: "Everyone", Status: "active"},
+		{ID: 2, Name: "Engineering", DepartmentID: &engineering, Status: "active"},
+		{ID: 3, Name: "Developers", DepartmentID: &engineering, DesignationID: &developer, Status: "active"},
+		{ID: 4, Name: "Sales", DepartmentID: &sales, Status: "inactive"},
+		{ID: 5, Name: "Everyone (new)", Status: "active"},
+	}
+
+	tests := []struct {
+		name          string
+		departmentID  int
+		designationID int
+		wantID        int
+	}{
+		{"designation and department beat department alone", engineering, developer, 3},
+		{"department beats tenant-wide", engineering, 11, 2},
+		{"inactive templates are ignored", sales, 11, 1},
+		{"ties go to the oldest template", 99, 99, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchOnboardingTemplate(templates, tt.departmentID, tt.designationID)
+			if got == nil || got.ID != tt.wantID {
+				t.Errorf("got %+v, want template %d", got, tt.wantID)
+			}
+		})
+	}
+
+	t.Run("returns nil when nothing applies", func(t *testing.T) {
+		if got := matchOnboardingTemplate(templates[1:4], sales, 11); got != nil {
+			t.Errorf("got template %d, want none", got.ID)
+		}
+	})
+}
+
+func TestBuildOnboardingTasks(t *testing.T) {
+	manager := 7
+	itLead := 12
+	template := &models.OnboardingTemplate{
+		ID: 2,
+		Tasks: []models.OnboardingTemplateTask{
+			{Title: "Prepare contract", AssigneeType: OnboardingAssigneeHR, DueOffsetDays: -5},
+			{Title: "Provision laptop", AssigneeType: OnboardingAssigneeIT, AssigneeEmployeeID: &itLead, DueOffsetDays: -2},
+			{Title: "First week plan", AssigneeType: OnboardingAssigneeManager},
+			{Title: "Read handbook", AssigneeType: OnboardingAssigneeNewHire, DueOffsetDays: 7},
+		},
+	}
+	employee := &models.Employee{ID: 4, TenantID: 1, ManagerID: &manager}
+
+	tasks := buildOnboardingTasks(template, employee, 1, date("2026-03-02"))
+
+	if len(tasks) != 4 {
+		t.Fatalf("got %d tasks, want 4", len(tasks))
+	}
+	want := []struct {
+		assignee int
+		due      string
+	}{
+		{1, "2026-02-25"},
+		{12, "2026-02-28"},
+		{7, "2026-03-02"},
+		{4, "2026-03-09"},
+	}
+	for i, w := range want {
+		task := tasks[i]
+		if task.AssigneeID == nil || *task.AssigneeID != w.assignee || !task.DueDate.Equal(date(w.due)) {
+			t.Errorf("task %q: got assignee %v due %s, want %d due %s", task.Title, task.AssigneeID, task.DueDate.Format("2006-01-02"), w.assignee, w.due)
+		}
+		if task.EmployeeID != 4 || task.TemplateID == nil || *task.TemplateID != 2 {
+			t.Errorf("task %q: got employee %d template %v, want employee 4 template 2", task.Title, task.EmployeeID, task.TemplateID)
+		}
+	}
+
+	t.Run("falls back to the creator when the hire has no manager", func(t *testing.T) {
+		tasks := buildOnboardingTasks(template, &models.Employee{ID: 4, TenantID: 1}, 1, date("2026-03-02"))
+		if *tasks[2].AssigneeID != 1 {
+			t.Errorf("got assignee %d, want the creator 1", *tasks[2].AssigneeID)
+		}
+	})
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+)
+
+// A4 page size and margins, in PDF points
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 72.0
+)
+
+// Fonts every page can use. Both are standard PDF fonts, so nothing needs
+// embedding.
+const (
+	pdfFontRegular = "F1"
+	pdfFontBold    = "F2"
+)
+
+// pdfDocument builds a simple PDF of text pages. Text is drawn in Helvetica
+// with the WinAnsi encoding, so characters outside it print as "?". Pages
+// may also draw images, and files may be attached to the document.
+type pdfDocument struct {
+	title       string
+	pages       []*pdfPage
+	images      []*pdfImage
+	attachments []pdfAttachment
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// pdfImage is an opaque image stored as 8-bit RGB samples
+type pdfImage struct {
+	width  int
+	height int
+	rgb    []byte
+}
+
+// pdfAttachment is a file embedded in the document
+type pdfAttachment struct {
+	name        string
+	description string
+	contentType string
+	data        []byte
+}
+
+func newPDFDocument(title string) *pdfDocument {
+	return &pdfDocument{title: title}
+}
+
+func (pd *pdfDocument) addPage() *pdfPage {
+	page := &pdfPage{}
+	pd.pages = append(pd.pages, page)
+	return page
+}
+
+// addImage adds an image pages may draw and returns its name. Transparent
+// parts are drawn over white.
+func (pd *pdfDocument) addImage(img image.Image) string {
+	bounds := img.Bounds()
+	rgb := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Premultiplied components, so adding the white let through by
+			// the alpha flattens the pixel
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xFFFF - a
+			rgb = append(rgb, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+	pd.images = append(pd.images, &pdfImage{width: bounds.Dx(), height: bounds.Dy(), rgb: rgb})
+	return fmt.Sprintf("Im%d", len(pd.images))
+}
+
+// attach embeds a file in the document, listed in the reader's attachments
+// panel
+func (pd *pdfDocument) attach(name string, description string, contentType string, data []byte) {
+	pd.attachments = append(pd.attachments, pdfAttachment{name: name, description: description, contentType: contentType, data: data})
+}
+
+// text draws s with its baseline starting at x, y
+func (pp *pdfPage) text(x float64, y float64, font string, size float64, s string) {
+	fmt.Fprintf(&pp.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(pdfWinAnsi(s)))
+}
+
+// line draws a thin line from x1, y1 to x2, y2
+func (pp *pdfPage) line(x1 float64, y1 float64, x2 float64, y2 float64) {
+	fmt.Fprintf(&pp.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// image draws the named image into the box with its lower left corner at
+// x, y
+func (pp *pdfPage) image(name string, x float64, y float64, width float64, height float64) {
+	fmt.Fprintf(&pp.content, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q\n", width, height, x, y, name)
+}
+
+func pdfDeflate(data []byte) ([]byte, error) {
+	var deflated bytes.Buffer
+	writer := zlib.NewWriter(&deflated)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return deflated.Bytes(), nil
+}
+
+// pdfStream writes a deflated stream object with the given dictionary
+// entries
+func pdfStream(entries string, data []byte) (string, error) {
+	deflated, err := pdfDeflate(data)
+	if err != nil {
+		return "", err
+	}
+	dictionary := fmt.Sprintf("/Length %d /Filter /FlateDecode", len(deflated))
+	if entries != "" {
+		dictionary = entries + " " + dictionary
+	}
+	return fmt.Sprintf("<< %s >>\nstream\n%s\nendstream", dictionary, deflated), nil
+}
+
+// bytes writes out the document. Page contents, images and attachments are
+// deflated.
+func (pd *pdfDocument) bytes() ([]byte, error) {
+	if len(pd.pages) == 0 {
+		pd.addPage()
+	}
+
+	// Objects 1 to 5 are the catalog, page tree, fonts and info. Images and
+	// attachments follow, then each page takes two objects, the page and its
+	// content stream.
+	objects := []string{
+		"",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (PeopleOS) >>", pdfEscape(pdfWinAnsi(pd.title))),
+	}
+	add := func(object string) int {
+		objects = append(objects, object)
+		return len(objects)
+	}
+
+	resources := fmt.Sprintf("/Font << /%s 3 0 R /%s 4 0 R >>", pdfFontRegular, pdfFontBold)
+	if len(pd.images) > 0 {
+		xobjects := make([]string, len(pd.images))
+		for i, img := range pd.images {
+			stream, err := pdfStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8",
+				img.width, img.height), img.rgb)
+			if err != nil {
+				return nil, err
+			}
+			xobjects[i] = fmt.Sprintf("/Im%d %d 0 R", i+1, add(stream))
+		}
+		resources += " /XObject << " + strings.Join(xobjects, " ") + " >>"
+	}
+
+	catalog := "<< /Type /Catalog /Pages 2 0 R >>"
+	if len(pd.attachments) > 0 {
+		// The keys of a name tree must be sorted
+		attachments := append([]pdfAttachment{}, pd.attachments...)
+		sort.SliceStable(attachments, func(i, j int) bool {
+			return pdfWinAnsi(attachments[i].name) < pdfWinAnsi(attachments[j].name)
+		})
+		names := make([]string, len(attachments))
+		for i, attachment := range attachments {
+			stream, err := pdfStream(fmt.Sprintf("/Type /EmbeddedFile /Subtype /%s /Params << /Size %d >>",
+				pdfName(attachment.contentType), len(attachment.data)), attachment.data)
+			if err != nil {
+				return nil, err
+			}
+			file := add(stream)
+			name := pdfEscape(pdfWinAnsi(attachment.name))
+			spec := add(fmt.Sprintf("<< /Type /Filespec /F (%s) /UF (%s) /Desc (%s) /EF << /F %d 0 R >> >>",
+				name, name, pdfEscape(pdfWinAnsi(attachment.description)), file))
+			names[i] = fmt.Sprintf("(%s) %d 0 R", name, spec)
+		}
+		catalog = fmt.Sprintf("<< /Type /Catalog /Pages 2 0 R /Names << /EmbeddedFiles << /Names [%s] >> >> /PageMode /UseAttachments >>",
+			strings.Join(names, " "))
+	}
+	objects[0] = catalog
+
+	kids := make([]string, len(pd.pages))
+	for i, page := range pd.pages {
+		pageObject := len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", pageObject)
+
+		stream, err := pdfStream("", page.content.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		add(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << %s >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, resources, pageObject+1))
+		add(stream)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pd.pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes(), nil
+}
+
+// pdfName writes s as the body of a PDF name, escaping the characters a name
+// cannot hold, such as the slash of a media type
+func pdfName(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_' || c == '+' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "#%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// pdfEscape escapes a string for use in a PDF literal string
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r', '\n', '\t':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// pdfWinAnsiExtras maps the characters WinAnsi places in 0x80-0x9F
+var pdfWinAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfWinAnsi encodes s in WinAnsi, which matches Latin-1 outside 0x80-0x9F
+func pdfWinAnsi(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			b.WriteByte(byte(r))
+		case pdfWinAnsiExtras[r] != 0:
+			b.WriteByte(pdfWinAnsiExtras[r])
+		case r == '\t':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// Widths of the printable ASCII characters, from space to tilde, in
+// thousandths of the font size
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// pdfTextWidth measures s in points. Characters outside ASCII are taken to
+// be as wide as a digit.
+func pdfTextWidth(s string, font string, size float64) float64 {
+	widths := &helveticaWidths
+	if font == pdfFontBold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7F {
+			total += widths[r-0x20]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfWrap breaks a line of text into lines no wider than width, breaking
+// between words and, for words too long to fit, inside them
+func pdfWrap(text string, font string, size float64, width float64) []string {
+	lines := []string{}
+	current := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if pdfTextWidth(candidate, font, size) <= width {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+			current = ""
+		}
+		for pdfTextWidth(word, font, size) > width {
+			runes := []rune(word)
+			cut := len(runes) - 1
+			for cut > 1 && pdfTextWidth(string(runes[:cut]), font, size) > width {
+				cut--
+			}
+			lines = append(lines, string(runes[:cut]))
+			word = string(runes[cut:])
+		}
+		current = word
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// pdfTextFlow lays text out down the pages of a document, starting a new
+// page when one is full
+type pdfTextFlow struct {
+	document *pdfDocument
+	page     *pdfPage
+	y        float64
+}
+
+func newPDFTextFlow(document *pdfDocument) *pdfTextFlow {
+	return &pdfTextFlow{document: document}
+}
+
+// space moves down by height points, starting a new page when there is no
+// room left for height more
+func (pf *pdfTextFlow) space(height float64) {
+	if pf.page == nil || pf.y-height < pdfMargin {
+		pf.page = pf.document.addPage()
+		pf.y = pdfPageHeight - pdfMargin
+		return
+	}
+	pf.y -= height
+}
+
+// paragraph writes text wrapped to the page width with leading points
+// between lines
+func (pf *pdfTextFlow) paragraph(text string, font string, size float64, leading float64) {
+	for _, line := range pdfWrap(text, font, size, pdfPageWidth-2*pdfMargin) {
+		pf.space(leading)
+		pf.page.text(pdfMargin, pf.y, font, size, line)
+	}
+}
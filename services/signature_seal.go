@@ -0,0 +1,260 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+const (
+	// maxSignatureImageBytes caps the size of a drawn signature's PNG
+	maxSignatureImageBytes = 256 << 10
+	maxSignatureImageWidth = 2000
+	// maxSignatureImageHeight is low as signatures are wide, not tall
+	maxSignatureImageHeight = 1000
+
+	// Drawn signatures are scaled to fit this box on the certificate
+	certificateImageWidth  = 180.0
+	certificateImageHeight = 60.0
+)
+
+// signatureCapture is a validated signature
+type signatureCapture struct {
+	signatureType string
+	typedName     string
+	image         []byte
+}
+
+// validateSignature checks a signature. Typed signatures need the signer's
+// typed name; drawn ones a PNG, which is decoded to make sure it is one.
+func validateSignature(req *dto.SignDocumentRequest) (*signatureCapture, error) {
+	if !req.Consent {
+		return nil, &utils.ValidationError{Field: "consent", Message: "You must agree to sign electronically"}
+	}
+
+	typedName := strings.Join(strings.Fields(req.TypedName), " ")
+	if len(typedName) > 100 {
+		return nil, &utils.ValidationError{Field: "typed_name", Message: "Typed name must be at most 100 characters"}
+	}
+
+	switch req.SignatureType {
+	case SignatureTypeTyped:
+		if typedName == "" {
+			return nil, &utils.ValidationError{Field: "typed_name", Message: "Typed name is required"}
+		}
+		return &signatureCapture{signatureType: SignatureTypeTyped, typedName: typedName}, nil
+	case SignatureTypeDrawn:
+		content, _, err := decodeSignatureImage(req.Image)
+		if err != nil {
+			return nil, err
+		}
+		return &signatureCapture{signatureType: SignatureTypeDrawn, typedName: typedName, image: content}, nil
+	default:
+		return nil, &utils.ValidationError{Field: "signature_type", Message: "Signature type must be typed or drawn"}
+	}
+}
+
+// decodeSignatureImage decodes a drawn signature sent as base64, with or
+// without a data URL prefix. The size is checked before the image is
+// decoded, so a small file cannot expand into a huge one.
+func decodeSignatureImage(encoded string) ([]byte, image.Image, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, nil, &utils.ValidationError{Field: "image", Message: "Image is required for a drawn signature"}
+	}
+	if strings.HasPrefix(encoded, "data:") {
+		comma := strings.Index(encoded, ",")
+		if comma < 0 || encoded[:comma] != "data:image/png;base64" {
+			return nil, nil, &utils.ValidationError{Field: "image", Message: "Image must be a base64 PNG"}
+		}
+		encoded = encoded[comma+1:]
+	}
+	if base64.StdEncoding.DecodedLen(len(encoded)) > maxSignatureImageBytes+2 {
+		return nil, nil, &utils.ValidationError{Field: "image", Message: fmt.Sprintf("Image must be at most %d bytes", maxSignatureImageBytes)}
+	}
+
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, &utils.ValidationError{Field: "image", Message: "Image must be a base64 PNG"}
+	}
+	if len(content) > maxSignatureImageBytes {
+		return nil, nil, &utils.ValidationError{Field: "image", Message: fmt.Sprintf("Image must be at most %d bytes", maxSignatureImageBytes)}
+	}
+
+	config, err := png.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, &utils.ValidationError{Field: "image", Message: "Image must be a base64 PNG"}
+	}
+	if config.Width < 1 || config.Height < 1 || config.Width > maxSignatureImageWidth || config.Height > maxSignatureImageHeight {
+		return nil, nil, &utils.ValidationError{Field: "image", Message: fmt.Sprintf("Image must be at most %d by %d pixels", maxSignatureImageWidth, maxSignatureImageHeight)}
+	}
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, &utils.ValidationError{Field: "image", Message: "Image must be a base64 PNG"}
+	}
+	return content, img, nil
+}
+
+// signerOf returns the signer of request that is employeeID, if any
+func signerOf(request *models.SignatureRequest, employeeID int) *models.SignatureSigner {
+	for i := range request.Signers {
+		if request.Signers[i].EmployeeID == employeeID {
+			return &request.Signers[i]
+		}
+	}
+	return nil
+}
+
+// checkSignerTurn returns an error unless signer may sign request now: the
+// request must be pending, the signer must not have signed or declined and
+// every signer with a lower order must have signed
+func checkSignerTurn(request *models.SignatureRequest, signer *models.SignatureSigner) error {
+	if request.Status != SignatureRequestPending {
+		return &utils.ValidationError{Field: "status", Message: "Signature request is " + request.Status}
+	}
+	if signer.Status != SignerPending {
+		return &utils.ValidationError{Field: "status", Message: "You have already " + signer.Status + " this request"}
+	}
+	for _, other := range request.Signers {
+		if other.SignOrder < signer.SignOrder && other.Status != SignerSigned {
+			return &utils.ValidationError{Field: "status", Message: "Waiting for earlier signers to sign first"}
+		}
+	}
+	return nil
+}
+
+// sealedFileName names the sealed PDF of a signed file
+func sealedFileName(fileName string) string {
+	base := strings.TrimSuffix(fileName, path.Ext(fileName))
+	if base == "" {
+		return "signed.pdf"
+	}
+	return base + " (signed).pdf"
+}
+
+func formatCertificateTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02 15:04:05 MST")
+}
+
+// renderSignatureCertificate writes the sealed PDF of a completed request:
+// a certificate listing the document's checksum and when, where from and
+// how each signer signed, with the signed file attached. images holds the
+// drawn signatures by signer ID.
+func renderSignatureCertificate(request *models.SignatureRequest, original []byte, contentType string, images map[int]image.Image, sealedAt time.Time) ([]byte, error) {
+	document := newPDFDocument("Signature certificate: " + request.Title)
+	flow := newPDFTextFlow(document)
+	flow.paragraph("Signature certificate", pdfFontBold, 16, 20)
+	flow.space(6)
+	flow.paragraph(request.Title, pdfFontBold, 12, 16)
+	flow.space(4)
+
+	details := []string{
+		fmt.Sprintf("Document: %s (version %d)", request.DocumentName, request.DocumentVersion),
+		"SHA-256 of the signed document: " + request.DocumentChecksum,
+		"Signature request: " + strconv.Itoa(request.ID),
+		fmt.Sprintf("Requested by %s on %s", request.CreatedByName, formatCertificateTime(&request.CreatedAt)),
+		"Completed: " + formatCertificateTime(request.CompletedAt),
+		"Sealed: " + formatCertificateTime(&sealedAt),
+	}
+	for _, line := range details {
+		flow.paragraph(line, pdfFontRegular, 10, 14)
+	}
+	flow.space(10)
+	flow.paragraph("Signers", pdfFontBold, 12, 16)
+
+	for i, signer := range request.Signers {
+		flow.space(6)
+		flow.paragraph(fmt.Sprintf("%d. %s", i+1, signer.EmployeeName), pdfFontBold, 10, 14)
+		lines := []string{
+			"Email: " + signer.EmployeeEmail,
+			"Signed: " + formatCertificateTime(signer.SignedAt),
+			"IP address: " + signer.IPAddress,
+			"Browser: " + signer.UserAgent,
+			"Method: " + signer.SignatureType + " signature",
+		}
+		if signer.SignatureType == SignatureTypeDrawn && signer.TypedName != "" {
+			lines = append(lines, "Printed name: "+signer.TypedName)
+		}
+		for _, line := range lines {
+			flow.paragraph(line, pdfFontRegular, 9, 12)
+		}
+
+		if img, ok := images[signer.ID]; ok {
+			width, height := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+			scale := certificateImageWidth / width
+			if certificateImageHeight/height < scale {
+				scale = certificateImageHeight / height
+			}
+			name := document.addImage(img)
+			flow.space(height*scale + 6)
+			flow.page.image(name, pdfMargin, flow.y, width*scale, height*scale)
+		}
+		if signer.SignatureType == SignatureTypeTyped {
+			flow.space(4)
+			flow.paragraph(signer.TypedName, pdfFontBold, 18, 22)
+		}
+	}
+
+	flow.space(12)
+	flow.paragraph("The signed document is attached to this PDF. Its SHA-256 checksum identifies the exact file every signer was shown.", pdfFontRegular, 9, 12)
+
+	document.attach(request.DocumentName, "Signed document", contentType, original)
+	return document.bytes()
+}
+
+// policyAcknowledgementReport lists the employees who have not acknowledged
+// a policy version. An acknowledgement counts even if its request was later
+// cancelled; an employee still asked to acknowledge it is pending.
+func policyAcknowledgementReport(policyName string, policyVersion string, subjects []repositories.RequirementSubject, signers []repositories.PolicySigner) *dto.PolicyAcknowledgementReport {
+	acknowledged := map[int]bool{}
+	pending := map[int]repositories.PolicySigner{}
+	for _, signer := range signers {
+		switch {
+		case signer.Status == SignerSigned:
+			acknowledged[signer.EmployeeID] = true
+		case signer.Status == SignerPending && signer.RequestStatus == SignatureRequestPending:
+			// Signers come oldest request first, so the latest request wins
+			pending[signer.EmployeeID] = signer
+		}
+	}
+
+	report := &dto.PolicyAcknowledgementReport{
+		PolicyName:     policyName,
+		PolicyVersion:  policyVersion,
+		TotalEmployees: len(subjects),
+		Outstanding:    []dto.PolicyAcknowledgementGap{},
+	}
+	for _, subject := range subjects {
+		if acknowledged[subject.EmployeeID] {
+			report.Acknowledged++
+			continue
+		}
+		gap := dto.PolicyAcknowledgementGap{
+			EmployeeID:   subject.EmployeeID,
+			EmployeeName: strings.TrimSpace(subject.FirstName + " " + subject.LastName),
+			Status:       "not_requested",
+		}
+		if signer, ok := pending[subject.EmployeeID]; ok {
+			requestID, requestedAt := signer.RequestID, signer.RequestedAt
+			gap.Status = SignerPending
+			gap.RequestID = &requestID
+			gap.RequestedAt = &requestedAt
+		}
+		report.Outstanding = append(report.Outstanding, gap)
+	}
+	return report
+}
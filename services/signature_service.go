@@ -0,0 +1,679 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+const (
+	SignatureKindSignature       = "signature"
+	SignatureKindAcknowledgement = "acknowledgement"
+
+	SignatureTypeTyped = "typed"
+	SignatureTypeDrawn = "drawn"
+
+	SignatureRequestPending   = "pending"
+	SignatureRequestCompleted = "completed"
+	SignatureRequestDeclined  = "declined"
+	SignatureRequestCancelled = "cancelled"
+
+	SignerPending  = "pending"
+	SignerSigned   = "signed"
+	SignerDeclined = "declined"
+)
+
+// maxSignatureSigners caps the signers listed on one request. Requests to
+// all employees, used for policy acknowledgements, are not capped.
+const maxSignatureSigners = 500
+
+var (
+	validSignatureKinds           = []string{SignatureKindSignature, SignatureKindAcknowledgement}
+	validSignatureRequestStatuses = []string{SignatureRequestPending, SignatureRequestCompleted, SignatureRequestDeclined, SignatureRequestCancelled}
+)
+
+type ISignatureService interface {
+	ListSignatureRequests(ctx context.Context, actor Actor, status string, kind string) ([]*dto.SignatureRequestResponse, error)
+	CreateSignatureRequest(ctx context.Context, actor Actor, req *dto.SignatureRequestRequest) (*dto.SignatureRequestResponse, error)
+	CancelSignatureRequest(ctx context.Context, actor Actor, id int) error
+	SealSignatureRequest(ctx context.Context, actor Actor, id int) (*dto.SignatureRequestResponse, error)
+	ListMySignatureRequests(ctx context.Context, actor Actor, status string) ([]*dto.SignatureRequestResponse, error)
+	GetSignatureRequest(ctx context.Context, actor Actor, id int) (*dto.SignatureRequestResponse, error)
+	OpenSignatureDocument(ctx context.Context, actor Actor, id int) (io.ReadCloser, *models.DocumentVersion, error)
+	OpenSealedDocument(ctx context.Context, actor Actor, id int) (io.ReadCloser, *models.DocumentVersion, error)
+	SignSignatureRequest(ctx context.Context, actor Actor, id int, req *dto.SignDocumentRequest) (*dto.SignatureRequestResponse, error)
+	DeclineSignatureRequest(ctx context.Context, actor Actor, id int, req *dto.DeclineSignatureRequest) (*dto.SignatureRequestResponse, error)
+	PolicyAcknowledgementReport(ctx context.Context, actor Actor, policyName string, policyVersion string) (*dto.PolicyAcknowledgementReport, error)
+}
+
+type SignatureService struct {
+	signatureRepo *repositories.SignatureRepository
+	documentRepo  *repositories.DocumentRepository
+	storage       DocumentStorage
+	mailer        Mailer
+}
+
+func NewSignatureService(signatureRepo *repositories.SignatureRepository, documentRepo *repositories.DocumentRepository, storage DocumentStorage, mailer Mailer) *SignatureService {
+	return &SignatureService{
+		signatureRepo: signatureRepo,
+		documentRepo:  documentRepo,
+		storage:       storage,
+		mailer:        mailer,
+	}
+}
+
+func signatureRequestResponses(requests []models.SignatureRequest) []*dto.SignatureRequestResponse {
+	responses := make([]*dto.SignatureRequestResponse, len(requests))
+	for i := range requests {
+		responses[i] = requests[i].ToResponse()
+	}
+	return responses
+}
+
+func validateSignatureRequestFilter(status string, kind string) error {
+	if status != "" && !containsString(validSignatureRequestStatuses, status) {
+		return &utils.ValidationError{Field: "status", Message: "Status must be one of " + strings.Join(validSignatureRequestStatuses, ", ")}
+	}
+	if kind != "" && !containsString(validSignatureKinds, kind) {
+		return &utils.ValidationError{Field: "kind", Message: "Kind must be one of " + strings.Join(validSignatureKinds, ", ")}
+	}
+	return nil
+}
+
+// ListSignatureRequests returns the tenant's signature requests. Only HR may
+// list them all.
+func (ss *SignatureService) ListSignatureRequests(ctx context.Context, actor Actor, status string, kind string) ([]*dto.SignatureRequestResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+	if err := validateSignatureRequestFilter(status, kind); err != nil {
+		return nil, err
+	}
+
+	requests, err := ss.signatureRepo.ListSignatureRequests(ctx, actor.TenantID, status, kind)
+	if err != nil {
+		return nil, fmt.Errorf("error listing signature requests: %w", err)
+	}
+	return signatureRequestResponses(requests), nil
+}
+
+// validateSignatureRequest checks a signature request and turns it into one
+// without its document. Signers without an order sign one after the other;
+// acknowledgements, which each signer makes for themselves, default to
+// everyone at once.
+func validateSignatureRequest(req *dto.SignatureRequestRequest) (*models.SignatureRequest, error) {
+	if req.DocumentID == 0 {
+		return nil, &utils.ValidationError{Field: "document_id", Message: "Document is required"}
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = SignatureKindSignature
+	}
+	if !containsString(validSignatureKinds, kind) {
+		return nil, &utils.ValidationError{Field: "kind", Message: "Kind must be one of " + strings.Join(validSignatureKinds, ", ")}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if len(title) > 255 {
+		return nil, &utils.ValidationError{Field: "title", Message: "Title must be at most 255 characters"}
+	}
+
+	policyName := strings.TrimSpace(req.PolicyName)
+	policyVersion := strings.TrimSpace(req.PolicyVersion)
+	if kind == SignatureKindAcknowledgement {
+		if policyName == "" {
+			return nil, &utils.ValidationError{Field: "policy_name", Message: "Policy name is required for an acknowledgement"}
+		}
+		if len(policyName) > 100 {
+			return nil, &utils.ValidationError{Field: "policy_name", Message: "Policy name must be at most 100 characters"}
+		}
+		if policyVersion == "" {
+			return nil, &utils.ValidationError{Field: "policy_version", Message: "Policy version is required for an acknowledgement"}
+		}
+		if len(policyVersion) > 50 {
+			return nil, &utils.ValidationError{Field: "policy_version", Message: "Policy version must be at most 50 characters"}
+		}
+	} else if policyName != "" || policyVersion != "" {
+		return nil, &utils.ValidationError{Field: "policy_name", Message: "Only acknowledgements record a policy"}
+	}
+
+	if req.AllEmployees {
+		if kind != SignatureKindAcknowledgement {
+			return nil, &utils.ValidationError{Field: "all_employees", Message: "Only acknowledgements can go to all employees"}
+		}
+		if len(req.Signers) > 0 {
+			return nil, &utils.ValidationError{Field: "signers", Message: "Signers cannot be listed when the request goes to all employees"}
+		}
+	} else {
+		if len(req.Signers) == 0 {
+			return nil, &utils.ValidationError{Field: "signers", Message: "At least one signer is required"}
+		}
+		if len(req.Signers) > maxSignatureSigners {
+			return nil, &utils.ValidationError{Field: "signers", Message: fmt.Sprintf("A request can have at most %d signers", maxSignatureSigners)}
+		}
+	}
+
+	signers := make([]models.SignatureSigner, len(req.Signers))
+	seen := map[int]bool{}
+	for i, signer := range req.Signers {
+		if signer.EmployeeID == 0 {
+			return nil, &utils.ValidationError{Field: "signers", Message: "Every signer needs an employee"}
+		}
+		if seen[signer.EmployeeID] {
+			return nil, &utils.ValidationError{Field: "signers", Message: "An employee can only be listed once"}
+		}
+		seen[signer.EmployeeID] = true
+
+		order := signer.Order
+		if order == 0 {
+			order = i + 1
+			if kind == SignatureKindAcknowledgement {
+				order = 1
+			}
+		}
+		if order < 1 {
+			return nil, &utils.ValidationError{Field: "signers", Message: "Signing order must be at least 1"}
+		}
+		signers[i] = models.SignatureSigner{EmployeeID: signer.EmployeeID, SignOrder: order}
+	}
+
+	return &models.SignatureRequest{
+		DocumentID:    req.DocumentID,
+		Kind:          kind,
+		Title:         title,
+		Message:       strings.TrimSpace(req.Message),
+		PolicyName:    policyName,
+		PolicyVersion: policyVersion,
+		Signers:       signers,
+	}, nil
+}
+
+// CreateSignatureRequest asks employees to sign, or acknowledge, the current
+// version of a document and tells the first signers. Only HR may create
+// signature requests.
+func (ss *SignatureService) CreateSignatureRequest(ctx context.Context, actor Actor, req *dto.SignatureRequestRequest) (*dto.SignatureRequestResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+	request, err := validateSignatureRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := ss.documentRepo.GetDocument(ctx, actor.TenantID, request.DocumentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &utils.ValidationError{Field: "document_id", Message: "Document not found"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting document: %w", err)
+	}
+	if document.StorageKey == "" {
+		return nil, &utils.ValidationError{Field: "document_id", Message: "Document has no file to sign"}
+	}
+
+	var ids []int
+	if !req.AllEmployees {
+		ids = make([]int, len(request.Signers))
+		for i, signer := range request.Signers {
+			ids[i] = signer.EmployeeID
+		}
+	}
+	active, err := ss.signatureRepo.ListActiveEmployeeIDs(ctx, actor.TenantID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error checking signers: %w", err)
+	}
+	if req.AllEmployees {
+		if len(active) == 0 {
+			return nil, &utils.ValidationError{Field: "all_employees", Message: "There are no employees to ask"}
+		}
+		for _, id := range active {
+			request.Signers = append(request.Signers, models.SignatureSigner{EmployeeID: id, SignOrder: 1})
+		}
+	}
+	for _, signer := range request.Signers {
+		if !containsInt(active, signer.EmployeeID) {
+			return nil, &utils.ValidationError{Field: "signers", Message: fmt.Sprintf("Employee %d not found or no longer employed", signer.EmployeeID)}
+		}
+	}
+
+	if request.Title == "" {
+		request.Title = "Please sign " + document.FileName
+		if request.Kind == SignatureKindAcknowledgement {
+			request.Title = fmt.Sprintf("Please acknowledge %s %s", request.PolicyName, request.PolicyVersion)
+		}
+	}
+	request.TenantID = actor.TenantID
+	request.DocumentVersion = document.CurrentVersion
+	request.DocumentChecksum = document.Checksum
+	request.CreatedBy = &actor.EmployeeID
+
+	created, err := ss.signatureRepo.CreateSignatureRequest(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("error creating signature request: %w", err)
+	}
+	ss.notifySigners(ctx, created)
+	return created.ToResponse(), nil
+}
+
+// notifySigners emails the signers whose turn it now is. Failures are only
+// logged, as the request is waiting for them on their list either way.
+func (ss *SignatureService) notifySigners(ctx context.Context, request *models.SignatureRequest) {
+	signers, err := ss.signatureRepo.ClaimSignerNotifications(ctx, request.TenantID, request.ID)
+	if err != nil {
+		log.Printf("signature request %d: error claiming signer notifications: %v", request.ID, err)
+		return
+	}
+
+	verb := "sign"
+	if request.Kind == SignatureKindAcknowledgement {
+		verb = "acknowledge"
+	}
+	for _, signer := range signers {
+		subject := fmt.Sprintf("Please %s: %s", verb, request.Title)
+		body := fmt.Sprintf("%s has asked you to %s %s.", request.CreatedByName, verb, request.DocumentName)
+		if request.Message != "" {
+			body += "\n\n" + request.Message
+		}
+		body += fmt.Sprintf("\n\nSignature request: %d", request.ID)
+		if err := ss.mailer.Send(ctx, signer.EmployeeEmail, subject, body); err != nil {
+			log.Printf("signature request %d: error notifying signer %d: %v", request.ID, signer.ID, err)
+		}
+	}
+}
+
+// authorizeSignatureRequest loads a request the actor may see, which HR and
+// its signers may, and returns the actor's signer entry, if any
+func (ss *SignatureService) authorizeSignatureRequest(ctx context.Context, actor Actor, id int) (*models.SignatureRequest, *models.SignatureSigner, error) {
+	request, err := ss.signatureRepo.GetSignatureRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, nil, notFoundOr(err, "signature request")
+	}
+	signer := signerOf(request, actor.EmployeeID)
+	if signer == nil && !actor.IsHR() {
+		return nil, nil, ErrForbidden
+	}
+	return request, signer, nil
+}
+
+func (ss *SignatureService) GetSignatureRequest(ctx context.Context, actor Actor, id int) (*dto.SignatureRequestResponse, error) {
+	request, _, err := ss.authorizeSignatureRequest(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	return request.ToResponse(), nil
+}
+
+// ListMySignatureRequests returns the requests the actor is a signer of
+func (ss *SignatureService) ListMySignatureRequests(ctx context.Context, actor Actor, status string) ([]*dto.SignatureRequestResponse, error) {
+	if err := validateSignatureRequestFilter(status, ""); err != nil {
+		return nil, err
+	}
+
+	requests, err := ss.signatureRepo.ListEmployeeSignatureRequests(ctx, actor.TenantID, actor.EmployeeID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error listing signature requests: %w", err)
+	}
+	return signatureRequestResponses(requests), nil
+}
+
+// openDocumentVersion returns the content of a version of a request's
+// document. Signers may open it even if they could not otherwise see the
+// document.
+func (ss *SignatureService) openDocumentVersion(ctx context.Context, request *models.SignatureRequest, version int) (io.ReadCloser, *models.DocumentVersion, error) {
+	documentVersion, err := ss.documentRepo.GetDocumentVersion(ctx, request.TenantID, request.DocumentID, version)
+	if err != nil {
+		return nil, nil, notFoundOr(err, "document version")
+	}
+
+	content, err := ss.storage.Get(ctx, documentVersion.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, documentVersion, nil
+}
+
+// OpenSignatureDocument returns the content of the document version a
+// request asks to be signed
+func (ss *SignatureService) OpenSignatureDocument(ctx context.Context, actor Actor, id int) (io.ReadCloser, *models.DocumentVersion, error) {
+	request, _, err := ss.authorizeSignatureRequest(ctx, actor, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ss.openDocumentVersion(ctx, request, request.DocumentVersion)
+}
+
+// OpenSealedDocument returns the sealed PDF of a completed request
+func (ss *SignatureService) OpenSealedDocument(ctx context.Context, actor Actor, id int) (io.ReadCloser, *models.DocumentVersion, error) {
+	request, _, err := ss.authorizeSignatureRequest(ctx, actor, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if request.SealedVersion == nil {
+		return nil, nil, fmt.Errorf("sealed document %w", ErrNotFound)
+	}
+	return ss.openDocumentVersion(ctx, request, *request.SealedVersion)
+}
+
+// newSignatureImageKey returns a fresh storage key for a drawn signature
+func newSignatureImageKey(tenantID int) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/signatures/%s", tenantID, hex.EncodeToString(random)), nil
+}
+
+// removeOrphanedFile deletes a stored file whose record could not be saved
+func (ss *SignatureService) removeOrphanedFile(ctx context.Context, key string) {
+	if err := ss.storage.Delete(ctx, key); err != nil {
+		log.Printf("signature request: error removing orphaned file %s: %v", key, err)
+	}
+}
+
+// SignSignatureRequest records the actor's signature, with the IP address
+// and user agent it was made from. The last signature of a signature request
+// seals it; otherwise the signers whose turn it now is are told.
+func (ss *SignatureService) SignSignatureRequest(ctx context.Context, actor Actor, id int, req *dto.SignDocumentRequest) (*dto.SignatureRequestResponse, error) {
+	request, signer, err := ss.authorizeSignatureRequest(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		return nil, ErrForbidden
+	}
+	if err := checkSignerTurn(request, signer); err != nil {
+		return nil, err
+	}
+	capture, err := validateSignature(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if capture.image != nil {
+		key, err := newSignatureImageKey(actor.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("error creating signature key: %w", err)
+		}
+		if err := ss.storage.Put(ctx, key, bytes.NewReader(capture.image), int64(len(capture.image)), "image/png"); err != nil {
+			return nil, err
+		}
+		signer.SignatureImageKey = key
+	}
+
+	signedAt := time.Now().UTC()
+	signer.SignatureType = capture.signatureType
+	signer.TypedName = capture.typedName
+	signer.IPAddress = req.IPAddress
+	signer.UserAgent = truncateString(req.UserAgent, 500)
+	signer.SignedAt = &signedAt
+
+	completed, err := ss.signatureRepo.SignSignatureRequest(ctx, signer)
+	if err != nil {
+		if signer.SignatureImageKey != "" {
+			ss.removeOrphanedFile(ctx, signer.SignatureImageKey)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("error signing signature request: %w", err)
+	}
+
+	updated, err := ss.signatureRepo.GetSignatureRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting signature request: %w", err)
+	}
+	if !completed {
+		ss.notifySigners(ctx, updated)
+		return updated.ToResponse(), nil
+	}
+	if updated.Kind == SignatureKindSignature {
+		// The signatures are saved either way; HR can seal the request
+		// again if this fails
+		sealed, err := ss.sealRequest(ctx, updated)
+		if err != nil {
+			log.Printf("signature request %d: error sealing: %v", id, err)
+			return updated.ToResponse(), nil
+		}
+		return sealed.ToResponse(), nil
+	}
+	return updated.ToResponse(), nil
+}
+
+func truncateString(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
+
+// DeclineSignatureRequest records that the actor declines to sign, which
+// ends the request. Acknowledgements cannot be declined.
+func (ss *SignatureService) DeclineSignatureRequest(ctx context.Context, actor Actor, id int, req *dto.DeclineSignatureRequest) (*dto.SignatureRequestResponse, error) {
+	request, signer, err := ss.authorizeSignatureRequest(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		return nil, ErrForbidden
+	}
+	if request.Kind == SignatureKindAcknowledgement {
+		return nil, &utils.ValidationError{Field: "kind", Message: "Acknowledgements cannot be declined"}
+	}
+	if request.Status != SignatureRequestPending {
+		return nil, &utils.ValidationError{Field: "status", Message: "Signature request is " + request.Status}
+	}
+	if signer.Status != SignerPending {
+		return nil, &utils.ValidationError{Field: "status", Message: "You have already " + signer.Status + " this request"}
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > 1000 {
+		return nil, &utils.ValidationError{Field: "reason", Message: "Reason must be at most 1000 characters"}
+	}
+
+	declinedAt := time.Now().UTC()
+	signer.DeclineReason = reason
+	signer.IPAddress = req.IPAddress
+	signer.UserAgent = truncateString(req.UserAgent, 500)
+	signer.DeclinedAt = &declinedAt
+	if err := ss.signatureRepo.DeclineSignatureRequest(ctx, signer); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("error declining signature request: %w", err)
+	}
+
+	updated, err := ss.signatureRepo.GetSignatureRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting signature request: %w", err)
+	}
+	return updated.ToResponse(), nil
+}
+
+// CancelSignatureRequest withdraws a pending request. Only HR may cancel
+// signature requests.
+func (ss *SignatureService) CancelSignatureRequest(ctx context.Context, actor Actor, id int) error {
+	if !actor.IsHR() {
+		return ErrForbidden
+	}
+
+	request, err := ss.signatureRepo.GetSignatureRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return notFoundOr(err, "signature request")
+	}
+	if request.Status != SignatureRequestPending {
+		return &utils.ValidationError{Field: "status", Message: "Only pending signature requests can be cancelled"}
+	}
+
+	if err := ss.signatureRepo.CancelSignatureRequest(ctx, actor.TenantID, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrConflict
+		}
+		return fmt.Errorf("error cancelling signature request: %w", err)
+	}
+	return nil
+}
+
+// SealSignatureRequest seals a completed signature request whose sealing
+// failed when the last signer signed. Only HR may seal requests.
+func (ss *SignatureService) SealSignatureRequest(ctx context.Context, actor Actor, id int) (*dto.SignatureRequestResponse, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+
+	request, err := ss.signatureRepo.GetSignatureRequest(ctx, actor.TenantID, id)
+	if err != nil {
+		return nil, notFoundOr(err, "signature request")
+	}
+	if request.Kind != SignatureKindSignature {
+		return nil, &utils.ValidationError{Field: "kind", Message: "Acknowledgements are not sealed"}
+	}
+	if request.Status != SignatureRequestCompleted {
+		return nil, &utils.ValidationError{Field: "status", Message: "Only completed signature requests can be sealed"}
+	}
+	if request.SealedVersion != nil {
+		return nil, &utils.ValidationError{Field: "status", Message: "Signature request is already sealed"}
+	}
+
+	sealed, err := ss.sealRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return sealed.ToResponse(), nil
+}
+
+// readStoredFile reads a whole stored file
+func (ss *SignatureService) readStoredFile(ctx context.Context, key string) ([]byte, error) {
+	file, err := ss.storage.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, MaxDocumentBytes+1))
+}
+
+// sealRequest renders the sealed PDF of a completed request, with the signed
+// file attached, and saves it as the new current version of the document.
+// The signed file must still match the checksum the signers were shown.
+func (ss *SignatureService) sealRequest(ctx context.Context, request *models.SignatureRequest) (*models.SignatureRequest, error) {
+	version, err := ss.documentRepo.GetDocumentVersion(ctx, request.TenantID, request.DocumentID, request.DocumentVersion)
+	if err != nil {
+		return nil, notFoundOr(err, "document version")
+	}
+	original, err := ss.readStoredFile(ctx, version.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signed document: %w", err)
+	}
+	sum := sha256.Sum256(original)
+	if hex.EncodeToString(sum[:]) != request.DocumentChecksum {
+		return nil, fmt.Errorf("signed document no longer matches its checksum")
+	}
+
+	images := map[int]image.Image{}
+	for _, signer := range request.Signers {
+		if signer.SignatureImageKey == "" {
+			continue
+		}
+		content, err := ss.readStoredFile(ctx, signer.SignatureImageKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading signature of signer %d: %w", signer.ID, err)
+		}
+		img, err := png.Decode(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding signature of signer %d: %w", signer.ID, err)
+		}
+		images[signer.ID] = img
+	}
+
+	content, err := renderSignatureCertificate(request, original, version.ContentType, images, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error rendering signature certificate: %w", err)
+	}
+
+	document, err := ss.documentRepo.GetDocument(ctx, request.TenantID, request.DocumentID)
+	if err != nil {
+		return nil, notFoundOr(err, "document")
+	}
+	employeeID := 0
+	if document.EmployeeID != nil {
+		employeeID = *document.EmployeeID
+	}
+	key, err := newDocumentStorageKey(request.TenantID, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error creating document key: %w", err)
+	}
+	if err := ss.storage.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		return nil, err
+	}
+
+	sealedSum := sha256.Sum256(content)
+	sealed := &models.Document{
+		ID:          document.ID,
+		TenantID:    request.TenantID,
+		StorageKey:  key,
+		FileName:    sealedFileName(version.FileName),
+		ContentType: "application/pdf",
+		SizeBytes:   int64(len(content)),
+		Checksum:    hex.EncodeToString(sealedSum[:]),
+		IssueDate:   document.IssueDate,
+		ExpiryDate:  document.ExpiryDate,
+		UploadedBy:  request.CreatedBy,
+	}
+	updated, err := ss.documentRepo.AddDocumentVersion(ctx, sealed)
+	if err != nil {
+		ss.removeOrphanedFile(ctx, key)
+		return nil, notFoundOr(err, "document")
+	}
+
+	if err := ss.signatureRepo.SealSignatureRequest(ctx, request.TenantID, request.ID, updated.CurrentVersion, sealed.Checksum); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("error sealing signature request: %w", err)
+	}
+	return ss.signatureRepo.GetSignatureRequest(ctx, request.TenantID, request.ID)
+}
+
+// PolicyAcknowledgementReport lists the employees who have not acknowledged a
+// policy version. Only HR may see it.
+func (ss *SignatureService) PolicyAcknowledgementReport(ctx context.Context, actor Actor, policyName string, policyVersion string) (*dto.PolicyAcknowledgementReport, error) {
+	if !actor.IsHR() {
+		return nil, ErrForbidden
+	}
+	policyName = strings.TrimSpace(policyName)
+	policyVersion = strings.TrimSpace(policyVersion)
+	if policyName == "" {
+		return nil, &utils.ValidationError{Field: "policy", Message: "Policy name is required"}
+	}
+	if policyVersion == "" {
+		return nil, &utils.ValidationError{Field: "version", Message: "Policy version is required"}
+	}
+
+	subjects, err := ss.documentRepo.ListRequirementSubjects(ctx, actor.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing employees: %w", err)
+	}
+	signers, err := ss.signatureRepo.ListPolicySigners(ctx, actor.TenantID, policyName, policyVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error listing policy acknowledgements: %w", err)
+	}
+	return policyAcknowledgementReport(policyName, policyVersion, subjects, signers), nil
+}
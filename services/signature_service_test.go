@@ -0,0 +1,297 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/peopleos/dto"
+	"github.com/falasefemi2/peopleos/models"
+	"github.com/falasefemi2/peopleos/repositories"
+	"github.com/falasefemi2/peopleos/utils"
+)
+
+func encodeTestPNG(t *testing.T, width int, height int) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, height/2, color.NRGBA{A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestValidateSignatureRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       dto.SignatureRequestRequest
+		wantField string
+		wantOrder []int
+	}{
+		{"signers sign in turn by default", dto.SignatureRequestRequest{DocumentID: 1, Signers: []dto.SignerRequest{{EmployeeID: 5}, {EmployeeID: 6}}}, "", []int{1, 2}},
+		{"explicit orders are kept", dto.SignatureRequestRequest{DocumentID: 1, Signers: []dto.SignerRequest{{EmployeeID: 5, Order: 2}, {EmployeeID: 6, Order: 2}}}, "", []int{2, 2}},
+		{"acknowledgements default to one order", dto.SignatureRequestRequest{DocumentID: 1, Kind: "acknowledgement", PolicyName: "Handbook", PolicyVersion: "2026.1",
+			Signers: []dto.SignerRequest{{EmployeeID: 5}, {EmployeeID: 6}}}, "", []int{1, 1}},
+		{"acknowledgement to all employees", dto.SignatureRequestRequest{DocumentID: 1, Kind: "acknowledgement", PolicyName: "Handbook", PolicyVersion: "2026.1", AllEmployees: true}, "", []int{}},
+		{"no document", dto.SignatureRequestRequest{Signers: []dto.SignerRequest{{EmployeeID: 5}}}, "document_id", nil},
+		{"unknown kind", dto.SignatureRequestRequest{DocumentID: 1, Kind: "witness", Signers: []dto.SignerRequest{{EmployeeID: 5}}}, "kind", nil},
+		{"no signers", dto.SignatureRequestRequest{DocumentID: 1}, "signers", nil},
+		{"signer listed twice", dto.SignatureRequestRequest{DocumentID: 1, Signers: []dto.SignerRequest{{EmployeeID: 5}, {EmployeeID: 5}}}, "signers", nil},
+		{"negative order", dto.SignatureRequestRequest{DocumentID: 1, Signers: []dto.SignerRequest{{EmployeeID: 5, Order: -1}}}, "signers", nil},
+		{"acknowledgement without a version", dto.SignatureRequestRequest{DocumentID: 1, Kind: "acknowledgement", PolicyName: "Handbook", AllEmployees: true}, "policy_version", nil},
+		{"policy on a signature", dto.SignatureRequestRequest{DocumentID: 1, PolicyName: "Handbook", Signers: []dto.SignerRequest{{EmployeeID: 5}}}, "policy_name", nil},
+		{"signature to all employees", dto.SignatureRequestRequest{DocumentID: 1, AllEmployees: true}, "all_employees", nil},
+		{"all employees and signers", dto.SignatureRequestRequest{DocumentID: 1, Kind: "acknowledgement", PolicyName: "Handbook", PolicyVersion: "1",
+			AllEmployees: true, Signers: []dto.SignerRequest{{EmployeeID: 5}}}, "signers", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := validateSignatureRequest(&tt.req)
+			if tt.wantField != "" {
+				var validationErr *utils.ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField {
+					t.Fatalf("got error %v, want a validation error on %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			orders := []int{}
+			for _, signer := range request.Signers {
+				orders = append(orders, signer.SignOrder)
+			}
+			if len(orders) != len(tt.wantOrder) {
+				t.Fatalf("got orders %v, want %v", orders, tt.wantOrder)
+			}
+			for i := range orders {
+				if orders[i] != tt.wantOrder[i] {
+					t.Errorf("got orders %v, want %v", orders, tt.wantOrder)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSignature(t *testing.T) {
+	valid := encodeTestPNG(t, 300, 100)
+
+	tests := []struct {
+		name      string
+		req       dto.SignDocumentRequest
+		wantField string
+	}{
+		{"typed", dto.SignDocumentRequest{SignatureType: "typed", TypedName: "  Ada   Obi ", Consent: true}, ""},
+		{"drawn", dto.SignDocumentRequest{SignatureType: "drawn", Image: valid, Consent: true}, ""},
+		{"drawn as a data URL", dto.SignDocumentRequest{SignatureType: "drawn", Image: "data:image/png;base64," + valid, Consent: true}, ""},
+		{"no consent", dto.SignDocumentRequest{SignatureType: "typed", TypedName: "Ada Obi"}, "consent"},
+		{"typed without a name", dto.SignDocumentRequest{SignatureType: "typed", Consent: true}, "typed_name"},
+		{"unknown type", dto.SignDocumentRequest{SignatureType: "stamp", Consent: true}, "signature_type"},
+		{"drawn without an image", dto.SignDocumentRequest{SignatureType: "drawn", Consent: true}, "image"},
+		{"data URL of another type", dto.SignDocumentRequest{SignatureType: "drawn", Image: "data:image/svg+xml;base64," + valid, Consent: true}, "image"},
+		{"not base64", dto.SignDocumentRequest{SignatureType: "drawn", Image: "not base64!", Consent: true}, "image"},
+		{"not a PNG", dto.SignDocumentRequest{SignatureType: "drawn", Image: base64.StdEncoding.EncodeToString([]byte("GIF89a")), Consent: true}, "image"},
+		{"too large", dto.SignDocumentRequest{SignatureType: "drawn", Image: encodeTestPNG(t, 100, maxSignatureImageHeight+1), Consent: true}, "image"},
+		{"too many bytes", dto.SignDocumentRequest{SignatureType: "drawn", Image: strings.Repeat("A", maxSignatureImageBytes*2), Consent: true}, "image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture, err := validateSignature(&tt.req)
+			if tt.wantField != "" {
+				var validationErr *utils.ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField {
+					t.Fatalf("got error %v, want a validation error on %s", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if capture.signatureType != tt.req.SignatureType {
+				t.Errorf("got type %q, want %q", capture.signatureType, tt.req.SignatureType)
+			}
+			if capture.signatureType == SignatureTypeTyped && capture.typedName != "Ada Obi" {
+				t.Errorf("got typed name %q, want the spacing tidied", capture.typedName)
+			}
+			if capture.signatureType == SignatureTypeDrawn && !bytes.HasPrefix(capture.image, []byte("\x89PNG")) {
+				t.Error("got no PNG for a drawn signature")
+			}
+		})
+	}
+}
+
+func TestCheckSignerTurn(t *testing.T) {
+	request := &models.SignatureRequest{
+		Status: SignatureRequestPending,
+		Signers: []models.SignatureSigner{
+			{ID: 1, EmployeeID: 10, SignOrder: 1, Status: SignerSigned},
+			{ID: 2, EmployeeID: 11, SignOrder: 1, Status: SignerPending},
+			{ID: 3, EmployeeID: 12, SignOrder: 2, Status: SignerPending},
+		},
+	}
+
+	if err := checkSignerTurn(request, signerOf(request, 11)); err != nil {
+		t.Errorf("got %v, want a signer sharing the first order to sign", err)
+	}
+	if err := checkSignerTurn(request, signerOf(request, 12)); err == nil {
+		t.Error("got no error, want the second order to wait for the first")
+	}
+	if err := checkSignerTurn(request, signerOf(request, 10)); err == nil {
+		t.Error("got no error, want a signer not to sign twice")
+	}
+
+	request.Signers[1].Status = SignerSigned
+	if err := checkSignerTurn(request, signerOf(request, 12)); err != nil {
+		t.Errorf("got %v, want the second order to sign once the first has", err)
+	}
+
+	request.Status = SignatureRequestCancelled
+	if err := checkSignerTurn(request, signerOf(request, 12)); err == nil {
+		t.Error("got no error, want cancelled requests not to be signed")
+	}
+	if signerOf(request, 99) != nil {
+		t.Error("got a signer for an employee not on the request")
+	}
+}
+
+func TestSealedFileName(t *testing.T) {
+	tests := map[string]string{
+		"contract.docx": "contract (signed).pdf",
+		"offer.v2.pdf":  "offer.v2 (signed).pdf",
+		"handbook":      "handbook (signed).pdf",
+		".pdf":          "signed.pdf",
+	}
+	for name, want := range tests {
+		if got := sealedFileName(name); got != want {
+			t.Errorf("sealedFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPolicyAcknowledgementReport(t *testing.T) {
+	asked := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	later := asked.AddDate(0, 0, 7)
+	subjects := []repositories.RequirementSubject{
+		{EmployeeID: 1, FirstName: "Ada", LastName: "Obi"},
+		{EmployeeID: 2, FirstName: "Bayo", LastName: "Ade"},
+		{EmployeeID: 3, FirstName: "Chi", LastName: "Eze"},
+		{EmployeeID: 4, FirstName: "Dami", LastName: "Ola"},
+	}
+	signers := []repositories.PolicySigner{
+		// Acknowledged, then the request was cancelled
+		{EmployeeID: 1, RequestID: 10, Status: SignerSigned, RequestStatus: SignatureRequestCancelled, RequestedAt: asked},
+		// Asked twice; the newer request is the one waiting
+		{EmployeeID: 2, RequestID: 10, Status: SignerPending, RequestStatus: SignatureRequestCancelled, RequestedAt: asked},
+		{EmployeeID: 2, RequestID: 11, Status: SignerPending, RequestStatus: SignatureRequestPending, RequestedAt: later},
+		// Only on a cancelled request
+		{EmployeeID: 3, RequestID: 10, Status: SignerPending, RequestStatus: SignatureRequestCancelled, RequestedAt: asked},
+		// No longer employed
+		{EmployeeID: 9, RequestID: 11, Status: SignerSigned, RequestStatus: SignatureRequestPending, RequestedAt: later},
+	}
+
+	report := policyAcknowledgementReport("Handbook", "2026.1", subjects, signers)
+
+	if report.TotalEmployees != 4 || report.Acknowledged != 1 {
+		t.Errorf("got %d of %d acknowledged, want 1 of 4", report.Acknowledged, report.TotalEmployees)
+	}
+	if len(report.Outstanding) != 3 {
+		t.Fatalf("got %d outstanding, want 3", len(report.Outstanding))
+	}
+	bayo, chi, dami := report.Outstanding[0], report.Outstanding[1], report.Outstanding[2]
+	if bayo.EmployeeName != "Bayo Ade" || bayo.Status != SignerPending || bayo.RequestID == nil || *bayo.RequestID != 11 || !bayo.RequestedAt.Equal(later) {
+		t.Errorf("got %+v, want Bayo pending on the newer request", bayo)
+	}
+	if chi.Status != "not_requested" || chi.RequestID != nil {
+		t.Errorf("got %+v, want Chi not requested", chi)
+	}
+	if dami.Status != "not_requested" {
+		t.Errorf("got %+v, want Dami not requested", dami)
+	}
+}
+
+func TestRenderSignatureCertificate(t *testing.T) {
+	signedAt := time.Date(2026, 10, 19, 14, 30, 5, 0, time.UTC)
+	original := []byte("%PDF-1.4 the contract")
+	drawn, _ := base64.StdEncoding.DecodeString(encodeTestPNG(t, 300, 100))
+	img, err := png.Decode(bytes.NewReader(drawn))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request := &models.SignatureRequest{
+		ID:               42,
+		Title:            "Employment contract",
+		DocumentName:     "contract.pdf",
+		DocumentVersion:  1,
+		DocumentChecksum: strings.Repeat("ab", 32),
+		CreatedByName:    "Hana HR",
+		CompletedAt:      &signedAt,
+		Signers: []models.SignatureSigner{
+			{ID: 1, EmployeeName: "Ada Obi", SignatureType: SignatureTypeTyped, TypedName: "Ada Obi", IPAddress: "203.0.113.7", UserAgent: "Firefox", SignedAt: &signedAt},
+			{ID: 2, EmployeeName: "Bayo Ade", SignatureType: SignatureTypeDrawn, IPAddress: "198.51.100.2", UserAgent: "Safari", SignedAt: &signedAt},
+		},
+	}
+
+	content, err := renderSignatureCertificate(request, original, "application/pdf", map[int]image.Image{2: img}, signedAt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, match := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(content, -1) {
+		objectOffset, _ := strconv.Atoi(string(match[1]))
+		if !bytes.HasPrefix(content[objectOffset:], []byte(strconv.Itoa(i+1)+" 0 obj")) {
+			t.Errorf("got xref entry %d at %d, want object %d there", i, objectOffset, i+1)
+		}
+	}
+	for _, want := range []string{
+		"/Subtype /Image /Width 300 /Height 100",
+		"/EmbeddedFiles << /Names [(contract.pdf) ",
+		"/Type /EmbeddedFile /Subtype /application#2Fpdf /Params << /Size 21 >>",
+		"/XObject << /Im1 ",
+	} {
+		if !bytes.Contains(content, []byte(want)) {
+			t.Errorf("got no %q in the sealed PDF", want)
+		}
+	}
+
+	// Streams are the image, the attachment and then the page content
+	streams := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(content, -1)
+	if len(streams) < 3 {
+		t.Fatalf("got %d streams, want at least 3", len(streams))
+	}
+	inflate := func(data []byte) []byte {
+		reader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		inflated, _ := io.ReadAll(reader)
+		return inflated
+	}
+	if pixels := inflate(streams[0][1]); len(pixels) != 300*100*3 || pixels[0] != 0xFF {
+		t.Errorf("got %d image bytes starting %x, want white RGB samples", len(pixels), pixels[:1])
+	}
+	if attached := inflate(streams[1][1]); !bytes.Equal(attached, original) {
+		t.Errorf("got attachment %q, want the signed document", attached)
+	}
+	page := inflate(streams[2][1])
+	for _, want := range []string{"(Signature certificate) Tj", "203.0.113.7", "2026-10-19 14:30:05 UTC", "/Im1 Do", strings.Repeat("ab", 32)} {
+		if !bytes.Contains(page, []byte(want)) {
+			t.Errorf("got no %q on the certificate page", want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// CountryRules holds the formats HR identifiers and bank details must follow
+// in a given country. Empty patterns are not validated.
+type CountryRules struct {
+	Code               string
+	NationalIDLabel    string
+	NationalIDPattern  *regexp.Regexp
+	TaxNumberPattern   *regexp.Regexp
+	PostalCodePattern  *regexp.Regexp
+	BankAccountPattern *regexp.Regexp
+	BankCodeLabel      string
+	BankCodePattern    *regexp.Regexp
+	UsesIBAN           bool
+}
+
+var countryRules = map[string]CountryRules{
+	"NG": {
+		Code:               "NG",
+		NationalIDLabel:    "NIN",
+		NationalIDPattern:  regexp.MustCompile(`^\d{11}$`),
+		TaxNumberPattern:   regexp.MustCompile(`^\d{8}-?\d{4}$`),
+		PostalCodePattern:  regexp.MustCompile(`^\d{6}$`),
+		BankAccountPattern: regexp.MustCompile(`^\d{10}$`),
+		BankCodeLabel:      "bank code",
+		BankCodePattern:    regexp.MustCompile(`^\d{3}(\d{3})?$`),
+	},
+	"GH": {
+		Code:               "GH",
+		NationalIDLabel:    "Ghana Card number",
+		NationalIDPattern:  regexp.MustCompile(`^GHA-\d{9}-\d$`),
+		TaxNumberPattern:   regexp.MustCompile(`^[A-Z]\d{10}$`),
+		BankAccountPattern: regexp.MustCompile(`^\d{10,16}$`),
+	},
+	"KE": {
+		Code:               "KE",
+		NationalIDLabel:    "national ID number",
+		NationalIDPattern:  regexp.MustCompile(`^\d{7,8}$`),
+		TaxNumberPattern:   regexp.MustCompile(`^[A-Z]\d{9}[A-Z]$`),
+		PostalCodePattern:  regexp.MustCompile(`^\d{5}$`),
+		BankAccountPattern: regexp.MustCompile(`^\d{10,14}$`),
+	},
+	"ZA": {
+		Code:               "ZA",
+		NationalIDLabel:    "ID number",
+		NationalIDPattern:  regexp.MustCompile(`^\d{13}$`),
+		TaxNumberPattern:   regexp.MustCompile(`^\d{10}$`),
+		PostalCodePattern:  regexp.MustCompile(`^\d{4}$`),
+		BankAccountPattern: regexp.MustCompile(`^\d{7,11}$`),
+		BankCodeLabel:      "branch code",
+		BankCodePattern:    regexp.MustCompile(`^\d{6}$`),
+	},
+	"GB": {
+		Code:               "GB",
+		NationalIDLabel:    "National Insurance number",
+		NationalIDPattern:  regexp.MustCompile(`^[A-CEGHJ-PR-TW-Z]{2}\d{6}[A-D]$`),
+		TaxNumberPattern:   regexp.MustCompile(`^\d{10}$`),
+		PostalCodePattern:  regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+		BankAccountPattern: regexp.MustCompile(`^\d{8}$`),
+		BankCodeLabel:      "sort code",
+		BankCodePattern:    regexp.MustCompile(`^\d{2}-?\d{2}-?\d{2}$`),
+		UsesIBAN:           true,
+	},
+	"US": {
+		Code:               "US",
+		NationalIDLabel:    "SSN",
+		NationalIDPattern:  regexp.MustCompile(`^\d{3}-?\d{2}-?\d{4}$`),
+		TaxNumberPattern:   regexp.MustCompile(`^\d{3}-?\d{2}-?\d{4}$`),
+		PostalCodePattern:  regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+		BankAccountPattern: regexp.MustCompile(`^\d{4,17}$`),
+		BankCodeLabel:      "routing number",
+		BankCodePattern:    regexp.MustCompile(`^\d{9}$`),
+	},
+	"CA": {
+		Code:               "CA",
+		NationalIDLabel:    "SIN",
+		NationalIDPattern:  regexp.MustCompile(`^\d{3}-?\d{3}-?\d{3}$`),
+		PostalCodePattern:  regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+		BankAccountPattern: regexp.MustCompile(`^\d{7,12}$`),
+		BankCodeLabel:      "transit number",
+		BankCodePattern:    regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	},
+	"IN": {
+		Code:               "IN",
+		NationalIDLabel:    "Aadhaar number",
+		NationalIDPattern:  regexp.MustCompile(`^\d{12}$`),
+		TaxNumberPattern:   regexp.MustCompile(`^[A-Z]{5}\d{4}[A-Z]$`),
+		PostalCodePattern:  regexp.MustCompile(`^\d{6}$`),
+		BankAccountPattern: regexp.MustCompile(`^\d{9,18}$`),
+		BankCodeLabel:      "IFSC",
+		BankCodePattern:    regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`),
+	},
+	"DE": {
+		Code:              "DE",
+		TaxNumberPattern:  regexp.MustCompile(`^\d{11}$`),
+		PostalCodePattern: regexp.MustCompile(`^\d{5}$`),
+		UsesIBAN:          true,
+	},
+	"FR": {
+		Code:              "FR",
+		NationalIDLabel:   "NIR",
+		NationalIDPattern: regexp.MustCompile(`^[12]\d{14}$`),
+		TaxNumberPattern:  regexp.MustCompile(`^\d{13}$`),
+		PostalCodePattern: regexp.MustCompile(`^\d{5}$`),
+		UsesIBAN:          true,
+	},
+}
+
+var countryNames = map[string]string{
+	"nigeria":                  "NG",
+	"ghana":                    "GH",
+	"kenya":                    "KE",
+	"south africa":             "ZA",
+	"united kingdom":           "GB",
+	"uk":                       "GB",
+	"great britain":            "GB",
+	"united states":            "US",
+	"united states of america": "US",
+	"usa":                      "US",
+	"canada":                   "CA",
+	"india":                    "IN",
+	"germany":                  "DE",
+	"france":                   "FR",
+}
+
+// NormalizeCountryCode turns a stored country (either an ISO 3166-1 alpha-2
+// code or an English country name) into an upper-case alpha-2 code. Unknown
+// names are returned as an empty string.
+func NormalizeCountryCode(country string) string {
+	country = strings.TrimSpace(country)
+	if len(country) == 2 {
+		return strings.ToUpper(country)
+	}
+	return countryNames[strings.ToLower(country)]
+}
+
+// GetCountryRules returns the validation rules for a country, and false when
+// the country has no specific rules.
+func GetCountryRules(country string) (CountryRules, bool) {
+	rules, ok := countryRules[NormalizeCountryCode(country)]
+	return rules, ok
+}
+
+// IsValidIBAN checks the length and ISO 7064 mod-97 checksum of an IBAN
+func IsValidIBAN(iban string) bool {
+	iban = strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	rearranged := iban[4:] + iban[:4]
+	var digits strings.Builder
+	for _, ch := range rearranged {
+		switch {
+		case ch >= '0' && ch <= '9':
+			digits.WriteRune(ch)
+		case ch >= 'A' && ch <= 'Z':
+			digits.WriteString(big.NewInt(int64(ch - 'A' + 10)).String())
+		default:
+			return false
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
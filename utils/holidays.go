@@ -0,0 +1,307 @@
+package utils
+
+import (
+	"sort"
+	"time"
+)
+
+// PublicHoliday is a day off on a date
+type PublicHoliday struct {
+	Date time.Time
+	Name string
+}
+
+type holidayKind int
+
+const (
+	fixedDate holidayKind = iota
+	nthWeekday
+	weekdayOnOrBefore
+	easterOffset
+)
+
+// holidayRule describes how to find a holiday's date in a given year. Nth
+// counts weekdays from the start of the month, or from the end when negative.
+type holidayRule struct {
+	Name     string
+	Kind     holidayKind
+	Month    time.Month
+	Day      int
+	Weekday  time.Weekday
+	Nth      int
+	Offset   int
+	FromYear int
+}
+
+func fixed(name string, month time.Month, day int) holidayRule {
+	return holidayRule{Name: name, Kind: fixedDate, Month: month, Day: day}
+}
+
+func nth(name string, month time.Month, weekday time.Weekday, n int) holidayRule {
+	return holidayRule{Name: name, Kind: nthWeekday, Month: month, Weekday: weekday, Nth: n}
+}
+
+func easter(name string, offset int) holidayRule {
+	return holidayRule{Name: name, Kind: easterOffset, Offset: offset}
+}
+
+func since(rule holidayRule, year int) holidayRule {
+	rule.FromYear = year
+	return rule
+}
+
+// How a country observes holidays that fall on a weekend
+type weekendRule int
+
+const (
+	noSubstitute weekendRule = iota
+	sundayToMonday
+	nextWeekday
+	nearestWeekday
+)
+
+type holidayCalendar struct {
+	Rules   []holidayRule
+	Weekend weekendRule
+}
+
+// holidayCalendars holds the national public holidays of the countries with
+// country rules. Holidays set by the lunar calendar, such as Eid, move every
+// year and are left to an iCalendar import or the tenant's own holidays.
+var holidayCalendars = map[string]holidayCalendar{
+	"NG": {
+		Weekend: sundayToMonday,
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			easter("Good Friday", -2),
+			easter("Easter Monday", 1),
+			fixed("Workers' Day", time.May, 1),
+			fixed("Democracy Day", time.June, 12),
+			fixed("Independence Day", time.October, 1),
+			fixed("Christmas Day", time.December, 25),
+			fixed("Boxing Day", time.December, 26),
+		},
+	},
+	"GH": {
+		Weekend: nextWeekday,
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			fixed("Constitution Day", time.January, 7),
+			fixed("Independence Day", time.March, 6),
+			easter("Good Friday", -2),
+			easter("Easter Monday", 1),
+			fixed("May Day", time.May, 1),
+			fixed("Founders' Day", time.August, 4),
+			fixed("Kwame Nkrumah Memorial Day", time.September, 21),
+			nth("Farmers' Day", time.December, time.Friday, 1),
+			fixed("Christmas Day", time.December, 25),
+			fixed("Boxing Day", time.December, 26),
+		},
+	},
+	"KE": {
+		Weekend: sundayToMonday,
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			easter("Good Friday", -2),
+			easter("Easter Monday", 1),
+			fixed("Labour Day", time.May, 1),
+			fixed("Madaraka Day", time.June, 1),
+			fixed("Mashujaa Day", time.October, 20),
+			fixed("Jamhuri Day", time.December, 12),
+			fixed("Christmas Day", time.December, 25),
+			fixed("Boxing Day", time.December, 26),
+		},
+	},
+	"ZA": {
+		Weekend: sundayToMonday,
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			fixed("Human Rights Day", time.March, 21),
+			easter("Good Friday", -2),
+			easter("Family Day", 1),
+			fixed("Freedom Day", time.April, 27),
+			fixed("Workers' Day", time.May, 1),
+			fixed("Youth Day", time.June, 16),
+			fixed("National Women's Day", time.August, 9),
+			fixed("Heritage Day", time.September, 24),
+			fixed("Day of Reconciliation", time.December, 16),
+			fixed("Christmas Day", time.December, 25),
+			fixed("Day of Goodwill", time.December, 26),
+		},
+	},
+	"GB": {
+		Weekend: nextWeekday,
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			easter("Good Friday", -2),
+			easter("Easter Monday", 1),
+			nth("Early May bank holiday", time.May, time.Monday, 1),
+			nth("Spring bank holiday", time.May, time.Monday, -1),
+			nth("Summer bank holiday", time.August, time.Monday, -1),
+			fixed("Christmas Day", time.December, 25),
+			fixed("Boxing Day", time.December, 26),
+		},
+	},
+	"US": {
+		Weekend: nearestWeekday,
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			nth("Martin Luther King Jr. Day", time.January, time.Monday, 3),
+			nth("Washington's Birthday", time.February, time.Monday, 3),
+			nth("Memorial Day", time.May, time.Monday, -1),
+			since(fixed("Juneteenth", time.June, 19), 2021),
+			fixed("Independence Day", time.July, 4),
+			nth("Labor Day", time.September, time.Monday, 1),
+			nth("Columbus Day", time.October, time.Monday, 2),
+			fixed("Veterans Day", time.November, 11),
+			nth("Thanksgiving Day", time.November, time.Thursday, 4),
+			fixed("Christmas Day", time.December, 25),
+		},
+	},
+	"CA": {
+		Weekend: nextWeekday,
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			easter("Good Friday", -2),
+			{Name: "Victoria Day", Kind: weekdayOnOrBefore, Month: time.May, Day: 24, Weekday: time.Monday},
+			fixed("Canada Day", time.July, 1),
+			nth("Labour Day", time.September, time.Monday, 1),
+			since(fixed("National Day for Truth and Reconciliation", time.September, 30), 2021),
+			nth("Thanksgiving", time.October, time.Monday, 2),
+			fixed("Remembrance Day", time.November, 11),
+			fixed("Christmas Day", time.December, 25),
+			fixed("Boxing Day", time.December, 26),
+		},
+	},
+	"IN": {
+		Rules: []holidayRule{
+			fixed("Republic Day", time.January, 26),
+			fixed("Independence Day", time.August, 15),
+			fixed("Gandhi Jayanti", time.October, 2),
+		},
+	},
+	"DE": {
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			easter("Good Friday", -2),
+			easter("Easter Monday", 1),
+			fixed("Labour Day", time.May, 1),
+			easter("Ascension Day", 39),
+			easter("Whit Monday", 50),
+			fixed("German Unity Day", time.October, 3),
+			fixed("Christmas Day", time.December, 25),
+			fixed("St. Stephen's Day", time.December, 26),
+		},
+	},
+	"FR": {
+		Rules: []holidayRule{
+			fixed("New Year's Day", time.January, 1),
+			easter("Easter Monday", 1),
+			fixed("Labour Day", time.May, 1),
+			fixed("Victory in Europe Day", time.May, 8),
+			easter("Ascension Day", 39),
+			easter("Whit Monday", 50),
+			fixed("Bastille Day", time.July, 14),
+			fixed("Assumption Day", time.August, 15),
+			fixed("All Saints' Day", time.November, 1),
+			fixed("Armistice Day", time.November, 11),
+			fixed("Christmas Day", time.December, 25),
+		},
+	},
+}
+
+// easterSunday returns the date of Easter Sunday in the Gregorian calendar
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func (r holidayRule) date(year int) time.Time {
+	switch r.Kind {
+	case nthWeekday:
+		if r.Nth < 0 {
+			last := time.Date(year, r.Month+1, 0, 0, 0, 0, 0, time.UTC)
+			back := (int(last.Weekday()) - int(r.Weekday) + 7) % 7
+			return last.AddDate(0, 0, -back+7*(r.Nth+1))
+		}
+		first := time.Date(year, r.Month, 1, 0, 0, 0, 0, time.UTC)
+		ahead := (int(r.Weekday) - int(first.Weekday()) + 7) % 7
+		return first.AddDate(0, 0, ahead+7*(r.Nth-1))
+	case weekdayOnOrBefore:
+		day := time.Date(year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)
+		back := (int(day.Weekday()) - int(r.Weekday) + 7) % 7
+		return day.AddDate(0, 0, -back)
+	case easterOffset:
+		return easterSunday(year).AddDate(0, 0, r.Offset)
+	default:
+		return time.Date(year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func isWeekend(day time.Time) bool {
+	return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+}
+
+// HasBundledHolidays reports whether public holidays are bundled for the
+// country
+func HasBundledHolidays(country string) bool {
+	_, ok := holidayCalendars[NormalizeCountryCode(country)]
+	return ok
+}
+
+// BundledHolidays returns the country's public holidays in the year ordered
+// by date, including the weekdays given in place of holidays that fall on a
+// weekend. It returns nil for countries without bundled holidays.
+func BundledHolidays(country string, year int) []PublicHoliday {
+	calendar, ok := holidayCalendars[NormalizeCountryCode(country)]
+	if !ok {
+		return nil
+	}
+
+	holidays := []PublicHoliday{}
+	taken := map[time.Time]bool{}
+	for _, rule := range calendar.Rules {
+		if year < rule.FromYear {
+			continue
+		}
+		date := rule.date(year)
+		holidays = append(holidays, PublicHoliday{Date: date, Name: rule.Name})
+		taken[date] = true
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date) })
+
+	substitutes := []PublicHoliday{}
+	for _, holiday := range holidays {
+		var substitute time.Time
+		switch {
+		case calendar.Weekend == sundayToMonday && holiday.Date.Weekday() == time.Sunday,
+			calendar.Weekend == nextWeekday && isWeekend(holiday.Date):
+			substitute = holiday.Date.AddDate(0, 0, 1)
+			for isWeekend(substitute) || taken[substitute] {
+				substitute = substitute.AddDate(0, 0, 1)
+			}
+		case calendar.Weekend == nearestWeekday && holiday.Date.Weekday() == time.Saturday:
+			substitute = holiday.Date.AddDate(0, 0, -1)
+		case calendar.Weekend == nearestWeekday && holiday.Date.Weekday() == time.Sunday:
+			substitute = holiday.Date.AddDate(0, 0, 1)
+		default:
+			continue
+		}
+		taken[substitute] = true
+		substitutes = append(substitutes, PublicHoliday{Date: substitute, Name: holiday.Name + " (observed)"})
+	}
+
+	holidays = append(holidays, substitutes...)
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date) })
+	return holidays
+}
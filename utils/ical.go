@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalidICalendar is returned for input that is not an iCalendar file
+var ErrInvalidICalendar = errors.New("not an iCalendar file")
+
+// maxICalEventDays caps how many days a single event can expand into
+const maxICalEventDays = 31
+
+// ParseICalendar reads the events of an iCalendar (RFC 5545) file as
+// holidays, one per day the event covers. Timed events count for the day they
+// start on.
+func ParseICalendar(r io.Reader) ([]PublicHoliday, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, ErrInvalidICalendar
+	}
+
+	holidays := []PublicHoliday{}
+	inEvent := false
+	var summary, start, end string
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(strings.ToUpper(name), ";")
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			summary, start, end = "", "", ""
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			inEvent = false
+			days, err := icalEventDays(start, end)
+			if err != nil {
+				return nil, err
+			}
+			for _, day := range days {
+				holidays = append(holidays, PublicHoliday{Date: day, Name: summary})
+			}
+		case !inEvent:
+		case name == "SUMMARY":
+			summary = unescapeICalText(value)
+		case name == "DTSTART":
+			start = value
+		case name == "DTEND":
+			// Only all-day events span several days
+			if strings.Contains(params, "VALUE=DATE") || len(value) == 8 {
+				end = value
+			}
+		}
+	}
+
+	return holidays, nil
+}
+
+// unfoldICalLines joins continuation lines, which start with a space or tab,
+// onto the line before them
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	lines := []string{}
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func parseICalDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, ErrInvalidICalendar
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, ErrInvalidICalendar
+	}
+	return date, nil
+}
+
+// icalEventDays lists the days from start up to the exclusive end date
+func icalEventDays(start string, end string) ([]time.Time, error) {
+	first, err := parseICalDate(start)
+	if err != nil {
+		return nil, err
+	}
+	if end == "" {
+		return []time.Time{first}, nil
+	}
+	last, err := parseICalDate(end)
+	if err != nil {
+		return nil, err
+	}
+
+	days := []time.Time{first}
+	for day := first.AddDate(0, 0, 1); day.Before(last) && len(days) < maxICalEventDays; day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+var icalTextReplacer = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeICalText(value string) string {
+	return strings.TrimSpace(icalTextReplacer.Replace(value))
+}
+
+// ICalEvent is an all-day event; End is the last day it covers
+type ICalEvent struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Tentative   bool
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+
+// FormatICalendar writes events as an iCalendar (RFC 5545) file named name,
+// stamped with now
+func FormatICalendar(name string, events []ICalEvent, now time.Time) []byte {
+	var b strings.Builder
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//PeopleOS//Leave Calendar//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+icalEscaper.Replace(name))
+	for _, event := range events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+event.UID)
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+event.Start.Format("20060102"))
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+event.End.AddDate(0, 0, 1).Format("20060102"))
+		writeICalLine(&b, "SUMMARY:"+icalEscaper.Replace(event.Summary))
+		if event.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+icalEscaper.Replace(event.Description))
+		}
+		status := "CONFIRMED"
+		if event.Tentative {
+			status = "TENTATIVE"
+		}
+		writeICalLine(&b, "STATUS:"+status)
+		writeICalLine(&b, "TRANSP:TRANSPARENT")
+		writeICalLine(&b, "END:VEVENT")
+	}
+	writeICalLine(&b, "END:VCALENDAR")
+
+	return []byte(b.String())
+}
+
+// writeICalLine folds lines longer than 75 octets without splitting a UTF-8
+// character
+func writeICalLine(b *strings.Builder, line string) {
+	// Continuation lines start with a space, which counts towards the limit
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		fmt.Fprintf(b, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line + "\r\n")
+}
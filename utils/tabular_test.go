@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTabularWriterNeutralizesFormulas(t *testing.T) {
+	row := []string{"=HYPERLINK(\"http://evil\")", "+1", "-2+3", "@SUM(A1)", "\tcmd", "Ada", "", "a=b"}
+	want := []string{"'=HYPERLINK(\"http://evil\")", "'+1", "'-2+3", "'@SUM(A1)", "'\tcmd", "Ada", "", "a=b"}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, _ := NewTabularWriter(FormatCSV, &buf)
+		w.WriteHeader([]string{"=name"})
+		w.WriteRow(row)
+		if err := w.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		reader := csv.NewReader(&buf)
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if records[0][0] != "'=name" {
+			t.Errorf("got header %q, want it neutralized", records[0][0])
+		}
+		for i, value := range want {
+			if records[1][i] != value {
+				t.Errorf("got %q, want %q", records[1][i], value)
+			}
+		}
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, _ := NewTabularWriter(FormatXLSX, &buf)
+		w.WriteHeader([]string{"name"})
+		w.WriteRow(row)
+		if err := w.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		f, err := zr.Open("xl/worksheets/sheet1.xml")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sheet, _ := io.ReadAll(f)
+		for _, value := range []string{`>&#39;=HYPERLINK(&#34;http://evil&#34;)<`, `>&#39;+1<`, `>&#39;@SUM(A1)<`, `>Ada<`} {
+			if !strings.Contains(string(sheet), value) {
+				t.Errorf("got sheet %s, want it to contain %s", sheet, value)
+			}
+		}
+	})
+
+	t.Run("jsonl keeps values as data", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, _ := NewTabularWriter(FormatJSONL, &buf)
+		w.WriteHeader([]string{"name"})
+		w.WriteRow([]string{"=1+1"})
+		if got := buf.String(); got != "{\"name\":\"=1+1\"}\n" {
+			t.Errorf("got %q, want the value unchanged", got)
+		}
+	})
+}
@@ -61,3 +61,18 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return e.Message
 }
+
+// QueryInt parses an optional integer query parameter, returning 0 when absent
+func QueryInt(r *http.Request, key string) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ValidationError{Field: key, Message: "Invalid " + key}
+	}
+
+	return n, nil
+}